@@ -16,6 +16,7 @@ import (
 	bcctypes "github.com/babylonchain/babylon/x/btccheckpoint/types"
 	btclctypes "github.com/babylonchain/babylon/x/btclightclient/types"
 	btcstypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	"github.com/babylonchain/btc-staker/metrics"
 	"github.com/babylonchain/btc-staker/stakercfg"
 	"github.com/babylonchain/btc-staker/stakerdb"
 	"github.com/babylonchain/btc-staker/utils"
@@ -55,11 +56,18 @@ var (
 	ErrInvalidValueReceivedFromBabylonNode = errors.New("invalid value received from babylon node")
 )
 
+var (
+	// type url of message sent to register delegation on babylon
+	MsgCreateBTCDelegationTypeURL = sdk.MsgTypeURL(&btcstypes.MsgCreateBTCDelegation{})
+)
+
 type BabylonController struct {
 	bbnClient *bbnclient.Client
 	cfg       *stakercfg.BBNConfig
 	btcParams *chaincfg.Params
 	logger    *logrus.Logger
+	// nil if metrics are not collected
+	metrics *metrics.BabylonClientMetrics
 }
 
 var _ BabylonClient = (*BabylonController)(nil)
@@ -69,6 +77,7 @@ func NewBabylonController(
 	btcParams *chaincfg.Params,
 	logger *logrus.Logger,
 	clientLogger *zap.Logger,
+	m *metrics.BabylonClientMetrics,
 ) (*BabylonController, error) {
 	babylonConfig := stakercfg.BBNConfigToBabylonConfig(cfg)
 
@@ -92,6 +101,7 @@ func NewBabylonController(
 		cfg,
 		btcParams,
 		logger,
+		m,
 	}
 
 	return client, nil
@@ -133,7 +143,13 @@ func (bc *BabylonController) Params() (*StakingParams, error) {
 	var bccParams *bcctypes.Params
 	if err := retry.Do(func() error {
 
-		response, err := bc.bbnClient.BTCCheckpointParams()
+		// client method does not accept context, so it is abandoned after timeout
+		ctx, cancel := getQueryContext(bc.cfg.Timeout)
+		defer cancel()
+
+		start := time.Now()
+		response, err := utils.CallWithContext(ctx, bc.bbnClient.BTCCheckpointParams)
+		bc.metrics.ObserveQuery("btc_checkpoint_params", time.Since(start), err)
 		if err != nil {
 			return err
 		}
@@ -399,8 +415,14 @@ func delegationDataToMsg(signer string, dg *DelegationData) (*btcstypes.MsgCreat
 func (bc *BabylonController) reliablySendMsgs(
 	msgs []sdk.Msg,
 ) (*pv.RelayerTxResponse, error) {
+	// sending waits for inclusion of the transaction in a block
+	ctx, cancel := context.WithTimeout(context.Background(), bc.cfg.Timeout+bc.cfg.BlockTimeout)
+	defer cancel()
+
 	// TODO Empty errors ??
-	return bc.bbnClient.ReliablySendMsgs(context.Background(), msgs, []*sdkErr.Error{}, []*sdkErr.Error{})
+	resp, err := bc.bbnClient.ReliablySendMsgs(ctx, msgs, []*sdkErr.Error{}, []*sdkErr.Error{})
+	bc.recordBroadcast(msgs, resp, err)
+	return resp, err
 }
 
 // TODO: for now return sdk.TxResponse, it will ease up debugging/testing
@@ -444,7 +466,9 @@ func (bc *BabylonController) QueryStakingTracker() (*StakingTrackerResponse, err
 	clientCtx := client.Context{Client: bc.bbnClient.RPCClient}
 	queryClient := btcstypes.NewQueryClient(clientCtx)
 
+	start := time.Now()
 	response, err := queryClient.Params(ctx, &btcstypes.QueryParamsRequest{})
+	bc.metrics.ObserveQuery("btcstaking_params", time.Since(start), err)
 	if err != nil {
 		return nil, err
 	}
@@ -502,6 +526,7 @@ func (bc *BabylonController) QueryFinalityProviders(
 
 	var response *btcstypes.QueryFinalityProvidersResponse
 	if err := retry.Do(func() error {
+		start := time.Now()
 		resp, err := queryClient.FinalityProviders(
 			ctx,
 			&btcstypes.QueryFinalityProvidersRequest{
@@ -512,6 +537,7 @@ func (bc *BabylonController) QueryFinalityProviders(
 				},
 			},
 		)
+		bc.metrics.ObserveQuery("finality_providers", time.Since(start), err)
 		if err != nil {
 			return err
 		}
@@ -569,12 +595,14 @@ func (bc *BabylonController) QueryFinalityProvider(btcPubKey *btcec.PublicKey) (
 
 	var response *btcstypes.QueryFinalityProviderResponse
 	if err := retry.Do(func() error {
+		start := time.Now()
 		resp, err := queryClient.FinalityProvider(
 			ctx,
 			&btcstypes.QueryFinalityProviderRequest{
 				FpBtcPkHex: hexPubKey,
 			},
 		)
+		bc.metrics.ObserveQuery("finality_provider", time.Since(start), err)
 		if err != nil {
 			if strings.Contains(err.Error(), btcstypes.ErrFpNotFound.Error()) {
 				// if there is no finality provider with such key, we return unrecoverable error, as we not need to retry any more
@@ -623,7 +651,9 @@ func (bc *BabylonController) QueryHeaderDepth(headerHash *chainhash.Hash) (uint6
 
 	var response *btclctypes.QueryHeaderDepthResponse
 	if err := retry.Do(func() error {
+		start := time.Now()
 		depthResponse, err := queryClient.HeaderDepth(ctx, &btclctypes.QueryHeaderDepthRequest{Hash: headerHash.String()})
+		bc.metrics.ObserveQuery("header_depth", time.Since(start), err)
 		if err != nil {
 			return err
 		}
@@ -694,9 +724,11 @@ func (bc *BabylonController) QueryDelegationInfo(stakingTxHash *chainhash.Hash)
 
 	var di *DelegationInfo
 	if err := retry.Do(func() error {
+		start := time.Now()
 		resp, err := queryClient.BTCDelegation(ctx, &btcstypes.QueryBTCDelegationRequest{
 			StakingTxHashHex: stakingTxHash.String(),
 		})
+		bc.metrics.ObserveQuery("btc_delegation", time.Since(start), err)
 		if err != nil {
 			if strings.Contains(err.Error(), btcstypes.ErrBTCDelegationNotFound.Error()) {
 				// delegation is not found on babylon, do not retry further
@@ -817,7 +849,9 @@ func (bc *BabylonController) QueryPendingBTCDelegations() ([]*btcstypes.BTCDeleg
 		Status: btcstypes.BTCDelegationStatus_PENDING,
 	}
 
+	start := time.Now()
 	res, err := queryClient.BTCDelegations(ctx, &queryRequest)
+	bc.metrics.ObserveQuery("btc_delegations", time.Since(start), err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query BTC delegations: %v", err)
 	}
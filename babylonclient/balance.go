@@ -0,0 +1,53 @@
+package babylonclient
+
+import (
+	"time"
+
+	"github.com/avast/retry-go/v4"
+	"github.com/cosmos/cosmos-sdk/client"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/sirupsen/logrus"
+)
+
+// QueryBalance returns balance of given address in given denom
+func (bc *BabylonController) QueryBalance(address sdk.AccAddress, denom string) (sdk.Coin, error) {
+	ctx, cancel := getQueryContext(bc.cfg.Timeout)
+	defer cancel()
+
+	clientCtx := client.Context{Client: bc.bbnClient.RPCClient}
+	queryClient := banktypes.NewQueryClient(clientCtx)
+
+	bech32Address := sdk.MustBech32ifyAddressBytes(bc.cfg.AccountPrefix, address)
+
+	var response *banktypes.QueryBalanceResponse
+	if err := retry.Do(func() error {
+		start := time.Now()
+		resp, err := queryClient.Balance(ctx, &banktypes.QueryBalanceRequest{
+			Address: bech32Address,
+			Denom:   denom,
+		})
+		bc.metrics.ObserveQuery("balance", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+		response = resp
+		return nil
+	}, RtyAtt, RtyDel, RtyErr, retry.OnRetry(func(n uint, err error) {
+		bc.logger.WithFields(logrus.Fields{
+			"attempt":      n + 1,
+			"max_attempts": RtyAttNum,
+			"address":      bech32Address,
+			"denom":        denom,
+			"error":        err,
+		}).Error("Failed to query babylon for account balance")
+	})); err != nil {
+		return sdk.Coin{}, err
+	}
+
+	if response.Balance == nil {
+		return sdk.NewInt64Coin(denom, 0), nil
+	}
+
+	return *response.Balance, nil
+}
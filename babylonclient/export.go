@@ -0,0 +1,70 @@
+package babylonclient
+
+import (
+	"fmt"
+
+	btcstypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/std"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+)
+
+// DelegationExport is delegation rendered in formats accepted by babylond cli,
+// so that it can be submitted to babylon without the daemon
+type DelegationExport struct {
+	// MsgCreateBTCDelegation in proto json format, with its type url
+	MsgJSON []byte
+	// unsigned transaction with the message, which can be signed by
+	// babylond tx sign and broadcast by babylond tx broadcast
+	UnsignedTxJSON []byte
+}
+
+// ExportDelegation renders delegation as MsgCreateBTCDelegation sent by signer.
+// Proof of possession of delegation is bound to babylon key of the daemon, so
+// signer must be the address of this key.
+func ExportDelegation(
+	signer string,
+	dg *DelegationData,
+	gasLimit uint64,
+	fees sdk.Coins,
+) (*DelegationExport, error) {
+	msg, err := delegationDataToMsg(signer, dg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	registry := codectypes.NewInterfaceRegistry()
+	std.RegisterInterfaces(registry)
+	btcstypes.RegisterInterfaces(registry)
+	cdc := codec.NewProtoCodec(registry)
+
+	msgJSON, err := cdc.MarshalInterfaceJSON(msg)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode delegation message: %w", err)
+	}
+
+	txConfig := authtx.NewTxConfig(cdc, authtx.DefaultSignModes)
+	txBuilder := txConfig.NewTxBuilder()
+
+	if err := txBuilder.SetMsgs(msg); err != nil {
+		return nil, fmt.Errorf("failed to build delegation transaction: %w", err)
+	}
+
+	txBuilder.SetGasLimit(gasLimit)
+	txBuilder.SetFeeAmount(fees)
+
+	txJSON, err := txConfig.TxJSONEncoder()(txBuilder.GetTx())
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode delegation transaction: %w", err)
+	}
+
+	return &DelegationExport{
+		MsgJSON:        msgJSON,
+		UnsignedTxJSON: txJSON,
+	}, nil
+}
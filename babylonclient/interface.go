@@ -1,6 +1,7 @@
 package babylonclient
 
 import (
+	"errors"
 	"fmt"
 
 	sdkmath "cosmossdk.io/math"
@@ -54,8 +55,12 @@ type BabylonClient interface {
 	QueryFinalityProviders(limit uint64, offset uint64) (*FinalityProvidersClientResponse, error)
 	QueryFinalityProvider(btcPubKey *btcec.PublicKey) (*FinalityProviderClientResponse, error)
 	QueryHeaderDepth(headerHash *chainhash.Hash) (uint64, error)
+	QueryBtcLightClientTip() (uint64, error)
 	IsTxAlreadyPartOfDelegation(stakingTxHash *chainhash.Hash) (bool, error)
 	QueryDelegationInfo(stakingTxHash *chainhash.Hash) (*DelegationInfo, error)
+	QueryRewardGauges(address sdk.AccAddress) (map[string]*RewardGauge, error)
+	QueryBalance(address sdk.AccAddress, denom string) (sdk.Coin, error)
+	WithdrawRewards(stakeholderType string, recipient sdk.AccAddress, amount sdk.Coins) (*pv.RelayerTxResponse, error)
 }
 
 type MockBabylonClient struct {
@@ -131,6 +136,10 @@ func (m *MockBabylonClient) QueryHeaderDepth(headerHash *chainhash.Hash) (uint64
 	return uint64(m.ClientParams.ConfirmationTimeBlocks) + 1, nil
 }
 
+func (m *MockBabylonClient) QueryBtcLightClientTip() (uint64, error) {
+	return 0, fmt.Errorf("btc light client tip is not known to mock client: %w", errors.ErrUnsupported)
+}
+
 func (m *MockBabylonClient) IsTxAlreadyPartOfDelegation(stakingTxHash *chainhash.Hash) (bool, error) {
 	return false, nil
 }
@@ -139,6 +148,22 @@ func (m *MockBabylonClient) QueryDelegationInfo(stakingTxHash *chainhash.Hash) (
 	return nil, fmt.Errorf("delegation do not exist")
 }
 
+func (m *MockBabylonClient) QueryRewardGauges(address sdk.AccAddress) (map[string]*RewardGauge, error) {
+	return map[string]*RewardGauge{}, nil
+}
+
+func (m *MockBabylonClient) QueryBalance(address sdk.AccAddress, denom string) (sdk.Coin, error) {
+	return sdk.NewInt64Coin(denom, 0), nil
+}
+
+func (m *MockBabylonClient) WithdrawRewards(
+	stakeholderType string,
+	recipient sdk.AccAddress,
+	amount sdk.Coins,
+) (*pv.RelayerTxResponse, error) {
+	return &pv.RelayerTxResponse{Code: 0}, nil
+}
+
 func (m *MockBabylonClient) Undelegate(
 	req *UndelegationRequest) (*pv.RelayerTxResponse, error) {
 	return &pv.RelayerTxResponse{Code: 0}, nil
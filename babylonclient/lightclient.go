@@ -0,0 +1,46 @@
+package babylonclient
+
+import (
+	"time"
+
+	"github.com/avast/retry-go/v4"
+	btclctypes "github.com/babylonchain/babylon/x/btclightclient/types"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/sirupsen/logrus"
+)
+
+// QueryBtcLightClientTip returns height of the best btc header known to babylon
+// btc light client
+func (bc *BabylonController) QueryBtcLightClientTip() (uint64, error) {
+	ctx, cancel := getQueryContext(bc.cfg.Timeout)
+	defer cancel()
+
+	clientCtx := client.Context{Client: bc.bbnClient.RPCClient}
+	queryClient := btclctypes.NewQueryClient(clientCtx)
+
+	var response *btclctypes.QueryTipResponse
+	if err := retry.Do(func() error {
+		start := time.Now()
+		resp, err := queryClient.Tip(ctx, &btclctypes.QueryTipRequest{})
+		bc.metrics.ObserveQuery("btc_light_client_tip", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+		response = resp
+		return nil
+	}, RtyAtt, RtyDel, RtyErr, retry.OnRetry(func(n uint, err error) {
+		bc.logger.WithFields(logrus.Fields{
+			"attempt":      n + 1,
+			"max_attempts": RtyAttNum,
+			"error":        err,
+		}).Error("Failed to query babylon for the tip of btc light client")
+	})); err != nil {
+		return 0, err
+	}
+
+	if response.Header == nil {
+		return 0, ErrInvalidValueReceivedFromBabylonNode
+	}
+
+	return response.Header.Height, nil
+}
@@ -0,0 +1,76 @@
+package babylonclient
+
+import (
+	"encoding/hex"
+
+	sdkErr "cosmossdk.io/errors"
+	"github.com/cosmos/cosmos-sdk/client"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	pv "github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/sirupsen/logrus"
+)
+
+// recordBroadcast records result of sending msgs to babylon. Gas used and
+// account sequence are queried in the background after successful transaction,
+// so that sending is not slowed down by metrics.
+func (bc *BabylonController) recordBroadcast(msgs []sdk.Msg, resp *pv.RelayerTxResponse, err error) {
+	if bc.metrics == nil || len(msgs) == 0 {
+		return
+	}
+
+	msgType := sdk.MsgTypeURL(msgs[0])
+
+	switch {
+	case err != nil:
+		codespace, code, _ := sdkErr.ABCIInfo(err, false)
+		bc.metrics.ObserveBroadcast(msgType, codespace, code)
+	case resp != nil:
+		bc.metrics.ObserveBroadcast(msgType, resp.Codespace, resp.Code)
+
+		if resp.Code == 0 {
+			go bc.recordTxStats(msgType, resp.TxHash)
+		}
+	}
+}
+
+func (bc *BabylonController) recordTxStats(msgType string, txHash string) {
+	ctx, cancel := getQueryContext(bc.cfg.Timeout)
+	defer cancel()
+
+	hash, err := hex.DecodeString(txHash)
+
+	if err != nil {
+		bc.metrics.TxStatsQueryFailed()
+		return
+	}
+
+	result, err := bc.bbnClient.RPCClient.Tx(ctx, hash, false)
+
+	if err != nil {
+		bc.logger.WithFields(logrus.Fields{
+			"txHash": txHash,
+			"error":  err,
+		}).Debug("Failed to query gas used by babylon transaction")
+		bc.metrics.TxStatsQueryFailed()
+	} else {
+		bc.metrics.ObserveGasUsed(msgType, result.TxResult.GasUsed)
+	}
+
+	clientCtx := client.Context{Client: bc.bbnClient.RPCClient}
+	queryClient := authtypes.NewQueryClient(clientCtx)
+
+	account, err := queryClient.AccountInfo(ctx, &authtypes.QueryAccountInfoRequest{
+		Address: bc.getTxSigner(),
+	})
+
+	if err != nil {
+		bc.logger.WithFields(logrus.Fields{
+			"error": err,
+		}).Debug("Failed to query sequence of babylon account")
+		bc.metrics.TxStatsQueryFailed()
+		return
+	}
+
+	bc.metrics.SetAccountSequence(account.Info.Sequence)
+}
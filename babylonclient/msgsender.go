@@ -18,6 +18,21 @@ var (
 	ErrBabylonBtcLightClientNotReady = errors.New("babylon btc light client is not ready to receive delegation")
 )
 
+// BabylonTxError is returned when transaction was included in babylon block, but
+// its execution failed. It carries babylon response, so that it can be recorded.
+type BabylonTxError struct {
+	Response *pv.RelayerTxResponse
+	Err      error
+}
+
+func (e *BabylonTxError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BabylonTxError) Unwrap() error {
+	return e.Err
+}
+
 type sendDelegationRequest struct {
 	utils.Request[*pv.RelayerTxResponse]
 	dg                          *DelegationData
@@ -142,6 +157,8 @@ func (m *BabylonMsgSender) sendDelegationAsync(stakingTxHash *chainhash.Hash, re
 					"babylonBlockHeight": txResp.Height,
 					"babylonErrorCode":   txResp.Code,
 				}).Error("Invalid delegation data sent to babylon")
+
+				err = &BabylonTxError{Response: txResp, Err: err}
 			}
 
 			m.logger.WithFields(logrus.Fields{
@@ -176,6 +193,8 @@ func (m *BabylonMsgSender) sendUndelegationAsync(stakingTxHash *chainhash.Hash,
 					"babylonBlockHeight": txResp.Height,
 					"babylonErrorCode":   txResp.Code,
 				}).Error("Invalid delegation data sent to babylon")
+
+				err = &BabylonTxError{Response: txResp, Err: err}
 			}
 
 			m.logger.WithFields(logrus.Fields{
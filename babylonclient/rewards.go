@@ -0,0 +1,116 @@
+package babylonclient
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/avast/retry-go/v4"
+	incentivetypes "github.com/babylonchain/babylon/x/incentive/types"
+	"github.com/cosmos/cosmos-sdk/client"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	pv "github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/sirupsen/logrus"
+)
+
+// BTCDelegationRewardType is the stakeholder type under which Babylon accumulates
+// rewards of btc delegations
+const BTCDelegationRewardType = "btc_delegation"
+
+type RewardGauge struct {
+	Coins          sdk.Coins
+	WithdrawnCoins sdk.Coins
+}
+
+// Withdrawable returns rewards which were accumulated but not withdrawn yet
+func (g *RewardGauge) Withdrawable() sdk.Coins {
+	diff, hasNeg := g.Coins.SafeSub(g.WithdrawnCoins...)
+
+	if hasNeg {
+		return sdk.NewCoins()
+	}
+
+	return diff
+}
+
+// QueryRewardGauges returns rewards accumulated by given address, keyed by
+// stakeholder type
+func (bc *BabylonController) QueryRewardGauges(address sdk.AccAddress) (map[string]*RewardGauge, error) {
+	ctx, cancel := getQueryContext(bc.cfg.Timeout)
+	defer cancel()
+
+	clientCtx := client.Context{Client: bc.bbnClient.RPCClient}
+	queryClient := incentivetypes.NewQueryClient(clientCtx)
+
+	bech32Address := sdk.MustBech32ifyAddressBytes(bc.cfg.AccountPrefix, address)
+
+	var response *incentivetypes.QueryRewardGaugesResponse
+	if err := retry.Do(func() error {
+		start := time.Now()
+		resp, err := queryClient.RewardGauges(ctx, &incentivetypes.QueryRewardGaugesRequest{
+			Address: bech32Address,
+		})
+		bc.metrics.ObserveQuery("reward_gauges", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+		response = resp
+		return nil
+	}, RtyAtt, RtyDel, RtyErr, retry.OnRetry(func(n uint, err error) {
+		bc.logger.WithFields(logrus.Fields{
+			"attempt":      n + 1,
+			"max_attempts": RtyAttNum,
+			"address":      bech32Address,
+			"error":        err,
+		}).Error("Failed to query babylon for reward gauges")
+	})); err != nil {
+		return nil, err
+	}
+
+	gauges := make(map[string]*RewardGauge)
+
+	for stakeholderType, gauge := range response.RewardGauges {
+		if gauge == nil {
+			continue
+		}
+
+		gauges[stakeholderType] = &RewardGauge{
+			Coins:          gauge.Coins,
+			WithdrawnCoins: gauge.WithdrawnCoins,
+		}
+	}
+
+	return gauges, nil
+}
+
+// WithdrawRewards withdraws all rewards of given stakeholder type accumulated by
+// the staker babylon key. If recipient is not nil, withdrawn amount is transferred
+// to the recipient in the same transaction.
+func (bc *BabylonController) WithdrawRewards(
+	stakeholderType string,
+	recipient sdk.AccAddress,
+	amount sdk.Coins,
+) (*pv.RelayerTxResponse, error) {
+	signer := bc.getTxSigner()
+
+	msgs := []sdk.Msg{
+		&incentivetypes.MsgWithdrawReward{
+			Type:    stakeholderType,
+			Address: signer,
+		},
+	}
+
+	if recipient != nil {
+		if amount.IsZero() {
+			return nil, fmt.Errorf("cannot transfer empty rewards amount to recipient")
+		}
+
+		msgs = append(msgs, &banktypes.MsgSend{
+			FromAddress: signer,
+			ToAddress:   sdk.MustBech32ifyAddressBytes(bc.cfg.AccountPrefix, recipient),
+			Amount:      amount,
+		})
+	}
+
+	return bc.reliablySendMsgs(msgs)
+}
@@ -1,12 +1,16 @@
 package admin
 
 import (
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path"
 
 	babylonApp "github.com/babylonchain/babylon/app"
+	"github.com/babylonchain/btc-staker/cmd/stakercli/helpers"
 	"github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/cosmos/cosmos-sdk/crypto/hd"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	"github.com/cosmos/go-bip39"
@@ -23,6 +27,7 @@ var AdminCommands = []cli.Command{
 		Subcommands: []cli.Command{
 			dumpCfgCommand,
 			createCosmosKeyringCommand,
+			generateRequestSigningKeyCommand,
 		},
 	},
 }
@@ -201,3 +206,32 @@ var createCosmosKeyringCommand = cli.Command{
 	},
 	Action: createKeyRing,
 }
+
+type requestSigningKey struct {
+	PrivateKey string `json:"private_key"`
+	PublicKey  string `json:"public_key"`
+}
+
+var generateRequestSigningKeyCommand = cli.Command{
+	Name:      "generate-request-signing-key",
+	ShortName: "grsk",
+	Usage: "Generate key pair for signing of spend capable rpc requests. Public key should be added as " +
+		"requestsigning.clientkey to daemon config, private key should be provided to stakercli in " +
+		"STAKER_REQUEST_SIGNING_KEY environment variable.",
+	Action: generateRequestSigningKey,
+}
+
+func generateRequestSigningKey(_ *cli.Context) error {
+	key, err := btcec.NewPrivateKey()
+
+	if err != nil {
+		return err
+	}
+
+	helpers.PrintRespJSON(requestSigningKey{
+		PrivateKey: hex.EncodeToString(key.Serialize()),
+		PublicKey:  hex.EncodeToString(schnorr.SerializePubKey(key.PubKey())),
+	})
+
+	return nil
+}
@@ -1,11 +1,18 @@
 package daemon
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"fmt"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/babylonchain/btc-staker/cmd/stakercli/helpers"
 	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	service "github.com/babylonchain/btc-staker/stakerservice"
 	dc "github.com/babylonchain/btc-staker/stakerservice/client"
 	"github.com/urfave/cli"
 )
@@ -18,18 +25,101 @@ var DaemonCommands = []cli.Command{
 		Category:  "Daemon commands",
 		Subcommands: []cli.Command{
 			checkDaemonHealthCmd,
+			delegationStatesCmd,
 			listOutputsCmd,
+			consolidateOutputsCmd,
+			utxoBlocklistCmd,
+			utxoBlocklistAddCmd,
+			utxoBlocklistRemoveCmd,
+			frozenOutputsCmd,
+			freezeOutputCmd,
+			unfreezeOutputCmd,
+			walletTransactionsCmd,
+			pendingActionsCmd,
+			approveActionCmd,
+			rejectActionCmd,
 			babylonFinalityProvidersCmd,
+			babylonStakingParamsCmd,
+			feeEstimateCmd,
+			feeBudgetCmd,
+			feeAnalyticsCmd,
+			confirmationSlaCmd,
+			babylonRewardsCmd,
+			withdrawBabylonRewardsCmd,
 			stakeCmd,
+			stakeExternalCmd,
+			monitoredTransactionsCmd,
 			unstakeCmd,
 			stakingDetailsCmd,
+			stakingDetailsBatchCmd,
+			stakingScriptInfoCmd,
+			delegationGraphCmd,
+			exitTemplatesCmd,
+			computeSigHashesCmd,
+			generateMuSig2NonceCmd,
+			musig2NonceCmd,
+			stakingPresetsCmd,
+			setStakingPresetCmd,
+			deleteStakingPresetCmd,
+			proveOwnershipCmd,
+			verifyOwnershipProofCmd,
+			signMessageCmd,
+			proofOfReservesCmd,
+			verifyMessageCmd,
 			listStakingTransactionsCmd,
+			stakingStatusLightCmd,
+			streamStakingTransactionsCmd,
+			stakingSummaryCmd,
+			groupSummariesCmd,
+			setDelegationGroupCmd,
 			withdrawableTransactionsCmd,
+			searchStakingTransactionsCmd,
 			unbondCmd,
+			setUnbondingOverridesCmd,
+			setAutoWithdrawCmd,
+			unbondAllCmd,
+			bumpStakingFeeCmd,
+			exportReportCmd,
+			retryQueueCmd,
+			flushRetryQueueCmd,
+			scheduleOperationCmd,
+			cancelScheduledOperationCmd,
+			scheduledOperationsCmd,
+			retryBabylonCmd,
+			exportDelegationCmd,
+			overrideDelegationStateCmd,
+			purgeDelegationCmd,
+			annotateDelegationCmd,
+			auditLogCmd,
+			watchStakingTxCmd,
+			devUnbondingSigHashCmd,
+			devSubmitCovenantUnbondingSigsCmd,
+			devSignedUnbondingTxCmd,
+			installServiceCmd,
 		},
 	},
 }
 
+// commands which write their own output format
+var noOutputFormatCommands = map[string]struct{}{
+	streamStakingTransactionsCmd.Name: {},
+	exportReportCmd.Name:              {},
+	installServiceCmd.Name:            {},
+}
+
+func init() {
+	// every other daemon command can print its result either as json or as table
+	for i := range DaemonCommands[0].Subcommands {
+		cmd := &DaemonCommands[0].Subcommands[i]
+
+		if _, skip := noOutputFormatCommands[cmd.Name]; skip {
+			continue
+		}
+
+		cmd.Flags = append(cmd.Flags, helpers.OutputFormatFlag)
+	}
+}
+
 const (
 	stakingDaemonAddressFlag   = "daemon-address"
 	offsetFlag                 = "offset"
@@ -38,6 +128,58 @@ const (
 	stakingTransactionHashFlag = "staking-transaction-hash"
 	feeRateFlag                = "fee-rate"
 	stakerAddressFlag          = "staker-address"
+	destinationAddressFlag     = "destination-address"
+	maxUtxoValueFlag           = "max-utxo-value"
+	metadataFlag               = "metadata"
+	metadataFilterFlag         = "metadata-filter"
+	rewardTypeFlag             = "reward-type"
+	rewardRecipientFlag        = "recipient"
+	challengeFlag              = "challenge"
+	stakerPkFlag               = "staker-pk"
+	signatureFlag              = "signature"
+	messageFlag                = "message"
+	addressFlag                = "address"
+	fundingAddressFlag         = "funding-address"
+	operationFlag              = "operation"
+	requestIdFlag              = "request-id"
+	minConfirmationsFlag       = "min-confirmations"
+	forceFlag                  = "force"
+	fromStateFlag              = "from-state"
+	toStateFlag                = "to-state"
+	reasonFlag                 = "reason"
+	outputFileFlag             = "output-file"
+	intervalFlag               = "interval"
+	dryRunFlag                 = "dry-run"
+	outpointFlag               = "outpoint"
+	noteFlag                   = "note"
+	labelFlag                  = "label"
+	actionIdFlag               = "action-id"
+	atHeightFlag               = "at-height"
+	atTimeFlag                 = "at-time"
+	idFlag                     = "id"
+	groupFlag                  = "group"
+	inputFileFlag              = "input-file"
+	covenantPkFlag             = "covenant-pk"
+	covenantSigFlag            = "covenant-sig"
+	txHexFlag                  = "tx-hex"
+	sessionIdFlag              = "session-id"
+	signerAddressFlag          = "signer-address"
+	ttlFlag                    = "ttl"
+	presetFlag                 = "preset"
+	unbondingTimeFlag          = "unbonding-time"
+	presetNameFlag             = "name"
+	amountTierFlag             = "amount-tier"
+	maxFeeRateFlag             = "max-fee-rate"
+	gasLimitFlag               = "gas-limit"
+	feesFlag                   = "fees"
+	markSubmittedFlag          = "mark-submitted"
+	daysFlag                   = "days"
+	etagFlag                   = "etag"
+	queryFlag                  = "query"
+	minAmountFlag              = "min-amount"
+	maxAmountFlag              = "max-amount"
+	autoWithdrawFlag           = "auto-withdraw"
+	disableFlag                = "disable"
 )
 
 var (
@@ -58,6 +200,20 @@ var checkDaemonHealthCmd = cli.Command{
 	Action: checkHealth,
 }
 
+var delegationStatesCmd = cli.Command{
+	Name:      "delegation-states",
+	ShortName: "ds",
+	Usage:     "List canonical delegation states reported by staker daemon.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "Full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+	},
+	Action: delegationStates,
+}
+
 var listOutputsCmd = cli.Command{
 	Name:      "list-outputs",
 	ShortName: "lo",
@@ -72,6 +228,227 @@ var listOutputsCmd = cli.Command{
 	Action: listOutputs,
 }
 
+var consolidateOutputsCmd = cli.Command{
+	Name:      "consolidate-outputs",
+	ShortName: "co",
+	Usage:     "Sweeps small unspent outputs in connected wallet into one output.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     destinationAddressFlag,
+			Usage:    "wallet address which will receive consolidated funds",
+			Required: true,
+		},
+		cli.IntFlag{
+			Name:  maxUtxoValueFlag,
+			Usage: "outputs with value lower or equal to this value in satoshis will be consolidated. If not provided, value from daemon config is used",
+		},
+		cli.IntFlag{
+			Name:  feeRateFlag,
+			Usage: "fee rate to pay for consolidation tx in sats/kb",
+		},
+	},
+	Action: consolidateOutputs,
+}
+
+var utxoBlocklistCmd = cli.Command{
+	Name:      "utxo-blocklist",
+	ShortName: "ubl",
+	Usage:     "Lists outpoints and addresses which outputs are never used to fund transactions",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+	},
+	Action: utxoBlocklist,
+}
+
+var utxoBlocklistAddCmd = cli.Command{
+	Name:      "utxo-blocklist-add",
+	ShortName: "ubla",
+	Usage:     "Blocks outpoints and addresses, so that their outputs are never used to fund transactions",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringSliceFlag{
+			Name:  outpointFlag,
+			Usage: "Outpoint in format <txid>:<vout>, can be repeated",
+		},
+		cli.StringSliceFlag{
+			Name:  addressFlag,
+			Usage: "Address which outputs are blocked, can be repeated",
+		},
+		cli.StringFlag{
+			Name:     reasonFlag,
+			Usage:    "Reason of blocking",
+			Required: true,
+		},
+	},
+	Action: utxoBlocklistAdd,
+}
+
+var utxoBlocklistRemoveCmd = cli.Command{
+	Name:      "utxo-blocklist-remove",
+	ShortName: "ublr",
+	Usage:     "Unblocks outpoints and addresses blocked through rpc. Entries from daemon config can be only removed from the config",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringSliceFlag{
+			Name:  outpointFlag,
+			Usage: "Outpoint in format <txid>:<vout>, can be repeated",
+		},
+		cli.StringSliceFlag{
+			Name:  addressFlag,
+			Usage: "Address which outputs are unblocked, can be repeated",
+		},
+	},
+	Action: utxoBlocklistRemove,
+}
+
+var frozenOutputsCmd = cli.Command{
+	Name:      "frozen-outputs",
+	ShortName: "fo",
+	Usage:     "Lists wallet outputs frozen by the operator",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+	},
+	Action: frozenOutputs,
+}
+
+var walletTransactionsCmd = cli.Command{
+	Name:      "wallet-transactions",
+	ShortName: "wtx",
+	Usage:     "Lists most recent transactions in bitcoind wallet with label starting with given prefix. Addresses of staking and unbonding outputs are labeled babylon-staking:<staking_tx_hash> and babylon-unbonding:<staking_tx_hash>",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:  labelFlag,
+			Usage: "label prefix, by default transactions of all staking outputs are listed",
+		},
+		cli.IntFlag{
+			Name:  limitFlag,
+			Usage: "maximum number of returned transactions",
+			Value: 50,
+		},
+	},
+	Action: walletTransactions,
+}
+
+var freezeOutputCmd = cli.Command{
+	Name:      "freeze-output",
+	ShortName: "fro",
+	Usage:     "Freezes wallet output, so that it is not used to fund transactions until it is unfrozen",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     outpointFlag,
+			Usage:    "Outpoint in format <txid>:<vout>",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:  noteFlag,
+			Usage: "Purpose for which the output is reserved",
+		},
+	},
+	Action: freezeOutput,
+}
+
+var unfreezeOutputCmd = cli.Command{
+	Name:      "unfreeze-output",
+	ShortName: "ufro",
+	Usage:     "Makes frozen wallet output available for funding transactions again",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     outpointFlag,
+			Usage:    "Outpoint in format <txid>:<vout>",
+			Required: true,
+		},
+	},
+	Action: unfreezeOutput,
+}
+
+var pendingActionsCmd = cli.Command{
+	Name:      "pending-actions",
+	ShortName: "pa",
+	Usage:     "Lists spend actions waiting for approval of second operator. Requires operator token in STAKER_OPERATOR_TOKEN environment variable",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+	},
+	Action: pendingActions,
+}
+
+var approveActionCmd = cli.Command{
+	Name:      "approve-action",
+	ShortName: "aa",
+	Usage:     "Approves and executes spend action requested by another operator. Requires operator token in STAKER_OPERATOR_TOKEN environment variable",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     actionIdFlag,
+			Usage:    "Id of the pending action",
+			Required: true,
+		},
+	},
+	Action: approveAction,
+}
+
+var rejectActionCmd = cli.Command{
+	Name:      "reject-action",
+	ShortName: "ra",
+	Usage:     "Rejects pending spend action. Requires operator token in STAKER_OPERATOR_TOKEN environment variable",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     actionIdFlag,
+			Usage:    "Id of the pending action",
+			Required: true,
+		},
+	},
+	Action: rejectAction,
+}
+
 var babylonFinalityProvidersCmd = cli.Command{
 	Name:      "babylon-finality-providers",
 	ShortName: "bfp",
@@ -96,63 +473,104 @@ var babylonFinalityProvidersCmd = cli.Command{
 	Action: babylonFinalityProviders,
 }
 
-var stakeCmd = cli.Command{
-	Name:      "stake",
-	ShortName: "st",
-	Usage:     "Stake an amount of BTC to Babylon",
+var babylonStakingParamsCmd = cli.Command{
+	Name:      "babylon-staking-params",
+	ShortName: "bsp",
+	Usage:     "Show current staking parameters of Babylon chain",
 	Flags: []cli.Flag{
 		cli.StringFlag{
 			Name:  stakingDaemonAddressFlag,
 			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
 			Value: defaultStakingDaemonAddress,
 		},
+	},
+	Action: babylonStakingParams,
+}
+
+var feeEstimateCmd = cli.Command{
+	Name:      "fee-estimate",
+	ShortName: "fe",
+	Usage:     "Show fee rate which would be used for new staking transaction",
+	Flags: []cli.Flag{
 		cli.StringFlag{
-			Name:     stakerAddressFlag,
-			Usage:    "BTC address of the staker in hex",
-			Required: true,
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
 		},
-		cli.Int64Flag{
-			Name:     helpers.StakingAmountFlag,
-			Usage:    "Staking amount in satoshis",
-			Required: true,
+	},
+	Action: feeEstimate,
+}
+
+var feeBudgetCmd = cli.Command{
+	Name:      "fee-budget",
+	ShortName: "fb",
+	Usage:     "Show btc fees spent by the daemon in last 24 hours and last 7 days, together with configured budgets",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
 		},
-		cli.StringSliceFlag{
-			Name:     fpPksFlag,
-			Usage:    "BTC public keys of the finality providers in hex",
-			Required: true,
+	},
+	Action: feeBudget,
+}
+
+var feeAnalyticsCmd = cli.Command{
+	Name:      "fee-analytics",
+	ShortName: "fa",
+	Usage:     "Show fee rates and confirmation times of transactions sent by the daemon per day and transaction kind",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
 		},
-		cli.Int64Flag{
-			Name:     helpers.StakingTimeBlocksFlag,
-			Usage:    "Staking time in BTC blocks",
-			Required: true,
+		cli.IntFlag{
+			Name:  daysFlag,
+			Usage: "Number of days, including today, covered by the statistics",
+			Value: 30,
 		},
 	},
-	Action: stake,
+	Action: feeAnalytics,
 }
 
-var unstakeCmd = cli.Command{
-	Name:      "unstake",
-	ShortName: "ust",
-	Usage:     "Spends staking transaction and sends funds back to staker; this can only be done after timelock of staking transaction expires",
+var confirmationSlaCmd = cli.Command{
+	Name:      "confirmation-sla",
+	ShortName: "csla",
+	Usage:     "Show times to first confirmation of transactions sent by the daemon and transactions which exceeded confirmation target",
 	Flags: []cli.Flag{
 		cli.StringFlag{
 			Name:  stakingDaemonAddressFlag,
 			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
 			Value: defaultStakingDaemonAddress,
 		},
+		cli.IntFlag{
+			Name:  daysFlag,
+			Usage: "Number of days covered by the summary",
+			Value: 7,
+		},
+	},
+	Action: confirmationSla,
+}
+
+var babylonRewardsCmd = cli.Command{
+	Name:      "babylon-rewards",
+	ShortName: "br",
+	Usage:     "Show staking rewards accumulated on Babylon chain by the staker babylon key",
+	Flags: []cli.Flag{
 		cli.StringFlag{
-			Name:     stakingTransactionHashFlag,
-			Usage:    "Hash of original staking transaction in bitcoin hex format",
-			Required: true,
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
 		},
 	},
-	Action: unstake,
+	Action: babylonRewards,
 }
 
-var unbondCmd = cli.Command{
-	Name:      "unbond",
-	ShortName: "ubd",
-	Usage:     "initiates unbonding flow: build unbonding tx, send to babylon, wait for signatures, and send unbonding tx to bitcoin",
+var withdrawBabylonRewardsCmd = cli.Command{
+	Name:      "withdraw-babylon-rewards",
+	ShortName: "wbr",
+	Usage:     "Withdraw staking rewards accumulated on Babylon chain",
 	Flags: []cli.Flag{
 		cli.StringFlag{
 			Name:  stakingDaemonAddressFlag,
@@ -160,22 +578,21 @@ var unbondCmd = cli.Command{
 			Value: defaultStakingDaemonAddress,
 		},
 		cli.StringFlag{
-			Name:     stakingTransactionHashFlag,
-			Usage:    "Hash of original staking transaction in bitcoin hex format",
-			Required: true,
+			Name:  rewardTypeFlag,
+			Usage: "type of the rewards to withdraw. If not provided, btc delegation rewards are withdrawn",
 		},
-		cli.IntFlag{
-			Name:  feeRateFlag,
-			Usage: "fee rate to pay for unbonding tx in sats/kb",
+		cli.StringFlag{
+			Name:  rewardRecipientFlag,
+			Usage: "babylon address which should receive withdrawn rewards. If not provided, recipient from daemon config is used",
 		},
 	},
-	Action: unbond,
+	Action: withdrawBabylonRewards,
 }
 
-var stakingDetailsCmd = cli.Command{
-	Name:      "staking-details",
-	ShortName: "sds",
-	Usage:     "Displays details of staking transaction with given hash",
+var stakeCmd = cli.Command{
+	Name:      "stake",
+	ShortName: "st",
+	Usage:     "Stake an amount of BTC to Babylon",
 	Flags: []cli.Flag{
 		cli.StringFlag{
 			Name:  stakingDaemonAddressFlag,
@@ -183,63 +600,2515 @@ var stakingDetailsCmd = cli.Command{
 			Value: defaultStakingDaemonAddress,
 		},
 		cli.StringFlag{
-			Name:     stakingTransactionHashFlag,
-			Usage:    "Hash of original staking transaction in bitcoin hex format",
+			Name:     stakerAddressFlag,
+			Usage:    "BTC address of the staker in hex",
 			Required: true,
 		},
+		cli.Int64Flag{
+			Name:  helpers.StakingAmountFlag,
+			Usage: "Staking amount in satoshis. Can be omitted with --preset which has single amount tier",
+		},
+		cli.StringSliceFlag{
+			Name:  fpPksFlag,
+			Usage: "BTC public keys of the finality providers in hex. Required unless --preset is provided",
+		},
+		cli.Int64Flag{
+			Name:  helpers.StakingTimeBlocksFlag,
+			Usage: "Staking time in BTC blocks. Required unless --preset is provided",
+		},
+		cli.StringFlag{
+			Name:  presetFlag,
+			Usage: "Name of the staking preset which defines finality providers, staking time, allowed amounts and max fee rate",
+		},
+		cli.StringSliceFlag{
+			Name:  metadataFlag,
+			Usage: "Metadata label attached to the delegation in format key=value, can be repeated",
+		},
+		cli.StringFlag{
+			Name:  requestIdFlag,
+			Usage: "Id used to correlate daemon logs of the whole staking process. Generated by the daemon if not provided",
+		},
+		cli.IntFlag{
+			Name:  minConfirmationsFlag,
+			Usage: "Minimum confirmations of wallet outputs used to fund staking transaction, 0 allows unconfirmed outputs. Daemon minfundingconfirmations option is used if not provided",
+		},
+		cli.BoolFlag{
+			Name:  autoWithdrawFlag,
+			Usage: "Withdraw staked funds automatically once timelock of staking or unbonding output expires",
+		},
 	},
-	Action: stakingDetails,
+	Action: stake,
+}
+
+var stakeExternalCmd = cli.Command{
+	Name:      "stake-external",
+	ShortName: "ste",
+	Usage: "Stake an amount of BTC funded from the connected wallet on behalf of an external staker public key. " +
+		"WARNING: staked funds can only be spent by the owner of the external key, who also needs to register " +
+		"the delegation on Babylon. Daemon only tracks such delegation in watch only mode",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     fundingAddressFlag,
+			Usage:    "BTC address of the connected wallet which funds staking transaction",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     stakerPkFlag,
+			Usage:    "External staker public key in schnorr format (32 byte) in hex",
+			Required: true,
+		},
+		cli.Int64Flag{
+			Name:     helpers.StakingAmountFlag,
+			Usage:    "Staking amount in satoshis",
+			Required: true,
+		},
+		cli.StringSliceFlag{
+			Name:     fpPksFlag,
+			Usage:    "BTC public keys of the finality providers in hex",
+			Required: true,
+		},
+		cli.Int64Flag{
+			Name:     helpers.StakingTimeBlocksFlag,
+			Usage:    "Staking time in BTC blocks",
+			Required: true,
+		},
+		cli.StringSliceFlag{
+			Name:  metadataFlag,
+			Usage: "Metadata label attached to the delegation in format key=value, can be repeated",
+		},
+		cli.StringFlag{
+			Name:  requestIdFlag,
+			Usage: "Id used to correlate daemon logs of the whole staking process. Generated by the daemon if not provided",
+		},
+		cli.IntFlag{
+			Name:  minConfirmationsFlag,
+			Usage: "Minimum confirmations of wallet outputs used to fund staking transaction, 0 allows unconfirmed outputs. Daemon minfundingconfirmations option is used if not provided",
+		},
+	},
+	Action: stakeExternal,
+}
+
+var monitoredTransactionsCmd = cli.Command{
+	Name:      "monitored-transactions",
+	ShortName: "mt",
+	Usage:     "Displays state of staking transactions tracked by daemon running in monitoring mode",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:  stakingTransactionHashFlag,
+			Usage: "Hash of monitored staking transaction in bitcoin hex format. If not provided, all monitored transactions are displayed",
+		},
+	},
+	Action: monitoredTransactions,
+}
+
+var retryQueueCmd = cli.Command{
+	Name:      "retry-queue",
+	ShortName: "rq",
+	Usage:     "Displays failed operations waiting in retry queue",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+	},
+	Action: retryQueue,
+}
+
+var flushRetryQueueCmd = cli.Command{
+	Name:      "flush-retry-queue",
+	ShortName: "frq",
+	Usage:     "Retries all operations waiting in retry queue immediately",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:  operationFlag,
+			Usage: "Flush only operations of given type {SEND_DELEGATION_TO_BABYLON, SEND_UNBONDING_TX_TO_BTC}",
+		},
+	},
+	Action: flushRetryQueue,
+}
+
+var scheduleOperationCmd = cli.Command{
+	Name:      "schedule-operation",
+	ShortName: "sco",
+	Usage:     "Schedules unbonding or withdrawal of staking transaction for execution at given btc block height or time",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     operationFlag,
+			Usage:    "Scheduled operation {SCHEDULED_UNBOND, SCHEDULED_WITHDRAW}",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of original staking transaction in bitcoin hex format",
+			Required: true,
+		},
+		cli.IntFlag{
+			Name:  atHeightFlag,
+			Usage: "Btc block height at which operation is executed",
+		},
+		cli.StringFlag{
+			Name:  atTimeFlag,
+			Usage: "Time at which operation is executed, in RFC3339 format",
+		},
+		cli.StringFlag{
+			Name:  noteFlag,
+			Usage: "Note describing why operation was scheduled",
+		},
+	},
+	Action: scheduleOperation,
+}
+
+var cancelScheduledOperationCmd = cli.Command{
+	Name:      "cancel-scheduled-operation",
+	ShortName: "cso",
+	Usage:     "Cancels operation which was not executed yet",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     idFlag,
+			Usage:    "Id of the scheduled operation",
+			Required: true,
+		},
+	},
+	Action: cancelScheduledOperation,
+}
+
+var scheduledOperationsCmd = cli.Command{
+	Name:      "scheduled-operations",
+	ShortName: "sops",
+	Usage:     "Lists operations waiting for execution",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+	},
+	Action: scheduledOperations,
+}
+
+var retryBabylonCmd = cli.Command{
+	Name:      "retry-babylon",
+	ShortName: "rb",
+	Usage:     "Forces re-submission of delegation of given staking transaction to Babylon",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of original staking transaction in bitcoin hex format",
+			Required: true,
+		},
+		cli.BoolFlag{
+			Name:  forceFlag,
+			Usage: "Re-submit delegation also if daemon or Babylon consider it already delivered",
+		},
+	},
+	Action: retryBabylon,
+}
+
+var exportDelegationCmd = cli.Command{
+	Name:      "export-delegation",
+	ShortName: "ed",
+	Usage:     "Renders delegation of confirmed staking transaction as Babylon MsgCreateBTCDelegation for manual submission with babylond",
+	Description: "Unsigned transaction must be signed with the Babylon key of the daemon (babylond tx sign) and " +
+		"broadcast (babylond tx broadcast). With --mark-submitted, delegation is moved to SENT_TO_BABYLON and the " +
+		"daemon waits for covenant signatures of the exported unbonding transaction, so only the last marked " +
+		"export may be submitted.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of original staking transaction in bitcoin hex format",
+			Required: true,
+		},
+		cli.IntFlag{
+			Name:  gasLimitFlag,
+			Usage: "Gas limit of the transaction, 400000 if not set",
+		},
+		cli.StringFlag{
+			Name:  feesFlag,
+			Usage: "Fees of the transaction e.g 1000ubbn, computed from configured gas prices if not set",
+		},
+		cli.BoolFlag{
+			Name:  markSubmittedFlag,
+			Usage: "Move delegation to SENT_TO_BABYLON, as it will be submitted manually",
+		},
+		cli.StringFlag{
+			Name:  outputFileFlag,
+			Usage: "Write unsigned transaction to this file instead of printing the whole response",
+		},
+	},
+	Action: exportDelegation,
+}
+
+var overrideDelegationStateCmd = cli.Command{
+	Name:      "override-delegation-state",
+	ShortName: "ods",
+	Usage:     "Forces state of the delegation, when actions performed outside of the daemon desynchronized it. Only selected state changes are allowed and every change is recorded in audit log",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of original staking transaction in bitcoin hex format",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     fromStateFlag,
+			Usage:    "Current state of the delegation, e.g SENT_TO_BABYLON",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     toStateFlag,
+			Usage:    "New state of the delegation, e.g CONFIRMED_ON_BTC",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     reasonFlag,
+			Usage:    "Reason of the override, recorded in audit log",
+			Required: true,
+		},
+	},
+	Action: overrideDelegationState,
+}
+
+var purgeDelegationCmd = cli.Command{
+	Name:      "purge-delegation",
+	ShortName: "pd",
+	Usage:     "Permanently removes record of the delegation and its metadata from the daemon database. Only delegations in SPENT_ON_BTC state can be purged",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of original staking transaction in bitcoin hex format",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     reasonFlag,
+			Usage:    "Reason of the purge, recorded in audit log",
+			Required: true,
+		},
+	},
+	Action: purgeDelegation,
+}
+
+var annotateDelegationCmd = cli.Command{
+	Name:      "annotate-delegation",
+	ShortName: "ad",
+	Usage:     "Attaches timestamped note to the delegation, e.g. manual action performed outside of the daemon. Notes are returned in staking details and recorded in audit log",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of original staking transaction in bitcoin hex format",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     noteFlag,
+			Usage:    "Text of the note",
+			Required: true,
+		},
+	},
+	Action: annotateDelegation,
+}
+
+var auditLogCmd = cli.Command{
+	Name:      "audit-log",
+	ShortName: "al",
+	Usage:     "Lists manual operator actions recorded by the daemon",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:  stakingTransactionHashFlag,
+			Usage: "List only actions performed on given staking transaction",
+		},
+	},
+	Action: auditLog,
+}
+
+var unstakeCmd = cli.Command{
+	Name:      "unstake",
+	ShortName: "ust",
+	Usage:     "Spends staking transaction and sends funds back to staker; this can only be done after timelock of staking transaction expires",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of original staking transaction in bitcoin hex format",
+			Required: true,
+		},
+	},
+	Action: unstake,
+}
+
+var unbondCmd = cli.Command{
+	Name:      "unbond",
+	ShortName: "ubd",
+	Usage:     "initiates unbonding flow: build unbonding tx, send to babylon, wait for signatures, and send unbonding tx to bitcoin",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of original staking transaction in bitcoin hex format",
+			Required: true,
+		},
+		cli.IntFlag{
+			Name:  feeRateFlag,
+			Usage: "fee rate to pay for unbonding tx in sats/kb",
+		},
+		cli.StringFlag{
+			Name:  destinationAddressFlag,
+			Usage: "BTC address which receives funds withdrawn from the unbonding output. If not provided, funds are withdrawn to staker address",
+		},
+	},
+	Action: unbond,
+}
+
+var setUnbondingOverridesCmd = cli.Command{
+	Name:      "set-unbonding-overrides",
+	ShortName: "suo",
+	Usage: "Overrides unbonding time and fee rate of unbonding transaction of the delegation. Unbonding transaction is " +
+		"pre-signed when delegation is sent to Babylon, so overrides can be set only before that",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of original staking transaction in bitcoin hex format",
+			Required: true,
+		},
+		cli.Int64Flag{
+			Name:  unbondingTimeFlag,
+			Usage: "Unbonding time in BTC blocks, must be greater than min unbonding time of Babylon. 0 restores the default",
+		},
+		cli.Int64Flag{
+			Name:  feeRateFlag,
+			Usage: "fee rate of unbonding tx in sats/kb. 0 restores estimated fee rate",
+		},
+	},
+	Action: setUnbondingOverrides,
+}
+
+var setAutoWithdrawCmd = cli.Command{
+	Name:      "set-auto-withdraw",
+	ShortName: "saw",
+	Usage: "Enables automatic withdrawal of the delegation once timelock of staking or unbonding output expires. " +
+		"With --disable cancels automatic withdrawal which was not executed yet",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of original staking transaction in bitcoin hex format",
+			Required: true,
+		},
+		cli.BoolFlag{
+			Name:  disableFlag,
+			Usage: "Disable automatic withdrawal",
+		},
+	},
+	Action: setAutoWithdraw,
+}
+
+var bumpStakingFeeCmd = cli.Command{
+	Name:      "bump-staking-fee",
+	ShortName: "bsf",
+	Usage:     "Bumps fee of unconfirmed staking transaction by sending child transaction spending its change (CPFP). Uses package relay if supported by the node",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of unconfirmed staking transaction in bitcoin hex format",
+			Required: true,
+		},
+		cli.IntFlag{
+			Name:  feeRateFlag,
+			Usage: "fee rate which staking and child transaction should pay together in sats/kb. If not provided, estimated fee rate is used",
+		},
+	},
+	Action: bumpStakingFee,
+}
+
+var unbondAllCmd = cli.Command{
+	Name:      "unbond-all",
+	ShortName: "uba",
+	Usage:     "Initiates unbonding of all active delegations, one by one in the background. Meant for incident response when all positions must be exited quickly",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.DurationFlag{
+			Name:  intervalFlag,
+			Usage: "Time to wait between starting unbonding of consecutive delegations",
+			Value: time.Second,
+		},
+		cli.BoolFlag{
+			Name:  dryRunFlag,
+			Usage: "Only list delegations which would be unbonded",
+		},
+	},
+	Action: unbondAll,
+}
+
+var stakingDetailsCmd = cli.Command{
+	Name:      "staking-details",
+	ShortName: "sds",
+	Usage:     "Displays details of staking transaction with given hash",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of original staking transaction in bitcoin hex format",
+			Required: true,
+		},
+	},
+	Action: stakingDetails,
+}
+
+var stakingDetailsBatchCmd = cli.Command{
+	Name:      "staking-details-batch",
+	ShortName: "sdb",
+	Usage:     "Displays details of multiple staking transactions in one call. Transactions which cannot be found are reported with an error",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringSliceFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of original staking transaction in bitcoin hex format, can be repeated",
+			Required: true,
+		},
+	},
+	Action: stakingDetailsBatch,
+}
+
+var watchStakingTxCmd = cli.Command{
+	Name:      "watch-staking-tx",
+	ShortName: "wst",
+	Usage:     "Import staking transaction created outside of the daemon, so that it is tracked and delegated to Babylon",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     inputFileFlag,
+			Usage:    "Path to json file with parameters of watch_staking_tx rpc call",
+			Required: true,
+		},
+	},
+	Action: watchStakingTx,
+}
+
+var devUnbondingSigHashCmd = cli.Command{
+	Name:      "dev-unbonding-sighash",
+	ShortName: "dus",
+	Usage:     "Displays sighash of unbonding transaction which covenant members sign. Requires daemon with enabled dev api",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of original staking transaction in bitcoin hex format",
+			Required: true,
+		},
+	},
+	Action: devUnbondingSigHash,
+}
+
+var devSubmitCovenantUnbondingSigsCmd = cli.Command{
+	Name:      "dev-submit-covenant-unbonding-sigs",
+	ShortName: "dscus",
+	Usage:     "Submits covenant signatures of unbonding transaction. Requires daemon with enabled dev api",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of original staking transaction in bitcoin hex format",
+			Required: true,
+		},
+		cli.StringSliceFlag{
+			Name:     covenantPkFlag,
+			Usage:    "Hex encoded BIP340 public key of covenant member, can be repeated",
+			Required: true,
+		},
+		cli.StringSliceFlag{
+			Name:     covenantSigFlag,
+			Usage:    "Hex encoded 64 byte BIP340 signature of covenant member over unbonding sighash, in the same order as covenant public keys, can be repeated",
+			Required: true,
+		},
+	},
+	Action: devSubmitCovenantUnbondingSigs,
+}
+
+var devSignedUnbondingTxCmd = cli.Command{
+	Name:      "dev-signed-unbonding-tx",
+	ShortName: "dsut",
+	Usage:     "Displays fully signed unbonding transaction. Requires daemon with enabled dev api",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of original staking transaction in bitcoin hex format",
+			Required: true,
+		},
+	},
+	Action: devSignedUnbondingTx,
+}
+
+var stakingScriptInfoCmd = cli.Command{
+	Name:      "staking-script-info",
+	ShortName: "ssi",
+	Usage:     "Displays script leaves, merkle proofs and control blocks of all spend paths of the staking output",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of original staking transaction in bitcoin hex format",
+			Required: true,
+		},
+	},
+	Action: stakingScriptInfo,
+}
+
+var delegationGraphCmd = cli.Command{
+	Name:      "delegation-graph",
+	ShortName: "dgr",
+	Usage:     "Displays transactions of the delegation, from funding inputs to withdrawal or slashing, and how they spend each other",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of original staking transaction in bitcoin hex format",
+			Required: true,
+		},
+	},
+	Action: delegationGraph,
+}
+
+var exitTemplatesCmd = cli.Command{
+	Name:      "exit-templates",
+	ShortName: "et",
+	Usage:     "Builds unsigned unbonding and timelock spend transactions of the delegation, which can be signed offline in case staker daemon is not available",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of original staking transaction in bitcoin hex format",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:  outputFileFlag,
+			Usage: "File to which templates are written instead of standard output",
+		},
+	},
+	Action: exitTemplates,
+}
+
+var computeSigHashesCmd = cli.Command{
+	Name:      "compute-sighashes",
+	ShortName: "csh",
+	Usage:     "Computes digests which external signer must sign for every input and spend path of the transaction",
+	Description: "Transaction may spend wallet outputs (e.g staking transaction), or staking and unbonding outputs " +
+		"of tracked delegations (e.g unbonding or withdrawal transaction). Unbonding outputs are only recognized " +
+		"for the delegation given by --staking-transaction-hash.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     txHexFlag,
+			Usage:    "Hex encoded unsigned transaction",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:  stakingTransactionHashFlag,
+			Usage: "Hash of staking transaction of the delegation whose unbonding output is spent by the transaction",
+		},
+	},
+	Action: computeSigHashes,
+}
+
+var generateMuSig2NonceCmd = cli.Command{
+	Name:      "generate-musig2-nonce",
+	ShortName: "gmn",
+	Usage:     "Generates MuSig2 nonce of the signer key for new signing session and returns its public nonce",
+	Description: "Secret nonce is persisted by the daemon and can be used for one signature only, before the nonce " +
+		"expires. Session ids can't be reused.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     sessionIdFlag,
+			Usage:    "Unique id of the signing session",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     signerAddressFlag,
+			Usage:    "Wallet address whose key signs in the session",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:  messageFlag,
+			Usage: "Optional hex encoded 32 byte message to be signed, mixed into nonce generation",
+		},
+		cli.DurationFlag{
+			Name:  ttlFlag,
+			Usage: "Time after which nonce expires",
+			Value: time.Hour,
+		},
+	},
+	Action: generateMuSig2Nonce,
+}
+
+var stakingPresetsCmd = cli.Command{
+	Name:      "staking-presets",
+	ShortName: "sp",
+	Usage:     "Lists staking presets defined in config or through rpc",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+	},
+	Action: stakingPresets,
+}
+
+var setStakingPresetCmd = cli.Command{
+	Name:      "set-staking-preset",
+	ShortName: "ssp",
+	Usage:     "Creates staking preset or replaces preset created earlier through rpc",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     presetNameFlag,
+			Usage:    "Name of the preset, at most 64 characters from [A-Za-z0-9_-]",
+			Required: true,
+		},
+		cli.StringSliceFlag{
+			Name:     fpPksFlag,
+			Usage:    "BTC public keys of the finality providers in hex",
+			Required: true,
+		},
+		cli.Int64Flag{
+			Name:     helpers.StakingTimeBlocksFlag,
+			Usage:    "Staking time in BTC blocks",
+			Required: true,
+		},
+		cli.Int64SliceFlag{
+			Name:  amountTierFlag,
+			Usage: "Allowed staking amount in satoshis, can be repeated. If not provided, any amount is allowed",
+		},
+		cli.Int64Flag{
+			Name:  maxFeeRateFlag,
+			Usage: "Max fee rate in sat/vbyte, staking with the preset is rejected when estimated fee rate is higher. 0 means no limit",
+		},
+	},
+	Action: setStakingPreset,
+}
+
+var deleteStakingPresetCmd = cli.Command{
+	Name:      "delete-staking-preset",
+	ShortName: "dsp",
+	Usage:     "Deletes staking preset created through rpc",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     presetNameFlag,
+			Usage:    "Name of the preset",
+			Required: true,
+		},
+	},
+	Action: deleteStakingPreset,
+}
+
+var musig2NonceCmd = cli.Command{
+	Name:      "musig2-nonce",
+	ShortName: "mn",
+	Usage:     "Displays public nonce and status of MuSig2 signing session",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     sessionIdFlag,
+			Usage:    "Id of the signing session",
+			Required: true,
+		},
+	},
+	Action: musig2Nonce,
+}
+
+var proveOwnershipCmd = cli.Command{
+	Name:      "prove-ownership",
+	ShortName: "po",
+	Usage:     "Signs provided challenge and staking transaction hash with the staker key, proving ownership of the delegation",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of original staking transaction in bitcoin hex format",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     challengeFlag,
+			Usage:    "Challenge provided by the verifying party",
+			Required: true,
+		},
+	},
+	Action: proveOwnership,
+}
+
+var verifyOwnershipProofCmd = cli.Command{
+	Name:      "verify-ownership-proof",
+	ShortName: "vop",
+	Usage:     "Verifies delegation ownership proof",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of original staking transaction in bitcoin hex format",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     stakerPkFlag,
+			Usage:    "Staker public key in schnorr format (32 byte) in hex",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     challengeFlag,
+			Usage:    "Challenge which was signed",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     signatureFlag,
+			Usage:    "Schnorr signature in hex",
+			Required: true,
+		},
+	},
+	Action: verifyOwnershipProof,
+}
+
+var signMessageCmd = cli.Command{
+	Name:      "sign-message",
+	ShortName: "sm",
+	Usage:     "Signs message with the key controlling staker address, producing BIP-322 simple signature",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     stakerAddressFlag,
+			Usage:    "BTC address of the staker. Only p2wpkh and p2tr addresses are supported",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     messageFlag,
+			Usage:    "Message to sign",
+			Required: true,
+		},
+	},
+	Action: signMessage,
+}
+
+var proofOfReservesCmd = cli.Command{
+	Name:      "proof-of-reserves",
+	ShortName: "por",
+	Usage:     "Creates proof of reserves report of funds locked by staker keys controlled by the wallet",
+	Description: "Report lists staking and unbonding outputs with data needed to derive their scripts, and BIP-322 " +
+		"simple signature of the message by every staker address. It can be verified offline by " +
+		"stakercli transaction verify-proof-of-reserves.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     messageFlag,
+			Usage:    "Message signed by every staker key, usually challenge provided by the auditor",
+			Required: true,
+		},
+	},
+	Action: proofOfReserves,
+}
+
+var verifyMessageCmd = cli.Command{
+	Name:      "verify-message",
+	ShortName: "vm",
+	Usage:     "Verifies BIP-322 simple signature of the message",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:     addressFlag,
+			Usage:    "BTC address which signed the message",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     messageFlag,
+			Usage:    "Message which was signed",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     signatureFlag,
+			Usage:    "BIP-322 simple signature in base64",
+			Required: true,
+		},
+	},
+	Action: verifyMessage,
+}
+
+var listStakingTransactionsCmd = cli.Command{
+	Name:      "list-staking-transactions",
+	ShortName: "lst",
+	Usage:     "List current staking transactions in db",
+	Flags: append([]cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.IntFlag{
+			Name:  offsetFlag,
+			Usage: "offset of the first transactions to return",
+			Value: 0,
+		},
+		cli.IntFlag{
+			Name:  limitFlag,
+			Usage: "maximum number of transactions to return",
+			Value: 100,
+		},
+		cli.StringSliceFlag{
+			Name:  metadataFilterFlag,
+			Usage: "Return only transactions with given metadata label in format key=value, can be repeated",
+		},
+		cli.StringFlag{
+			Name:  groupFlag,
+			Usage: "Return only transactions assigned to given group",
+		},
+	}, timeFilterFlags...),
+	Action: listStakingTransactions,
+}
+
+var stakingStatusLightCmd = cli.Command{
+	Name:      "staking-status-light",
+	ShortName: "ssl",
+	Usage:     "Show state, amount, finality providers and blocks to maturity of staking transactions",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.IntFlag{
+			Name:  offsetFlag,
+			Usage: "offset of the first transactions to return",
+			Value: 0,
+		},
+		cli.IntFlag{
+			Name:  limitFlag,
+			Usage: "maximum number of transactions to return",
+			Value: 100,
+		},
+		cli.StringFlag{
+			Name:  etagFlag,
+			Usage: "etag of previously returned status, transactions are omitted if status did not change",
+		},
+	},
+	Action: stakingStatusLight,
+}
+
+var streamStakingTransactionsCmd = cli.Command{
+	Name:      "stream-staking-transactions",
+	ShortName: "sst",
+	Usage:     "Stream all staking transactions in db as newline delimited json, suitable for exporting large number of transactions",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.Uint64Flag{
+			Name:  offsetFlag,
+			Usage: "index of the transaction after which stream starts, allows resuming interrupted stream",
+			Value: 0,
+		},
+		cli.StringSliceFlag{
+			Name:  metadataFilterFlag,
+			Usage: "Return only transactions with given metadata label in format key=value, can be repeated",
+		},
+	},
+	Action: streamStakingTransactions,
+}
+
+var stakingSummaryCmd = cli.Command{
+	Name:      "staking-summary",
+	ShortName: "ss",
+	Usage:     "Show summary of staked amounts, paid fees and number of staking transactions per state",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:  groupFlag,
+			Usage: "Summarize only transactions assigned to given group",
+		},
+	},
+	Action: stakingSummary,
+}
+
+var groupSummariesCmd = cli.Command{
+	Name:      "group-summaries",
+	ShortName: "gs",
+	Usage:     "Show staking summary of each delegation group. Delegations without group are summarized under empty group name",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+	},
+	Action: groupSummaries,
+}
+
+var setDelegationGroupCmd = cli.Command{
+	Name:      "set-delegation-group",
+	ShortName: "sdg",
+	Usage:     "Assign delegations to a group (portfolio). Empty group removes delegations from their group",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringSliceFlag{
+			Name:     stakingTransactionHashFlag,
+			Usage:    "Hash of the staking transaction to assign, can be repeated",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:  groupFlag,
+			Usage: "Name of the group, may contain letters, digits, '.', '_' and '-'",
+		},
+	},
+	Action: setDelegationGroup,
+}
+
+var withdrawableTransactionsCmd = cli.Command{
+	Name:      "withdrawable-transactions",
+	ShortName: "wt",
+	Usage:     "List current tranactions that can be withdrawn i.e funds can be transferred back to staker address",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.IntFlag{
+			Name:  offsetFlag,
+			Usage: "offset of the first transactions to return",
+			Value: 0,
+		},
+		cli.IntFlag{
+			Name:  limitFlag,
+			Usage: "maximum number of transactions to return",
+			Value: 100,
+		},
+	},
+	Action: withdrawableTransactions,
+}
+
+var searchStakingTransactionsCmd = cli.Command{
+	Name:      "search-staking-transactions",
+	ShortName: "sts",
+	Usage:     "Search staking transactions by tx hash prefix, metadata, group, finality provider key prefix or alias, and amount range",
+	Flags: append([]cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:  queryFlag,
+			Usage: "Case insensitive text to search for, empty matches all transactions",
+		},
+		cli.Int64Flag{
+			Name:  minAmountFlag,
+			Usage: "Minimum staking amount in satoshis, 0 means no minimum",
+		},
+		cli.Int64Flag{
+			Name:  maxAmountFlag,
+			Usage: "Maximum staking amount in satoshis, 0 means no maximum",
+		},
+		cli.IntFlag{
+			Name:  offsetFlag,
+			Usage: "index of transaction after which search starts, use last_transaction_index of previous page",
+			Value: 0,
+		},
+		cli.IntFlag{
+			Name:  limitFlag,
+			Usage: "maximum number of transactions to return",
+			Value: 100,
+		},
+	}, timeFilterFlags...),
+	Action: searchStakingTransactions,
+}
+
+func checkHealth(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	health, err := client.Health(sctx)
+
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, health)
+}
+
+func delegationStates(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	states, err := client.DelegationStates(sctx)
+
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, states)
+}
+
+func listOutputs(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	outputs, err := client.ListOutputs(sctx)
+
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, outputs)
+}
+
+func utxoBlocklist(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.UtxoBlocklist(sctx)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func utxoBlocklistAdd(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.UtxoBlocklistAdd(
+		sctx,
+		ctx.StringSlice(outpointFlag),
+		ctx.StringSlice(addressFlag),
+		ctx.String(reasonFlag),
+	)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func utxoBlocklistRemove(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.UtxoBlocklistRemove(
+		sctx,
+		ctx.StringSlice(outpointFlag),
+		ctx.StringSlice(addressFlag),
+	)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func frozenOutputs(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.FrozenOutputs(sctx)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func walletTransactions(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	var label *string
+	if ctx.IsSet(labelFlag) {
+		l := ctx.String(labelFlag)
+		label = &l
+	}
+
+	limit := ctx.Int(limitFlag)
+
+	result, err := client.WalletTransactions(sctx, label, &limit)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func freezeOutput(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.FreezeOutput(sctx, ctx.String(outpointFlag), ctx.String(noteFlag))
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func unfreezeOutput(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.UnfreezeOutput(sctx, ctx.String(outpointFlag))
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func pendingActions(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.PendingActions(sctx)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func approveAction(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.ApproveAction(sctx, ctx.String(actionIdFlag))
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func rejectAction(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.RejectAction(sctx, ctx.String(actionIdFlag))
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func consolidateOutputs(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	destinationAddress := ctx.String(destinationAddressFlag)
+
+	maxUtxoValue := ctx.Int(maxUtxoValueFlag)
+
+	if maxUtxoValue < 0 {
+		return cli.NewExitError("Max utxo value must be non-negative", 1)
+	}
+
+	var mv *int = nil
+	if maxUtxoValue > 0 {
+		mv = &maxUtxoValue
+	}
+
+	feeRate := ctx.Int(feeRateFlag)
+
+	if feeRate < 0 {
+		return cli.NewExitError("Fee rate must be non-negative", 1)
+	}
+
+	var fr *int = nil
+	if feeRate > 0 {
+		fr = &feeRate
+	}
+
+	result, err := client.ConsolidateOutputs(sctx, destinationAddress, mv, fr)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func babylonFinalityProviders(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	offset := ctx.Int(offsetFlag)
+
+	if offset < 0 {
+		return cli.NewExitError("Offset must be non-negative", 1)
+	}
+
+	limit := ctx.Int(limitFlag)
+
+	if limit < 0 {
+		return cli.NewExitError("Limit must be non-negative", 1)
+	}
+
+	finalityProviders, err := client.BabylonFinalityProviders(sctx, &offset, &limit)
+
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, finalityProviders)
+}
+
+func babylonRewards(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	rewards, err := client.BabylonRewards(sctx)
+
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, rewards)
+}
+
+func withdrawBabylonRewards(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	var rewardType *string = nil
+	if t := ctx.String(rewardTypeFlag); t != "" {
+		rewardType = &t
+	}
+
+	var recipient *string = nil
+	if r := ctx.String(rewardRecipientFlag); r != "" {
+		recipient = &r
+	}
+
+	result, err := client.WithdrawBabylonRewards(sctx, rewardType, recipient)
+
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func parseMetadata(entries []string) (map[string]string, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	metadata := make(map[string]string)
+
+	for _, entry := range entries {
+		key, value, found := strings.Cut(entry, "=")
+
+		if !found || len(key) == 0 {
+			return nil, fmt.Errorf("invalid metadata entry %s, expected format key=value", entry)
+		}
+
+		metadata[key] = value
+	}
+
+	return metadata, nil
+}
+
+func minConfirmationsFromCliCtx(ctx *cli.Context) *int {
+	if !ctx.IsSet(minConfirmationsFlag) {
+		return nil
+	}
+
+	minConfirmations := ctx.Int(minConfirmationsFlag)
+	return &minConfirmations
+}
+
+func stake(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	stakerAddress := ctx.String(stakerAddressFlag)
+	stakingAmount := ctx.Int64(helpers.StakingAmountFlag)
+	fpPks := ctx.StringSlice(fpPksFlag)
+	stakingTimeBlocks := ctx.Int64(helpers.StakingTimeBlocksFlag)
+
+	metadata, err := parseMetadata(ctx.StringSlice(metadataFlag))
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	opts := &dc.StakeOptions{
+		Metadata:         metadata,
+		RequestId:        ctx.String(requestIdFlag),
+		MinConfirmations: minConfirmationsFromCliCtx(ctx),
+		AutoWithdraw:     ctx.Bool(autoWithdrawFlag),
+	}
+
+	if preset := ctx.String(presetFlag); preset != "" {
+		if len(fpPks) > 0 || ctx.IsSet(helpers.StakingTimeBlocksFlag) {
+			return cli.NewExitError(fmt.Sprintf("--%s and --%s must not be provided together with --%s",
+				fpPksFlag, helpers.StakingTimeBlocksFlag, presetFlag), 1)
+		}
+
+		results, err := client.StakeWithPreset(
+			sctx,
+			stakerAddress,
+			stakingAmount,
+			preset,
+			opts,
+		)
+		if err != nil {
+			return err
+		}
+
+		return helpers.PrintResp(ctx, results)
+	}
+
+	if !ctx.IsSet(helpers.StakingAmountFlag) || len(fpPks) == 0 || !ctx.IsSet(helpers.StakingTimeBlocksFlag) {
+		return cli.NewExitError(fmt.Sprintf("either --%s or all of --%s, --%s and --%s must be provided",
+			presetFlag, helpers.StakingAmountFlag, fpPksFlag, helpers.StakingTimeBlocksFlag), 1)
+	}
+
+	results, err := client.Stake(
+		sctx,
+		stakerAddress,
+		stakingAmount,
+		fpPks,
+		stakingTimeBlocks,
+		opts,
+	)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, results)
+}
+
+func stakeExternal(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	metadata, err := parseMetadata(ctx.StringSlice(metadataFlag))
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	results, err := client.StakeExternal(
+		sctx,
+		ctx.String(fundingAddressFlag),
+		ctx.String(stakerPkFlag),
+		ctx.Int64(helpers.StakingAmountFlag),
+		ctx.StringSlice(fpPksFlag),
+		ctx.Int64(helpers.StakingTimeBlocksFlag),
+		metadata,
+		ctx.String(requestIdFlag),
+		minConfirmationsFromCliCtx(ctx),
+	)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, results)
+}
+
+func monitoredTransactions(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	if stakingTxHash := ctx.String(stakingTransactionHashFlag); stakingTxHash != "" {
+		result, err := client.MonitoredTransaction(sctx, stakingTxHash)
+		if err != nil {
+			return err
+		}
+
+		return helpers.PrintResp(ctx, result)
+	}
+
+	result, err := client.MonitoredTransactions(sctx)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func retryQueue(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.RetryQueue(sctx)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func flushRetryQueue(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	var operation *string
+	if op := ctx.String(operationFlag); op != "" {
+		operation = &op
+	}
+
+	result, err := client.FlushRetryQueue(sctx, operation)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func scheduleOperation(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	var executeAtHeight *int
+	if ctx.IsSet(atHeightFlag) {
+		h := ctx.Int(atHeightFlag)
+		executeAtHeight = &h
+	}
+
+	var executeAtTime *int64
+	if ctx.IsSet(atTimeFlag) {
+		t, err := time.Parse(time.RFC3339, ctx.String(atTimeFlag))
+		if err != nil {
+			return fmt.Errorf("invalid time %s, expected RFC3339 format: %w", ctx.String(atTimeFlag), err)
+		}
+
+		unix := t.Unix()
+		executeAtTime = &unix
+	}
+
+	var note *string
+	if ctx.IsSet(noteFlag) {
+		n := ctx.String(noteFlag)
+		note = &n
+	}
+
+	result, err := client.ScheduleOperation(
+		sctx,
+		ctx.String(operationFlag),
+		ctx.String(stakingTransactionHashFlag),
+		executeAtHeight,
+		executeAtTime,
+		note,
+	)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func cancelScheduledOperation(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.CancelScheduledOperation(sctx, ctx.String(idFlag))
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func scheduledOperations(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.ScheduledOperations(sctx)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func retryBabylon(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	stakingTransactionHash := ctx.String(stakingTransactionHashFlag)
+
+	result, err := client.RetryBabylon(sctx, stakingTransactionHash, ctx.Bool(forceFlag))
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func exportDelegation(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	var gasLimit *int
+	if ctx.IsSet(gasLimitFlag) {
+		gas := ctx.Int(gasLimitFlag)
+		gasLimit = &gas
+	}
+
+	var fees *string
+	if ctx.IsSet(feesFlag) {
+		f := ctx.String(feesFlag)
+		fees = &f
+	}
+
+	result, err := client.ExportDelegation(
+		sctx,
+		ctx.String(stakingTransactionHashFlag),
+		gasLimit,
+		fees,
+		ctx.Bool(markSubmittedFlag),
+	)
+	if err != nil {
+		return err
+	}
+
+	outputFile := ctx.String(outputFileFlag)
+
+	if outputFile == "" {
+		return helpers.PrintResp(ctx, result)
+	}
+
+	return os.WriteFile(outputFile, result.UnsignedTx, 0600)
+}
+
+func overrideDelegationState(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.OverrideDelegationState(
+		sctx,
+		ctx.String(stakingTransactionHashFlag),
+		ctx.String(fromStateFlag),
+		ctx.String(toStateFlag),
+		ctx.String(reasonFlag),
+	)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func purgeDelegation(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.PurgeDelegation(
+		sctx,
+		ctx.String(stakingTransactionHashFlag),
+		ctx.String(reasonFlag),
+	)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func annotateDelegation(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.AnnotateDelegation(
+		sctx,
+		ctx.String(stakingTransactionHashFlag),
+		ctx.String(noteFlag),
+	)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func auditLog(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	var stakingTxHash *string
+	if hash := ctx.String(stakingTransactionHashFlag); hash != "" {
+		stakingTxHash = &hash
+	}
+
+	result, err := client.AuditLog(sctx, stakingTxHash)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func unstake(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	stakingTransactionHash := ctx.String(stakingTransactionHashFlag)
+
+	result, err := client.SpendStakingTransaction(sctx, stakingTransactionHash)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func unbond(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	stakingTransactionHash := ctx.String(stakingTransactionHashFlag)
+
+	feeRate := ctx.Int(feeRateFlag)
+
+	if feeRate < 0 {
+		return cli.NewExitError("Fee rate must be non-negative", 1)
+	}
+
+	var fr *int = nil
+	if feeRate > 0 {
+		fr = &feeRate
+	}
+
+	var destinationAddress *string
+	if ctx.IsSet(destinationAddressFlag) {
+		a := ctx.String(destinationAddressFlag)
+		destinationAddress = &a
+	}
+
+	result, err := client.UnbondStaking(sctx, stakingTransactionHash, fr, destinationAddress)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func setUnbondingOverrides(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	var unbondingTime, feeRate *int64
+
+	if ctx.IsSet(unbondingTimeFlag) {
+		t := ctx.Int64(unbondingTimeFlag)
+		unbondingTime = &t
+	}
+
+	if ctx.IsSet(feeRateFlag) {
+		r := ctx.Int64(feeRateFlag)
+		feeRate = &r
+	}
+
+	result, err := client.SetUnbondingOverrides(sctx, ctx.String(stakingTransactionHashFlag), unbondingTime, feeRate)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func setAutoWithdraw(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.SetAutoWithdraw(sctx, ctx.String(stakingTransactionHashFlag), !ctx.Bool(disableFlag))
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func bumpStakingFee(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	stakingTransactionHash := ctx.String(stakingTransactionHashFlag)
+
+	feeRate := ctx.Int(feeRateFlag)
+
+	if feeRate < 0 {
+		return cli.NewExitError("Fee rate must be non-negative", 1)
+	}
+
+	var fr *int = nil
+	if feeRate > 0 {
+		fr = &feeRate
+	}
+
+	result, err := client.BumpStakingFee(sctx, stakingTransactionHash, fr)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func stakingDetails(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	stakingTransactionHash := ctx.String(stakingTransactionHashFlag)
+
+	result, err := client.StakingDetails(sctx, stakingTransactionHash)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func stakingDetailsBatch(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.StakingDetailsBatch(sctx, ctx.StringSlice(stakingTransactionHashFlag))
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+// watchStakingRequest is the input file of watch-staking-tx command, its fields
+// are named as parameters of watch_staking_tx rpc call
+type watchStakingRequest struct {
+	StakingTx           string            `json:"stakingTx"`
+	StakingTime         int               `json:"stakingTime"`
+	StakingValue        int               `json:"stakingValue"`
+	StakerBtcPk         string            `json:"stakerBtcPk"`
+	FpBtcPks            []string          `json:"fpBtcPks"`
+	SlashingTx          string            `json:"slashingTx"`
+	SlashingTxSig       string            `json:"slashingTxSig"`
+	StakerBabylonPk     string            `json:"stakerBabylonPk"`
+	StakerAddress       string            `json:"stakerAddress"`
+	StakerBabylonSig    string            `json:"stakerBabylonSig"`
+	StakerBtcSig        string            `json:"stakerBtcSig"`
+	UnbondingTx         string            `json:"unbondingTx"`
+	SlashUnbondingTx    string            `json:"slashUnbondingTx"`
+	SlashUnbondingTxSig string            `json:"slashUnbondingTxSig"`
+	UnbondingTime       int               `json:"unbondingTime"`
+	PopType             int               `json:"popType"`
+	Metadata            map[string]string `json:"metadata,omitempty"`
+}
+
+func watchStakingTx(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	data, err := os.ReadFile(ctx.String(inputFileFlag))
+	if err != nil {
+		return err
+	}
+
+	var req watchStakingRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return cli.NewExitError(fmt.Sprintf("invalid input file: %s", err), 1)
+	}
+
+	result, err := client.WatchStaking(
+		sctx,
+		req.StakingTx,
+		req.StakingTime,
+		req.StakingValue,
+		req.StakerBtcPk,
+		req.FpBtcPks,
+		req.SlashingTx,
+		req.SlashingTxSig,
+		req.StakerBabylonPk,
+		req.StakerAddress,
+		req.StakerBabylonSig,
+		req.StakerBtcSig,
+		req.UnbondingTx,
+		req.SlashUnbondingTx,
+		req.SlashUnbondingTxSig,
+		req.UnbondingTime,
+		req.PopType,
+		req.Metadata,
+	)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func devUnbondingSigHash(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.DevUnbondingSigHash(sctx, ctx.String(stakingTransactionHashFlag))
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func devSubmitCovenantUnbondingSigs(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	covenantPks := ctx.StringSlice(covenantPkFlag)
+	covenantSigs := ctx.StringSlice(covenantSigFlag)
+
+	if len(covenantPks) != len(covenantSigs) {
+		return cli.NewExitError("Number of covenant public keys must match number of covenant signatures", 1)
+	}
+
+	result, err := client.DevSubmitCovenantUnbondingSigs(
+		sctx,
+		ctx.String(stakingTransactionHashFlag),
+		covenantPks,
+		covenantSigs,
+	)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func devSignedUnbondingTx(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.DevSignedUnbondingTx(sctx, ctx.String(stakingTransactionHashFlag))
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func unbondAll(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	interval := ctx.Duration(intervalFlag)
+
+	if interval < 0 {
+		return cli.NewExitError("Interval must be non-negative", 1)
+	}
+
+	intervalMs := int(interval.Milliseconds())
+
+	result, err := client.UnbondAll(sctx, &intervalMs, ctx.Bool(dryRunFlag))
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func stakingScriptInfo(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	stakingTransactionHash := ctx.String(stakingTransactionHashFlag)
+
+	result, err := client.StakingScriptInfo(sctx, stakingTransactionHash)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func delegationGraph(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	stakingTransactionHash := ctx.String(stakingTransactionHashFlag)
+
+	result, err := client.DelegationGraph(sctx, stakingTransactionHash)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func generateMuSig2Nonce(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	var message *string
+	if ctx.IsSet(messageFlag) {
+		m := ctx.String(messageFlag)
+		message = &m
+	}
+
+	ttlSeconds := int64(ctx.Duration(ttlFlag).Seconds())
+
+	result, err := client.GenerateMuSig2Nonce(
+		sctx,
+		ctx.String(sessionIdFlag),
+		ctx.String(signerAddressFlag),
+		message,
+		&ttlSeconds,
+	)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func stakingPresets(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.StakingPresets(sctx)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func setStakingPreset(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	var maxFeeRate *int64
+	if ctx.IsSet(maxFeeRateFlag) {
+		r := ctx.Int64(maxFeeRateFlag)
+		maxFeeRate = &r
+	}
+
+	result, err := client.SetStakingPreset(
+		sctx,
+		ctx.String(presetNameFlag),
+		ctx.StringSlice(fpPksFlag),
+		ctx.Int64(helpers.StakingTimeBlocksFlag),
+		ctx.Int64Slice(amountTierFlag),
+		maxFeeRate,
+	)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func deleteStakingPreset(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.DeleteStakingPreset(sctx, ctx.String(presetNameFlag))
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func musig2Nonce(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.MuSig2Nonce(sctx, ctx.String(sessionIdFlag))
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func computeSigHashes(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	var stakingTxHash *string
+	if ctx.IsSet(stakingTransactionHashFlag) {
+		hash := ctx.String(stakingTransactionHashFlag)
+		stakingTxHash = &hash
+	}
+
+	result, err := client.ComputeSigHashes(sctx, ctx.String(txHexFlag), stakingTxHash)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
 }
 
-var listStakingTransactionsCmd = cli.Command{
-	Name:      "list-staking-transactions",
-	ShortName: "lst",
-	Usage:     "List current staking transactions in db",
-	Flags: []cli.Flag{
-		cli.StringFlag{
-			Name:  stakingDaemonAddressFlag,
-			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
-			Value: defaultStakingDaemonAddress,
-		},
-		cli.IntFlag{
-			Name:  offsetFlag,
-			Usage: "offset of the first transactions to return",
-			Value: 0,
-		},
-		cli.IntFlag{
-			Name:  limitFlag,
-			Usage: "maximum number of transactions to return",
-			Value: 100,
-		},
-	},
-	Action: listStakingTransactions,
+func exitTemplates(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	stakingTransactionHash := ctx.String(stakingTransactionHashFlag)
+
+	result, err := client.ExitTemplates(sctx, stakingTransactionHash)
+	if err != nil {
+		return err
+	}
+
+	outputFile := ctx.String(outputFileFlag)
+
+	if outputFile == "" {
+		return helpers.PrintResp(ctx, result)
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(outputFile, data, 0600)
 }
 
-var withdrawableTransactionsCmd = cli.Command{
-	Name:      "withdrawable-transactions",
-	ShortName: "wt",
-	Usage:     "List current tranactions that can be withdrawn i.e funds can be transferred back to staker address",
-	Flags: []cli.Flag{
-		cli.StringFlag{
-			Name:  stakingDaemonAddressFlag,
-			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
-			Value: defaultStakingDaemonAddress,
-		},
-		cli.IntFlag{
-			Name:  offsetFlag,
-			Usage: "offset of the first transactions to return",
-			Value: 0,
-		},
-		cli.IntFlag{
-			Name:  limitFlag,
-			Usage: "maximum number of transactions to return",
-			Value: 100,
-		},
-	},
-	Action: withdrawableTransactions,
+func proveOwnership(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	stakingTransactionHash := ctx.String(stakingTransactionHashFlag)
+	challenge := ctx.String(challengeFlag)
+
+	result, err := client.ProveOwnership(sctx, stakingTransactionHash, challenge)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
 }
 
-func checkHealth(ctx *cli.Context) error {
+func verifyOwnershipProof(ctx *cli.Context) error {
 	daemonAddress := ctx.String(stakingDaemonAddressFlag)
 	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
 	if err != nil {
@@ -248,18 +3117,38 @@ func checkHealth(ctx *cli.Context) error {
 
 	sctx := context.Background()
 
-	health, err := client.Health(sctx)
+	result, err := client.VerifyOwnershipProof(
+		sctx,
+		ctx.String(stakingTransactionHashFlag),
+		ctx.String(stakerPkFlag),
+		ctx.String(challengeFlag),
+		ctx.String(signatureFlag),
+	)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
 
+func signMessage(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
 	if err != nil {
 		return err
 	}
 
-	helpers.PrintRespJSON(health)
+	sctx := context.Background()
+
+	result, err := client.SignMessage(sctx, ctx.String(stakerAddressFlag), ctx.String(messageFlag))
+	if err != nil {
+		return err
+	}
 
-	return nil
+	return helpers.PrintResp(ctx, result)
 }
 
-func listOutputs(ctx *cli.Context) error {
+func proofOfReserves(ctx *cli.Context) error {
 	daemonAddress := ctx.String(stakingDaemonAddressFlag)
 	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
 	if err != nil {
@@ -268,18 +3157,37 @@ func listOutputs(ctx *cli.Context) error {
 
 	sctx := context.Background()
 
-	outputs, err := client.ListOutputs(sctx)
+	result, err := client.ProofOfReserves(sctx, ctx.String(messageFlag))
+	if err != nil {
+		return err
+	}
 
+	return helpers.PrintResp(ctx, result)
+}
+
+func verifyMessage(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
 	if err != nil {
 		return err
 	}
 
-	helpers.PrintRespJSON(outputs)
+	sctx := context.Background()
+
+	result, err := client.VerifyMessage(
+		sctx,
+		ctx.String(addressFlag),
+		ctx.String(messageFlag),
+		ctx.String(signatureFlag),
+	)
+	if err != nil {
+		return err
+	}
 
-	return nil
+	return helpers.PrintResp(ctx, result)
 }
 
-func babylonFinalityProviders(ctx *cli.Context) error {
+func stakingStatusLight(ctx *cli.Context) error {
 	daemonAddress := ctx.String(stakingDaemonAddressFlag)
 	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
 	if err != nil {
@@ -300,18 +3208,22 @@ func babylonFinalityProviders(ctx *cli.Context) error {
 		return cli.NewExitError("Limit must be non-negative", 1)
 	}
 
-	finalityProviders, err := client.BabylonFinalityProviders(sctx, &offset, &limit)
+	var etag *string
+	if ctx.IsSet(etagFlag) {
+		e := ctx.String(etagFlag)
+		etag = &e
+	}
+
+	status, err := client.StakingStatusLight(sctx, &offset, &limit, etag)
 
 	if err != nil {
 		return err
 	}
 
-	helpers.PrintRespJSON(finalityProviders)
-
-	return nil
+	return helpers.PrintResp(ctx, status)
 }
 
-func stake(ctx *cli.Context) error {
+func listStakingTransactions(ctx *cli.Context) error {
 	daemonAddress := ctx.String(stakingDaemonAddressFlag)
 	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
 	if err != nil {
@@ -320,22 +3232,44 @@ func stake(ctx *cli.Context) error {
 
 	sctx := context.Background()
 
-	stakerAddress := ctx.String(stakerAddressFlag)
-	stakingAmount := ctx.Int64(helpers.StakingAmountFlag)
-	fpPks := ctx.StringSlice(fpPksFlag)
-	stakingTimeBlocks := ctx.Int64(helpers.StakingTimeBlocksFlag)
+	offset := ctx.Int(offsetFlag)
+
+	if offset < 0 {
+		return cli.NewExitError("Offset must be non-negative", 1)
+	}
+
+	limit := ctx.Int(limitFlag)
+
+	if limit < 0 {
+		return cli.NewExitError("Limit must be non-negative", 1)
+	}
 
-	results, err := client.Stake(sctx, stakerAddress, stakingAmount, fpPks, stakingTimeBlocks)
+	metadataFilter, err := parseMetadata(ctx.StringSlice(metadataFilterFlag))
 	if err != nil {
-		return err
+		return cli.NewExitError(err.Error(), 1)
 	}
 
-	helpers.PrintRespJSON(results)
+	var group *string
+	if ctx.IsSet(groupFlag) {
+		g := ctx.String(groupFlag)
+		group = &g
+	}
+
+	timeFilter, err := parseTimeFilterFlags(ctx)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	transactions, err := client.ListStakingTransactions(sctx, &offset, &limit, metadataFilter, group, timeFilter)
+
+	if err != nil {
+		return err
+	}
 
-	return nil
+	return helpers.PrintResp(ctx, transactions)
 }
 
-func unstake(ctx *cli.Context) error {
+func streamStakingTransactions(ctx *cli.Context) error {
 	daemonAddress := ctx.String(stakingDaemonAddressFlag)
 	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
 	if err != nil {
@@ -344,19 +3278,24 @@ func unstake(ctx *cli.Context) error {
 
 	sctx := context.Background()
 
-	stakingTransactionHash := ctx.String(stakingTransactionHashFlag)
+	offset := ctx.Uint64(offsetFlag)
 
-	result, err := client.SpendStakingTransaction(sctx, stakingTransactionHash)
+	metadataFilter, err := parseMetadata(ctx.StringSlice(metadataFilterFlag))
 	if err != nil {
-		return err
+		return cli.NewExitError(err.Error(), 1)
 	}
 
-	helpers.PrintRespJSON(result)
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	encoder := json.NewEncoder(out)
 
-	return nil
+	return client.StreamStakingTransactions(sctx, &offset, metadataFilter, func(details *service.StakingDetails) error {
+		return encoder.Encode(details)
+	})
 }
 
-func unbond(ctx *cli.Context) error {
+func stakingSummary(ctx *cli.Context) error {
 	daemonAddress := ctx.String(stakingDaemonAddressFlag)
 	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
 	if err != nil {
@@ -365,30 +3304,40 @@ func unbond(ctx *cli.Context) error {
 
 	sctx := context.Background()
 
-	stakingTransactionHash := ctx.String(stakingTransactionHashFlag)
+	var group *string
+	if ctx.IsSet(groupFlag) {
+		g := ctx.String(groupFlag)
+		group = &g
+	}
 
-	feeRate := ctx.Int(feeRateFlag)
+	summary, err := client.StakingSummary(sctx, group)
 
-	if feeRate < 0 {
-		return cli.NewExitError("Fee rate must be non-negative", 1)
+	if err != nil {
+		return err
 	}
 
-	var fr *int = nil
-	if feeRate > 0 {
-		fr = &feeRate
-	}
+	return helpers.PrintResp(ctx, summary)
+}
 
-	result, err := client.UnbondStaking(sctx, stakingTransactionHash, fr)
+func groupSummaries(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
 	if err != nil {
 		return err
 	}
 
-	helpers.PrintRespJSON(result)
+	sctx := context.Background()
+
+	summaries, err := client.GroupSummaries(sctx)
+
+	if err != nil {
+		return err
+	}
 
-	return nil
+	return helpers.PrintResp(ctx, summaries)
 }
 
-func stakingDetails(ctx *cli.Context) error {
+func setDelegationGroup(ctx *cli.Context) error {
 	daemonAddress := ctx.String(stakingDaemonAddressFlag)
 	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
 	if err != nil {
@@ -397,19 +3346,16 @@ func stakingDetails(ctx *cli.Context) error {
 
 	sctx := context.Background()
 
-	stakingTransactionHash := ctx.String(stakingTransactionHashFlag)
+	result, err := client.SetDelegationGroup(sctx, ctx.StringSlice(stakingTransactionHashFlag), ctx.String(groupFlag))
 
-	result, err := client.StakingDetails(sctx, stakingTransactionHash)
 	if err != nil {
 		return err
 	}
 
-	helpers.PrintRespJSON(result)
-
-	return nil
+	return helpers.PrintResp(ctx, result)
 }
 
-func listStakingTransactions(ctx *cli.Context) error {
+func withdrawableTransactions(ctx *cli.Context) error {
 	daemonAddress := ctx.String(stakingDaemonAddressFlag)
 	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
 	if err != nil {
@@ -430,18 +3376,16 @@ func listStakingTransactions(ctx *cli.Context) error {
 		return cli.NewExitError("Limit must be non-negative", 1)
 	}
 
-	transactions, err := client.ListStakingTransactions(sctx, &offset, &limit)
+	transactions, err := client.WithdrawableTransactions(sctx, &offset, &limit)
 
 	if err != nil {
 		return err
 	}
 
-	helpers.PrintRespJSON(transactions)
-
-	return nil
+	return helpers.PrintResp(ctx, transactions)
 }
 
-func withdrawableTransactions(ctx *cli.Context) error {
+func searchStakingTransactions(ctx *cli.Context) error {
 	daemonAddress := ctx.String(stakingDaemonAddressFlag)
 	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
 	if err != nil {
@@ -462,13 +3406,109 @@ func withdrawableTransactions(ctx *cli.Context) error {
 		return cli.NewExitError("Limit must be non-negative", 1)
 	}
 
-	transactions, err := client.WithdrawableTransactions(sctx, &offset, &limit)
+	query := ctx.String(queryFlag)
+	minAmount := ctx.Int64(minAmountFlag)
+	maxAmount := ctx.Int64(maxAmountFlag)
+
+	timeFilter, err := parseTimeFilterFlags(ctx)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	transactions, err := client.SearchStakingTransactions(sctx, &query, &minAmount, &maxAmount, &offset, &limit, timeFilter)
+
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, transactions)
+}
+
+func babylonStakingParams(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.BabylonStakingParams(sctx)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func feeEstimate(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.FeeEstimate(sctx)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func feeBudget(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	result, err := client.FeeBudget(sctx)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
+
+func feeAnalytics(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	days := ctx.Int(daysFlag)
+
+	result, err := client.FeeAnalytics(sctx, &days)
+	if err != nil {
+		return err
+	}
+
+	return helpers.PrintResp(ctx, result)
+}
 
+func confirmationSla(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
 	if err != nil {
 		return err
 	}
 
-	helpers.PrintRespJSON(transactions)
+	sctx := context.Background()
+
+	days := ctx.Int(daysFlag)
+
+	result, err := client.ConfirmationSla(sctx, &days)
+	if err != nil {
+		return err
+	}
 
-	return nil
+	return helpers.PrintResp(ctx, result)
 }
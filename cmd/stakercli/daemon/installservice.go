@@ -0,0 +1,304 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/urfave/cli"
+)
+
+const (
+	servicePlatformFlag = "platform"
+	serviceNameFlag     = "service-name"
+	stakerdPathFlag     = "stakerd-path"
+	stakerdDirFlag      = "stakerd-dir"
+	configFileFlag      = "config-file"
+	serviceOutputFlag   = "output-file"
+	servicePrintFlag    = "print"
+
+	platformLinux   = "linux"
+	platformDarwin  = "darwin"
+	platformWindows = "windows"
+
+	defaultServiceName = "stakerd"
+
+	launchdLabelPrefix = "com.babylonchain."
+
+	serviceDescription = "Babylon BTC staker daemon"
+)
+
+var installServiceCmd = cli.Command{
+	Name:      "install-service",
+	ShortName: "is",
+	Usage: "Install staker daemon as managed background service: systemd unit on linux, launchd " +
+		"agent on macOS and service controlled by service control manager on windows.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  servicePlatformFlag,
+			Usage: "platform of the service definition, one of (linux, darwin, windows). Windows services can be installed only on windows",
+			Value: runtime.GOOS,
+		},
+		cli.StringFlag{
+			Name:  serviceNameFlag,
+			Usage: "name of the service",
+			Value: defaultServiceName,
+		},
+		cli.StringFlag{
+			Name:  stakerdPathFlag,
+			Usage: "path to stakerd binary. By default stakerd next to stakercli binary or in PATH is used",
+		},
+		cli.StringFlag{
+			Name:  stakerdDirFlag,
+			Usage: "base directory of the daemon passed as --stakerddir, services often run as different user so it should be explicit",
+			Value: scfg.DefaultStakerdDir,
+		},
+		cli.StringFlag{
+			Name:  configFileFlag,
+			Usage: "config file of the daemon passed as --configfile. By default stakerd.conf in stakerd directory",
+		},
+		cli.StringFlag{
+			Name:  serviceOutputFlag,
+			Usage: "path of the systemd unit or launchd plist. By default /etc/systemd/system/<name>.service on linux and ~/Library/LaunchAgents/<label>.plist on macOS",
+		},
+		cli.BoolFlag{
+			Name:  servicePrintFlag,
+			Usage: "print service definition to stdout instead of installing it",
+		},
+	},
+	Action: installService,
+}
+
+// findStakerdBinary looks for stakerd next to running stakercli binary first,
+// as both are installed together
+func findStakerdBinary() (string, error) {
+	binaryName := "stakerd"
+	if runtime.GOOS == platformWindows {
+		binaryName += ".exe"
+	}
+
+	if self, err := os.Executable(); err == nil {
+		candidate := filepath.Join(filepath.Dir(self), binaryName)
+
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+
+	path, err := exec.LookPath(binaryName)
+
+	if err != nil {
+		return "", fmt.Errorf("stakerd binary not found, provide it with --%s", stakerdPathFlag)
+	}
+
+	return filepath.Abs(path)
+}
+
+type serviceDefinition struct {
+	name       string
+	binary     string
+	stakerdDir string
+	configFile string
+}
+
+func (d *serviceDefinition) args() []string {
+	return []string{"--stakerddir", d.stakerdDir, "--configfile", d.configFile}
+}
+
+func quoteSystemdArg(arg string) string {
+	if !strings.ContainsAny(arg, " \t\"\\") {
+		return arg
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
+
+// systemdUnit returns unit with notify readiness and watchdog, which stakerd
+// supports natively
+func systemdUnit(d *serviceDefinition, serviceUser string) string {
+	execStart := []string{quoteSystemdArg(d.binary)}
+	for _, arg := range d.args() {
+		execStart = append(execStart, quoteSystemdArg(arg))
+	}
+
+	var b strings.Builder
+
+	b.WriteString("[Unit]\n")
+	b.WriteString("Description=" + serviceDescription + "\n")
+	b.WriteString("Wants=network-online.target\n")
+	b.WriteString("After=network-online.target\n\n")
+	b.WriteString("[Service]\n")
+	b.WriteString("Type=notify\n")
+	b.WriteString("ExecStart=" + strings.Join(execStart, " ") + "\n")
+
+	if serviceUser != "" {
+		b.WriteString("User=" + serviceUser + "\n")
+	}
+
+	b.WriteString("WatchdogSec=2min\n")
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("RestartSec=10\n\n")
+	b.WriteString("[Install]\n")
+	b.WriteString("WantedBy=multi-user.target\n")
+
+	return b.String()
+}
+
+func launchdLabel(name string) string {
+	return launchdLabelPrefix + name
+}
+
+func xmlEscape(s string) string {
+	var b bytes.Buffer
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// launchdPlist returns agent definition which starts daemon at login and
+// restarts it if it exits
+func launchdPlist(d *serviceDefinition) string {
+	logFile := filepath.Join(d.stakerdDir, "logs", "launchd.log")
+
+	var b strings.Builder
+
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	b.WriteString(`<plist version="1.0">` + "\n")
+	b.WriteString("<dict>\n")
+	b.WriteString("\t<key>Label</key>\n")
+	b.WriteString("\t<string>" + xmlEscape(launchdLabel(d.name)) + "</string>\n")
+	b.WriteString("\t<key>ProgramArguments</key>\n")
+	b.WriteString("\t<array>\n")
+	b.WriteString("\t\t<string>" + xmlEscape(d.binary) + "</string>\n")
+	for _, arg := range d.args() {
+		b.WriteString("\t\t<string>" + xmlEscape(arg) + "</string>\n")
+	}
+	b.WriteString("\t</array>\n")
+	b.WriteString("\t<key>RunAtLoad</key>\n")
+	b.WriteString("\t<true/>\n")
+	b.WriteString("\t<key>KeepAlive</key>\n")
+	b.WriteString("\t<dict>\n")
+	b.WriteString("\t\t<key>SuccessfulExit</key>\n")
+	b.WriteString("\t\t<false/>\n")
+	b.WriteString("\t</dict>\n")
+	b.WriteString("\t<key>StandardOutPath</key>\n")
+	b.WriteString("\t<string>" + xmlEscape(logFile) + "</string>\n")
+	b.WriteString("\t<key>StandardErrorPath</key>\n")
+	b.WriteString("\t<string>" + xmlEscape(logFile) + "</string>\n")
+	b.WriteString("</dict>\n")
+	b.WriteString("</plist>\n")
+
+	return b.String()
+}
+
+func writeServiceFile(path string, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("service definition %s already exists", path)
+	}
+
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+func installService(ctx *cli.Context) error {
+	d := &serviceDefinition{
+		name:       ctx.String(serviceNameFlag),
+		binary:     ctx.String(stakerdPathFlag),
+		stakerdDir: scfg.CleanAndExpandPath(ctx.String(stakerdDirFlag)),
+		configFile: ctx.String(configFileFlag),
+	}
+
+	if d.name == "" {
+		return cli.NewExitError("service name must not be empty", 1)
+	}
+
+	if d.binary == "" {
+		binary, err := findStakerdBinary()
+
+		if err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+
+		d.binary = binary
+	}
+
+	if d.configFile == "" {
+		d.configFile = filepath.Join(d.stakerdDir, "stakerd.conf")
+	}
+
+	d.configFile = scfg.CleanAndExpandPath(d.configFile)
+
+	var (
+		content      string
+		outputFile   = ctx.String(serviceOutputFlag)
+		instructions string
+	)
+
+	switch platform := ctx.String(servicePlatformFlag); platform {
+	case platformLinux:
+		// daemon runs as the user who installs the service, also when
+		// installing with sudo
+		serviceUser := os.Getenv("SUDO_USER")
+		if current, err := user.Current(); err == nil && current.Uid != "0" {
+			serviceUser = current.Username
+		}
+
+		content = systemdUnit(d, serviceUser)
+
+		if outputFile == "" {
+			outputFile = filepath.Join("/etc/systemd/system", d.name+".service")
+		}
+
+		instructions = fmt.Sprintf("Start the service with:\n  systemctl daemon-reload\n  systemctl enable --now %s", d.name)
+	case platformDarwin:
+		content = launchdPlist(d)
+
+		if outputFile == "" {
+			home, err := os.UserHomeDir()
+
+			if err != nil {
+				return err
+			}
+
+			outputFile = filepath.Join(home, "Library", "LaunchAgents", launchdLabel(d.name)+".plist")
+		}
+
+		instructions = fmt.Sprintf("Start the service with:\n  launchctl load -w %s", outputFile)
+	case platformWindows:
+		if ctx.Bool(servicePrintFlag) {
+			return cli.NewExitError("windows services are registered in service control manager and have no definition to print", 1)
+		}
+
+		if err := installWindowsService(d); err != nil {
+			return cli.NewExitError(fmt.Sprintf("failed to install windows service: %s", err), 1)
+		}
+
+		fmt.Printf("Service %s installed. Start it with:\n  sc start %s\n", d.name, d.name)
+		return nil
+	default:
+		return cli.NewExitError(fmt.Sprintf("unsupported platform %s", platform), 1)
+	}
+
+	if ctx.Bool(servicePrintFlag) {
+		fmt.Print(content)
+		return nil
+	}
+
+	if err := writeServiceFile(outputFile, content); err != nil {
+		return cli.NewExitError(fmt.Sprintf("failed to write service definition: %s", err), 1)
+	}
+
+	fmt.Printf("Service definition written to %s\n%s\n", outputFile, instructions)
+
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package daemon
+
+import "fmt"
+
+func installWindowsService(_ *serviceDefinition) error {
+	return fmt.Errorf("windows services can be installed only on windows")
+}
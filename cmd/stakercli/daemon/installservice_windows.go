@@ -0,0 +1,56 @@
+//go:build windows
+
+package daemon
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+const (
+	serviceRestartDelay = 10 * time.Second
+
+	// failure count is reset after a day without failures
+	serviceResetPeriodSecs = 24 * 60 * 60
+)
+
+// installWindowsService registers automatically started service, which is
+// restarted by service control manager when the daemon fails
+func installWindowsService(d *serviceDefinition) error {
+	m, err := mgr.Connect()
+
+	if err != nil {
+		return fmt.Errorf("failed to connect to service control manager: %w", err)
+	}
+
+	defer m.Disconnect()
+
+	if existing, err := m.OpenService(d.name); err == nil {
+		existing.Close()
+		return fmt.Errorf("service %s already exists", d.name)
+	}
+
+	s, err := m.CreateService(d.name, d.binary, mgr.Config{
+		DisplayName: serviceDescription,
+		Description: serviceDescription,
+		StartType:   mgr.StartAutomatic,
+	}, d.args()...)
+
+	if err != nil {
+		return err
+	}
+
+	defer s.Close()
+
+	err = s.SetRecoveryActions([]mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: serviceRestartDelay},
+	}, serviceResetPeriodSecs)
+
+	if err != nil {
+		return fmt.Errorf("failed to set recovery actions: %w", err)
+	}
+
+	return nil
+}
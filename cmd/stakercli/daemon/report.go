@@ -0,0 +1,303 @@
+package daemon
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	service "github.com/babylonchain/btc-staker/stakerservice"
+	dc "github.com/babylonchain/btc-staker/stakerservice/client"
+	"github.com/urfave/cli"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+const (
+	reportFormatFlag = "format"
+	reportFromFlag   = "from"
+	reportToFlag     = "to"
+	reportOutputFlag = "output-file"
+
+	reportFormatCsv     = "csv"
+	reportFormatParquet = "parquet"
+
+	reportDateLayout = "2006-01-02"
+)
+
+var exportReportCmd = cli.Command{
+	Name:      "export-report",
+	ShortName: "er",
+	Usage:     "Export report of delegations with amounts, fees paid, state timestamps and finality providers.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  stakingDaemonAddressFlag,
+			Usage: "full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: defaultStakingDaemonAddress,
+		},
+		cli.StringFlag{
+			Name:  reportFormatFlag,
+			Usage: "format of the report, one of (csv, parquet)",
+			Value: reportFormatCsv,
+		},
+		cli.StringFlag{
+			Name:  reportFromFlag,
+			Usage: "include only delegations created at or after this time, in format YYYY-MM-DD or RFC3339",
+		},
+		cli.StringFlag{
+			Name:  reportToFlag,
+			Usage: "include only delegations created before or at this time, in format YYYY-MM-DD or RFC3339. Date means end of the given day",
+		},
+		cli.StringFlag{
+			Name:  reportOutputFlag,
+			Usage: "path of the file to write report to. If not provided, report is written to stdout",
+		},
+	},
+	Action: exportReport,
+}
+
+type reportColumnKind int
+
+const (
+	reportIntColumn reportColumnKind = iota
+	reportStringColumn
+	reportTimeColumn
+)
+
+type reportColumn struct {
+	name  string
+	kind  reportColumnKind
+	value func(e *service.StakingReportEntry) string
+}
+
+var reportColumns = []reportColumn{
+	{"staking_tx_hash", reportStringColumn, func(e *service.StakingReportEntry) string { return e.StakingTxHash }},
+	{"staker_address", reportStringColumn, func(e *service.StakingReportEntry) string { return e.StakerAddress }},
+	{"staking_state", reportStringColumn, func(e *service.StakingReportEntry) string { return e.StakingState }},
+	{"watched", reportStringColumn, func(e *service.StakingReportEntry) string { return strconv.FormatBool(e.Watched) }},
+	{"staking_amount", reportIntColumn, func(e *service.StakingReportEntry) string { return e.StakingAmount }},
+	{"staking_time_blocks", reportIntColumn, func(e *service.StakingReportEntry) string { return e.StakingTimeBlocks }},
+	{"finality_provider_pks", reportStringColumn, func(e *service.StakingReportEntry) string {
+		return strings.Join(e.FinalityProviderPks, ";")
+	}},
+	{"staking_tx_fee", reportIntColumn, func(e *service.StakingReportEntry) string { return e.StakingTxFee }},
+	{"unbonding_tx_fee", reportIntColumn, func(e *service.StakingReportEntry) string { return e.UnbondingTxFee }},
+	{"spend_tx_fee", reportIntColumn, func(e *service.StakingReportEntry) string { return e.SpendTxFee }},
+	{"staking_tx_block_height", reportIntColumn, func(e *service.StakingReportEntry) string { return e.StakingTxBlockHeight }},
+	{"created_at", reportTimeColumn, func(e *service.StakingReportEntry) string { return e.CreatedAt }},
+	{"confirmed_on_btc_at", reportTimeColumn, func(e *service.StakingReportEntry) string { return e.ConfirmedOnBtcAt }},
+	{"sent_to_babylon_at", reportTimeColumn, func(e *service.StakingReportEntry) string { return e.SentToBabylonAt }},
+	{"delegation_active_at", reportTimeColumn, func(e *service.StakingReportEntry) string { return e.DelegationActiveAt }},
+	{"unbonding_confirmed_at", reportTimeColumn, func(e *service.StakingReportEntry) string { return e.UnbondingConfirmedAt }},
+	{"spent_on_btc_at", reportTimeColumn, func(e *service.StakingReportEntry) string { return e.SpentOnBtcAt }},
+	{"metadata", reportStringColumn, func(e *service.StakingReportEntry) string { return formatReportMetadata(e.Metadata) }},
+}
+
+func formatReportMetadata(metadata map[string]string) string {
+	entries := make([]string, 0, len(metadata))
+
+	for k, v := range metadata {
+		entries = append(entries, k+"="+v)
+	}
+
+	sort.Strings(entries)
+
+	return strings.Join(entries, ";")
+}
+
+// formatReportTime converts unix timestamp returned by daemon to RFC3339 format
+func formatReportTime(unixTime string) (string, error) {
+	if unixTime == "" {
+		return "", nil
+	}
+
+	seconds, err := strconv.ParseInt(unixTime, 10, 64)
+
+	if err != nil {
+		return "", err
+	}
+
+	return time.Unix(seconds, 0).UTC().Format(time.RFC3339), nil
+}
+
+func parseReportTime(value string, endOfDay bool) (*int64, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(reportDateLayout, value); err == nil {
+		if endOfDay {
+			t = t.Add(24*time.Hour - time.Second)
+		}
+		unix := t.Unix()
+		return &unix, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid time %s, expected format YYYY-MM-DD or RFC3339", value)
+	}
+
+	unix := t.Unix()
+	return &unix, nil
+}
+
+func writeCsvReport(out io.Writer, entries []service.StakingReportEntry) error {
+	w := csv.NewWriter(out)
+
+	header := make([]string, len(reportColumns))
+	for i, c := range reportColumns {
+		header[i] = c.name
+	}
+
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	for i := range entries {
+		record := make([]string, len(reportColumns))
+
+		for j, c := range reportColumns {
+			value := c.value(&entries[i])
+
+			if c.kind == reportTimeColumn {
+				formatted, err := formatReportTime(value)
+
+				if err != nil {
+					return err
+				}
+
+				value = formatted
+			}
+
+			record[j] = value
+		}
+
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// parquetSchema returns metadata of report columns in format of parquet-go
+// CSV writer. Int columns are INT64, other columns UTF8 strings.
+func parquetSchema() []string {
+	schema := make([]string, len(reportColumns))
+
+	for i, c := range reportColumns {
+		if c.kind == reportIntColumn {
+			schema[i] = fmt.Sprintf("name=%s, type=INT64", c.name)
+		} else {
+			schema[i] = fmt.Sprintf("name=%s, type=BYTE_ARRAY, convertedtype=UTF8", c.name)
+		}
+	}
+
+	return schema
+}
+
+// writeParquetReport writes entries as single parquet file. Rows are written
+// to the output in row groups, so only the current row group is kept in memory.
+func writeParquetReport(out io.Writer, entries []service.StakingReportEntry) error {
+	w, err := writer.NewCSVWriterFromWriter(parquetSchema(), out, 1)
+
+	if err != nil {
+		return err
+	}
+
+	for i := range entries {
+		row := make([]interface{}, len(reportColumns))
+
+		for j, c := range reportColumns {
+			value := c.value(&entries[i])
+
+			switch c.kind {
+			case reportIntColumn:
+				var parsed int64
+
+				if value != "" {
+					v, err := strconv.ParseInt(value, 10, 64)
+
+					if err != nil {
+						return fmt.Errorf("invalid value %s of column %s: %w", value, c.name, err)
+					}
+
+					parsed = v
+				}
+
+				row[j] = parsed
+			case reportTimeColumn:
+				formatted, err := formatReportTime(value)
+
+				if err != nil {
+					return err
+				}
+
+				row[j] = formatted
+			default:
+				row[j] = value
+			}
+		}
+
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return w.WriteStop()
+}
+
+func exportReport(ctx *cli.Context) error {
+	daemonAddress := ctx.String(stakingDaemonAddressFlag)
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	sctx := context.Background()
+
+	format := ctx.String(reportFormatFlag)
+
+	if format != reportFormatCsv && format != reportFormatParquet {
+		return cli.NewExitError(fmt.Sprintf("Invalid report format %s, must be one of (csv, parquet)", format), 1)
+	}
+
+	from, err := parseReportTime(ctx.String(reportFromFlag), false)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	to, err := parseReportTime(ctx.String(reportToFlag), true)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	report, err := client.StakingReport(sctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+
+	if outputFile := ctx.String(reportOutputFlag); outputFile != "" {
+		f, err := os.Create(outputFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if format == reportFormatParquet {
+		return writeParquetReport(out, report.Entries)
+	}
+
+	return writeCsvReport(out, report.Entries)
+}
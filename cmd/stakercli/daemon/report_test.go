@@ -0,0 +1,102 @@
+package daemon
+
+import (
+	"bytes"
+	"testing"
+
+	service "github.com/babylonchain/btc-staker/stakerservice"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+func testReportEntries() []service.StakingReportEntry {
+	return []service.StakingReportEntry{
+		{
+			StakingTxHash:       "6f0f1a7c5a1e22e3bd62b7b8fbdc2fa5f2ed24a3d0e6b1c2f4a7d9e8c1b0a4f2",
+			StakerAddress:       "bc1qstaker",
+			StakingState:        "DELEGATION_ACTIVE",
+			Watched:             true,
+			StakingAmount:       "100000",
+			StakingTimeBlocks:   "1000",
+			FinalityProviderPks: []string{"fp1", "fp2"},
+			StakingTxFee:        "1500",
+			CreatedAt:           "1704067200",
+			ConfirmedOnBtcAt:    "1704070800",
+			Metadata:            map[string]string{"team": "b", "desk": "a"},
+		},
+		{
+			StakingTxHash:     "0f0e",
+			StakingState:      "SENT_TO_BTC",
+			StakingAmount:     "1099511627776",
+			StakingTimeBlocks: "64000",
+			CreatedAt:         "1704153600",
+		},
+	}
+}
+
+func TestWriteParquetReportReadBack(t *testing.T) {
+	entries := testReportEntries()
+
+	var out bytes.Buffer
+	require.NoError(t, writeParquetReport(&out, entries))
+
+	file, err := buffer.NewBufferFile(out.Bytes())
+	require.NoError(t, err)
+
+	pr, err := reader.NewParquetColumnReader(file, 1)
+	require.NoError(t, err)
+	defer pr.ReadStop()
+
+	require.Equal(t, int64(len(entries)), pr.GetNumRows())
+	require.Len(t, pr.SchemaHandler.ValueColumns, len(reportColumns))
+
+	expected := map[string][]interface{}{
+		"staking_tx_hash":         {entries[0].StakingTxHash, "0f0e"},
+		"staker_address":          {"bc1qstaker", ""},
+		"watched":                 {"true", "false"},
+		"staking_amount":          {int64(100000), int64(1 << 40)},
+		"finality_provider_pks":   {"fp1;fp2", ""},
+		"unbonding_tx_fee":        {int64(0), int64(0)},
+		"created_at":              {"2024-01-01T00:00:00Z", "2024-01-02T00:00:00Z"},
+		"confirmed_on_btc_at":     {"2024-01-01T01:00:00Z", ""},
+		"staking_tx_block_height": {int64(0), int64(0)},
+		"metadata":                {"desk=a;team=b", ""},
+	}
+
+	for _, c := range reportColumns {
+		path := common.PathToStr([]string{pr.SchemaHandler.GetRootExName(), c.name})
+
+		values, _, _, err := pr.ReadColumnByPath(path, pr.GetNumRows())
+		require.NoError(t, err, c.name)
+		require.Len(t, values, len(entries), c.name)
+
+		if e, ok := expected[c.name]; ok {
+			require.Equal(t, e, values, c.name)
+		}
+	}
+}
+
+func TestWriteParquetReportEmpty(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, writeParquetReport(&out, nil))
+
+	file, err := buffer.NewBufferFile(out.Bytes())
+	require.NoError(t, err)
+
+	pr, err := reader.NewParquetColumnReader(file, 1)
+	require.NoError(t, err)
+	defer pr.ReadStop()
+
+	require.Equal(t, int64(0), pr.GetNumRows())
+	require.Len(t, pr.SchemaHandler.ValueColumns, len(reportColumns))
+}
+
+func TestWriteParquetReportInvalidInt(t *testing.T) {
+	entries := testReportEntries()
+	entries[1].StakingTxFee = "not a number"
+
+	var out bytes.Buffer
+	require.ErrorContains(t, writeParquetReport(&out, entries), "staking_tx_fee")
+}
@@ -0,0 +1,66 @@
+package daemon
+
+import (
+	dc "github.com/babylonchain/btc-staker/stakerservice/client"
+	"github.com/urfave/cli"
+)
+
+const (
+	createdAfterFlag     = "created-after"
+	createdBeforeFlag    = "created-before"
+	transitionStateFlag  = "transition-state"
+	transitionAfterFlag  = "transition-after"
+	transitionBeforeFlag = "transition-before"
+)
+
+// timeFilterFlags restrict listed transactions by creation and state transition
+// time, shared by listing commands
+var timeFilterFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  createdAfterFlag,
+		Usage: "Return only transactions created at or after given time, in format YYYY-MM-DD or RFC3339",
+	},
+	cli.StringFlag{
+		Name:  createdBeforeFlag,
+		Usage: "Return only transactions created before given time, in format YYYY-MM-DD or RFC3339",
+	},
+	cli.StringFlag{
+		Name:  transitionStateFlag,
+		Usage: "Consider only transitions to given state in transition time range, e.g DELEGATION_ACTIVE",
+	},
+	cli.StringFlag{
+		Name:  transitionAfterFlag,
+		Usage: "Return only transactions which changed state at or after given time, in format YYYY-MM-DD or RFC3339",
+	},
+	cli.StringFlag{
+		Name:  transitionBeforeFlag,
+		Usage: "Return only transactions which changed state before given time, in format YYYY-MM-DD or RFC3339",
+	},
+}
+
+func parseTimeFilterFlags(ctx *cli.Context) (*dc.TimeFilter, error) {
+	var filter dc.TimeFilter
+	var err error
+
+	if filter.CreatedAfter, err = parseReportTime(ctx.String(createdAfterFlag), false); err != nil {
+		return nil, err
+	}
+
+	if filter.CreatedBefore, err = parseReportTime(ctx.String(createdBeforeFlag), false); err != nil {
+		return nil, err
+	}
+
+	if filter.TransitionAfter, err = parseReportTime(ctx.String(transitionAfterFlag), false); err != nil {
+		return nil, err
+	}
+
+	if filter.TransitionBefore, err = parseReportTime(ctx.String(transitionBeforeFlag), false); err != nil {
+		return nil, err
+	}
+
+	if state := ctx.String(transitionStateFlag); state != "" {
+		filter.TransitionState = &state
+	}
+
+	return &filter, nil
+}
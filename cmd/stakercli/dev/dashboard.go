@@ -0,0 +1,57 @@
+package dev
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/babylonchain/btc-staker/metrics"
+	"github.com/urfave/cli"
+)
+
+const (
+	dashboardKindFlag  = "kind"
+	dashboardTitleFlag = "title"
+	dashboardFileFlag  = "output-file"
+)
+
+var exportDashboardCmd = cli.Command{
+	Name:  "export-dashboard",
+	Usage: "Prints Grafana dashboard json showing metrics exported by the daemon",
+	Description: "Dashboard can be imported to Grafana as it is. It has data source variable, which must point " +
+		"to Prometheus scraping the daemon, and network variable, selecting daemons by btc network label " +
+		"set on all metrics.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  dashboardKindFlag,
+			Usage: fmt.Sprintf("Kind of daemon, one of: %s, %s (watch-only monitoring mode)", metrics.DashboardStaker, metrics.DashboardMonitor),
+			Value: metrics.DashboardStaker,
+		},
+		cli.StringFlag{
+			Name:  dashboardTitleFlag,
+			Usage: "Title of the dashboard",
+			Value: "BTC Staker",
+		},
+		cli.StringFlag{
+			Name:  dashboardFileFlag,
+			Usage: "Path of file to which dashboard is written, printed to stdout if not set",
+		},
+	},
+	Action: exportDashboard,
+}
+
+func exportDashboard(ctx *cli.Context) error {
+	dashboard, err := metrics.GrafanaDashboard(ctx.String(dashboardKindFlag), ctx.String(dashboardTitleFlag))
+
+	if err != nil {
+		return err
+	}
+
+	outputFile := ctx.String(dashboardFileFlag)
+
+	if outputFile == "" {
+		fmt.Println(string(dashboard))
+		return nil
+	}
+
+	return os.WriteFile(outputFile, dashboard, 0644)
+}
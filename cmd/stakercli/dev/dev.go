@@ -0,0 +1,119 @@
+package dev
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/babylonchain/btc-staker/mockbabylon"
+	"github.com/babylonchain/btc-staker/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+var DevCommands = []cli.Command{
+	{
+		Name:     "dev",
+		Usage:    "Tools for development and integration testing, never use them with real funds",
+		Category: "Development",
+		Subcommands: []cli.Command{
+			mockBabylonCmd,
+			loadTestCmd,
+			exportDashboardCmd,
+		},
+	},
+}
+
+const (
+	listenFlag   = "listen"
+	scenarioFlag = "scenario"
+	networkFlag  = "network"
+)
+
+var mockBabylonCmd = cli.Command{
+	Name:  "mock-babylon",
+	Usage: "Starts mock of babylon node, to which daemon connects when babylon mock-url option is set",
+	Description: "Mock serves staking params, finality providers, btc header depths and delegations, and signs " +
+		"unbonding transactions with covenant keys, according to the scenario. Keys not provided in the " +
+		"scenario are derived from fixed seeds, so the same scenario always produces the same results.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  listenFlag,
+			Usage: "host:port on which mock listens",
+			Value: "127.0.0.1:26660",
+		},
+		cli.StringFlag{
+			Name:  scenarioFlag,
+			Usage: "Path to json file with scenario, defaults are used if not set",
+		},
+		cli.StringFlag{
+			Name:  networkFlag,
+			Usage: "Bitcoin network one of (mainnet, testnet3, regtest, simnet, signet)",
+			Value: "regtest",
+		},
+	},
+	Action: mockBabylon,
+}
+
+func mockBabylon(ctx *cli.Context) error {
+	btcParams, err := utils.GetBtcNetworkParams(ctx.String(networkFlag))
+
+	if err != nil {
+		return err
+	}
+
+	scenario, err := mockbabylon.LoadScenario(ctx.String(scenarioFlag))
+
+	if err != nil {
+		return err
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(os.Stderr)
+
+	server, err := mockbabylon.NewServer(scenario, btcParams, logger)
+
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", ctx.String(listenFlag))
+
+	if err != nil {
+		return fmt.Errorf("unable to listen on %s: %w", ctx.String(listenFlag), err)
+	}
+
+	httpServer := &http.Server{
+		Handler:           server.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	quit := make(chan struct{})
+	go server.ProduceBlocks(quit)
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-interrupt
+		close(quit)
+		_ = httpServer.Close()
+	}()
+
+	for i, k := range server.CovenantKeys() {
+		logger.WithField("pk", fmt.Sprintf("%x", k.PubKey().SerializeCompressed())).Infof("Covenant member %d", i)
+	}
+
+	logger.WithField("address", listener.Addr().String()).Info("Mock babylon listening")
+
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
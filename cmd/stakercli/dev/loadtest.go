@@ -0,0 +1,570 @@
+package dev
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/babylonchain/btc-staker/cmd/stakercli/helpers"
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	dc "github.com/babylonchain/btc-staker/stakerservice/client"
+	"github.com/babylonchain/btc-staker/types"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/urfave/cli"
+)
+
+const (
+	daemonAddressFlag      = "daemon-address"
+	stakesFlag             = "stakes"
+	rateFlag               = "rate"
+	stakerAddressFlag      = "staker-address"
+	stakingAmountFlag      = "staking-amount"
+	stakingTimeFlag        = "staking-time"
+	finalityProviderPkFlag = "finality-provider-pk"
+	unbondFlag             = "unbond"
+	btcNodeHostFlag        = "btc-node-host"
+	btcNodeUserFlag        = "btc-node-user"
+	btcNodePassFlag        = "btc-node-pass"
+	mineIntervalFlag       = "mine-interval"
+	pollIntervalFlag       = "poll-interval"
+	dbFileFlag             = "db-file"
+	timeoutFlag            = "timeout"
+
+	// metadata key under which delegations created by load test are tagged
+	loadTestMetadataKey = "load-test"
+)
+
+var defaultDBFile = filepath.Join(scfg.DefaultDBConfig().DBPath, scfg.DefaultDBConfig().DBFileName)
+
+var loadTestCmd = cli.Command{
+	Name:  "load-test",
+	Usage: "Drives daemon running against regtest with synthetic staking traffic and reports its performance",
+	Description: "Sends --stakes staking requests at --rate requests per second, mines regtest blocks and polls " +
+		"every delegation until it is active on babylon, or until unbonding is confirmed on btc when --unbond is set. " +
+		"Reports throughput, latency of every pipeline stage and growth of the database file. Latencies are " +
+		"measured by polling, so they are accurate only up to --poll-interval. Staker address must be funded " +
+		"with enough mature outputs for all stakes.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  daemonAddressFlag,
+			Usage: "Full address of the staker daemon in format tcp:://<host>:<port>",
+			Value: "tcp://127.0.0.1:" + strconv.Itoa(scfg.DefaultRPCPort),
+		},
+		cli.IntFlag{
+			Name:  stakesFlag,
+			Usage: "Number of staking requests to send",
+			Value: 10,
+		},
+		cli.Float64Flag{
+			Name:  rateFlag,
+			Usage: "Number of staking requests sent per second",
+			Value: 1,
+		},
+		cli.StringFlag{
+			Name:     stakerAddressFlag,
+			Usage:    "Address of the staker, funded on regtest",
+			Required: true,
+		},
+		cli.Int64Flag{
+			Name:  stakingAmountFlag,
+			Usage: "Staking amount of every delegation in satoshis",
+			Value: 100000,
+		},
+		cli.Int64Flag{
+			Name:  stakingTimeFlag,
+			Usage: "Staking time of every delegation in btc blocks",
+			Value: 1000,
+		},
+		cli.StringFlag{
+			Name:  finalityProviderPkFlag,
+			Usage: "BTC public key of the finality provider in hex, first one registered on babylon is used if not set",
+		},
+		cli.BoolFlag{
+			Name:  unbondFlag,
+			Usage: "Unbond every delegation once it is active and wait until unbonding is confirmed on btc",
+		},
+		cli.StringFlag{
+			Name:  btcNodeHostFlag,
+			Usage: "Host of regtest btc node rpc",
+			Value: "127.0.0.1:18443",
+		},
+		cli.StringFlag{
+			Name:  btcNodeUserFlag,
+			Usage: "Btc node rpc user",
+		},
+		cli.StringFlag{
+			Name:  btcNodePassFlag,
+			Usage: "Btc node rpc password",
+		},
+		cli.DurationFlag{
+			Name:  mineIntervalFlag,
+			Usage: "Interval in which regtest blocks are mined, 0 disables mining when blocks are produced by other means",
+			Value: 5 * time.Second,
+		},
+		cli.DurationFlag{
+			Name:  pollIntervalFlag,
+			Usage: "Interval in which state of delegations is polled",
+			Value: time.Second,
+		},
+		cli.StringFlag{
+			Name:  dbFileFlag,
+			Usage: "Path to database file of the daemon, used to measure database growth",
+			Value: defaultDBFile,
+		},
+		cli.DurationFlag{
+			Name:  timeoutFlag,
+			Usage: "Maximum duration of the test, delegations which did not finish by then are reported as failed",
+			Value: 30 * time.Minute,
+		},
+	},
+	Action: loadTest,
+}
+
+type LoadTestStageReport struct {
+	Stage string `json:"stage"`
+	// number of delegations which went through the stage
+	Count int   `json:"count"`
+	P50Ms int64 `json:"p50_ms"`
+	P90Ms int64 `json:"p90_ms"`
+	P99Ms int64 `json:"p99_ms"`
+	MaxMs int64 `json:"max_ms"`
+}
+
+type LoadTestReport struct {
+	Stakes    int `json:"stakes"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+	// number of occurrences of every distinct error
+	Errors          map[string]int `json:"errors,omitempty"`
+	DurationSeconds float64        `json:"duration_seconds"`
+	// rate in which staking requests were actually accepted by the daemon
+	SendRate float64 `json:"send_rate"`
+	// completed delegations per second over whole test
+	Throughput   float64               `json:"throughput"`
+	Stages       []LoadTestStageReport `json:"stages"`
+	DBFile       string                `json:"db_file,omitempty"`
+	DBSizeBefore *int64                `json:"db_size_before,omitempty"`
+	DBSizeAfter  *int64                `json:"db_size_after,omitempty"`
+	DBGrowth     *int64                `json:"db_growth,omitempty"`
+}
+
+// loadTestStages are states through which every delegation goes, in order.
+// Latency of the stage is time between reaching previous stage and reaching
+// this one, latency of the first one is duration of stake request.
+var loadTestStages = []types.DelegationState{
+	types.DelegationStateSentToBtc,
+	types.DelegationStateConfirmedOnBtc,
+	types.DelegationStateSentToBabylon,
+	types.DelegationStateActive,
+	types.DelegationStateUnbondingConfirmedOnBtc,
+}
+
+type loadTestDelegation struct {
+	txHash    string
+	startedAt time.Time
+	// time at which each stage was first observed
+	reached       []time.Time
+	unbondingSent bool
+	done          bool
+	err           error
+}
+
+func (d *loadTestDelegation) markReached(stageIdx int, at time.Time) {
+	// stages skipped between two polls are marked as reached together with
+	// the observed one
+	for i := 0; i <= stageIdx; i++ {
+		if d.reached[i].IsZero() {
+			d.reached[i] = at
+		}
+	}
+}
+
+func stageIndex(state string) int {
+	for i, s := range loadTestStages {
+		if string(s) == state {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func percentile(sorted []time.Duration, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	idx := int(float64(len(sorted)-1) * p)
+
+	return sorted[idx].Milliseconds()
+}
+
+func stageReport(name string, latencies []time.Duration) LoadTestStageReport {
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+
+	return LoadTestStageReport{
+		Stage: name,
+		Count: len(latencies),
+		P50Ms: percentile(latencies, 0.5),
+		P90Ms: percentile(latencies, 0.9),
+		P99Ms: percentile(latencies, 0.99),
+		MaxMs: percentile(latencies, 1),
+	}
+}
+
+func fileSize(path string) *int64 {
+	info, err := os.Stat(path)
+
+	if err != nil {
+		return nil
+	}
+
+	size := info.Size()
+	return &size
+}
+
+func newBtcNodeClient(host, user, pass string) (*rpcclient.Client, error) {
+	return rpcclient.New(&rpcclient.ConnConfig{
+		Host:         host,
+		User:         user,
+		Pass:         pass,
+		DisableTLS:   true,
+		HTTPPostMode: true,
+	}, nil)
+}
+
+// mineBlocks mines regtest block to given address in every interval until quit
+// is closed
+func mineBlocks(btcClient *rpcclient.Client, address btcutil.Address, interval time.Duration, quit <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := btcClient.GenerateToAddress(1, address, nil); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to mine block: %v\n", err)
+			}
+		case <-quit:
+			return
+		}
+	}
+}
+
+func loadTest(ctx *cli.Context) error {
+	numStakes := ctx.Int(stakesFlag)
+	rate := ctx.Float64(rateFlag)
+	unbond := ctx.Bool(unbondFlag)
+
+	if numStakes <= 0 {
+		return cli.NewExitError("number of stakes must be positive", 1)
+	}
+
+	if rate <= 0 {
+		return cli.NewExitError("rate must be positive", 1)
+	}
+
+	btcClient, err := newBtcNodeClient(
+		ctx.String(btcNodeHostFlag),
+		ctx.String(btcNodeUserFlag),
+		ctx.String(btcNodePassFlag),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	defer btcClient.Shutdown()
+
+	chainInfo, err := btcClient.GetBlockChainInfo()
+
+	if err != nil {
+		return fmt.Errorf("failed to query btc node: %w", err)
+	}
+
+	// load test spends real funds of the staker address, so it is never run
+	// against anything else than regtest
+	if chainInfo.Chain != "regtest" {
+		return cli.NewExitError(fmt.Sprintf("load test can only run against regtest, btc node is on %s", chainInfo.Chain), 1)
+	}
+
+	stakerAddress := ctx.String(stakerAddressFlag)
+
+	minerAddress, err := btcutil.DecodeAddress(stakerAddress, &chaincfg.RegressionNetParams)
+
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("invalid regtest staker address: %s", err), 1)
+	}
+
+	client, err := dc.NewStakerServiceJsonRpcClient(ctx.String(daemonAddressFlag))
+
+	if err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithTimeout(context.Background(), ctx.Duration(timeoutFlag))
+	defer cancel()
+
+	fpPk := ctx.String(finalityProviderPkFlag)
+
+	if fpPk == "" {
+		fps, err := client.BabylonFinalityProviders(runCtx, nil, nil)
+
+		if err != nil {
+			return err
+		}
+
+		if len(fps.FinalityProviders) == 0 {
+			return cli.NewExitError("no finality providers registered on babylon", 1)
+		}
+
+		fpPk = fps.FinalityProviders[0].BtcPublicKey
+	}
+
+	report := LoadTestReport{
+		Stakes: numStakes,
+		Errors: make(map[string]int),
+		DBFile: ctx.String(dbFileFlag),
+	}
+
+	if report.DBFile != "" {
+		report.DBSizeBefore = fileSize(report.DBFile)
+	}
+
+	quit := make(chan struct{})
+	defer close(quit)
+
+	if interval := ctx.Duration(mineIntervalFlag); interval > 0 {
+		go mineBlocks(btcClient, minerAddress, interval, quit)
+	}
+
+	var (
+		mu          sync.Mutex
+		delegations = make([]*loadTestDelegation, numStakes)
+		wg          sync.WaitGroup
+	)
+
+	runId := strconv.FormatInt(time.Now().UnixNano(), 10)
+	metadata := map[string]string{loadTestMetadataKey: runId}
+	testStart := time.Now()
+
+	var lastAccepted time.Time
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+		defer ticker.Stop()
+
+		for i := 0; i < numStakes; i++ {
+			if i > 0 {
+				select {
+				case <-ticker.C:
+				case <-runCtx.Done():
+					return
+				}
+			}
+
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				d := &loadTestDelegation{
+					startedAt: time.Now(),
+					reached:   make([]time.Time, len(loadTestStages)),
+				}
+
+				res, err := client.Stake(
+					runCtx,
+					stakerAddress,
+					ctx.Int64(stakingAmountFlag),
+					[]string{fpPk},
+					ctx.Int64(stakingTimeFlag),
+					&dc.StakeOptions{
+						Metadata:  metadata,
+						RequestId: fmt.Sprintf("load-test-%s-%d", runId, i),
+					},
+				)
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				if err != nil {
+					d.err = fmt.Errorf("stake: %w", err)
+				} else {
+					d.txHash = res.TxHash
+					d.markReached(0, time.Now())
+					lastAccepted = time.Now()
+				}
+
+				delegations[i] = d
+			}(i)
+		}
+	}()
+
+	// index of the stage after which delegation is finished
+	finalStage := stageIndex(string(types.DelegationStateActive))
+
+	if unbond {
+		finalStage = len(loadTestStages) - 1
+	}
+
+	pollTicker := time.NewTicker(ctx.Duration(pollIntervalFlag))
+	defer pollTicker.Stop()
+
+	for {
+		select {
+		case <-pollTicker.C:
+		case <-runCtx.Done():
+		}
+
+		mu.Lock()
+		var pending []*loadTestDelegation
+		for _, d := range delegations {
+			if d != nil && !d.done && d.err == nil {
+				pending = append(pending, d)
+			}
+		}
+		mu.Unlock()
+
+		for _, d := range pending {
+			details, err := client.StakingDetails(runCtx, d.txHash)
+
+			if err != nil {
+				if runCtx.Err() != nil {
+					break
+				}
+
+				fmt.Fprintf(os.Stderr, "failed to poll delegation %s: %v\n", d.txHash, err)
+				continue
+			}
+
+			now := time.Now()
+			idx := stageIndex(details.StakingState)
+
+			mu.Lock()
+			if idx >= 0 {
+				d.markReached(idx, now)
+			} else if details.StakingState == string(types.DelegationStateSpentOnBtc) {
+				d.err = fmt.Errorf("delegation spent before load test finished")
+			}
+
+			if idx >= finalStage {
+				d.done = true
+			}
+			mu.Unlock()
+
+			if unbond && !d.unbondingSent && idx == stageIndex(string(types.DelegationStateActive)) {
+				if _, err := client.UnbondStaking(runCtx, d.txHash, nil, nil); err != nil {
+					mu.Lock()
+					d.err = fmt.Errorf("unbond: %w", err)
+					mu.Unlock()
+					continue
+				}
+
+				d.unbondingSent = true
+			}
+		}
+
+		mu.Lock()
+		finished := 0
+		for _, d := range delegations {
+			if d != nil && (d.done || d.err != nil) {
+				finished++
+			}
+		}
+		mu.Unlock()
+
+		if finished == numStakes || runCtx.Err() != nil {
+			break
+		}
+	}
+
+	testDuration := time.Since(testStart)
+
+	// stop in flight stake requests if the test timed out
+	cancel()
+	wg.Wait()
+
+	stageLatencies := make([][]time.Duration, finalStage+1)
+	var totalLatencies []time.Duration
+	accepted := 0
+
+	for _, d := range delegations {
+		if d == nil {
+			report.Failed++
+			report.Errors["stake request not sent before timeout"]++
+			continue
+		}
+
+		if d.txHash != "" {
+			accepted++
+		}
+
+		for i := 0; i <= finalStage; i++ {
+			if d.reached[i].IsZero() {
+				break
+			}
+
+			prev := d.startedAt
+			if i > 0 {
+				prev = d.reached[i-1]
+			}
+
+			stageLatencies[i] = append(stageLatencies[i], d.reached[i].Sub(prev))
+		}
+
+		switch {
+		case d.done:
+			report.Completed++
+			totalLatencies = append(totalLatencies, d.reached[finalStage].Sub(d.startedAt))
+		case d.err != nil:
+			report.Failed++
+			report.Errors[d.err.Error()]++
+		default:
+			report.Failed++
+			report.Errors["delegation not finished before timeout"]++
+		}
+	}
+
+	for i, latencies := range stageLatencies {
+		report.Stages = append(report.Stages, stageReport(string(loadTestStages[i]), latencies))
+	}
+
+	report.Stages = append(report.Stages, stageReport("TOTAL", totalLatencies))
+
+	report.DurationSeconds = testDuration.Seconds()
+	report.Throughput = float64(report.Completed) / testDuration.Seconds()
+
+	if sendDuration := lastAccepted.Sub(testStart).Seconds(); sendDuration > 0 {
+		report.SendRate = float64(accepted) / sendDuration
+	}
+
+	if report.DBFile != "" {
+		report.DBSizeAfter = fileSize(report.DBFile)
+
+		if report.DBSizeBefore != nil && report.DBSizeAfter != nil {
+			growth := *report.DBSizeAfter - *report.DBSizeBefore
+			report.DBGrowth = &growth
+		}
+	}
+
+	helpers.PrintRespJSON(report)
+
+	if report.Failed > 0 {
+		return errors.New("some delegations did not finish")
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package helpers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+
+	service "github.com/babylonchain/btc-staker/stakerservice"
+	"github.com/urfave/cli"
+)
+
+// Process exit codes of stakercli. Codes are part of the cli interface used by
+// scripts, so existing codes must never be changed.
+const (
+	ExitCodeGeneral = 1
+	// invalid or missing command arguments
+	ExitCodeInvalidArguments = 2
+	// daemon could not be reached
+	ExitCodeDaemonUnavailable = 3
+	// btc node, wallet or babylon node used by the daemon is unavailable
+	ExitCodeBackendUnavailable = 4
+	ExitCodeNotFound           = 5
+	// operation is not allowed in current state or conflicts with other data
+	ExitCodeConflict          = 6
+	ExitCodeInsufficientFunds = 7
+	ExitCodeWalletLocked      = 8
+	// call was denied by acl, missing operator token or policy service
+	ExitCodeDenied = 9
+	// call was queued and waits for approval of second operator
+	ExitCodeApprovalRequired = 10
+)
+
+// CliError is printed to stderr as json when json errors are enabled
+type CliError struct {
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	Hint     string `json:"hint,omitempty"`
+	ExitCode int    `json:"exit_code"`
+}
+
+const (
+	cliErrCodeInvalidArguments  = "invalid_arguments"
+	cliErrCodeDaemonUnavailable = "daemon_unavailable"
+	cliErrCodeError             = "error"
+)
+
+type rpcErrorClass struct {
+	exitCode int
+	hint     string
+}
+
+var rpcErrorClasses = map[service.ErrorCode]rpcErrorClass{
+	service.ErrCodeInvalidParams: {
+		exitCode: ExitCodeInvalidArguments,
+		hint:     "check parameters of the command",
+	},
+	service.ErrCodeInsufficientFunds: {
+		exitCode: ExitCodeInsufficientFunds,
+		hint:     "fund the wallet or lower the amount or fee rate",
+	},
+	service.ErrCodeWalletLocked: {
+		exitCode: ExitCodeWalletLocked,
+		hint:     "unlock the btc wallet used by the daemon",
+	},
+	service.ErrCodeBtcBackendUnavailable: {
+		exitCode: ExitCodeBackendUnavailable,
+		hint:     "check connection between the daemon and its btc node",
+	},
+	service.ErrCodeBabylonUnavailable: {
+		exitCode: ExitCodeBackendUnavailable,
+		hint:     "check connection between the daemon and its babylon node",
+	},
+	service.ErrCodeNotFound: {
+		exitCode: ExitCodeNotFound,
+	},
+	service.ErrCodeConflict: {
+		exitCode: ExitCodeConflict,
+		hint:     "check current state with staking-details command",
+	},
+	service.ErrCodeForbidden: {
+		exitCode: ExitCodeDenied,
+		hint:     "check rpc access control configuration of the daemon",
+	},
+	service.ErrCodePolicyRejected: {
+		exitCode: ExitCodeDenied,
+	},
+	service.ErrCodeUnauthorized: {
+		exitCode: ExitCodeDenied,
+		hint:     "set operator token in STAKER_OPERATOR_TOKEN environment variable",
+	},
+	service.ErrCodeApprovalRequired: {
+		exitCode: ExitCodeApprovalRequired,
+		hint:     "second operator must approve the action with approve-action command",
+	},
+}
+
+func isDaemonUnavailable(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// ClassifyError maps error returned by stakercli command to its CliError
+func ClassifyError(err error) *CliError {
+	if errData, parseErr := service.ParseRpcErrorData(err.Error()); parseErr == nil && errData.ErrorCode != "" {
+		class, found := rpcErrorClasses[errData.ErrorCode]
+
+		if !found {
+			class = rpcErrorClass{exitCode: ExitCodeGeneral}
+		}
+
+		return &CliError{
+			Code:     string(errData.ErrorCode),
+			Message:  errData.Message,
+			Hint:     class.hint,
+			ExitCode: class.exitCode,
+		}
+	}
+
+	if isDaemonUnavailable(err) {
+		return &CliError{
+			Code:     cliErrCodeDaemonUnavailable,
+			Message:  err.Error(),
+			Hint:     "check that stakerd is running and --daemon-address is correct",
+			ExitCode: ExitCodeDaemonUnavailable,
+		}
+	}
+
+	var exitErr cli.ExitCoder
+	// commands report invalid arguments as exit errors, the same does cli
+	// library for missing required flags
+	if errors.As(err, &exitErr) || strings.HasPrefix(err.Error(), "Required flag") {
+		return &CliError{
+			Code:     cliErrCodeInvalidArguments,
+			Message:  err.Error(),
+			Hint:     "run the command with --help to see its usage",
+			ExitCode: ExitCodeInvalidArguments,
+		}
+	}
+
+	return &CliError{
+		Code:     cliErrCodeError,
+		Message:  err.Error(),
+		ExitCode: ExitCodeGeneral,
+	}
+}
+
+// PrintError writes error to w either as single line json or as plain text, and
+// returns exit code of the error
+func PrintError(w io.Writer, err error, asJSON bool) int {
+	cliErr := ClassifyError(err)
+
+	if asJSON {
+		jsonBytes, marshalErr := json.Marshal(cliErr)
+
+		if marshalErr == nil {
+			fmt.Fprintf(w, "%s\n", jsonBytes)
+			return cliErr.ExitCode
+		}
+	}
+
+	fmt.Fprintf(w, "[btc-staker] %v\n", err)
+
+	if cliErr.Hint != "" {
+		fmt.Fprintf(w, "hint: %s\n", cliErr.Hint)
+	}
+
+	return cliErr.ExitCode
+}
@@ -0,0 +1,264 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli"
+)
+
+const (
+	OutputFlag = "output"
+
+	OutputFormatJSON  = "json"
+	OutputFormatTable = "table"
+)
+
+var OutputFormatFlag = cli.StringFlag{
+	Name:  OutputFlag,
+	Usage: fmt.Sprintf("Output format, one of: %s, %s", OutputFormatJSON, OutputFormatTable),
+	Value: OutputFormatJSON,
+}
+
+// PrintResp prints response in format selected by OutputFlag. Commands without
+// the flag print json.
+func PrintResp(ctx *cli.Context, resp interface{}) error {
+	switch format := ctx.String(OutputFlag); format {
+	case "", OutputFormatJSON:
+		PrintRespJSON(resp)
+		return nil
+	case OutputFormatTable:
+		return PrintRespTable(os.Stdout, resp)
+	default:
+		return cli.NewExitError(fmt.Sprintf("unknown output format %s", format), 1)
+	}
+}
+
+// orderedObject is json object which keeps order of its keys, so that table
+// columns follow order of fields in response structs
+type orderedObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, isDelim := token.(json.Delim)
+
+	if !isDelim {
+		return token, nil
+	}
+
+	switch delim {
+	case '{':
+		obj := &orderedObject{values: make(map[string]interface{})}
+
+		for dec.More() {
+			keyToken, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			key, ok := keyToken.(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected json object key %v", keyToken)
+			}
+
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			obj.keys = append(obj.keys, key)
+			obj.values[key] = value
+		}
+
+		// closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+
+		return obj, nil
+	case '[':
+		list := make([]interface{}, 0)
+
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			list = append(list, value)
+		}
+
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+
+		return list, nil
+	default:
+		return nil, fmt.Errorf("unexpected json delimiter %s", delim)
+	}
+}
+
+// formatCell renders value in single table cell, nested objects and lists are
+// flattened to comma separated entries
+func formatCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case *orderedObject:
+		entries := make([]string, 0, len(v.keys))
+		for _, k := range v.keys {
+			entries = append(entries, fmt.Sprintf("%s=%s", k, formatCell(v.values[k])))
+		}
+		return strings.Join(entries, ",")
+	case []interface{}:
+		entries := make([]string, 0, len(v))
+		for _, e := range v {
+			entries = append(entries, formatCell(e))
+		}
+		return strings.Join(entries, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type namedTable struct {
+	name string
+	rows []interface{}
+}
+
+func isObjectList(value interface{}) bool {
+	list, ok := value.([]interface{})
+
+	if !ok || len(list) == 0 {
+		return false
+	}
+
+	for _, e := range list {
+		if _, isObject := e.(*orderedObject); !isObject {
+			return false
+		}
+	}
+
+	return true
+}
+
+// writeRows writes list of objects as table with one column per key. Columns
+// are union of keys of all objects, in order of first appearance.
+func writeRows(w io.Writer, list []interface{}) {
+	var columns []string
+	seen := make(map[string]struct{})
+
+	for _, e := range list {
+		for _, k := range e.(*orderedObject).keys {
+			if _, found := seen[k]; !found {
+				seen[k] = struct{}{}
+				columns = append(columns, k)
+			}
+		}
+	}
+
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = strings.ToUpper(c)
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+
+	for _, e := range list {
+		obj := e.(*orderedObject)
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			row[i] = formatCell(obj.values[c])
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+}
+
+// writeFields writes fields of the object as key value pairs and returns number
+// of written lines. Nested objects are written with dotted keys, lists of
+// objects are collected to tables and written after all other fields.
+func writeFields(w io.Writer, prefix string, obj *orderedObject, tables *[]namedTable) int {
+	written := 0
+
+	for _, k := range obj.keys {
+		key := prefix + k
+		value := obj.values[k]
+
+		if nested, isObject := value.(*orderedObject); isObject {
+			written += writeFields(w, key+".", nested, tables)
+			continue
+		}
+
+		if isObjectList(value) {
+			*tables = append(*tables, namedTable{name: key, rows: value.([]interface{})})
+			continue
+		}
+
+		fmt.Fprintf(w, "%s\t%s\n", key, formatCell(value))
+		written++
+	}
+
+	return written
+}
+
+// PrintRespTable prints response as human readable table. Lists of objects are
+// printed with one row per object, all other values as key value pairs.
+func PrintRespTable(out io.Writer, resp interface{}) error {
+	jsonBytes, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(jsonBytes))
+	dec.UseNumber()
+
+	decoded, err := decodeOrdered(dec)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	switch v := decoded.(type) {
+	case *orderedObject:
+		var tables []namedTable
+
+		written := writeFields(w, "", v, &tables)
+
+		for i, table := range tables {
+			// response which consists of single list is printed as plain table
+			if written == 0 && len(tables) == 1 {
+				writeRows(w, table.rows)
+				break
+			}
+
+			if written > 0 || i > 0 {
+				fmt.Fprintln(w)
+			}
+
+			fmt.Fprintf(w, "%s:\n", table.name)
+			writeRows(w, table.rows)
+		}
+	case []interface{}:
+		if isObjectList(v) {
+			writeRows(w, v)
+		} else {
+			fmt.Fprintln(w, formatCell(v))
+		}
+	default:
+		fmt.Fprintln(w, formatCell(v))
+	}
+
+	return w.Flush()
+}
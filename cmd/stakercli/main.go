@@ -1,18 +1,19 @@
 package main
 
 import (
-	"fmt"
 	"os"
 
 	cmdadmin "github.com/babylonchain/btc-staker/cmd/stakercli/admin"
 	cmddaemon "github.com/babylonchain/btc-staker/cmd/stakercli/daemon"
+	cmddev "github.com/babylonchain/btc-staker/cmd/stakercli/dev"
+	"github.com/babylonchain/btc-staker/cmd/stakercli/helpers"
 	cmdtx "github.com/babylonchain/btc-staker/cmd/stakercli/transaction"
+	cmdwizard "github.com/babylonchain/btc-staker/cmd/stakercli/wizard"
 	"github.com/urfave/cli"
 )
 
-func fatal(err error) {
-	fmt.Fprintf(os.Stderr, "[btc-staker] %v\n", err)
-	os.Exit(1)
+func fatal(err error, jsonErrors bool) {
+	os.Exit(helpers.PrintError(os.Stderr, err, jsonErrors))
 }
 
 const (
@@ -22,6 +23,7 @@ const (
 	btcWalletRpcPassFlag    = "btc-wallet-rpc-pass"
 	btcWalletPassphraseFlag = "btc-wallet-passphrase"
 	btcWalletBackendFlag    = "btc-wallet-backend"
+	jsonErrorsFlag          = "json-errors"
 )
 
 func main() {
@@ -58,13 +60,29 @@ func main() {
 			Usage: "Bitcoin backend (btcwallet|bitcoind)",
 			Value: "btcd",
 		},
+		cli.BoolFlag{
+			Name:   jsonErrorsFlag,
+			Usage:  "Print errors to stderr as json with code, message and hint",
+			EnvVar: "STAKERCLI_JSON_ERRORS",
+		},
+	}
+
+	jsonErrors := false
+	app.Before = func(ctx *cli.Context) error {
+		jsonErrors = ctx.GlobalBool(jsonErrorsFlag)
+		return nil
 	}
+	// errors are printed and mapped to exit codes after app returns, instead of
+	// exiting inside of the cli library
+	app.ExitErrHandler = func(_ *cli.Context, _ error) {}
 
 	app.Commands = append(app.Commands, cmddaemon.DaemonCommands...)
 	app.Commands = append(app.Commands, cmdadmin.AdminCommands...)
 	app.Commands = append(app.Commands, cmdtx.TransactionCommands...)
+	app.Commands = append(app.Commands, cmdwizard.WizardCommands...)
+	app.Commands = append(app.Commands, cmddev.DevCommands...)
 
 	if err := app.Run(os.Args); err != nil {
-		fatal(err)
+		fatal(err, jsonErrors)
 	}
 }
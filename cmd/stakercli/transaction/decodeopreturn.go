@@ -0,0 +1,83 @@
+package transaction
+
+import (
+	"encoding/hex"
+
+	bbn "github.com/babylonchain/babylon/types"
+	"github.com/babylonchain/btc-staker/cmd/stakercli/helpers"
+	"github.com/babylonchain/btc-staker/opreturn"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/urfave/cli"
+)
+
+var decodePhase1OpReturnCmd = cli.Command{
+	Name:      "decode-phase1-op-return",
+	ShortName: "dpor",
+	Usage:     "Decodes op_return output of phase 1 staking transaction",
+	Description: "Looks for op_return output tagged with --magic-bytes, or any staking op_return output if magic " +
+		"bytes are not provided. Outputs of versions not supported by this build are reported with raw payload.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:     stakingTransactionFlag,
+			Usage:    "Staking transaction in hex",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:  magicBytesFlag,
+			Usage: "Magic bytes in op_return output in hex",
+		},
+	},
+	Action: decodePhase1OpReturn,
+}
+
+type DecodePhase1OpReturnResponse struct {
+	OutputIdx          int    `json:"output_idx"`
+	MagicBytes         string `json:"magic_bytes"`
+	Version            uint8  `json:"version"`
+	Supported          bool   `json:"supported"`
+	StakerPk           string `json:"staker_pk,omitempty"`
+	FinalityProviderPk string `json:"finality_provider_pk,omitempty"`
+	StakingTime        uint16 `json:"staking_time,omitempty"`
+	Payload            string `json:"payload"`
+}
+
+func decodePhase1OpReturn(ctx *cli.Context) error {
+	tx, _, err := bbn.NewBTCTxFromHex(ctx.String(stakingTransactionFlag))
+
+	if err != nil {
+		return err
+	}
+
+	var magicBytes []byte
+
+	if ctx.IsSet(magicBytesFlag) {
+		magicBytes, err = parseMagicBytesFromCliCtx(ctx)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	idx, d, err := opreturn.FindInTx(tx, magicBytes)
+
+	if err != nil {
+		return err
+	}
+
+	resp := DecodePhase1OpReturnResponse{
+		OutputIdx:  idx,
+		MagicBytes: hex.EncodeToString(d.MagicBytes),
+		Version:    d.Version,
+		Supported:  d.Supported,
+		Payload:    hex.EncodeToString(d.Payload),
+	}
+
+	if d.Supported {
+		resp.StakerPk = hex.EncodeToString(schnorr.SerializePubKey(d.StakerPk))
+		resp.FinalityProviderPk = hex.EncodeToString(schnorr.SerializePubKey(d.FinalityProviderPk))
+		resp.StakingTime = d.StakingTime
+	}
+
+	helpers.PrintRespJSON(resp)
+	return nil
+}
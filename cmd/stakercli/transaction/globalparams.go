@@ -0,0 +1,149 @@
+package transaction
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// VersionedGlobalParams are phase-1 staking parameters which apply to staking
+// transactions included in btc blocks starting from ActivationHeight until
+// activation of the next version
+type VersionedGlobalParams struct {
+	Version           uint64   `json:"version"`
+	ActivationHeight  uint64   `json:"activation_height"`
+	StakingCap        uint64   `json:"staking_cap"`
+	Tag               string   `json:"tag"`
+	CovenantPks       []string `json:"covenant_pks"`
+	CovenantQuorum    uint64   `json:"covenant_quorum"`
+	UnbondingTime     uint64   `json:"unbonding_time"`
+	UnbondingFee      uint64   `json:"unbonding_fee"`
+	MaxStakingAmount  uint64   `json:"max_staking_amount"`
+	MinStakingAmount  uint64   `json:"min_staking_amount"`
+	MaxStakingTime    uint64   `json:"max_staking_time"`
+	MinStakingTime    uint64   `json:"min_staking_time"`
+	ConfirmationDepth uint64   `json:"confirmation_depth"`
+}
+
+// GlobalParams is content of phase-1 global parameters file
+type GlobalParams struct {
+	Versions []*VersionedGlobalParams `json:"versions"`
+}
+
+// ParsedVersionedGlobalParams are VersionedGlobalParams with decoded keys and
+// tag
+type ParsedVersionedGlobalParams struct {
+	Version          uint64
+	ActivationHeight uint64
+	MagicBytes       []byte
+	CovenantPks      []*btcec.PublicKey
+	CovenantQuorum   uint32
+	MinStakingAmount btcutil.Amount
+	MaxStakingAmount btcutil.Amount
+	MinStakingTime   uint16
+	MaxStakingTime   uint16
+}
+
+func parseVersionedGlobalParams(p *VersionedGlobalParams) (*ParsedVersionedGlobalParams, error) {
+	magicBytes, err := parseMagicBytesFromHex(p.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag: %w", err)
+	}
+
+	covenantPks, err := parseCovenantKeysFromSlice(p.CovenantPks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid covenant public keys: %w", err)
+	}
+
+	if p.CovenantQuorum == 0 || p.CovenantQuorum > uint64(len(covenantPks)) {
+		return nil, fmt.Errorf("covenant quorum %d must be between 1 and number of covenant keys %d", p.CovenantQuorum, len(covenantPks))
+	}
+
+	if p.MinStakingAmount > p.MaxStakingAmount {
+		return nil, fmt.Errorf("min staking amount %d is greater than max staking amount %d", p.MinStakingAmount, p.MaxStakingAmount)
+	}
+
+	if p.MaxStakingTime > uint64(^uint16(0)) || p.MinStakingTime > p.MaxStakingTime {
+		return nil, fmt.Errorf("invalid staking time range [%d, %d]", p.MinStakingTime, p.MaxStakingTime)
+	}
+
+	return &ParsedVersionedGlobalParams{
+		Version:          p.Version,
+		ActivationHeight: p.ActivationHeight,
+		MagicBytes:       magicBytes,
+		CovenantPks:      covenantPks,
+		CovenantQuorum:   uint32(p.CovenantQuorum),
+		MinStakingAmount: btcutil.Amount(p.MinStakingAmount),
+		MaxStakingAmount: btcutil.Amount(p.MaxStakingAmount),
+		MinStakingTime:   uint16(p.MinStakingTime),
+		MaxStakingTime:   uint16(p.MaxStakingTime),
+	}, nil
+}
+
+// ReadGlobalParams reads and validates global parameters file. Versions must be
+// numbered from 0 and ordered by strictly increasing activation height.
+func ReadGlobalParams(path string) (*GlobalParams, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading global params file %s: %w", path, err)
+	}
+
+	var params GlobalParams
+	if err := json.Unmarshal(bz, &params); err != nil {
+		return nil, fmt.Errorf("error parsing global params file %s: %w", path, err)
+	}
+
+	if len(params.Versions) == 0 {
+		return nil, fmt.Errorf("global params file %s contains no versions", path)
+	}
+
+	for i, v := range params.Versions {
+		if v.Version != uint64(i) {
+			return nil, fmt.Errorf("global params version %d is at position %d, versions must be numbered from 0", v.Version, i)
+		}
+
+		if i > 0 && v.ActivationHeight <= params.Versions[i-1].ActivationHeight {
+			return nil, fmt.Errorf("activation height of global params version %d must be greater than activation height of previous version", v.Version)
+		}
+
+		if _, err := parseVersionedGlobalParams(v); err != nil {
+			return nil, fmt.Errorf("invalid global params version %d: %w", v.Version, err)
+		}
+	}
+
+	return &params, nil
+}
+
+// ParamsForHeight returns the params version active at given btc height i.e
+// the last version activated at or before the height
+func (g *GlobalParams) ParamsForHeight(height uint64) (*ParsedVersionedGlobalParams, error) {
+	for i := len(g.Versions) - 1; i >= 0; i-- {
+		if g.Versions[i].ActivationHeight <= height {
+			return parseVersionedGlobalParams(g.Versions[i])
+		}
+	}
+
+	return nil, fmt.Errorf("no global params version is active at height %d, first version activates at height %d",
+		height, g.Versions[0].ActivationHeight)
+}
+
+// TxInclusionHeight queries btc node for height of the block which includes given
+// transaction. Node must run with transaction index, unless the transaction
+// belongs to its wallet.
+func TxInclusionHeight(host, user, pass string, txHash *chainhash.Hash) (uint64, error) {
+	client, err := newBtcNodeClient(host, user, pass)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer client.Shutdown()
+
+	_, height, err := inclusionBlockOfTx(client, txHash)
+
+	return height, err
+}
@@ -0,0 +1,299 @@
+package transaction
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	bbn "github.com/babylonchain/babylon/types"
+	bcctypes "github.com/babylonchain/babylon/x/btccheckpoint/types"
+	"github.com/babylonchain/btc-staker/babylonclient"
+	"github.com/babylonchain/btc-staker/cmd/stakercli/helpers"
+	"github.com/babylonchain/btc-staker/utils"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/urfave/cli"
+)
+
+const (
+	txHashFlag     = "tx-hash"
+	esploraUrlFlag = "esplora-url"
+
+	esploraRequestTimeout = 30 * time.Second
+)
+
+var createInclusionProofCmd = cli.Command{
+	Name:      "create-inclusion-proof",
+	ShortName: "cip",
+	Usage:     "Builds merkle proof of inclusion of confirmed transaction in its btc block, in the format expected by Babylon",
+	Description: "Block with the transaction is fetched either from bitcoind rpc or, if --esplora-url is provided, " +
+		"from Esplora http api. Bitcoind needs txindex=1 to find transactions which do not belong to its wallet.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:     txHashFlag,
+			Usage:    "Hash of the confirmed transaction",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:  btcNodeHostFlag,
+			Usage: "Host of btc node rpc",
+			Value: "127.0.0.1:8332",
+		},
+		cli.StringFlag{
+			Name:  btcNodeUserFlag,
+			Usage: "Btc node rpc user",
+		},
+		cli.StringFlag{
+			Name:  btcNodePassFlag,
+			Usage: "Btc node rpc password",
+		},
+		cli.StringFlag{
+			Name:  esploraUrlFlag,
+			Usage: "Base url of Esplora api e.g https://mempool.space/signet/api, used instead of btc node if set",
+		},
+	},
+	Action: createInclusionProof,
+}
+
+// InclusionProofResponse carries all parts of the proof. TransactionInfoHex is
+// protobuf encoded babylon TransactionInfo, as embedded in delegation messages.
+type InclusionProofResponse struct {
+	TxHash             string `json:"tx_hash"`
+	BlockHash          string `json:"block_hash"`
+	BlockHeight        uint64 `json:"block_height"`
+	TxIndex            uint32 `json:"tx_index"`
+	BlockHeaderHex     string `json:"block_header_hex"`
+	TransactionHex     string `json:"transaction_hex"`
+	ProofHex           string `json:"proof_hex"`
+	TransactionInfoHex string `json:"transaction_info_hex"`
+}
+
+func newBtcNodeClient(host, user, pass string) (*rpcclient.Client, error) {
+	return rpcclient.New(&rpcclient.ConnConfig{
+		Host:         host,
+		User:         user,
+		Pass:         pass,
+		DisableTLS:   true,
+		HTTPPostMode: true,
+	}, nil)
+}
+
+// inclusionBlockOfTx returns hash and height of the block which includes given
+// transaction
+func inclusionBlockOfTx(client *rpcclient.Client, txHash *chainhash.Hash) (*chainhash.Hash, uint64, error) {
+	txInfo, err := client.GetRawTransactionVerbose(txHash)
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query transaction %s: %w", txHash, err)
+	}
+
+	if txInfo.BlockHash == "" {
+		return nil, 0, fmt.Errorf("transaction %s is not included in any block yet", txHash)
+	}
+
+	blockHash, err := chainhash.NewHashFromStr(txInfo.BlockHash)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	header, err := client.GetBlockHeaderVerbose(blockHash)
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query block header %s: %w", blockHash, err)
+	}
+
+	return blockHash, uint64(header.Height), nil
+}
+
+// blockOfTxFromNode returns block including given transaction together with its
+// height
+func blockOfTxFromNode(host, user, pass string, txHash *chainhash.Hash) (*wire.MsgBlock, uint64, error) {
+	client, err := newBtcNodeClient(host, user, pass)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer client.Shutdown()
+
+	blockHash, height, err := inclusionBlockOfTx(client, txHash)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	block, err := client.GetBlock(blockHash)
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query block %s: %w", blockHash, err)
+	}
+
+	return block, height, nil
+}
+
+type esploraTxStatus struct {
+	Confirmed   bool   `json:"confirmed"`
+	BlockHeight uint64 `json:"block_height"`
+	BlockHash   string `json:"block_hash"`
+}
+
+func esploraGet(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("esplora request %s failed with status %d: %s", url, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return body, nil
+}
+
+func blockOfTxFromEsplora(baseUrl string, txHash *chainhash.Hash) (*wire.MsgBlock, uint64, error) {
+	client := &http.Client{Timeout: esploraRequestTimeout}
+	baseUrl = strings.TrimSuffix(baseUrl, "/")
+
+	statusBytes, err := esploraGet(client, fmt.Sprintf("%s/tx/%s/status", baseUrl, txHash))
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var status esploraTxStatus
+	if err := json.Unmarshal(statusBytes, &status); err != nil {
+		return nil, 0, fmt.Errorf("invalid esplora transaction status: %w", err)
+	}
+
+	if !status.Confirmed {
+		return nil, 0, fmt.Errorf("transaction %s is not included in any block yet", txHash)
+	}
+
+	rawBlock, err := esploraGet(client, fmt.Sprintf("%s/block/%s/raw", baseUrl, status.BlockHash))
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var block wire.MsgBlock
+	if err := block.Deserialize(bytes.NewReader(rawBlock)); err != nil {
+		return nil, 0, fmt.Errorf("invalid block returned by esplora: %w", err)
+	}
+
+	if block.BlockHash().String() != status.BlockHash {
+		return nil, 0, fmt.Errorf("esplora returned block %s instead of requested block %s", block.BlockHash(), status.BlockHash)
+	}
+
+	return &block, status.BlockHeight, nil
+}
+
+// MakeInclusionProofResponse builds inclusion proof of transaction with given
+// hash in the block
+func MakeInclusionProofResponse(block *wire.MsgBlock, height uint64, txHash *chainhash.Hash) (*InclusionProofResponse, error) {
+	txIdx := -1
+	for i, tx := range block.Transactions {
+		if tx.TxHash() == *txHash {
+			txIdx = i
+			break
+		}
+	}
+
+	if txIdx < 0 {
+		return nil, fmt.Errorf("transaction %s not found in block %s", txHash, block.BlockHash())
+	}
+
+	proof, err := babylonclient.GenerateProof(block, uint32(txIdx))
+
+	if err != nil {
+		return nil, err
+	}
+
+	serializedTx, err := utils.SerializeBtcTransaction(block.Transactions[txIdx])
+
+	if err != nil {
+		return nil, err
+	}
+
+	headerBytes := bbn.NewBTCHeaderBytesFromBlockHeader(&block.Header)
+	inclusionBlockHash := block.BlockHash()
+	blockHash := bbn.NewBTCHeaderHashBytesFromChainhash(&inclusionBlockHash)
+
+	txInfo := bcctypes.TransactionInfo{
+		Key: &bcctypes.TransactionKey{
+			Index: uint32(txIdx),
+			Hash:  &blockHash,
+		},
+		Transaction: serializedTx,
+		Proof:       proof,
+	}
+
+	txInfoBytes, err := txInfo.Marshal()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &InclusionProofResponse{
+		TxHash:             txHash.String(),
+		BlockHash:          inclusionBlockHash.String(),
+		BlockHeight:        height,
+		TxIndex:            uint32(txIdx),
+		BlockHeaderHex:     hex.EncodeToString(headerBytes),
+		TransactionHex:     hex.EncodeToString(serializedTx),
+		ProofHex:           hex.EncodeToString(proof),
+		TransactionInfoHex: hex.EncodeToString(txInfoBytes),
+	}, nil
+}
+
+func createInclusionProof(ctx *cli.Context) error {
+	txHash, err := chainhash.NewHashFromStr(ctx.String(txHashFlag))
+
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("invalid transaction hash: %s", err), 1)
+	}
+
+	var (
+		block  *wire.MsgBlock
+		height uint64
+	)
+
+	if esploraUrl := ctx.String(esploraUrlFlag); esploraUrl != "" {
+		block, height, err = blockOfTxFromEsplora(esploraUrl, txHash)
+	} else {
+		block, height, err = blockOfTxFromNode(
+			ctx.String(btcNodeHostFlag),
+			ctx.String(btcNodeUserFlag),
+			ctx.String(btcNodePassFlag),
+			txHash,
+		)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	resp, err := MakeInclusionProofResponse(block, height, txHash)
+
+	if err != nil {
+		return err
+	}
+
+	helpers.PrintRespJSON(*resp)
+	return nil
+}
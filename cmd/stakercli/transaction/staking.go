@@ -3,6 +3,7 @@ package transaction
 import (
 	"fmt"
 
+	"github.com/babylonchain/btc-staker/scriptbuilder"
 	"github.com/babylonchain/btc-staker/utils"
 	"github.com/btcsuite/btcd/btcutil"
 )
@@ -27,6 +28,11 @@ type InputBtcStakingTx struct {
 	MagicBytesHex string `json:"magic_bytes"`
 	// CovenantQuorum the number of covenant required as quorum.
 	CovenantQuorum uint32 `json:"covenant_quorum"`
+	// OpReturnVersion version of op_return output, 0 if not provided.
+	OpReturnVersion uint `json:"op_return_version"`
+	// ScriptBuilder name of the builder of staking scripts, default builder if
+	// not provided.
+	ScriptBuilder string `json:"script_builder"`
 }
 
 // ToCreatePhase1StakingTxResponse from the data input parses and build parameters to create and serialize response tx structure.
@@ -56,8 +62,25 @@ func (tx InputBtcStakingTx) ToCreatePhase1StakingTxResponse() (*CreatePhase1Stak
 		return nil, fmt.Errorf("error parsing btc network %s: %w", tx.BtcNetwork, err)
 	}
 
+	opReturnVersion, err := parseOpReturnVersion(tx.OpReturnVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	builderName := tx.ScriptBuilder
+	if builderName == "" {
+		builderName = scriptbuilder.DefaultBuilderName
+	}
+
+	builder, err := scriptbuilder.Get(builderName)
+	if err != nil {
+		return nil, err
+	}
+
 	return MakeCreatePhase1StakingTxResponse(
+		builder,
 		magicBytes,
+		opReturnVersion,
 		stakerPk,
 		fpPk,
 		covenantMembersPks,
@@ -7,14 +7,16 @@ import (
 	"fmt"
 	"math"
 
-	"github.com/babylonchain/babylon/btcstaking"
 	bbn "github.com/babylonchain/babylon/types"
 	"github.com/babylonchain/btc-staker/cmd/stakercli/helpers"
+	"github.com/babylonchain/btc-staker/opreturn"
+	"github.com/babylonchain/btc-staker/scriptbuilder"
 	"github.com/babylonchain/btc-staker/utils"
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/cometbft/cometbft/libs/os"
 	"github.com/urfave/cli"
 )
@@ -27,6 +29,13 @@ const (
 	networkNameFlag         = "network"
 	stakerPublicKeyFlag     = "staker-pk"
 	finalityProviderKeyFlag = "finality-provider-pk"
+	globalParamsFlag        = "global-params"
+	btcHeightFlag           = "btc-height"
+	btcNodeHostFlag         = "btc-node-host"
+	btcNodeUserFlag         = "btc-node-user"
+	btcNodePassFlag         = "btc-node-pass"
+	opReturnVersionFlag     = "op-return-version"
+	scriptBuilderFlag       = "script-builder"
 )
 
 var TransactionCommands = []cli.Command{
@@ -39,6 +48,11 @@ var TransactionCommands = []cli.Command{
 			checkPhase1StakingTransactionCmd,
 			createPhase1StakingTransactionCmd,
 			createPhase1StakingTransactionFromJsonCmd,
+			createPhase1UnbondingTransactionCmd,
+			decodePhase1OpReturnCmd,
+			createInclusionProofCmd,
+			verifyInclusionProofCmd,
+			verifyProofOfReservesCmd,
 		},
 	},
 }
@@ -98,13 +112,17 @@ func parseMagicBytesFromHex(magicBytesHex string) ([]byte, error) {
 		return nil, err
 	}
 
-	if len(magicBytes) != btcstaking.MagicBytesLen {
-		return nil, fmt.Errorf("magic bytes should be of length %d", btcstaking.MagicBytesLen)
+	if len(magicBytes) != opreturn.MagicBytesLen {
+		return nil, fmt.Errorf("magic bytes should be of length %d", opreturn.MagicBytesLen)
 	}
 
 	return magicBytes, nil
 }
 
+func parseScriptBuilderFromCliCtx(ctx *cli.Context) (scriptbuilder.StakingScriptBuilder, error) {
+	return scriptbuilder.Get(ctx.String(scriptBuilderFlag))
+}
+
 func parseStakingAmountFromCliCtx(ctx *cli.Context) (btcutil.Amount, error) {
 	amt := ctx.Int64(helpers.StakingAmountFlag)
 
@@ -133,6 +151,9 @@ var checkPhase1StakingTransactionCmd = cli.Command{
 	Name:      "check-phase1-staking-transaction",
 	ShortName: "cpst",
 	Usage:     "Checks whether provided staking transactions is valid staking transaction (tx must be funded/have inputs)",
+	Description: "Transaction is checked either against parameters provided by flags, or against the version of " +
+		"global parameters active at btc height given by --btc-height flag. If height is not provided, inclusion " +
+		"height of the transaction is queried from btc node.",
 	Flags: []cli.Flag{
 		cli.StringFlag{
 			Name:     stakingTransactionFlag,
@@ -140,25 +161,48 @@ var checkPhase1StakingTransactionCmd = cli.Command{
 			Required: true,
 		},
 		cli.StringFlag{
-			Name:     magicBytesFlag,
-			Usage:    "Magic bytes in op return output in hex",
-			Required: true,
+			Name:  magicBytesFlag,
+			Usage: "Magic bytes in op return output in hex, required if global params are not provided",
 		},
 		cli.StringSliceFlag{
-			Name:     covenantMembersPksFlag,
-			Usage:    "BTC public keys of the covenant committee members",
-			Required: true,
+			Name:  covenantMembersPksFlag,
+			Usage: "BTC public keys of the covenant committee members, required if global params are not provided",
 		},
 		cli.Uint64Flag{
-			Name:     covenantQuorumFlag,
-			Usage:    "Required quorum for the covenant members",
-			Required: true,
+			Name:  covenantQuorumFlag,
+			Usage: "Required quorum for the covenant members, required if global params are not provided",
 		},
 		cli.StringFlag{
 			Name:     networkNameFlag,
-			Usage:    "Bitcoin network on which staking should take place one of (mainnet, testnet3, regtest, simnet, signet)",
+			Usage:    "Bitcoin network on which staking should take place one of (mainnet, testnet3, regtest, simnet, signet) or path to json file with custom network parameters",
 			Required: true,
 		},
+		cli.StringFlag{
+			Name:  globalParamsFlag,
+			Usage: "Path to json file with versioned phase-1 global parameters",
+		},
+		cli.Uint64Flag{
+			Name:  btcHeightFlag,
+			Usage: "Btc height at which transaction is checked, used to select global params version",
+		},
+		cli.StringFlag{
+			Name:  btcNodeHostFlag,
+			Usage: "Host of btc node rpc used to query inclusion height of the transaction if --btc-height is not provided",
+			Value: "127.0.0.1:8332",
+		},
+		cli.StringFlag{
+			Name:  btcNodeUserFlag,
+			Usage: "Btc node rpc user",
+		},
+		cli.StringFlag{
+			Name:  btcNodePassFlag,
+			Usage: "Btc node rpc password",
+		},
+		cli.StringFlag{
+			Name:  scriptBuilderFlag,
+			Usage: "Name of the builder of staking scripts",
+			Value: scriptbuilder.DefaultBuilderName,
+		},
 	},
 	Action: checkPhase1StakingTransaction,
 }
@@ -179,6 +223,22 @@ func checkPhase1StakingTransaction(ctx *cli.Context) error {
 	if err != nil {
 		return err
 	}
+
+	builder, err := parseScriptBuilderFromCliCtx(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if ctx.IsSet(globalParamsFlag) {
+		return checkPhase1StakingTransactionWithGlobalParams(ctx, builder, tx, currentParams)
+	}
+
+	if !ctx.IsSet(magicBytesFlag) || !ctx.IsSet(covenantMembersPksFlag) || !ctx.IsSet(covenantQuorumFlag) {
+		return cli.NewExitError(fmt.Sprintf("either --%s or all of --%s, --%s and --%s must be provided",
+			globalParamsFlag, magicBytesFlag, covenantMembersPksFlag, covenantQuorumFlag), 1)
+	}
+
 	magicBytes, err := parseMagicBytesFromCliCtx(ctx)
 
 	if err != nil {
@@ -193,7 +253,8 @@ func checkPhase1StakingTransaction(ctx *cli.Context) error {
 
 	covenantQuorum := uint32(ctx.Uint64(covenantQuorumFlag))
 
-	_, err = btcstaking.ParseV0StakingTx(
+	_, err = opreturn.ParseStakingTx(
+		builder,
 		tx,
 		magicBytes,
 		covenantMembersPks,
@@ -209,6 +270,72 @@ func checkPhase1StakingTransaction(ctx *cli.Context) error {
 	return nil
 }
 
+func checkPhase1StakingTransactionWithGlobalParams(
+	ctx *cli.Context,
+	builder scriptbuilder.StakingScriptBuilder,
+	tx *wire.MsgTx,
+	net *chaincfg.Params,
+) error {
+	globalParams, err := ReadGlobalParams(ctx.String(globalParamsFlag))
+
+	if err != nil {
+		return err
+	}
+
+	height := ctx.Uint64(btcHeightFlag)
+
+	if !ctx.IsSet(btcHeightFlag) {
+		txHash := tx.TxHash()
+		height, err = TxInclusionHeight(
+			ctx.String(btcNodeHostFlag),
+			ctx.String(btcNodeUserFlag),
+			ctx.String(btcNodePassFlag),
+			&txHash,
+		)
+
+		if err != nil {
+			return fmt.Errorf("failed to get inclusion height of the transaction, provide it with --%s flag: %w", btcHeightFlag, err)
+		}
+	}
+
+	params, err := globalParams.ParamsForHeight(height)
+
+	if err != nil {
+		return err
+	}
+
+	parsed, err := opreturn.ParseStakingTx(
+		builder,
+		tx,
+		params.MagicBytes,
+		params.CovenantPks,
+		params.CovenantQuorum,
+		net,
+	)
+
+	if err != nil {
+		return fmt.Errorf("transaction is not valid under global params version %d active at height %d: %w", params.Version, height, err)
+	}
+
+	stakingAmount := btcutil.Amount(parsed.StakingOutput.Value)
+
+	if stakingAmount < params.MinStakingAmount || stakingAmount > params.MaxStakingAmount {
+		return fmt.Errorf("staking amount %d is outside of range [%d, %d] of global params version %d active at height %d",
+			int64(stakingAmount), int64(params.MinStakingAmount), int64(params.MaxStakingAmount), params.Version, height)
+	}
+
+	stakingTime := parsed.OpReturnData.StakingTime
+
+	if stakingTime < params.MinStakingTime || stakingTime > params.MaxStakingTime {
+		return fmt.Errorf("staking time %d is outside of range [%d, %d] of global params version %d active at height %d",
+			stakingTime, params.MinStakingTime, params.MaxStakingTime, params.Version, height)
+	}
+
+	fmt.Printf("Provided transaction is valid staking transaction under global params version %d active at height %d!\n",
+		params.Version, height)
+	return nil
+}
+
 var createPhase1StakingTransactionCmd = cli.Command{
 	Name:      "create-phase1-staking-transaction",
 	ShortName: "crpst",
@@ -239,6 +366,16 @@ var createPhase1StakingTransactionCmd = cli.Command{
 			Usage:    "Magic bytes in op_return output in hex",
 			Required: true,
 		},
+		cli.UintFlag{
+			Name:  opReturnVersionFlag,
+			Usage: "Version of op_return output",
+			Value: uint(opreturn.V0),
+		},
+		cli.StringFlag{
+			Name:  scriptBuilderFlag,
+			Usage: "Name of the builder of staking scripts",
+			Value: scriptbuilder.DefaultBuilderName,
+		},
 		cli.StringSliceFlag{
 			Name:     covenantMembersPksFlag,
 			Usage:    "BTC public keys of the covenant committee members",
@@ -251,7 +388,7 @@ var createPhase1StakingTransactionCmd = cli.Command{
 		},
 		cli.StringFlag{
 			Name:     networkNameFlag,
-			Usage:    "Bitcoin network on which staking should take place one of (mainnet, testnet3, regtest, simnet, signet)",
+			Usage:    "Bitcoin network on which staking should take place one of (mainnet, testnet3, regtest, simnet, signet) or path to json file with custom network parameters",
 			Required: true,
 		},
 	},
@@ -317,8 +454,22 @@ func createPhase1StakingTransaction(ctx *cli.Context) error {
 
 	covenantQuorum := uint32(ctx.Uint64(covenantQuorumFlag))
 
+	opReturnVersion, err := parseOpReturnVersion(ctx.Uint(opReturnVersionFlag))
+
+	if err != nil {
+		return err
+	}
+
+	builder, err := parseScriptBuilderFromCliCtx(ctx)
+
+	if err != nil {
+		return err
+	}
+
 	resp, err := MakeCreatePhase1StakingTxResponse(
+		builder,
 		magicBytes,
+		opReturnVersion,
 		stakerPk,
 		fpPk,
 		covenantMembersPks,
@@ -364,9 +515,24 @@ func createPhase1StakingTransactionFromJson(ctx *cli.Context) error {
 	return nil
 }
 
+func parseOpReturnVersion(version uint) (byte, error) {
+	if version > math.MaxUint8 {
+		return 0, fmt.Errorf("op_return version %d is too large", version)
+	}
+
+	if _, supported := opreturn.Lookup(byte(version)); !supported {
+		return 0, fmt.Errorf("op_return version %d is not supported, supported versions: %v",
+			version, opreturn.SupportedVersions())
+	}
+
+	return byte(version), nil
+}
+
 // MakeCreatePhase1StakingTxResponse builds and serialize staking tx as hex response.
 func MakeCreatePhase1StakingTxResponse(
+	builder scriptbuilder.StakingScriptBuilder,
 	magicBytes []byte,
+	opReturnVersion byte,
 	stakerPk *btcec.PublicKey,
 	fpPk *btcec.PublicKey,
 	covenantMembersPks []*btcec.PublicKey,
@@ -375,13 +541,17 @@ func MakeCreatePhase1StakingTxResponse(
 	stakingAmount btcutil.Amount,
 	net *chaincfg.Params,
 ) (*CreatePhase1StakingTxResponse, error) {
-	_, tx, err := btcstaking.BuildV0IdentifiableStakingOutputsAndTx(
-		magicBytes,
-		stakerPk,
-		fpPk,
+	tx, err := opreturn.BuildStakingTx(
+		builder,
+		&opreturn.Data{
+			MagicBytes:         magicBytes,
+			Version:            opReturnVersion,
+			StakerPk:           stakerPk,
+			FinalityProviderPk: fpPk,
+			StakingTime:        stakingTimeBlocks,
+		},
 		covenantMembersPks,
 		covenantQuorum,
-		stakingTimeBlocks,
 		stakingAmount,
 		net,
 	)
@@ -0,0 +1,275 @@
+package transaction
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+
+	bbn "github.com/babylonchain/babylon/types"
+	"github.com/babylonchain/btc-staker/cmd/stakercli/helpers"
+	"github.com/babylonchain/btc-staker/opreturn"
+	"github.com/babylonchain/btc-staker/scriptbuilder"
+	"github.com/babylonchain/btc-staker/utils"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/urfave/cli"
+)
+
+const (
+	unbondingTimeFlag = "unbonding-time"
+	unbondingFeeFlag  = "unbonding-fee"
+)
+
+var createPhase1UnbondingTransactionCmd = cli.Command{
+	Name:      "create-phase1-unbonding-transaction",
+	ShortName: "crput",
+	Usage:     "Creates unsigned unbonding transaction of phase 1 staking transaction",
+	Description: "Unbonding transaction spends the staking output through the unbonding path. Besides the " +
+		"transaction, staking output and unbonding path spend info is returned, so that the staker key " +
+		"can sign it offline. Transaction also needs signatures of the covenant committee quorum.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:     stakingTransactionFlag,
+			Usage:    "Staking transaction in hex",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     magicBytesFlag,
+			Usage:    "Magic bytes in op_return output of staking transaction in hex",
+			Required: true,
+		},
+		cli.StringSliceFlag{
+			Name:     covenantMembersPksFlag,
+			Usage:    "BTC public keys of the covenant committee members",
+			Required: true,
+		},
+		cli.Uint64Flag{
+			Name:     covenantQuorumFlag,
+			Usage:    "Required quorum for the covenant members",
+			Required: true,
+		},
+		cli.Int64Flag{
+			Name:     unbondingTimeFlag,
+			Usage:    "Unbonding time in BTC blocks",
+			Required: true,
+		},
+		cli.Int64Flag{
+			Name:     unbondingFeeFlag,
+			Usage:    "Fee of unbonding transaction in satoshis",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     networkNameFlag,
+			Usage:    "Bitcoin network on which staking should take place one of (mainnet, testnet3, regtest, simnet, signet) or path to json file with custom network parameters",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:  scriptBuilderFlag,
+			Usage: "Name of the builder of staking scripts",
+			Value: scriptbuilder.DefaultBuilderName,
+		},
+	},
+	Action: createPhase1UnbondingTransaction,
+}
+
+type CreatePhase1UnbondingTxResponse struct {
+	UnbondingTxHex  string `json:"unbonding_tx_hex"`
+	UnbondingTxHash string `json:"unbonding_tx_hash"`
+	// StakingOutputIdx, StakingOutputValue and StakingOutputPkScriptHex describe
+	// staking output spent by unbonding transaction, signer needs it to compute
+	// the sighash.
+	StakingOutputIdx         uint32 `json:"staking_output_idx"`
+	StakingOutputValue       int64  `json:"staking_output_value"`
+	StakingOutputPkScriptHex string `json:"staking_output_pk_script_hex"`
+	// UnbondingPathLeafScriptHex and UnbondingPathControlBlockHex are part of
+	// the witness spending staking output through the unbonding path.
+	UnbondingPathLeafScriptHex   string `json:"unbonding_path_leaf_script_hex"`
+	UnbondingPathControlBlockHex string `json:"unbonding_path_control_block_hex"`
+	// SigHashHex is the digest signed by staker and covenant keys with schnorr
+	// signature and SIGHASH_DEFAULT.
+	SigHashHex string `json:"sighash_hex"`
+}
+
+func parseUnbondingTimeFromCliCtx(ctx *cli.Context) (uint16, error) {
+	unbondingTime := ctx.Int64(unbondingTimeFlag)
+
+	if unbondingTime <= 0 {
+		return 0, fmt.Errorf("unbonding time should be greater than 0")
+	}
+
+	if unbondingTime > math.MaxUint16 {
+		return 0, fmt.Errorf("unbonding time should be less or equal to %d", math.MaxUint16)
+	}
+
+	return uint16(unbondingTime), nil
+}
+
+func createPhase1UnbondingTransaction(ctx *cli.Context) error {
+	net := ctx.String(networkNameFlag)
+
+	currentParams, err := utils.GetBtcNetworkParams(net)
+
+	if err != nil {
+		return err
+	}
+
+	stakingTx, _, err := bbn.NewBTCTxFromHex(ctx.String(stakingTransactionFlag))
+
+	if err != nil {
+		return err
+	}
+
+	magicBytes, err := parseMagicBytesFromCliCtx(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	covenantMembersPks, err := parseCovenantKeysFromCliCtx(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	covenantQuorum := uint32(ctx.Uint64(covenantQuorumFlag))
+
+	unbondingTime, err := parseUnbondingTimeFromCliCtx(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	unbondingFee := btcutil.Amount(ctx.Int64(unbondingFeeFlag))
+
+	builder, err := parseScriptBuilderFromCliCtx(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	resp, err := MakeCreatePhase1UnbondingTxResponse(
+		builder,
+		stakingTx,
+		magicBytes,
+		covenantMembersPks,
+		covenantQuorum,
+		unbondingTime,
+		unbondingFee,
+		currentParams,
+	)
+	if err != nil {
+		return err
+	}
+
+	helpers.PrintRespJSON(*resp)
+	return nil
+}
+
+// MakeCreatePhase1UnbondingTxResponse builds unsigned unbonding transaction of
+// phase-1 staking transaction together with spend info of the staking output.
+func MakeCreatePhase1UnbondingTxResponse(
+	builder scriptbuilder.StakingScriptBuilder,
+	stakingTx *wire.MsgTx,
+	magicBytes []byte,
+	covenantMembersPks []*btcec.PublicKey,
+	covenantQuorum uint32,
+	unbondingTime uint16,
+	unbondingFee btcutil.Amount,
+	net *chaincfg.Params,
+) (*CreatePhase1UnbondingTxResponse, error) {
+	parsed, err := opreturn.ParseStakingTx(
+		builder,
+		stakingTx,
+		magicBytes,
+		covenantMembersPks,
+		covenantQuorum,
+		net,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("provided transaction is not valid staking transaction: %w", err)
+	}
+
+	stakingValue := btcutil.Amount(parsed.StakingOutput.Value)
+
+	if unbondingFee <= 0 || unbondingFee >= stakingValue {
+		return nil, fmt.Errorf("unbonding fee should be greater than 0 and less than staking amount %d", int64(stakingValue))
+	}
+
+	fpPks := []*btcec.PublicKey{parsed.OpReturnData.FinalityProviderPk}
+
+	stakingScripts, err := builder.BuildStakingScripts(
+		parsed.OpReturnData.StakerPk,
+		fpPks,
+		covenantMembersPks,
+		covenantQuorum,
+		parsed.OpReturnData.StakingTime,
+		stakingValue,
+		net,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	unbondingPathInfo, err := stakingScripts.UnbondingPathSpendInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build unbonding path info: %w", err)
+	}
+
+	unbondingScripts, err := builder.BuildUnbondingScripts(
+		parsed.OpReturnData.StakerPk,
+		fpPks,
+		covenantMembersPks,
+		covenantQuorum,
+		unbondingTime,
+		stakingValue-unbondingFee,
+		net,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build unbonding output: %w", err)
+	}
+
+	stakingTxHash := stakingTx.TxHash()
+	stakingOutputIdx := uint32(parsed.StakingOutputIdx)
+
+	unbondingTx := wire.NewMsgTx(2)
+	unbondingTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&stakingTxHash, stakingOutputIdx), nil, nil))
+	unbondingTx.AddTxOut(unbondingScripts.Output())
+
+	fetcher := txscript.NewCannedPrevOutputFetcher(parsed.StakingOutput.PkScript, parsed.StakingOutput.Value)
+
+	sigHash, err := txscript.CalcTapscriptSignaturehash(
+		txscript.NewTxSigHashes(unbondingTx, fetcher),
+		txscript.SigHashDefault,
+		unbondingTx,
+		0,
+		fetcher,
+		unbondingPathInfo.RevealedLeaf,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate sighash of unbonding transaction: %w", err)
+	}
+
+	controlBlockBytes, err := unbondingPathInfo.ControlBlock.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	serializedTx, err := utils.SerializeBtcTransaction(unbondingTx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CreatePhase1UnbondingTxResponse{
+		UnbondingTxHex:               hex.EncodeToString(serializedTx),
+		UnbondingTxHash:              unbondingTx.TxHash().String(),
+		StakingOutputIdx:             stakingOutputIdx,
+		StakingOutputValue:           parsed.StakingOutput.Value,
+		StakingOutputPkScriptHex:     hex.EncodeToString(parsed.StakingOutput.PkScript),
+		UnbondingPathLeafScriptHex:   hex.EncodeToString(unbondingPathInfo.RevealedLeaf.Script),
+		UnbondingPathControlBlockHex: hex.EncodeToString(controlBlockBytes),
+		SigHashHex:                   hex.EncodeToString(sigHash),
+	}, nil
+}
@@ -0,0 +1,239 @@
+package transaction_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	bbn "github.com/babylonchain/babylon/types"
+	"github.com/babylonchain/btc-staker/cmd/stakercli/transaction"
+	"github.com/babylonchain/btc-staker/opreturn"
+	"github.com/babylonchain/btc-staker/scriptbuilder"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testStakingAmount  = btcutil.Amount(1000000)
+	testStakingTime    = uint16(1000)
+	testUnbondingTime  = uint16(100)
+	testCovenantQuorum = uint32(2)
+)
+
+var testMagicBytes = []byte("bbt4")
+
+type unbondingTestData struct {
+	net          *chaincfg.Params
+	builder      scriptbuilder.StakingScriptBuilder
+	stakerKey    *btcec.PrivateKey
+	fpKey        *btcec.PrivateKey
+	covenantKeys []*btcec.PrivateKey
+	stakingTx    *wire.MsgTx
+}
+
+func (d *unbondingTestData) covenantPks() []*btcec.PublicKey {
+	pks := make([]*btcec.PublicKey, len(d.covenantKeys))
+	for i, k := range d.covenantKeys {
+		pks[i] = k.PubKey()
+	}
+	return pks
+}
+
+func (d *unbondingTestData) unbondingTxResponse(fee btcutil.Amount) (*transaction.CreatePhase1UnbondingTxResponse, error) {
+	return transaction.MakeCreatePhase1UnbondingTxResponse(
+		d.builder,
+		d.stakingTx,
+		testMagicBytes,
+		d.covenantPks(),
+		testCovenantQuorum,
+		testUnbondingTime,
+		fee,
+		d.net,
+	)
+}
+
+func genUnbondingTestData(t *testing.T) *unbondingTestData {
+	newKey := func() *btcec.PrivateKey {
+		k, err := btcec.NewPrivateKey()
+		require.NoError(t, err)
+		return k
+	}
+
+	d := &unbondingTestData{
+		net:          &chaincfg.RegressionNetParams,
+		builder:      scriptbuilder.Default(),
+		stakerKey:    newKey(),
+		fpKey:        newKey(),
+		covenantKeys: []*btcec.PrivateKey{newKey(), newKey(), newKey()},
+	}
+
+	resp, err := transaction.MakeCreatePhase1StakingTxResponse(
+		d.builder,
+		testMagicBytes,
+		opreturn.V0,
+		d.stakerKey.PubKey(),
+		d.fpKey.PubKey(),
+		d.covenantPks(),
+		testCovenantQuorum,
+		testStakingTime,
+		testStakingAmount,
+		d.net,
+	)
+	require.NoError(t, err)
+
+	d.stakingTx, _, err = bbn.NewBTCTxFromHex(resp.StakingTxHex)
+	require.NoError(t, err)
+
+	return d
+}
+
+func TestCreatePhase1UnbondingTxFeeBounds(t *testing.T) {
+	d := genUnbondingTestData(t)
+
+	for _, fee := range []btcutil.Amount{-1, 0, testStakingAmount, testStakingAmount + 1} {
+		_, err := d.unbondingTxResponse(fee)
+		require.ErrorContains(t, err, "unbonding fee should be greater than 0", "fee %d", fee)
+	}
+
+	for _, fee := range []btcutil.Amount{1, testStakingAmount - 1} {
+		resp, err := d.unbondingTxResponse(fee)
+		require.NoError(t, err, "fee %d", fee)
+
+		unbondingTx, _, err := bbn.NewBTCTxFromHex(resp.UnbondingTxHex)
+		require.NoError(t, err)
+		require.Equal(t, int64(testStakingAmount-fee), unbondingTx.TxOut[0].Value)
+	}
+}
+
+func TestCreatePhase1UnbondingTx(t *testing.T) {
+	d := genUnbondingTestData(t)
+	fee := btcutil.Amount(10000)
+
+	resp, err := d.unbondingTxResponse(fee)
+	require.NoError(t, err)
+
+	unbondingTx, _, err := bbn.NewBTCTxFromHex(resp.UnbondingTxHex)
+	require.NoError(t, err)
+	require.Equal(t, unbondingTx.TxHash().String(), resp.UnbondingTxHash)
+
+	// unbonding transaction spends staking output
+	stakingOutput := d.stakingTx.TxOut[resp.StakingOutputIdx]
+	require.Equal(t, stakingOutput.Value, resp.StakingOutputValue)
+	require.Equal(t, hex.EncodeToString(stakingOutput.PkScript), resp.StakingOutputPkScriptHex)
+	require.Equal(t, int64(testStakingAmount), resp.StakingOutputValue)
+
+	require.Len(t, unbondingTx.TxIn, 1)
+	require.Equal(t, d.stakingTx.TxHash(), unbondingTx.TxIn[0].PreviousOutPoint.Hash)
+	require.Equal(t, resp.StakingOutputIdx, unbondingTx.TxIn[0].PreviousOutPoint.Index)
+
+	// single unbonding output, paying the fee
+	unbondingScripts, err := d.builder.BuildUnbondingScripts(
+		d.stakerKey.PubKey(),
+		[]*btcec.PublicKey{d.fpKey.PubKey()},
+		d.covenantPks(),
+		testCovenantQuorum,
+		testUnbondingTime,
+		testStakingAmount-fee,
+		d.net,
+	)
+	require.NoError(t, err)
+
+	require.Len(t, unbondingTx.TxOut, 1)
+	require.Equal(t, int64(testStakingAmount-fee), unbondingTx.TxOut[0].Value)
+	require.Equal(t, unbondingScripts.Output().PkScript, unbondingTx.TxOut[0].PkScript)
+
+	leafScript, err := hex.DecodeString(resp.UnbondingPathLeafScriptHex)
+	require.NoError(t, err)
+	controlBlockBytes, err := hex.DecodeString(resp.UnbondingPathControlBlockHex)
+	require.NoError(t, err)
+	sigHash, err := hex.DecodeString(resp.SigHashHex)
+	require.NoError(t, err)
+
+	// leaf and control block commit to the taproot output key of staking output
+	controlBlock, err := txscript.ParseControlBlock(controlBlockBytes)
+	require.NoError(t, err)
+	require.Len(t, stakingOutput.PkScript, 34)
+	require.Equal(t, byte(txscript.OP_1), stakingOutput.PkScript[0])
+	require.NoError(t, txscript.VerifyTaprootLeafCommitment(controlBlock, stakingOutput.PkScript[2:], leafScript))
+
+	// returned sighash is the tapscript sighash of the returned leaf
+	fetcher := txscript.NewCannedPrevOutputFetcher(stakingOutput.PkScript, stakingOutput.Value)
+	sigHashes := txscript.NewTxSigHashes(unbondingTx, fetcher)
+	expectedSigHash, err := txscript.CalcTapscriptSignaturehash(
+		sigHashes,
+		txscript.SigHashDefault,
+		unbondingTx,
+		0,
+		fetcher,
+		txscript.NewBaseTapLeaf(leafScript),
+	)
+	require.NoError(t, err)
+	require.Equal(t, expectedSigHash, sigHash)
+
+	stakerSig, err := schnorr.Sign(d.stakerKey, sigHash)
+	require.NoError(t, err)
+	require.True(t, stakerSig.Verify(sigHash, d.stakerKey.PubKey()))
+
+	// keys in the leaf are staker key followed by covenant keys in the order in
+	// which script checks their signatures
+	var leafKeys [][]byte
+	tokenizer := txscript.MakeScriptTokenizer(0, leafScript)
+	for tokenizer.Next() {
+		if len(tokenizer.Data()) == schnorr.PubKeyBytesLen {
+			leafKeys = append(leafKeys, tokenizer.Data())
+		}
+	}
+	require.NoError(t, tokenizer.Err())
+	require.Len(t, leafKeys, len(d.covenantKeys)+1)
+	require.Equal(t, schnorr.SerializePubKey(d.stakerKey.PubKey()), leafKeys[0])
+
+	spendWithCovenantSigs := func(numSigs int) error {
+		// signature checked first is on top of the stack i.e last in witness,
+		// first numSigs covenant keys checked by the script sign
+		var witness wire.TxWitness
+		signed := 0
+		for i := len(leafKeys) - 1; i >= 1; i-- {
+			var sig []byte
+			if i-1 < numSigs {
+				for _, k := range d.covenantKeys {
+					if bytes.Equal(schnorr.SerializePubKey(k.PubKey()), leafKeys[i]) {
+						s, err := schnorr.Sign(k, sigHash)
+						require.NoError(t, err)
+						sig = s.Serialize()
+						signed++
+					}
+				}
+			}
+			witness = append(witness, sig)
+		}
+		require.Equal(t, numSigs, signed)
+
+		witness = append(witness, stakerSig.Serialize(), leafScript, controlBlockBytes)
+
+		signedTx := unbondingTx.Copy()
+		signedTx.TxIn[0].Witness = witness
+
+		engine, err := txscript.NewEngine(
+			stakingOutput.PkScript,
+			signedTx,
+			0,
+			txscript.StandardVerifyFlags,
+			nil,
+			txscript.NewTxSigHashes(signedTx, fetcher),
+			stakingOutput.Value,
+			fetcher,
+		)
+		require.NoError(t, err)
+
+		return engine.Execute()
+	}
+
+	// staker signature with covenant quorum spends staking output
+	require.NoError(t, spendWithCovenantSigs(int(testCovenantQuorum)))
+	require.Error(t, spendWithCovenantSigs(int(testCovenantQuorum)-1))
+}
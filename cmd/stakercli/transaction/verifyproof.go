@@ -0,0 +1,253 @@
+package transaction
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	bcctypes "github.com/babylonchain/babylon/x/btccheckpoint/types"
+	"github.com/babylonchain/btc-staker/cmd/stakercli/helpers"
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/urfave/cli"
+)
+
+const (
+	blockHeaderFlag     = "block-header"
+	transactionFlag     = "transaction"
+	proofFlag           = "proof"
+	txIndexFlag         = "tx-index"
+	transactionInfoFlag = "transaction-info"
+)
+
+var verifyInclusionProofCmd = cli.Command{
+	Name:      "verify-inclusion-proof",
+	ShortName: "vip",
+	Usage:     "Verifies merkle proof of inclusion of btc transaction in block with given header",
+	Description: "Proof is provided either as hex encoded Babylon TransactionInfo (transaction_info_hex output of " +
+		"create-inclusion-proof), or as separate transaction (or its hash), proof and transaction index. " +
+		"Verification is done locally, valid proof is still rejected by Babylon if its header is not known to " +
+		"Babylon btc light client.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:     blockHeaderFlag,
+			Usage:    "Hex encoded 80 byte btc block header",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:  transactionInfoFlag,
+			Usage: "Hex encoded Babylon TransactionInfo",
+		},
+		cli.StringFlag{
+			Name:  transactionFlag,
+			Usage: "Hex encoded transaction",
+		},
+		cli.StringFlag{
+			Name:  txHashFlag,
+			Usage: "Hash of the transaction, can be used instead of --transaction",
+		},
+		cli.StringFlag{
+			Name:  proofFlag,
+			Usage: "Hex encoded merkle proof i.e concatenated 32 byte intermediate merkle nodes",
+		},
+		cli.Uint64Flag{
+			Name:  txIndexFlag,
+			Usage: "Index of the transaction in the block",
+		},
+	},
+	Action: verifyInclusionProof,
+}
+
+// VerifyInclusionProofResponse lists result of every check, so that it is clear
+// which part of the proof is wrong
+type VerifyInclusionProofResponse struct {
+	Valid      bool   `json:"valid"`
+	TxHash     string `json:"tx_hash"`
+	BlockHash  string `json:"block_hash"`
+	TxIndex    uint32 `json:"tx_index"`
+	MerkleRoot string `json:"merkle_root"`
+	// Merkle root computed from the transaction hash and the proof
+	ComputedMerkleRoot string `json:"computed_merkle_root"`
+	// Header hash meets target encoded in the header
+	HeaderPowValid bool `json:"header_pow_valid"`
+	// Only set for TransactionInfo input, block hash in its key matches the header
+	BlockHashMatches *bool    `json:"block_hash_matches,omitempty"`
+	Errors           []string `json:"errors,omitempty"`
+}
+
+// computeMerkleRoot folds transaction hash with intermediate merkle nodes. Bits
+// of the index determine whether node is left or right sibling at given level,
+// the same way as Babylon verifies proofs.
+func computeMerkleRoot(txHash *chainhash.Hash, proof []byte, index uint32) (*chainhash.Hash, error) {
+	if len(proof)%chainhash.HashSize != 0 {
+		return nil, fmt.Errorf("proof length %d is not multiple of %d", len(proof), chainhash.HashSize)
+	}
+
+	current := *txHash
+	buf := make([]byte, 2*chainhash.HashSize)
+
+	for i := 0; i < len(proof); i += chainhash.HashSize {
+		node := proof[i : i+chainhash.HashSize]
+
+		if index&1 == 1 {
+			copy(buf, node)
+			copy(buf[chainhash.HashSize:], current[:])
+		} else {
+			copy(buf, current[:])
+			copy(buf[chainhash.HashSize:], node)
+		}
+
+		current = chainhash.DoubleHashH(buf)
+		index >>= 1
+	}
+
+	if index != 0 {
+		return nil, fmt.Errorf("transaction index is too large for proof with %d nodes", len(proof)/chainhash.HashSize)
+	}
+
+	return &current, nil
+}
+
+func headerPowValid(header *wire.BlockHeader) bool {
+	target := blockchain.CompactToBig(header.Bits)
+
+	if target.Sign() <= 0 {
+		return false
+	}
+
+	hash := header.BlockHash()
+	return blockchain.HashToBig(&hash).Cmp(target) <= 0
+}
+
+func txHashFromHex(txHex string) (*chainhash.Hash, error) {
+	txBytes, err := hex.DecodeString(txHex)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return txHashFromBytes(txBytes)
+}
+
+func txHashFromBytes(txBytes []byte) (*chainhash.Hash, error) {
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+		return nil, err
+	}
+
+	hash := tx.TxHash()
+	return &hash, nil
+}
+
+func verifyInclusionProof(ctx *cli.Context) error {
+	headerBytes, err := hex.DecodeString(ctx.String(blockHeaderFlag))
+
+	if err != nil || len(headerBytes) != wire.MaxBlockHeaderPayload {
+		return cli.NewExitError(fmt.Sprintf("block header must be %d hex encoded bytes", wire.MaxBlockHeaderPayload), 1)
+	}
+
+	var header wire.BlockHeader
+	if err := header.Deserialize(bytes.NewReader(headerBytes)); err != nil {
+		return cli.NewExitError(fmt.Sprintf("invalid block header: %s", err), 1)
+	}
+
+	blockHash := header.BlockHash()
+
+	var (
+		txHash           *chainhash.Hash
+		proof            []byte
+		txIndex          uint32
+		blockHashMatches *bool
+	)
+
+	if txInfoHex := ctx.String(transactionInfoFlag); txInfoHex != "" {
+		txInfoBytes, err := hex.DecodeString(txInfoHex)
+
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("invalid transaction info: %s", err), 1)
+		}
+
+		var txInfo bcctypes.TransactionInfo
+		if err := txInfo.Unmarshal(txInfoBytes); err != nil {
+			return cli.NewExitError(fmt.Sprintf("invalid transaction info: %s", err), 1)
+		}
+
+		if txInfo.Key == nil || txInfo.Key.Hash == nil {
+			return cli.NewExitError("transaction info has no key", 1)
+		}
+
+		txHash, err = txHashFromBytes(txInfo.Transaction)
+
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("invalid transaction in transaction info: %s", err), 1)
+		}
+
+		proof = txInfo.Proof
+		txIndex = txInfo.Key.Index
+		// header hash bytes are stored in the same byte order as chainhash
+		matches := bytes.Equal(*txInfo.Key.Hash, blockHash[:])
+		blockHashMatches = &matches
+	} else {
+		switch {
+		case ctx.String(transactionFlag) != "":
+			txHash, err = txHashFromHex(ctx.String(transactionFlag))
+		case ctx.String(txHashFlag) != "":
+			txHash, err = chainhash.NewHashFromStr(ctx.String(txHashFlag))
+		default:
+			return cli.NewExitError(fmt.Sprintf("either --%s, --%s or --%s must be provided", transactionInfoFlag, transactionFlag, txHashFlag), 1)
+		}
+
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("invalid transaction: %s", err), 1)
+		}
+
+		proof, err = hex.DecodeString(ctx.String(proofFlag))
+
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("invalid proof: %s", err), 1)
+		}
+
+		txIndex = uint32(ctx.Uint64(txIndexFlag))
+	}
+
+	resp := VerifyInclusionProofResponse{
+		TxHash:           txHash.String(),
+		BlockHash:        blockHash.String(),
+		TxIndex:          txIndex,
+		MerkleRoot:       header.MerkleRoot.String(),
+		HeaderPowValid:   headerPowValid(&header),
+		BlockHashMatches: blockHashMatches,
+	}
+
+	if !resp.HeaderPowValid {
+		resp.Errors = append(resp.Errors, "block header hash does not meet its target")
+	}
+
+	if blockHashMatches != nil && !*blockHashMatches {
+		resp.Errors = append(resp.Errors, "block hash in transaction info key does not match provided header")
+	}
+
+	computedRoot, err := computeMerkleRoot(txHash, proof, txIndex)
+
+	if err != nil {
+		resp.Errors = append(resp.Errors, err.Error())
+	} else {
+		resp.ComputedMerkleRoot = computedRoot.String()
+
+		if !computedRoot.IsEqual(&header.MerkleRoot) {
+			resp.Errors = append(resp.Errors, "merkle root computed from proof does not match merkle root of the header")
+		}
+	}
+
+	resp.Valid = len(resp.Errors) == 0
+
+	helpers.PrintRespJSON(resp)
+
+	if !resp.Valid {
+		return errors.New("inclusion proof is not valid")
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package transaction
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/babylonchain/btc-staker/cmd/stakercli/helpers"
+	"github.com/babylonchain/btc-staker/stakerservice"
+	"github.com/babylonchain/btc-staker/utils"
+	"github.com/urfave/cli"
+)
+
+const (
+	reportFileFlag   = "report-file"
+	checkUnspentFlag = "check-unspent"
+)
+
+var verifyProofOfReservesCmd = cli.Command{
+	Name:      "verify-proof-of-reserves",
+	ShortName: "vpor",
+	Usage:     "Verifies proof of reserves report created by stakercli daemon proof-of-reserves",
+	Description: "Checks that script of every output is derived from listed staking parameters, that every staker " +
+		"key signed the report message and that amounts add up. With --check-unspent, btc node is queried " +
+		"to check that all outputs are still unspent.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:     reportFileFlag,
+			Usage:    "Path to json file with the report",
+			Required: true,
+		},
+		cli.StringFlag{
+			Name:     networkNameFlag,
+			Usage:    "Bitcoin network of the report one of (mainnet, testnet3, regtest, simnet, signet)",
+			Required: true,
+		},
+		cli.BoolFlag{
+			Name:  checkUnspentFlag,
+			Usage: "Check that outputs are unspent using btc node",
+		},
+		cli.StringFlag{
+			Name:  btcNodeHostFlag,
+			Usage: "Host of btc node rpc",
+			Value: "127.0.0.1:8332",
+		},
+		cli.StringFlag{
+			Name:  btcNodeUserFlag,
+			Usage: "Btc node rpc user",
+		},
+		cli.StringFlag{
+			Name:  btcNodePassFlag,
+			Usage: "Btc node rpc password",
+		},
+		cli.StringFlag{
+			Name:  scriptBuilderFlag,
+			Usage: "Name of the builder of staking scripts",
+			Value: scriptbuilder.DefaultBuilderName,
+		},
+	},
+	Action: verifyProofOfReserves,
+}
+
+type VerifyProofOfReservesResponse struct {
+	Valid       bool     `json:"valid"`
+	NumOutputs  int      `json:"num_outputs"`
+	NumKeys     int      `json:"num_keys"`
+	TotalAmount int64    `json:"total_amount"`
+	Errors      []string `json:"errors,omitempty"`
+}
+
+func verifyProofOfReserves(ctx *cli.Context) error {
+	net, err := utils.GetBtcNetworkParams(ctx.String(networkNameFlag))
+
+	if err != nil {
+		return err
+	}
+
+	builder, err := parseScriptBuilderFromCliCtx(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	reportBytes, err := os.ReadFile(ctx.String(reportFileFlag))
+
+	if err != nil {
+		return err
+	}
+
+	var resp stakerservice.ProofOfReservesResponse
+	if err := json.Unmarshal(reportBytes, &resp); err != nil {
+		return cli.NewExitError(fmt.Sprintf("invalid report: %s", err), 1)
+	}
+
+	report, err := resp.Report(net)
+
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("invalid report: %s", err), 1)
+	}
+
+	result := VerifyProofOfReservesResponse{
+		NumOutputs:  len(report.Outputs),
+		NumKeys:     len(report.Keys),
+		TotalAmount: int64(report.TotalAmount),
+	}
+
+	for _, e := range report.Verify(builder, net) {
+		result.Errors = append(result.Errors, e.Error())
+	}
+
+	if ctx.Bool(checkUnspentFlag) {
+		client, err := newBtcNodeClient(
+			ctx.String(btcNodeHostFlag),
+			ctx.String(btcNodeUserFlag),
+			ctx.String(btcNodePassFlag),
+		)
+
+		if err != nil {
+			return err
+		}
+
+		defer client.Shutdown()
+
+		for _, o := range report.Outputs {
+			txOut, err := client.GetTxOut(&o.OutPoint.Hash, o.OutPoint.Index, false)
+
+			if err != nil {
+				return fmt.Errorf("failed to query output %s: %w", o.OutPoint, err)
+			}
+
+			if txOut == nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("output %s is spent or does not exist", o.OutPoint))
+			}
+		}
+	}
+
+	result.Valid = len(result.Errors) == 0
+
+	helpers.PrintRespJSON(result)
+
+	if !result.Valid {
+		return errors.New("proof of reserves is not valid")
+	}
+
+	return nil
+}
@@ -0,0 +1,420 @@
+package wizard
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/babylonchain/btc-staker/cmd/stakercli/helpers"
+	"github.com/babylonchain/btc-staker/cmd/stakercli/transaction"
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	service "github.com/babylonchain/btc-staker/stakerservice"
+	dc "github.com/babylonchain/btc-staker/stakerservice/client"
+	"github.com/babylonchain/btc-staker/utils"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/urfave/cli"
+)
+
+const (
+	modeDaemon  = "daemon"
+	modeOffline = "offline"
+
+	// maximum number of finality providers offered for selection
+	maxListedFinalityProviders = 100
+)
+
+var (
+	networks = []string{"mainnet", "testnet3", "signet", "regtest", "simnet"}
+
+	defaultStakingDaemonAddress = "tcp://127.0.0.1:" + strconv.Itoa(scfg.DefaultRPCPort)
+
+	errAborted = errors.New("staking aborted")
+)
+
+var WizardCommands = []cli.Command{
+	{
+		Name:     "stake-wizard",
+		Usage:    "Interactively walks through staking: selecting network, finality provider, amount and staking time",
+		Category: "Daemon commands",
+		Description: "Wizard fetches staking parameters and finality providers from running staker daemon. " +
+			"Stake is either sent by the daemon from its wallet, or created as unsigned phase-1 staking " +
+			"transaction which is funded and signed outside of the daemon.",
+		Action: stakeWizard,
+	},
+}
+
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask prints the question until answer passes validation. Empty answer is
+// replaced by default value if there is one.
+func (p *prompter) ask(question string, defaultValue string, validate func(string) error) (string, error) {
+	for {
+		if defaultValue != "" {
+			fmt.Fprintf(p.out, "%s [%s]: ", question, defaultValue)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", question)
+		}
+
+		line, err := p.in.ReadString('\n')
+
+		if err != nil && !(errors.Is(err, io.EOF) && len(line) > 0) {
+			return "", errAborted
+		}
+
+		answer := strings.TrimSpace(line)
+
+		if answer == "" {
+			answer = defaultValue
+		}
+
+		if err := validate(answer); err != nil {
+			fmt.Fprintf(p.out, "  %s\n", err)
+			continue
+		}
+
+		return answer, nil
+	}
+}
+
+func (p *prompter) choose(question string, options []string, defaultValue string) (string, error) {
+	return p.ask(fmt.Sprintf("%s (%s)", question, strings.Join(options, "/")), defaultValue, func(s string) error {
+		for _, o := range options {
+			if s == o {
+				return nil
+			}
+		}
+		return fmt.Errorf("answer must be one of: %s", strings.Join(options, ", "))
+	})
+}
+
+func (p *prompter) confirm(question string) (bool, error) {
+	answer, err := p.choose(question, []string{"y", "n"}, "n")
+
+	if err != nil {
+		return false, err
+	}
+
+	return answer == "y", nil
+}
+
+func parseUintParam(name, value string) (uint64, error) {
+	parsed, err := strconv.ParseUint(value, 10, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("daemon returned invalid %s %s: %w", name, value, err)
+	}
+
+	return parsed, nil
+}
+
+func selectFinalityProvider(p *prompter, fps []service.FinalityProviderInfoResponse) (string, error) {
+	if len(fps) == 0 {
+		// daemon knows no providers, key still can be provided manually
+		return p.ask("Finality provider BTC public key (hex)", "", validateSchnorrKey)
+	}
+
+	fmt.Fprintln(p.out, "Active finality providers:")
+	for i, fp := range fps {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, fp.BtcPublicKey)
+	}
+
+	answer, err := p.ask("Finality provider number or BTC public key (hex)", "1", func(s string) error {
+		if idx, err := strconv.Atoi(s); err == nil {
+			if idx < 1 || idx > len(fps) {
+				return fmt.Errorf("number must be between 1 and %d", len(fps))
+			}
+			return nil
+		}
+
+		return validateSchnorrKey(s)
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	if idx, err := strconv.Atoi(answer); err == nil {
+		return fps[idx-1].BtcPublicKey, nil
+	}
+
+	return answer, nil
+}
+
+func validateSchnorrKey(s string) error {
+	keyBytes, err := hex.DecodeString(s)
+
+	if err != nil {
+		return fmt.Errorf("key must be hex encoded: %w", err)
+	}
+
+	_, err = schnorr.ParsePubKey(keyBytes)
+	return err
+}
+
+// stakerAddresses returns addresses of the daemon wallet with their spendable
+// balance, ordered from the highest balance
+func stakerAddresses(ctx context.Context, client *dc.StakerServiceJsonRpcClient) ([]string, map[string]btcutil.Amount, error) {
+	outputs, err := client.ListOutputs(ctx)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	balances := make(map[string]btcutil.Amount)
+	for _, o := range outputs.Outputs {
+		if o.Frozen {
+			continue
+		}
+
+		amount, err := strconv.ParseInt(o.Amount, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		balances[o.Address] += btcutil.Amount(amount)
+	}
+
+	addresses := make([]string, 0, len(balances))
+	for addr := range balances {
+		addresses = append(addresses, addr)
+	}
+
+	sort.Slice(addresses, func(i, j int) bool {
+		return balances[addresses[i]] > balances[addresses[j]]
+	})
+
+	return addresses, balances, nil
+}
+
+func stakeWizard(_ *cli.Context) error {
+	p := &prompter{in: bufio.NewReader(os.Stdin), out: os.Stdout}
+	sctx := context.Background()
+
+	fmt.Fprintln(p.out, "This wizard creates new BTC stake. Press enter to accept default values in brackets.")
+
+	network, err := p.choose("BTC network", networks, "signet")
+	if err != nil {
+		return err
+	}
+
+	btcParams, err := utils.GetBtcNetworkParams(network)
+	if err != nil {
+		return err
+	}
+
+	mode, err := p.choose("Send stake from daemon wallet or create unsigned transaction", []string{modeDaemon, modeOffline}, modeDaemon)
+	if err != nil {
+		return err
+	}
+
+	daemonAddress, err := p.ask("Staker daemon address", defaultStakingDaemonAddress, func(s string) error {
+		if s == "" {
+			return fmt.Errorf("daemon address must not be empty")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	client, err := dc.NewStakerServiceJsonRpcClient(daemonAddress)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(p.out, "Fetching staking parameters...")
+
+	params, err := client.BabylonStakingParams(sctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch staking parameters: %w", err)
+	}
+
+	confirmationTime, err := parseUintParam("confirmation time", params.ConfirmationTimeBlocks)
+	if err != nil {
+		return err
+	}
+
+	finalizationTimeout, err := parseUintParam("finalization timeout", params.FinalizationTimeoutBlocks)
+	if err != nil {
+		return err
+	}
+
+	minSlashingFee, err := parseUintParam("min slashing fee", params.MinSlashingTxFeeSat)
+	if err != nil {
+		return err
+	}
+
+	// the same minimum is enforced by the daemon
+	minStakingTime := 2*finalizationTimeout + confirmationTime
+
+	limit := maxListedFinalityProviders
+	fps, err := client.BabylonFinalityProviders(sctx, nil, &limit)
+	if err != nil {
+		return fmt.Errorf("failed to fetch finality providers: %w", err)
+	}
+
+	fpPk, err := selectFinalityProvider(p, fps.FinalityProviders)
+	if err != nil {
+		return err
+	}
+
+	amountStr, err := p.ask(fmt.Sprintf("Staking amount in satoshis (more than %d)", minSlashingFee), "", func(s string) error {
+		amount, err := strconv.ParseInt(s, 10, 64)
+
+		if err != nil {
+			return fmt.Errorf("amount must be a whole number of satoshis")
+		}
+
+		if amount <= int64(minSlashingFee) {
+			return fmt.Errorf("amount must be greater than minimum slashing fee %d", minSlashingFee)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	amount, _ := strconv.ParseInt(amountStr, 10, 64)
+
+	stakingTimeStr, err := p.ask(
+		fmt.Sprintf("Staking time in BTC blocks (%d - %d)", minStakingTime, math.MaxUint16),
+		strconv.FormatUint(minStakingTime, 10),
+		func(s string) error {
+			blocks, err := strconv.ParseUint(s, 10, 64)
+
+			if err != nil || blocks < minStakingTime || blocks > math.MaxUint16 {
+				return fmt.Errorf("staking time must be a number between %d and %d", minStakingTime, math.MaxUint16)
+			}
+
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+	stakingTime, _ := strconv.ParseUint(stakingTimeStr, 10, 16)
+
+	if mode == modeOffline {
+		return createOfflineStakingTx(p, params, network, fpPk, amount, uint16(stakingTime))
+	}
+
+	addresses, balances, err := stakerAddresses(sctx, client)
+	if err != nil {
+		return fmt.Errorf("failed to list wallet outputs: %w", err)
+	}
+
+	if len(addresses) > 0 {
+		fmt.Fprintln(p.out, "Wallet addresses with spendable outputs:")
+		for _, addr := range addresses {
+			fmt.Fprintf(p.out, "  %s (%s)\n", addr, balances[addr])
+		}
+	}
+
+	defaultAddress := ""
+	if len(addresses) > 0 {
+		defaultAddress = addresses[0]
+	}
+
+	stakerAddress, err := p.ask("Staker address", defaultAddress, func(s string) error {
+		if _, err := btcutil.DecodeAddress(s, btcParams); err != nil {
+			return fmt.Errorf("invalid %s address: %w", network, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(p.out, "\nStake %s from %s to finality provider %s for %d blocks.\n",
+		btcutil.Amount(amount), stakerAddress, fpPk, stakingTime)
+
+	ok, err := p.confirm("Send staking transaction")
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errAborted
+	}
+
+	result, err := client.Stake(sctx, stakerAddress, amount, []string{fpPk}, int64(stakingTime), nil)
+	if err != nil {
+		return err
+	}
+
+	helpers.PrintRespJSON(result)
+	return nil
+}
+
+func createOfflineStakingTx(
+	p *prompter,
+	params *service.BabylonStakingParamsResponse,
+	network string,
+	fpPkHex string,
+	amount int64,
+	stakingTime uint16,
+) error {
+	stakerPk, err := p.ask("Staker BTC public key (hex, BIP340)", "", validateSchnorrKey)
+	if err != nil {
+		return err
+	}
+
+	magicBytes, err := p.ask("Magic bytes of phase-1 staking transactions (hex)", "", func(s string) error {
+		if _, err := hex.DecodeString(s); err != nil || len(s) != 8 {
+			return fmt.Errorf("magic bytes must be 4 hex encoded bytes")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	quorum, err := parseUintParam("covenant quorum", params.CovenantQuorum)
+	if err != nil {
+		return err
+	}
+
+	input := transaction.InputBtcStakingTx{
+		BtcNetwork:                   network,
+		StakerPublicKeyHex:           stakerPk,
+		CovenantMembersPkHex:         params.CovenantPks,
+		FinalityProviderPublicKeyHex: fpPkHex,
+		StakingAmount:                amount,
+		StakingTimeBlocks:            stakingTime,
+		MagicBytesHex:                magicBytes,
+		CovenantQuorum:               uint32(quorum),
+	}
+
+	fmt.Fprintf(p.out, "\nCreate %s staking transaction of %s from %s to finality provider %s for %d blocks.\n",
+		network, btcutil.Amount(amount), stakerPk, fpPkHex, stakingTime)
+
+	ok, err := p.confirm("Create transaction")
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errAborted
+	}
+
+	resp, err := input.ToCreatePhase1StakingTxResponse()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(p.out, "Unsigned staking transaction, fund and sign it with your wallet before broadcasting:")
+	helpers.PrintRespJSON(*resp)
+	return nil
+}
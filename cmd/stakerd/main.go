@@ -7,12 +7,16 @@ import (
 	"runtime/pprof"
 
 	"github.com/babylonchain/btc-staker/metrics"
+	"github.com/babylonchain/btc-staker/monitor"
 	staker "github.com/babylonchain/btc-staker/staker"
 	scfg "github.com/babylonchain/btc-staker/stakercfg"
 	service "github.com/babylonchain/btc-staker/stakerservice"
 
 	"github.com/jessevdk/go-flags"
+	"github.com/lightningnetwork/lnd/kvdb"
 	"github.com/lightningnetwork/lnd/signal"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/zap"
 )
 
 func main() {
@@ -69,7 +73,12 @@ func main() {
 		os.Exit(1)
 	}
 
-	stakerMetrics := metrics.NewStakerMetrics()
+	if cfg.MonitorConfig.Enabled {
+		runMonitor(cfg, cfgLogger, zapLogger, dbBackend, shutdownInterceptor)
+		return
+	}
+
+	stakerMetrics := metrics.NewStakerMetrics(cfg.ActiveNetParams.Name)
 
 	// TODO: consider moving this to stakerservice
 	staker, err := staker.NewStakerAppFromConfig(
@@ -96,7 +105,49 @@ func main() {
 	addr := fmt.Sprintf("%s:%d", cfg.MetricsConfig.Host, cfg.MetricsConfig.ServerPort)
 	metrics.Start(cfgLogger, addr, stakerMetrics.Registry)
 
-	err = service.RunUntilShutdown()
+	err = runService(shutdownInterceptor, service.RunUntilShutdown)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// runMonitor runs daemon in watch only monitoring mode, which does not require
+// btc wallet or babylon key
+func runMonitor(
+	cfg *scfg.Config,
+	cfgLogger *logrus.Logger,
+	zapLogger *zap.Logger,
+	dbBackend kvdb.Backend,
+	shutdownInterceptor signal.Interceptor,
+) {
+	monitorMetrics := metrics.NewMonitorMetrics(cfg.ActiveNetParams.Name)
+
+	mon, err := monitor.NewMonitorFromConfig(
+		cfg,
+		cfgLogger,
+		zapLogger,
+		dbBackend,
+		monitorMetrics,
+	)
+
+	if err != nil {
+		cfgLogger.Errorf("failed to create monitor: %v", err)
+		os.Exit(1)
+	}
+
+	service := service.NewMonitorService(
+		cfg,
+		mon,
+		cfgLogger,
+		shutdownInterceptor,
+		dbBackend,
+	)
+
+	addr := fmt.Sprintf("%s:%d", cfg.MetricsConfig.Host, cfg.MetricsConfig.ServerPort)
+	metrics.Start(cfgLogger, addr, monitorMetrics.Registry)
+
+	err = runService(shutdownInterceptor, service.RunUntilShutdown)
 	if err != nil {
 		_, _ = fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
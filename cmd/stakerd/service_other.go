@@ -0,0 +1,13 @@
+//go:build !windows
+
+package main
+
+import (
+	"github.com/lightningnetwork/lnd/signal"
+)
+
+// runService runs the daemon. Outside of windows daemon is always run in the
+// foreground, background service is managed by systemd or launchd.
+func runService(_ signal.Interceptor, run func() error) error {
+	return run()
+}
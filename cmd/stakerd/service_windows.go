@@ -0,0 +1,77 @@
+//go:build windows
+
+package main
+
+import (
+	"github.com/lightningnetwork/lnd/signal"
+	"golang.org/x/sys/windows/svc"
+)
+
+// serviceName is ignored by service control manager for services running in
+// their own process, it is only used in logs
+const serviceName = "stakerd"
+
+type windowsService struct {
+	interceptor signal.Interceptor
+	run         func() error
+	err         error
+}
+
+// Execute reports daemon as running to service control manager and requests
+// graceful shutdown when the service is stopped
+func (s *windowsService) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		s.err = s.run()
+	}()
+
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				s.interceptor.RequestShutdown()
+			}
+
+		case <-done:
+			if s.err != nil {
+				// non zero exit code lets service recovery actions restart
+				// the daemon
+				return false, 1
+			}
+
+			return false, 0
+		}
+	}
+}
+
+// runService runs the daemon as windows service if it was started by service
+// control manager, and in the foreground otherwise
+func runService(interceptor signal.Interceptor, run func() error) error {
+	isService, err := svc.IsWindowsService()
+
+	if err != nil {
+		return err
+	}
+
+	if !isService {
+		return run()
+	}
+
+	s := &windowsService{interceptor: interceptor, run: run}
+
+	if err := svc.Run(serviceName, s); err != nil {
+		return err
+	}
+
+	return s.err
+}
@@ -0,0 +1,114 @@
+package eventsink
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+const (
+	kafkaSinkName = "kafka"
+	kafkaClientId = "btc-staker"
+)
+
+// kafkaSink produces events to a Kafka topic. Events are keyed by staking
+// transaction hash and partitioned the same way as default Java producer, so
+// that all events of a delegation are in one partition, in order. Every event
+// is acknowledged by all in sync replicas.
+type kafkaSink struct {
+	brokers []string
+	topic   string
+	useTls  bool
+	timeout time.Duration
+
+	// created on first publish, client connects to brokers only when needed and
+	// refreshes metadata of the topic itself
+	client *kgo.Client
+}
+
+func newKafkaSink(brokers []string, topic string, useTls bool, timeout time.Duration) *kafkaSink {
+	return &kafkaSink{
+		brokers: brokers,
+		topic:   topic,
+		useTls:  useTls,
+		timeout: timeout,
+	}
+}
+
+func (s *kafkaSink) Name() string {
+	return kafkaSinkName
+}
+
+// kafkaPartitioner returns partitioner choosing partition of the key as default
+// Java producer does, positive murmur2 hash modulo number of partitions
+func kafkaPartitioner() kgo.Partitioner {
+	return kgo.StickyKeyPartitioner(nil)
+}
+
+func (s *kafkaSink) newClient() (*kgo.Client, error) {
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(s.brokers...),
+		kgo.ClientID(kafkaClientId),
+		kgo.DefaultProduceTopic(s.topic),
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+		kgo.RecordPartitioner(kafkaPartitioner()),
+		kgo.DialTimeout(s.timeout),
+		kgo.ProduceRequestTimeout(s.timeout),
+		// events are published one by one, there is nothing to batch
+		kgo.ProducerLinger(0),
+	}
+
+	if s.useTls {
+		// server name is set to host of the dialed broker
+		opts = append(opts, kgo.DialTLSConfig(&tls.Config{
+			MinVersion: tls.VersionTLS12,
+		}))
+	}
+
+	return kgo.NewClient(opts...)
+}
+
+// kafkaRecord maps event to record produced to the topic
+func kafkaRecord(ev *proto.LifecycleEvent, payload []byte, contentType string) *kgo.Record {
+	return &kgo.Record{
+		Key:   []byte(ev.StakingTxHash),
+		Value: payload,
+		Headers: []kgo.RecordHeader{
+			{Key: "content-type", Value: []byte(contentType)},
+			{Key: "schema-version", Value: []byte(strconv.Itoa(int(ev.SchemaVersion)))},
+			{Key: "event-type", Value: []byte(ev.Type)},
+		},
+	}
+}
+
+func (s *kafkaSink) Publish(ev *proto.LifecycleEvent, payload []byte, contentType string) error {
+	if s.client == nil {
+		client, err := s.newClient()
+
+		if err != nil {
+			return fmt.Errorf("failed to create kafka client: %w", err)
+		}
+
+		s.client = client
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	return s.client.ProduceSync(ctx, kafkaRecord(ev, payload, contentType)).FirstErr()
+}
+
+func (s *kafkaSink) Close() error {
+	if s.client == nil {
+		return nil
+	}
+
+	s.client.Close()
+	s.client = nil
+	return nil
+}
@@ -0,0 +1,50 @@
+package eventsink
+
+import (
+	"testing"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestKafkaPartitioner(t *testing.T) {
+	// partitions chosen by default Java producer for topic with 10 partitions,
+	// from UtilsTest.testMurmur2 of Apache Kafka
+	vectors := map[string]int{
+		"21":                         0,
+		"foobar":                     6,
+		"a-little-bit-long-string":   2,
+		"a-little-bit-longer-string": 9,
+		"abc":                        7,
+	}
+
+	partitioner := kafkaPartitioner().ForTopic("events")
+
+	for key, expected := range vectors {
+		rec := &kgo.Record{Key: []byte(key)}
+		require.Equal(t, expected, partitioner.Partition(rec, 10), key)
+		// partition depends only on the key
+		require.Equal(t, expected, partitioner.Partition(rec, 10), key)
+	}
+}
+
+func TestKafkaRecord(t *testing.T) {
+	ev := &proto.LifecycleEvent{
+		Type:          "STAKING_REQUESTED",
+		SchemaVersion: SchemaVersion,
+		StakingTxHash: "6f0f1a7c5a1e22e3bd62b7b8fbdc2fa5f2ed24a3d0e6b1c2f4a7d9e8c1b0a4f2",
+	}
+
+	rec := kafkaRecord(ev, []byte(`{"type":"STAKING_REQUESTED"}`), ContentTypeJson)
+
+	require.Equal(t, []byte(ev.StakingTxHash), rec.Key)
+	require.Equal(t, []byte(`{"type":"STAKING_REQUESTED"}`), rec.Value)
+	// topic is the default produce topic of the client
+	require.Empty(t, rec.Topic)
+	require.Equal(t, []kgo.RecordHeader{
+		{Key: "content-type", Value: []byte(ContentTypeJson)},
+		{Key: "schema-version", Value: []byte("1")},
+		{Key: "event-type", Value: []byte("STAKING_REQUESTED")},
+	}, rec.Headers)
+}
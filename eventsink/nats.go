@@ -0,0 +1,95 @@
+package eventsink
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	natsSinkName   = "nats"
+	natsClientName = "btc-staker"
+)
+
+// natsSink publishes events to core NATS. Every publish is followed by flush,
+// server answers it only after processing the publish, so errors reported by
+// server are not missed.
+type natsSink struct {
+	url     string
+	subject string
+	timeout time.Duration
+
+	conn *nats.Conn
+}
+
+func newNatsSink(natsUrl string, subject string, timeout time.Duration) *natsSink {
+	return &natsSink{
+		url:     natsUrl,
+		subject: subject,
+		timeout: timeout,
+	}
+}
+
+func (s *natsSink) Name() string {
+	return natsSinkName
+}
+
+// connect connects to the server from the url. Credentials are taken from the
+// url, user without password is used as a token. tls:// scheme, or server
+// requiring tls, upgrades connection to tls.
+func (s *natsSink) connect() error {
+	conn, err := nats.Connect(
+		s.url,
+		nats.Name(natsClientName),
+		nats.Timeout(s.timeout),
+		nats.MaxReconnects(-1),
+		// publish fails while client is reconnecting instead of being
+		// buffered, so that event retried by publisher is not delivered twice
+		nats.ReconnectBufSize(-1),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	s.conn = conn
+	return nil
+}
+
+func (s *natsSink) Publish(ev *proto.LifecycleEvent, payload []byte, _ string) error {
+	if s.conn == nil || s.conn.IsClosed() {
+		if err := s.connect(); err != nil {
+			return fmt.Errorf("failed to connect to nats server: %w", err)
+		}
+	}
+
+	// server reports rejected publish, e.g. permissions violation,
+	// asynchronously, it is detected as a new last error of the connection
+	lastErr := s.conn.LastError()
+
+	if err := s.conn.Publish(s.subject+"."+subjectSuffix(ev.Type), payload); err != nil {
+		return err
+	}
+
+	if err := s.conn.FlushTimeout(s.timeout); err != nil {
+		return err
+	}
+
+	if err := s.conn.LastError(); err != nil && err != lastErr {
+		return err
+	}
+
+	return nil
+}
+
+func (s *natsSink) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+
+	s.conn.Close()
+	s.conn = nil
+	return nil
+}
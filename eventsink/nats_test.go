@@ -0,0 +1,182 @@
+package eventsink
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testNatsInfo = `{"server_id":"test","max_payload":1048576}`
+
+// natsConnectOptions are fields of CONNECT message checked by tests
+type natsConnectOptions struct {
+	Name      string `json:"name"`
+	User      string `json:"user"`
+	Pass      string `json:"pass"`
+	AuthToken string `json:"auth_token"`
+}
+
+// fakeNatsServer accepts single connection and runs serve on it
+func fakeNatsServer(t *testing.T, serve func(conn net.Conn, reader *bufio.Reader)) (string, <-chan struct{}) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		serve(conn, bufio.NewReader(conn))
+	}()
+
+	return listener.Addr().String(), done
+}
+
+func readLine(t *testing.T, reader *bufio.Reader) string {
+	line, err := reader.ReadString('\n')
+	assert.NoError(t, err)
+	return strings.TrimRight(line, "\r\n")
+}
+
+// acceptConnect sends INFO, reads CONNECT of the client and answers its PING
+func acceptConnect(t *testing.T, conn net.Conn, reader *bufio.Reader, info string) natsConnectOptions {
+	_, _ = conn.Write([]byte("INFO " + info + "\r\n"))
+
+	var connect natsConnectOptions
+	connectJson, found := strings.CutPrefix(readLine(t, reader), "CONNECT ")
+	assert.True(t, found)
+	assert.NoError(t, json.Unmarshal([]byte(connectJson), &connect))
+	assert.Equal(t, "PING", readLine(t, reader))
+	_, _ = conn.Write([]byte("PONG\r\n"))
+
+	return connect
+}
+
+func TestNatsPublish(t *testing.T) {
+	payload := []byte(`{"id":"1"}`)
+
+	var connect natsConnectOptions
+
+	addr, done := fakeNatsServer(t, func(conn net.Conn, reader *bufio.Reader) {
+		connect = acceptConnect(t, conn, reader, testNatsInfo)
+
+		assert.Equal(t, "PUB staker.events.staking_requested 10", readLine(t, reader))
+		received := make([]byte, len(payload)+2)
+		_, err := io.ReadFull(reader, received)
+		assert.NoError(t, err)
+		assert.Equal(t, string(payload)+"\r\n", string(received))
+		assert.Equal(t, "PING", readLine(t, reader))
+		_, _ = conn.Write([]byte("PONG\r\n"))
+
+		// wait until client closes connection
+		_, _ = reader.ReadByte()
+	})
+
+	s := newNatsSink("nats://secret-token@"+addr, "staker.events", 5*time.Second)
+
+	ev := &proto.LifecycleEvent{Type: "STAKING_REQUESTED"}
+	require.NoError(t, s.Publish(ev, payload, ContentTypeJson))
+	require.NoError(t, s.Close())
+	<-done
+
+	require.Equal(t, natsConnectOptions{
+		Name:      natsClientName,
+		AuthToken: "secret-token",
+	}, connect)
+}
+
+func TestNatsConnectUserPassword(t *testing.T) {
+	var connect natsConnectOptions
+
+	addr, done := fakeNatsServer(t, func(conn net.Conn, reader *bufio.Reader) {
+		connect = acceptConnect(t, conn, reader, testNatsInfo)
+		_, _ = reader.ReadByte()
+	})
+
+	s := newNatsSink("nats://staker:pass@"+addr, "staker.events", 5*time.Second)
+
+	require.NoError(t, s.connect())
+	require.NoError(t, s.Close())
+	<-done
+
+	require.Equal(t, "staker", connect.User)
+	require.Equal(t, "pass", connect.Pass)
+	require.Empty(t, connect.AuthToken)
+}
+
+func TestNatsConnectError(t *testing.T) {
+	addr, _ := fakeNatsServer(t, func(conn net.Conn, reader *bufio.Reader) {
+		_, _ = conn.Write([]byte("INFO {}\r\n"))
+		readLine(t, reader)
+		readLine(t, reader)
+		_, _ = conn.Write([]byte("-ERR 'Authorization Violation'\r\n"))
+	})
+
+	s := newNatsSink("nats://"+addr, "staker.events", 5*time.Second)
+	defer s.Close()
+
+	err := s.Publish(&proto.LifecycleEvent{Type: "STAKING_REQUESTED"}, []byte("{}"), ContentTypeJson)
+	require.ErrorIs(t, err, nats.ErrAuthorization)
+	require.Nil(t, s.conn)
+}
+
+func TestNatsPermissionsViolation(t *testing.T) {
+	addr, done := fakeNatsServer(t, func(conn net.Conn, reader *bufio.Reader) {
+		acceptConnect(t, conn, reader, testNatsInfo)
+
+		// publish and flush of both events
+		for i := 0; i < 2; i++ {
+			readLine(t, reader)
+			readLine(t, reader)
+			assert.Equal(t, "PING", readLine(t, reader))
+
+			if i == 0 {
+				_, _ = conn.Write([]byte("-ERR 'Permissions Violation for Publish to \"staker.events.staking_requested\"'\r\n"))
+			}
+			_, _ = conn.Write([]byte("PONG\r\n"))
+		}
+
+		_, _ = reader.ReadByte()
+	})
+
+	s := newNatsSink("nats://"+addr, "staker.events", 5*time.Second)
+
+	err := s.Publish(&proto.LifecycleEvent{Type: "STAKING_REQUESTED"}, []byte("{}"), ContentTypeJson)
+	require.ErrorContains(t, err, "Permissions Violation")
+
+	// connection stays open and error of previous publish is not reported again
+	require.NoError(t, s.Publish(&proto.LifecycleEvent{Type: "STAKING_REQUESTED"}, []byte("{}"), ContentTypeJson))
+	require.NoError(t, s.Close())
+	<-done
+}
+
+func TestNatsMaxPayload(t *testing.T) {
+	addr, done := fakeNatsServer(t, func(conn net.Conn, reader *bufio.Reader) {
+		acceptConnect(t, conn, reader, `{"max_payload":4}`)
+		// keep connection open until client closes it
+		_, _ = reader.ReadByte()
+	})
+
+	s := newNatsSink("nats://"+addr, "staker.events", 5*time.Second)
+
+	err := s.Publish(&proto.LifecycleEvent{Type: "STAKING_REQUESTED"}, []byte("12345"), ContentTypeJson)
+	require.ErrorIs(t, err, nats.ErrMaxPayload)
+	require.NoError(t, s.Close())
+	<-done
+}
@@ -0,0 +1,193 @@
+// Package eventsink publishes lifecycle events of delegations to message
+// brokers, so that they can feed data pipelines without polling the daemon.
+package eventsink
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/avast/retry-go/v4"
+	"github.com/babylonchain/btc-staker/proto"
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+	pm "google.golang.org/protobuf/proto"
+)
+
+const (
+	// SchemaVersion is version of published LifecycleEvent schema
+	SchemaVersion = 1
+
+	ContentTypeJson     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+
+	publishRetryAttempts = 3
+	publishRetryDelay    = 2 * time.Second
+)
+
+// Sink delivers encoded event to a message broker
+type Sink interface {
+	Name() string
+	Publish(ev *proto.LifecycleEvent, payload []byte, contentType string) error
+	Close() error
+}
+
+// Publisher delivers events to all configured sinks from a background
+// goroutine, so that unavailable broker never stalls processing of delegations
+type Publisher struct {
+	sinks  []Sink
+	format string
+	logger *logrus.Logger
+	failed *prometheus.CounterVec
+	events chan *proto.LifecycleEvent
+	wg     sync.WaitGroup
+	quit   chan struct{}
+}
+
+// NewPublisher creates publisher for sinks enabled in the config. Publisher
+// without sinks drops all events.
+func NewPublisher(
+	cfg *scfg.EventSinkConfig,
+	logger *logrus.Logger,
+	failed *prometheus.CounterVec,
+) *Publisher {
+	var sinks []Sink
+
+	if cfg.NatsUrl != "" {
+		sinks = append(sinks, newNatsSink(cfg.NatsUrl, cfg.NatsSubject, cfg.Timeout))
+	}
+
+	if len(cfg.KafkaBrokers) > 0 {
+		sinks = append(sinks, newKafkaSink(cfg.KafkaBrokers, cfg.KafkaTopic, cfg.KafkaTls, cfg.Timeout))
+	}
+
+	return &Publisher{
+		sinks:  sinks,
+		format: cfg.Format,
+		logger: logger,
+		failed: failed,
+		events: make(chan *proto.LifecycleEvent, cfg.QueueSize),
+		quit:   make(chan struct{}),
+	}
+}
+
+// Enabled returns true if at least one sink is configured
+func (p *Publisher) Enabled() bool {
+	return len(p.sinks) > 0
+}
+
+func (p *Publisher) Start() {
+	if !p.Enabled() {
+		return
+	}
+
+	p.wg.Add(1)
+	go p.publishLoop()
+}
+
+// Stop waits for event being published and closes sink connections. Events
+// still in the queue are dropped.
+func (p *Publisher) Stop() {
+	close(p.quit)
+	p.wg.Wait()
+
+	for _, sink := range p.sinks {
+		if err := sink.Close(); err != nil {
+			p.logger.WithFields(logrus.Fields{
+				"sink": sink.Name(),
+				"err":  err,
+			}).Warn("Failed to close event sink")
+		}
+	}
+}
+
+// Publish queues event for delivery. It never blocks, if queue is full event is
+// dropped.
+func (p *Publisher) Publish(ev *proto.LifecycleEvent) {
+	if !p.Enabled() {
+		return
+	}
+
+	ev.SchemaVersion = SchemaVersion
+
+	select {
+	case p.events <- ev:
+	default:
+		for _, sink := range p.sinks {
+			p.failed.WithLabelValues(sink.Name()).Inc()
+		}
+		p.logger.WithFields(logrus.Fields{
+			"type":          ev.Type,
+			"stakingTxHash": ev.StakingTxHash,
+		}).Error("Event sink queue is full. Dropping event")
+	}
+}
+
+// Encode encodes event in given format. Json uses proto field names and emits
+// fields with zero values, so that every event has all fields of the schema.
+func Encode(ev *proto.LifecycleEvent, format string) ([]byte, string, error) {
+	switch format {
+	case scfg.EventSinkFormatJson:
+		payload, err := protojson.MarshalOptions{
+			UseProtoNames:   true,
+			EmitUnpopulated: true,
+		}.Marshal(ev)
+		return payload, ContentTypeJson, err
+	case scfg.EventSinkFormatProtobuf:
+		payload, err := pm.Marshal(ev)
+		return payload, ContentTypeProtobuf, err
+	default:
+		return nil, "", fmt.Errorf("unknown event format %s", format)
+	}
+}
+
+func (p *Publisher) publishLoop() {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case ev := <-p.events:
+			payload, contentType, err := Encode(ev, p.format)
+
+			if err != nil {
+				p.logger.WithFields(logrus.Fields{
+					"type": ev.Type,
+					"err":  err,
+				}).Error("Failed to encode lifecycle event")
+				continue
+			}
+
+			for _, sink := range p.sinks {
+				if err := p.publish(sink, ev, payload, contentType); err != nil {
+					p.failed.WithLabelValues(sink.Name()).Inc()
+					p.logger.WithFields(logrus.Fields{
+						"sink":          sink.Name(),
+						"type":          ev.Type,
+						"stakingTxHash": ev.StakingTxHash,
+						"err":           err,
+					}).Error("Failed to publish lifecycle event")
+				}
+			}
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+func (p *Publisher) publish(sink Sink, ev *proto.LifecycleEvent, payload []byte, contentType string) error {
+	return retry.Do(func() error {
+		return sink.Publish(ev, payload, contentType)
+	},
+		retry.Attempts(publishRetryAttempts),
+		retry.Delay(publishRetryDelay),
+		retry.LastErrorOnly(true),
+	)
+}
+
+// subjectSuffix returns event type in form used in NATS subjects
+func subjectSuffix(eventType string) string {
+	return strings.ToLower(eventType)
+}
@@ -0,0 +1,34 @@
+//go:build !faultinjection
+
+package faultinjection
+
+import (
+	cl "github.com/babylonchain/btc-staker/babylonclient"
+	"github.com/babylonchain/btc-staker/walletcontroller"
+	"github.com/sirupsen/logrus"
+)
+
+const Enabled = false
+
+// Injector is no-op in binaries built without faultinjection tag
+type Injector struct{}
+
+func NewInjector(_ *logrus.Logger) *Injector {
+	return &Injector{}
+}
+
+func (i *Injector) Faults() (*Faults, error) {
+	return nil, ErrNotCompiled
+}
+
+func (i *Injector) SetFaults(_ *Faults) error {
+	return ErrNotCompiled
+}
+
+func (i *Injector) WrapWallet(w walletcontroller.WalletController) walletcontroller.WalletController {
+	return w
+}
+
+func (i *Injector) WrapBabylonClient(c cl.BabylonClient) cl.BabylonClient {
+	return c
+}
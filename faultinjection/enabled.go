@@ -0,0 +1,83 @@
+//go:build faultinjection
+
+package faultinjection
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	cl "github.com/babylonchain/btc-staker/babylonclient"
+	"github.com/babylonchain/btc-staker/walletcontroller"
+	"github.com/sirupsen/logrus"
+)
+
+const Enabled = true
+
+// Injector holds faults injected into wrapped clients, faults can be changed
+// while the daemon is running
+type Injector struct {
+	mu     sync.RWMutex
+	faults Faults
+	logger *logrus.Logger
+}
+
+func NewInjector(logger *logrus.Logger) *Injector {
+	return &Injector{logger: logger}
+}
+
+func (i *Injector) Faults() (*Faults, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	f := i.faults
+	return &f, nil
+}
+
+func (i *Injector) SetFaults(f *Faults) error {
+	if err := f.Validate(); err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	i.faults = *f
+	i.mu.Unlock()
+
+	i.logger.WithFields(logrus.Fields{
+		"dropBroadcasts":        f.DropBroadcasts,
+		"babylonDelay":          f.BabylonDelay,
+		"signingFailurePercent": f.SigningFailurePercent,
+	}).Warn("Injected faults changed")
+
+	return nil
+}
+
+func (i *Injector) current() Faults {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.faults
+}
+
+func (i *Injector) dropBroadcast() bool {
+	return i.current().DropBroadcasts
+}
+
+func (i *Injector) delayBabylon() {
+	if delay := i.current().BabylonDelay; delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+func (i *Injector) failSigning() bool {
+	percent := i.current().SigningFailurePercent
+	//nolint:gosec
+	return percent > 0 && uint32(rand.Intn(100)) < percent
+}
+
+func (i *Injector) WrapWallet(w walletcontroller.WalletController) walletcontroller.WalletController {
+	return &faultyWallet{WalletController: w, injector: i}
+}
+
+func (i *Injector) WrapBabylonClient(c cl.BabylonClient) cl.BabylonClient {
+	return &faultyBabylonClient{BabylonClient: c, injector: i}
+}
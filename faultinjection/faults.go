@@ -0,0 +1,38 @@
+// Package faultinjection injects failures into wallet and babylon clients used
+// by the daemon, so that its recovery can be validated before it is trusted
+// with real funds. Injection is compiled only into binaries built with
+// faultinjection build tag, in regular binaries clients are never wrapped.
+package faultinjection
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrNotCompiled = errors.New("fault injection is not compiled in, daemon must be built with faultinjection tag")
+	ErrInjected    = errors.New("injected fault")
+)
+
+// Faults currently injected into clients, zero value injects nothing
+type Faults struct {
+	// transactions are reported as sent, but never reach btc node
+	DropBroadcasts bool
+	// every babylon request is delayed by given duration
+	BabylonDelay time.Duration
+	// percentage of signing requests to the wallet which fail
+	SigningFailurePercent uint32
+}
+
+func (f *Faults) Validate() error {
+	if f.BabylonDelay < 0 {
+		return fmt.Errorf("babylon delay cannot be negative")
+	}
+
+	if f.SigningFailurePercent > 100 {
+		return fmt.Errorf("signing failure percent must be between 0 and 100")
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+//go:build faultinjection
+
+package faultinjection
+
+import (
+	"fmt"
+
+	cl "github.com/babylonchain/btc-staker/babylonclient"
+	"github.com/babylonchain/btc-staker/walletcontroller"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	pv "github.com/cosmos/relayer/v2/relayer/provider"
+)
+
+type faultyWallet struct {
+	walletcontroller.WalletController
+	injector *Injector
+}
+
+var _ walletcontroller.WalletController = (*faultyWallet)(nil)
+
+func (w *faultyWallet) SendRawTransaction(tx *wire.MsgTx, allowHighFees bool) (*chainhash.Hash, error) {
+	if w.injector.dropBroadcast() {
+		txHash := tx.TxHash()
+		w.injector.logger.WithField("txHash", txHash).Warn("Injected fault: dropping broadcast of transaction")
+		return &txHash, nil
+	}
+
+	return w.WalletController.SendRawTransaction(tx, allowHighFees)
+}
+
+func (w *faultyWallet) SubmitPackage(parent, child *wire.MsgTx) error {
+	if w.injector.dropBroadcast() {
+		w.injector.logger.WithField("txHash", child.TxHash()).Warn("Injected fault: dropping broadcast of package")
+		return nil
+	}
+
+	return w.WalletController.SubmitPackage(parent, child)
+}
+
+func (w *faultyWallet) SignRawTransaction(tx *wire.MsgTx) (*wire.MsgTx, bool, error) {
+	if w.injector.failSigning() {
+		return nil, false, fmt.Errorf("failed to sign transaction: %w", ErrInjected)
+	}
+
+	return w.WalletController.SignRawTransaction(tx)
+}
+
+func (w *faultyWallet) CreateAndSignTx(
+	outputs []*wire.TxOut,
+	feeRatePerKb btcutil.Amount,
+	changeAddress btcutil.Address,
+	minConfirmations uint32,
+) (*wire.MsgTx, error) {
+	if w.injector.failSigning() {
+		return nil, fmt.Errorf("failed to sign transaction: %w", ErrInjected)
+	}
+
+	return w.WalletController.CreateAndSignTx(outputs, feeRatePerKb, changeAddress, minConfirmations)
+}
+
+// staker keys are dumped to sign staking, unbonding and withdrawal
+// transactions
+func (w *faultyWallet) DumpPrivateKey(address btcutil.Address) (*btcec.PrivateKey, error) {
+	if w.injector.failSigning() {
+		return nil, fmt.Errorf("failed to dump private key: %w", ErrInjected)
+	}
+
+	return w.WalletController.DumpPrivateKey(address)
+}
+
+type faultyBabylonClient struct {
+	cl.BabylonClient
+	injector *Injector
+}
+
+var _ cl.BabylonClient = (*faultyBabylonClient)(nil)
+
+func (c *faultyBabylonClient) Params() (*cl.StakingParams, error) {
+	c.injector.delayBabylon()
+	return c.BabylonClient.Params()
+}
+
+func (c *faultyBabylonClient) Delegate(dg *cl.DelegationData) (*pv.RelayerTxResponse, error) {
+	c.injector.delayBabylon()
+	return c.BabylonClient.Delegate(dg)
+}
+
+func (c *faultyBabylonClient) Undelegate(req *cl.UndelegationRequest) (*pv.RelayerTxResponse, error) {
+	c.injector.delayBabylon()
+	return c.BabylonClient.Undelegate(req)
+}
+
+func (c *faultyBabylonClient) QueryFinalityProviders(limit uint64, offset uint64) (*cl.FinalityProvidersClientResponse, error) {
+	c.injector.delayBabylon()
+	return c.BabylonClient.QueryFinalityProviders(limit, offset)
+}
+
+func (c *faultyBabylonClient) QueryFinalityProvider(btcPubKey *btcec.PublicKey) (*cl.FinalityProviderClientResponse, error) {
+	c.injector.delayBabylon()
+	return c.BabylonClient.QueryFinalityProvider(btcPubKey)
+}
+
+func (c *faultyBabylonClient) QueryHeaderDepth(headerHash *chainhash.Hash) (uint64, error) {
+	c.injector.delayBabylon()
+	return c.BabylonClient.QueryHeaderDepth(headerHash)
+}
+
+func (c *faultyBabylonClient) QueryBtcLightClientTip() (uint64, error) {
+	c.injector.delayBabylon()
+	return c.BabylonClient.QueryBtcLightClientTip()
+}
+
+func (c *faultyBabylonClient) IsTxAlreadyPartOfDelegation(stakingTxHash *chainhash.Hash) (bool, error) {
+	c.injector.delayBabylon()
+	return c.BabylonClient.IsTxAlreadyPartOfDelegation(stakingTxHash)
+}
+
+func (c *faultyBabylonClient) QueryDelegationInfo(stakingTxHash *chainhash.Hash) (*cl.DelegationInfo, error) {
+	c.injector.delayBabylon()
+	return c.BabylonClient.QueryDelegationInfo(stakingTxHash)
+}
+
+func (c *faultyBabylonClient) QueryRewardGauges(address sdk.AccAddress) (map[string]*cl.RewardGauge, error) {
+	c.injector.delayBabylon()
+	return c.BabylonClient.QueryRewardGauges(address)
+}
+
+func (c *faultyBabylonClient) QueryBalance(address sdk.AccAddress, denom string) (sdk.Coin, error) {
+	c.injector.delayBabylon()
+	return c.BabylonClient.QueryBalance(address, denom)
+}
+
+func (c *faultyBabylonClient) WithdrawRewards(stakeholderType string, recipient sdk.AccAddress, amount sdk.Coins) (*pv.RelayerTxResponse, error) {
+	c.injector.delayBabylon()
+	return c.BabylonClient.WithdrawRewards(stakeholderType, recipient, amount)
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/babylonchain/btc-staker/stakercfg"
 	service "github.com/babylonchain/btc-staker/stakerservice"
 	dc "github.com/babylonchain/btc-staker/stakerservice/client"
+	"github.com/babylonchain/btc-staker/testutil"
 	"github.com/babylonchain/btc-staker/types"
 	"github.com/babylonchain/btc-staker/utils"
 	"github.com/babylonchain/btc-staker/walletcontroller"
@@ -69,78 +70,27 @@ func keyToAddr(key *btcec.PrivateKey, net *chaincfg.Params) (btcutil.Address, er
 }
 
 func defaultStakerConfig(t *testing.T, passphrase string) (*stakercfg.Config, *rpcclient.Client) {
-	defaultConfig := stakercfg.DefaultConfig()
-
-	// both wallet and node are bicoind
-	defaultConfig.BtcNodeBackendConfig.ActiveWalletBackend = types.BitcoindWalletBackend
-	defaultConfig.BtcNodeBackendConfig.ActiveNodeBackend = types.BitcoindNodeBackend
-	defaultConfig.ActiveNetParams = *regtestParams
-
-	// Fees configuration
-	defaultConfig.BtcNodeBackendConfig.FeeMode = "dynamic"
-	defaultConfig.BtcNodeBackendConfig.EstimationMode = types.DynamicFeeEstimation
-
-	bitcoindHost := "127.0.0.1:18443"
-	bitcoindUser := "user"
-	bitcoindPass := "pass"
-
-	// Wallet configuration
-	defaultConfig.WalletRpcConfig.Host = bitcoindHost
-	defaultConfig.WalletRpcConfig.User = bitcoindUser
-	defaultConfig.WalletRpcConfig.Pass = bitcoindPass
-	defaultConfig.WalletRpcConfig.DisableTls = true
-	defaultConfig.WalletConfig.WalletPass = passphrase
-
-	// node configuration
-	defaultConfig.BtcNodeBackendConfig.Bitcoind.RPCHost = bitcoindHost
-	defaultConfig.BtcNodeBackendConfig.Bitcoind.RPCUser = bitcoindUser
-	defaultConfig.BtcNodeBackendConfig.Bitcoind.RPCPass = bitcoindPass
-
-	// Use rpc polling, as it is our default mode and it is a bit more troublesome
-	// to configure ZMQ from inside the bitcoind docker container
-	defaultConfig.BtcNodeBackendConfig.Bitcoind.RPCPolling = true
-	defaultConfig.BtcNodeBackendConfig.Bitcoind.BlockPollingInterval = 1 * time.Second
-	defaultConfig.BtcNodeBackendConfig.Bitcoind.TxPollingInterval = 1 * time.Second
-
-	defaultConfig.StakerConfig.BabylonStallingInterval = 1 * time.Second
-	defaultConfig.StakerConfig.UnbondingTxCheckInterval = 1 * time.Second
-
-	// TODO: After bumping relayer version sending transactions concurrently fails wih
-	// fatal error: concurrent map writes
-	// For now diable concurrent sends but this need to be sorted out
-	defaultConfig.StakerConfig.MaxConcurrentTransactions = 1
-
-	testRpcClient, err := rpcclient.New(&rpcclient.ConnConfig{
-		Host:                 bitcoindHost,
-		User:                 bitcoindUser,
-		Pass:                 bitcoindPass,
-		DisableTLS:           true,
-		DisableConnectOnNew:  true,
-		DisableAutoReconnect: false,
-		// we use post mode as it sure it works with either bitcoind or btcwallet
-		// we may need to re-consider it later if we need any notifications
-		HTTPPostMode: true,
-	}, nil)
-	require.NoError(t, err)
-
-	return &defaultConfig, testRpcClient
+	testRpcClient, err := testutil.NewBitcoindRpcClient()
+	require.NoError(t, err)
+
+	return testutil.RegtestStakerConfig(passphrase), testRpcClient
 }
 
 type TestManager struct {
-	BabylonHandler   *BabylonNodeHandler
-	Config           *stakercfg.Config
-	Db               kvdb.Backend
-	Sa               *staker.StakerApp
-	BabylonClient    *babylonclient.BabylonController
-	WalletPrivKey    *btcec.PrivateKey
-	MinerAddr        btcutil.Address
-	serverStopper    *signal.Interceptor
-	wg               *sync.WaitGroup
-	serviceAddress   string
-	StakerClient     *dc.StakerServiceJsonRpcClient
-	CovenantPrivKeys []*btcec.PrivateKey
-	BitcoindHandler  *BitcoindTestHandler
-	TestRpcClient    *rpcclient.Client
+	BabylonHandler  *testutil.BabylonNodeHandler
+	Config          *stakercfg.Config
+	Db              kvdb.Backend
+	Sa              *staker.StakerApp
+	BabylonClient   *babylonclient.BabylonController
+	WalletPrivKey   *btcec.PrivateKey
+	MinerAddr       btcutil.Address
+	serverStopper   *signal.Interceptor
+	wg              *sync.WaitGroup
+	serviceAddress  string
+	StakerClient    *dc.StakerServiceJsonRpcClient
+	CovenantSigner  *testutil.CovenantSigner
+	BitcoindHandler *testutil.BitcoindTestHandler
+	TestRpcClient   *rpcclient.Client
 }
 
 type testStakingData struct {
@@ -208,7 +158,7 @@ func (td *testStakingData) withStakingAmout(amout int64) *testStakingData {
 func StartManager(
 	t *testing.T,
 	numMatureOutputsInWallet uint32) *TestManager {
-	h := NewBitcoindHandler(t)
+	h := testutil.NewBitcoindHandler(t)
 	h.Start()
 	passphrase := "pass"
 	_ = h.CreateWallet("test-wallet", passphrase)
@@ -220,19 +170,16 @@ func StartManager(
 
 	quorum := 2
 	numCovenants := 3
-	var coventantPrivKeys []*btcec.PrivateKey
-	for i := 0; i < numCovenants; i++ {
-		covenantPrivKey, err := btcec.NewPrivateKey()
-		require.NoError(t, err)
-		coventantPrivKeys = append(coventantPrivKeys, covenantPrivKey)
-	}
+	covenantSigner, err := testutil.NewCovenantSigner(numCovenants, regtestParams)
+	require.NoError(t, err)
+	coventantPrivKeys := covenantSigner.PrivKeys
 
 	var buff bytes.Buffer
 	err = regtestParams.GenesisBlock.Header.Serialize(&buff)
 	require.NoError(t, err)
 	baseHeaderHex := hex.EncodeToString(buff.Bytes())
 
-	bh, err := NewBabylonNodeHandler(
+	bh, err := testutil.NewBabylonNodeHandler(
 		quorum,
 		coventantPrivKeys[0].PubKey(),
 		coventantPrivKeys[1].PubKey(),
@@ -271,11 +218,11 @@ func StartManager(
 	dbbackend, err := stakercfg.GetDbBackend(cfg.DBConfig)
 	require.NoError(t, err)
 
-	m := metrics.NewStakerMetrics()
+	m := metrics.NewStakerMetrics(cfg.ActiveNetParams.Name)
 	stakerApp, err := staker.NewStakerAppFromConfig(cfg, logger, zapLogger, dbbackend, m)
 	require.NoError(t, err)
 	// we require separate client to send BTC headers to babylon node (interface does not need this method?)
-	bl, err := babylonclient.NewBabylonController(cfg.BabylonConfig, &cfg.ActiveNetParams, logger, zapLogger)
+	bl, err := babylonclient.NewBabylonController(cfg.BabylonConfig, &cfg.ActiveNetParams, logger, zapLogger, nil)
 	require.NoError(t, err)
 
 	walletClient := stakerApp.Wallet()
@@ -318,20 +265,20 @@ func StartManager(
 	require.NoError(t, err)
 
 	return &TestManager{
-		BabylonHandler:   bh,
-		Config:           cfg,
-		Db:               dbbackend,
-		Sa:               stakerApp,
-		BabylonClient:    bl,
-		WalletPrivKey:    walletPrivKey,
-		MinerAddr:        minerAddressDecoded,
-		serverStopper:    &interceptor,
-		wg:               &wg,
-		serviceAddress:   addressString,
-		StakerClient:     stakerClient,
-		CovenantPrivKeys: coventantPrivKeys,
-		BitcoindHandler:  h,
-		TestRpcClient:    c,
+		BabylonHandler:  bh,
+		Config:          cfg,
+		Db:              dbbackend,
+		Sa:              stakerApp,
+		BabylonClient:   bl,
+		WalletPrivKey:   walletPrivKey,
+		MinerAddr:       minerAddressDecoded,
+		serverStopper:   &interceptor,
+		wg:              &wg,
+		serviceAddress:  addressString,
+		StakerClient:    stakerClient,
+		CovenantSigner:  covenantSigner,
+		BitcoindHandler: h,
+		TestRpcClient:   c,
 	}
 }
 
@@ -356,7 +303,7 @@ func (tm *TestManager) RestartApp(t *testing.T) {
 
 	dbbackend, err := stakercfg.GetDbBackend(tm.Config.DBConfig)
 	require.NoError(t, err)
-	m := metrics.NewStakerMetrics()
+	m := metrics.NewStakerMetrics(tm.Config.ActiveNetParams.Name)
 	stakerApp, err := staker.NewStakerAppFromConfig(tm.Config, logger, zapLogger, dbbackend, m)
 	require.NoError(t, err)
 
@@ -503,6 +450,7 @@ func (tm *TestManager) sendStakingTx(t *testing.T, testStakingData *testStakingD
 		testStakingData.StakingAmount,
 		fpBTCPKs,
 		int64(testStakingData.StakingTime),
+		nil,
 	)
 	require.NoError(t, err)
 	txHash := res.TxHash
@@ -510,7 +458,7 @@ func (tm *TestManager) sendStakingTx(t *testing.T, testStakingData *testStakingD
 	stakingDetails, err := tm.StakerClient.StakingDetails(context.Background(), txHash)
 	require.NoError(t, err)
 	require.Equal(t, stakingDetails.StakingTxHash, txHash)
-	require.Equal(t, stakingDetails.StakingState, proto.TransactionState_SENT_TO_BTC.String())
+	require.Equal(t, stakingDetails.StakingState, string(types.DelegationStateSentToBtc))
 
 	hashFromString, err := chainhash.NewHashFromStr(txHash)
 	require.NoError(t, err)
@@ -543,6 +491,7 @@ func (tm *TestManager) sendMultipleStakingTx(t *testing.T, testStakingData []*te
 			data.StakingAmount,
 			fpBTCPKs,
 			int64(data.StakingTime),
+			nil,
 		)
 		require.NoError(t, err)
 		txHash, err := chainhash.NewHashFromStr(res.TxHash)
@@ -556,7 +505,7 @@ func (tm *TestManager) sendMultipleStakingTx(t *testing.T, testStakingData []*te
 		stakingDetails, err := tm.StakerClient.StakingDetails(context.Background(), hashStr)
 		require.NoError(t, err)
 		require.Equal(t, stakingDetails.StakingTxHash, hashStr)
-		require.Equal(t, stakingDetails.StakingState, proto.TransactionState_SENT_TO_BTC.String())
+		require.Equal(t, stakingDetails.StakingState, string(types.DelegationStateSentToBtc))
 	}
 
 	mBlock := tm.mineBlock(t)
@@ -593,6 +542,7 @@ func (tm *TestManager) sendWatchedStakingTx(
 		[]*wire.TxOut{stakingInfo.StakingOutput},
 		2000,
 		tm.MinerAddr,
+		1,
 	)
 	require.NoError(t, err)
 	txHash := tx.TxHash()
@@ -724,6 +674,7 @@ func (tm *TestManager) sendWatchedStakingTx(
 		int(unbondingTme),
 		// Use schnor verification
 		int(btcstypes.BTCSigType_BIP340),
+		nil,
 	)
 	require.NoError(t, err)
 
@@ -780,7 +731,7 @@ func (tm *TestManager) waitForStakingTxState(t *testing.T, txHash *chainhash.Has
 		if err != nil {
 			return false
 		}
-		return detailResult.StakingState == expectedState.String()
+		return detailResult.StakingState == string(types.CanonicalDelegationState(expectedState))
 	}, 1*time.Minute, eventuallyPollTime)
 }
 
@@ -806,90 +757,11 @@ func (tm *TestManager) insertAllMinedBlocksToBabylon(t *testing.T) {
 }
 
 func (tm *TestManager) insertCovenantSigForDelegation(t *testing.T, btcDel *btcstypes.BTCDelegationResponse) {
-	fpBTCPKs, err := bbntypes.NewBTCPKsFromBIP340PKs(btcDel.FpBtcPkList)
-	require.NoError(t, err)
-
-	slashingTxBytes, err := hex.DecodeString(btcDel.SlashingTxHex)
-	require.NoError(t, err)
-	slashingTx := btcstypes.BTCSlashingTx(slashingTxBytes)
-	stakingTx := btcDel.StakingTxHex
-	stakingMsgTx, _, err := bbntypes.NewBTCTxFromHex(stakingTx)
+	params, err := tm.Sa.BabylonController().Params()
 	require.NoError(t, err)
 
-	cl := tm.Sa.BabylonController()
-	params, err := cl.Params()
+	err = tm.CovenantSigner.SubmitSignatures(tm.BabylonClient, params, btcDel)
 	require.NoError(t, err)
-
-	stakingInfo, err := staking.BuildStakingInfo(
-		btcDel.BtcPk.MustToBTCPK(),
-		fpBTCPKs,
-		params.CovenantPks,
-		params.CovenantQuruomThreshold,
-		uint16(btcDel.EndHeight-btcDel.StartHeight),
-		btcutil.Amount(btcDel.TotalSat),
-		regtestParams,
-	)
-	slashingPathInfo, err := stakingInfo.SlashingPathSpendInfo()
-	require.NoError(t, err)
-
-	covenantSlashingTxSigs, err := datagen.GenCovenantAdaptorSigs(
-		tm.CovenantPrivKeys,
-		fpBTCPKs,
-		stakingMsgTx,
-		slashingPathInfo.GetPkScriptPath(),
-		&slashingTx,
-	)
-	require.NoError(t, err)
-
-	// slash unbonding tx spends unbonding tx
-	unbondingMsgTx, _, err := bbntypes.NewBTCTxFromHex(btcDel.UndelegationResponse.UnbondingTxHex)
-	require.NoError(t, err)
-	unbondingInfo, err := staking.BuildUnbondingInfo(
-		btcDel.BtcPk.MustToBTCPK(),
-		fpBTCPKs,
-		params.CovenantPks,
-		params.CovenantQuruomThreshold,
-		uint16(btcDel.UnbondingTime),
-		btcutil.Amount(unbondingMsgTx.TxOut[0].Value),
-		regtestParams,
-	)
-	unbondingSlashingPathInfo, err := unbondingInfo.SlashingPathSpendInfo()
-	require.NoError(t, err)
-
-	// generate all covenant signatures from all covenant members
-	unbondingSlashingTx, err := btcstypes.NewBTCSlashingTxFromHex(btcDel.UndelegationResponse.SlashingTxHex)
-	require.NoError(t, err)
-	covenantUnbondingSlashingTxSigs, err := datagen.GenCovenantAdaptorSigs(
-		tm.CovenantPrivKeys,
-		fpBTCPKs,
-		unbondingMsgTx,
-		unbondingSlashingPathInfo.GetPkScriptPath(),
-		unbondingSlashingTx,
-	)
-	require.NoError(t, err)
-
-	// each covenant member submits signatures
-	unbondingPathInfo, err := stakingInfo.UnbondingPathSpendInfo()
-	require.NoError(t, err)
-	covUnbondingSigs, err := datagen.GenCovenantUnbondingSigs(
-		tm.CovenantPrivKeys,
-		stakingMsgTx,
-		btcDel.StakingOutputIdx,
-		unbondingPathInfo.GetPkScriptPath(),
-		unbondingMsgTx,
-	)
-	require.NoError(t, err)
-
-	for i := 0; i < len(tm.CovenantPrivKeys); i++ {
-		_, err = tm.BabylonClient.SubmitCovenantSig(
-			bbntypes.NewBIP340PubKeyFromBTCPK(tm.CovenantPrivKeys[i].PubKey()),
-			stakingMsgTx.TxHash().String(),
-			covenantSlashingTxSigs[i].AdaptorSigs,
-			bbntypes.NewBIP340SignatureFromBTCSig(covUnbondingSigs[i]),
-			covenantUnbondingSlashingTxSigs[i].AdaptorSigs,
-		)
-		require.NoError(t, err)
-	}
 }
 
 func TestStakingFailures(t *testing.T) {
@@ -915,6 +787,7 @@ func TestStakingFailures(t *testing.T) {
 		testStakingData.StakingAmount,
 		[]string{fpKey, fpKey},
 		int64(testStakingData.StakingTime),
+		nil,
 	)
 	require.Error(t, err)
 
@@ -925,6 +798,7 @@ func TestStakingFailures(t *testing.T) {
 		testStakingData.StakingAmount,
 		[]string{},
 		int64(testStakingData.StakingTime),
+		nil,
 	)
 	require.Error(t, err)
 }
@@ -947,7 +821,7 @@ func TestSendingStakingTransaction(t *testing.T) {
 
 	hashed, err := chainhash.NewHash(datagen.GenRandomByteArray(r, 32))
 	require.NoError(t, err)
-	scr, err := txscript.PayToTaprootScript(tm.CovenantPrivKeys[0].PubKey())
+	scr, err := txscript.PayToTaprootScript(tm.CovenantSigner.PrivKeys[0].PubKey())
 	require.NoError(t, err)
 	_, st, erro := tm.Sa.Wallet().TxDetails(hashed, scr)
 	// query for exsisting tx is not an error, proper state should be returned
@@ -997,7 +871,7 @@ func TestSendingStakingTransaction(t *testing.T) {
 
 	offset := 0
 	limit := 10
-	transactionsResult, err := tm.StakerClient.ListStakingTransactions(context.Background(), &offset, &limit)
+	transactionsResult, err := tm.StakerClient.ListStakingTransactions(context.Background(), &offset, &limit, nil, nil, nil)
 	require.NoError(t, err)
 	require.Len(t, transactionsResult.Transactions, 1)
 	require.Equal(t, transactionsResult.TotalTransactionCount, "1")
@@ -1192,7 +1066,7 @@ func TestStakingUnbonding(t *testing.T) {
 	tm.waitForStakingTxState(t, txHash, proto.TransactionState_DELEGATION_ACTIVE)
 
 	feeRate := 2000
-	resp, err := tm.StakerClient.UnbondStaking(context.Background(), txHash.String(), &feeRate)
+	resp, err := tm.StakerClient.UnbondStaking(context.Background(), txHash.String(), &feeRate, nil)
 	require.NoError(t, err)
 
 	unbondingTxHash, err := chainhash.NewHashFromStr(resp.UnbondingTxHash)
@@ -1266,7 +1140,7 @@ func TestUnbondingRestartWaitingForSignatures(t *testing.T) {
 	tm.waitForStakingTxState(t, txHash, proto.TransactionState_DELEGATION_ACTIVE)
 
 	feeRate := 2000
-	unbondResponse, err := tm.StakerClient.UnbondStaking(context.Background(), txHash.String(), &feeRate)
+	unbondResponse, err := tm.StakerClient.UnbondStaking(context.Background(), txHash.String(), &feeRate, nil)
 	require.NoError(t, err)
 	unbondingTxHash, err := chainhash.NewHashFromStr(unbondResponse.UnbondingTxHash)
 	require.NoError(t, err)
@@ -1303,7 +1177,7 @@ func containsOutput(outputs []walletcontroller.Utxo, address string, amount btcu
 }
 
 func TestBitcoindWalletRpcApi(t *testing.T) {
-	h := NewBitcoindHandler(t)
+	h := testutil.NewBitcoindHandler(t)
 	h.Start()
 	passphrase := "pass"
 	numMatureOutputs := 1
@@ -1349,6 +1223,7 @@ func TestBitcoindWalletRpcApi(t *testing.T) {
 		[]*wire.TxOut{newOutput},
 		btcutil.Amount(2000),
 		walletAddress,
+		1,
 	)
 	require.NoError(t, err)
 
@@ -1392,7 +1267,7 @@ func TestSendingStakingTransaction_Restaking(t *testing.T) {
 
 	hashed, err := chainhash.NewHash(datagen.GenRandomByteArray(r, 32))
 	require.NoError(t, err)
-	scr, err := txscript.PayToTaprootScript(tm.CovenantPrivKeys[0].PubKey())
+	scr, err := txscript.PayToTaprootScript(tm.CovenantSigner.PrivKeys[0].PubKey())
 	require.NoError(t, err)
 	_, st, erro := tm.Sa.Wallet().TxDetails(hashed, scr)
 	// query for exsisting tx is not an error, proper state should be returned
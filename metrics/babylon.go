@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// BabylonClientMetrics describe quality of interaction with babylon node. All
+// methods are no-op on nil receiver, so that babylon client can be used without
+// metrics.
+type BabylonClientMetrics struct {
+	QueryLatency     *prometheus.HistogramVec
+	TxBroadcasts     *prometheus.CounterVec
+	AccountSequence  prometheus.Gauge
+	TxGasUsed        *prometheus.HistogramVec
+	TxStatsQueryErrs prometheus.Counter
+}
+
+// NewBabylonClientMetrics registers babylon client metrics with names starting
+// with given prefix
+func NewBabylonClientMetrics(registerer prometheus.Registerer, prefix string) *BabylonClientMetrics {
+	factory := promauto.With(registerer)
+
+	return &BabylonClientMetrics{
+		QueryLatency: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    prefix + "_babylon_query_latency_seconds",
+			Help:    "Latency of single babylon query attempt, by query method and result",
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 12),
+		}, []string{"method", "result"}),
+		TxBroadcasts: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: prefix + "_babylon_tx_broadcasts",
+			Help: "Total number of transactions sent to babylon, by type of the first message and result code",
+		}, []string{"msg_type", "codespace", "code"}),
+		AccountSequence: factory.NewGauge(prometheus.GaugeOpts{
+			Name: prefix + "_babylon_account_sequence",
+			Help: "Sequence of the babylon account signing transactions, updated after each successful transaction",
+		}),
+		TxGasUsed: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    prefix + "_babylon_tx_gas_used",
+			Help:    "Gas used by successful babylon transactions, by type of the first message",
+			Buckets: prometheus.ExponentialBuckets(50_000, 2, 10),
+		}, []string{"msg_type"}),
+		TxStatsQueryErrs: factory.NewCounter(prometheus.CounterOpts{
+			Name: prefix + "_babylon_tx_stats_query_errors",
+			Help: "Total number of failures to query gas used or account sequence after successful transaction",
+		}),
+	}
+}
+
+func (m *BabylonClientMetrics) ObserveQuery(method string, latency time.Duration, err error) {
+	if m == nil {
+		return
+	}
+
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+
+	m.QueryLatency.WithLabelValues(method, result).Observe(latency.Seconds())
+}
+
+func (m *BabylonClientMetrics) ObserveBroadcast(msgType string, codespace string, code uint32) {
+	if m == nil {
+		return
+	}
+
+	m.TxBroadcasts.WithLabelValues(msgType, codespace, strconv.FormatUint(uint64(code), 10)).Inc()
+}
+
+func (m *BabylonClientMetrics) ObserveGasUsed(msgType string, gasUsed int64) {
+	if m == nil {
+		return
+	}
+
+	m.TxGasUsed.WithLabelValues(msgType).Observe(float64(gasUsed))
+}
+
+func (m *BabylonClientMetrics) SetAccountSequence(sequence uint64) {
+	if m == nil {
+		return
+	}
+
+	m.AccountSequence.Set(float64(sequence))
+}
+
+func (m *BabylonClientMetrics) TxStatsQueryFailed() {
+	if m == nil {
+		return
+	}
+
+	m.TxStatsQueryErrs.Inc()
+}
@@ -0,0 +1,383 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	DashboardStaker  = "staker"
+	DashboardMonitor = "monitor"
+
+	dashboardSchemaVersion = 39
+	dashboardWidth         = 24
+	dashboardPanelHeight   = 8
+)
+
+type dashboardTarget struct {
+	expr   string
+	legend string
+}
+
+type dashboardPanel struct {
+	title   string
+	unit    string
+	targets []dashboardTarget
+}
+
+type dashboardRow struct {
+	title  string
+	panels []dashboardPanel
+}
+
+// networkSelector is label selector of the network chosen in dashboard
+const networkSelector = `network="$network"`
+
+func panelTarget(expr string, legend string) dashboardTarget {
+	return dashboardTarget{expr: expr, legend: legend}
+}
+
+func quantileExpr(q string, histogram string, by string) string {
+	return fmt.Sprintf(`histogram_quantile(%s, sum by (le, %s) (rate(%s_bucket{%s}[$__rate_interval])))`, q, by, histogram, networkSelector)
+}
+
+func babylonRow(prefix string) dashboardRow {
+	return dashboardRow{
+		title: "Babylon",
+		panels: []dashboardPanel{
+			{
+				title: "Query latency p95 by method",
+				unit:  "s",
+				targets: []dashboardTarget{
+					panelTarget(quantileExpr("0.95", prefix+"_babylon_query_latency_seconds", "method"), "{{method}}"),
+				},
+			},
+			{
+				title: "Query errors",
+				unit:  "reqps",
+				targets: []dashboardTarget{
+					panelTarget(fmt.Sprintf(`sum by (method) (rate(%s_babylon_query_latency_seconds_count{%s, result="error"}[$__rate_interval]))`, prefix, networkSelector), "{{method}}"),
+				},
+			},
+			{
+				title: "Transactions by message and result code",
+				unit:  "short",
+				targets: []dashboardTarget{
+					panelTarget(fmt.Sprintf(`sum by (msg_type, code) (increase(%s_babylon_tx_broadcasts{%s}[$__rate_interval]))`, prefix, networkSelector), "{{msg_type}} {{code}}"),
+				},
+			},
+			{
+				title: "Gas used p95 by message",
+				unit:  "short",
+				targets: []dashboardTarget{
+					panelTarget(quantileExpr("0.95", prefix+"_babylon_tx_gas_used", "msg_type"), "{{msg_type}}"),
+				},
+			},
+		},
+	}
+}
+
+var stakerDashboardRows = []dashboardRow{
+	{
+		title: "Delegations",
+		panels: []dashboardPanel{
+			{
+				title: "Delegations by state",
+				unit:  "short",
+				targets: []dashboardTarget{
+					panelTarget(`sum by (state) (staker_delegations{`+networkSelector+`})`, "{{state}}"),
+				},
+			},
+			{
+				title: "Oldest delegation age by state",
+				unit:  "s",
+				targets: []dashboardTarget{
+					panelTarget(`max by (state) (staker_oldest_delegation_age_seconds{`+networkSelector+`})`, "{{state}}"),
+				},
+			},
+			{
+				title: "Delegation progress",
+				unit:  "short",
+				targets: []dashboardTarget{
+					panelTarget(`sum(increase(staker_valid_received_delegation_requests{`+networkSelector+`}[$__rate_interval]))`, "requested"),
+					panelTarget(`sum(increase(staker_delegations_confirmed_on_btc{`+networkSelector+`}[$__rate_interval]))`, "confirmed on btc"),
+					panelTarget(`sum(increase(staker_delegations_send_to_babylon{`+networkSelector+`}[$__rate_interval]))`, "sent to babylon"),
+					panelTarget(`sum(increase(staker_delegations_activated_on_babylon{`+networkSelector+`}[$__rate_interval]))`, "activated"),
+				},
+			},
+			{
+				title: "Staking pipeline",
+				unit:  "short",
+				targets: []dashboardTarget{
+					panelTarget(`sum(staker_queued_staking_requests{`+networkSelector+`})`, "queued"),
+					panelTarget(`sum(staker_signings_in_progress{`+networkSelector+`})`, "signing"),
+					panelTarget(`sum(staker_unconfirmed_staking_transactions{`+networkSelector+`})`, "unconfirmed"),
+					panelTarget(`sum(staker_pending_babylon_submissions{`+networkSelector+`})`, "pending babylon submission"),
+				},
+			},
+			{
+				title: "Retry queue",
+				unit:  "short",
+				targets: []dashboardTarget{
+					panelTarget(`sum(staker_retry_queue_length{`+networkSelector+`})`, "waiting"),
+					panelTarget(`sum by (operation) (increase(staker_retry_queue_scheduled_operations{`+networkSelector+`}[$__rate_interval]))`, "scheduled {{operation}}"),
+				},
+			},
+			{
+				title: "Errors",
+				unit:  "short",
+				targets: []dashboardTarget{
+					panelTarget(`sum(increase(staker_number_of_fatal_errors{`+networkSelector+`}[$__rate_interval]))`, "critical errors"),
+					panelTarget(`sum by (sink) (increase(staker_failed_event_sink_publications{`+networkSelector+`}[$__rate_interval]))`, "failed {{sink}} events"),
+					panelTarget(`sum by (action) (increase(staker_policy_hook_checks{`+networkSelector+`, result!="approved"}[$__rate_interval]))`, "policy hook {{action}} not approved"),
+				},
+			},
+		},
+	},
+	{
+		title: "Finality providers",
+		panels: []dashboardPanel{
+			{
+				title: "Delegations by finality provider",
+				unit:  "short",
+				targets: []dashboardTarget{
+					panelTarget(`sum by (fp) (staker_finality_provider_delegations{`+networkSelector+`})`, "{{fp}}"),
+				},
+			},
+			{
+				title: "Stake by finality provider",
+				unit:  "short",
+				targets: []dashboardTarget{
+					panelTarget(`sum by (fp) (staker_finality_provider_stake_satoshis{`+networkSelector+`})`, "{{fp}}"),
+				},
+			},
+		},
+	},
+	{
+		title: "Bitcoin",
+		panels: []dashboardPanel{
+			{
+				title: "Block height",
+				unit:  "none",
+				targets: []dashboardTarget{
+					panelTarget(`max(staker_current_btc_block_height{`+networkSelector+`})`, "daemon"),
+					panelTarget(`max by (backend) (staker_btc_backend_height{`+networkSelector+`})`, "{{backend}} backend"),
+					panelTarget(`max(staker_btc_light_client_height{`+networkSelector+`})`, "babylon light client"),
+				},
+			},
+			{
+				title: "Time to first confirmation",
+				unit:  "s",
+				targets: []dashboardTarget{
+					panelTarget(quantileExpr("0.5", "staker_time_to_first_confirmation_seconds", "kind"), "p50 {{kind}}"),
+					panelTarget(quantileExpr("0.95", "staker_time_to_first_confirmation_seconds", "kind"), "p95 {{kind}}"),
+				},
+			},
+			{
+				title: "Fee rates (sat/kvbyte)",
+				unit:  "short",
+				targets: []dashboardTarget{
+					panelTarget(`max(staker_min_relay_fee_rate{`+networkSelector+`})`, "min relay"),
+					panelTarget(`max(staker_mempool_min_fee_rate{`+networkSelector+`})`, "mempool min"),
+					panelTarget(`max(staker_low_fee_window_open{`+networkSelector+`})`, "low fee window open"),
+				},
+			},
+			{
+				title: "Fees spent (satoshis)",
+				unit:  "short",
+				targets: []dashboardTarget{
+					panelTarget(`sum(staker_fees_spent_last_day{`+networkSelector+`})`, "last day"),
+					panelTarget(`sum(staker_fees_spent_last_week{`+networkSelector+`})`, "last week"),
+				},
+			},
+			{
+				title: "Confirmation SLA exceeded",
+				unit:  "short",
+				targets: []dashboardTarget{
+					panelTarget(`sum by (kind) (increase(staker_confirmation_sla_exceeded{`+networkSelector+`}[$__rate_interval]))`, "{{kind}}"),
+				},
+			},
+			{
+				title: "Backend failover",
+				unit:  "short",
+				targets: []dashboardTarget{
+					panelTarget(`max(staker_btc_backend_secondary_active{`+networkSelector+`})`, "secondary active"),
+					panelTarget(`sum by (to) (increase(staker_btc_backend_switchovers{`+networkSelector+`}[$__rate_interval]))`, "switch to {{to}}"),
+				},
+			},
+		},
+	},
+	babylonRow("staker"),
+	{
+		title: "Babylon account",
+		panels: []dashboardPanel{
+			{
+				title: "Fee account balance",
+				unit:  "short",
+				targets: []dashboardTarget{
+					panelTarget(`max(staker_babylon_account_balance{`+networkSelector+`})`, "balance"),
+					panelTarget(`max(staker_babylon_account_balance_low{`+networkSelector+`})`, "low"),
+				},
+			},
+			{
+				title: "Btc light client lag (blocks)",
+				unit:  "none",
+				targets: []dashboardTarget{
+					panelTarget(`max(staker_btc_light_client_lag_blocks{`+networkSelector+`})`, "lag"),
+				},
+			},
+		},
+	},
+}
+
+var monitorDashboardRows = []dashboardRow{
+	{
+		title: "Monitored transactions",
+		panels: []dashboardPanel{
+			{
+				title: "Transactions",
+				unit:  "short",
+				targets: []dashboardTarget{
+					panelTarget(`sum(monitor_monitored_transactions{`+networkSelector+`})`, "monitored"),
+					panelTarget(`sum(monitor_confirmed_transactions{`+networkSelector+`})`, "confirmed"),
+					panelTarget(`sum(monitor_active_delegations{`+networkSelector+`})`, "active on babylon"),
+					panelTarget(`sum(monitor_spent_transactions{`+networkSelector+`})`, "spent"),
+				},
+			},
+			{
+				title: "Block height",
+				unit:  "none",
+				targets: []dashboardTarget{
+					panelTarget(`max(monitor_current_btc_block_height{`+networkSelector+`})`, "btc"),
+				},
+			},
+			{
+				title: "Failed webhook notifications",
+				unit:  "short",
+				targets: []dashboardTarget{
+					panelTarget(`sum(increase(monitor_failed_webhook_notifications{`+networkSelector+`}[$__rate_interval]))`, "failed"),
+				},
+			},
+		},
+	},
+	babylonRow("monitor"),
+}
+
+func datasourceRef() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "prometheus",
+		"uid":  "${datasource}",
+	}
+}
+
+// GrafanaDashboard returns json of Grafana dashboard showing metrics exported by
+// daemon of given kind. Dashboard has datasource and network variables, so one
+// dashboard serves daemons of all networks.
+func GrafanaDashboard(kind string, title string) ([]byte, error) {
+	var rows []dashboardRow
+	var networkMetric string
+
+	switch kind {
+	case DashboardStaker:
+		rows = stakerDashboardRows
+		networkMetric = "staker_current_btc_block_height"
+	case DashboardMonitor:
+		rows = monitorDashboardRows
+		networkMetric = "monitor_current_btc_block_height"
+	default:
+		return nil, fmt.Errorf("unknown dashboard kind %s", kind)
+	}
+
+	var panels []interface{}
+	id := 1
+	y := 0
+
+	for _, row := range rows {
+		panels = append(panels, map[string]interface{}{
+			"id":        id,
+			"type":      "row",
+			"title":     row.title,
+			"collapsed": false,
+			"panels":    []interface{}{},
+			"gridPos":   map[string]int{"x": 0, "y": y, "w": dashboardWidth, "h": 1},
+		})
+		id++
+		y++
+
+		for i, p := range row.panels {
+			var targets []interface{}
+
+			for j, t := range p.targets {
+				targets = append(targets, map[string]interface{}{
+					"refId":        string(rune('A' + j)),
+					"datasource":   datasourceRef(),
+					"expr":         t.expr,
+					"legendFormat": t.legend,
+				})
+			}
+
+			panels = append(panels, map[string]interface{}{
+				"id":         id,
+				"type":       "timeseries",
+				"title":      p.title,
+				"datasource": datasourceRef(),
+				"targets":    targets,
+				"fieldConfig": map[string]interface{}{
+					"defaults":  map[string]interface{}{"unit": p.unit},
+					"overrides": []interface{}{},
+				},
+				"options": map[string]interface{}{
+					"legend":  map[string]interface{}{"displayMode": "list", "placement": "bottom", "showLegend": true},
+					"tooltip": map[string]interface{}{"mode": "multi", "sort": "desc"},
+				},
+				"gridPos": map[string]int{
+					"x": (i % 2) * dashboardWidth / 2,
+					"y": y + (i/2)*dashboardPanelHeight,
+					"w": dashboardWidth / 2,
+					"h": dashboardPanelHeight,
+				},
+			})
+			id++
+		}
+
+		y += (len(row.panels) + 1) / 2 * dashboardPanelHeight
+	}
+
+	dashboard := map[string]interface{}{
+		"title":         title,
+		"uid":           "btc-" + kind,
+		"tags":          []string{"btc-staker", kind},
+		"schemaVersion": dashboardSchemaVersion,
+		"version":       1,
+		"editable":      true,
+		"refresh":       "30s",
+		"time":          map[string]string{"from": "now-24h", "to": "now"},
+		"timezone":      "browser",
+		"templating": map[string]interface{}{
+			"list": []interface{}{
+				map[string]interface{}{
+					"name":  "datasource",
+					"label": "Data source",
+					"type":  "datasource",
+					"query": "prometheus",
+				},
+				map[string]interface{}{
+					"name":       "network",
+					"label":      "Network",
+					"type":       "query",
+					"datasource": datasourceRef(),
+					"query": map[string]string{
+						"query": fmt.Sprintf("label_values(%s, %s)", networkMetric, NetworkLabel),
+						"refId": "network",
+					},
+					"definition": fmt.Sprintf("label_values(%s, %s)", networkMetric, NetworkLabel),
+					"refresh":    1,
+					"sort":       1,
+				},
+			},
+		},
+		"panels": panels,
+	}
+
+	return json.MarshalIndent(dashboard, "", "  ")
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type MonitorMetrics struct {
+	Registry                  *prometheus.Registry
+	MonitoredTransactions     prometheus.Gauge
+	ConfirmedTransactions     prometheus.Gauge
+	SpentTransactions         prometheus.Gauge
+	ActiveDelegations         prometheus.Gauge
+	CurrentBtcBlockHeight     prometheus.Gauge
+	FailedWebhookNotification prometheus.Counter
+	RpcSlowRequests           *prometheus.CounterVec
+	Babylon                   *BabylonClientMetrics
+}
+
+// NewMonitorMetrics creates monitor metrics labeled with name of btc network
+func NewMonitorMetrics(network string) *MonitorMetrics {
+	registry := prometheus.NewRegistry()
+	networkRegisterer := prometheus.WrapRegistererWith(prometheus.Labels{NetworkLabel: network}, registry)
+	registerer := promauto.With(networkRegisterer)
+
+	metrics := &MonitorMetrics{
+		Registry: registry,
+		MonitoredTransactions: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "monitor_monitored_transactions",
+			Help: "Number of monitored staking transactions",
+		}),
+		ConfirmedTransactions: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "monitor_confirmed_transactions",
+			Help: "Number of monitored staking transactions confirmed on btc",
+		}),
+		SpentTransactions: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "monitor_spent_transactions",
+			Help: "Number of monitored staking transactions which staking output was spent",
+		}),
+		ActiveDelegations: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "monitor_active_delegations",
+			Help: "Number of monitored staking transactions which delegation is active on babylon",
+		}),
+		CurrentBtcBlockHeight: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "monitor_current_btc_block_height",
+			Help: "Current block height of the btc chain",
+		}),
+		FailedWebhookNotification: registerer.NewCounter(prometheus.CounterOpts{
+			Name: "monitor_failed_webhook_notifications",
+			Help: "Total number of webhook notifications which could not be delivered",
+		}),
+		RpcSlowRequests: registerer.NewCounterVec(prometheus.CounterOpts{
+			Name: "monitor_rpc_slow_requests",
+			Help: "Total number of rpc requests which took longer than configured slow request threshold by method",
+		}, []string{"method"}),
+		Babylon: NewBabylonClientMetrics(networkRegisterer, "monitor"),
+	}
+	return metrics
+}
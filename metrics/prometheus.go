@@ -12,6 +12,9 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// NetworkLabel is label with name of btc network set on all metrics of the daemon
+const NetworkLabel = "network"
+
 func Start(logger *logrus.Logger, addr string, reg *prometheus.Registry) {
 	go start(logger, addr, reg)
 }
@@ -13,11 +13,49 @@ type StakerMetrics struct {
 	DelegationsActivatedOnBabylon   prometheus.Counter
 	NumberOfFatalErrors             prometheus.Counter
 	CurrentBtcBlockHeight           prometheus.Gauge
+	QueuedStakingRequests           prometheus.Gauge
+	SigningsInProgress              prometheus.Gauge
+	UnconfirmedStakingTransactions  prometheus.Gauge
+	PendingBabylonSubmissions       prometheus.Gauge
+	RetryQueueLength                prometheus.Gauge
+	RetryQueueScheduledOperations   *prometheus.CounterVec
+	TrackedConfirmations            prometheus.Gauge
+	FeesSpentLastDay                prometheus.Gauge
+	FeesSpentLastWeek               prometheus.Gauge
+	AdditionalBroadcasts            *prometheus.CounterVec
+	MinRelayFeeRate                 prometheus.Gauge
+	MempoolMinFeeRate               prometheus.Gauge
+	PolicyHookChecks                *prometheus.CounterVec
+	LowFeeWindowOpen                prometheus.Gauge
+	OldestDelegationAge             *prometheus.GaugeVec
+	Delegations                     *prometheus.GaugeVec
+	FinalityProviderDelegations     *prometheus.GaugeVec
+	FinalityProviderStake           *prometheus.GaugeVec
+	BabylonAccountBalance           prometheus.Gauge
+	BabylonAccountBalanceLow        prometheus.Gauge
+	FaucetRequests                  *prometheus.CounterVec
+	QuotaRejections                 *prometheus.CounterVec
+	QuotaUsedStakes                 *prometheus.GaugeVec
+	QuotaUsedAmount                 *prometheus.GaugeVec
+	TimeToFirstConfirmation         *prometheus.HistogramVec
+	ConfirmationSlaExceeded         *prometheus.CounterVec
+	BtcBackendSwitchovers           *prometheus.CounterVec
+	BtcBackendSecondaryActive       prometheus.Gauge
+	BtcBackendHeight                *prometheus.GaugeVec
+	BtcLightClientHeight            prometheus.Gauge
+	BtcLightClientLag               prometheus.Gauge
+	BtcLightClientLagging           prometheus.Gauge
+	FailedEventSinkPublications     *prometheus.CounterVec
+	RpcSlowRequests                 *prometheus.CounterVec
+	Babylon                         *BabylonClientMetrics
 }
 
-func NewStakerMetrics() *StakerMetrics {
+// NewStakerMetrics creates staker metrics, all of them labeled with name of btc
+// network, so that dashboards can show multiple daemons side by side
+func NewStakerMetrics(network string) *StakerMetrics {
 	registry := prometheus.NewRegistry()
-	registerer := promauto.With(registry)
+	networkRegisterer := prometheus.WrapRegistererWith(prometheus.Labels{NetworkLabel: network}, registry)
+	registerer := promauto.With(networkRegisterer)
 
 	metrics := &StakerMetrics{
 		Registry: registry,
@@ -45,6 +83,144 @@ func NewStakerMetrics() *StakerMetrics {
 			Name: "staker_current_btc_block_height",
 			Help: "Current block height of the btc chain",
 		}),
+		QueuedStakingRequests: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "staker_queued_staking_requests",
+			Help: "Number of staking requests waiting for free signing or unconfirmed transaction slot",
+		}),
+		SigningsInProgress: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "staker_signings_in_progress",
+			Help: "Number of staking transactions being created, signed and sent by the wallet",
+		}),
+		UnconfirmedStakingTransactions: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "staker_unconfirmed_staking_transactions",
+			Help: "Number of staking transactions sent to btc which are not confirmed yet",
+		}),
+		PendingBabylonSubmissions: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "staker_pending_babylon_submissions",
+			Help: "Number of delegations waiting to be submitted to babylon",
+		}),
+		RetryQueueLength: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "staker_retry_queue_length",
+			Help: "Number of failed operations waiting in retry queue",
+		}),
+		RetryQueueScheduledOperations: registerer.NewCounterVec(prometheus.CounterOpts{
+			Name: "staker_retry_queue_scheduled_operations",
+			Help: "Total number of failed operations scheduled for retry by operation",
+		}, []string{"operation"}),
+		TrackedConfirmations: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "staker_tracked_confirmations",
+			Help: "Number of btc transactions waiting for required number of confirmations",
+		}),
+		FeesSpentLastDay: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "staker_fees_spent_last_day",
+			Help: "Btc fees (in satoshis) paid by transactions sent by the daemon in last 24 hours",
+		}),
+		FeesSpentLastWeek: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "staker_fees_spent_last_week",
+			Help: "Btc fees (in satoshis) paid by transactions sent by the daemon in last 7 days",
+		}),
+		AdditionalBroadcasts: registerer.NewCounterVec(prometheus.CounterOpts{
+			Name: "staker_additional_broadcasts",
+			Help: "Total number of transactions broadcast to additional endpoints by endpoint and result",
+		}, []string{"endpoint", "result"}),
+		MinRelayFeeRate: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "staker_min_relay_fee_rate",
+			Help: "Minimum relay fee rate (in sat/kvbyte) of connected btc node",
+		}),
+		MempoolMinFeeRate: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "staker_mempool_min_fee_rate",
+			Help: "Minimum fee rate (in sat/kvbyte) of transactions accepted to mempool of connected btc node",
+		}),
+		PolicyHookChecks: registerer.NewCounterVec(prometheus.CounterOpts{
+			Name: "staker_policy_hook_checks",
+			Help: "Total number of requests checked by external policy service by action and result",
+		}, []string{"action", "result"}),
+		LowFeeWindowOpen: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "staker_low_fee_window_open",
+			Help: "1 if estimated fee rate is low enough to send non urgent transactions, 0 otherwise",
+		}),
+		OldestDelegationAge: registerer.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "staker_oldest_delegation_age_seconds",
+			Help: "Time (in seconds) for which the oldest delegation in given non terminal state stays in it, 0 if there is no delegation in the state",
+		}, []string{"state"}),
+		Delegations: registerer.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "staker_delegations",
+			Help: "Number of tracked delegations by state",
+		}, []string{"state"}),
+		FinalityProviderDelegations: registerer.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "staker_finality_provider_delegations",
+			Help: "Number of delegations in non terminal states by finality provider alias or public key",
+		}, []string{"fp"}),
+		FinalityProviderStake: registerer.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "staker_finality_provider_stake_satoshis",
+			Help: "Satoshis staked by delegations in non terminal states by finality provider alias or public key",
+		}, []string{"fp"}),
+		BabylonAccountBalance: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "staker_babylon_account_balance",
+			Help: "Balance of babylon account paying fees of delegation submissions, in monitored denom",
+		}),
+		BabylonAccountBalanceLow: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "staker_babylon_account_balance_low",
+			Help: "1 if balance of babylon fee account is below configured minimum, 0 otherwise",
+		}),
+		FaucetRequests: registerer.NewCounterVec(prometheus.CounterOpts{
+			Name: "staker_faucet_requests",
+			Help: "Total number of requests for funds sent to testnet faucet by result",
+		}, []string{"result"}),
+		QuotaRejections: registerer.NewCounterVec(prometheus.CounterOpts{
+			Name: "staker_quota_rejections",
+			Help: "Total number of stake requests rejected by quota of api identity by exceeded limit",
+		}, []string{"identity", "limit"}),
+		QuotaUsedStakes: registerer.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "staker_quota_used_stakes",
+			Help: "Number of stakes of api identity in last 24 hours",
+		}, []string{"identity"}),
+		QuotaUsedAmount: registerer.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "staker_quota_used_amount",
+			Help: "Satoshis staked by api identity in last 24 hours",
+		}, []string{"identity"}),
+		TimeToFirstConfirmation: registerer.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "staker_time_to_first_confirmation_seconds",
+			Help:    "Time (in seconds) between sending transaction and observing its first confirmation by transaction kind",
+			Buckets: []float64{60, 300, 600, 1200, 1800, 3600, 7200, 14400, 43200, 86400},
+		}, []string{"kind"}),
+		ConfirmationSlaExceeded: registerer.NewCounterVec(prometheus.CounterOpts{
+			Name: "staker_confirmation_sla_exceeded",
+			Help: "Total number of transactions which received first confirmation later than configured target by transaction kind",
+		}, []string{"kind"}),
+		BtcBackendSwitchovers: registerer.NewCounterVec(prometheus.CounterOpts{
+			Name: "staker_btc_backend_switchovers",
+			Help: "Total number of switches between primary and secondary btc backend by backend which became active",
+		}, []string{"to"}),
+		BtcBackendSecondaryActive: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "staker_btc_backend_secondary_active",
+			Help: "1 if broadcasts and confirmation queries are served by secondary btc backend, 0 otherwise",
+		}),
+		BtcBackendHeight: registerer.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "staker_btc_backend_height",
+			Help: "Best block height reported by btc backend, -1 if backend is unreachable",
+		}, []string{"backend"}),
+		BtcLightClientHeight: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "staker_btc_light_client_height",
+			Help: "Height of the best btc header known to babylon btc light client",
+		}),
+		BtcLightClientLag: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "staker_btc_light_client_lag_blocks",
+			Help: "Number of blocks by which babylon btc light client lags behind connected btc node",
+		}),
+		BtcLightClientLagging: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "staker_btc_light_client_lagging",
+			Help: "1 if babylon btc light client lags behind btc node by more than configured number of blocks, 0 otherwise",
+		}),
+		FailedEventSinkPublications: registerer.NewCounterVec(prometheus.CounterOpts{
+			Name: "staker_failed_event_sink_publications",
+			Help: "Total number of lifecycle events which could not be published by sink",
+		}, []string{"sink"}),
+		RpcSlowRequests: registerer.NewCounterVec(prometheus.CounterOpts{
+			Name: "staker_rpc_slow_requests",
+			Help: "Total number of rpc requests which took longer than configured slow request threshold by method",
+		}, []string{"method"}),
+		Babylon: NewBabylonClientMetrics(networkRegisterer, "staker"),
 	}
 	return metrics
 }
@@ -0,0 +1,119 @@
+// Package mockbabylon implements deterministic mock of babylon node queries and
+// messages used by the daemon, so that integration tests can run without
+// babylon localnet. Server is started by stakercli dev mock-babylon, and
+// daemon connects to it when babylon mock-url option is set.
+package mockbabylon
+
+// Paths of endpoints served by mock server, every request is json POST
+const (
+	ParamsPath               = "/params"
+	FinalityProvidersPath    = "/finality_providers"
+	FinalityProviderPath     = "/finality_provider"
+	HeaderDepthPath          = "/header_depth"
+	DelegationPath           = "/delegation"
+	DelegatePath             = "/delegate"
+	UndelegatePath           = "/undelegate"
+	ControlAdvanceBlocksPath = "/control/advance_blocks"
+	ControlStatePath         = "/control/state"
+)
+
+// codes of errors mapped to errors of babylon client
+const (
+	errCodeDelegationNotFound = "delegation_not_found"
+	errCodeFpNotFound         = "finality_provider_not_found"
+	errCodeHeaderNotKnown     = "header_not_known"
+	errCodeInvalidRequest     = "invalid_request"
+)
+
+type ErrorResponse struct {
+	Code  string `json:"code"`
+	Error string `json:"error"`
+}
+
+type ParamsResponse struct {
+	ConfirmationTimeBlocks    uint32 `json:"confirmation_time_blocks"`
+	FinalizationTimeoutBlocks uint32 `json:"finalization_timeout_blocks"`
+	MinSlashingTxFeeSat       int64  `json:"min_slashing_tx_fee_sat"`
+	// hex encoded compressed keys
+	CovenantPks      []string `json:"covenant_pks"`
+	CovenantQuorum   uint32   `json:"covenant_quorum"`
+	SlashingAddress  string   `json:"slashing_address"`
+	SlashingRate     string   `json:"slashing_rate"`
+	MinUnbondingTime uint16   `json:"min_unbonding_time"`
+}
+
+type FinalityProviderResponse struct {
+	// hex encoded compressed keys
+	BabylonPk string `json:"babylon_pk"`
+	BtcPk     string `json:"btc_pk"`
+}
+
+type FinalityProvidersRequest struct {
+	Limit  uint64 `json:"limit"`
+	Offset uint64 `json:"offset"`
+}
+
+type FinalityProvidersResponse struct {
+	FinalityProviders []FinalityProviderResponse `json:"finality_providers"`
+	Total             uint64                     `json:"total"`
+}
+
+type FinalityProviderRequest struct {
+	BtcPk string `json:"btc_pk"`
+}
+
+type HashRequest struct {
+	Hash string `json:"hash"`
+}
+
+type HeaderDepthResponse struct {
+	Depth uint64 `json:"depth"`
+}
+
+type CovenantSignatureResponse struct {
+	Pk        string `json:"pk"`
+	Signature string `json:"signature"`
+}
+
+type DelegationResponse struct {
+	Active        bool                        `json:"active"`
+	UnbondingTx   string                      `json:"unbonding_tx"`
+	UnbondingTime uint16                      `json:"unbonding_time"`
+	CovenantSigs  []CovenantSignatureResponse `json:"covenant_sigs"`
+	Unbonded      bool                        `json:"unbonded"`
+}
+
+type DelegateRequest struct {
+	StakingTx              string   `json:"staking_tx"`
+	StakingOutputIdx       uint32   `json:"staking_output_idx"`
+	InclusionBlockHash     string   `json:"inclusion_block_hash"`
+	StakingTime            uint16   `json:"staking_time"`
+	StakerBtcPk            string   `json:"staker_btc_pk"`
+	FinalityProviderBtcPks []string `json:"finality_provider_btc_pks"`
+	UnbondingTx            string   `json:"unbonding_tx"`
+	UnbondingTime          uint16   `json:"unbonding_time"`
+}
+
+type UndelegateRequest struct {
+	StakingTxHash      string `json:"staking_tx_hash"`
+	StakerUnbondingSig string `json:"staker_unbonding_sig"`
+}
+
+type TxResponse struct {
+	TxHash    string `json:"tx_hash"`
+	Height    int64  `json:"height"`
+	Code      uint32 `json:"code"`
+	Codespace string `json:"codespace"`
+}
+
+type AdvanceBlocksRequest struct {
+	Blocks uint64 `json:"blocks"`
+}
+
+type StateResponse struct {
+	Height      uint64 `json:"height"`
+	Delegations uint64 `json:"delegations"`
+	Active      uint64 `json:"active"`
+	Unbonded    uint64 `json:"unbonded"`
+	Messages    uint64 `json:"messages"`
+}
@@ -0,0 +1,374 @@
+package mockbabylon
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	cl "github.com/babylonchain/btc-staker/babylonclient"
+	"github.com/babylonchain/btc-staker/utils"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	pv "github.com/cosmos/relayer/v2/relayer/provider"
+)
+
+const clientTimeout = 10 * time.Second
+
+// Client implements babylon client of the daemon against mock server. Its
+// babylon key is derived from fixed seed, as mock does not check signatures.
+type Client struct {
+	url    string
+	net    *chaincfg.Params
+	key    *secp256k1.PrivKey
+	client *http.Client
+}
+
+var _ cl.BabylonClient = (*Client)(nil)
+
+func NewClient(url string, net *chaincfg.Params) *Client {
+	return &Client{
+		url:    strings.TrimSuffix(url, "/"),
+		net:    net,
+		key:    secp256k1.GenPrivKeyFromSecret([]byte("mockbabylon-staker")),
+		client: &http.Client{Timeout: clientTimeout},
+	}
+}
+
+type mockError struct {
+	code    string
+	message string
+}
+
+func (e *mockError) Error() string {
+	return fmt.Sprintf("mock babylon error %s: %s", e.code, e.message)
+}
+
+func (c *Client) call(path string, req interface{}, resp interface{}) error {
+	reqBytes, err := json.Marshal(req)
+
+	if err != nil {
+		return err
+	}
+
+	httpResp, err := c.client.Post(c.url+path, "application/json", bytes.NewReader(reqBytes))
+
+	if err != nil {
+		return err
+	}
+
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		var errResp ErrorResponse
+
+		if err := json.NewDecoder(httpResp.Body).Decode(&errResp); err != nil {
+			return fmt.Errorf("mock babylon responded with status %d", httpResp.StatusCode)
+		}
+
+		switch errResp.Code {
+		case errCodeDelegationNotFound:
+			return cl.ErrDelegationNotFound
+		case errCodeFpNotFound:
+			return cl.ErrFinalityProviderDoesNotExist
+		case errCodeHeaderNotKnown:
+			return cl.ErrHeaderNotKnownToBabylon
+		default:
+			return &mockError{code: errResp.Code, message: errResp.Error}
+		}
+	}
+
+	return json.NewDecoder(httpResp.Body).Decode(resp)
+}
+
+func (c *Client) Sign(msg []byte) ([]byte, error) {
+	return c.key.Sign(msg)
+}
+
+func (c *Client) GetKeyAddress() sdk.AccAddress {
+	return sdk.AccAddress(c.key.PubKey().Address())
+}
+
+func (c *Client) GetPubKey() *secp256k1.PubKey {
+	return c.key.PubKey().(*secp256k1.PubKey)
+}
+
+func (c *Client) Params() (*cl.StakingParams, error) {
+	var resp ParamsResponse
+
+	if err := c.call(ParamsPath, struct{}{}, &resp); err != nil {
+		return nil, err
+	}
+
+	var covenantPks []*btcec.PublicKey
+
+	for _, k := range resp.CovenantPks {
+		pk, err := parsePubKey(k)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid covenant key: %w", err)
+		}
+
+		covenantPks = append(covenantPks, pk)
+	}
+
+	slashingAddress, err := btcutil.DecodeAddress(resp.SlashingAddress, c.net)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid slashing address: %w", err)
+	}
+
+	slashingRate, err := sdkmath.LegacyNewDecFromStr(resp.SlashingRate)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid slashing rate: %w", err)
+	}
+
+	return &cl.StakingParams{
+		ConfirmationTimeBlocks:    resp.ConfirmationTimeBlocks,
+		FinalizationTimeoutBlocks: resp.FinalizationTimeoutBlocks,
+		MinSlashingTxFeeSat:       btcutil.Amount(resp.MinSlashingTxFeeSat),
+		CovenantPks:               covenantPks,
+		SlashingAddress:           slashingAddress,
+		SlashingRate:              slashingRate,
+		CovenantQuruomThreshold:   resp.CovenantQuorum,
+		MinUnbondingTime:          resp.MinUnbondingTime,
+	}, nil
+}
+
+func txResponse(resp *TxResponse) (*pv.RelayerTxResponse, error) {
+	relayerResp := &pv.RelayerTxResponse{
+		Height:    resp.Height,
+		TxHash:    resp.TxHash,
+		Codespace: resp.Codespace,
+		Code:      resp.Code,
+	}
+
+	if resp.Code != 0 {
+		return relayerResp, fmt.Errorf("mock babylon tx %s failed with code %d: %w", resp.TxHash, resp.Code, cl.ErrInvalidBabylonExecution)
+	}
+
+	return relayerResp, nil
+}
+
+func (c *Client) Delegate(dg *cl.DelegationData) (*pv.RelayerTxResponse, error) {
+	if dg.Ud == nil {
+		return nil, fmt.Errorf("nil undelegation data")
+	}
+
+	stakingTx, err := utils.SerializeBtcTransaction(dg.StakingTransaction)
+
+	if err != nil {
+		return nil, err
+	}
+
+	unbondingTx, err := utils.SerializeBtcTransaction(dg.Ud.UnbondingTransaction)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req := &DelegateRequest{
+		StakingTx:          hex.EncodeToString(stakingTx),
+		StakingOutputIdx:   dg.StakingTransactionIdx,
+		InclusionBlockHash: dg.StakingTransactionInclusionBlockHash.String(),
+		StakingTime:        dg.StakingTime,
+		StakerBtcPk:        hex.EncodeToString(dg.StakerBtcPk.SerializeCompressed()),
+		UnbondingTx:        hex.EncodeToString(unbondingTx),
+		UnbondingTime:      dg.Ud.UnbondingTxUnbondingTime,
+	}
+
+	for _, fpPk := range dg.FinalityProvidersBtcPks {
+		req.FinalityProviderBtcPks = append(req.FinalityProviderBtcPks, hex.EncodeToString(fpPk.SerializeCompressed()))
+	}
+
+	var resp TxResponse
+
+	if err := c.call(DelegatePath, req, &resp); err != nil {
+		return nil, err
+	}
+
+	return txResponse(&resp)
+}
+
+func (c *Client) Undelegate(req *cl.UndelegationRequest) (*pv.RelayerTxResponse, error) {
+	var resp TxResponse
+
+	err := c.call(UndelegatePath, &UndelegateRequest{
+		StakingTxHash:      req.StakingTxHash.String(),
+		StakerUnbondingSig: hex.EncodeToString(req.StakerUnbondingSig.Serialize()),
+	}, &resp)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return txResponse(&resp)
+}
+
+func finalityProviderInfo(resp *FinalityProviderResponse) (*cl.FinalityProviderInfo, error) {
+	btcPk, err := parsePubKey(resp.BtcPk)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid finality provider btc key: %w", err)
+	}
+
+	babylonPk, err := hex.DecodeString(resp.BabylonPk)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid finality provider babylon key: %w", err)
+	}
+
+	return &cl.FinalityProviderInfo{
+		BabylonPk: secp256k1.PubKey{Key: babylonPk},
+		BtcPk:     *btcPk,
+	}, nil
+}
+
+func (c *Client) QueryFinalityProviders(limit uint64, offset uint64) (*cl.FinalityProvidersClientResponse, error) {
+	var resp FinalityProvidersResponse
+
+	if err := c.call(FinalityProvidersPath, &FinalityProvidersRequest{Limit: limit, Offset: offset}, &resp); err != nil {
+		return nil, err
+	}
+
+	result := &cl.FinalityProvidersClientResponse{Total: resp.Total}
+
+	for i := range resp.FinalityProviders {
+		fp, err := finalityProviderInfo(&resp.FinalityProviders[i])
+
+		if err != nil {
+			return nil, err
+		}
+
+		result.FinalityProviders = append(result.FinalityProviders, *fp)
+	}
+
+	return result, nil
+}
+
+func (c *Client) QueryFinalityProvider(btcPubKey *btcec.PublicKey) (*cl.FinalityProviderClientResponse, error) {
+	var resp FinalityProviderResponse
+
+	err := c.call(FinalityProviderPath, &FinalityProviderRequest{
+		BtcPk: hex.EncodeToString(btcPubKey.SerializeCompressed()),
+	}, &resp)
+
+	if err != nil {
+		return nil, err
+	}
+
+	fp, err := finalityProviderInfo(&resp)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &cl.FinalityProviderClientResponse{FinalityProvider: *fp}, nil
+}
+
+func (c *Client) QueryHeaderDepth(headerHash *chainhash.Hash) (uint64, error) {
+	var resp HeaderDepthResponse
+
+	if err := c.call(HeaderDepthPath, &HashRequest{Hash: headerHash.String()}, &resp); err != nil {
+		return 0, err
+	}
+
+	return resp.Depth, nil
+}
+
+// QueryBtcLightClientTip is not supported, as mock babylon does not follow btc
+// chain and reports header depths from the scenario
+func (c *Client) QueryBtcLightClientTip() (uint64, error) {
+	return 0, fmt.Errorf("btc light client tip is not supported by mock babylon: %w", errors.ErrUnsupported)
+}
+
+func (c *Client) IsTxAlreadyPartOfDelegation(stakingTxHash *chainhash.Hash) (bool, error) {
+	_, err := c.QueryDelegationInfo(stakingTxHash)
+
+	if err != nil {
+		if errors.Is(err, cl.ErrDelegationNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (c *Client) QueryDelegationInfo(stakingTxHash *chainhash.Hash) (*cl.DelegationInfo, error) {
+	var resp DelegationResponse
+
+	if err := c.call(DelegationPath, &HashRequest{Hash: stakingTxHash.String()}, &resp); err != nil {
+		return nil, err
+	}
+
+	unbondingTx, err := parseTx(resp.UnbondingTx)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid unbonding tx: %w", err)
+	}
+
+	info := &cl.UndelegationInfo{
+		UnbondingTransaction: unbondingTx,
+		UnbondingTime:        resp.UnbondingTime,
+	}
+
+	for _, s := range resp.CovenantSigs {
+		pk, err := parsePubKey(s.Pk)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid covenant key: %w", err)
+		}
+
+		sigBytes, err := hex.DecodeString(s.Signature)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid covenant signature: %w", err)
+		}
+
+		sig, err := schnorr.ParseSignature(sigBytes)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid covenant signature: %w", err)
+		}
+
+		info.CovenantUnbondingSignatures = append(info.CovenantUnbondingSignatures, cl.CovenantSignatureInfo{
+			Signature: sig,
+			PubKey:    pk,
+		})
+	}
+
+	return &cl.DelegationInfo{
+		Active:           resp.Active,
+		UndelegationInfo: info,
+	}, nil
+}
+
+// QueryRewardGauges returns no rewards, mock does not distribute them
+func (c *Client) QueryRewardGauges(_ sdk.AccAddress) (map[string]*cl.RewardGauge, error) {
+	return map[string]*cl.RewardGauge{}, nil
+}
+
+// mockBalance is balance reported for every account, mock does not charge fees
+const mockBalance = 1_000_000_000_000
+
+// QueryBalance returns large constant balance, mock does not charge fees
+func (c *Client) QueryBalance(_ sdk.AccAddress, denom string) (sdk.Coin, error) {
+	return sdk.NewInt64Coin(denom, mockBalance), nil
+}
+
+func (c *Client) WithdrawRewards(_ string, _ sdk.AccAddress, _ sdk.Coins) (*pv.RelayerTxResponse, error) {
+	return nil, fmt.Errorf("rewards are not supported by mock babylon")
+}
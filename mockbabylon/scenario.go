@@ -0,0 +1,156 @@
+package mockbabylon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// Scenario defines params and behaviour of the mock. All keys not provided in
+// the scenario are derived from fixed seeds, so that every run of the same
+// scenario produces the same keys and signatures.
+type Scenario struct {
+	ConfirmationTimeBlocks    uint32 `json:"confirmation_time_blocks"`
+	FinalizationTimeoutBlocks uint32 `json:"finalization_timeout_blocks"`
+	MinSlashingTxFeeSat       int64  `json:"min_slashing_tx_fee_sat"`
+	// empty means p2wpkh address of key derived from seed
+	SlashingAddress  string `json:"slashing_address"`
+	SlashingRate     string `json:"slashing_rate"`
+	MinUnbondingTime uint16 `json:"min_unbonding_time"`
+	// hex encoded private keys of covenant members, if empty num_covenants
+	// keys are derived from seed
+	CovenantPrivKeys []string `json:"covenant_private_keys"`
+	NumCovenants     int      `json:"num_covenants"`
+	CovenantQuorum   uint32   `json:"covenant_quorum"`
+	// hex encoded btc keys of registered finality providers, if empty one
+	// finality provider with key derived from seed is registered
+	FinalityProviders []string `json:"finality_providers"`
+	// depth reported for every btc header, 0 means that headers are not known
+	// to babylon
+	HeaderDepth uint64 `json:"header_depth"`
+	// babylon blocks after inclusion of delegation, after which covenant
+	// members sign its unbonding transaction
+	CovenantSignatureDelayBlocks uint64 `json:"covenant_signature_delay_blocks"`
+	// covenant members never sign unbonding transactions
+	WithholdCovenantSignatures bool `json:"withhold_covenant_signatures"`
+	// number of first delegations which fail execution
+	FailDelegations uint64 `json:"fail_delegations"`
+	// duration in go format e.g 1s, after which babylon block is produced. Empty
+	// means that blocks are produced only through control endpoint, which makes
+	// runs fully deterministic
+	BlockTime string `json:"block_time"`
+}
+
+func DefaultScenario() *Scenario {
+	return &Scenario{
+		ConfirmationTimeBlocks:       2,
+		FinalizationTimeoutBlocks:    4,
+		MinSlashingTxFeeSat:          1000,
+		SlashingRate:                 "0.1",
+		MinUnbondingTime:             10,
+		NumCovenants:                 3,
+		CovenantQuorum:               2,
+		HeaderDepth:                  10,
+		CovenantSignatureDelayBlocks: 1,
+		BlockTime:                    "1s",
+	}
+}
+
+// LoadScenario reads scenario from json file, fields missing in the file have
+// default values
+func LoadScenario(path string) (*Scenario, error) {
+	s := DefaultScenario()
+
+	if path == "" {
+		return s, nil
+	}
+
+	bytes, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(bytes, s); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+
+	return s, nil
+}
+
+// seedKey derives private key from fixed seed
+func seedKey(seed string, i int) *btcec.PrivateKey {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("mockbabylon-%s-%d", seed, i)))
+	privKey, _ := btcec.PrivKeyFromBytes(hash[:])
+	return privKey
+}
+
+func (s *Scenario) blockTime() (time.Duration, error) {
+	if s.BlockTime == "" {
+		return 0, nil
+	}
+
+	return time.ParseDuration(s.BlockTime)
+}
+
+func (s *Scenario) covenantKeys() ([]*btcec.PrivateKey, error) {
+	if len(s.CovenantPrivKeys) == 0 {
+		keys := make([]*btcec.PrivateKey, s.NumCovenants)
+
+		for i := range keys {
+			keys[i] = seedKey("covenant", i)
+		}
+
+		return keys, nil
+	}
+
+	keys := make([]*btcec.PrivateKey, len(s.CovenantPrivKeys))
+
+	for i, k := range s.CovenantPrivKeys {
+		keyBytes, err := hex.DecodeString(k)
+
+		if err != nil || len(keyBytes) != btcec.PrivKeyBytesLen {
+			return nil, fmt.Errorf("invalid covenant private key %d", i)
+		}
+
+		keys[i], _ = btcec.PrivKeyFromBytes(keyBytes)
+	}
+
+	return keys, nil
+}
+
+func (s *Scenario) finalityProviderKeys() ([]*btcec.PublicKey, error) {
+	if len(s.FinalityProviders) == 0 {
+		return []*btcec.PublicKey{seedKey("finality-provider", 0).PubKey()}, nil
+	}
+
+	keys := make([]*btcec.PublicKey, len(s.FinalityProviders))
+
+	for i, k := range s.FinalityProviders {
+		key, err := parsePubKey(k)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid finality provider key %d: %w", i, err)
+		}
+
+		keys[i] = key
+	}
+
+	return keys, nil
+}
+
+func (s *Scenario) slashingAddress(net *chaincfg.Params) (btcutil.Address, error) {
+	if s.SlashingAddress == "" {
+		pk := seedKey("slashing", 0).PubKey()
+		return btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(pk.SerializeCompressed()), net)
+	}
+
+	return btcutil.DecodeAddress(s.SlashingAddress, net)
+}
@@ -0,0 +1,547 @@
+package mockbabylon
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	staking "github.com/babylonchain/babylon/btcstaking"
+	cl "github.com/babylonchain/btc-staker/babylonclient"
+	"github.com/babylonchain/btc-staker/utils"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/sirupsen/logrus"
+)
+
+// codespace of failed delegations, so that they are easy to tell apart from
+// real babylon errors in logs
+const mockCodespace = "mockbabylon"
+
+type delegation struct {
+	stakingTx        *wire.MsgTx
+	stakingOutputIdx uint32
+	stakingInfo      *staking.StakingInfo
+	unbondingTx      *wire.MsgTx
+	unbondingTime    uint16
+	includedAt       uint64
+	covenantSigs     []CovenantSignatureResponse
+	unbonded         bool
+}
+
+// Server is mock of babylon node. All state is kept in memory and advances
+// only with babylon blocks, so that runs of the same scenario are reproducible.
+type Server struct {
+	mu sync.Mutex
+
+	scenario     *Scenario
+	net          *chaincfg.Params
+	params       *ParamsResponse
+	covenantKeys []*btcec.PrivateKey
+	covenantPks  []*btcec.PublicKey
+	fps          []FinalityProviderResponse
+	fpKeys       map[string]struct{}
+	blockTime    time.Duration
+	logger       *logrus.Logger
+
+	height         uint64
+	delegations    map[chainhash.Hash]*delegation
+	numDelegations uint64
+	numMessages    uint64
+}
+
+func parsePubKey(encoded string) (*btcec.PublicKey, error) {
+	keyBytes, err := hex.DecodeString(encoded)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keyBytes) == schnorr.PubKeyBytesLen {
+		return schnorr.ParsePubKey(keyBytes)
+	}
+
+	return btcec.ParsePubKey(keyBytes)
+}
+
+func xOnlyKey(pk *btcec.PublicKey) string {
+	return hex.EncodeToString(schnorr.SerializePubKey(pk))
+}
+
+func NewServer(scenario *Scenario, net *chaincfg.Params, logger *logrus.Logger) (*Server, error) {
+	covenantKeys, err := scenario.covenantKeys()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(covenantKeys) == 0 || scenario.CovenantQuorum == 0 || int(scenario.CovenantQuorum) > len(covenantKeys) {
+		return nil, fmt.Errorf("covenant quorum must be between 1 and number of covenants %d", len(covenantKeys))
+	}
+
+	if _, err := sdkmath.LegacyNewDecFromStr(scenario.SlashingRate); err != nil {
+		return nil, fmt.Errorf("invalid slashing rate: %w", err)
+	}
+
+	slashingAddress, err := scenario.slashingAddress(net)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid slashing address: %w", err)
+	}
+
+	fpKeys, err := scenario.finalityProviderKeys()
+
+	if err != nil {
+		return nil, err
+	}
+
+	blockTime, err := scenario.blockTime()
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid block time: %w", err)
+	}
+
+	s := &Server{
+		scenario:     scenario,
+		net:          net,
+		covenantKeys: covenantKeys,
+		fpKeys:       make(map[string]struct{}, len(fpKeys)),
+		blockTime:    blockTime,
+		logger:       logger,
+		delegations:  make(map[chainhash.Hash]*delegation),
+	}
+
+	s.params = &ParamsResponse{
+		ConfirmationTimeBlocks:    scenario.ConfirmationTimeBlocks,
+		FinalizationTimeoutBlocks: scenario.FinalizationTimeoutBlocks,
+		MinSlashingTxFeeSat:       scenario.MinSlashingTxFeeSat,
+		CovenantQuorum:            scenario.CovenantQuorum,
+		SlashingAddress:           slashingAddress.EncodeAddress(),
+		SlashingRate:              scenario.SlashingRate,
+		MinUnbondingTime:          scenario.MinUnbondingTime,
+	}
+
+	for _, k := range covenantKeys {
+		s.covenantPks = append(s.covenantPks, k.PubKey())
+		s.params.CovenantPks = append(s.params.CovenantPks, hex.EncodeToString(k.PubKey().SerializeCompressed()))
+	}
+
+	for i, k := range fpKeys {
+		// babylon keys of finality providers are never used by the daemon
+		babylonKey := secp256k1.GenPrivKeyFromSecret([]byte(fmt.Sprintf("mockbabylon-finality-provider-babylon-%d", i)))
+
+		s.fps = append(s.fps, FinalityProviderResponse{
+			BabylonPk: hex.EncodeToString(babylonKey.PubKey().Bytes()),
+			BtcPk:     hex.EncodeToString(k.SerializeCompressed()),
+		})
+		s.fpKeys[xOnlyKey(k)] = struct{}{}
+	}
+
+	return s, nil
+}
+
+// ProduceBlocks advances babylon height every block time until quit is closed,
+// it returns immediately if blocks are produced only through control endpoint
+func (s *Server) ProduceBlocks(quit <-chan struct{}) {
+	if s.blockTime == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.blockTime)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.advanceBlocks(1)
+		case <-quit:
+			return
+		}
+	}
+}
+
+func (s *Server) advanceBlocks(blocks uint64) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.height += blocks
+
+	return s.height
+}
+
+// messageHash returns deterministic hash of the message, used as babylon tx hash
+func messageHash(msg interface{}) string {
+	msgBytes, _ := json.Marshal(msg)
+	hash := sha256.Sum256(msgBytes)
+	return fmt.Sprintf("%X", hash[:])
+}
+
+func invalidRequest(format string, args ...interface{}) *ErrorResponse {
+	return &ErrorResponse{Code: errCodeInvalidRequest, Error: fmt.Sprintf(format, args...)}
+}
+
+func (s *Server) finalityProviders(req *FinalityProvidersRequest) (*FinalityProvidersResponse, *ErrorResponse) {
+	resp := &FinalityProvidersResponse{
+		FinalityProviders: []FinalityProviderResponse{},
+		Total:             uint64(len(s.fps)),
+	}
+
+	for i := req.Offset; i < uint64(len(s.fps)) && (req.Limit == 0 || i < req.Offset+req.Limit); i++ {
+		resp.FinalityProviders = append(resp.FinalityProviders, s.fps[i])
+	}
+
+	return resp, nil
+}
+
+func (s *Server) finalityProvider(req *FinalityProviderRequest) (*FinalityProviderResponse, *ErrorResponse) {
+	pk, err := parsePubKey(req.BtcPk)
+
+	if err != nil {
+		return nil, invalidRequest("invalid key: %s", err)
+	}
+
+	for i := range s.fps {
+		fpPk, _ := parsePubKey(s.fps[i].BtcPk)
+
+		if fpPk.IsEqual(pk) {
+			return &s.fps[i], nil
+		}
+	}
+
+	return nil, &ErrorResponse{Code: errCodeFpNotFound, Error: "finality provider not found"}
+}
+
+func (s *Server) headerDepth(_ *HashRequest) (*HeaderDepthResponse, *ErrorResponse) {
+	if s.scenario.HeaderDepth == 0 {
+		return nil, &ErrorResponse{Code: errCodeHeaderNotKnown, Error: "header not known"}
+	}
+
+	return &HeaderDepthResponse{Depth: s.scenario.HeaderDepth}, nil
+}
+
+// signUnbonding signs unbonding transaction by every covenant member, once
+// delay from scenario elapsed
+func (s *Server) signUnbonding(d *delegation) error {
+	if d.covenantSigs != nil ||
+		s.scenario.WithholdCovenantSignatures ||
+		s.height < d.includedAt+s.scenario.CovenantSignatureDelayBlocks {
+		return nil
+	}
+
+	unbondingPathInfo, err := d.stakingInfo.UnbondingPathSpendInfo()
+
+	if err != nil {
+		return err
+	}
+
+	sigs := make([]CovenantSignatureResponse, 0, len(s.covenantKeys))
+
+	for _, k := range s.covenantKeys {
+		sig, err := staking.SignTxWithOneScriptSpendInputFromTapLeaf(
+			d.unbondingTx,
+			d.stakingTx.TxOut[d.stakingOutputIdx],
+			k,
+			unbondingPathInfo.RevealedLeaf,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		sigs = append(sigs, CovenantSignatureResponse{
+			Pk:        xOnlyKey(k.PubKey()),
+			Signature: hex.EncodeToString(sig.Serialize()),
+		})
+	}
+
+	d.covenantSigs = sigs
+
+	return nil
+}
+
+func (s *Server) delegation(req *HashRequest) (*DelegationResponse, *ErrorResponse) {
+	hash, err := chainhash.NewHashFromStr(req.Hash)
+
+	if err != nil {
+		return nil, invalidRequest("invalid hash: %s", err)
+	}
+
+	d, found := s.delegations[*hash]
+
+	if !found {
+		return nil, &ErrorResponse{Code: errCodeDelegationNotFound, Error: "delegation not found"}
+	}
+
+	if err := s.signUnbonding(d); err != nil {
+		return nil, &ErrorResponse{Error: fmt.Sprintf("failed to sign unbonding transaction: %s", err)}
+	}
+
+	unbondingTx, err := utils.SerializeBtcTransaction(d.unbondingTx)
+
+	if err != nil {
+		return nil, &ErrorResponse{Error: err.Error()}
+	}
+
+	return &DelegationResponse{
+		Active:        len(d.covenantSigs) >= int(s.scenario.CovenantQuorum) && !d.unbonded,
+		UnbondingTx:   hex.EncodeToString(unbondingTx),
+		UnbondingTime: d.unbondingTime,
+		CovenantSigs:  d.covenantSigs,
+		Unbonded:      d.unbonded,
+	}, nil
+}
+
+func parseTx(encoded string) (*wire.MsgTx, error) {
+	txBytes, err := hex.DecodeString(encoded)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+
+	if err := tx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// validateDelegation checks the same staking output and unbonding
+// transaction properties which babylon checks, except of signatures and proofs
+func (s *Server) validateDelegation(req *DelegateRequest) (*delegation, error) {
+	stakingTx, err := parseTx(req.StakingTx)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid staking tx: %w", err)
+	}
+
+	if int(req.StakingOutputIdx) >= len(stakingTx.TxOut) {
+		return nil, fmt.Errorf("staking output index %d out of range", req.StakingOutputIdx)
+	}
+
+	stakerPk, err := parsePubKey(req.StakerBtcPk)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid staker key: %w", err)
+	}
+
+	var fpPks []*btcec.PublicKey
+
+	for _, k := range req.FinalityProviderBtcPks {
+		fpPk, err := parsePubKey(k)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid finality provider key: %w", err)
+		}
+
+		if _, found := s.fpKeys[xOnlyKey(fpPk)]; !found {
+			return nil, fmt.Errorf("finality provider %s is not registered", k)
+		}
+
+		fpPks = append(fpPks, fpPk)
+	}
+
+	stakingOutput := stakingTx.TxOut[req.StakingOutputIdx]
+
+	stakingInfo, err := staking.BuildStakingInfo(
+		stakerPk,
+		fpPks,
+		s.covenantPks,
+		s.scenario.CovenantQuorum,
+		req.StakingTime,
+		btcutil.Amount(stakingOutput.Value),
+		s.net,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid staking parameters: %w", err)
+	}
+
+	if !bytes.Equal(stakingInfo.StakingOutput.PkScript, stakingOutput.PkScript) {
+		return nil, fmt.Errorf("staking output script does not match staking parameters")
+	}
+
+	unbondingTx, err := parseTx(req.UnbondingTx)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid unbonding tx: %w", err)
+	}
+
+	stakingTxHash := stakingTx.TxHash()
+
+	if len(unbondingTx.TxIn) != 1 || len(unbondingTx.TxOut) != 1 ||
+		!unbondingTx.TxIn[0].PreviousOutPoint.Hash.IsEqual(&stakingTxHash) ||
+		unbondingTx.TxIn[0].PreviousOutPoint.Index != req.StakingOutputIdx {
+		return nil, fmt.Errorf("unbonding tx must have one input spending staking output and one output")
+	}
+
+	if req.UnbondingTime < s.scenario.MinUnbondingTime {
+		return nil, fmt.Errorf("unbonding time %d is lower than minimum %d", req.UnbondingTime, s.scenario.MinUnbondingTime)
+	}
+
+	return &delegation{
+		stakingTx:        stakingTx,
+		stakingOutputIdx: req.StakingOutputIdx,
+		stakingInfo:      stakingInfo,
+		unbondingTx:      unbondingTx,
+		unbondingTime:    req.UnbondingTime,
+		includedAt:       s.height,
+	}, nil
+}
+
+func (s *Server) delegate(req *DelegateRequest) (*TxResponse, *ErrorResponse) {
+	s.numMessages++
+	s.numDelegations++
+
+	resp := &TxResponse{
+		TxHash: messageHash(req),
+		Height: int64(s.height),
+	}
+
+	if s.numDelegations <= s.scenario.FailDelegations {
+		resp.Code = 1
+		resp.Codespace = mockCodespace
+		return resp, nil
+	}
+
+	d, err := s.validateDelegation(req)
+
+	if err != nil {
+		s.logger.WithError(err).Warn("Rejected invalid delegation")
+		resp.Code = 2
+		resp.Codespace = mockCodespace
+		return resp, nil
+	}
+
+	stakingTxHash := d.stakingTx.TxHash()
+
+	if _, found := s.delegations[stakingTxHash]; found {
+		resp.Code = 3
+		resp.Codespace = mockCodespace
+		return resp, nil
+	}
+
+	s.delegations[stakingTxHash] = d
+
+	s.logger.WithFields(logrus.Fields{
+		"stakingTxHash": stakingTxHash,
+		"height":        s.height,
+	}).Info("Delegation included")
+
+	return resp, nil
+}
+
+func (s *Server) undelegate(req *UndelegateRequest) (*TxResponse, *ErrorResponse) {
+	s.numMessages++
+
+	hash, err := chainhash.NewHashFromStr(req.StakingTxHash)
+
+	if err != nil {
+		return nil, invalidRequest("invalid hash: %s", err)
+	}
+
+	resp := &TxResponse{
+		TxHash: messageHash(req),
+		Height: int64(s.height),
+	}
+
+	d, found := s.delegations[*hash]
+
+	if !found || d.unbonded {
+		resp.Code = 4
+		resp.Codespace = mockCodespace
+		return resp, nil
+	}
+
+	d.unbonded = true
+
+	return resp, nil
+}
+
+func (s *Server) advance(req *AdvanceBlocksRequest) (*StateResponse, *ErrorResponse) {
+	s.height += req.Blocks
+	return s.state(nil)
+}
+
+func (s *Server) state(_ *struct{}) (*StateResponse, *ErrorResponse) {
+	resp := &StateResponse{
+		Height:      s.height,
+		Delegations: uint64(len(s.delegations)),
+		Messages:    s.numMessages,
+	}
+
+	for _, d := range s.delegations {
+		if d.unbonded {
+			resp.Unbonded++
+		} else if len(d.covenantSigs) >= int(s.scenario.CovenantQuorum) {
+			resp.Active++
+		}
+	}
+
+	return resp, nil
+}
+
+func (s *Server) queryParams(_ *struct{}) (*ParamsResponse, *ErrorResponse) {
+	return s.params, nil
+}
+
+// handler decodes json request, calls method holding server lock and encodes
+// its response
+func handler[Req any, Resp any](s *Server, method func(req *Req) (*Resp, *ErrorResponse)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var req Req
+		// empty body is the same as empty json object
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(invalidRequest("invalid request: %s", err))
+			return
+		}
+
+		s.mu.Lock()
+		resp, errResp := method(&req)
+		s.mu.Unlock()
+
+		if errResp != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(errResp)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(ParamsPath, handler(s, s.queryParams))
+	mux.Handle(FinalityProvidersPath, handler(s, s.finalityProviders))
+	mux.Handle(FinalityProviderPath, handler(s, s.finalityProvider))
+	mux.Handle(HeaderDepthPath, handler(s, s.headerDepth))
+	mux.Handle(DelegationPath, handler(s, s.delegation))
+	mux.Handle(DelegatePath, handler(s, s.delegate))
+	mux.Handle(UndelegatePath, handler(s, s.undelegate))
+	mux.Handle(ControlAdvanceBlocksPath, handler(s, s.advance))
+	mux.Handle(ControlStatePath, handler(s, s.state))
+	return mux
+}
+
+// CovenantKeys returns private keys of covenant members, so that tests can
+// sign transactions on their behalf
+func (s *Server) CovenantKeys() []*btcec.PrivateKey {
+	return s.covenantKeys
+}
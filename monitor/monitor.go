@@ -0,0 +1,657 @@
+package monitor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	cl "github.com/babylonchain/btc-staker/babylonclient"
+	"github.com/babylonchain/btc-staker/metrics"
+	"github.com/babylonchain/btc-staker/staker"
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/babylonchain/btc-staker/utils"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	notifier "github.com/lightningnetwork/lnd/chainntnfs"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+)
+
+// ErrTransactionNotMonitored is returned when querying transaction which is not
+// monitored
+var ErrTransactionNotMonitored = errors.New("transaction not monitored")
+
+// BabylonStatus is the status of monitored delegation on babylon
+type BabylonStatus string
+
+const (
+	// Babylon was not queried yet or last query failed
+	BabylonStatusUnknown BabylonStatus = "UNKNOWN"
+	// Delegation for staking transaction was not registered on babylon
+	BabylonStatusNotRegistered BabylonStatus = "NOT_REGISTERED"
+	// Delegation is registered but not active yet i.e it waits for covenant signatures
+	BabylonStatusPending BabylonStatus = "PENDING"
+	BabylonStatusActive  BabylonStatus = "ACTIVE"
+	// Delegation was active before, but it is not active any more i.e it was
+	// unbonded or its timelock expired
+	BabylonStatusInactive BabylonStatus = "INACTIVE"
+)
+
+// MonitoredTxConfig identifies staking transaction to monitor
+type MonitoredTxConfig struct {
+	StakingTxHash chainhash.Hash
+	// Address of the staking output, required to find staking output in staking
+	// transaction and to register for btc notifications
+	StakingOutputAddress btcutil.Address
+}
+
+// ParseMonitoredTxConfig parses monitored transaction in format
+// <staking_tx_hash>:<staking_output_address>
+func ParseMonitoredTxConfig(s string, net *chaincfg.Params) (*MonitoredTxConfig, error) {
+	hashStr, addressStr, found := strings.Cut(s, ":")
+
+	if !found {
+		return nil, fmt.Errorf("invalid monitored transaction %s, expected format <staking_tx_hash>:<staking_output_address>", s)
+	}
+
+	txHash, err := chainhash.NewHashFromStr(hashStr)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid staking transaction hash %s: %w", hashStr, err)
+	}
+
+	address, err := btcutil.DecodeAddress(addressStr, net)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid staking output address %s: %w", addressStr, err)
+	}
+
+	if !address.IsForNet(net) {
+		return nil, fmt.Errorf("staking output address %s is not for network %s", addressStr, net.Name)
+	}
+
+	return &MonitoredTxConfig{
+		StakingTxHash:        *txHash,
+		StakingOutputAddress: address,
+	}, nil
+}
+
+// TransactionSummary is a snapshot of the state of monitored staking transaction
+type TransactionSummary struct {
+	StakingTxHash        string        `json:"staking_tx_hash"`
+	StakingOutputAddress string        `json:"staking_output_address"`
+	StakingOutputIdx     *uint32       `json:"staking_output_idx,omitempty"`
+	StakingValue         int64         `json:"staking_value,omitempty"`
+	ConfirmationHeight   uint32        `json:"confirmation_height,omitempty"`
+	ConfirmationBlock    string        `json:"confirmation_block,omitempty"`
+	Confirmations        uint32        `json:"confirmations"`
+	SpendTxHash          string        `json:"spend_tx_hash,omitempty"`
+	SpendHeight          uint32        `json:"spend_height,omitempty"`
+	SpentByUnbondingTx   bool          `json:"spent_by_unbonding_tx,omitempty"`
+	BabylonStatus        BabylonStatus `json:"babylon_status"`
+	UnbondingTxHash      string        `json:"unbonding_tx_hash,omitempty"`
+	LastBabylonCheck     int64         `json:"last_babylon_check,omitempty"`
+}
+
+type monitoredTx struct {
+	cfg      *MonitoredTxConfig
+	pkScript []byte
+
+	stakingOutputIdx   *uint32
+	stakingValue       btcutil.Amount
+	confirmationHeight uint32
+	confirmationBlock  *chainhash.Hash
+
+	spendTxHash        *chainhash.Hash
+	spendHeight        uint32
+	spentByUnbondingTx bool
+
+	babylonStatus    BabylonStatus
+	unbondingTxHash  *chainhash.Hash
+	lastBabylonCheck time.Time
+}
+
+func (tx *monitoredTx) confirmed() bool {
+	return tx.confirmationBlock != nil
+}
+
+func (tx *monitoredTx) spent() bool {
+	return tx.spendTxHash != nil
+}
+
+func (tx *monitoredTx) summary(bestHeight uint32) *TransactionSummary {
+	s := &TransactionSummary{
+		StakingTxHash:        tx.cfg.StakingTxHash.String(),
+		StakingOutputAddress: tx.cfg.StakingOutputAddress.EncodeAddress(),
+		StakingOutputIdx:     tx.stakingOutputIdx,
+		StakingValue:         int64(tx.stakingValue),
+		BabylonStatus:        tx.babylonStatus,
+		SpentByUnbondingTx:   tx.spentByUnbondingTx,
+	}
+
+	if tx.confirmed() {
+		s.ConfirmationHeight = tx.confirmationHeight
+		s.ConfirmationBlock = tx.confirmationBlock.String()
+
+		if bestHeight >= tx.confirmationHeight {
+			s.Confirmations = bestHeight - tx.confirmationHeight + 1
+		}
+	}
+
+	if tx.spent() {
+		s.SpendTxHash = tx.spendTxHash.String()
+		s.SpendHeight = tx.spendHeight
+	}
+
+	if tx.unbondingTxHash != nil {
+		s.UnbondingTxHash = tx.unbondingTxHash.String()
+	}
+
+	if !tx.lastBabylonCheck.IsZero() {
+		s.LastBabylonCheck = tx.lastBabylonCheck.Unix()
+	}
+
+	return s
+}
+
+// Monitor tracks configured staking transactions without access to btc wallet
+// or babylon key. It only uses btc node backend and read only babylon queries.
+type Monitor struct {
+	startOnce sync.Once
+	stopOnce  sync.Once
+	wg        sync.WaitGroup
+	quit      chan struct{}
+
+	config        *scfg.Config
+	notifier      notifier.ChainNotifier
+	babylonClient cl.BabylonClient
+	logger        *logrus.Logger
+	m             *metrics.MonitorMetrics
+	webhooks      *webhookNotifier
+
+	mu              sync.Mutex
+	txs             map[chainhash.Hash]*monitoredTx
+	bestBlockHeight uint32
+}
+
+func NewMonitorFromConfig(
+	config *scfg.Config,
+	logger *logrus.Logger,
+	rpcClientLogger *zap.Logger,
+	db kvdb.Backend,
+	m *metrics.MonitorMetrics,
+) (*Monitor, error) {
+	// babylon client is only used for queries, so babylon key does not need to
+	// exist in keyring
+	babylonClient, err := cl.NewBabylonController(config.BabylonConfig, &config.ActiveNetParams, logger, rpcClientLogger, m.Babylon)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hintCache, err := channeldb.NewHeightHintCache(
+		channeldb.CacheConfig{
+			QueryDisable: false,
+		}, db,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to create height hint cache: %v", err)
+	}
+
+	nodeNotifier, err := staker.NewNodeBackend(config.BtcNodeBackendConfig, &config.ActiveNetParams, hintCache)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMonitorFromDeps(config, logger, babylonClient, nodeNotifier, m)
+}
+
+func NewMonitorFromDeps(
+	config *scfg.Config,
+	logger *logrus.Logger,
+	babylonClient cl.BabylonClient,
+	nodeNotifier notifier.ChainNotifier,
+	m *metrics.MonitorMetrics,
+) (*Monitor, error) {
+	txs := make(map[chainhash.Hash]*monitoredTx)
+
+	for _, txStr := range config.MonitorConfig.StakingTxs {
+		txCfg, err := ParseMonitoredTxConfig(txStr, &config.ActiveNetParams)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if _, found := txs[txCfg.StakingTxHash]; found {
+			return nil, fmt.Errorf("duplicate monitored staking transaction %s", txCfg.StakingTxHash)
+		}
+
+		pkScript, err := txscript.PayToAddrScript(txCfg.StakingOutputAddress)
+
+		if err != nil {
+			return nil, err
+		}
+
+		txs[txCfg.StakingTxHash] = &monitoredTx{
+			cfg:           txCfg,
+			pkScript:      pkScript,
+			babylonStatus: BabylonStatusUnknown,
+		}
+	}
+
+	return &Monitor{
+		quit:          make(chan struct{}),
+		config:        config,
+		notifier:      nodeNotifier,
+		babylonClient: babylonClient,
+		logger:        logger,
+		m:             m,
+		webhooks: newWebhookNotifier(
+			config.MonitorConfig.WebhookUrls,
+			config.MonitorConfig.WebhookTimeout,
+			logger,
+			m,
+		),
+		txs: txs,
+	}, nil
+}
+
+func (mon *Monitor) Start() error {
+	var startErr error
+	mon.startOnce.Do(func() {
+		mon.logger.Infof("Starting Monitor")
+
+		mon.logger.Infof("Connecting to node backend: %s", mon.config.BtcNodeBackendConfig.Nodetype)
+
+		if err := mon.notifier.Start(); err != nil {
+			startErr = err
+			return
+		}
+
+		blockEventNotifier, err := mon.notifier.RegisterBlockEpochNtfn(nil)
+
+		if err != nil {
+			startErr = err
+			return
+		}
+
+		select {
+		case block := <-blockEventNotifier.Epochs:
+			mon.setBestBlockHeight(uint32(block.Height))
+		case <-mon.quit:
+			startErr = errors.New("monitor quit before finishing start")
+			return
+		}
+
+		mon.webhooks.start()
+
+		mon.wg.Add(2)
+		go mon.handleNewBlocks(blockEventNotifier)
+		go mon.checkBabylonStatusLoop()
+
+		for _, tx := range mon.txs {
+			confEvent, err := mon.notifier.RegisterConfirmationsNtfn(
+				&tx.cfg.StakingTxHash,
+				tx.pkScript,
+				1,
+				mon.config.MonitorConfig.StartHeight,
+				notifier.WithIncludeBlock(),
+			)
+
+			if err != nil {
+				startErr = err
+				return
+			}
+
+			mon.wg.Add(1)
+			go mon.waitForConfirmation(tx.cfg.StakingTxHash, confEvent)
+		}
+
+		mon.updateMetrics()
+
+		mon.logger.WithFields(logrus.Fields{
+			"monitoredTxs": len(mon.txs),
+		}).Info("Monitor started")
+	})
+
+	return startErr
+}
+
+// Metrics returns metrics of the monitor, shared with the rpc service
+func (mon *Monitor) Metrics() *metrics.MonitorMetrics {
+	return mon.m
+}
+
+func (mon *Monitor) Stop() error {
+	var stopErr error
+	mon.stopOnce.Do(func() {
+		mon.logger.Infof("Stopping Monitor")
+
+		close(mon.quit)
+		mon.wg.Wait()
+		mon.webhooks.stop()
+
+		if err := mon.notifier.Stop(); err != nil {
+			stopErr = err
+		}
+	})
+	return stopErr
+}
+
+func (mon *Monitor) setBestBlockHeight(height uint32) {
+	mon.mu.Lock()
+	mon.bestBlockHeight = height
+	mon.mu.Unlock()
+	mon.m.CurrentBtcBlockHeight.Set(float64(height))
+}
+
+func (mon *Monitor) handleNewBlocks(blockNotifier *notifier.BlockEpochEvent) {
+	defer mon.wg.Done()
+	defer blockNotifier.Cancel()
+	for {
+		select {
+		case block, ok := <-blockNotifier.Epochs:
+			if !ok {
+				return
+			}
+			mon.setBestBlockHeight(uint32(block.Height))
+		case <-mon.quit:
+			return
+		}
+	}
+}
+
+// updateTx applies update to monitored transaction and sends webhook event with
+// resulting state. Update function returns false if nothing changed.
+func (mon *Monitor) updateTx(txHash chainhash.Hash, evType EventType, update func(tx *monitoredTx) bool) {
+	mon.mu.Lock()
+	tx, found := mon.txs[txHash]
+
+	if !found || !update(tx) {
+		mon.mu.Unlock()
+		return
+	}
+
+	summary := tx.summary(mon.bestBlockHeight)
+	mon.mu.Unlock()
+
+	mon.logger.WithFields(logrus.Fields{
+		"event":         evType,
+		"stakingTxHash": txHash,
+		"babylonStatus": summary.BabylonStatus,
+	}).Info("Monitored staking transaction changed")
+
+	mon.updateMetrics()
+
+	mon.webhooks.notify(&Event{
+		Type:          evType,
+		StakingTxHash: txHash.String(),
+		Timestamp:     time.Now().Unix(),
+		Transaction:   summary,
+	})
+}
+
+func (mon *Monitor) waitForConfirmation(txHash chainhash.Hash, ev *notifier.ConfirmationEvent) {
+	defer mon.wg.Done()
+	defer ev.Cancel()
+
+	spendRegistered := false
+
+	for {
+		select {
+		case conf := <-ev.Confirmed:
+			var stakingOutputIdx *uint32
+			var stakingValue btcutil.Amount
+			for i, out := range conf.Tx.TxOut {
+				if bytes.Equal(out.PkScript, mon.txs[txHash].pkScript) {
+					idx := uint32(i)
+					stakingOutputIdx = &idx
+					stakingValue = btcutil.Amount(out.Value)
+					break
+				}
+			}
+
+			if stakingOutputIdx == nil {
+				mon.logger.WithFields(logrus.Fields{
+					"stakingTxHash": txHash,
+				}).Error("Confirmed staking transaction does not have output to staking output address")
+				return
+			}
+
+			mon.updateTx(txHash, EventStakingTxConfirmed, func(tx *monitoredTx) bool {
+				tx.stakingOutputIdx = stakingOutputIdx
+				tx.stakingValue = stakingValue
+				tx.confirmationHeight = conf.BlockHeight
+				tx.confirmationBlock = conf.BlockHash
+				return true
+			})
+
+			// spend notification survives reorgs, so register it only once
+			if !spendRegistered {
+				spendEvent, err := mon.notifier.RegisterSpendNtfn(
+					wire.NewOutPoint(&txHash, *stakingOutputIdx),
+					mon.txs[txHash].pkScript,
+					conf.BlockHeight,
+				)
+
+				if err != nil {
+					mon.logger.WithFields(logrus.Fields{
+						"stakingTxHash": txHash,
+						"err":           err,
+					}).Error("Failed to register for staking output spend notifications")
+				} else {
+					spendRegistered = true
+					mon.wg.Add(1)
+					go mon.waitForSpend(txHash, spendEvent)
+				}
+			}
+		case <-ev.NegativeConf:
+			mon.updateTx(txHash, EventStakingTxReorged, func(tx *monitoredTx) bool {
+				tx.confirmationHeight = 0
+				tx.confirmationBlock = nil
+				return true
+			})
+		case <-mon.quit:
+			return
+		}
+	}
+}
+
+func (mon *Monitor) waitForSpend(txHash chainhash.Hash, ev *notifier.SpendEvent) {
+	defer mon.wg.Done()
+	defer ev.Cancel()
+
+	for {
+		select {
+		case spend, ok := <-ev.Spend:
+			if !ok {
+				return
+			}
+
+			mon.updateTx(txHash, EventStakingTxSpent, func(tx *monitoredTx) bool {
+				tx.spendTxHash = spend.SpenderTxHash
+				tx.spendHeight = uint32(spend.SpendingHeight)
+				tx.spentByUnbondingTx = tx.unbondingTxHash != nil &&
+					tx.unbondingTxHash.IsEqual(spend.SpenderTxHash)
+				return true
+			})
+		case <-ev.Reorg:
+			mon.updateTx(txHash, EventStakingTxReorged, func(tx *monitoredTx) bool {
+				tx.spendTxHash = nil
+				tx.spendHeight = 0
+				tx.spentByUnbondingTx = false
+				return true
+			})
+		case <-mon.quit:
+			return
+		}
+	}
+}
+
+func (mon *Monitor) checkBabylonStatusLoop() {
+	defer mon.wg.Done()
+
+	ticker := time.NewTicker(mon.config.MonitorConfig.BabylonPollInterval)
+	defer ticker.Stop()
+
+	mon.checkBabylonStatus()
+
+	for {
+		select {
+		case <-ticker.C:
+			mon.checkBabylonStatus()
+		case <-mon.quit:
+			return
+		}
+	}
+}
+
+func delegationStatus(di *cl.DelegationInfo, previous BabylonStatus) BabylonStatus {
+	if di.Active {
+		return BabylonStatusActive
+	}
+
+	if previous == BabylonStatusActive || previous == BabylonStatusInactive {
+		return BabylonStatusInactive
+	}
+
+	return BabylonStatusPending
+}
+
+// checkBabylonStatus queries statuses of all monitored delegations in parallel
+// by bounded number of workers
+func (mon *Monitor) checkBabylonStatus() {
+	utils.ParallelForEach(
+		mon.txHashes(),
+		int(mon.config.MonitorConfig.BabylonPollWorkers),
+		mon.quit,
+		mon.checkDelegationBabylonStatus,
+	)
+}
+
+func (mon *Monitor) checkDelegationBabylonStatus(txHash chainhash.Hash) {
+	di, err := mon.babylonClient.QueryDelegationInfo(&txHash)
+	checkTime := time.Now()
+
+	if err != nil && !errors.Is(err, cl.ErrDelegationNotFound) {
+		mon.logger.WithFields(logrus.Fields{
+			"stakingTxHash": txHash,
+			"err":           err,
+		}).Error("Failed to query delegation status on babylon")
+		return
+	}
+
+	mon.updateTx(txHash, EventBabylonStatusChanged, func(tx *monitoredTx) bool {
+		tx.lastBabylonCheck = checkTime
+
+		newStatus := BabylonStatusNotRegistered
+		var unbondingTxHash *chainhash.Hash
+
+		if di != nil {
+			newStatus = delegationStatus(di, tx.babylonStatus)
+
+			if di.UndelegationInfo != nil && di.UndelegationInfo.UnbondingTransaction != nil {
+				h := di.UndelegationInfo.UnbondingTransaction.TxHash()
+				unbondingTxHash = &h
+			}
+		}
+
+		changed := newStatus != tx.babylonStatus
+		tx.babylonStatus = newStatus
+		tx.unbondingTxHash = unbondingTxHash
+
+		return changed
+	})
+}
+
+func (mon *Monitor) txHashes() []chainhash.Hash {
+	mon.mu.Lock()
+	defer mon.mu.Unlock()
+
+	hashes := make([]chainhash.Hash, 0, len(mon.txs))
+	for h, tx := range mon.txs {
+		// nothing more can change on babylon for spent transactions with known
+		// babylon status
+		if tx.spent() && tx.babylonStatus != BabylonStatusUnknown {
+			continue
+		}
+		hashes = append(hashes, h)
+	}
+
+	return hashes
+}
+
+func (mon *Monitor) updateMetrics() {
+	mon.mu.Lock()
+	defer mon.mu.Unlock()
+
+	var confirmed, spent, active int
+	for _, tx := range mon.txs {
+		if tx.confirmed() {
+			confirmed++
+		}
+
+		if tx.spent() {
+			spent++
+		}
+
+		if tx.babylonStatus == BabylonStatusActive {
+			active++
+		}
+	}
+
+	mon.m.MonitoredTransactions.Set(float64(len(mon.txs)))
+	mon.m.ConfirmedTransactions.Set(float64(confirmed))
+	mon.m.SpentTransactions.Set(float64(spent))
+	mon.m.ActiveDelegations.Set(float64(active))
+}
+
+// BestBlockHeight returns height of the best known btc block
+func (mon *Monitor) BestBlockHeight() uint32 {
+	mon.mu.Lock()
+	defer mon.mu.Unlock()
+	return mon.bestBlockHeight
+}
+
+// Transactions returns current state of all monitored transactions ordered by
+// staking transaction hash
+func (mon *Monitor) Transactions() []*TransactionSummary {
+	mon.mu.Lock()
+	defer mon.mu.Unlock()
+
+	summaries := make([]*TransactionSummary, 0, len(mon.txs))
+	for _, tx := range mon.txs {
+		summaries = append(summaries, tx.summary(mon.bestBlockHeight))
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].StakingTxHash < summaries[j].StakingTxHash
+	})
+
+	return summaries
+}
+
+// Transaction returns current state of monitored transaction with given hash
+func (mon *Monitor) Transaction(txHash *chainhash.Hash) (*TransactionSummary, error) {
+	mon.mu.Lock()
+	defer mon.mu.Unlock()
+
+	tx, found := mon.txs[*txHash]
+
+	if !found {
+		return nil, fmt.Errorf("staking transaction %s is not monitored: %w", txHash, ErrTransactionNotMonitored)
+	}
+
+	return tx.summary(mon.bestBlockHeight), nil
+}
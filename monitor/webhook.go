@@ -0,0 +1,147 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/avast/retry-go/v4"
+	"github.com/babylonchain/btc-staker/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	webhookQueueSize     = 1000
+	webhookRetryAttempts = 3
+	webhookRetryDelay    = 2 * time.Second
+	webhookContentType   = "application/json"
+)
+
+type EventType string
+
+const (
+	EventStakingTxConfirmed   EventType = "staking_tx_confirmed"
+	EventStakingTxReorged     EventType = "staking_tx_reorged"
+	EventStakingTxSpent       EventType = "staking_tx_spent"
+	EventBabylonStatusChanged EventType = "babylon_status_changed"
+)
+
+// Event is json payload sent to configured webhooks
+type Event struct {
+	Type          EventType           `json:"type"`
+	StakingTxHash string              `json:"staking_tx_hash"`
+	Timestamp     int64               `json:"timestamp"`
+	Transaction   *TransactionSummary `json:"transaction"`
+}
+
+type webhookNotifier struct {
+	urls   []string
+	client *http.Client
+	logger *logrus.Logger
+	m      *metrics.MonitorMetrics
+	events chan *Event
+	wg     sync.WaitGroup
+	quit   chan struct{}
+}
+
+func newWebhookNotifier(
+	urls []string,
+	timeout time.Duration,
+	logger *logrus.Logger,
+	m *metrics.MonitorMetrics,
+) *webhookNotifier {
+	return &webhookNotifier{
+		urls:   urls,
+		client: &http.Client{Timeout: timeout},
+		logger: logger,
+		m:      m,
+		events: make(chan *Event, webhookQueueSize),
+		quit:   make(chan struct{}),
+	}
+}
+
+func (w *webhookNotifier) start() {
+	w.wg.Add(1)
+	go w.sendLoop()
+}
+
+func (w *webhookNotifier) stop() {
+	close(w.quit)
+	w.wg.Wait()
+}
+
+// notify queues event for delivery. It never blocks, if queue is full event is
+// dropped so that slow webhook receiver does not stall monitoring.
+func (w *webhookNotifier) notify(ev *Event) {
+	if len(w.urls) == 0 {
+		return
+	}
+
+	select {
+	case w.events <- ev:
+	default:
+		w.m.FailedWebhookNotification.Inc()
+		w.logger.WithFields(logrus.Fields{
+			"type":          ev.Type,
+			"stakingTxHash": ev.StakingTxHash,
+		}).Error("Webhook queue is full. Dropping event")
+	}
+}
+
+func (w *webhookNotifier) sendLoop() {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case ev := <-w.events:
+			payload, err := json.Marshal(ev)
+
+			if err != nil {
+				w.logger.WithFields(logrus.Fields{
+					"type": ev.Type,
+					"err":  err,
+				}).Error("Failed to serialize webhook event")
+				continue
+			}
+
+			for _, url := range w.urls {
+				if err := w.send(url, payload); err != nil {
+					w.m.FailedWebhookNotification.Inc()
+					w.logger.WithFields(logrus.Fields{
+						"url":           url,
+						"type":          ev.Type,
+						"stakingTxHash": ev.StakingTxHash,
+						"err":           err,
+					}).Error("Failed to deliver webhook event")
+				}
+			}
+		case <-w.quit:
+			return
+		}
+	}
+}
+
+func (w *webhookNotifier) send(url string, payload []byte) error {
+	return retry.Do(func() error {
+		resp, err := w.client.Post(url, webhookContentType, bytes.NewReader(payload))
+
+		if err != nil {
+			return err
+		}
+
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		}
+
+		return nil
+	},
+		retry.Attempts(webhookRetryAttempts),
+		retry.Delay(webhookRetryDelay),
+		retry.LastErrorOnly(true),
+	)
+}
@@ -0,0 +1,256 @@
+// Package opreturn parses and builds OP_RETURN outputs which identify phase-1
+// staking transactions. Every output starts with magic bytes and version byte,
+// layout of the rest depends on the version. Versions are kept in a registry, so
+// outputs of versions unknown to this build are recognized and reported instead
+// of being rejected as malformed.
+package opreturn
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	// MagicBytesLen is length of magic bytes (tag) of the output
+	MagicBytesLen = 4
+
+	// headerLen is length of magic bytes and version byte
+	headerLen = MagicBytesLen + 1
+)
+
+var (
+	ErrNotStakingOpReturn    = errors.New("output is not staking op_return output")
+	ErrUnsupportedVersion    = errors.New("unsupported op_return version")
+	ErrInvalidPayload        = errors.New("invalid op_return payload")
+	ErrOpReturnNotFound      = errors.New("staking op_return output not found")
+	ErrMultipleOpReturns     = errors.New("transaction has more than one staking op_return output")
+	ErrVersionRegistered     = errors.New("op_return version is already registered")
+	ErrInvalidMagicBytes     = fmt.Errorf("magic bytes must be %d bytes long", MagicBytesLen)
+	ErrStakingOutputNotFound = errors.New("staking output not found")
+)
+
+// Data is content of staking op_return output. Fields other than MagicBytes,
+// Version and Payload are set only if version is supported.
+type Data struct {
+	MagicBytes []byte
+	Version    byte
+	// Supported is false if version is not registered, in which case only raw
+	// payload is available
+	Supported          bool
+	StakerPk           *btcec.PublicKey
+	FinalityProviderPk *btcec.PublicKey
+	StakingTime        uint16
+	// bytes after version byte
+	Payload []byte
+}
+
+// Version is layout of the output payload of one version
+type Version struct {
+	Number byte
+	// Decode parses payload, it is called with payload of any length
+	Decode func(payload []byte, d *Data) error
+	// Encode serializes fields of d into payload
+	Encode func(d *Data) ([]byte, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[byte]*Version{}
+)
+
+// Register adds version to the registry. It is meant to be called from init
+// functions of packages implementing new versions.
+func Register(v *Version) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, found := registry[v.Number]; found {
+		return fmt.Errorf("version %d: %w", v.Number, ErrVersionRegistered)
+	}
+
+	registry[v.Number] = v
+	return nil
+}
+
+// Lookup returns registered version, false if version is not supported
+func Lookup(number byte) (*Version, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	v, found := registry[number]
+	return v, found
+}
+
+// SupportedVersions returns numbers of all registered versions in ascending order
+func SupportedVersions() []byte {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	versions := make([]byte, 0, len(registry))
+	for n := range registry {
+		versions = append(versions, n)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i] < versions[j]
+	})
+
+	return versions
+}
+
+// pushedData returns data pushed by OP_RETURN script with single push
+func pushedData(pkScript []byte) ([]byte, bool) {
+	if len(pkScript) == 0 || pkScript[0] != txscript.OP_RETURN {
+		return nil, false
+	}
+
+	tokenizer := txscript.MakeScriptTokenizer(0, pkScript[1:])
+
+	if !tokenizer.Next() || tokenizer.Data() == nil {
+		return nil, false
+	}
+
+	data := tokenizer.Data()
+
+	if tokenizer.Next() || tokenizer.Err() != nil {
+		return nil, false
+	}
+
+	return data, true
+}
+
+// decodeHeader parses magic bytes and version of the output, payload is not
+// parsed
+func decodeHeader(out *wire.TxOut) (*Data, error) {
+	data, ok := pushedData(out.PkScript)
+
+	if !ok || len(data) < headerLen {
+		return nil, ErrNotStakingOpReturn
+	}
+
+	return &Data{
+		MagicBytes: data[:MagicBytesLen],
+		Version:    data[MagicBytesLen],
+		Payload:    data[headerLen:],
+	}, nil
+}
+
+// decodePayload parses payload of the output if its version is supported
+func decodePayload(d *Data) error {
+	v, found := Lookup(d.Version)
+
+	if !found {
+		return nil
+	}
+
+	if err := v.Decode(d.Payload, d); err != nil {
+		return fmt.Errorf("version %d: %w", d.Version, err)
+	}
+
+	d.Supported = true
+	return nil
+}
+
+// Decode parses staking op_return output. Outputs of unsupported versions are
+// returned with Supported set to false and no error.
+func Decode(out *wire.TxOut) (*Data, error) {
+	d, err := decodeHeader(out)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodePayload(d); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func unsupportedVersionError(number byte) error {
+	return fmt.Errorf("version %d, supported versions %v: %w", number, SupportedVersions(), ErrUnsupportedVersion)
+}
+
+// Build creates op_return output of d.Version from fields of d
+func Build(d *Data) (*wire.TxOut, error) {
+	if len(d.MagicBytes) != MagicBytesLen {
+		return nil, ErrInvalidMagicBytes
+	}
+
+	v, found := Lookup(d.Version)
+
+	if !found {
+		return nil, unsupportedVersionError(d.Version)
+	}
+
+	payload, err := v.Encode(d)
+
+	if err != nil {
+		return nil, fmt.Errorf("version %d: %w", d.Version, err)
+	}
+
+	data := make([]byte, 0, headerLen+len(payload))
+	data = append(data, d.MagicBytes...)
+	data = append(data, d.Version)
+	data = append(data, payload...)
+
+	pkScript, err := txscript.NullDataScript(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return wire.NewTxOut(0, pkScript), nil
+}
+
+// FindInTx returns index and content of the only op_return output of tx tagged
+// with magicBytes. Outputs of unsupported versions are returned with Supported
+// set to false.
+//
+// If magicBytes is nil, output with any magic bytes is accepted, but op_return
+// outputs with payload not matching their version are skipped, as they most
+// probably are not staking outputs.
+func FindInTx(tx *wire.MsgTx, magicBytes []byte) (int, *Data, error) {
+	foundIdx := -1
+	var found *Data
+
+	for i, out := range tx.TxOut {
+		d, err := decodeHeader(out)
+
+		if err != nil {
+			continue
+		}
+
+		if magicBytes != nil && !bytes.Equal(d.MagicBytes, magicBytes) {
+			continue
+		}
+
+		if err := decodePayload(d); err != nil {
+			if magicBytes == nil {
+				continue
+			}
+
+			return -1, nil, fmt.Errorf("output %d: %w", i, err)
+		}
+
+		if found != nil {
+			return -1, nil, ErrMultipleOpReturns
+		}
+
+		foundIdx = i
+		found = d
+	}
+
+	if found == nil {
+		return -1, nil, ErrOpReturnNotFound
+	}
+
+	return foundIdx, found, nil
+}
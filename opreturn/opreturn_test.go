@@ -0,0 +1,237 @@
+package opreturn
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testMagicBytes  = []byte("bbt4")
+	otherMagicBytes = []byte("bbn1")
+)
+
+func genTestData(t *testing.T, version byte) *Data {
+	stakerKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	fpKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	return &Data{
+		MagicBytes:         testMagicBytes,
+		Version:            version,
+		StakerPk:           stakerKey.PubKey(),
+		FinalityProviderPk: fpKey.PubKey(),
+		StakingTime:        64000,
+	}
+}
+
+// rawOpReturn returns op_return output pushing magic bytes, version and payload
+// without any validation
+func rawOpReturn(t *testing.T, magicBytes []byte, version byte, payload []byte) *wire.TxOut {
+	data := append(append(append([]byte{}, magicBytes...), version), payload...)
+
+	pkScript, err := txscript.NullDataScript(data)
+	require.NoError(t, err)
+
+	return wire.NewTxOut(0, pkScript)
+}
+
+func requireSameKey(t *testing.T, expected, actual *btcec.PublicKey) {
+	require.NotNil(t, actual)
+	require.Equal(t, schnorr.SerializePubKey(expected), schnorr.SerializePubKey(actual))
+}
+
+func TestRoundTripRegisteredVersions(t *testing.T) {
+	versions := SupportedVersions()
+	require.Contains(t, versions, V0)
+
+	for _, version := range versions {
+		d := genTestData(t, version)
+
+		out, err := Build(d)
+		require.NoError(t, err, "version %d", version)
+		require.Zero(t, out.Value)
+
+		decoded, err := Decode(out)
+		require.NoError(t, err, "version %d", version)
+		require.True(t, decoded.Supported)
+		require.Equal(t, testMagicBytes, decoded.MagicBytes)
+		require.Equal(t, version, decoded.Version)
+		requireSameKey(t, d.StakerPk, decoded.StakerPk)
+		requireSameKey(t, d.FinalityProviderPk, decoded.FinalityProviderPk)
+		require.Equal(t, d.StakingTime, decoded.StakingTime)
+
+		// output built from decoded data is the same
+		rebuilt, err := Build(decoded)
+		require.NoError(t, err)
+		require.Equal(t, out.PkScript, rebuilt.PkScript)
+
+		// output is found among other outputs, including op_return output
+		// which only looks like staking output
+		tx := wire.NewMsgTx(2)
+		tx.AddTxOut(wire.NewTxOut(10000, []byte{txscript.OP_TRUE}))
+		tx.AddTxOut(rawOpReturn(t, otherMagicBytes, version, []byte("not a staking output")))
+		tx.AddTxOut(out)
+
+		for _, magicBytes := range [][]byte{testMagicBytes, nil} {
+			idx, found, err := FindInTx(tx, magicBytes)
+			require.NoError(t, err, "version %d", version)
+			require.Equal(t, 2, idx)
+			require.True(t, found.Supported)
+			requireSameKey(t, d.StakerPk, found.StakerPk)
+			require.Equal(t, d.StakingTime, found.StakingTime)
+		}
+	}
+}
+
+func TestRegisterDuplicateVersion(t *testing.T) {
+	for _, version := range SupportedVersions() {
+		err := Register(&Version{Number: version, Decode: decodeV0, Encode: encodeV0})
+		require.ErrorIs(t, err, ErrVersionRegistered)
+	}
+}
+
+func TestUnknownVersion(t *testing.T) {
+	const unknownVersion = byte(0xff)
+
+	_, found := Lookup(unknownVersion)
+	require.False(t, found)
+
+	d := genTestData(t, unknownVersion)
+	_, err := Build(d)
+	require.ErrorIs(t, err, ErrUnsupportedVersion)
+
+	// outputs of unknown versions are reported with raw payload, whatever its
+	// length is
+	payload := bytes.Repeat([]byte{0xab}, 60)
+	out := rawOpReturn(t, testMagicBytes, unknownVersion, payload)
+
+	decoded, err := Decode(out)
+	require.NoError(t, err)
+	require.False(t, decoded.Supported)
+	require.Equal(t, unknownVersion, decoded.Version)
+	require.Equal(t, payload, decoded.Payload)
+	require.Nil(t, decoded.StakerPk)
+	require.Nil(t, decoded.FinalityProviderPk)
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxOut(wire.NewTxOut(10000, []byte{txscript.OP_TRUE}))
+	tx.AddTxOut(out)
+
+	idx, foundData, err := FindInTx(tx, testMagicBytes)
+	require.NoError(t, err)
+	require.Equal(t, 1, idx)
+	require.False(t, foundData.Supported)
+	require.Equal(t, unknownVersion, foundData.Version)
+}
+
+func TestTruncatedPayload(t *testing.T) {
+	out, err := Build(genTestData(t, V0))
+	require.NoError(t, err)
+
+	full, ok := pushedData(out.PkScript)
+	require.True(t, ok)
+	payload := full[headerLen:]
+
+	for _, length := range []int{0, 1, len(payload) / 2, len(payload) - 1} {
+		truncated := rawOpReturn(t, testMagicBytes, V0, payload[:length])
+
+		_, err := Decode(truncated)
+		require.ErrorIs(t, err, ErrInvalidPayload, "payload length %d", length)
+
+		tx := wire.NewMsgTx(2)
+		tx.AddTxOut(truncated)
+
+		// output tagged with requested magic bytes must be valid
+		_, _, err = FindInTx(tx, testMagicBytes)
+		require.ErrorIs(t, err, ErrInvalidPayload, "payload length %d", length)
+
+		// without magic bytes malformed outputs are skipped
+		_, _, err = FindInTx(tx, nil)
+		require.ErrorIs(t, err, ErrOpReturnNotFound, "payload length %d", length)
+	}
+
+	// payload of wrong length is rejected even if it is longer
+	_, err = Decode(rawOpReturn(t, testMagicBytes, V0, append(payload, 0)))
+	require.ErrorIs(t, err, ErrInvalidPayload)
+
+	// outputs too short to carry magic bytes and version are not staking outputs
+	for _, data := range [][]byte{nil, testMagicBytes[:2], testMagicBytes} {
+		pkScript, err := txscript.NullDataScript(data)
+		require.NoError(t, err)
+
+		_, err = Decode(wire.NewTxOut(0, pkScript))
+		require.ErrorIs(t, err, ErrNotStakingOpReturn)
+	}
+}
+
+func TestWrongMagicBytes(t *testing.T) {
+	for _, magicBytes := range [][]byte{nil, testMagicBytes[:3], []byte("bbt4x")} {
+		d := genTestData(t, V0)
+		d.MagicBytes = magicBytes
+
+		_, err := Build(d)
+		require.ErrorIs(t, err, ErrInvalidMagicBytes)
+	}
+
+	d := genTestData(t, V0)
+	d.MagicBytes = otherMagicBytes
+	out, err := Build(d)
+	require.NoError(t, err)
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxOut(out)
+
+	_, _, err = FindInTx(tx, testMagicBytes)
+	require.ErrorIs(t, err, ErrOpReturnNotFound)
+
+	// output with other magic bytes is not taken into account even if it is
+	// malformed
+	tx.AddTxOut(rawOpReturn(t, otherMagicBytes, V0, []byte{1, 2, 3}))
+	_, _, err = FindInTx(tx, testMagicBytes)
+	require.ErrorIs(t, err, ErrOpReturnNotFound)
+
+	idx, found, err := FindInTx(tx, otherMagicBytes)
+	require.ErrorIs(t, err, ErrInvalidPayload)
+	require.Equal(t, -1, idx)
+	require.Nil(t, found)
+}
+
+func TestMultipleOpReturns(t *testing.T) {
+	first, err := Build(genTestData(t, V0))
+	require.NoError(t, err)
+
+	second, err := Build(genTestData(t, V0))
+	require.NoError(t, err)
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxOut(first)
+	tx.AddTxOut(second)
+
+	_, _, err = FindInTx(tx, testMagicBytes)
+	require.ErrorIs(t, err, ErrMultipleOpReturns)
+
+	_, _, err = FindInTx(tx, nil)
+	require.ErrorIs(t, err, ErrMultipleOpReturns)
+}
+
+func TestNonOpReturnOutputs(t *testing.T) {
+	for _, pkScript := range [][]byte{
+		nil,
+		{txscript.OP_TRUE},
+		// op_return with two pushes
+		{txscript.OP_RETURN, txscript.OP_DATA_1, 0x01, txscript.OP_DATA_1, 0x02},
+		// op_return without push
+		{txscript.OP_RETURN},
+	} {
+		_, err := Decode(wire.NewTxOut(0, pkScript))
+		require.ErrorIs(t, err, ErrNotStakingOpReturn, "script %x", pkScript)
+	}
+}
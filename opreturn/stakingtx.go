@@ -0,0 +1,139 @@
+package opreturn
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/babylonchain/btc-staker/scriptbuilder"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// ParsedStakingTx is phase-1 staking transaction with decoded op_return output
+type ParsedStakingTx struct {
+	StakingOutput     *wire.TxOut
+	StakingOutputIdx  int
+	OpReturnOutputIdx int
+	OpReturnData      *Data
+}
+
+func stakingPkScript(
+	builder scriptbuilder.StakingScriptBuilder,
+	d *Data,
+	covenantPks []*btcec.PublicKey,
+	covenantQuorum uint32,
+	amount btcutil.Amount,
+	net *chaincfg.Params,
+) ([]byte, error) {
+	scripts, err := builder.BuildStakingScripts(
+		d.StakerPk,
+		[]*btcec.PublicKey{d.FinalityProviderPk},
+		covenantPks,
+		covenantQuorum,
+		d.StakingTime,
+		amount,
+		net,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scripts.Output().PkScript, nil
+}
+
+// ParseStakingTx checks that tx is staking transaction tagged with magicBytes,
+// i.e it has exactly one op_return output of supported version and exactly one
+// output locked by staking script derived from op_return data and covenant
+// committee, built by builder.
+func ParseStakingTx(
+	builder scriptbuilder.StakingScriptBuilder,
+	tx *wire.MsgTx,
+	magicBytes []byte,
+	covenantPks []*btcec.PublicKey,
+	covenantQuorum uint32,
+	net *chaincfg.Params,
+) (*ParsedStakingTx, error) {
+	if len(magicBytes) != MagicBytesLen {
+		return nil, ErrInvalidMagicBytes
+	}
+
+	if len(tx.TxOut) < 2 {
+		return nil, fmt.Errorf("staking transaction must have at least 2 outputs")
+	}
+
+	opReturnIdx, d, err := FindInTx(tx, magicBytes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !d.Supported {
+		return nil, unsupportedVersionError(d.Version)
+	}
+
+	parsed := &ParsedStakingTx{
+		StakingOutputIdx:  -1,
+		OpReturnOutputIdx: opReturnIdx,
+		OpReturnData:      d,
+	}
+
+	for i, out := range tx.TxOut {
+		if i == opReturnIdx {
+			continue
+		}
+
+		pkScript, err := stakingPkScript(builder, d, covenantPks, covenantQuorum, btcutil.Amount(out.Value), net)
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot build staking script from op_return data: %w", err)
+		}
+
+		if !bytes.Equal(pkScript, out.PkScript) {
+			continue
+		}
+
+		if parsed.StakingOutput != nil {
+			return nil, fmt.Errorf("transaction has more than one staking output")
+		}
+
+		parsed.StakingOutput = out
+		parsed.StakingOutputIdx = i
+	}
+
+	if parsed.StakingOutput == nil {
+		return nil, ErrStakingOutputNotFound
+	}
+
+	return parsed, nil
+}
+
+// BuildStakingTx creates unfunded staking transaction with staking output and
+// op_return output of d.Version. Staking output script is built by builder.
+func BuildStakingTx(
+	builder scriptbuilder.StakingScriptBuilder,
+	d *Data,
+	covenantPks []*btcec.PublicKey,
+	covenantQuorum uint32,
+	amount btcutil.Amount,
+	net *chaincfg.Params,
+) (*wire.MsgTx, error) {
+	opReturnOutput, err := Build(d)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pkScript, err := stakingPkScript(builder, d, covenantPks, covenantQuorum, amount, net)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxOut(wire.NewTxOut(int64(amount), pkScript))
+	tx.AddTxOut(opReturnOutput)
+	return tx, nil
+}
@@ -0,0 +1,57 @@
+package opreturn
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+const (
+	// V0 is the first version of the output, used by phase-1 staking
+	V0 byte = 0
+
+	// x-only staker key, x-only finality provider key and big endian staking time
+	v0PayloadLen = schnorr.PubKeyBytesLen + schnorr.PubKeyBytesLen + 2
+)
+
+func decodeV0(payload []byte, d *Data) error {
+	if len(payload) != v0PayloadLen {
+		return fmt.Errorf("payload length %d, expected %d: %w", len(payload), v0PayloadLen, ErrInvalidPayload)
+	}
+
+	stakerPk, err := schnorr.ParsePubKey(payload[:schnorr.PubKeyBytesLen])
+
+	if err != nil {
+		return fmt.Errorf("invalid staker key %s: %w", err, ErrInvalidPayload)
+	}
+
+	fpPk, err := schnorr.ParsePubKey(payload[schnorr.PubKeyBytesLen : 2*schnorr.PubKeyBytesLen])
+
+	if err != nil {
+		return fmt.Errorf("invalid finality provider key %s: %w", err, ErrInvalidPayload)
+	}
+
+	d.StakerPk = stakerPk
+	d.FinalityProviderPk = fpPk
+	d.StakingTime = binary.BigEndian.Uint16(payload[2*schnorr.PubKeyBytesLen:])
+	return nil
+}
+
+func encodeV0(d *Data) ([]byte, error) {
+	if d.StakerPk == nil || d.FinalityProviderPk == nil {
+		return nil, fmt.Errorf("staker and finality provider keys are required: %w", ErrInvalidPayload)
+	}
+
+	payload := make([]byte, 0, v0PayloadLen)
+	payload = append(payload, schnorr.SerializePubKey(d.StakerPk)...)
+	payload = append(payload, schnorr.SerializePubKey(d.FinalityProviderPk)...)
+	payload = binary.BigEndian.AppendUint16(payload, d.StakingTime)
+	return payload, nil
+}
+
+func init() {
+	if err := Register(&Version{Number: V0, Decode: decodeV0, Encode: encodeV0}); err != nil {
+		panic(err)
+	}
+}
@@ -6,7 +6,7 @@ set -e
 function generate() {
   echo "Generating staker protos"
 
-  PROTOS="transaction.proto"
+  PROTOS="transaction.proto events.proto"
 
   # For each of the sub-servers, we then generate their protos, but a restricted
   # set as they don't yet require REST proxies, or swagger docs.
@@ -0,0 +1,91 @@
+package scriptbuilder
+
+import (
+	staking "github.com/babylonchain/babylon/btcstaking"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+)
+
+type babylonStakingScripts struct {
+	*staking.StakingInfo
+}
+
+func (s babylonStakingScripts) Output() *wire.TxOut {
+	return s.StakingOutput
+}
+
+type babylonUnbondingScripts struct {
+	*staking.UnbondingInfo
+}
+
+func (s babylonUnbondingScripts) Output() *wire.TxOut {
+	return s.UnbondingOutput
+}
+
+// babylonBuilder builds scripts in the layout defined by Babylon btcstaking
+// module
+type babylonBuilder struct{}
+
+func (babylonBuilder) Name() string {
+	return DefaultBuilderName
+}
+
+func (babylonBuilder) BuildStakingScripts(
+	stakerKey *btcec.PublicKey,
+	fpKeys []*btcec.PublicKey,
+	covenantKeys []*btcec.PublicKey,
+	covenantQuorum uint32,
+	stakingTime uint16,
+	stakingAmount btcutil.Amount,
+	net *chaincfg.Params,
+) (StakingScripts, error) {
+	info, err := staking.BuildStakingInfo(
+		stakerKey,
+		fpKeys,
+		covenantKeys,
+		covenantQuorum,
+		stakingTime,
+		stakingAmount,
+		net,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return babylonStakingScripts{info}, nil
+}
+
+func (babylonBuilder) BuildUnbondingScripts(
+	stakerKey *btcec.PublicKey,
+	fpKeys []*btcec.PublicKey,
+	covenantKeys []*btcec.PublicKey,
+	covenantQuorum uint32,
+	unbondingTime uint16,
+	unbondingAmount btcutil.Amount,
+	net *chaincfg.Params,
+) (UnbondingScripts, error) {
+	info, err := staking.BuildUnbondingInfo(
+		stakerKey,
+		fpKeys,
+		covenantKeys,
+		covenantQuorum,
+		unbondingTime,
+		unbondingAmount,
+		net,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return babylonUnbondingScripts{info}, nil
+}
+
+func init() {
+	if err := Register(babylonBuilder{}); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,134 @@
+// Package scriptbuilder defines how staking and unbonding outputs are built.
+// Daemon and cli build scripts only through StakingScriptBuilder selected by
+// name, so deployments using different script layouts (e.g different ordering
+// of leaves or additional spend paths) can register their own builder instead
+// of forking the staker. Builders are registered in init functions of packages
+// linked into the binary, in the same way as database/sql drivers.
+package scriptbuilder
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	staking "github.com/babylonchain/babylon/btcstaking"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// DefaultBuilderName is name of the builder producing scripts accepted by
+// Babylon
+const DefaultBuilderName = "babylon"
+
+var (
+	ErrBuilderRegistered = errors.New("script builder is already registered")
+	ErrUnknownBuilder    = errors.New("unknown script builder")
+)
+
+// StakingScripts is staking output together with its spend paths
+type StakingScripts interface {
+	Output() *wire.TxOut
+	TimeLockPathSpendInfo() (*staking.SpendInfo, error)
+	UnbondingPathSpendInfo() (*staking.SpendInfo, error)
+	SlashingPathSpendInfo() (*staking.SpendInfo, error)
+}
+
+// UnbondingScripts is unbonding output together with its spend paths
+type UnbondingScripts interface {
+	Output() *wire.TxOut
+	TimeLockPathSpendInfo() (*staking.SpendInfo, error)
+	SlashingPathSpendInfo() (*staking.SpendInfo, error)
+}
+
+// StakingScriptBuilder builds outputs of staking and unbonding transactions.
+// Slashing transactions are still built and checked by Babylon rules, so
+// slashing path of custom layouts must be compatible with them.
+type StakingScriptBuilder interface {
+	// Name is unique name under which builder is registered
+	Name() string
+
+	BuildStakingScripts(
+		stakerKey *btcec.PublicKey,
+		fpKeys []*btcec.PublicKey,
+		covenantKeys []*btcec.PublicKey,
+		covenantQuorum uint32,
+		stakingTime uint16,
+		stakingAmount btcutil.Amount,
+		net *chaincfg.Params,
+	) (StakingScripts, error)
+
+	BuildUnbondingScripts(
+		stakerKey *btcec.PublicKey,
+		fpKeys []*btcec.PublicKey,
+		covenantKeys []*btcec.PublicKey,
+		covenantQuorum uint32,
+		unbondingTime uint16,
+		unbondingAmount btcutil.Amount,
+		net *chaincfg.Params,
+	) (UnbondingScripts, error)
+}
+
+var (
+	buildersMu sync.RWMutex
+	builders   = make(map[string]StakingScriptBuilder)
+)
+
+// Register makes builder available under its name
+func Register(b StakingScriptBuilder) error {
+	buildersMu.Lock()
+	defer buildersMu.Unlock()
+
+	if _, found := builders[b.Name()]; found {
+		return fmt.Errorf("%s: %w", b.Name(), ErrBuilderRegistered)
+	}
+
+	builders[b.Name()] = b
+	return nil
+}
+
+// Get returns builder registered under given name
+func Get(name string) (StakingScriptBuilder, error) {
+	buildersMu.RLock()
+	defer buildersMu.RUnlock()
+
+	b, found := builders[name]
+
+	if !found {
+		return nil, fmt.Errorf("%s: %w, registered builders: %v", name, ErrUnknownBuilder, namesLocked())
+	}
+
+	return b, nil
+}
+
+// Default returns builder producing scripts accepted by Babylon
+func Default() StakingScriptBuilder {
+	b, err := Get(DefaultBuilderName)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+func namesLocked() []string {
+	names := make([]string, 0, len(builders))
+
+	for name := range builders {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Names returns sorted names of registered builders
+func Names() []string {
+	buildersMu.RLock()
+	defer buildersMu.RUnlock()
+
+	return namesLocked()
+}
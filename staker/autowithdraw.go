@@ -0,0 +1,206 @@
+package staker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/sirupsen/logrus"
+)
+
+const autoWithdrawNote = "automatic withdrawal"
+
+// SetAutoWithdraw enables or disables automatic withdrawal of the delegation.
+// Enabled withdrawal is scheduled at maturity height as soon as timelocked
+// output of the delegation is confirmed. Disabling it cancels withdrawal which
+// was not executed yet.
+func (app *StakerApp) SetAutoWithdraw(stakingTxHash *chainhash.Hash, enabled bool) error {
+	tx, err := app.txTracker.GetTransaction(stakingTxHash)
+
+	if err != nil {
+		return err
+	}
+
+	if enabled && tx.WatchOnly() {
+		return fmt.Errorf("cannot withdraw funds of watched transaction: %w", ErrInvalidTransactionState)
+	}
+
+	if err := app.txTracker.SetTxAutoWithdraw(stakingTxHash, enabled); err != nil {
+		return err
+	}
+
+	if !enabled {
+		return app.cancelAutoWithdraw(stakingTxHash)
+	}
+
+	return app.scheduleAutoWithdraw(stakingTxHash)
+}
+
+// autoWithdrawOperation returns withdrawal scheduled for the delegation, or nil
+// if there is none
+func (app *StakerApp) autoWithdrawOperation(stakingTxHash *chainhash.Hash) (*stakerdb.ScheduledOperation, error) {
+	scheduled, err := app.scheduledOps.store.Operations()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range scheduled {
+		o := &scheduled[i]
+
+		if o.Operation == proto.ScheduledOperationType_SCHEDULED_WITHDRAW && o.StakingTxHash == *stakingTxHash {
+			return o, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// scheduleAutoWithdraw schedules withdrawal of delegation with automatic
+// withdrawal at height at which its timelocked output matures. It is called
+// whenever staking or unbonding output is confirmed, withdrawal which is already
+// scheduled is moved to the new maturity height. Withdrawals scheduled by the
+// operator are left untouched.
+func (app *StakerApp) scheduleAutoWithdraw(stakingTxHash *chainhash.Hash) error {
+	app.scheduledOps.autoWithdrawMu.Lock()
+	defer app.scheduledOps.autoWithdrawMu.Unlock()
+
+	tx, err := app.txTracker.GetTransaction(stakingTxHash)
+
+	if err != nil {
+		return err
+	}
+
+	if !tx.AutoWithdraw || tx.WatchOnly() {
+		return nil
+	}
+
+	currentHeight := app.currentBestBlockHeight.Load()
+	blocksToMaturity, confirmed := tx.BlocksToMaturity(currentHeight)
+
+	if !confirmed {
+		// scheduled once timelocked output is confirmed
+		return nil
+	}
+
+	// withdrawal can be sent once best block reaches this height
+	maturityHeight := currentHeight + blocksToMaturity
+
+	existing, err := app.autoWithdrawOperation(stakingTxHash)
+
+	if err != nil {
+		return err
+	}
+
+	logger := app.logger.WithFields(logrus.Fields{
+		"stakingTxHash":  stakingTxHash,
+		"maturityHeight": maturityHeight,
+	})
+
+	switch {
+	case existing != nil && !existing.Automatic:
+		logger.WithField("id", existing.Id).Info("Withdrawal already scheduled by operator. Skipping automatic withdrawal")
+		return nil
+	case existing != nil && existing.ExecuteAtHeight == maturityHeight:
+		return nil
+	case existing != nil:
+		existing.ExecuteAtHeight = maturityHeight
+
+		if err := app.scheduledOps.store.UpdateOperation(existing); err != nil {
+			return err
+		}
+
+		logger = logger.WithField("id", existing.Id)
+	default:
+		operation := &stakerdb.ScheduledOperation{
+			Operation:       proto.ScheduledOperationType_SCHEDULED_WITHDRAW,
+			StakingTxHash:   *stakingTxHash,
+			ExecuteAtHeight: maturityHeight,
+			Note:            autoWithdrawNote,
+			CreatedAt:       time.Now(),
+			Automatic:       true,
+		}
+
+		if err := app.scheduledOps.store.AddOperation(operation); err != nil {
+			return err
+		}
+
+		logger = logger.WithField("id", operation.Id)
+	}
+
+	logger.Info("Scheduled automatic withdrawal")
+
+	app.publishOperatorLifecycleEvent(LifecycleEventAutoWithdrawScheduled, *stakingTxHash, tx)
+
+	return nil
+}
+
+// maybeScheduleAutoWithdraw schedules automatic withdrawal after timelocked
+// output of the delegation was confirmed. Failure does not stop processing of
+// the delegation, withdrawal can still be requested manually.
+func (app *StakerApp) maybeScheduleAutoWithdraw(stakingTxHash *chainhash.Hash) {
+	if err := app.scheduleAutoWithdraw(stakingTxHash); err != nil {
+		app.logger.WithFields(logrus.Fields{
+			"stakingTxHash": stakingTxHash,
+			"err":           err,
+		}).Error("Failed to schedule automatic withdrawal")
+	}
+}
+
+// cancelAutoWithdraw removes automatic withdrawal of the delegation which was
+// not executed yet
+func (app *StakerApp) cancelAutoWithdraw(stakingTxHash *chainhash.Hash) error {
+	app.scheduledOps.autoWithdrawMu.Lock()
+	defer app.scheduledOps.autoWithdrawMu.Unlock()
+
+	existing, err := app.autoWithdrawOperation(stakingTxHash)
+
+	if err != nil {
+		return err
+	}
+
+	if existing == nil || !existing.Automatic {
+		return nil
+	}
+
+	if err := app.scheduledOps.store.DeleteOperation(existing.Id); err != nil {
+		return err
+	}
+
+	app.logger.WithFields(logrus.Fields{
+		"id":            existing.Id,
+		"stakingTxHash": stakingTxHash,
+	}).Info("Cancelled automatic withdrawal")
+
+	if tx, err := app.txTracker.GetTransaction(stakingTxHash); err == nil {
+		app.publishOperatorLifecycleEvent(LifecycleEventAutoWithdrawCancelled, *stakingTxHash, tx)
+	}
+
+	return nil
+}
+
+// autoWithdrawFeeAllowed returns false if estimated fee rate is above maximum fee
+// rate of automatic withdrawals
+func (app *StakerApp) autoWithdrawFeeAllowed(logger *logrus.Entry) bool {
+	maxFeeRate := app.config.AutoWithdrawConfig.MaxFeeRate
+
+	if maxFeeRate == 0 {
+		return true
+	}
+
+	// estimator returns fee per kvbyte, config is in sat/vbyte
+	currentFeeRate := uint64(app.feeEstimator.EstimateFeePerKb() / 1000)
+
+	if currentFeeRate <= maxFeeRate {
+		return true
+	}
+
+	logger.WithFields(logrus.Fields{
+		"feeRate":    currentFeeRate,
+		"maxFeeRate": maxFeeRate,
+	}).Debug("Postponing automatic withdrawal until fee rate drops")
+
+	return false
+}
@@ -0,0 +1,56 @@
+package staker
+
+import (
+	"errors"
+	"time"
+
+	cl "github.com/babylonchain/btc-staker/babylonclient"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	pv "github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/sirupsen/logrus"
+)
+
+// recordBabylonTxResponse stores response of babylon to transaction sent on
+// behalf of delegation, so that operators can find the transaction in babylon
+// explorers. Transactions which failed before inclusion in babylon block have
+// no response and are not recorded. Failure to store the response is only
+// logged, as the transaction was already sent.
+func (app *StakerApp) recordBabylonTxResponse(
+	stakingTxHash *chainhash.Hash,
+	msgType string,
+	resp *pv.RelayerTxResponse,
+	sendErr error,
+) {
+	if !app.config.StakerConfig.PersistBabylonTxResponses {
+		return
+	}
+
+	var txErr *cl.BabylonTxError
+	if errors.As(sendErr, &txErr) {
+		resp = txErr.Response
+	} else if sendErr != nil {
+		return
+	}
+
+	if resp == nil {
+		return
+	}
+
+	err := app.txTracker.AddTxBabylonResponse(stakingTxHash, &stakerdb.BabylonTxResponse{
+		MsgType:   msgType,
+		TxHash:    resp.TxHash,
+		Height:    resp.Height,
+		Codespace: resp.Codespace,
+		Code:      resp.Code,
+		Timestamp: time.Now(),
+	})
+
+	if err != nil {
+		app.logger.WithFields(logrus.Fields{
+			"stakingTxHash": stakingTxHash,
+			"babylonTxHash": resp.TxHash,
+			"err":           err,
+		}).Error("Failed to store babylon transaction response")
+	}
+}
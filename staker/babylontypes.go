@@ -3,9 +3,9 @@ package staker
 import (
 	"errors"
 	"fmt"
-	"time"
 
 	cl "github.com/babylonchain/btc-staker/babylonclient"
+	"github.com/babylonchain/btc-staker/proto"
 	"github.com/babylonchain/btc-staker/stakerdb"
 	"github.com/babylonchain/btc-staker/utils"
 	"github.com/btcsuite/btcd/btcec/v2"
@@ -39,7 +39,9 @@ func (app *StakerApp) buildOwnedDelegation(
 
 	slashingFee := app.getSlashingFee(externalData.babylonParams.MinSlashingTxFeeSat)
 
-	slashingTx, slashingTxSig, err := buildSlashingTxAndSig(slashingFee, externalData, storedTx, app.network)
+	unbondingTime, unbondingTxFeeRatePerKb := app.unbondingParamsOfDelegation(storedTx, externalData.babylonParams)
+
+	slashingTx, slashingTxSig, err := buildSlashingTxAndSig(slashingFee, unbondingTime, externalData, storedTx, app.scriptBuilder, app.network)
 	if err != nil {
 		// This is truly unexpected, most probably programming error we have
 		// valid and btc confirmed staking transacion, but for some reason we cannot
@@ -51,10 +53,6 @@ func (app *StakerApp) buildOwnedDelegation(
 		}).Fatalf("Failed to build delegation data for already confirmed staking transaction")
 	}
 
-	// TODO: Option to use custom fee rate, as estimator uses pretty big value for fee
-	// in case of estimation failure (25 sat/byte)
-	unbondingTxFeeRatePerKb := btcutil.Amount(app.feeEstimator.EstimateFeePerKb())
-
 	undelegationData, err := createUndelegationData(
 		storedTx,
 		externalData.stakerPrivKey,
@@ -62,10 +60,10 @@ func (app *StakerApp) buildOwnedDelegation(
 		externalData.babylonParams.CovenantQuruomThreshold,
 		externalData.babylonParams.SlashingAddress,
 		unbondingTxFeeRatePerKb,
-		// TODO: Possiblity to customize finalization time
-		uint16(externalData.babylonParams.MinUnbondingTime)+1,
+		unbondingTime,
 		app.getSlashingFee(externalData.babylonParams.MinSlashingTxFeeSat),
 		externalData.babylonParams.SlashingRate,
+		app.scriptBuilder,
 		app.network,
 	)
 
@@ -139,91 +137,98 @@ func (app *StakerApp) buildDelegation(
 	}
 }
 
-// TODO for now we launch this handler indefinitly. At some point we may introduce
+// TODO for now we poll delegations indefinitly. At some point we may introduce
 // timeout, and if signatures are not find in this timeout, then we may submit
 // evidence that covenant members are censoring our staking transactions
-func (app *StakerApp) checkForUnbondingTxSignaturesOnBabylon(stakingTxHash *chainhash.Hash) {
-	checkSigTicker := time.NewTicker(app.config.StakerConfig.UnbondingTxCheckInterval)
-	defer checkSigTicker.Stop()
-	defer app.wg.Done()
-
-	for {
-		select {
-		case <-checkSigTicker.C:
-			di, err := app.babylonClient.QueryDelegationInfo(stakingTxHash)
-
-			if err != nil {
-				if errors.Is(err, cl.ErrDelegationNotFound) {
-					// As we only start this handler when we are sure delegation is already on babylon
-					// this can only that:
-					// - either we are connected to wrong babylon network
-					// - or babylon node lost data and is still syncing
-					app.logger.WithFields(logrus.Fields{
-						"stakingTxHash": stakingTxHash,
-					}).Error("Delegation for given staking tx hash does not exsist on babylon. Check your babylon node.")
-				} else {
-					app.logger.WithFields(logrus.Fields{
-						"stakingTxHash": stakingTxHash,
-						"err":           err,
-					}).Error("Error getting delegation info from babylon")
-				}
-
-				continue
-			}
-
-			if di.UndelegationInfo == nil {
-				// As we only start this handler when we are sure delegation received unbonding request
-				// this can only that:
-				// - babylon node lost data and is still syncing, and not processed unbonding request yet
-				app.logger.WithFields(logrus.Fields{
-					"stakingTxHash": stakingTxHash,
-				}).Error("Delegation for given staking tx hash is not unbonding yet.")
-				continue
-			}
-
-			params, err := app.babylonClient.Params()
-
-			if err != nil {
-				app.logger.WithFields(logrus.Fields{
-					"stakingTxHash": stakingTxHash,
-					"err":           err,
-				}).Error("Error getting babylon params")
-				// Failed to get params, we cannont do anything, most probably connection error to babylon node
-				// we will try again in next iteration
-				continue
-			}
-
-			// we have enough signatures to submit unbonding tx this means that delegation is active
-			if len(di.UndelegationInfo.CovenantUnbondingSignatures) >= int(params.CovenantQuruomThreshold) {
-				app.logger.WithFields(logrus.Fields{
-					"stakingTxHash": stakingTxHash,
-					"numSignatures": len(di.UndelegationInfo.CovenantUnbondingSignatures),
-				}).Debug("Received enough covenant unbonding signatures on babylon")
-
-				req := &unbondingTxSignaturesConfirmedOnBabylonEvent{
-					stakingTxHash:               *stakingTxHash,
-					covenantUnbondingSignatures: di.UndelegationInfo.CovenantUnbondingSignatures,
-				}
-
-				utils.PushOrQuit[*unbondingTxSignaturesConfirmedOnBabylonEvent](
-					app.unbondingTxSignaturesConfirmedOnBabylonEvChan,
-					req,
-					app.quit,
-				)
-
-				return
-			} else {
-				app.logger.WithFields(logrus.Fields{
-					"stakingTxHash": stakingTxHash,
-					"numSignatures": len(di.UndelegationInfo.CovenantUnbondingSignatures),
-					"required":      params.CovenantQuruomThreshold,
-				}).Debug("Received not enough covenant unbonding signatures on babylon")
-			}
-
-		case <-app.quit:
-			return
+func (app *StakerApp) prepareUnbondingTxSignaturesCheck() (delegationCheck, bool) {
+	params, err := app.babylonClient.Params()
+
+	if err != nil {
+		app.logger.WithFields(logrus.Fields{
+			"err": err,
+		}).Error("Error getting babylon params")
+		// Failed to get params, we cannont do anything, most probably connection error to babylon node
+		// we will try again in next round
+		return nil, false
+	}
+
+	return func(stakingTxHash *chainhash.Hash) bool {
+		return app.checkUnbondingTxSignaturesOnBabylon(stakingTxHash, params.CovenantQuruomThreshold)
+	}, true
+}
+
+// checkUnbondingTxSignaturesOnBabylon returns true if delegation received enough
+// covenant signatures or does not wait for them anymore
+func (app *StakerApp) checkUnbondingTxSignaturesOnBabylon(
+	stakingTxHash *chainhash.Hash,
+	covenantQuorum uint32,
+) bool {
+	storedTx, err := app.txTracker.GetTransaction(stakingTxHash)
+
+	if err == nil && storedTx.State != proto.TransactionState_SENT_TO_BABYLON {
+		// signatures were already delivered by other means i.e through
+		// developer api, nothing more to do here
+		return true
+	}
+
+	di, err := app.babylonClient.QueryDelegationInfo(stakingTxHash)
+
+	if err != nil {
+		if errors.Is(err, cl.ErrDelegationNotFound) {
+			// As we only start checking when we are sure delegation is already on babylon
+			// this can only that:
+			// - either we are connected to wrong babylon network
+			// - or babylon node lost data and is still syncing
+			app.logger.WithFields(logrus.Fields{
+				"stakingTxHash": stakingTxHash,
+			}).Error("Delegation for given staking tx hash does not exsist on babylon. Check your babylon node.")
+		} else {
+			app.logger.WithFields(logrus.Fields{
+				"stakingTxHash": stakingTxHash,
+				"err":           err,
+			}).Error("Error getting delegation info from babylon")
 		}
+
+		return false
+	}
+
+	if di.UndelegationInfo == nil {
+		// As we only start checking when we are sure delegation received unbonding request
+		// this can only that:
+		// - babylon node lost data and is still syncing, and not processed unbonding request yet
+		app.logger.WithFields(logrus.Fields{
+			"stakingTxHash": stakingTxHash,
+		}).Error("Delegation for given staking tx hash is not unbonding yet.")
+		return false
+	}
+
+	// we have enough signatures to submit unbonding tx this means that delegation is active
+	if len(di.UndelegationInfo.CovenantUnbondingSignatures) < int(covenantQuorum) {
+		app.logger.WithFields(logrus.Fields{
+			"stakingTxHash": stakingTxHash,
+			"numSignatures": len(di.UndelegationInfo.CovenantUnbondingSignatures),
+			"required":      covenantQuorum,
+		}).Debug("Received not enough covenant unbonding signatures on babylon")
+		return false
+	}
+
+	app.logger.WithFields(logrus.Fields{
+		"stakingTxHash": stakingTxHash,
+		"numSignatures": len(di.UndelegationInfo.CovenantUnbondingSignatures),
+	}).Debug("Received enough covenant unbonding signatures on babylon")
+
+	req := &unbondingTxSignaturesConfirmedOnBabylonEvent{
+		stakingTxHash:               *stakingTxHash,
+		covenantUnbondingSignatures: di.UndelegationInfo.CovenantUnbondingSignatures,
 	}
+
+	utils.PushOrQuit[*unbondingTxSignaturesConfirmedOnBabylonEvent](
+		app.unbondingTxSignaturesConfirmedOnBabylonEvChan,
+		req,
+		app.quit,
+	)
+
+	return true
 }
 
 func (app *StakerApp) finalityProviderExists(fpPk *btcec.PublicKey) error {
@@ -0,0 +1,120 @@
+package staker
+
+import (
+	"sync"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/sirupsen/logrus"
+)
+
+// BabylonBalance is the last checked balance of babylon account paying fees of
+// delegation submissions
+type BabylonBalance struct {
+	Balance    sdk.Coin
+	MinBalance sdkmath.Int
+	Low        bool
+	CheckedAt  time.Time
+}
+
+// babylonBalanceState holds result of the last balance check, accessed from
+// balance monitoring loop and rpc handlers
+type babylonBalanceState struct {
+	mu   sync.Mutex
+	last *BabylonBalance
+}
+
+func (s *babylonBalanceState) get() *BabylonBalance {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.last == nil {
+		return nil
+	}
+
+	b := *s.last
+	return &b
+}
+
+// set stores new balance and returns the previously stored one
+func (s *babylonBalanceState) set(b *BabylonBalance) *BabylonBalance {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev := s.last
+	s.last = b
+	return prev
+}
+
+// refreshBabylonBalance queries balance of babylon fee account and raises alert
+// if it is below configured minimum. Alert is logged on every check while the
+// balance stays low, so it is not lost in log rotation.
+func (app *StakerApp) refreshBabylonBalance() {
+	cfg := app.config.BalanceMonitorConfig
+	address := app.babylonClient.GetKeyAddress()
+
+	balance, err := app.babylonClient.QueryBalance(address, cfg.Denom)
+
+	if err != nil {
+		app.logger.WithError(err).Error("Failed to query balance of babylon fee account")
+		return
+	}
+
+	minBalance := sdkmath.NewIntFromUint64(cfg.MinBalance)
+
+	current := &BabylonBalance{
+		Balance:    balance,
+		MinBalance: minBalance,
+		Low:        balance.Amount.LT(minBalance),
+		CheckedAt:  time.Now(),
+	}
+
+	prev := app.babylonBalance.set(current)
+
+	balanceF, _ := balance.Amount.ToLegacyDec().Float64()
+	app.m.BabylonAccountBalance.Set(balanceF)
+
+	logFields := logrus.Fields{
+		"address":     address.String(),
+		"balance":     balance.String(),
+		"min_balance": sdk.NewCoin(cfg.Denom, minBalance).String(),
+	}
+
+	if current.Low {
+		app.m.BabylonAccountBalanceLow.Set(1)
+		app.logger.WithFields(logFields).Warn("Balance of babylon fee account is low, delegations may fail to be submitted to babylon")
+		app.requestFaucetFunds(cfg.Denom)
+		return
+	}
+
+	app.m.BabylonAccountBalanceLow.Set(0)
+
+	if prev != nil && prev.Low {
+		app.logger.WithFields(logFields).Info("Balance of babylon fee account is above minimum again")
+	}
+}
+
+func (app *StakerApp) babylonBalanceLoop(interval time.Duration) {
+	defer app.wg.Done()
+
+	app.refreshBabylonBalance()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			app.refreshBabylonBalance()
+		case <-app.quit:
+			return
+		}
+	}
+}
+
+// BabylonBalance returns result of the last balance check of babylon fee
+// account, nil if monitoring is disabled or balance was not checked yet
+func (app *StakerApp) BabylonBalance() *BabylonBalance {
+	return app.babylonBalance.get()
+}
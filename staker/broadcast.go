@@ -0,0 +1,70 @@
+package staker
+
+import (
+	"context"
+
+	"github.com/babylonchain/btc-staker/utils"
+	"github.com/babylonchain/btc-staker/walletcontroller"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/sirupsen/logrus"
+)
+
+// sendRawTransaction sends transaction through connected wallet node and, if it
+// was accepted, announces it to all additional broadcast endpoints. Only the
+// result of the wallet node decides about success, additional broadcasts are
+// done in the background and their results are only logged and counted in
+// metrics.
+func (app *StakerApp) sendRawTransaction(tx *wire.MsgTx, allowHighFees bool) (*chainhash.Hash, error) {
+	txHash, err := app.wc.SendRawTransaction(tx, allowHighFees)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(app.broadcastEndpoints) > 0 {
+		app.wg.Add(1)
+		go app.broadcastToAdditionalEndpoints(tx.Copy())
+	}
+
+	return txHash, nil
+}
+
+func (app *StakerApp) broadcastToAdditionalEndpoints(tx *wire.MsgTx) {
+	defer app.wg.Done()
+
+	ctx, cancel := app.appQuitContext()
+	defer cancel()
+
+	txHash := tx.TxHash()
+
+	utils.ParallelForEach(
+		app.broadcastEndpoints,
+		len(app.broadcastEndpoints),
+		app.quit,
+		func(endpoint walletcontroller.BroadcastEndpoint) {
+			broadcastCtx, broadcastCancel := context.WithTimeout(ctx, app.config.BroadcastConfig.Timeout)
+			defer broadcastCancel()
+
+			err := endpoint.Broadcast(broadcastCtx, tx)
+
+			if err != nil {
+				app.m.AdditionalBroadcasts.WithLabelValues(endpoint.Name(), "error").Inc()
+				// transactions already known by the endpoint are also reported
+				// as errors by most of them
+				app.logger.WithFields(logrus.Fields{
+					"txHash":   txHash,
+					"endpoint": endpoint.Name(),
+					"err":      err,
+				}).Warn("Failed to broadcast transaction to additional endpoint")
+				return
+			}
+
+			app.m.AdditionalBroadcasts.WithLabelValues(endpoint.Name(), "success").Inc()
+			app.logger.WithFields(logrus.Fields{
+				"txHash":   txHash,
+				"endpoint": endpoint.Name(),
+			}).Debug("Transaction broadcast to additional endpoint")
+		},
+	)
+}
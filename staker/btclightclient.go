@@ -0,0 +1,168 @@
+package staker
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	cl "github.com/babylonchain/btc-staker/babylonclient"
+	"github.com/sirupsen/logrus"
+)
+
+// BtcLightClientStatus is result of the last comparison of babylon btc light
+// client with connected btc node
+type BtcLightClientStatus struct {
+	BtcHeight         uint64
+	LightClientHeight uint64
+	// number of blocks by which light client lags behind btc node, 0 if light
+	// client is ahead
+	Lag       uint64
+	Lagging   bool
+	CheckedAt time.Time
+}
+
+// btcLightClientState holds result of the last check, accessed from monitoring
+// loop, delegation submissions and rpc handlers
+type btcLightClientState struct {
+	mu   sync.Mutex
+	last *BtcLightClientStatus
+}
+
+func (s *btcLightClientState) get() *BtcLightClientStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.last == nil {
+		return nil
+	}
+
+	status := *s.last
+	return &status
+}
+
+// set stores new status and returns the previously stored one
+func (s *btcLightClientState) set(status *BtcLightClientStatus) *BtcLightClientStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev := s.last
+	s.last = status
+	return prev
+}
+
+// checkBtcLightClient compares tip of babylon btc light client with connected
+// btc node. It returns false if babylon does not support the query and
+// monitoring should stop.
+func (app *StakerApp) checkBtcLightClient() bool {
+	lcHeight, err := app.babylonClient.QueryBtcLightClientTip()
+
+	if errors.Is(err, errors.ErrUnsupported) {
+		app.logger.WithError(err).Warn("Babylon does not report btc light client tip, monitoring of light client height is disabled")
+		return false
+	}
+
+	if err != nil {
+		app.logger.WithError(err).Error("Failed to query tip of babylon btc light client")
+		return true
+	}
+
+	syncStatus, err := app.wc.ChainSyncStatus()
+
+	if err != nil {
+		app.logger.WithError(err).Error("Failed to query best height of btc node")
+		return true
+	}
+
+	btcHeight := uint64(syncStatus.Blocks)
+
+	var lag uint64
+	if btcHeight > lcHeight {
+		lag = btcHeight - lcHeight
+	}
+
+	current := &BtcLightClientStatus{
+		BtcHeight:         btcHeight,
+		LightClientHeight: lcHeight,
+		Lag:               lag,
+		Lagging:           lag > uint64(app.config.BtcLightClientConfig.MaxBlocksBehind),
+		CheckedAt:         time.Now(),
+	}
+
+	prev := app.btcLightClient.set(current)
+
+	app.m.BtcLightClientHeight.Set(float64(lcHeight))
+	app.m.BtcLightClientLag.Set(float64(lag))
+
+	logFields := logrus.Fields{
+		"btcBlockHeight":       btcHeight,
+		"btcLightClientHeight": lcHeight,
+		"maxBlocksBehind":      app.config.BtcLightClientConfig.MaxBlocksBehind,
+		"delaySubmissions":     !app.config.BtcLightClientConfig.DisableSubmissionDelay,
+	}
+
+	if current.Lagging {
+		app.m.BtcLightClientLagging.Set(1)
+		app.logger.WithFields(logFields).Warn("Babylon btc light client lags behind btc node, delegations may be rejected by babylon")
+		return true
+	}
+
+	app.m.BtcLightClientLagging.Set(0)
+
+	if prev != nil && prev.Lagging {
+		app.logger.WithFields(logFields).Info("Babylon btc light client caught up with btc node")
+	}
+
+	return true
+}
+
+func (app *StakerApp) btcLightClientLoop(interval time.Duration) {
+	defer app.wg.Done()
+
+	if !app.checkBtcLightClient() {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !app.checkBtcLightClient() {
+				return
+			}
+		case <-app.quit:
+			return
+		}
+	}
+}
+
+// checkBtcLightClientReady returns error if delegations should not be submitted
+// to babylon, because its btc light client lags behind btc node. Proofs of
+// inclusion in blocks unknown to the light client would be rejected.
+func (app *StakerApp) checkBtcLightClientReady() error {
+	if app.config.BtcLightClientConfig.DisableSubmissionDelay {
+		return nil
+	}
+
+	status := app.btcLightClient.get()
+
+	if status == nil || !status.Lagging {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"btc light client at height %d lags %d blocks behind btc node at height %d: %w",
+		status.LightClientHeight,
+		status.Lag,
+		status.BtcHeight,
+		cl.ErrBabylonBtcLightClientNotReady,
+	)
+}
+
+// BtcLightClient returns result of the last comparison of babylon btc light
+// client with btc node, nil if monitoring is disabled or did not succeed yet
+func (app *StakerApp) BtcLightClient() *BtcLightClientStatus {
+	return app.btcLightClient.get()
+}
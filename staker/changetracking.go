@@ -0,0 +1,131 @@
+package staker
+
+import (
+	"fmt"
+	"time"
+
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/sirupsen/logrus"
+)
+
+// trackChangeAddress makes sure that change of the transaction funded by the
+// wallet is rediscovered by wallet rescans
+func (app *StakerApp) trackChangeAddress(address btcutil.Address) error {
+	if app.config.WalletConfig.DisableChangeTracking {
+		return nil
+	}
+
+	imported, err := app.wc.TrackAddress(address, time.Time{})
+
+	if err != nil {
+		return fmt.Errorf("failed to track change address %s: %w", address, err)
+	}
+
+	if imported {
+		app.logger.WithFields(logrus.Fields{
+			"address": address,
+		}).Info("Imported change address to the wallet")
+	}
+
+	return nil
+}
+
+// changeAddress returns address receiving change of transaction funded from
+// fundingAddress, according to change destination configured for the operation
+func (app *StakerApp) changeAddress(destination string, fundingAddress btcutil.Address) (btcutil.Address, error) {
+	switch destination {
+	case scfg.ChangeDestinationFresh:
+		address, err := app.wc.NewChangeAddress()
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to get fresh change address: %w", err)
+		}
+
+		return address, nil
+	case scfg.ChangeDestinationCold:
+		// cold address does not belong to the wallet, so it is not tracked
+		return app.config.ChangePolicyConfig.ColdChangeAddress(app.network)
+	default:
+		if err := app.trackChangeAddress(fundingAddress); err != nil {
+			return nil, err
+		}
+
+		return fundingAddress, nil
+	}
+}
+
+// trackStoredChangeAddresses imports change addresses of all stored staking
+// transactions which are not known to the wallet. This happens after wallet is
+// restored from backup which does not contain imported keys, and without it
+// change from staking transactions would look like missing funds. Rescan starts
+// at the time first transaction paying to the address was created.
+func (app *StakerApp) trackStoredChangeAddresses() {
+	defer app.wg.Done()
+
+	if app.config.WalletConfig.DisableChangeTracking {
+		return
+	}
+
+	// earliest creation time of transactions paying change to the address, zero
+	// if it is unknown
+	since := make(map[string]time.Time)
+
+	err := app.txTracker.ScanTrackedTransactions(func(tx *stakerdb.StoredTransaction) error {
+		// change of watched transactions was paid to external wallet
+		if tx.Watched {
+			return nil
+		}
+
+		createdAt := tx.CreatedAt()
+
+		if current, found := since[tx.StakerAddress]; !found || createdAt.Before(current) {
+			since[tx.StakerAddress] = createdAt
+		}
+
+		return nil
+	}, func() {
+		since = make(map[string]time.Time)
+	})
+
+	if err != nil {
+		app.logger.WithError(err).Error("Failed to read change addresses of stored transactions")
+		return
+	}
+
+	for encoded, createdAt := range since {
+		select {
+		case <-app.quit:
+			return
+		default:
+		}
+
+		address, err := btcutil.DecodeAddress(encoded, app.network)
+
+		if err != nil {
+			app.logger.WithError(err).WithField("address", encoded).Error("Invalid change address of stored transaction")
+			continue
+		}
+
+		rescanFrom := createdAt
+		if rescanFrom.IsZero() {
+			// whole chain must be rescanned
+			rescanFrom = time.Unix(0, 0)
+		}
+
+		imported, err := app.wc.TrackAddress(address, rescanFrom)
+
+		if err != nil {
+			app.logger.WithError(err).WithField("address", encoded).Warn("Failed to track change address of stored transactions")
+			continue
+		}
+
+		if imported {
+			app.logger.WithFields(logrus.Fields{
+				"address":    encoded,
+				"rescanFrom": rescanFrom,
+			}).Info("Imported change address of stored transactions to the wallet")
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package staker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/semaphore"
+)
+
+// signingLimiter limits number of staking transactions which are created and
+// signed by the wallet in parallel
+type signingLimiter struct {
+	s        *semaphore.Weighted
+	queued   prometheus.Gauge
+	inFlight prometheus.Gauge
+}
+
+func newSigningLimiter(maxConcurrentSignings uint32, queued, inFlight prometheus.Gauge) *signingLimiter {
+	return &signingLimiter{
+		s:        semaphore.NewWeighted(int64(maxConcurrentSignings)),
+		queued:   queued,
+		inFlight: inFlight,
+	}
+}
+
+func (l *signingLimiter) acquire(ctx context.Context) error {
+	l.queued.Inc()
+	defer l.queued.Dec()
+
+	if err := l.s.Acquire(ctx, 1); err != nil {
+		return fmt.Errorf("timed out waiting for free signing slot: %w", err)
+	}
+
+	l.inFlight.Inc()
+	return nil
+}
+
+func (l *signingLimiter) release() {
+	l.inFlight.Dec()
+	l.s.Release(1)
+}
+
+// unconfirmedTxSlot is slot reserved by staking request in unconfirmedTxLimiter
+type unconfirmedTxSlot struct {
+	// set when slot was either committed or cancelled, guarded by limiter mutex
+	done bool
+}
+
+// unconfirmedTxLimiter limits number of staking transactions sent to btc, which
+// are not confirmed yet. Slot is first reserved by staking request, then it is
+// either committed after transaction is sent to btc or cancelled if sending failed.
+// Committed slot is released after transaction is confirmed.
+type unconfirmedTxLimiter struct {
+	mu sync.Mutex
+	// 0 means there is no limit
+	max      int
+	pending  map[chainhash.Hash]struct{}
+	reserved int
+	// closed and replaced each time slot is freed
+	freed chan struct{}
+
+	queued      prometheus.Gauge
+	unconfirmed prometheus.Gauge
+}
+
+func newUnconfirmedTxLimiter(maxUnconfirmed uint32, queued, unconfirmed prometheus.Gauge) *unconfirmedTxLimiter {
+	return &unconfirmedTxLimiter{
+		max:         int(maxUnconfirmed),
+		pending:     make(map[chainhash.Hash]struct{}),
+		freed:       make(chan struct{}),
+		queued:      queued,
+		unconfirmed: unconfirmed,
+	}
+}
+
+func (l *unconfirmedTxLimiter) notifyFreedLocked() {
+	close(l.freed)
+	l.freed = make(chan struct{})
+}
+
+// reserve blocks until there is free slot for new unconfirmed transaction or
+// context is done
+func (l *unconfirmedTxLimiter) reserve(ctx context.Context) (*unconfirmedTxSlot, error) {
+	l.queued.Inc()
+	defer l.queued.Dec()
+
+	for {
+		l.mu.Lock()
+		if l.max == 0 || len(l.pending)+l.reserved < l.max {
+			l.reserved++
+			l.mu.Unlock()
+			return &unconfirmedTxSlot{}, nil
+		}
+		freed := l.freed
+		l.mu.Unlock()
+
+		select {
+		case <-freed:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out waiting for confirmation of previous staking transactions. Max unconfirmed staking transactions: %d: %w", l.max, ctx.Err())
+		}
+	}
+}
+
+// commit marks reserved slot as used by transaction sent to btc
+func (l *unconfirmedTxLimiter) commit(slot *unconfirmedTxSlot, txHash chainhash.Hash) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if slot.done {
+		return
+	}
+
+	slot.done = true
+	l.reserved--
+	l.pending[txHash] = struct{}{}
+	l.unconfirmed.Set(float64(len(l.pending)))
+}
+
+// cancel releases reserved slot, unless it was already committed
+func (l *unconfirmedTxLimiter) cancel(slot *unconfirmedTxSlot) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if slot.done {
+		return
+	}
+
+	slot.done = true
+	l.reserved--
+	l.notifyFreedLocked()
+}
+
+// track marks already sent transaction as unconfirmed without reserving a slot
+// first, it is used to restore state after restart
+func (l *unconfirmedTxLimiter) track(txHash chainhash.Hash) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pending[txHash] = struct{}{}
+	l.unconfirmed.Set(float64(len(l.pending)))
+}
+
+// release frees slot used by transaction which got confirmed
+func (l *unconfirmedTxLimiter) release(txHash chainhash.Hash) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, found := l.pending[txHash]; !found {
+		return
+	}
+
+	delete(l.pending, txHash)
+	l.unconfirmed.Set(float64(len(l.pending)))
+	l.notifyFreedLocked()
+}
+
+// acquireStakingSlots waits until there is a free slot for new unconfirmed staking
+// transaction and free signing slot. Request waits at most StakingQueueTimeout.
+// Returned function must be called after staking request is processed.
+func (app *StakerApp) acquireStakingSlots() (*unconfirmedTxSlot, func(), error) {
+	quitCtx, cancelQuitCtx := app.appQuitContext()
+	defer cancelQuitCtx()
+
+	ctx, cancel := context.WithTimeout(quitCtx, app.config.StakerConfig.StakingQueueTimeout)
+	defer cancel()
+
+	slot, err := app.unconfirmedTxs.reserve(ctx)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := app.signings.acquire(ctx); err != nil {
+		app.unconfirmedTxs.cancel(slot)
+		return nil, nil, err
+	}
+
+	release := func() {
+		app.signings.release()
+		// no-op if transaction was sent to btc
+		app.unconfirmedTxs.cancel(slot)
+	}
+
+	return slot, release, nil
+}
@@ -0,0 +1,195 @@
+package staker
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/sirupsen/logrus"
+)
+
+// transactions not confirmed in this time are most likely replaced or evicted
+// from mempool, and are not watched anymore
+const firstConfirmationMaxWait = 14 * 24 * time.Hour
+
+// ConfirmationSlaKindStats summarizes times to first confirmation of
+// transactions of one kind
+type ConfirmationSlaKindStats struct {
+	Kind string
+	// number of transactions whose first confirmation was observed, times are
+	// calculated only from them
+	Observed int
+	Avg      time.Duration
+	Median   time.Duration
+	P90      time.Duration
+	Max      time.Duration
+	Exceeded int
+}
+
+// FlaggedTransaction is transaction which exceeded confirmation target, either
+// confirmed late or still not observed to confirm
+type FlaggedTransaction struct {
+	TxHash chainhash.Hash
+	Kind   string
+	SentAt time.Time
+	// nil if first confirmation was not observed
+	TimeToFirstConfirmation *time.Duration
+}
+
+type ConfirmationSlaSummary struct {
+	Target  time.Duration
+	Kinds   []ConfirmationSlaKindStats
+	Flagged []FlaggedTransaction
+}
+
+// watchFirstConfirmation records time to first confirmation of transaction
+// sent by the daemon
+func (app *StakerApp) watchFirstConfirmation(tx *wire.MsgTx) {
+	txHash := tx.TxHash()
+	sub := app.confTracker.track(&txHash, tx.TxOut[0].PkScript, 1)
+
+	app.wg.Add(1)
+	go func() {
+		defer app.wg.Done()
+		defer sub.Cancel()
+
+		timeout := time.NewTimer(firstConfirmationMaxWait)
+		defer timeout.Stop()
+
+		select {
+		case conf := <-sub.Confirmed:
+			app.recordFirstConfirmation(txHash, conf.BlockHeight, time.Now())
+		case <-timeout.C:
+		case <-app.quit:
+		}
+	}()
+}
+
+func (app *StakerApp) recordFirstConfirmation(txHash chainhash.Hash, height uint32, observedAt time.Time) {
+	entry, err := app.feeSpends.SetConfirmation(txHash, height, observedAt)
+
+	if err != nil {
+		app.logger.WithFields(logrus.Fields{
+			"txHash": txHash,
+			"height": height,
+			"err":    err,
+		}).Error("Failed to record first confirmation of transaction")
+		return
+	}
+
+	ttfc, _ := entry.TimeToFirstConfirmation()
+	app.m.TimeToFirstConfirmation.WithLabelValues(entry.Kind).Observe(ttfc.Seconds())
+
+	target := app.config.ConfirmationSlaConfig.Target
+
+	if target > 0 && ttfc > target {
+		app.m.ConfirmationSlaExceeded.WithLabelValues(entry.Kind).Inc()
+		app.logger.WithFields(logrus.Fields{
+			"txHash":                  txHash,
+			"kind":                    entry.Kind,
+			"feeRate":                 fmt.Sprintf("%.2f", entry.FeeRate()),
+			"timeToFirstConfirmation": ttfc,
+			"target":                  target,
+		}).Warn("Transaction received first confirmation later than confirmation target")
+	}
+}
+
+// percentile returns value below which given fraction of sorted durations lies
+func percentile(sorted []time.Duration, fraction float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	idx := int(fraction * float64(len(sorted)-1))
+	return sorted[idx]
+}
+
+// ConfirmationSlaSummary returns times to first confirmation of transactions
+// sent by the daemon in the last window, and transactions which exceeded
+// confirmation target
+func (app *StakerApp) ConfirmationSlaSummary(window time.Duration) (*ConfirmationSlaSummary, error) {
+	now := time.Now()
+
+	spends, err := app.feeSpends.SpendsSince(now.Add(-window))
+
+	if err != nil {
+		return nil, err
+	}
+
+	target := app.config.ConfirmationSlaConfig.Target
+
+	summary := &ConfirmationSlaSummary{
+		Target:  target,
+		Flagged: []FlaggedTransaction{},
+	}
+
+	times := make(map[string][]time.Duration)
+	exceeded := make(map[string]int)
+
+	for i := range spends {
+		spend := &spends[i]
+		ttfc, observed := spend.TimeToFirstConfirmation()
+
+		if _, found := times[spend.Kind]; !found {
+			times[spend.Kind] = []time.Duration{}
+		}
+
+		if observed {
+			times[spend.Kind] = append(times[spend.Kind], ttfc)
+		}
+
+		if target == 0 {
+			continue
+		}
+
+		if observed && ttfc > target {
+			exceeded[spend.Kind]++
+			summary.Flagged = append(summary.Flagged, flaggedTransaction(spend, &ttfc))
+		} else if !observed && spend.ConfirmationHeight == 0 && now.Sub(spend.Timestamp) > target {
+			exceeded[spend.Kind]++
+			summary.Flagged = append(summary.Flagged, flaggedTransaction(spend, nil))
+		}
+	}
+
+	for kind, kindTimes := range times {
+		stats := ConfirmationSlaKindStats{
+			Kind:     kind,
+			Observed: len(kindTimes),
+			Exceeded: exceeded[kind],
+		}
+
+		if len(kindTimes) > 0 {
+			sort.Slice(kindTimes, func(i, j int) bool { return kindTimes[i] < kindTimes[j] })
+
+			var sum time.Duration
+			for _, t := range kindTimes {
+				sum += t
+			}
+
+			stats.Avg = sum / time.Duration(len(kindTimes))
+			stats.Median = percentile(kindTimes, 0.5)
+			stats.P90 = percentile(kindTimes, 0.9)
+			stats.Max = kindTimes[len(kindTimes)-1]
+		}
+
+		summary.Kinds = append(summary.Kinds, stats)
+	}
+
+	sort.Slice(summary.Kinds, func(i, j int) bool {
+		return summary.Kinds[i].Kind < summary.Kinds[j].Kind
+	})
+
+	return summary, nil
+}
+
+func flaggedTransaction(spend *stakerdb.FeeSpendEntry, ttfc *time.Duration) FlaggedTransaction {
+	return FlaggedTransaction{
+		TxHash:                  spend.TxHash,
+		Kind:                    spend.Kind,
+		SentAt:                  spend.Timestamp,
+		TimeToFirstConfirmation: ttfc,
+	}
+}
@@ -0,0 +1,529 @@
+package staker
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/babylonchain/btc-staker/metrics"
+	"github.com/babylonchain/btc-staker/walletcontroller"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	notifier "github.com/lightningnetwork/lnd/chainntnfs"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// confirmationSubscription is notified when tracked transaction reaches required
+// number of confirmations
+type confirmationSubscription struct {
+	txHash   chainhash.Hash
+	pkScript []byte
+	numConfs uint32
+
+	// Confirmed receives exactly one notification when transaction reaches
+	// required number of confirmations
+	Confirmed chan *notifier.TxConfirmation
+
+	// Updates receives number of confirmations left, updates are dropped if
+	// nobody is listening
+	Updates chan uint32
+
+	tracker *confirmationTracker
+
+	// fields below are guarded by tracker mutex
+	inclusion    *notifier.TxConfirmation
+	initialCheck bool
+	// transaction is known not to be included in blocks up to this height, so
+	// initial check only scans newer blocks. Set for transactions resumed from
+	// tracking snapshot.
+	scannedUpTo uint32
+	// inclusion of transaction is known up to best height of the tracker
+	verified bool
+}
+
+// Cancel stops tracking of transaction confirmations
+func (s *confirmationSubscription) Cancel() {
+	s.tracker.remove(s)
+}
+
+// confirmationTracker tracks confirmations of all staker transactions using single
+// block subscription. Each new block is retrieved from btc node exactly once and
+// all tracked transactions are updated based on it, so load on btc node does not
+// depend on number of tracked transactions.
+type confirmationTracker struct {
+	wc      walletcontroller.WalletController
+	ntfn    notifier.ChainNotifier
+	logger  *logrus.Logger
+	tracked prometheus.Gauge
+
+	mu   sync.Mutex
+	subs map[chainhash.Hash][]*confirmationSubscription
+	// height and hash of last processed block, hash is nil after reorg until
+	// next block is processed
+	bestHeight uint32
+	bestHash   *chainhash.Hash
+
+	// best height reported by secondary btc backend while node delivering
+	// block notifications is stalled
+	heights chan uint32
+
+	// signalled when new subscriptions require initial check
+	wakeup chan struct{}
+	wg     sync.WaitGroup
+	quit   chan struct{}
+}
+
+func newConfirmationTracker(
+	wc walletcontroller.WalletController,
+	ntfn notifier.ChainNotifier,
+	logger *logrus.Logger,
+	m *metrics.StakerMetrics,
+) *confirmationTracker {
+	return &confirmationTracker{
+		wc:      wc,
+		ntfn:    ntfn,
+		logger:  logger,
+		tracked: m.TrackedConfirmations,
+		subs:    make(map[chainhash.Hash][]*confirmationSubscription),
+		wakeup:  make(chan struct{}, 1),
+		heights: make(chan uint32, 1),
+		quit:    make(chan struct{}),
+	}
+}
+
+func (t *confirmationTracker) start() error {
+	blockEvents, err := t.ntfn.RegisterBlockEpochNtfn(nil)
+
+	if err != nil {
+		return fmt.Errorf("failed to register for block notifications: %w", err)
+	}
+
+	// we registered with `nil` so we receive current best block immediately.
+	// Transactions registered before that are checked on registration, so
+	// there is no need to scan this block
+	select {
+	case block := <-blockEvents.Epochs:
+		t.mu.Lock()
+		t.bestHeight = uint32(block.Height)
+		t.bestHash = block.Hash
+		t.mu.Unlock()
+	case <-t.quit:
+		blockEvents.Cancel()
+		return fmt.Errorf("confirmation tracker quit before finishing start")
+	}
+
+	t.wg.Add(1)
+	go t.trackLoop(blockEvents)
+
+	return nil
+}
+
+func (t *confirmationTracker) stop() {
+	close(t.quit)
+	t.wg.Wait()
+}
+
+// track starts tracking confirmations of given transaction. It never blocks
+// and never calls into btc node, so it is safe to call from the main event loop.
+func (t *confirmationTracker) track(
+	txHash *chainhash.Hash,
+	pkScript []byte,
+	numConfs uint32,
+) *confirmationSubscription {
+	return t.add(&confirmationSubscription{
+		txHash:       *txHash,
+		pkScript:     pkScript,
+		numConfs:     numConfs,
+		Confirmed:    make(chan *notifier.TxConfirmation, 1),
+		Updates:      make(chan uint32, 1),
+		tracker:      t,
+		initialCheck: true,
+	})
+}
+
+// trackFromSnapshot starts tracking confirmations of transaction whose state is
+// known from tracking snapshot. Transaction is either already included in the
+// chain, or known not to be included in blocks up to scannedUpTo height. Unlike
+// track, it does not query btc node for every transaction, only blocks after
+// scannedUpTo are scanned once for all resumed transactions. Inclusion from
+// snapshot does not carry the block, receiver must retrieve it.
+func (t *confirmationTracker) trackFromSnapshot(
+	txHash *chainhash.Hash,
+	pkScript []byte,
+	numConfs uint32,
+	inclusion *notifier.TxConfirmation,
+	scannedUpTo uint32,
+) *confirmationSubscription {
+	sub := &confirmationSubscription{
+		txHash:    *txHash,
+		pkScript:  pkScript,
+		numConfs:  numConfs,
+		Confirmed: make(chan *notifier.TxConfirmation, 1),
+		Updates:   make(chan uint32, 1),
+		tracker:   t,
+	}
+
+	if inclusion != nil {
+		sub.inclusion = inclusion
+		sub.verified = true
+	} else {
+		sub.initialCheck = true
+		sub.scannedUpTo = scannedUpTo
+	}
+
+	return t.add(sub)
+}
+
+func (t *confirmationTracker) add(sub *confirmationSubscription) *confirmationSubscription {
+	t.mu.Lock()
+	t.subs[sub.txHash] = append(t.subs[sub.txHash], sub)
+	t.tracked.Set(float64(len(t.subs)))
+	t.mu.Unlock()
+
+	select {
+	case t.wakeup <- struct{}{}:
+	default:
+	}
+
+	return sub
+}
+
+func (t *confirmationTracker) remove(sub *confirmationSubscription) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.removeLocked(sub)
+}
+
+func (t *confirmationTracker) removeLocked(sub *confirmationSubscription) {
+	subs := t.subs[sub.txHash]
+
+	for i, s := range subs {
+		if s == sub {
+			subs = append(subs[:i], subs[i+1:]...)
+			break
+		}
+	}
+
+	if len(subs) == 0 {
+		delete(t.subs, sub.txHash)
+	} else {
+		t.subs[sub.txHash] = subs
+	}
+
+	t.tracked.Set(float64(len(t.subs)))
+}
+
+func (t *confirmationTracker) trackLoop(blockEvents *notifier.BlockEpochEvent) {
+	defer t.wg.Done()
+	defer blockEvents.Cancel()
+
+	for {
+		select {
+		case block, ok := <-blockEvents.Epochs:
+			if !ok {
+				return
+			}
+
+			if err := t.connectBlock(uint32(block.Height), block.Hash); err != nil {
+				t.logger.WithFields(logrus.Fields{
+					"btcBlockHeight": block.Height,
+					"btcBlockHash":   block.Hash,
+					"err":            err,
+				}).Error("Failed to process new block in confirmation tracker")
+			}
+		case <-t.wakeup:
+			t.initialCheck()
+		case height := <-t.heights:
+			if err := t.connectHeight(height); err != nil {
+				t.logger.WithFields(logrus.Fields{
+					"btcBlockHeight": height,
+					"err":            err,
+				}).Error("Failed to process blocks reported by secondary btc backend")
+			}
+		case <-t.quit:
+			return
+		}
+	}
+}
+
+// initialCheck checks new subscriptions against btc node, as transaction could
+// be already included in the chain before subscription was created
+func (t *confirmationTracker) initialCheck() {
+	t.mu.Lock()
+	var toCheck []*confirmationSubscription
+	var toScan []*confirmationSubscription
+	for _, subs := range t.subs {
+		for _, sub := range subs {
+			if !sub.initialCheck {
+				continue
+			}
+
+			sub.initialCheck = false
+
+			if sub.scannedUpTo > 0 {
+				toScan = append(toScan, sub)
+			} else {
+				toCheck = append(toCheck, sub)
+			}
+		}
+	}
+	t.mu.Unlock()
+
+	if len(toScan) > 0 {
+		if err := t.scanBlocks(toScan); err != nil {
+			t.logger.WithFields(logrus.Fields{
+				"transactions": len(toScan),
+				"err":          err,
+			}).Warn("Failed to scan blocks for transactions resumed from snapshot. Checking them one by one")
+
+			toCheck = append(toCheck, toScan...)
+		}
+	}
+
+	for _, sub := range toCheck {
+		details, status, err := t.wc.TxDetails(&sub.txHash, sub.pkScript)
+
+		if err != nil {
+			t.logger.WithFields(logrus.Fields{
+				"btcTxHash": sub.txHash,
+				"err":       err,
+			}).Error("Failed to check transaction status. Waiting for it in new blocks")
+			continue
+		}
+
+		t.mu.Lock()
+		if status == walletcontroller.TxInChain && sub.inclusion == nil {
+			sub.inclusion = details
+		}
+		sub.verified = true
+		t.mu.Unlock()
+	}
+
+	t.mu.Lock()
+	t.notifyLocked()
+	t.mu.Unlock()
+}
+
+// scanBlocks looks for given transactions in blocks after their scanned height
+// up to best height. Every block is retrieved once, regardless of number of
+// transactions.
+func (t *confirmationTracker) scanBlocks(subs []*confirmationSubscription) error {
+	byHash := make(map[chainhash.Hash][]*confirmationSubscription, len(subs))
+
+	t.mu.Lock()
+	toHeight := t.bestHeight
+	fromHeight := toHeight + 1
+	for _, sub := range subs {
+		byHash[sub.txHash] = append(byHash[sub.txHash], sub)
+
+		if sub.scannedUpTo+1 < fromHeight {
+			fromHeight = sub.scannedUpTo + 1
+		}
+	}
+	t.mu.Unlock()
+
+	for height := fromHeight; height <= toHeight; height++ {
+		hash, err := t.wc.GetBlockHash(int64(height))
+
+		if err != nil {
+			return err
+		}
+
+		block, err := t.wc.GetBlock(hash)
+
+		if err != nil {
+			return err
+		}
+
+		t.mu.Lock()
+		for i, tx := range block.Transactions {
+			for _, sub := range byHash[tx.TxHash()] {
+				if sub.inclusion != nil || sub.scannedUpTo >= height {
+					continue
+				}
+
+				sub.inclusion = &notifier.TxConfirmation{
+					BlockHash:   hash,
+					BlockHeight: height,
+					TxIndex:     uint32(i),
+					Tx:          tx,
+					Block:       block,
+				}
+			}
+		}
+		t.mu.Unlock()
+	}
+
+	t.mu.Lock()
+	for _, sub := range subs {
+		sub.scannedUpTo = 0
+		sub.verified = true
+	}
+	t.mu.Unlock()
+
+	return nil
+}
+
+// advanceTo processes blocks up to given height without waiting for block
+// notifications. It never blocks, if previous height was not processed yet it is
+// replaced.
+func (t *confirmationTracker) advanceTo(height uint32) {
+	select {
+	case <-t.heights:
+	default:
+	}
+
+	select {
+	case t.heights <- height:
+	default:
+	}
+}
+
+func (t *confirmationTracker) connectHeight(height uint32) error {
+	t.mu.Lock()
+	bestHeight := t.bestHeight
+	t.mu.Unlock()
+
+	// unlike block notifications, polled height carries no information about
+	// reorgs, so heights which were already processed are skipped
+	if height <= bestHeight {
+		return nil
+	}
+
+	hash, err := t.wc.GetBlockHash(int64(height))
+
+	if err != nil {
+		return err
+	}
+
+	return t.connectBlock(height, hash)
+}
+
+func (t *confirmationTracker) connectBlock(height uint32, hash *chainhash.Hash) error {
+	t.mu.Lock()
+	bestHeight := t.bestHeight
+	t.mu.Unlock()
+
+	if height <= bestHeight {
+		// reorg, blocks starting from this height are replaced by new chain
+		t.disconnectBlocks(height)
+	} else if height > bestHeight+1 {
+		// we missed some blocks e.g btc node was catching up, process them in order
+		for missingHeight := bestHeight + 1; missingHeight < height; missingHeight++ {
+			missingHash, err := t.wc.GetBlockHash(int64(missingHeight))
+
+			if err != nil {
+				return err
+			}
+
+			if err := t.processBlock(missingHeight, missingHash); err != nil {
+				return err
+			}
+		}
+	}
+
+	return t.processBlock(height, hash)
+}
+
+func (t *confirmationTracker) disconnectBlocks(fromHeight uint32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, subs := range t.subs {
+		for _, sub := range subs {
+			if sub.inclusion != nil && sub.inclusion.BlockHeight >= fromHeight {
+				t.logger.WithFields(logrus.Fields{
+					"btcTxHash":      sub.txHash,
+					"btcBlockHeight": sub.inclusion.BlockHeight,
+				}).Info("Tracked transaction was reorged out of the chain")
+				sub.inclusion = nil
+			}
+		}
+	}
+
+	t.bestHeight = fromHeight - 1
+	t.bestHash = nil
+}
+
+func (t *confirmationTracker) processBlock(height uint32, hash *chainhash.Hash) error {
+	var block *wire.MsgBlock
+
+	t.mu.Lock()
+	numTracked := len(t.subs)
+	t.mu.Unlock()
+
+	// no need to retrieve block if there is nothing to look for
+	if numTracked > 0 {
+		b, err := t.wc.GetBlock(hash)
+
+		if err != nil {
+			return err
+		}
+		block = b
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if block != nil {
+		for i, tx := range block.Transactions {
+			subs, found := t.subs[tx.TxHash()]
+
+			if !found {
+				continue
+			}
+
+			for _, sub := range subs {
+				if sub.inclusion != nil {
+					continue
+				}
+
+				sub.inclusion = &notifier.TxConfirmation{
+					BlockHash:   hash,
+					BlockHeight: height,
+					TxIndex:     uint32(i),
+					Tx:          tx,
+					Block:       block,
+				}
+			}
+		}
+	}
+
+	t.bestHeight = height
+	t.bestHash = hash
+	t.notifyLocked()
+
+	return nil
+}
+
+// notifyLocked notifies subscriptions about confirmations and removes those which
+// reached required depth
+func (t *confirmationTracker) notifyLocked() {
+	var confirmed []*confirmationSubscription
+
+	for _, subs := range t.subs {
+		for _, sub := range subs {
+			if sub.inclusion == nil || sub.inclusion.BlockHeight > t.bestHeight {
+				continue
+			}
+
+			confs := t.bestHeight - sub.inclusion.BlockHeight + 1
+
+			if confs >= sub.numConfs {
+				confirmed = append(confirmed, sub)
+				continue
+			}
+
+			select {
+			case sub.Updates <- sub.numConfs - confs:
+			default:
+			}
+		}
+	}
+
+	for _, sub := range confirmed {
+		// channel is buffered and receives only this notification
+		sub.Confirmed <- sub.inclusion
+		t.removeLocked(sub)
+	}
+}
@@ -0,0 +1,454 @@
+package staker
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/babylonchain/btc-staker/metrics"
+	"github.com/babylonchain/btc-staker/walletcontroller"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	notifier "github.com/lightningnetwork/lnd/chainntnfs"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChain is btc node serving blocks of in memory chain. Only methods used by
+// confirmation tracker are implemented.
+type fakeChain struct {
+	walletcontroller.WalletController
+
+	mu sync.Mutex
+	// blocks[height] is block at given height
+	blocks         []*wire.MsgBlock
+	nonce          uint32
+	blockRequests  map[uint32]int
+	txDetailsCalls int
+	getBlockErr    error
+}
+
+func newFakeChain(height uint32) *fakeChain {
+	c := &fakeChain{blockRequests: make(map[uint32]int)}
+
+	for h := uint32(0); h <= height; h++ {
+		c.mine()
+	}
+
+	return c
+}
+
+func newTestTx(id uint32) *wire.MsgTx {
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: id}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(int64(id)+1000, []byte{0x51}))
+	return tx
+}
+
+// mine appends block with given transactions to the tip of the chain
+func (c *fakeChain) mine(txs ...*wire.MsgTx) *wire.MsgBlock {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nonce++
+
+	var prevHash chainhash.Hash
+	if len(c.blocks) > 0 {
+		prevHash = c.blocks[len(c.blocks)-1].BlockHash()
+	}
+
+	block := &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			PrevBlock: prevHash,
+			Nonce:     c.nonce,
+		},
+		Transactions: append([]*wire.MsgTx{newTestTx(1 << 31)}, txs...),
+	}
+
+	c.blocks = append(c.blocks, block)
+	return block
+}
+
+// reorg removes blocks starting from given height
+func (c *fakeChain) reorg(fromHeight uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.blocks = c.blocks[:fromHeight]
+}
+
+func (c *fakeChain) tip() (uint32, *chainhash.Hash) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hash := c.blocks[len(c.blocks)-1].BlockHash()
+	return uint32(len(c.blocks) - 1), &hash
+}
+
+func (c *fakeChain) requests(height uint32) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.blockRequests[height]
+}
+
+func (c *fakeChain) TxDetails(txHash *chainhash.Hash, _ []byte) (*notifier.TxConfirmation, walletcontroller.TxStatus, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.txDetailsCalls++
+
+	for height, block := range c.blocks {
+		for i, tx := range block.Transactions {
+			if tx.TxHash() != *txHash {
+				continue
+			}
+
+			blockHash := block.BlockHash()
+			return &notifier.TxConfirmation{
+				BlockHash:   &blockHash,
+				BlockHeight: uint32(height),
+				TxIndex:     uint32(i),
+				Tx:          tx,
+				Block:       block,
+			}, walletcontroller.TxInChain, nil
+		}
+	}
+
+	return nil, walletcontroller.TxNotFound, nil
+}
+
+func (c *fakeChain) GetBlockHash(blockHeight int64) (*chainhash.Hash, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if blockHeight < 0 || blockHeight >= int64(len(c.blocks)) {
+		return nil, fmt.Errorf("block at height %d not found", blockHeight)
+	}
+
+	hash := c.blocks[blockHeight].BlockHash()
+	return &hash, nil
+}
+
+func (c *fakeChain) GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.getBlockErr != nil {
+		return nil, c.getBlockErr
+	}
+
+	for height, block := range c.blocks {
+		if block.BlockHash() == *blockHash {
+			c.blockRequests[uint32(height)]++
+			return block, nil
+		}
+	}
+
+	return nil, fmt.Errorf("block %s not found", blockHash)
+}
+
+// fakeBlockSource delivers block notifications pushed by the test
+type fakeBlockSource struct {
+	notifier.ChainNotifier
+
+	epochs chan *notifier.BlockEpoch
+}
+
+func (s *fakeBlockSource) RegisterBlockEpochNtfn(_ *notifier.BlockEpoch) (*notifier.BlockEpochEvent, error) {
+	return &notifier.BlockEpochEvent{
+		Epochs: s.epochs,
+		Cancel: func() {},
+	}, nil
+}
+
+func (s *fakeBlockSource) notifyTip(c *fakeChain) {
+	height, hash := c.tip()
+	s.epochs <- &notifier.BlockEpoch{Height: int32(height), Hash: hash}
+}
+
+// newTestConfirmationTracker returns tracker which processed tip of the chain,
+// but is not running, so that tests can drive it directly
+func newTestConfirmationTracker(c *fakeChain) *confirmationTracker {
+	t := newConfirmationTracker(c, &fakeBlockSource{}, logrus.New(), metrics.NewStakerMetrics("test"))
+	t.bestHeight, t.bestHash = c.tip()
+	return t
+}
+
+func (c *fakeChain) connectTip(t *testing.T, tracker *confirmationTracker) {
+	height, hash := c.tip()
+	require.NoError(t, tracker.connectBlock(height, hash))
+}
+
+func requireConfirmed(t *testing.T, sub *confirmationSubscription, blockHeight uint32) {
+	select {
+	case conf := <-sub.Confirmed:
+		require.Equal(t, sub.txHash, conf.Tx.TxHash())
+		require.Equal(t, blockHeight, conf.BlockHeight)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("transaction %s was not confirmed", sub.txHash)
+	}
+}
+
+func requireNotConfirmed(t *testing.T, sub *confirmationSubscription) {
+	select {
+	case conf := <-sub.Confirmed:
+		t.Fatalf("unexpected confirmation of transaction %s at height %d", sub.txHash, conf.BlockHeight)
+	default:
+	}
+}
+
+func TestConfirmationTrackerReorg(t *testing.T) {
+	chain := newFakeChain(100)
+	blocks := &fakeBlockSource{epochs: make(chan *notifier.BlockEpoch, 10)}
+	tracker := newConfirmationTracker(chain, blocks, logrus.New(), metrics.NewStakerMetrics("test"))
+
+	blocks.notifyTip(chain)
+	require.NoError(t, tracker.start())
+	defer tracker.stop()
+
+	tx := newTestTx(1)
+	txHash := tx.TxHash()
+	sub := tracker.track(&txHash, nil, 3)
+
+	require.Eventually(t, func() bool {
+		tracker.mu.Lock()
+		defer tracker.mu.Unlock()
+		return sub.verified
+	}, 5*time.Second, 10*time.Millisecond)
+
+	chain.mine(tx)
+	blocks.notifyTip(chain)
+	chain.mine()
+	blocks.notifyTip(chain)
+
+	require.Eventually(t, func() bool {
+		tracker.mu.Lock()
+		defer tracker.mu.Unlock()
+		return tracker.bestHeight == 102
+	}, 5*time.Second, 10*time.Millisecond)
+	requireNotConfirmed(t, sub)
+
+	// confirmed block is replaced, transaction is included again one block later
+	chain.reorg(101)
+	chain.mine()
+	blocks.notifyTip(chain)
+	chain.mine(tx)
+	blocks.notifyTip(chain)
+	chain.mine()
+	blocks.notifyTip(chain)
+
+	require.Eventually(t, func() bool {
+		tracker.mu.Lock()
+		defer tracker.mu.Unlock()
+		return tracker.bestHeight == 103
+	}, 5*time.Second, 10*time.Millisecond)
+	// two confirmations of the new inclusion are not enough
+	requireNotConfirmed(t, sub)
+
+	chain.mine()
+	blocks.notifyTip(chain)
+
+	requireConfirmed(t, sub, 102)
+}
+
+func TestConfirmationTrackerDisconnectsConfirmedTx(t *testing.T) {
+	chain := newFakeChain(100)
+	tracker := newTestConfirmationTracker(chain)
+
+	tx := newTestTx(1)
+	txHash := tx.TxHash()
+	sub := tracker.track(&txHash, nil, 2)
+	tracker.initialCheck()
+
+	chain.mine(tx)
+	chain.connectTip(t, tracker)
+	require.NotNil(t, sub.inclusion)
+	require.Equal(t, uint32(1), <-sub.Updates)
+
+	// reorg at the height of inclusion forgets it
+	chain.reorg(101)
+	chain.mine()
+	chain.connectTip(t, tracker)
+	require.Nil(t, sub.inclusion)
+
+	chain.mine()
+	chain.connectTip(t, tracker)
+	requireNotConfirmed(t, sub)
+
+	chain.mine(tx)
+	chain.connectTip(t, tracker)
+	chain.mine()
+	chain.connectTip(t, tracker)
+	requireConfirmed(t, sub, 103)
+}
+
+func TestConfirmationTrackerSkippedHeights(t *testing.T) {
+	chain := newFakeChain(100)
+	tracker := newTestConfirmationTracker(chain)
+
+	tx := newTestTx(1)
+	txHash := tx.TxHash()
+	sub := tracker.track(&txHash, nil, 4)
+	tracker.initialCheck()
+
+	chain.mine()
+	chain.mine(tx)
+	chain.mine()
+	chain.mine()
+	chain.mine()
+
+	// notification of block 105 arrives right after block 100, missing blocks
+	// are processed in order, each of them retrieved once
+	chain.connectTip(t, tracker)
+	requireConfirmed(t, sub, 102)
+
+	for height := uint32(101); height <= 105; height++ {
+		require.Equal(t, 1, chain.requests(height), "height %d", height)
+	}
+
+	tracker.mu.Lock()
+	require.Equal(t, uint32(105), tracker.bestHeight)
+	require.Empty(t, tracker.subs)
+	tracker.mu.Unlock()
+
+	// missing block which can't be retrieved fails processing of the new one
+	chain.mine()
+	chain.mine()
+	tracker.track(&txHash, nil, 1)
+	chain.getBlockErr = fmt.Errorf("node unavailable")
+	height, hash := chain.tip()
+	require.Error(t, tracker.connectBlock(height, hash))
+
+	tracker.mu.Lock()
+	require.Equal(t, uint32(105), tracker.bestHeight)
+	tracker.mu.Unlock()
+}
+
+func TestConfirmationTrackerInitialCheckOfConfirmedTx(t *testing.T) {
+	chain := newFakeChain(90)
+	confirmedTx := newTestTx(1)
+	chain.mine(confirmedTx)
+	for i := 0; i < 9; i++ {
+		chain.mine()
+	}
+
+	tracker := newTestConfirmationTracker(chain)
+	txHash := confirmedTx.TxHash()
+
+	// transaction included at height 91 has 10 confirmations at height 100
+	deep := tracker.track(&txHash, nil, 10)
+	shallow := tracker.track(&txHash, nil, 12)
+	tracker.initialCheck()
+
+	requireConfirmed(t, deep, 91)
+	requireNotConfirmed(t, shallow)
+	require.Equal(t, uint32(2), <-shallow.Updates)
+	require.Equal(t, 2, chain.txDetailsCalls)
+
+	// subsequent checks do not query node for already checked transactions
+	tracker.initialCheck()
+	require.Equal(t, 2, chain.txDetailsCalls)
+
+	chain.mine()
+	chain.connectTip(t, tracker)
+	requireNotConfirmed(t, shallow)
+	chain.mine()
+	chain.connectTip(t, tracker)
+	requireConfirmed(t, shallow, 91)
+}
+
+func TestConfirmationTrackerScanBlocksAfterRestart(t *testing.T) {
+	chain := newFakeChain(95)
+	tx := newTestTx(1)
+	chain.mine(tx)
+	chain.mine()
+	otherTx := newTestTx(2)
+	chain.mine(otherTx)
+	chain.mine()
+	chain.mine()
+
+	tracker := newTestConfirmationTracker(chain)
+	txHash := tx.TxHash()
+	otherTxHash := otherTx.TxHash()
+
+	// snapshot was taken at height 97, tx was already included at height 96,
+	// otherTx was not found up to height 97
+	blockHash := chain.blocks[96].BlockHash()
+	included := tracker.trackFromSnapshot(&txHash, nil, 5, &notifier.TxConfirmation{
+		BlockHash:   &blockHash,
+		BlockHeight: 96,
+		Tx:          tx,
+	}, 0)
+	resumed := tracker.trackFromSnapshot(&otherTxHash, nil, 3, nil, 97)
+	tracker.initialCheck()
+
+	requireConfirmed(t, included, 96)
+	requireConfirmed(t, resumed, 98)
+
+	// only blocks after snapshot were scanned, each of them once, and node was
+	// not queried for individual transactions
+	for height := uint32(0); height <= 97; height++ {
+		require.Zero(t, chain.requests(height), "height %d", height)
+	}
+	for height := uint32(98); height <= 100; height++ {
+		require.Equal(t, 1, chain.requests(height), "height %d", height)
+	}
+	require.Zero(t, chain.txDetailsCalls)
+}
+
+func TestConfirmationTrackerScanFailureFallsBackToTxDetails(t *testing.T) {
+	chain := newFakeChain(95)
+	tx := newTestTx(1)
+	chain.mine(tx)
+	chain.mine()
+
+	tracker := newTestConfirmationTracker(chain)
+	txHash := tx.TxHash()
+
+	chain.getBlockErr = fmt.Errorf("node unavailable")
+	sub := tracker.trackFromSnapshot(&txHash, nil, 2, nil, 95)
+	tracker.initialCheck()
+
+	require.Equal(t, 1, chain.txDetailsCalls)
+	requireConfirmed(t, sub, 96)
+}
+
+func TestConfirmationTrackerNotifiesOnce(t *testing.T) {
+	chain := newFakeChain(100)
+	tracker := newTestConfirmationTracker(chain)
+
+	tx := newTestTx(1)
+	txHash := tx.TxHash()
+	first := tracker.track(&txHash, nil, 1)
+	second := tracker.track(&txHash, nil, 1)
+	cancelled := tracker.track(&txHash, nil, 1)
+	tracker.initialCheck()
+	cancelled.Cancel()
+
+	chain.mine(tx)
+	chain.connectTip(t, tracker)
+
+	requireConfirmed(t, first, 101)
+	requireConfirmed(t, second, 101)
+	requireNotConfirmed(t, cancelled)
+
+	// confirmed subscriptions are removed, so further blocks and reorgs of
+	// other blocks do not notify them again
+	for i := 0; i < 3; i++ {
+		chain.mine()
+		chain.connectTip(t, tracker)
+	}
+	chain.reorg(103)
+	chain.mine()
+	chain.connectTip(t, tracker)
+	tracker.initialCheck()
+
+	requireNotConfirmed(t, first)
+	requireNotConfirmed(t, second)
+
+	tracker.mu.Lock()
+	require.Empty(t, tracker.subs)
+	tracker.mu.Unlock()
+}
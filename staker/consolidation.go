@@ -0,0 +1,242 @@
+package staker
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/babylonchain/btc-staker/walletcontroller"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/sirupsen/logrus"
+)
+
+// Upper bound on number of inputs in one consolidation transaction, to keep
+// transaction well below standardness size limit
+const maxConsolidationInputs = 500
+
+type ConsolidationResult struct {
+	TxHash         chainhash.Hash
+	NumInputs      int
+	InputsValue    btcutil.Amount
+	Fee            btcutil.Amount
+	ConsolidatedTo btcutil.Address
+}
+
+func (app *StakerApp) smallOutputs(maxUtxoValue btcutil.Amount) ([]walletcontroller.Utxo, error) {
+	utxos, err := app.wc.ListOutputs(true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var small []walletcontroller.Utxo
+	for _, utxo := range utxos {
+		// consolidation would mix blocked or frozen outputs with the rest of the wallet
+		if !app.utxoSelectable(&utxo) {
+			continue
+		}
+
+		if utxo.Amount <= maxUtxoValue {
+			small = append(small, utxo)
+		}
+	}
+
+	// consolidate smallest outputs first
+	sort.Slice(small, func(i, j int) bool {
+		return small[i].Amount < small[j].Amount
+	})
+
+	if len(small) > maxConsolidationInputs {
+		small = small[:maxConsolidationInputs]
+	}
+
+	return small, nil
+}
+
+// ConsolidateOutputs sweeps all spendable wallet outputs with value lower or equal to
+// maxUtxoValue into one output paying to destAddress. If feeRate is nil, fee rate
+// from fee estimator is used.
+func (app *StakerApp) ConsolidateOutputs(
+	destAddress btcutil.Address,
+	maxUtxoValue btcutil.Amount,
+	feeRate *btcutil.Amount,
+) (*ConsolidationResult, error) {
+	// check we are not shutting down
+	select {
+	case <-app.quit:
+		return nil, ErrStakerStopped
+
+	default:
+	}
+
+	if maxUtxoValue <= 0 {
+		return nil, fmt.Errorf("max utxo value must be positive")
+	}
+
+	if err := app.checkWithdrawalDestination(destAddress); err != nil {
+		return nil, fmt.Errorf("cannot consolidate outputs: %w", err)
+	}
+
+	destScript, err := txscript.PayToAddrScript(destAddress)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot build destination script: %w", err)
+	}
+
+	utxos, err := app.smallOutputs(maxUtxoValue)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(utxos) < 2 {
+		return nil, fmt.Errorf("wallet has %d outputs with value lower than %s, nothing to consolidate", len(utxos), maxUtxoValue)
+	}
+
+	var feeRatePerKb btcutil.Amount
+	if feeRate != nil {
+		feeRatePerKb = *feeRate
+	} else {
+		feeRatePerKb = btcutil.Amount(app.feeEstimator.EstimateFeePerKb())
+	}
+
+	if floor := app.mempoolPolicy.feeFloor(); feeRatePerKb < floor {
+		return nil, fmt.Errorf("fee rate %d is lower than minimum fee rate %d", feeRatePerKb, floor)
+	}
+
+	tx, fee, err := walletcontroller.BuildConsolidationTx(app.wc.InputWeights(), utxos, destScript, feeRatePerKb, app.mempoolPolicy.dustRelayFee())
+
+	if err != nil {
+		return nil, err
+	}
+
+	app.applyAntiFeeSniping(tx)
+
+	if err := app.wc.UnlockWallet(defaultWalletUnlockTimeout); err != nil {
+		return nil, err
+	}
+
+	signedTx, signed, err := app.wc.SignRawTransaction(tx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !signed {
+		return nil, fmt.Errorf("not all consolidation transaction inputs could be signed")
+	}
+
+	txHash, err := app.sendRawTransaction(signedTx, true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	app.recordFeeSpend(FeeSpendKindConsolidation, signedTx, fee)
+
+	var inputsValue btcutil.Amount
+	for _, utxo := range utxos {
+		inputsValue += utxo.Amount
+	}
+
+	app.logger.WithFields(logrus.Fields{
+		"txHash":      txHash,
+		"numInputs":   len(utxos),
+		"inputsValue": inputsValue,
+		"fee":         fee,
+		"destAddress": destAddress,
+	}).Info("Sent consolidation transaction")
+
+	return &ConsolidationResult{
+		TxHash:         *txHash,
+		NumInputs:      len(utxos),
+		InputsValue:    inputsValue,
+		Fee:            fee,
+		ConsolidatedTo: destAddress,
+	}, nil
+}
+
+func (app *StakerApp) tryConsolidateOutputs() {
+	cfg := app.config.ConsolidationConfig
+	feeWindowEnabled := app.config.FeeWindowConfig.Enabled
+
+	// estimator returns fee per kvbyte, config is in sat/vbyte
+	currentFeeRate := uint64(app.feeEstimator.EstimateFeePerKb() / 1000)
+
+	if !feeWindowEnabled && currentFeeRate > cfg.MaxFeeRate {
+		app.logger.WithFields(logrus.Fields{
+			"currentFeeRate": currentFeeRate,
+			"maxFeeRate":     cfg.MaxFeeRate,
+		}).Debug("Fee rate too high to consolidate outputs")
+		return
+	}
+
+	utxos, err := app.smallOutputs(btcutil.Amount(cfg.MaxUtxoValue))
+
+	if err != nil {
+		app.logger.WithFields(logrus.Fields{
+			"err": err,
+		}).Error("Failed to list wallet outputs for consolidation")
+		return
+	}
+
+	if len(utxos) < int(cfg.MinUtxos) {
+		app.consolidationWaitingSince = time.Time{}
+		return
+	}
+
+	if feeWindowEnabled {
+		if app.consolidationWaitingSince.IsZero() {
+			app.consolidationWaitingSince = time.Now()
+		}
+
+		logger := app.logger.WithField("numOutputs", len(utxos))
+
+		if !app.nonUrgentTxAllowed(app.consolidationWaitingSince, logger) {
+			return
+		}
+	}
+
+	if !app.automatedFeeSpendAllowed() {
+		return
+	}
+
+	// address was validated when loading config
+	destAddress, err := btcutil.DecodeAddress(cfg.Address, app.network)
+
+	if err != nil {
+		app.logger.WithFields(logrus.Fields{
+			"err": err,
+		}).Error("Invalid consolidation address")
+		return
+	}
+
+	if _, err := app.ConsolidateOutputs(destAddress, btcutil.Amount(cfg.MaxUtxoValue), nil); err != nil {
+		app.logger.WithFields(logrus.Fields{
+			"err": err,
+		}).Error("Failed to consolidate wallet outputs")
+		return
+	}
+
+	app.consolidationWaitingSince = time.Time{}
+}
+
+// consolidateOutputsLoop periodically checks whether wallet contains enough small
+// outputs and consolidates them when fees are low
+func (app *StakerApp) consolidateOutputsLoop(interval time.Duration) {
+	defer app.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			app.tryConsolidateOutputs()
+		case <-app.quit:
+			return
+		}
+	}
+}
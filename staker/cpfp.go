@@ -0,0 +1,224 @@
+package staker
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/babylonchain/btc-staker/walletcontroller"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/sirupsen/logrus"
+)
+
+type CpfpResult struct {
+	StakingTxHash chainhash.Hash
+	ChildTxHash   chainhash.Hash
+	ChildFee      btcutil.Amount
+	// parent fee is unknown if it is not found in the fee spend history, in
+	// that case child pays fee for the whole package
+	ParentFee          *btcutil.Amount
+	SubmittedAsPackage bool
+}
+
+// stakingTxFee returns fee paid by staking transaction sent by the daemon, nil if
+// it is no longer known
+func (app *StakerApp) stakingTxFee(stakingTxHash *chainhash.Hash, createdAt time.Time) (*btcutil.Amount, error) {
+	// fee is recorded right after transaction is sent, which happens before it
+	// is stored
+	spends, err := app.feeSpends.SpendsSince(createdAt.Add(-time.Hour))
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range spends {
+		if s.Kind == FeeSpendKindStaking && s.TxHash == *stakingTxHash {
+			fee := s.Fee
+			return &fee, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// BumpStakingTxFee bumps fee of not yet confirmed staking transaction by sending
+// child transaction which spends change of staking transaction back to the change
+// address. Change paid to cold address can't be spent, as the wallet can't sign
+// for it. Child fee is chosen so that both transactions together pay feeRate.
+// If the node supports package relay, parent and child are submitted together, so
+// that parent paying less than mempool minimum fee can be also rescued. If feeRate
+// is nil, fee rate from fee estimator is used.
+func (app *StakerApp) BumpStakingTxFee(stakingTxHash *chainhash.Hash, feeRate *btcutil.Amount) (*CpfpResult, error) {
+	// check we are not shutting down
+	select {
+	case <-app.quit:
+		return nil, ErrStakerStopped
+
+	default:
+	}
+
+	tx, err := app.txTracker.GetTransaction(stakingTxHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tx.WatchOnly() {
+		return nil, fmt.Errorf("cannot bump fee of watched transaction: %w", ErrInvalidTransactionState)
+	}
+
+	if tx.State != proto.TransactionState_SENT_TO_BTC {
+		return nil, fmt.Errorf("cannot bump fee of transaction which is already confirmed: %w", ErrInvalidTransactionState)
+	}
+
+	stakerAddress, err := btcutil.DecodeAddress(tx.StakerAddress, app.network)
+
+	if err != nil {
+		return nil, fmt.Errorf("error decoding staker address: %s. Err: %v", tx.StakerAddress, err)
+	}
+
+	changeScript, err := txscript.PayToAddrScript(stakerAddress)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot build change script: %w", err)
+	}
+
+	changeIdx := -1
+	for i, out := range tx.StakingTx.TxOut {
+		if uint32(i) != tx.StakingOutputIndex && bytes.Equal(out.PkScript, changeScript) {
+			changeIdx = i
+			break
+		}
+	}
+
+	// change may be paid to other address, depending on configured change
+	// destination. Staking transactions created by the daemon have at most one
+	// output besides staking output.
+	if changeIdx < 0 && len(tx.StakingTx.TxOut) == 2 {
+		changeIdx = 1 - int(tx.StakingOutputIndex)
+		changeScript = tx.StakingTx.TxOut[changeIdx].PkScript
+	}
+
+	if changeIdx < 0 {
+		return nil, fmt.Errorf("staking transaction does not have change output which could pay for child transaction: %w", ErrInvalidTransactionState)
+	}
+
+	var feeRatePerKb btcutil.Amount
+	if feeRate != nil {
+		feeRatePerKb = *feeRate
+	} else {
+		feeRatePerKb = btcutil.Amount(app.feeEstimator.EstimateFeePerKb())
+	}
+
+	if floor := app.mempoolPolicy.feeFloor(); feeRatePerKb < floor {
+		return nil, fmt.Errorf("fee rate %d is lower than minimum fee rate %d", feeRatePerKb, floor)
+	}
+
+	parentFee, err := app.stakingTxFee(stakingTxHash, tx.CreatedAt())
+
+	if err != nil {
+		return nil, err
+	}
+
+	var knownParentFee btcutil.Amount
+	if parentFee != nil {
+		knownParentFee = *parentFee
+	}
+
+	change := walletcontroller.Utxo{
+		Amount:   btcutil.Amount(tx.StakingTx.TxOut[changeIdx].Value),
+		OutPoint: *wire.NewOutPoint(stakingTxHash, uint32(changeIdx)),
+		PkScript: changeScript,
+	}
+
+	child, fee, err := walletcontroller.BuildCpfpTx(
+		app.wc.InputWeights(),
+		change,
+		mempool.GetTxVirtualSize(btcutil.NewTx(tx.StakingTx)),
+		knownParentFee,
+		changeScript,
+		feeRatePerKb,
+		app.mempoolPolicy.dustRelayFee(),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	app.applyAntiFeeSniping(child)
+
+	if err := app.wc.UnlockWallet(defaultWalletUnlockTimeout); err != nil {
+		return nil, err
+	}
+
+	signedChild, signed, err := app.wc.SignRawTransaction(child)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !signed {
+		return nil, fmt.Errorf("child transaction input could not be signed")
+	}
+
+	packageRelay, err := app.wc.SupportsPackageRelay()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to check package relay support: %w", err)
+	}
+
+	if packageRelay {
+		if err := app.wc.SubmitPackage(tx.StakingTx, signedChild); err != nil {
+			return nil, err
+		}
+	} else {
+		// without package relay parent must be accepted on its own. It is
+		// resent in case it was evicted from mempool, error means it is either
+		// already in mempool or it must be rebroadcast with the child by other
+		// means
+		if _, err := app.wc.SendRawTransaction(tx.StakingTx, true); err != nil {
+			app.logger.WithFields(logrus.Fields{
+				"stakingTxHash": stakingTxHash,
+				"err":           err,
+			}).Debug("Staking transaction not resent before sending child transaction")
+		}
+
+		if _, err := app.sendRawTransaction(signedChild, true); err != nil {
+			return nil, fmt.Errorf("node does not support package relay and child transaction was rejected: %w", err)
+		}
+	}
+
+	childHash := signedChild.TxHash()
+
+	app.recordFeeSpend(FeeSpendKindCpfp, signedChild, fee)
+
+	// child is already sent, so failure to record it is only logged
+	if err := app.txTracker.AddTxCpfpChild(stakingTxHash, &childHash); err != nil {
+		app.logger.WithFields(logrus.Fields{
+			"stakingTxHash": stakingTxHash,
+			"childTxHash":   childHash,
+			"err":           err,
+		}).Error("Failed to record child transaction of staking transaction")
+	}
+
+	app.logger.WithFields(logrus.Fields{
+		"stakingTxHash":      stakingTxHash,
+		"childTxHash":        childHash,
+		"childFee":           fee,
+		"feeRate":            feeRatePerKb,
+		"submittedAsPackage": packageRelay,
+	}).Info("Sent child transaction paying for staking transaction")
+
+	return &CpfpResult{
+		StakingTxHash:      *stakingTxHash,
+		ChildTxHash:        childHash,
+		ChildFee:           fee,
+		ParentFee:          parentFee,
+		SubmittedAsPackage: packageRelay,
+	}, nil
+}
@@ -0,0 +1,160 @@
+package staker
+
+import (
+	"runtime"
+	"sort"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// EventChannelState is number of events waiting in the channel of event loop
+type EventChannelState struct {
+	Name     string `json:"name"`
+	Len      int    `json:"len"`
+	Capacity int    `json:"capacity"`
+}
+
+// ConfirmationSubscriptionState is transaction waiting for confirmations
+type ConfirmationSubscriptionState struct {
+	TxHash        chainhash.Hash `json:"tx_hash"`
+	NumConfs      uint32         `json:"num_confs"`
+	Subscriptions int            `json:"subscriptions"`
+}
+
+// RetryOperationState is retried operation which is currently executing
+type RetryOperationState struct {
+	Operation string         `json:"operation"`
+	TxHash    chainhash.Hash `json:"tx_hash"`
+}
+
+// DebugState is snapshot of in memory queues and pending operations of the
+// daemon, used to diagnose stalls. Persisted state is available through regular
+// rpc endpoints.
+type DebugState struct {
+	NumGoroutines   int                 `json:"num_goroutines"`
+	BestBlockHeight uint32              `json:"best_block_height"`
+	EventChannels   []EventChannelState `json:"event_channels"`
+	// staking transactions sent to btc and not confirmed yet
+	UnconfirmedStakingTxs []chainhash.Hash `json:"unconfirmed_staking_txs"`
+	// slots reserved by staking requests which did not send transaction yet
+	ReservedUnconfirmedSlots int                             `json:"reserved_unconfirmed_slots"`
+	MaxUnconfirmedStakingTxs int                             `json:"max_unconfirmed_staking_txs"`
+	ConfirmationsBestHeight  uint32                          `json:"confirmations_best_height"`
+	ConfirmationTxs          []ConfirmationSubscriptionState `json:"confirmation_txs"`
+	RunningRetryOperations   []RetryOperationState           `json:"running_retry_operations"`
+	// delegations polled on babylon for covenant signatures
+	PendingUnbondingSigs []chainhash.Hash `json:"pending_unbonding_sigs"`
+	// delegations of external staker keys polled for registration on babylon
+	PendingExternalDelegations []chainhash.Hash `json:"pending_external_delegations"`
+	UnbondAllRunning           bool             `json:"unbond_all_running"`
+	NewBlockListeners          int              `json:"new_block_listeners"`
+}
+
+func sortedHashes(hashes map[chainhash.Hash]struct{}) []chainhash.Hash {
+	sorted := make([]chainhash.Hash, 0, len(hashes))
+
+	for h := range hashes {
+		sorted = append(sorted, h)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].String() < sorted[j].String()
+	})
+
+	return sorted
+}
+
+func (l *unconfirmedTxLimiter) debugState() ([]chainhash.Hash, int, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return sortedHashes(l.pending), l.reserved, l.max
+}
+
+func (t *confirmationTracker) debugState() (uint32, []ConfirmationSubscriptionState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	txs := make([]ConfirmationSubscriptionState, 0, len(t.subs))
+
+	for txHash, subs := range t.subs {
+		s := ConfirmationSubscriptionState{
+			TxHash:        txHash,
+			Subscriptions: len(subs),
+		}
+
+		for _, sub := range subs {
+			if sub.numConfs > s.NumConfs {
+				s.NumConfs = sub.numConfs
+			}
+		}
+
+		txs = append(txs, s)
+	}
+
+	sort.Slice(txs, func(i, j int) bool {
+		return txs[i].TxHash.String() < txs[j].TxHash.String()
+	})
+
+	return t.bestHeight, txs
+}
+
+func (q *retryQueue) debugState() []RetryOperationState {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ops := make([]RetryOperationState, 0, len(q.running))
+
+	for k := range q.running {
+		ops = append(ops, RetryOperationState{
+			Operation: k.op.String(),
+			TxHash:    k.txHash,
+		})
+	}
+
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].Operation != ops[j].Operation {
+			return ops[i].Operation < ops[j].Operation
+		}
+		return ops[i].TxHash.String() < ops[j].TxHash.String()
+	})
+
+	return ops
+}
+
+func (p *delegationPoller) debugState() []chainhash.Hash {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return sortedHashes(p.pending)
+}
+
+// DebugState returns snapshot of in memory state of the daemon
+func (app *StakerApp) DebugState() *DebugState {
+	state := &DebugState{
+		NumGoroutines:   runtime.NumGoroutine(),
+		BestBlockHeight: app.currentBestBlockHeight.Load(),
+		EventChannels: []EventChannelState{
+			{Name: "stakingRequested", Len: len(app.stakingRequestedEvChan), Capacity: cap(app.stakingRequestedEvChan)},
+			{Name: "stakingTxBtcConfirmed", Len: len(app.stakingTxBtcConfirmedEvChan), Capacity: cap(app.stakingTxBtcConfirmedEvChan)},
+			{Name: "delegationSubmittedToBabylon", Len: len(app.delegationSubmittedToBabylonEvChan), Capacity: cap(app.delegationSubmittedToBabylonEvChan)},
+			{Name: "unbondingTxSignaturesConfirmedOnBabylon", Len: len(app.unbondingTxSignaturesConfirmedOnBabylonEvChan), Capacity: cap(app.unbondingTxSignaturesConfirmedOnBabylonEvChan)},
+			{Name: "unbondingTxConfirmedOnBtc", Len: len(app.unbondingTxConfirmedOnBtcEvChan), Capacity: cap(app.unbondingTxConfirmedOnBtcEvChan)},
+			{Name: "spendStakeTxConfirmedOnBtc", Len: len(app.spendStakeTxConfirmedOnBtcEvChan), Capacity: cap(app.spendStakeTxConfirmedOnBtcEvChan)},
+			{Name: "criticalError", Len: len(app.criticalErrorEvChan), Capacity: cap(app.criticalErrorEvChan)},
+		},
+		UnbondAllRunning: app.unbondAllRunning.Load(),
+	}
+
+	state.UnconfirmedStakingTxs, state.ReservedUnconfirmedSlots, state.MaxUnconfirmedStakingTxs = app.unconfirmedTxs.debugState()
+	state.ConfirmationsBestHeight, state.ConfirmationTxs = app.confTracker.debugState()
+	state.RunningRetryOperations = app.retryQueue.debugState()
+	state.PendingUnbondingSigs = app.unbondingSigsPoller.debugState()
+	state.PendingExternalDelegations = app.externalDelegationPoller.debugState()
+
+	app.newBlockListenersMu.Lock()
+	state.NewBlockListeners = len(app.newBlockListeners)
+	app.newBlockListenersMu.Unlock()
+
+	return state
+}
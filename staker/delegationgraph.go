@@ -0,0 +1,231 @@
+package staker
+
+import (
+	"fmt"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+const (
+	GraphNodeFunding           = "funding"
+	GraphNodeStaking           = "staking"
+	GraphNodeCpfpChild         = "cpfp_child"
+	GraphNodeUnbonding         = "unbonding"
+	GraphNodeSlashing          = "slashing"
+	GraphNodeUnbondingSlashing = "unbonding_slashing"
+	GraphNodeWithdrawal        = "withdrawal"
+
+	// transaction is confirmed on btc
+	GraphTxStatusConfirmed = "confirmed"
+	// transaction was sent by the daemon and is not yet confirmed
+	GraphTxStatusUnconfirmed = "unconfirmed"
+	// transaction is signed for the delegation, but the daemon does not know
+	// whether it was ever broadcast
+	GraphTxStatusPresigned = "presigned"
+	GraphTxStatusUnknown   = "unknown"
+)
+
+// DelegationGraphNode is one transaction of the delegation
+type DelegationGraphNode struct {
+	TxHash chainhash.Hash
+	Kind   string
+	Status string
+	// height of block including the transaction, 0 if unknown
+	BlockHeight uint32
+}
+
+// DelegationGraphEdge describes transaction To spending output of transaction
+// From
+type DelegationGraphEdge struct {
+	From chainhash.Hash
+	To   chainhash.Hash
+	// index of spent output of From, nil if unknown
+	OutputIndex *uint32
+}
+
+// DelegationGraph describes how transactions of the delegation spend each other,
+// from transactions funding the staking transaction to the transaction which
+// withdrew staked funds. Unbonding and slashing transactions spend the same
+// outputs, so at most one of them can be confirmed.
+type DelegationGraph struct {
+	StakingTxHash chainhash.Hash
+	State         proto.TransactionState
+	Nodes         []DelegationGraphNode
+	Edges         []DelegationGraphEdge
+}
+
+func (g *DelegationGraph) addNode(node DelegationGraphNode) {
+	for _, n := range g.Nodes {
+		if n.TxHash == node.TxHash {
+			return
+		}
+	}
+
+	g.Nodes = append(g.Nodes, node)
+}
+
+func (g *DelegationGraph) addEdge(from, to chainhash.Hash, outputIndex *uint32) {
+	g.Edges = append(g.Edges, DelegationGraphEdge{
+		From:        from,
+		To:          to,
+		OutputIndex: outputIndex,
+	})
+}
+
+// buildDelegationGraph builds graph from hashes recorded with the delegation.
+// Slashing hashes of watched delegations are taken from watched data if they
+// were not recorded, watchedData is nil for other delegations.
+func buildDelegationGraph(
+	storedTx *stakerdb.StoredTransaction,
+	watchedData *stakerdb.WatchedTransactionData,
+) *DelegationGraph {
+	stakingTxHash := storedTx.StakingTx.TxHash()
+	stakingConfirmed := storedTx.StakingTxConfirmationInfo != nil
+
+	g := &DelegationGraph{
+		StakingTxHash: stakingTxHash,
+		State:         storedTx.State,
+	}
+
+	fundingStatus := GraphTxStatusUnknown
+	if stakingConfirmed {
+		// inputs of confirmed transaction must be confirmed
+		fundingStatus = GraphTxStatusConfirmed
+	}
+
+	for _, in := range storedTx.StakingTx.TxIn {
+		outputIndex := in.PreviousOutPoint.Index
+
+		g.addNode(DelegationGraphNode{
+			TxHash: in.PreviousOutPoint.Hash,
+			Kind:   GraphNodeFunding,
+			Status: fundingStatus,
+		})
+		g.addEdge(in.PreviousOutPoint.Hash, stakingTxHash, &outputIndex)
+	}
+
+	stakingNode := DelegationGraphNode{
+		TxHash: stakingTxHash,
+		Kind:   GraphNodeStaking,
+		Status: GraphTxStatusUnconfirmed,
+	}
+
+	if stakingConfirmed {
+		stakingNode.Status = GraphTxStatusConfirmed
+		stakingNode.BlockHeight = storedTx.StakingTxConfirmationInfo.Height
+	}
+
+	g.addNode(stakingNode)
+
+	childStatus := GraphTxStatusUnconfirmed
+	if stakingConfirmed {
+		// child could have been confirmed with the parent or replaced
+		childStatus = GraphTxStatusUnknown
+	}
+
+	for _, childHash := range storedTx.CpfpChildTxHashes {
+		g.addNode(DelegationGraphNode{
+			TxHash: childHash,
+			Kind:   GraphNodeCpfpChild,
+			Status: childStatus,
+		})
+		// child spends change output, which is not recorded
+		g.addEdge(stakingTxHash, childHash, nil)
+	}
+
+	stakingOutputIndex := storedTx.StakingOutputIndex
+	// unbonding transaction has only one output
+	unbondingOutputIndex := uint32(0)
+
+	slashingTxHash := storedTx.SlashingTxHash
+	unbondingSlashingTxHash := storedTx.UnbondingSlashingTxHash
+
+	if slashingTxHash == nil && watchedData != nil {
+		watchedSlashingTxHash := watchedData.SlashingTx.TxHash()
+		watchedUnbondingSlashingTxHash := watchedData.SlashingUnbondingTx.TxHash()
+		slashingTxHash = &watchedSlashingTxHash
+		unbondingSlashingTxHash = &watchedUnbondingSlashingTxHash
+	}
+
+	if slashingTxHash != nil {
+		g.addNode(DelegationGraphNode{
+			TxHash: *slashingTxHash,
+			Kind:   GraphNodeSlashing,
+			Status: GraphTxStatusPresigned,
+		})
+		g.addEdge(stakingTxHash, *slashingTxHash, &stakingOutputIndex)
+	}
+
+	var unbondingTxHash *chainhash.Hash
+	unbondingConfirmed := false
+
+	if storedTx.UnbondingTxData != nil && storedTx.UnbondingTxData.UnbondingTx != nil {
+		hash := storedTx.UnbondingTxData.UnbondingTx.TxHash()
+		unbondingTxHash = &hash
+
+		unbondingNode := DelegationGraphNode{
+			TxHash: hash,
+			Kind:   GraphNodeUnbonding,
+			Status: GraphTxStatusPresigned,
+		}
+
+		if confInfo := storedTx.UnbondingTxData.UnbondingTxConfirmationInfo; confInfo != nil {
+			unbondingConfirmed = true
+			unbondingNode.Status = GraphTxStatusConfirmed
+			unbondingNode.BlockHeight = confInfo.Height
+		}
+
+		g.addNode(unbondingNode)
+		g.addEdge(stakingTxHash, hash, &stakingOutputIndex)
+
+		if unbondingSlashingTxHash != nil {
+			g.addNode(DelegationGraphNode{
+				TxHash: *unbondingSlashingTxHash,
+				Kind:   GraphNodeUnbondingSlashing,
+				Status: GraphTxStatusPresigned,
+			})
+			g.addEdge(hash, *unbondingSlashingTxHash, &unbondingOutputIndex)
+		}
+	}
+
+	if storedTx.SpendTxHash != nil {
+		g.addNode(DelegationGraphNode{
+			TxHash: *storedTx.SpendTxHash,
+			Kind:   GraphNodeWithdrawal,
+			Status: GraphTxStatusConfirmed,
+		})
+
+		if unbondingConfirmed {
+			g.addEdge(*unbondingTxHash, *storedTx.SpendTxHash, &unbondingOutputIndex)
+		} else {
+			g.addEdge(stakingTxHash, *storedTx.SpendTxHash, &stakingOutputIndex)
+		}
+	}
+
+	return g
+}
+
+// DelegationGraph returns transactions of the delegation and how they spend each
+// other. Transactions which were not recorded by the daemon, like withdrawals
+// made before withdrawal hashes were tracked, are missing from the graph.
+func (app *StakerApp) DelegationGraph(stakingTxHash *chainhash.Hash) (*DelegationGraph, error) {
+	storedTx, err := app.txTracker.GetTransaction(stakingTxHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var watchedData *stakerdb.WatchedTransactionData
+
+	if storedTx.Watched && storedTx.SlashingTxHash == nil {
+		watchedData, err = app.txTracker.GetWatchedTransactionData(stakingTxHash)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to get watched data of delegation: %w", err)
+		}
+	}
+
+	return buildDelegationGraph(storedTx, watchedData), nil
+}
@@ -0,0 +1,102 @@
+package staker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/babylonchain/btc-staker/utils"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// delegationCheck checks status of single delegation on babylon and returns true
+// if delegation does not need to be polled anymore
+type delegationCheck func(stakingTxHash *chainhash.Hash) bool
+
+// delegationPoller periodically checks all pending delegations of one kind on
+// babylon. Instead of running separate ticker for every delegation, each round
+// checks all pending delegations in parallel by bounded number of workers.
+type delegationPoller struct {
+	interval time.Duration
+	workers  int
+	// prepare is called once per round, so that queries common for all
+	// delegations i.e babylon params are done only once. If it returns false
+	// the round is skipped.
+	prepare func() (delegationCheck, bool)
+
+	mu      sync.Mutex
+	pending map[chainhash.Hash]struct{}
+}
+
+func newDelegationPoller(
+	interval time.Duration,
+	workers uint32,
+	prepare func() (delegationCheck, bool),
+) *delegationPoller {
+	return &delegationPoller{
+		interval: interval,
+		workers:  int(workers),
+		prepare:  prepare,
+		pending:  make(map[chainhash.Hash]struct{}),
+	}
+}
+
+func (p *delegationPoller) add(stakingTxHash *chainhash.Hash) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pending[*stakingTxHash] = struct{}{}
+}
+
+func (p *delegationPoller) remove(stakingTxHash *chainhash.Hash) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.pending, *stakingTxHash)
+}
+
+func (p *delegationPoller) pendingHashes() []chainhash.Hash {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	hashes := make([]chainhash.Hash, 0, len(p.pending))
+	for h := range p.pending {
+		hashes = append(hashes, h)
+	}
+
+	return hashes
+}
+
+func (p *delegationPoller) poll(quit <-chan struct{}) {
+	hashes := p.pendingHashes()
+
+	if len(hashes) == 0 {
+		return
+	}
+
+	check, ok := p.prepare()
+
+	if !ok {
+		return
+	}
+
+	utils.ParallelForEach(hashes, p.workers, quit, func(h chainhash.Hash) {
+		if check(&h) {
+			p.remove(&h)
+		}
+	})
+}
+
+// run must be started as goroutine after incrementing app wait group
+func (p *delegationPoller) run(wg *sync.WaitGroup, quit <-chan struct{}) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.poll(quit)
+		case <-quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package staker
+
+import (
+	"fmt"
+
+	staking "github.com/babylonchain/babylon/btcstaking"
+	cl "github.com/babylonchain/btc-staker/babylonclient"
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/babylonchain/btc-staker/utils"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/sirupsen/logrus"
+)
+
+// Methods in this file are meant to be used only in tests and private deployments
+// which run their own covenant committee. They make it possible to provide covenant
+// signatures directly to the staker instead of waiting for them to appear on Babylon.
+//
+// Only covenant signatures over the unbonding path of the staking output are
+// supported, as those are the only covenant signatures the staker itself needs to
+// spend its funds. Adaptor signatures over slashing transactions are consumed only
+// by Babylon, and the covenant committee signs with separate keys under
+// OP_CHECKSIGADD quorum, so there are no partial (aggregated) signatures to combine.
+// Each covenant signature must be full BIP340 signature over the unbonding sighash.
+
+// UnbondingSigHashInfo contains all the data covenant member needs to sign
+// unbonding transaction of given delegation
+type UnbondingSigHashInfo struct {
+	UnbondingTx         *wire.MsgTx
+	UnbondingPathScript []byte
+	SigHash             []byte
+	CovenantPks         []*btcec.PublicKey
+	CovenantQuorum      uint32
+}
+
+// scriptPathSigHash returns sighash of the only input of the tx, spending
+// funding output through given script path
+func scriptPathSigHash(
+	tx *wire.MsgTx,
+	fundingOutput *wire.TxOut,
+	spendPathInfo *staking.SpendInfo,
+) ([]byte, error) {
+	fetcher := txscript.NewCannedPrevOutputFetcher(
+		fundingOutput.PkScript,
+		fundingOutput.Value,
+	)
+
+	sigHashes := txscript.NewTxSigHashes(tx, fetcher)
+
+	return txscript.CalcTapscriptSignaturehash(
+		sigHashes,
+		txscript.SigHashDefault,
+		tx,
+		0,
+		fetcher,
+		spendPathInfo.RevealedLeaf,
+	)
+}
+
+func (app *StakerApp) unbondingSigHashInfo(tx *stakerdb.StoredTransaction) (*UnbondingSigHashInfo, error) {
+	if tx.UnbondingTxData == nil || tx.UnbondingTxData.UnbondingTx == nil {
+		return nil, fmt.Errorf("staking transaction does not have unbonding transaction yet. Current state: %s: %w", tx.State, ErrInvalidTransactionState)
+	}
+
+	stakerPubKey, err := app.stakerPubKeyForTx(tx)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot retrieve staker public key: %w", err)
+	}
+
+	params, err := app.babylonClient.Params()
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting params: %w", err)
+	}
+
+	scriptsInfo, err := buildStakingScriptsInfo(
+		stakerPubKey,
+		params.CovenantPks,
+		params.CovenantQuruomThreshold,
+		tx,
+		app.scriptBuilder,
+		app.network,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sigHash, err := scriptPathSigHash(
+		tx.UnbondingTxData.UnbondingTx,
+		scriptsInfo.StakingOutput,
+		scriptsInfo.UnbondingPath,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate unbonding tx sighash: %w", err)
+	}
+
+	return &UnbondingSigHashInfo{
+		UnbondingTx:         tx.UnbondingTxData.UnbondingTx,
+		UnbondingPathScript: scriptsInfo.UnbondingPath.RevealedLeaf.Script,
+		SigHash:             sigHash,
+		CovenantPks:         params.CovenantPks,
+		CovenantQuorum:      params.CovenantQuruomThreshold,
+	}, nil
+}
+
+// UnbondingTxSigHash returns sighash of the unbonding transaction of the delegation
+// which must be signed by covenant committee members
+func (app *StakerApp) UnbondingTxSigHash(stakingTxHash *chainhash.Hash) (*UnbondingSigHashInfo, error) {
+	tx, err := app.txTracker.GetTransaction(stakingTxHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return app.unbondingSigHashInfo(tx)
+}
+
+// SubmitCovenantUnbondingSignatures validates provided covenant signatures over
+// unbonding transaction and treats them as if they were received from Babylon
+// i.e delegation becomes active and can be unbonded.
+func (app *StakerApp) SubmitCovenantUnbondingSignatures(
+	stakingTxHash *chainhash.Hash,
+	signatures []cl.CovenantSignatureInfo,
+) error {
+	tx, err := app.txTracker.GetTransaction(stakingTxHash)
+
+	if err != nil {
+		return err
+	}
+
+	if tx.State != proto.TransactionState_SENT_TO_BABYLON {
+		return fmt.Errorf("cannot submit covenant signatures. Staking transaction is in invalid state: %s: %w", tx.State, ErrInvalidTransactionState)
+	}
+
+	info, err := app.unbondingSigHashInfo(tx)
+
+	if err != nil {
+		return err
+	}
+
+	if len(signatures) < int(info.CovenantQuorum) {
+		return fmt.Errorf("not enough covenant signatures. Required: %d, received: %d", info.CovenantQuorum, len(signatures))
+	}
+
+	covenantKeys := make(map[string]struct{})
+	for _, pk := range info.CovenantPks {
+		covenantKeys[pubKeyToString(pk)] = struct{}{}
+	}
+
+	seenKeys := make(map[string]struct{})
+	for _, sig := range signatures {
+		key := pubKeyToString(sig.PubKey)
+
+		if _, found := covenantKeys[key]; !found {
+			return fmt.Errorf("public key %s is not a member of covenant committee", key)
+		}
+
+		if _, found := seenKeys[key]; found {
+			return fmt.Errorf("duplicated signature for covenant member %s", key)
+		}
+		seenKeys[key] = struct{}{}
+
+		if !sig.Signature.Verify(info.SigHash, sig.PubKey) {
+			return fmt.Errorf("invalid unbonding tx signature for covenant member %s, signature must be full BIP340 signature over unbonding path sighash", key)
+		}
+	}
+
+	app.logger.WithFields(logrus.Fields{
+		"stakingTxHash": stakingTxHash,
+		"numSignatures": len(signatures),
+	}).Info("Received covenant unbonding signatures through developer api")
+
+	req := &unbondingTxSignaturesConfirmedOnBabylonEvent{
+		stakingTxHash:               *stakingTxHash,
+		covenantUnbondingSignatures: signatures,
+	}
+
+	utils.PushOrQuit[*unbondingTxSignaturesConfirmedOnBabylonEvent](
+		app.unbondingTxSignaturesConfirmedOnBabylonEvChan,
+		req,
+		app.quit,
+	)
+
+	return nil
+}
+
+// SignedUnbondingTx assembles full witness of the unbonding transaction from
+// received covenant signatures and staker signature, and returns signed unbonding
+// transaction without sending it to btc.
+func (app *StakerApp) SignedUnbondingTx(stakingTxHash *chainhash.Hash) (*wire.MsgTx, error) {
+	tx, err := app.txTracker.GetTransaction(stakingTxHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tx.WatchOnly() {
+		return nil, fmt.Errorf("cannot sign unbonding transaction of watched staking transaction: %w", ErrInvalidTransactionState)
+	}
+
+	if tx.UnbondingTxData == nil {
+		return nil, fmt.Errorf("staking transaction does not have unbonding transaction yet. Current state: %s: %w", tx.State, ErrInvalidTransactionState)
+	}
+
+	stakerAddress, err := btcutil.DecodeAddress(tx.StakerAddress, app.network)
+
+	if err != nil {
+		return nil, fmt.Errorf("error decoding staker address: %w", err)
+	}
+
+	privKey, err := app.stakerPrivateKey(stakerAddress)
+
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := app.babylonClient.Params()
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting params: %w", err)
+	}
+
+	witness, err := createWitnessToSendUnbondingTx(
+		privKey,
+		tx,
+		tx.UnbondingTxData,
+		params,
+		app.scriptBuilder,
+		app.network,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	signedTx := tx.UnbondingTxData.UnbondingTx.Copy()
+	signedTx.TxIn[0].Witness = witness
+
+	return signedTx, nil
+}
@@ -0,0 +1,25 @@
+package staker
+
+import "errors"
+
+var (
+	// ErrInvalidStakingRequest is returned when staking request is malformed or
+	// does not meet current babylon staking parameters
+	ErrInvalidStakingRequest = errors.New("invalid staking request")
+
+	// ErrInvalidTransactionState is returned when requested operation is not
+	// allowed in current state of staking transaction
+	ErrInvalidTransactionState = errors.New("operation not allowed in current state of staking transaction")
+
+	// ErrRejectedByPolicy is returned when configured policy service did not
+	// approve the request
+	ErrRejectedByPolicy = errors.New("request rejected by policy service")
+
+	// ErrDestinationNotWhitelisted is returned when funds would be sent to
+	// address outside of configured withdrawal whitelist
+	ErrDestinationNotWhitelisted = errors.New("destination is not in withdrawal whitelist")
+
+	// ErrStakerStopped is returned when request could not be completed because
+	// staker app is shutting down
+	ErrStakerStopped = errors.New("staker app is stopped")
+)
@@ -37,6 +37,11 @@ type stakingRequestedEvent struct {
 	requiredDepthOnBtcChain uint32
 	pop                     *cl.BabylonPop
 	watchTxData             *watchTxData
+	metadata                map[string]string
+	stakingTxFee            btcutil.Amount
+	externalStakerBtcPk     *btcec.PublicKey
+	unconfirmedSlot         *unconfirmedTxSlot
+	requestId               string
 	errChan                 chan error
 	successChan             chan *chainhash.Hash
 }
@@ -45,6 +50,10 @@ func (req *stakingRequestedEvent) isWatched() bool {
 	return req.watchTxData != nil
 }
 
+func (req *stakingRequestedEvent) isExternalKey() bool {
+	return req.externalStakerBtcPk != nil
+}
+
 func newOwnedStakingRequest(
 	stakerAddress btcutil.Address,
 	stakingTx *wire.MsgTx,
@@ -55,6 +64,8 @@ func newOwnedStakingRequest(
 	fpBtcPks []*btcec.PublicKey,
 	confirmationTimeBlocks uint32,
 	pop *cl.BabylonPop,
+	metadata map[string]string,
+	stakingTxFee btcutil.Amount,
 ) *stakingRequestedEvent {
 	return &stakingRequestedEvent{
 		stakerAddress:           stakerAddress,
@@ -68,6 +79,43 @@ func newOwnedStakingRequest(
 		requiredDepthOnBtcChain: confirmationTimeBlocks,
 		pop:                     pop,
 		watchTxData:             nil,
+		metadata:                metadata,
+		stakingTxFee:            stakingTxFee,
+		errChan:                 make(chan error, 1),
+		successChan:             make(chan *chainhash.Hash, 1),
+	}
+}
+
+// newExternalKeyStakingRequest creates request to send staking transaction funded
+// by connected wallet, which staker key is controlled by external party
+func newExternalKeyStakingRequest(
+	fundingAddress btcutil.Address,
+	stakingTx *wire.MsgTx,
+	stakingOutputIdx uint32,
+	stakingOutputPkScript []byte,
+	stakingTime uint16,
+	stakingValue btcutil.Amount,
+	fpBtcPks []*btcec.PublicKey,
+	confirmationTimeBlocks uint32,
+	stakerBtcPk *btcec.PublicKey,
+	metadata map[string]string,
+	stakingTxFee btcutil.Amount,
+) *stakingRequestedEvent {
+	return &stakingRequestedEvent{
+		stakerAddress:           fundingAddress,
+		stakingTxHash:           stakingTx.TxHash(),
+		stakingTx:               stakingTx,
+		stakingOutputIdx:        stakingOutputIdx,
+		stakingOutputPkScript:   stakingOutputPkScript,
+		stakingTime:             stakingTime,
+		stakingValue:            stakingValue,
+		fpBtcPks:                fpBtcPks,
+		requiredDepthOnBtcChain: confirmationTimeBlocks,
+		pop:                     nil,
+		watchTxData:             nil,
+		metadata:                metadata,
+		stakingTxFee:            stakingTxFee,
+		externalStakerBtcPk:     stakerBtcPk,
 		errChan:                 make(chan error, 1),
 		successChan:             make(chan *chainhash.Hash, 1),
 	}
@@ -103,6 +151,7 @@ func newWatchedStakingRequest(
 	slashUnbondingTx *wire.MsgTx,
 	slashUnbondingTxSig *schnorr.Signature,
 	unbondingTime uint16,
+	metadata map[string]string,
 ) *stakingRequestedEvent {
 	return &stakingRequestedEvent{
 		stakerAddress:           stakerAddress,
@@ -125,6 +174,7 @@ func newWatchedStakingRequest(
 			slashUnbondingTxSig: slashUnbondingTxSig,
 			unbondingTime:       unbondingTime,
 		},
+		metadata:    metadata,
 		errChan:     make(chan error, 1),
 		successChan: make(chan *chainhash.Hash, 1),
 	}
@@ -160,6 +210,10 @@ type delegationSubmittedToBabylonEvent struct {
 	stakingTxHash chainhash.Hash
 	unbondingTx   *wire.MsgTx
 	unbondingTime uint16
+	// nil if delegation was found on babylon instead of being sent by the
+	// daemon, as babylon does not return slashing transactions
+	slashingTxHash          *chainhash.Hash
+	unbondingSlashingTxHash *chainhash.Hash
 }
 
 func (event *delegationSubmittedToBabylonEvent) EventId() chainhash.Hash {
@@ -199,6 +253,8 @@ func (event *unbondingTxConfirmedOnBtcEvent) EventDesc() string {
 
 type spendStakeTxConfirmedOnBtcEvent struct {
 	stakingTxHash chainhash.Hash
+	spendTxHash   chainhash.Hash
+	spendTxFee    btcutil.Amount
 }
 
 func (event *spendStakeTxConfirmedOnBtcEvent) EventId() chainhash.Hash {
@@ -225,14 +281,16 @@ func (event *criticalErrorEvent) EventDesc() string {
 
 func (app *StakerApp) logStakingEventReceived(event StakingEvent) {
 	app.logger.WithFields(logrus.Fields{
-		"eventId": event.EventId(),
-		"event":   event.EventDesc(),
+		"eventId":   event.EventId(),
+		"event":     event.EventDesc(),
+		"requestId": app.requestIdOf(event),
 	}).Debug("Received staking event")
 }
 
 func (app *StakerApp) logStakingEventProcessed(event StakingEvent) {
 	app.logger.WithFields(logrus.Fields{
-		"eventId": event.EventId(),
-		"event":   event.EventDesc(),
+		"eventId":   event.EventId(),
+		"event":     event.EventDesc(),
+		"requestId": app.requestIdOf(event),
 	}).Debug("Processed staking event")
 }
@@ -0,0 +1,435 @@
+package staker
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	staking "github.com/babylonchain/babylon/btcstaking"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/babylonchain/btc-staker/utils"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ExitTxTypeStakingTimeLockSpend   = "staking_timelock_spend"
+	ExitTxTypeUnbonding              = "unbonding"
+	ExitTxTypeUnbondingTimeLockSpend = "unbonding_timelock_spend"
+)
+
+// ExitTxTemplate is unsigned transaction spending staking or unbonding output
+// through one of its script paths, together with all the data required to sign
+// it and build its witness without access to the daemon
+type ExitTxTemplate struct {
+	Type          string
+	Tx            *wire.MsgTx
+	FundingOutput *wire.TxOut
+	SpendPath     *staking.SpendInfo
+	SigHash       []byte
+	Fee           btcutil.Amount
+	// relative timelock which must pass before tx can be included in the block
+	RelativeTimeLock uint16
+	// covenant signatures already collected from Babylon, only in unbonding
+	// template
+	CovenantSignatures []stakerdb.PubKeySigPair
+}
+
+// ExitTemplates contains exit transactions of one delegation. Unbonding
+// templates are available only after delegation was sent to Babylon, as
+// unbonding transaction is created at that time.
+type ExitTemplates struct {
+	StakingTxHash          chainhash.Hash
+	StakerPubKey           []byte
+	DestinationAddress     string
+	FeeRate                chainfee.SatPerKVByte
+	CovenantQuorum         uint32
+	StakingTimeLockSpend   *ExitTxTemplate
+	Unbonding              *ExitTxTemplate
+	UnbondingTimeLockSpend *ExitTxTemplate
+}
+
+func newExitTxTemplate(
+	txType string,
+	tx *wire.MsgTx,
+	fundingOutput *wire.TxOut,
+	spendPath *staking.SpendInfo,
+	fee btcutil.Amount,
+	relativeTimeLock uint16,
+) (*ExitTxTemplate, error) {
+	sigHash, err := scriptPathSigHash(tx, fundingOutput, spendPath)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate sighash of %s tx: %w", txType, err)
+	}
+
+	return &ExitTxTemplate{
+		Type:             txType,
+		Tx:               tx,
+		FundingOutput:    fundingOutput,
+		SpendPath:        spendPath,
+		SigHash:          sigHash,
+		Fee:              fee,
+		RelativeTimeLock: relativeTimeLock,
+	}, nil
+}
+
+// ExitTemplates builds exit transactions of the delegation, which spend its
+// funds back to the staker address using current fee rate estimate. Transactions
+// are not signed by the staker, so that they can be signed offline by the holder
+// of staker key in case the daemon host is not available.
+func (app *StakerApp) ExitTemplates(stakingTxHash *chainhash.Hash) (*ExitTemplates, error) {
+	tx, err := app.txTracker.GetTransaction(stakingTxHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stakerPubKey, err := app.stakerPubKeyForTx(tx)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot retrieve staker public key: %w", err)
+	}
+
+	params, err := app.babylonClient.Params()
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting params: %w", err)
+	}
+
+	stakerAddress, err := btcutil.DecodeAddress(tx.StakerAddress, app.network)
+
+	if err != nil {
+		return nil, fmt.Errorf("error decoding staker address: %w", err)
+	}
+
+	if err := app.checkWithdrawalDestination(stakerAddress); err != nil {
+		return nil, fmt.Errorf("cannot build exit templates: %w", err)
+	}
+
+	destinationScript, err := txscript.PayToAddrScript(stakerAddress)
+
+	if err != nil {
+		return nil, err
+	}
+
+	scriptsInfo, err := buildStakingScriptsInfo(
+		stakerPubKey,
+		params.CovenantPks,
+		params.CovenantQuruomThreshold,
+		tx,
+		app.scriptBuilder,
+		app.network,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	feeRate := app.feeEstimator.EstimateFeePerKb()
+
+	templates := &ExitTemplates{
+		StakingTxHash:      *stakingTxHash,
+		StakerPubKey:       schnorr.SerializePubKey(stakerPubKey),
+		DestinationAddress: tx.StakerAddress,
+		FeeRate:            feeRate,
+		CovenantQuorum:     params.CovenantQuruomThreshold,
+	}
+
+	timeLockSpendTx, fee, err := createSpendStakeTx(
+		destinationScript,
+		scriptsInfo.StakingOutput,
+		tx.StakingOutputIndex,
+		stakingTxHash,
+		tx.StakingTime,
+		feeRate,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	templates.StakingTimeLockSpend, err = newExitTxTemplate(
+		ExitTxTypeStakingTimeLockSpend,
+		timeLockSpendTx,
+		scriptsInfo.StakingOutput,
+		scriptsInfo.TimeLockPath,
+		*fee,
+		tx.StakingTime,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tx.UnbondingTxData == nil || tx.UnbondingTxData.UnbondingTx == nil {
+		return templates, nil
+	}
+
+	data := tx.UnbondingTxData
+
+	templates.Unbonding, err = newExitTxTemplate(
+		ExitTxTypeUnbonding,
+		data.UnbondingTx,
+		scriptsInfo.StakingOutput,
+		scriptsInfo.UnbondingPath,
+		btcutil.Amount(scriptsInfo.StakingOutput.Value-data.UnbondingTx.TxOut[0].Value),
+		0,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	templates.Unbonding.CovenantSignatures = data.CovenantSignatures
+
+	unbondingScriptsInfo, err := buildUnbondingScriptsInfo(
+		stakerPubKey,
+		params.CovenantPks,
+		params.CovenantQuruomThreshold,
+		tx,
+		app.scriptBuilder,
+		app.network,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	unbondingTxHash := data.UnbondingTx.TxHash()
+
+	unbondingSpendTx, fee, err := createSpendStakeTx(
+		destinationScript,
+		// unbonding tx has only one output
+		data.UnbondingTx.TxOut[0],
+		0,
+		&unbondingTxHash,
+		data.UnbondingTime,
+		feeRate,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	templates.UnbondingTimeLockSpend, err = newExitTxTemplate(
+		ExitTxTypeUnbondingTimeLockSpend,
+		unbondingSpendTx,
+		data.UnbondingTx.TxOut[0],
+		unbondingScriptsInfo.TimeLockPath,
+		*fee,
+		data.UnbondingTime,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+type ExportedCovenantSignature struct {
+	PubKeyHex    string `json:"pub_key_hex"`
+	SignatureHex string `json:"signature_hex"`
+}
+
+// ExportedExitTx is json representation of ExitTxTemplate. Witness of script
+// path spend is built as: <signatures required by leaf script> <leaf script>
+// <control block>.
+type ExportedExitTx struct {
+	Type               string                      `json:"type"`
+	TxHash             string                      `json:"tx_hash"`
+	TxHex              string                      `json:"tx_hex"`
+	PrevOutPkScriptHex string                      `json:"prev_out_pk_script_hex"`
+	PrevOutValue       string                      `json:"prev_out_value"`
+	LeafScriptHex      string                      `json:"leaf_script_hex"`
+	ControlBlockHex    string                      `json:"control_block_hex"`
+	SigHashHex         string                      `json:"sig_hash_hex"`
+	Fee                string                      `json:"fee"`
+	RelativeTimeLock   string                      `json:"relative_timelock_blocks"`
+	CovenantSignatures []ExportedCovenantSignature `json:"covenant_signatures,omitempty"`
+}
+
+type ExportedExitTemplates struct {
+	StakingTxHash      string           `json:"staking_tx_hash"`
+	StakerPubKeyHex    string           `json:"staker_pub_key_hex"`
+	DestinationAddress string           `json:"destination_address"`
+	FeeRateSatPerKvb   string           `json:"fee_rate_sat_per_kvb"`
+	CovenantQuorum     string           `json:"covenant_quorum"`
+	Transactions       []ExportedExitTx `json:"transactions"`
+}
+
+func exportExitTx(t *ExitTxTemplate) (*ExportedExitTx, error) {
+	txBytes, err := utils.SerializeBtcTransaction(t.Tx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	controlBlock, err := t.SpendPath.ControlBlock.ToBytes()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize control block: %w", err)
+	}
+
+	exported := &ExportedExitTx{
+		Type:               t.Type,
+		TxHash:             t.Tx.TxHash().String(),
+		TxHex:              hex.EncodeToString(txBytes),
+		PrevOutPkScriptHex: hex.EncodeToString(t.FundingOutput.PkScript),
+		PrevOutValue:       strconv.FormatInt(t.FundingOutput.Value, 10),
+		LeafScriptHex:      hex.EncodeToString(t.SpendPath.RevealedLeaf.Script),
+		ControlBlockHex:    hex.EncodeToString(controlBlock),
+		SigHashHex:         hex.EncodeToString(t.SigHash),
+		Fee:                strconv.FormatInt(int64(t.Fee), 10),
+		RelativeTimeLock:   strconv.FormatUint(uint64(t.RelativeTimeLock), 10),
+	}
+
+	for _, sig := range t.CovenantSignatures {
+		exported.CovenantSignatures = append(exported.CovenantSignatures, ExportedCovenantSignature{
+			PubKeyHex:    pubKeyToString(sig.PubKey),
+			SignatureHex: hex.EncodeToString(sig.Signature.Serialize()),
+		})
+	}
+
+	return exported, nil
+}
+
+// Export converts templates to json friendly representation
+func (t *ExitTemplates) Export() (*ExportedExitTemplates, error) {
+	exported := &ExportedExitTemplates{
+		StakingTxHash:      t.StakingTxHash.String(),
+		StakerPubKeyHex:    hex.EncodeToString(t.StakerPubKey),
+		DestinationAddress: t.DestinationAddress,
+		FeeRateSatPerKvb:   strconv.FormatUint(uint64(t.FeeRate), 10),
+		CovenantQuorum:     strconv.FormatUint(uint64(t.CovenantQuorum), 10),
+	}
+
+	for _, template := range []*ExitTxTemplate{t.StakingTimeLockSpend, t.Unbonding, t.UnbondingTimeLockSpend} {
+		if template == nil {
+			continue
+		}
+
+		exportedTx, err := exportExitTx(template)
+
+		if err != nil {
+			return nil, err
+		}
+
+		exported.Transactions = append(exported.Transactions, *exportedTx)
+	}
+
+	return exported, nil
+}
+
+// writeExitTemplates atomically replaces templates file of the delegation, so
+// that cold storage sync never picks up partially written file
+func writeExitTemplates(dir string, templates *ExportedExitTemplates) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(templates, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, templates.StakingTxHash+".json")
+
+	tmp, err := os.CreateTemp(dir, templates.StakingTxHash+".*.tmp")
+
+	if err != nil {
+		return err
+	}
+
+	tmpPath := tmp.Name()
+
+	_, err = tmp.Write(data)
+
+	if err == nil {
+		err = tmp.Sync()
+	}
+
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+// removeExitTemplates removes exported templates file of the delegation together
+// with temporary files left by interrupted writes
+func removeExitTemplates(dir string, stakingTxHash string) error {
+	leftovers, err := filepath.Glob(filepath.Join(dir, stakingTxHash+".*.tmp"))
+
+	if err != nil {
+		return err
+	}
+
+	for _, path := range append(leftovers, filepath.Join(dir, stakingTxHash+".json")) {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// exportExitTemplates exports exit templates of the delegation to configured
+// directory in the background. Export failures are only logged, as they must
+// not influence delegation process.
+func (app *StakerApp) exportExitTemplates(stakingTxHash chainhash.Hash) {
+	exportDir := app.config.ExitTemplatesConfig.ExportDir
+
+	if exportDir == "" {
+		return
+	}
+
+	app.wg.Add(1)
+	go func() {
+		defer app.wg.Done()
+
+		err := func() error {
+			templates, err := app.ExitTemplates(&stakingTxHash)
+
+			if err != nil {
+				return err
+			}
+
+			exported, err := templates.Export()
+
+			if err != nil {
+				return err
+			}
+
+			return writeExitTemplates(exportDir, exported)
+		}()
+
+		if err != nil {
+			app.logger.WithFields(logrus.Fields{
+				"stakingTxHash": stakingTxHash,
+				"err":           err,
+			}).Error("Failed to export exit templates")
+			return
+		}
+
+		app.logger.WithFields(logrus.Fields{
+			"stakingTxHash": stakingTxHash,
+			"exportDir":     exportDir,
+		}).Debug("Exported exit templates")
+	}()
+}
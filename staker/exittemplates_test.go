@@ -0,0 +1,42 @@
+package staker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoveExitTemplates(t *testing.T) {
+	dir := t.TempDir()
+	purgedHash := chainhash.Hash{1}.String()
+	otherHash := chainhash.Hash{2}.String()
+
+	for _, hash := range []string{purgedHash, otherHash} {
+		require.NoError(t, writeExitTemplates(dir, &ExportedExitTemplates{StakingTxHash: hash}))
+	}
+
+	// temporary file left by interrupted write
+	leftover, err := os.CreateTemp(dir, purgedHash+".*.tmp")
+	require.NoError(t, err)
+	require.NoError(t, leftover.Close())
+
+	require.NoError(t, removeExitTemplates(dir, purgedHash))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, otherHash+".json", entries[0].Name())
+
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		require.NoError(t, err)
+		require.False(t, strings.Contains(string(data), purgedHash))
+	}
+
+	// removing already removed templates is not an error
+	require.NoError(t, removeExitTemplates(dir, purgedHash))
+}
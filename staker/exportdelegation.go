@@ -0,0 +1,115 @@
+package staker
+
+import (
+	"fmt"
+
+	cl "github.com/babylonchain/btc-staker/babylonclient"
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/sirupsen/logrus"
+)
+
+// ExportedDelegation is delegation rendered for manual submission to babylon
+type ExportedDelegation struct {
+	*cl.DelegationExport
+	// babylon address which must sign the transaction
+	Signer string
+	// true if delegation was moved to SENT_TO_BABYLON
+	MarkedSubmitted bool
+}
+
+// ExportDelegation renders delegation of confirmed staking transaction as
+// babylon MsgCreateBTCDelegation, for manual submission with babylond when the
+// daemon can't send it itself. Staking params are still queried from babylon.
+//
+// Unbonding transaction is created anew on every export. With markSubmitted,
+// delegation is moved to SENT_TO_BABYLON with unbonding transaction of this
+// export, and the daemon continues with waiting for covenant signatures as if it
+// sent the delegation itself. Only the message of the last marked export may be
+// submitted then.
+func (app *StakerApp) ExportDelegation(
+	stakingTxHash *chainhash.Hash,
+	gasLimit uint64,
+	fees sdk.Coins,
+	markSubmitted bool,
+) (*ExportedDelegation, error) {
+	// check we are not shutting down
+	select {
+	case <-app.quit:
+		return nil, ErrStakerStopped
+	default:
+	}
+
+	storedTx, err := app.txTracker.GetTransaction(stakingTxHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if storedTx.State == proto.TransactionState_SENT_TO_BTC {
+		return nil, fmt.Errorf("cannot export delegation of staking transaction not confirmed on btc: %w", ErrInvalidTransactionState)
+	}
+
+	if markSubmitted && storedTx.State != proto.TransactionState_CONFIRMED_ON_BTC {
+		return nil, fmt.Errorf("delegation in state %s was already sent to babylon: %w", storedTx.State, ErrInvalidTransactionState)
+	}
+
+	if markSubmitted {
+		// make sure retry queue does not send the delegation concurrently
+		key := retryQueueKey{op: proto.RetryOperation_SEND_DELEGATION_TO_BABYLON, txHash: *stakingTxHash}
+
+		if !app.retryQueue.tryStart(key) {
+			return nil, fmt.Errorf("delegation is being sent by retry queue: %w", ErrInvalidTransactionState)
+		}
+		defer app.retryQueue.finish(key)
+	}
+
+	stakerAddress, err := btcutil.DecodeAddress(storedTx.StakerAddress, app.network)
+
+	if err != nil {
+		return nil, fmt.Errorf("error decoding staker address: %s. Err: %v", storedTx.StakerAddress, err)
+	}
+
+	req, err := app.sendDelegationRequestFromWallet(stakingTxHash, storedTx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	delegationData, err := app.buildDelegation(req, stakerAddress, storedTx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := sdk.Bech32ifyAddressBytes(app.config.BabylonConfig.AccountPrefix, app.babylonClient.GetKeyAddress())
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode babylon signer address: %w", err)
+	}
+
+	export, err := cl.ExportDelegation(signer, delegationData, gasLimit, fees)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if markSubmitted {
+		app.completeRetry(proto.RetryOperation_SEND_DELEGATION_TO_BABYLON, stakingTxHash)
+		app.reportDelegationSubmitted(req, delegationData)
+	}
+
+	app.logger.WithFields(logrus.Fields{
+		"stakingTxHash":   stakingTxHash,
+		"signer":          signer,
+		"markedSubmitted": markSubmitted,
+	}).Info("Delegation exported for manual submission to babylon")
+
+	return &ExportedDelegation{
+		DelegationExport: export,
+		Signer:           signer,
+		MarkedSubmitted:  markSubmitted,
+	}, nil
+}
@@ -0,0 +1,240 @@
+package staker
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	bbn "github.com/babylonchain/babylon/types"
+	cl "github.com/babylonchain/btc-staker/babylonclient"
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/babylonchain/btc-staker/utils"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/sirupsen/logrus"
+)
+
+// StakeFundsForExternalKey creates staking transaction funded from the funding address
+// of connected wallet, which staker key is the provided external key. Connected wallet
+// does not control the external key, so:
+// - only the owner of external key can spend staking output
+// - only the owner of external key can register delegation on babylon
+// Staker program only sends staking transaction to btc and tracks delegation
+// in watch only mode.
+func (app *StakerApp) StakeFundsForExternalKey(
+	fundingAddress btcutil.Address,
+	stakerBtcPk *btcec.PublicKey,
+	stakingAmount btcutil.Amount,
+	fpPks []*btcec.PublicKey,
+	stakingTimeBlocks uint16,
+	minConfirmations uint32,
+	metadata map[string]string,
+	requestId string,
+) (*chainhash.Hash, error) {
+	// check we are not shutting down
+	select {
+	case <-app.quit:
+		return nil, ErrStakerStopped
+
+	default:
+	}
+
+	if !app.config.StakerConfig.AllowExternalStakerKeys {
+		return nil, fmt.Errorf("staking on behalf of external staker keys is disabled. Enable it with allowexternalstakerkeys option")
+	}
+
+	if stakerBtcPk == nil {
+		return nil, fmt.Errorf("external staker public key must be provided")
+	}
+
+	params, err := app.validateStakingRequest(stakingAmount, fpPks, stakingTimeBlocks)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.checkPolicy(policyStakeRequest(
+		PolicyActionStakeExternal,
+		fundingAddress,
+		stakerBtcPk,
+		stakingAmount,
+		fpPks,
+		stakingTimeBlocks,
+		metadata,
+		requestId,
+	))
+
+	if err != nil {
+		return nil, err
+	}
+
+	slot, releaseSlots, err := app.acquireStakingSlots()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer releaseSlots()
+
+	err = app.wc.UnlockWallet(defaultWalletUnlockTimeout)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// external key must not be the key of funding address, otherwise user should
+	// use normal staking flow
+	fundingPrivKey, err := app.wc.DumpPrivateKey(fundingAddress)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.Equal(schnorr.SerializePubKey(fundingPrivKey.PubKey()), schnorr.SerializePubKey(stakerBtcPk)) {
+		return nil, fmt.Errorf("external staker key is controlled by funding address. Use regular staking instead")
+	}
+
+	stakingInfo, err := app.scriptBuilder.BuildStakingScripts(
+		stakerBtcPk,
+		fpPks,
+		params.CovenantPks,
+		params.CovenantQuruomThreshold,
+		stakingTimeBlocks,
+		stakingAmount,
+		app.network,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build staking info: %w", err)
+	}
+
+	feeRate := app.feeEstimator.EstimateFeePerKb()
+
+	changeAddress, err := app.changeAddress(app.config.ChangePolicyConfig.ExternalStake, fundingAddress)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := app.wc.CreateAndSignTx([]*wire.TxOut{stakingInfo.Output()}, btcutil.Amount(feeRate), changeAddress, minConfirmations)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stakingOutputIdx, err := bbn.GetOutputIdxInBTCTx(tx, stakingInfo.Output())
+
+	if err != nil {
+		return nil, err
+	}
+
+	stakingTxFee, err := app.walletTxFee(tx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	app.logger.WithFields(logrus.Fields{
+		"fundingAddress": fundingAddress,
+		"stakerBtcPk":    fmt.Sprintf("%x", schnorr.SerializePubKey(stakerBtcPk)),
+		"stakingAmount":  stakingAmount,
+		"btxTxHash":      tx.TxHash(),
+		"fee":            feeRate,
+		"txFee":          stakingTxFee,
+		"requestId":      requestId,
+	}).Warn("Created staking transaction on behalf of external staker key. Staked funds can only be spent and delegated by the owner of the external key")
+
+	req := newExternalKeyStakingRequest(
+		fundingAddress,
+		tx,
+		stakingOutputIdx,
+		stakingInfo.Output().PkScript,
+		stakingTimeBlocks,
+		stakingAmount,
+		fpPks,
+		params.ConfirmationTimeBlocks,
+		stakerBtcPk,
+		metadata,
+		stakingTxFee,
+	)
+	req.unconfirmedSlot = slot
+	req.requestId = requestId
+
+	utils.PushOrQuit[*stakingRequestedEvent](
+		app.stakingRequestedEvChan,
+		req,
+		app.quit,
+	)
+
+	select {
+	case reqErr := <-req.errChan:
+		app.logger.WithFields(logrus.Fields{
+			"fundingAddress": fundingAddress,
+			"requestId":      requestId,
+			"err":            reqErr,
+		}).Debugf("Sending staking tx failed")
+
+		return nil, reqErr
+	case hash := <-req.successChan:
+		return hash, nil
+	case <-app.quit:
+		return nil, ErrStakerStopped
+	}
+}
+
+// checkExternalDelegationOnBabylon checks whether owner of external staker key
+// registered delegation of confirmed staking transaction on babylon. After
+// delegation is found, it is tracked as any other delegation and true is returned.
+func (app *StakerApp) checkExternalDelegationOnBabylon(stakingTxHash *chainhash.Hash) bool {
+	storedTx, err := app.txTracker.GetTransaction(stakingTxHash)
+
+	if err == nil && storedTx.State != proto.TransactionState_CONFIRMED_ON_BTC {
+		// delegation state was already updated, nothing more to do here
+		return true
+	}
+
+	di, err := app.babylonClient.QueryDelegationInfo(stakingTxHash)
+
+	if err != nil {
+		if errors.Is(err, cl.ErrDelegationNotFound) {
+			app.logger.WithFields(logrus.Fields{
+				"stakingTxHash": stakingTxHash,
+			}).Debug("Delegation of external staker key not registered on babylon yet")
+		} else {
+			app.logger.WithFields(logrus.Fields{
+				"stakingTxHash": stakingTxHash,
+				"err":           err,
+			}).Error("Error getting delegation info from babylon")
+		}
+
+		return false
+	}
+
+	if di.UndelegationInfo == nil {
+		app.logger.WithFields(logrus.Fields{
+			"stakingTxHash": stakingTxHash,
+		}).Error("Delegation of external staker key does not have unbonding data")
+		return false
+	}
+
+	app.logger.WithFields(logrus.Fields{
+		"stakingTxHash": stakingTxHash,
+	}).Info("Delegation of external staker key found on babylon")
+
+	ev := &delegationSubmittedToBabylonEvent{
+		stakingTxHash: *stakingTxHash,
+		unbondingTx:   di.UndelegationInfo.UnbondingTransaction,
+		unbondingTime: di.UndelegationInfo.UnbondingTime,
+	}
+
+	utils.PushOrQuit[*delegationSubmittedToBabylonEvent](
+		app.delegationSubmittedToBabylonEvChan,
+		ev,
+		app.quit,
+	)
+
+	return true
+}
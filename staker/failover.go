@@ -0,0 +1,75 @@
+package staker
+
+import (
+	"time"
+
+	"github.com/babylonchain/btc-staker/metrics"
+	"github.com/babylonchain/btc-staker/walletcontroller"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	btcBackendPrimary   = "primary"
+	btcBackendSecondary = "secondary"
+)
+
+func newBtcBackendSwitchHandler(
+	logger *logrus.Logger,
+	m *metrics.StakerMetrics,
+) func(ev walletcontroller.BackendSwitch) {
+	return func(ev walletcontroller.BackendSwitch) {
+		if ev.ToSecondary {
+			m.BtcBackendSwitchovers.WithLabelValues(btcBackendSecondary).Inc()
+			m.BtcBackendSecondaryActive.Set(1)
+			logger.WithFields(logrus.Fields{
+				"backend": ev.Secondary,
+				"reason":  ev.Reason,
+			}).Warn("Switched broadcasts and confirmation queries to secondary btc backend")
+			return
+		}
+
+		m.BtcBackendSwitchovers.WithLabelValues(btcBackendPrimary).Inc()
+		m.BtcBackendSecondaryActive.Set(0)
+		logger.WithFields(logrus.Fields{
+			"reason": ev.Reason,
+		}).Info("Switched broadcasts and confirmation queries back to primary btc node")
+	}
+}
+
+func (app *StakerApp) checkBtcBackends() {
+	heights, err := app.failover.CheckBackends()
+
+	if err != nil {
+		app.logger.WithFields(logrus.Fields{
+			"err": err,
+		}).Error("Failed to compare heights of btc backends")
+		return
+	}
+
+	app.m.BtcBackendHeight.WithLabelValues(btcBackendPrimary).Set(float64(heights.Primary))
+	app.m.BtcBackendHeight.WithLabelValues(btcBackendSecondary).Set(float64(heights.Secondary))
+
+	// block notifications come from primary node, so while it is stalled
+	// confirmations are driven by height of secondary backend
+	if heights.SecondaryActive && heights.Secondary > 0 {
+		app.confTracker.advanceTo(uint32(heights.Secondary))
+	}
+}
+
+func (app *StakerApp) btcBackendFailoverLoop(interval time.Duration) {
+	defer app.wg.Done()
+
+	app.checkBtcBackends()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			app.checkBtcBackends()
+		case <-app.quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package staker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/sirupsen/logrus"
+)
+
+// faucet responses are ignored apart from status code, read only limited part
+// of the body to include in the error
+const maxFaucetResponseBytes = 4 * 1024
+
+// FaucetRequest is json payload posted to the faucet
+type FaucetRequest struct {
+	Address string `json:"address"`
+	Denom   string `json:"denom"`
+}
+
+// faucetHook requests funds for babylon fee account from testnet faucet, so
+// long running testnet deployments do not need manual top ups
+type faucetHook struct {
+	cfg    *scfg.FaucetConfig
+	client *http.Client
+
+	// time of the last request, accessed only from babylonBalanceLoop
+	lastRequest time.Time
+}
+
+func newFaucetHook(cfg *scfg.FaucetConfig) *faucetHook {
+	return &faucetHook{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+func (h *faucetHook) enabled() bool {
+	return h.cfg.Url != ""
+}
+
+func (h *faucetHook) request(ctx context.Context, req *FaucetRequest) error {
+	payload, err := json.Marshal(req)
+
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.cfg.Url, bytes.NewReader(payload))
+
+	if err != nil {
+		return err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(httpReq)
+
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxFaucetResponseBytes))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// requestFaucetFunds asks the faucet for funds unless the previous request was
+// sent less than cooldown ago. Funds arrive asynchronously, result is visible
+// on next balance check.
+func (app *StakerApp) requestFaucetFunds(denom string) {
+	if !app.faucet.enabled() {
+		return
+	}
+
+	if !app.faucet.lastRequest.IsZero() && time.Since(app.faucet.lastRequest) < app.config.FaucetConfig.Cooldown {
+		return
+	}
+
+	app.faucet.lastRequest = time.Now()
+
+	req := &FaucetRequest{
+		Address: app.stakerBabylonAddress(),
+		Denom:   denom,
+	}
+
+	logger := app.logger.WithFields(logrus.Fields{
+		"address": req.Address,
+		"denom":   req.Denom,
+	})
+
+	ctx, cancel := app.appQuitContext()
+	defer cancel()
+
+	if err := app.faucet.request(ctx, req); err != nil {
+		app.m.FaucetRequests.WithLabelValues("error").Inc()
+		logger.WithError(err).Error("Failed to request funds from faucet")
+		return
+	}
+
+	app.m.FaucetRequests.WithLabelValues("success").Inc()
+	logger.Info("Requested funds for babylon fee account from faucet")
+}
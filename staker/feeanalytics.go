@@ -0,0 +1,129 @@
+package staker
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+// FeeStats aggregates fees of transactions of one kind sent in one UTC day
+type FeeStats struct {
+	Day  time.Time
+	Kind string
+	// number of transactions, fee rate statistics are calculated only from
+	// transactions with known virtual size
+	Count         int
+	TotalFee      btcutil.Amount
+	AvgFeeRate    float64
+	MedianFeeRate float64
+	// number of transactions with observed confirmation and average number of
+	// blocks it took to confirm them
+	Confirmed             int
+	AvgConfirmationBlocks float64
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+
+	return sorted[mid]
+}
+
+// FeeAnalytics returns fee statistics of transactions sent by the daemon in the
+// last days, per UTC day and transaction kind, ordered by day and kind
+func (app *StakerApp) FeeAnalytics(days uint32) ([]FeeStats, error) {
+	if days == 0 {
+		return nil, fmt.Errorf("number of days must be positive")
+	}
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	since := today.AddDate(0, 0, -int(days-1))
+
+	spends, err := app.feeSpends.SpendsSince(since)
+
+	if err != nil {
+		return nil, err
+	}
+
+	type statsKey struct {
+		day  time.Time
+		kind string
+	}
+
+	type accumulator struct {
+		stats              FeeStats
+		feeRates           []float64
+		confirmationBlocks uint64
+	}
+
+	accs := make(map[statsKey]*accumulator)
+
+	for _, spend := range spends {
+		ts := spend.Timestamp.UTC()
+		key := statsKey{
+			day:  time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, time.UTC),
+			kind: spend.Kind,
+		}
+
+		acc, found := accs[key]
+
+		if !found {
+			acc = &accumulator{stats: FeeStats{Day: key.day, Kind: key.kind}}
+			accs[key] = acc
+		}
+
+		acc.stats.Count++
+		acc.stats.TotalFee += spend.Fee
+
+		if feeRate := spend.FeeRate(); feeRate > 0 {
+			acc.feeRates = append(acc.feeRates, feeRate)
+		}
+
+		if blocks, confirmed := spend.ConfirmationBlocks(); confirmed {
+			acc.stats.Confirmed++
+			acc.confirmationBlocks += uint64(blocks)
+		}
+	}
+
+	result := make([]FeeStats, 0, len(accs))
+
+	for _, acc := range accs {
+		if len(acc.feeRates) > 0 {
+			var sum float64
+			for _, r := range acc.feeRates {
+				sum += r
+			}
+
+			acc.stats.AvgFeeRate = sum / float64(len(acc.feeRates))
+			acc.stats.MedianFeeRate = median(acc.feeRates)
+		}
+
+		if acc.stats.Confirmed > 0 {
+			acc.stats.AvgConfirmationBlocks = float64(acc.confirmationBlocks) / float64(acc.stats.Confirmed)
+		}
+
+		result = append(result, acc.stats)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Day.Equal(result[j].Day) {
+			return result[i].Day.Before(result[j].Day)
+		}
+		return result[i].Kind < result[j].Kind
+	})
+
+	return result, nil
+}
@@ -0,0 +1,186 @@
+package staker
+
+import (
+	"errors"
+	"time"
+
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	FeeSpendKindStaking       = "staking"
+	FeeSpendKindUnbonding     = "unbonding"
+	FeeSpendKindSpendStake    = "spend_stake"
+	FeeSpendKindConsolidation = "consolidation"
+	FeeSpendKindCpfp          = "cpfp"
+
+	feeBudgetDayWindow  = 24 * time.Hour
+	feeBudgetWeekWindow = 7 * 24 * time.Hour
+)
+
+// FeeBudgetWindow describes fees spent in one rolling time window
+type FeeBudgetWindow struct {
+	Window time.Duration
+	Spent  btcutil.Amount
+	// 0 if there is no budget for the window
+	Budget       btcutil.Amount
+	SpentPerKind map[string]btcutil.Amount
+}
+
+func (w *FeeBudgetWindow) Exceeded() bool {
+	return w.Budget > 0 && w.Spent >= w.Budget
+}
+
+type FeeBudgetStatus struct {
+	Day  FeeBudgetWindow
+	Week FeeBudgetWindow
+}
+
+func (s *FeeBudgetStatus) Exceeded() bool {
+	return s.Day.Exceeded() || s.Week.Exceeded()
+}
+
+// FeeBudgetStatus returns fees spent by the daemon in the last 24 hours and
+// last 7 days
+func (app *StakerApp) FeeBudgetStatus() (*FeeBudgetStatus, error) {
+	now := time.Now()
+
+	spends, err := app.feeSpends.SpendsSince(now.Add(-feeBudgetWeekWindow))
+
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := app.config.FeeBudgetConfig
+
+	status := &FeeBudgetStatus{
+		Day: FeeBudgetWindow{
+			Window:       feeBudgetDayWindow,
+			Budget:       btcutil.Amount(cfg.DailyBudget),
+			SpentPerKind: make(map[string]btcutil.Amount),
+		},
+		Week: FeeBudgetWindow{
+			Window:       feeBudgetWeekWindow,
+			Budget:       btcutil.Amount(cfg.WeeklyBudget),
+			SpentPerKind: make(map[string]btcutil.Amount),
+		},
+	}
+
+	dayStart := now.Add(-feeBudgetDayWindow)
+
+	for _, spend := range spends {
+		status.Week.Spent += spend.Fee
+		status.Week.SpentPerKind[spend.Kind] += spend.Fee
+
+		if !spend.Timestamp.Before(dayStart) {
+			status.Day.Spent += spend.Fee
+			status.Day.SpentPerKind[spend.Kind] += spend.Fee
+		}
+	}
+
+	return status, nil
+}
+
+// refreshFeeBudgetMetrics recalculates spent fees, so that fees spent before the
+// start of the window stop being counted even if daemon does not spend anything
+func (app *StakerApp) refreshFeeBudgetMetrics() {
+	status, err := app.FeeBudgetStatus()
+
+	if err != nil {
+		app.logger.WithFields(logrus.Fields{
+			"err": err,
+		}).Error("Failed to calculate spent fees")
+		return
+	}
+
+	app.m.FeesSpentLastDay.Set(float64(status.Day.Spent))
+	app.m.FeesSpentLastWeek.Set(float64(status.Week.Spent))
+}
+
+// recordFeeSpend records fee of transaction sent by the daemon. Fee is already
+// paid at this point, so failures are only logged.
+func (app *StakerApp) recordFeeSpend(kind string, tx *wire.MsgTx, fee btcutil.Amount) {
+	now := time.Now()
+	txHash := tx.TxHash()
+
+	err := app.feeSpends.AddSpend(&stakerdb.FeeSpendEntry{
+		Timestamp:  now,
+		Kind:       kind,
+		TxHash:     txHash,
+		Fee:        fee,
+		VSize:      mempool.GetTxVirtualSize(btcutil.NewTx(tx)),
+		SentHeight: app.currentBestBlockHeight.Load(),
+	})
+
+	if err != nil {
+		app.logger.WithFields(logrus.Fields{
+			"kind":   kind,
+			"txHash": txHash,
+			"fee":    fee,
+			"err":    err,
+		}).Error("Failed to record spent fee")
+		return
+	}
+
+	app.watchFirstConfirmation(tx)
+
+	// entries are kept for fee analytics, which never covers less than the
+	// longest budget window
+	if err := app.feeSpends.PruneBefore(now.Add(-app.config.FeeBudgetConfig.HistoryRetention)); err != nil {
+		app.logger.WithFields(logrus.Fields{
+			"err": err,
+		}).Error("Failed to prune spent fees")
+	}
+
+	app.refreshFeeBudgetMetrics()
+}
+
+// recordFeeSpendConfirmation records height at which transaction sent by the
+// daemon was confirmed, to compare paid fee rate with confirmation time.
+// Transactions whose fees were pruned or not recorded are ignored.
+func (app *StakerApp) recordFeeSpendConfirmation(txHash chainhash.Hash, height uint32) {
+	_, err := app.feeSpends.SetConfirmation(txHash, height, time.Time{})
+
+	if err != nil && !errors.Is(err, stakerdb.ErrFeeSpendNotFound) {
+		app.logger.WithFields(logrus.Fields{
+			"txHash": txHash,
+			"height": height,
+			"err":    err,
+		}).Error("Failed to record confirmation of transaction with spent fee")
+	}
+}
+
+// automatedFeeSpendAllowed returns false if automated fee consuming actions must
+// be skipped because fee budget is exceeded
+func (app *StakerApp) automatedFeeSpendAllowed() bool {
+	if !app.config.FeeBudgetConfig.BlockAutomatedActions {
+		return true
+	}
+
+	status, err := app.FeeBudgetStatus()
+
+	if err != nil {
+		// budget can't be verified, so automated action is not performed
+		app.logger.WithFields(logrus.Fields{
+			"err": err,
+		}).Error("Failed to check fee budget")
+		return false
+	}
+
+	if status.Exceeded() {
+		app.logger.WithFields(logrus.Fields{
+			"spentLastDay":  status.Day.Spent,
+			"dailyBudget":   status.Day.Budget,
+			"spentLastWeek": status.Week.Spent,
+			"weeklyBudget":  status.Week.Budget,
+		}).Warn("Fee budget exceeded, skipping automated action")
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,51 @@
+package staker
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// lowFeeWindowOpen returns true if estimated fee rate is at or below threshold
+// of the low fee window
+func (app *StakerApp) lowFeeWindowOpen() bool {
+	// estimator returns fee per kvbyte, config is in sat/vbyte
+	currentFeeRate := uint64(app.feeEstimator.EstimateFeePerKb() / 1000)
+	open := currentFeeRate <= app.config.FeeWindowConfig.MaxFeeRate
+
+	if open {
+		app.m.LowFeeWindowOpen.Set(1)
+	} else {
+		app.m.LowFeeWindowOpen.Set(0)
+	}
+
+	return open
+}
+
+// nonUrgentTxAllowed returns true if non urgent transaction, which is waiting to
+// be sent since waitingSince, can be sent now. Transactions are sent either in
+// the low fee window, or after they waited for configured maximum time.
+func (app *StakerApp) nonUrgentTxAllowed(waitingSince time.Time, logger *logrus.Entry) bool {
+	cfg := app.config.FeeWindowConfig
+
+	if !cfg.Enabled || app.lowFeeWindowOpen() {
+		return true
+	}
+
+	waited := time.Since(waitingSince)
+
+	if waited >= cfg.MaxWait {
+		logger.WithFields(logrus.Fields{
+			"waited":  waited,
+			"maxWait": cfg.MaxWait,
+		}).Warn("Low fee window did not open in time. Sending transaction with current fee rate")
+		return true
+	}
+
+	logger.WithFields(logrus.Fields{
+		"waited":     waited,
+		"maxFeeRate": cfg.MaxFeeRate,
+	}).Debug("Waiting for low fee window")
+
+	return false
+}
@@ -0,0 +1,142 @@
+package staker
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/babylonchain/btc-staker/walletcontroller"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/sirupsen/logrus"
+)
+
+// frozenOutputs keeps wallet outputs reserved by the operator for non staking
+// purposes. Contrary to utxo blocklist, frozen outputs are expected to be
+// unfrozen once they are no longer reserved.
+type frozenOutputs struct {
+	mu      sync.RWMutex
+	outputs map[wire.OutPoint]stakerdb.FrozenOutput
+}
+
+func newFrozenOutputs(store *stakerdb.FrozenOutputStore) (*frozenOutputs, error) {
+	stored, err := store.FrozenOutputs()
+
+	if err != nil {
+		return nil, err
+	}
+
+	f := &frozenOutputs{
+		outputs: make(map[wire.OutPoint]stakerdb.FrozenOutput, len(stored)),
+	}
+
+	for _, o := range stored {
+		f.outputs[o.Outpoint] = o
+	}
+
+	return f, nil
+}
+
+func (f *frozenOutputs) allowed(utxo *walletcontroller.Utxo) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	_, frozen := f.outputs[utxo.OutPoint]
+	return !frozen
+}
+
+// utxoSelectable returns true if utxo can be used to fund transactions created
+// by the daemon
+func (app *StakerApp) utxoSelectable(utxo *walletcontroller.Utxo) bool {
+	return app.utxoBlocklist.allowed(utxo) && app.frozenOutputs.allowed(utxo)
+}
+
+// FrozenOutputs returns all frozen outputs ordered by outpoint
+func (app *StakerApp) FrozenOutputs() []stakerdb.FrozenOutput {
+	f := app.frozenOutputs
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	outputs := make([]stakerdb.FrozenOutput, 0, len(f.outputs))
+
+	for _, o := range f.outputs {
+		outputs = append(outputs, o)
+	}
+
+	sort.Slice(outputs, func(i, j int) bool {
+		return outputs[i].Outpoint.String() < outputs[j].Outpoint.String()
+	})
+
+	return outputs
+}
+
+// IsOutputFrozen returns true if given outpoint is frozen
+func (app *StakerApp) IsOutputFrozen(outpoint *wire.OutPoint) bool {
+	return !app.frozenOutputs.allowed(&walletcontroller.Utxo{OutPoint: *outpoint})
+}
+
+// FreezeOutput excludes unspent wallet output from coin selection until it is
+// unfrozen. Freezing is persistent and applies to all transactions created after
+// this call returns.
+func (app *StakerApp) FreezeOutput(outpoint *wire.OutPoint, note string) error {
+	utxos, err := app.wc.ListOutputs(false)
+
+	if err != nil {
+		return fmt.Errorf("failed to list wallet outputs: %w", err)
+	}
+
+	found := false
+	for _, utxo := range utxos {
+		if utxo.OutPoint == *outpoint {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("output %s is not unspent wallet output: %w", outpoint, ErrInvalidStakingRequest)
+	}
+
+	f := app.frozenOutputs
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	frozen := stakerdb.FrozenOutput{
+		Outpoint:  *outpoint,
+		Timestamp: time.Now(),
+		Note:      note,
+	}
+
+	if err := app.frozenOutputStore.Freeze(&frozen); err != nil {
+		return err
+	}
+
+	f.outputs[*outpoint] = frozen
+
+	app.logger.WithFields(logrus.Fields{
+		"outpoint": outpoint,
+		"note":     note,
+	}).Info("Wallet output frozen")
+
+	return nil
+}
+
+// UnfreezeOutput makes frozen output available for coin selection again
+func (app *StakerApp) UnfreezeOutput(outpoint *wire.OutPoint) error {
+	f := app.frozenOutputs
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := app.frozenOutputStore.Unfreeze(outpoint); err != nil {
+		return err
+	}
+
+	delete(f.outputs, *outpoint)
+
+	app.logger.WithFields(logrus.Fields{
+		"outpoint": outpoint,
+	}).Info("Wallet output unfrozen")
+
+	return nil
+}
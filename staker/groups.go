@@ -0,0 +1,52 @@
+package staker
+
+import (
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/sirupsen/logrus"
+)
+
+// SetDelegationGroup assigns given delegations to the group. Empty group removes
+// delegations from their current group. Assignment stops at first unknown
+// delegation, delegations before it stay assigned.
+func (app *StakerApp) SetDelegationGroup(stakingTxHashes []*chainhash.Hash, group string) error {
+	for _, hash := range stakingTxHashes {
+		if err := app.txTracker.SetTxGroup(hash, group); err != nil {
+			return err
+		}
+
+		app.logger.WithFields(logrus.Fields{
+			"stakingTxHash": hash,
+			"group":         group,
+		}).Info("Delegation group changed")
+	}
+
+	return nil
+}
+
+// GroupSummaries returns staking summary for each group which has at least one
+// delegation assigned. Delegations without group are summarized under empty
+// group name.
+func (app *StakerApp) GroupSummaries() (map[string]*StakingSummary, error) {
+	summaries := make(map[string]*StakingSummary)
+
+	err := app.txTracker.ScanTrackedTransactions(func(tx *stakerdb.StoredTransaction) error {
+		summary, found := summaries[tx.Group]
+
+		if !found {
+			summary = newStakingSummary()
+			summaries[tx.Group] = summary
+		}
+
+		summary.add(tx)
+		return nil
+	}, func() {
+		summaries = make(map[string]*StakingSummary)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,57 @@
+package staker
+
+import (
+	"github.com/babylonchain/btc-staker/walletcontroller"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// labels of addresses of staking and unbonding outputs in the wallet are
+	// the prefix followed by staking transaction hash
+	StakingTxLabelPrefix   = "babylon-staking:"
+	UnbondingTxLabelPrefix = "babylon-unbonding:"
+)
+
+func stakingTxLabel(stakingTxHash *chainhash.Hash) string {
+	return StakingTxLabelPrefix + stakingTxHash.String()
+}
+
+func unbondingTxLabel(stakingTxHash *chainhash.Hash) string {
+	return UnbondingTxLabelPrefix + stakingTxHash.String()
+}
+
+// labelOutputAddress labels address of output created by the daemon in the
+// wallet, so that transactions sending to it can be recognized in wallet
+// history. Failure to label does not affect the operation which created the
+// output.
+func (app *StakerApp) labelOutputAddress(pkScript []byte, label string) {
+	if app.config.WalletConfig.DisableTxLabels {
+		return
+	}
+
+	_, addresses, _, err := txscript.ExtractPkScriptAddrs(pkScript, app.network)
+
+	if err != nil || len(addresses) != 1 {
+		app.logger.WithFields(logrus.Fields{
+			"label": label,
+			"err":   err,
+		}).Warn("Cannot label output without single address")
+		return
+	}
+
+	if err := app.wc.LabelAddress(addresses[0], label); err != nil {
+		app.logger.WithFields(logrus.Fields{
+			"address": addresses[0],
+			"label":   label,
+			"err":     err,
+		}).Warn("Failed to label address in the wallet")
+	}
+}
+
+// WalletTransactionsByLabel returns up to limit most recent wallet transaction
+// entries with label starting with labelPrefix
+func (app *StakerApp) WalletTransactionsByLabel(labelPrefix string, limit int) ([]walletcontroller.WalletTransaction, error) {
+	return app.wc.ListTransactionsByLabel(labelPrefix, limit)
+}
@@ -0,0 +1,115 @@
+package staker
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+const (
+	LifecycleEventStateOverridden       = "STATE_OVERRIDDEN"
+	LifecycleEventDelegationPurged      = "DELEGATION_PURGED"
+	LifecycleEventAutoWithdrawScheduled = "AUTO_WITHDRAW_SCHEDULED"
+	LifecycleEventAutoWithdrawCancelled = "AUTO_WITHDRAW_CANCELLED"
+	LifecycleEventAutoWithdrawExecuted  = "AUTO_WITHDRAW_EXECUTED"
+)
+
+func newLifecycleEventId() string {
+	var id [16]byte
+	// crypto/rand never fails on supported platforms
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// newLifecycleEvent describes delegation after the event. Stored transaction is
+// nil if delegation is not in database.
+func newLifecycleEvent(
+	eventType string,
+	stakingTxHash chainhash.Hash,
+	storedTx *stakerdb.StoredTransaction,
+) *proto.LifecycleEvent {
+	ev := &proto.LifecycleEvent{
+		Id:            newLifecycleEventId(),
+		Type:          eventType,
+		Timestamp:     time.Now().Unix(),
+		StakingTxHash: stakingTxHash.String(),
+	}
+
+	if storedTx == nil {
+		return ev
+	}
+
+	fpPks := make([]string, len(storedTx.FinalityProvidersBtcPks))
+	for i, pk := range storedTx.FinalityProvidersBtcPks {
+		fpPks[i] = hex.EncodeToString(schnorr.SerializePubKey(pk))
+	}
+
+	ev.State = storedTx.State.String()
+	ev.RequestId = storedTx.RequestId
+	ev.StakingAmount = storedTx.StakingTx.TxOut[storedTx.StakingOutputIndex].Value
+	ev.StakingTime = uint32(storedTx.StakingTime)
+	ev.StakerAddress = storedTx.StakerAddress
+	ev.FinalityProviderPks = fpPks
+	ev.Group = storedTx.Group
+	ev.Metadata = storedTx.Metadata
+	ev.Watched = storedTx.Watched
+
+	if storedTx.StakingTxConfirmationInfo != nil {
+		ev.ConfirmationBlockHash = storedTx.StakingTxConfirmationInfo.BlockHash.String()
+		ev.ConfirmationBlockHeight = storedTx.StakingTxConfirmationInfo.Height
+	}
+
+	return ev
+}
+
+// newDelegationPurgedEvent describes purge of delegation. Purge erases data of
+// the delegation, so the event carries only its staking transaction hash and
+// none of the delegation fields which could identify the customer.
+func newDelegationPurgedEvent(stakingTxHash chainhash.Hash) *proto.LifecycleEvent {
+	return newLifecycleEvent(LifecycleEventDelegationPurged, stakingTxHash, nil)
+}
+
+// publishLifecycleEvent publishes processed staking event to configured event
+// sinks. It must be called after event changed the database.
+func (app *StakerApp) publishLifecycleEvent(event StakingEvent) {
+	if !app.eventPublisher.Enabled() {
+		return
+	}
+
+	stakingTxHash := event.EventId()
+
+	storedTx, err := app.txTracker.GetTransaction(&stakingTxHash)
+
+	if err != nil {
+		// critical errors can be reported for transactions which are not stored
+		storedTx = nil
+	}
+
+	ev := newLifecycleEvent(event.EventDesc(), stakingTxHash, storedTx)
+
+	if critical, ok := event.(*criticalErrorEvent); ok {
+		ev.Error = fmt.Sprintf("%s: %v", critical.additionalContext, critical.err)
+	}
+
+	app.eventPublisher.Publish(ev)
+}
+
+// publishOperatorLifecycleEvent publishes change of delegation made by the
+// operator outside of the staking event loop
+func (app *StakerApp) publishOperatorLifecycleEvent(
+	eventType string,
+	stakingTxHash chainhash.Hash,
+	storedTx *stakerdb.StoredTransaction,
+) {
+	if !app.eventPublisher.Enabled() {
+		return
+	}
+
+	app.eventPublisher.Publish(newLifecycleEvent(eventType, stakingTxHash, storedTx))
+}
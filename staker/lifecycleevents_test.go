@@ -0,0 +1,37 @@
+package staker
+
+import (
+	"testing"
+
+	"github.com/babylonchain/btc-staker/eventsink"
+	"github.com/babylonchain/btc-staker/proto"
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/stretchr/testify/require"
+	pm "google.golang.org/protobuf/proto"
+)
+
+func TestDelegationPurgedEventHasNoDelegationData(t *testing.T) {
+	stakingTxHash := chainhash.Hash{1, 2, 3}
+
+	ev := newDelegationPurgedEvent(stakingTxHash)
+
+	require.Equal(t, LifecycleEventDelegationPurged, ev.Type)
+	require.Equal(t, stakingTxHash.String(), ev.StakingTxHash)
+	require.Empty(t, ev.Metadata)
+
+	// apart from the event envelope, no field may be set
+	stripped := pm.Clone(ev).(*proto.LifecycleEvent)
+	stripped.Id = ""
+	stripped.Type = ""
+	stripped.Timestamp = 0
+	stripped.StakingTxHash = ""
+	require.True(t, pm.Equal(&proto.LifecycleEvent{}, stripped), "unexpected fields in purge event: %v", stripped)
+
+	payload, _, err := eventsink.Encode(ev, scfg.EventSinkFormatJson)
+	require.NoError(t, err)
+	require.Contains(t, string(payload), `"metadata":{}`)
+	require.Contains(t, string(payload), `"staker_address":""`)
+	require.Contains(t, string(payload), `"request_id":""`)
+	require.Contains(t, string(payload), `"group":""`)
+}
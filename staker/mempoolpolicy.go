@@ -0,0 +1,120 @@
+package staker
+
+import (
+	"sync/atomic"
+	"time"
+
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+	"github.com/sirupsen/logrus"
+)
+
+// mempoolPolicy keeps relay fee rates of the connected node, which are used as
+// floors in fee calculation
+type mempoolPolicy struct {
+	cfg *scfg.MempoolPolicyConfig
+
+	minRelayFee   atomic.Int64
+	mempoolMinFee atomic.Int64
+}
+
+func newMempoolPolicy(cfg *scfg.MempoolPolicyConfig) *mempoolPolicy {
+	p := &mempoolPolicy{cfg: cfg}
+
+	// until node is probed, standard policy is assumed
+	minRelayFee := MinFeePerKb
+	if cfg.MinRelayFee > 0 {
+		minRelayFee = btcutil.Amount(cfg.MinRelayFee)
+	}
+
+	p.minRelayFee.Store(int64(minRelayFee))
+	p.mempoolMinFee.Store(int64(minRelayFee))
+
+	return p
+}
+
+// feeFloor returns minimum fee rate in sat/kvbyte of transactions accepted by
+// the connected node
+func (p *mempoolPolicy) feeFloor() btcutil.Amount {
+	floor := MinFeePerKb
+
+	if minRelayFee := btcutil.Amount(p.minRelayFee.Load()); minRelayFee > floor {
+		floor = minRelayFee
+	}
+
+	if mempoolMinFee := btcutil.Amount(p.mempoolMinFee.Load()); mempoolMinFee > floor {
+		floor = mempoolMinFee
+	}
+
+	return floor
+}
+
+// dustRelayFee returns relay fee which makes txrules dust checks match dust
+// relay fee of the node. Dust threshold of txrules is 3 times fee of spending
+// the output at relay fee, while bitcoind uses fee at dust relay fee.
+func (p *mempoolPolicy) dustRelayFee() btcutil.Amount {
+	return btcutil.Amount(p.cfg.DustRelayFee / 3)
+}
+
+// policyFeeEstimator never returns fee rate lower than the node would accept
+type policyFeeEstimator struct {
+	FeeEstimator
+	policy *mempoolPolicy
+}
+
+func (e *policyFeeEstimator) EstimateFeePerKb() chainfee.SatPerKVByte {
+	estimated := e.FeeEstimator.EstimateFeePerKb()
+
+	if floor := chainfee.SatPerKVByte(e.policy.feeFloor()); estimated < floor {
+		return floor
+	}
+
+	return estimated
+}
+
+func (app *StakerApp) probeMempoolPolicy() {
+	policy, err := app.wc.MempoolPolicy()
+
+	if err != nil {
+		app.logger.WithFields(logrus.Fields{
+			"err":         err,
+			"minRelayFee": app.mempoolPolicy.minRelayFee.Load(),
+		}).Warn("Failed to query mempool policy of connected node, using previous values")
+		return
+	}
+
+	minRelayFee := policy.MinRelayFee
+	if app.config.MempoolPolicyConfig.MinRelayFee > 0 {
+		minRelayFee = btcutil.Amount(app.config.MempoolPolicyConfig.MinRelayFee)
+	}
+
+	previousMempoolMinFee := app.mempoolPolicy.mempoolMinFee.Swap(int64(policy.MempoolMinFee))
+	previousMinRelayFee := app.mempoolPolicy.minRelayFee.Swap(int64(minRelayFee))
+
+	app.m.MinRelayFeeRate.Set(float64(minRelayFee))
+	app.m.MempoolMinFeeRate.Set(float64(policy.MempoolMinFee))
+
+	if previousMempoolMinFee != int64(policy.MempoolMinFee) || previousMinRelayFee != int64(minRelayFee) {
+		app.logger.WithFields(logrus.Fields{
+			"minRelayFee":   minRelayFee,
+			"mempoolMinFee": policy.MempoolMinFee,
+		}).Info("Mempool policy of connected node changed")
+	}
+}
+
+func (app *StakerApp) mempoolPolicyLoop(interval time.Duration) {
+	defer app.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			app.probeMempoolPolicy()
+		case <-app.quit:
+			return
+		}
+	}
+}
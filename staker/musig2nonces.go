@@ -0,0 +1,120 @@
+package staker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr/musig2"
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+const (
+	DefaultMuSig2NonceTtl = time.Hour
+	MaxMuSig2NonceTtl     = 7 * 24 * time.Hour
+)
+
+// GenerateMuSig2Nonce generates nonce pair of the key of signerAddress for new
+// MuSig2 signing session and persists it. Public nonce is shared with other
+// signers of the session, secret nonce never leaves the daemon and can be used
+// for one signature only, before the nonce expires. Optional 32 byte message is
+// mixed into nonce generation as additional entropy, as defined in BIP327.
+func (app *StakerApp) GenerateMuSig2Nonce(
+	sessionId string,
+	signerAddress btcutil.Address,
+	message []byte,
+	ttl time.Duration,
+) (*stakerdb.MuSig2Nonce, error) {
+	if message != nil && len(message) != 32 {
+		return nil, fmt.Errorf("message must be 32 bytes long, got %d: %w", len(message), ErrInvalidStakingRequest)
+	}
+
+	if ttl <= 0 || ttl > MaxMuSig2NonceTtl {
+		return nil, fmt.Errorf("nonce ttl must be between 1s and %s: %w", MaxMuSig2NonceTtl, ErrInvalidStakingRequest)
+	}
+
+	if err := app.wc.UnlockWallet(defaultWalletUnlockTimeout); err != nil {
+		return nil, err
+	}
+
+	signerPk, err := app.wc.AddressPublicKey(signerAddress)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot retrieve public key of signer address %s: %w", signerAddress, err)
+	}
+
+	opts := []musig2.NonceGenOption{musig2.WithPublicKey(signerPk)}
+
+	if message != nil {
+		var msg [32]byte
+		copy(msg[:], message)
+		opts = append(opts, musig2.WithNonceMessageAux(msg))
+	}
+
+	nonces, err := musig2.GenNonces(opts...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate musig2 nonces: %w", err)
+	}
+
+	now := time.Now()
+
+	if _, err := app.musig2Nonces.PruneExpired(now); err != nil {
+		return nil, err
+	}
+
+	nonce := &stakerdb.MuSig2Nonce{
+		SessionId: sessionId,
+		SignerPk:  signerPk.SerializeCompressed(),
+		PubNonce:  nonces.PubNonce[:],
+		SecNonce:  nonces.SecNonce[:],
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	if err := app.musig2Nonces.Add(nonce); err != nil {
+		return nil, err
+	}
+
+	app.logger.WithField("sessionId", sessionId).Info("Generated musig2 nonce")
+
+	return withoutSecNonce(nonce), nil
+}
+
+func withoutSecNonce(n *stakerdb.MuSig2Nonce) *stakerdb.MuSig2Nonce {
+	public := *n
+	public.SecNonce = nil
+	return &public
+}
+
+// MuSig2Nonce returns public data of the nonce of the session
+func (app *StakerApp) MuSig2Nonce(sessionId string) (*stakerdb.MuSig2Nonce, error) {
+	nonce, err := app.musig2Nonces.Get(sessionId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return withoutSecNonce(nonce), nil
+}
+
+// UseMuSig2Nonce returns nonces of the session for signing and marks them as
+// used, so that they are never used again. Fails if nonces expired or were
+// already used.
+func (app *StakerApp) UseMuSig2Nonce(sessionId string) (*musig2.Nonces, error) {
+	nonce, err := app.musig2Nonces.Use(sessionId, time.Now())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce.PubNonce) != musig2.PubNonceSize || len(nonce.SecNonce) != musig2.SecNonceSize {
+		return nil, fmt.Errorf("stored nonce of session %s is malformed", sessionId)
+	}
+
+	var nonces musig2.Nonces
+	copy(nonces.PubNonce[:], nonce.PubNonce)
+	copy(nonces.SecNonce[:], nonce.SecNonce)
+
+	return &nonces, nil
+}
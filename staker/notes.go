@@ -0,0 +1,70 @@
+package staker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/sirupsen/logrus"
+)
+
+const annotateDelegationAction = "annotate_delegation"
+
+// AnnotationRequest describes note attached to delegation by the operator
+type AnnotationRequest struct {
+	StakingTxHash chainhash.Hash
+	Note          string
+	// origin of the request, recorded in audit log
+	RequestId  string
+	RemoteAddr string
+}
+
+// AnnotateDelegation attaches timestamped note to the delegation, e.g. manual
+// action performed outside of the daemon. Note is stored with the delegation and
+// recorded in audit log.
+func (app *StakerApp) AnnotateDelegation(req *AnnotationRequest) (*stakerdb.DelegationNote, error) {
+	if req.Note == "" {
+		return nil, fmt.Errorf("note must be provided: %w", ErrInvalidStakingRequest)
+	}
+
+	note := &stakerdb.DelegationNote{
+		Timestamp: time.Now(),
+		Text:      req.Note,
+		RequestId: req.RequestId,
+	}
+
+	if err := app.txTracker.AddTxNote(&req.StakingTxHash, note); err != nil {
+		return nil, err
+	}
+
+	storedTx, err := app.txTracker.GetTransaction(&req.StakingTxHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	app.logger.WithFields(logrus.Fields{
+		"stakingTxHash": req.StakingTxHash,
+		"note":          req.Note,
+		"requestId":     req.RequestId,
+		"remoteAddr":    req.RemoteAddr,
+	}).Info("Delegation annotated by operator")
+
+	err = app.auditLog.AddEntry(&stakerdb.AuditLogEntry{
+		Timestamp:     note.Timestamp,
+		Action:        annotateDelegationAction,
+		StakingTxHash: req.StakingTxHash,
+		PreviousState: storedTx.State,
+		NewState:      storedTx.State,
+		Reason:        req.Note,
+		RequestId:     req.RequestId,
+		RemoteAddr:    req.RemoteAddr,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("note added, but failed to record it in audit log: %w", err)
+	}
+
+	return note, nil
+}
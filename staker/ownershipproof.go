@@ -0,0 +1,95 @@
+package staker
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// Tag used to domain separate ownership proof signatures from any other
+// signatures made by the staker key
+var ownershipProofTag = []byte("btc-staker/ownership-proof")
+
+// OwnershipProof proves that owner of the staker key, which locked funds in
+// given staking transaction, agreed to the provided challenge
+type OwnershipProof struct {
+	StakingTxHash chainhash.Hash
+	StakerPk      *btcec.PublicKey
+	Challenge     string
+	Signature     *schnorr.Signature
+}
+
+// OwnershipProofMessage returns hash which is signed by the staker key, it is
+// BIP340 tagged hash of the challenge and staking transaction hash
+func OwnershipProofMessage(stakingTxHash *chainhash.Hash, challenge string) *chainhash.Hash {
+	return chainhash.TaggedHash(ownershipProofTag, []byte(challenge), stakingTxHash[:])
+}
+
+// VerifyOwnershipProof checks that signature over the challenge and staking
+// transaction hash was made by the given staker key
+func VerifyOwnershipProof(
+	stakingTxHash *chainhash.Hash,
+	stakerPk *btcec.PublicKey,
+	challenge string,
+	signature *schnorr.Signature,
+) error {
+	if challenge == "" {
+		return fmt.Errorf("challenge cannot be empty")
+	}
+
+	msg := OwnershipProofMessage(stakingTxHash, challenge)
+
+	if !signature.Verify(msg[:], stakerPk) {
+		return fmt.Errorf("invalid ownership proof signature")
+	}
+
+	return nil
+}
+
+// ProveOwnership signs provided challenge together with staking transaction hash
+// using staker key of the given staking transaction
+func (app *StakerApp) ProveOwnership(stakingTxHash *chainhash.Hash, challenge string) (*OwnershipProof, error) {
+	if challenge == "" {
+		return nil, fmt.Errorf("challenge cannot be empty")
+	}
+
+	tx, err := app.txTracker.GetTransaction(stakingTxHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tx.WatchOnly() {
+		return nil, fmt.Errorf("cannot prove ownership of watched staking transaction, staker key is not controlled by connected wallet: %w", ErrInvalidTransactionState)
+	}
+
+	stakerAddress, err := btcutil.DecodeAddress(tx.StakerAddress, app.network)
+
+	if err != nil {
+		return nil, fmt.Errorf("error decoding staker address: %w", err)
+	}
+
+	privKey, err := app.stakerPrivateKey(stakerAddress)
+
+	if err != nil {
+		return nil, err
+	}
+
+	msg := OwnershipProofMessage(stakingTxHash, challenge)
+
+	sig, err := schnorr.Sign(privKey, msg[:])
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &OwnershipProof{
+		StakingTxHash: *stakingTxHash,
+		StakerPk:      privKey.PubKey(),
+		Challenge:     challenge,
+		Signature:     sig,
+	}, nil
+}
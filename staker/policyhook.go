@@ -0,0 +1,217 @@
+package staker
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	PolicyActionStake         = "stake"
+	PolicyActionStakeExternal = "stake_external"
+	PolicyActionUnbond        = "unbond"
+	PolicyActionWithdraw      = "withdraw"
+
+	// responses of policy service are small, anything bigger is an error
+	maxPolicyResponseBytes = 64 * 1024
+)
+
+// PolicyRequest is json payload posted to the policy service. Fields which do
+// not apply to the action are omitted.
+type PolicyRequest struct {
+	Action              string            `json:"action"`
+	RequestId           string            `json:"request_id,omitempty"`
+	Timestamp           int64             `json:"timestamp"`
+	StakingTxHash       string            `json:"staking_tx_hash,omitempty"`
+	StakerAddress       string            `json:"staker_address,omitempty"`
+	StakerPk            string            `json:"staker_pk,omitempty"`
+	StakingAmount       int64             `json:"staking_amount,omitempty"`
+	FinalityProviderPks []string          `json:"finality_provider_pks,omitempty"`
+	StakingTimeBlocks   uint16            `json:"staking_time_blocks,omitempty"`
+	FeeRate             int64             `json:"fee_rate,omitempty"`
+	Metadata            map[string]string `json:"metadata,omitempty"`
+}
+
+// PolicyResponse is expected json response of the policy service
+type PolicyResponse struct {
+	Approved bool   `json:"approved"`
+	Reason   string `json:"reason"`
+}
+
+// policyHook asks external policy service for approval of requests which move
+// funds. It allows operators to plug approval workflows without changes to
+// the daemon.
+type policyHook struct {
+	cfg    *scfg.PolicyHookConfig
+	client *http.Client
+}
+
+func newPolicyHook(cfg *scfg.PolicyHookConfig) *policyHook {
+	return &policyHook{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+func (h *policyHook) enabled() bool {
+	return h.cfg.Url != ""
+}
+
+func (h *policyHook) query(ctx context.Context, req *PolicyRequest) (*PolicyResponse, error) {
+	payload, err := json.Marshal(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.cfg.Url, bytes.NewReader(payload))
+
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if req.RequestId != "" {
+		httpReq.Header.Set("X-Request-Id", req.RequestId)
+	}
+
+	resp, err := h.client.Do(httpReq)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPolicyResponseBytes))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var policyResp PolicyResponse
+	if err := json.Unmarshal(body, &policyResp); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return &policyResp, nil
+}
+
+// checkPolicy returns nil if the policy hook is disabled or the request was
+// approved. If the policy service can't be queried, request is rejected unless
+// the hook is configured to fail open.
+func (app *StakerApp) checkPolicy(req *PolicyRequest) error {
+	if !app.policyHook.enabled() {
+		return nil
+	}
+
+	req.Timestamp = time.Now().Unix()
+
+	ctx, cancel := app.appQuitContext()
+	defer cancel()
+
+	logger := app.logger.WithFields(logrus.Fields{
+		"action":        req.Action,
+		"requestId":     req.RequestId,
+		"stakingTxHash": req.StakingTxHash,
+	})
+
+	resp, err := app.policyHook.query(ctx, req)
+
+	if err != nil {
+		if app.config.PolicyHookConfig.FailOpen {
+			app.m.PolicyHookChecks.WithLabelValues(req.Action, "error_allowed").Inc()
+			logger.WithError(err).Warn("Policy service failed. Executing request as policy hook is configured to fail open")
+			return nil
+		}
+
+		app.m.PolicyHookChecks.WithLabelValues(req.Action, "error").Inc()
+		logger.WithError(err).Error("Policy service failed. Rejecting request")
+		return fmt.Errorf("%w: policy service failed: %s", ErrRejectedByPolicy, err)
+	}
+
+	if !resp.Approved {
+		app.m.PolicyHookChecks.WithLabelValues(req.Action, "rejected").Inc()
+		logger.WithField("reason", resp.Reason).Warn("Request rejected by policy service")
+
+		if resp.Reason != "" {
+			return fmt.Errorf("%w: %s", ErrRejectedByPolicy, resp.Reason)
+		}
+		return ErrRejectedByPolicy
+	}
+
+	app.m.PolicyHookChecks.WithLabelValues(req.Action, "approved").Inc()
+	logger.Debug("Request approved by policy service")
+
+	return nil
+}
+
+func policyStakeRequest(
+	action string,
+	stakerAddress btcutil.Address,
+	stakerPk *btcec.PublicKey,
+	stakingAmount btcutil.Amount,
+	fpPks []*btcec.PublicKey,
+	stakingTimeBlocks uint16,
+	metadata map[string]string,
+	requestId string,
+) *PolicyRequest {
+	fpPksHex := make([]string, len(fpPks))
+	for i, pk := range fpPks {
+		fpPksHex[i] = hex.EncodeToString(schnorr.SerializePubKey(pk))
+	}
+
+	req := &PolicyRequest{
+		Action:              action,
+		RequestId:           requestId,
+		StakerAddress:       stakerAddress.EncodeAddress(),
+		StakingAmount:       int64(stakingAmount),
+		FinalityProviderPks: fpPksHex,
+		StakingTimeBlocks:   stakingTimeBlocks,
+		Metadata:            metadata,
+	}
+
+	if stakerPk != nil {
+		req.StakerPk = hex.EncodeToString(schnorr.SerializePubKey(stakerPk))
+	}
+
+	return req
+}
+
+func policyStoredTxRequest(
+	action string,
+	stakingTxHash string,
+	tx *stakerdb.StoredTransaction,
+	feeRate *btcutil.Amount,
+) *PolicyRequest {
+	req := &PolicyRequest{
+		Action:            action,
+		StakingTxHash:     stakingTxHash,
+		StakerAddress:     tx.StakerAddress,
+		StakingAmount:     tx.StakingTx.TxOut[tx.StakingOutputIndex].Value,
+		StakingTimeBlocks: tx.StakingTime,
+		Metadata:          tx.Metadata,
+	}
+
+	if feeRate != nil {
+		req.FeeRate = int64(*feeRate)
+	}
+
+	return req
+}
@@ -0,0 +1,250 @@
+package staker
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	StakingPresetSourceConfig = "config"
+	StakingPresetSourceRpc    = "rpc"
+)
+
+// StakingPreset is named set of staking parameters which can be referenced in
+// stake calls
+type StakingPreset struct {
+	Name              string
+	FinalityProviders []*btcec.PublicKey
+	StakingTimeBlocks uint16
+	// if not empty, staking amount must be one of the tiers
+	AmountTiers []btcutil.Amount
+	// sat/vbyte, 0 if not limited
+	MaxFeeRate uint64
+	// zero for presets from config
+	UpdatedAt time.Time
+	Source    string
+}
+
+// stakingPresets keeps presets from config and presets created through rpc.
+// Presets from config take precedence and can't be changed through rpc.
+type stakingPresets struct {
+	mu      sync.RWMutex
+	presets map[string]*StakingPreset
+}
+
+func newStakingPresets(cfg *scfg.PresetsConfig, store *stakerdb.StakingPresetStore) (*stakingPresets, error) {
+	p := &stakingPresets{
+		presets: make(map[string]*StakingPreset),
+	}
+
+	stored, err := store.Presets()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range stored {
+		p.presets[e.Name] = &StakingPreset{
+			Name:              e.Name,
+			FinalityProviders: e.FinalityProviders,
+			StakingTimeBlocks: e.StakingTimeBlocks,
+			AmountTiers:       e.AmountTiers,
+			MaxFeeRate:        e.MaxFeeRate,
+			UpdatedAt:         e.UpdatedAt,
+			Source:            StakingPresetSourceRpc,
+		}
+	}
+
+	configured, err := cfg.Parse()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range configured {
+		p.presets[c.Name] = &StakingPreset{
+			Name:              c.Name,
+			FinalityProviders: c.FinalityProviders,
+			StakingTimeBlocks: c.StakingTimeBlocks,
+			AmountTiers:       c.AmountTiers,
+			MaxFeeRate:        c.MaxFeeRate,
+			Source:            StakingPresetSourceConfig,
+		}
+	}
+
+	return p, nil
+}
+
+// ResolveAmount returns staking amount for the preset. Zero amount selects the
+// only amount tier of the preset.
+func (p *StakingPreset) ResolveAmount(amount btcutil.Amount) (btcutil.Amount, error) {
+	if amount == 0 {
+		if len(p.AmountTiers) != 1 {
+			return 0, fmt.Errorf("staking amount must be provided for preset %s: %w", p.Name, ErrInvalidStakingRequest)
+		}
+
+		return p.AmountTiers[0], nil
+	}
+
+	if len(p.AmountTiers) == 0 {
+		return amount, nil
+	}
+
+	for _, tier := range p.AmountTiers {
+		if tier == amount {
+			return amount, nil
+		}
+	}
+
+	return 0, fmt.Errorf("staking amount %d is not one of amount tiers %v of preset %s: %w",
+		amount, p.AmountTiers, p.Name, ErrInvalidStakingRequest)
+}
+
+// StakingPresets returns all staking presets ordered by name
+func (app *StakerApp) StakingPresets() []StakingPreset {
+	app.stakingPresets.mu.RLock()
+	defer app.stakingPresets.mu.RUnlock()
+
+	presets := make([]StakingPreset, 0, len(app.stakingPresets.presets))
+
+	for _, p := range app.stakingPresets.presets {
+		presets = append(presets, *p)
+	}
+
+	sort.Slice(presets, func(i, j int) bool {
+		return presets[i].Name < presets[j].Name
+	})
+
+	return presets
+}
+
+// GetStakingPreset returns preset with given name
+func (app *StakerApp) GetStakingPreset(name string) (*StakingPreset, error) {
+	app.stakingPresets.mu.RLock()
+	defer app.stakingPresets.mu.RUnlock()
+
+	p, found := app.stakingPresets.presets[name]
+
+	if !found {
+		return nil, fmt.Errorf("staking preset %s: %w", name, stakerdb.ErrStakingPresetNotFound)
+	}
+
+	preset := *p
+	return &preset, nil
+}
+
+// SetStakingPreset creates new preset or replaces existing preset created
+// through rpc
+func (app *StakerApp) SetStakingPreset(preset *scfg.StakingPreset) error {
+	if err := preset.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", err, ErrInvalidStakingRequest)
+	}
+
+	p := app.stakingPresets
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if existing, found := p.presets[preset.Name]; found && existing.Source == StakingPresetSourceConfig {
+		return fmt.Errorf("preset %s is defined in config and can't be changed through rpc: %w", preset.Name, ErrInvalidStakingRequest)
+	}
+
+	now := time.Now()
+
+	err := app.stakingPresetStore.PutPreset(&stakerdb.StakingPresetEntry{
+		Name:              preset.Name,
+		FinalityProviders: preset.FinalityProviders,
+		StakingTimeBlocks: preset.StakingTimeBlocks,
+		AmountTiers:       preset.AmountTiers,
+		MaxFeeRate:        preset.MaxFeeRate,
+		UpdatedAt:         now,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	p.presets[preset.Name] = &StakingPreset{
+		Name:              preset.Name,
+		FinalityProviders: preset.FinalityProviders,
+		StakingTimeBlocks: preset.StakingTimeBlocks,
+		AmountTiers:       preset.AmountTiers,
+		MaxFeeRate:        preset.MaxFeeRate,
+		UpdatedAt:         now,
+		Source:            StakingPresetSourceRpc,
+	}
+
+	app.logger.WithFields(logrus.Fields{
+		"preset":      preset.Name,
+		"stakingTime": preset.StakingTimeBlocks,
+		"amountTiers": preset.AmountTiers,
+		"maxFeeRate":  preset.MaxFeeRate,
+	}).Info("Staking preset saved")
+
+	return nil
+}
+
+// DeleteStakingPreset removes preset created through rpc. Presets from config
+// can be removed only by changing the config.
+func (app *StakerApp) DeleteStakingPreset(name string) error {
+	p := app.stakingPresets
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if existing, found := p.presets[name]; found && existing.Source == StakingPresetSourceConfig {
+		return fmt.Errorf("preset %s is defined in config and can't be removed through rpc: %w", name, ErrInvalidStakingRequest)
+	}
+
+	if err := app.stakingPresetStore.DeletePreset(name); err != nil {
+		return err
+	}
+
+	delete(p.presets, name)
+
+	app.logger.WithField("preset", name).Info("Staking preset deleted")
+
+	return nil
+}
+
+// StakeFundsWithPreset sends staking transaction with finality providers,
+// staking time and fee limit of given preset. Zero stakingAmount is allowed
+// for presets with single amount tier.
+func (app *StakerApp) StakeFundsWithPreset(
+	stakerAddress btcutil.Address,
+	stakingAmount btcutil.Amount,
+	presetName string,
+	minConfirmations uint32,
+	metadata map[string]string,
+	requestId string,
+) (*chainhash.Hash, error) {
+	preset, err := app.GetStakingPreset(presetName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := preset.ResolveAmount(stakingAmount)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return app.stakeFunds(
+		stakerAddress,
+		amount,
+		preset.FinalityProviders,
+		preset.StakingTimeBlocks,
+		preset.MaxFeeRate,
+		minConfirmations,
+		metadata,
+		requestId,
+	)
+}
@@ -0,0 +1,339 @@
+package staker
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/babylonchain/btc-staker/scriptbuilder"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/babylonchain/btc-staker/utils"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/sirupsen/logrus"
+)
+
+// ReservesOutput is output which locks staked funds, together with all data
+// needed to derive its script
+type ReservesOutput struct {
+	StakingTxHash chainhash.Hash
+	// staking output, or unbonding output if delegation was unbonded and
+	// funds were not withdrawn yet
+	OutPoint            wire.OutPoint
+	Unbonding           bool
+	Amount              btcutil.Amount
+	PkScript            []byte
+	StakerPk            *btcec.PublicKey
+	FinalityProviderPks []*btcec.PublicKey
+	CovenantPks         []*btcec.PublicKey
+	CovenantQuorum      uint32
+	// staking time for staking output, unbonding time for unbonding output
+	LockTime uint16
+}
+
+// pkScript derives script of the output from its staking parameters
+func (o *ReservesOutput) pkScript(builder scriptbuilder.StakingScriptBuilder, net *chaincfg.Params) ([]byte, error) {
+	if o.Unbonding {
+		info, err := builder.BuildUnbondingScripts(
+			o.StakerPk,
+			o.FinalityProviderPks,
+			o.CovenantPks,
+			o.CovenantQuorum,
+			o.LockTime,
+			o.Amount,
+			net,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return info.Output().PkScript, nil
+	}
+
+	info, err := builder.BuildStakingScripts(
+		o.StakerPk,
+		o.FinalityProviderPks,
+		o.CovenantPks,
+		o.CovenantQuorum,
+		o.LockTime,
+		o.Amount,
+		net,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return info.Output().PkScript, nil
+}
+
+// ReservesKeyProof proves control of one staker key, by BIP-322 simple signature
+// of the report message made with the staker address
+type ReservesKeyProof struct {
+	StakerAddress btcutil.Address
+	StakerPk      *btcec.PublicKey
+	Signature     []byte
+	// sum of outputs locked by the key
+	Amount btcutil.Amount
+}
+
+// ReservesReport lists outputs locking funds staked by keys controlled by the
+// wallet, with signature of every staker key over auditor provided message
+type ReservesReport struct {
+	Message     string
+	Network     string
+	BtcHeight   uint32
+	CreatedAt   time.Time
+	Outputs     []ReservesOutput
+	Keys        []ReservesKeyProof
+	TotalAmount btcutil.Amount
+	// delegations which funds are locked, but are not part of the report as
+	// their staker key is not controlled by the wallet
+	SkippedDelegations int
+}
+
+func stakerKeyMatchesAddress(pk *btcec.PublicKey, address btcutil.Address) bool {
+	switch a := address.(type) {
+	case *btcutil.AddressWitnessPubKeyHash:
+		return bytes.Equal(btcutil.Hash160(pk.SerializeCompressed()), a.WitnessProgram())
+	case *btcutil.AddressTaproot:
+		return bytes.Equal(schnorr.SerializePubKey(txscript.ComputeTaprootKeyNoScript(pk)), a.WitnessProgram())
+	default:
+		return false
+	}
+}
+
+// Verify checks that scripts of all outputs are derived from listed staking
+// parameters, that every staker key signed the report message and that amounts
+// add up. It does not check that outputs are still unspent, this must be done
+// against btc chain. Returns all found problems, empty if report is valid.
+func (r *ReservesReport) Verify(builder scriptbuilder.StakingScriptBuilder, net *chaincfg.Params) []error {
+	var errs []error
+
+	if r.Message == "" {
+		errs = append(errs, fmt.Errorf("report message is empty"))
+	}
+
+	if r.Network != net.Name {
+		errs = append(errs, fmt.Errorf("report is for network %s, expected %s", r.Network, net.Name))
+	}
+
+	outpoints := make(map[wire.OutPoint]struct{}, len(r.Outputs))
+	amountByKey := make(map[string]btcutil.Amount)
+	var total btcutil.Amount
+
+	for i := range r.Outputs {
+		o := &r.Outputs[i]
+
+		if _, found := outpoints[o.OutPoint]; found {
+			errs = append(errs, fmt.Errorf("output %s is listed more than once", o.OutPoint))
+			continue
+		}
+
+		outpoints[o.OutPoint] = struct{}{}
+
+		script, err := o.pkScript(builder, net)
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot derive script of output %s: %w", o.OutPoint, err))
+		} else if !bytes.Equal(script, o.PkScript) {
+			errs = append(errs, fmt.Errorf("script of output %s is not derived from its staking parameters", o.OutPoint))
+		}
+
+		amountByKey[string(schnorr.SerializePubKey(o.StakerPk))] += o.Amount
+		total += o.Amount
+	}
+
+	provenKeys := make(map[string]struct{}, len(r.Keys))
+
+	for i := range r.Keys {
+		k := &r.Keys[i]
+		key := string(schnorr.SerializePubKey(k.StakerPk))
+
+		if !stakerKeyMatchesAddress(k.StakerPk, k.StakerAddress) {
+			errs = append(errs, fmt.Errorf("staker key of address %s does not match the address", k.StakerAddress))
+		}
+
+		if err := utils.VerifyBip322Simple(k.StakerAddress, []byte(r.Message), k.Signature); err != nil {
+			errs = append(errs, fmt.Errorf("invalid signature of address %s: %w", k.StakerAddress, err))
+		}
+
+		if k.Amount != amountByKey[key] {
+			errs = append(errs, fmt.Errorf("amount %d of address %s does not match sum %d of its outputs",
+				k.Amount, k.StakerAddress, amountByKey[key]))
+		}
+
+		provenKeys[key] = struct{}{}
+	}
+
+	for key := range amountByKey {
+		if _, found := provenKeys[key]; !found {
+			errs = append(errs, fmt.Errorf("staker key %x has outputs, but no signature", []byte(key)))
+		}
+	}
+
+	if total != r.TotalAmount {
+		errs = append(errs, fmt.Errorf("total amount %d does not match sum %d of outputs", r.TotalAmount, total))
+	}
+
+	return errs
+}
+
+// reservesOutputOfTx returns output locking funds of the delegation without
+// staker key, nil if funds are not locked by staking or unbonding output
+func reservesOutputOfTx(
+	tx *stakerdb.StoredTransaction,
+	covenantPks []*btcec.PublicKey,
+	covenantQuorum uint32,
+) *ReservesOutput {
+	stakingTxHash := tx.StakingTx.TxHash()
+
+	out := &ReservesOutput{
+		StakingTxHash:       stakingTxHash,
+		FinalityProviderPks: tx.FinalityProvidersBtcPks,
+		CovenantPks:         covenantPks,
+		CovenantQuorum:      covenantQuorum,
+	}
+
+	switch tx.State {
+	case proto.TransactionState_CONFIRMED_ON_BTC,
+		proto.TransactionState_SENT_TO_BABYLON,
+		proto.TransactionState_DELEGATION_ACTIVE:
+		stakingOutput := tx.StakingTx.TxOut[tx.StakingOutputIndex]
+		out.OutPoint = *wire.NewOutPoint(&stakingTxHash, tx.StakingOutputIndex)
+		out.Amount = btcutil.Amount(stakingOutput.Value)
+		out.PkScript = stakingOutput.PkScript
+		out.LockTime = tx.StakingTime
+	case proto.TransactionState_UNBONDING_CONFIRMED_ON_BTC:
+		if tx.UnbondingTxData == nil || tx.UnbondingTxData.UnbondingTx == nil {
+			return nil
+		}
+
+		unbondingTxHash := tx.UnbondingTxData.UnbondingTx.TxHash()
+		unbondingOutput := tx.UnbondingTxData.UnbondingTx.TxOut[0]
+		out.OutPoint = *wire.NewOutPoint(&unbondingTxHash, 0)
+		out.Unbonding = true
+		out.Amount = btcutil.Amount(unbondingOutput.Value)
+		out.PkScript = unbondingOutput.PkScript
+		out.LockTime = tx.UnbondingTxData.UnbondingTime
+	default:
+		return nil
+	}
+
+	return out
+}
+
+// ProofOfReserves creates report of funds locked in staking and unbonding
+// outputs by staker keys controlled by the wallet. Every staker key signs the
+// message, which should be a challenge provided by the auditor.
+func (app *StakerApp) ProofOfReserves(message string) (*ReservesReport, error) {
+	if message == "" {
+		return nil, fmt.Errorf("message cannot be empty: %w", ErrInvalidStakingRequest)
+	}
+
+	params, err := app.babylonClient.Params()
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting params: %w", err)
+	}
+
+	var txs []*stakerdb.StoredTransaction
+
+	err = app.txTracker.ScanTrackedTransactions(func(tx *stakerdb.StoredTransaction) error {
+		txs = append(txs, tx)
+		return nil
+	}, func() {
+		txs = nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReservesReport{
+		Message:   message,
+		Network:   app.network.Name,
+		BtcHeight: app.currentBestBlockHeight.Load(),
+		CreatedAt: time.Now(),
+	}
+
+	keys := make(map[string]*ReservesKeyProof)
+
+	for _, tx := range txs {
+		out := reservesOutputOfTx(tx, params.CovenantPks, params.CovenantQuruomThreshold)
+
+		if out == nil {
+			continue
+		}
+
+		// staker keys of watched delegations and external staker keys are not
+		// controlled by the wallet, so they can't sign the report
+		if tx.WatchOnly() || tx.ExternalStakerBtcPk != nil {
+			report.SkippedDelegations++
+			continue
+		}
+
+		proof, found := keys[tx.StakerAddress]
+
+		if !found {
+			stakerAddress, err := btcutil.DecodeAddress(tx.StakerAddress, app.network)
+
+			if err != nil {
+				return nil, fmt.Errorf("error decoding staker address: %w", err)
+			}
+
+			privKey, err := app.stakerPrivateKey(stakerAddress)
+
+			if err != nil {
+				return nil, err
+			}
+
+			sig, err := utils.SignBip322Simple(privKey, stakerAddress, []byte(message))
+
+			if err != nil {
+				return nil, fmt.Errorf("failed to sign message with staker address %s: %w", stakerAddress, err)
+			}
+
+			proof = &ReservesKeyProof{
+				StakerAddress: stakerAddress,
+				StakerPk:      privKey.PubKey(),
+				Signature:     sig,
+			}
+		}
+
+		out.StakerPk = proof.StakerPk
+
+		// the same check as when building spend paths, script can't be derived
+		// if covenant committee changed after staking transaction was created
+		if script, err := out.pkScript(app.scriptBuilder, app.network); err != nil || !bytes.Equal(script, out.PkScript) {
+			app.logger.WithFields(logrus.Fields{
+				"stakingTxHash": out.StakingTxHash,
+			}).Warn("Output of delegation can't be derived from current parameters, skipping it in proof of reserves")
+			report.SkippedDelegations++
+			continue
+		}
+
+		keys[tx.StakerAddress] = proof
+		proof.Amount += out.Amount
+		report.TotalAmount += out.Amount
+		report.Outputs = append(report.Outputs, *out)
+	}
+
+	for _, k := range keys {
+		report.Keys = append(report.Keys, *k)
+	}
+
+	sort.Slice(report.Keys, func(i, j int) bool {
+		return report.Keys[i].StakerAddress.EncodeAddress() < report.Keys[j].StakerAddress.EncodeAddress()
+	})
+
+	return report, nil
+}
@@ -0,0 +1,91 @@
+package staker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/sirupsen/logrus"
+)
+
+const purgeDelegationAction = "purge_delegation"
+
+// PurgeRequest describes permanent removal of delegation record requested by
+// the operator
+type PurgeRequest struct {
+	StakingTxHash chainhash.Hash
+	Reason        string
+	// origin of the request, recorded in audit log
+	RequestId  string
+	RemoteAddr string
+}
+
+// PurgeDelegation permanently removes delegation record and its metadata,
+// together with all other stored data and exported exit templates referencing
+// the delegation. Only delegations in terminal state can be purged, as the
+// daemon no longer needs them. Purge is recorded in audit log, which keeps only
+// staking transaction hash and reason of the purge.
+func (app *StakerApp) PurgeDelegation(req *PurgeRequest) error {
+	if req.Reason == "" {
+		return fmt.Errorf("reason of purge must be provided: %w", ErrInvalidStakingRequest)
+	}
+
+	storedTx, err := app.txTracker.GetTransaction(&req.StakingTxHash)
+
+	if err != nil {
+		return err
+	}
+
+	if storedTx.State != proto.TransactionState_SPENT_ON_BTC {
+		return fmt.Errorf("cannot purge delegation in state %s, only delegations in state %s can be purged: %w",
+			storedTx.State, proto.TransactionState_SPENT_ON_BTC, ErrInvalidTransactionState)
+	}
+
+	if err := app.txTracker.DeleteTransaction(&req.StakingTxHash, storedTx.State); err != nil {
+		return err
+	}
+
+	if exportDir := app.config.ExitTemplatesConfig.ExportDir; exportDir != "" {
+		if err := removeExitTemplates(exportDir, req.StakingTxHash.String()); err != nil {
+			app.reportCriticialError(req.StakingTxHash, err, "Failed to remove exported exit templates")
+			return fmt.Errorf("delegation purged, but failed to remove its exported exit templates: %w", err)
+		}
+	}
+
+	for op := range proto.RetryOperation_name {
+		app.completeRetry(proto.RetryOperation(op), &req.StakingTxHash)
+	}
+
+	app.requestIds.Delete(req.StakingTxHash)
+
+	if app.eventPublisher.Enabled() {
+		app.eventPublisher.Publish(newDelegationPurgedEvent(req.StakingTxHash))
+	}
+
+	app.logger.WithFields(logrus.Fields{
+		"stakingTxHash": req.StakingTxHash,
+		"reason":        req.Reason,
+		"requestId":     req.RequestId,
+		"remoteAddr":    req.RemoteAddr,
+	}).Warn("Delegation purged by operator")
+
+	err = app.auditLog.AddEntry(&stakerdb.AuditLogEntry{
+		Timestamp:     time.Now(),
+		Action:        purgeDelegationAction,
+		StakingTxHash: req.StakingTxHash,
+		PreviousState: storedTx.State,
+		NewState:      storedTx.State,
+		Reason:        req.Reason,
+		RequestId:     req.RequestId,
+		RemoteAddr:    req.RemoteAddr,
+	})
+
+	if err != nil {
+		app.reportCriticialError(req.StakingTxHash, err, "Failed to record purge in audit log")
+		return fmt.Errorf("delegation purged, but failed to record it in audit log: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package staker
+
+import "github.com/btcsuite/btcd/chaincfg/chainhash"
+
+// trackRequestId remembers id of the rpc request which created staking transaction,
+// so that all logs related to this transaction can be correlated with the request
+func (app *StakerApp) trackRequestId(stakingTxHash chainhash.Hash, requestId string) {
+	if requestId == "" {
+		return
+	}
+
+	app.requestIds.Store(stakingTxHash, requestId)
+}
+
+// requestIdOf returns id of the rpc request which created staking transaction
+// related to the event, or empty string if it is unknown. Ids which are not
+// cached are loaded from database, as request id is stored with transaction.
+func (app *StakerApp) requestIdOf(event StakingEvent) string {
+	if req, ok := event.(*stakingRequestedEvent); ok {
+		return req.requestId
+	}
+
+	stakingTxHash := event.EventId()
+
+	if requestId, found := app.requestIds.Load(stakingTxHash); found {
+		return requestId
+	}
+
+	storedTx, err := app.txTracker.GetTransaction(&stakingTxHash)
+
+	if err != nil {
+		return ""
+	}
+
+	// transactions without request id are cached as well, so that logging
+	// does not hit database on every event
+	app.requestIds.Store(stakingTxHash, storedTx.RequestId)
+
+	return storedTx.RequestId
+}
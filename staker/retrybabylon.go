@@ -0,0 +1,104 @@
+package staker
+
+import (
+	"fmt"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/sirupsen/logrus"
+)
+
+// RetryBabylonDelegation re-submits delegation of the staking transaction to
+// babylon and returns hash of the babylon transaction. It is meant for
+// delegations stuck after babylon rejected them, which are not retried
+// automatically.
+//
+// Without force, delegation must be confirmed on btc and not yet known to
+// babylon. With force, delegation is re-submitted also in later states, e.g when
+// babylon chain was reset. Delegation which is not confirmed on btc can't be
+// submitted in any case, as it requires inclusion proof.
+func (app *StakerApp) RetryBabylonDelegation(stakingTxHash *chainhash.Hash, force bool) (string, error) {
+	// check we are not shutting down
+	select {
+	case <-app.quit:
+		return "", ErrStakerStopped
+	default:
+	}
+
+	storedTx, err := app.txTracker.GetTransaction(stakingTxHash)
+
+	if err != nil {
+		return "", err
+	}
+
+	if storedTx.State == proto.TransactionState_SENT_TO_BTC {
+		return "", fmt.Errorf("cannot send delegation of staking transaction not confirmed on btc: %w", ErrInvalidTransactionState)
+	}
+
+	if storedTx.State != proto.TransactionState_CONFIRMED_ON_BTC && !force {
+		return "", fmt.Errorf("delegation is already in state %s, use force to send it again: %w", storedTx.State, ErrInvalidTransactionState)
+	}
+
+	if !force {
+		alreadyDelegated, err := app.babylonClient.IsTxAlreadyPartOfDelegation(stakingTxHash)
+
+		if err != nil {
+			return "", err
+		}
+
+		if alreadyDelegated {
+			return "", fmt.Errorf("delegation is already known to babylon, use force to send it again: %w", ErrInvalidTransactionState)
+		}
+	}
+
+	// make sure retry queue does not send the same delegation concurrently
+	key := retryQueueKey{op: proto.RetryOperation_SEND_DELEGATION_TO_BABYLON, txHash: *stakingTxHash}
+
+	if !app.retryQueue.tryStart(key) {
+		return "", fmt.Errorf("delegation is being sent by retry queue: %w", ErrInvalidTransactionState)
+	}
+	defer app.retryQueue.finish(key)
+
+	stakerAddress, err := btcutil.DecodeAddress(storedTx.StakerAddress, app.network)
+
+	if err != nil {
+		return "", fmt.Errorf("error decoding staker address: %s. Err: %v", storedTx.StakerAddress, err)
+	}
+
+	req, err := app.sendDelegationRequestFromWallet(stakingTxHash, storedTx)
+
+	if err != nil {
+		return "", err
+	}
+
+	logger := app.logger.WithFields(logrus.Fields{
+		"stakingTxHash": stakingTxHash,
+		"state":         storedTx.State,
+		"force":         force,
+		"requestId":     storedTx.RequestId,
+	})
+
+	logger.Info("Re-sending delegation to babylon on operator request")
+
+	app.m.PendingBabylonSubmissions.Inc()
+	resp, delegationData, err := app.buildAndSendDelegation(req, stakerAddress, storedTx)
+	app.m.PendingBabylonSubmissions.Dec()
+
+	if err != nil {
+		logger.WithError(err).Error("Failed to re-send delegation to babylon")
+		return "", err
+	}
+
+	app.completeRetry(proto.RetryOperation_SEND_DELEGATION_TO_BABYLON, stakingTxHash)
+
+	// delegations in later states already went through SENT_TO_BABYLON transition,
+	// moving them back would break their state machine
+	if storedTx.State == proto.TransactionState_CONFIRMED_ON_BTC {
+		app.reportDelegationSubmitted(req, delegationData)
+	}
+
+	logger.WithField("babylonTxHash", resp.TxHash).Info("Delegation re-sent to babylon")
+
+	return resp.TxHash, nil
+}
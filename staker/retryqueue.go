@@ -0,0 +1,339 @@
+package staker
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// how often retry queue is checked for operations which are due
+	retryQueuePollInterval = 1 * time.Second
+
+	// delay is randomized by +/- retryJitterFraction to avoid retrying many
+	// operations at the same time
+	retryJitterFraction = 0.2
+)
+
+type retryQueueKey struct {
+	op     proto.RetryOperation
+	txHash chainhash.Hash
+}
+
+// retryQueue keeps track of retried operations which are currently executing,
+// so that scheduler does not start them twice. Queue itself is persisted in
+// stakerdb.RetryQueueStore
+type retryQueue struct {
+	store *stakerdb.RetryQueueStore
+
+	mu      sync.Mutex
+	running map[retryQueueKey]struct{}
+
+	wakeup chan struct{}
+}
+
+func newRetryQueue(store *stakerdb.RetryQueueStore) *retryQueue {
+	return &retryQueue{
+		store:   store,
+		running: make(map[retryQueueKey]struct{}),
+		wakeup:  make(chan struct{}, 1),
+	}
+}
+
+// tryStart marks operation as running, returns false if it is already running
+func (q *retryQueue) tryStart(key retryQueueKey) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, running := q.running[key]; running {
+		return false
+	}
+
+	q.running[key] = struct{}{}
+	return true
+}
+
+func (q *retryQueue) finish(key retryQueueKey) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.running, key)
+}
+
+func (q *retryQueue) wake() {
+	select {
+	case q.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// sortRetryQueueEntries sorts entries by priority of operation and then by time
+// of next attempt
+func sortRetryQueueEntries(entries []stakerdb.RetryQueueEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Operation != entries[j].Operation {
+			return entries[i].Operation < entries[j].Operation
+		}
+		return entries[i].NextAttempt.Before(entries[j].NextAttempt)
+	})
+}
+
+func (app *StakerApp) retryBaseDelay(op proto.RetryOperation) time.Duration {
+	switch op {
+	case proto.RetryOperation_SEND_DELEGATION_TO_BABYLON:
+		return app.config.StakerConfig.BabylonStallingInterval
+	default:
+		return unbondingSendRetryTimeout
+	}
+}
+
+// retryDelay returns jittered exponential backoff delay after given number of
+// failed attempts
+func (app *StakerApp) retryDelay(op proto.RetryOperation, attempts uint32) time.Duration {
+	maxDelay := app.config.StakerConfig.RetryMaxDelay
+	delay := app.retryBaseDelay(op)
+
+	for i := uint32(1); i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	jitter := 1 - retryJitterFraction + 2*retryJitterFraction*rand.Float64()
+	return time.Duration(float64(delay) * jitter)
+}
+
+// scheduleRetry persists failed operation in retry queue. Operation stays in
+// the queue until it succeeds, so it is never silently dropped. After RetryMaxAttempts
+// failures critical error is reported, but operation is still retried.
+func (app *StakerApp) scheduleRetry(op proto.RetryOperation, stakingTxHash *chainhash.Hash, opErr error) {
+	now := time.Now()
+
+	entry, err := app.retryQueue.store.GetEntry(op, stakingTxHash)
+
+	if errors.Is(err, stakerdb.ErrRetryQueueEntryNotFound) {
+		entry = &stakerdb.RetryQueueEntry{
+			Operation:     op,
+			StakingTxHash: *stakingTxHash,
+			CreatedAt:     now,
+		}
+	} else if err != nil {
+		app.reportCriticialError(*stakingTxHash, err, fmt.Sprintf("Failed to read retry queue entry for operation %s", op))
+		return
+	}
+
+	entry.Attempts++
+	entry.LastError = opErr.Error()
+	entry.NextAttempt = now.Add(app.retryDelay(op, entry.Attempts))
+
+	if err := app.retryQueue.store.PutEntry(entry); err != nil {
+		app.reportCriticialError(*stakingTxHash, err, fmt.Sprintf("Failed to schedule retry of operation %s", op))
+		return
+	}
+
+	app.m.RetryQueueScheduledOperations.WithLabelValues(strings.ToLower(op.String())).Inc()
+
+	app.logger.WithFields(logrus.Fields{
+		"operation":     op,
+		"stakingTxHash": stakingTxHash,
+		"attempt":       entry.Attempts,
+		"nextAttempt":   entry.NextAttempt,
+		"err":           opErr,
+	}).Error("Operation failed. Scheduled retry")
+
+	if entry.Attempts == app.config.StakerConfig.RetryMaxAttempts {
+		app.reportCriticialError(
+			*stakingTxHash,
+			opErr,
+			fmt.Sprintf("Operation %s failed %d times. It stays in retry queue", op, entry.Attempts),
+		)
+	}
+}
+
+// completeRetry removes operation from retry queue after it succeeded or failed
+// with unrecoverable error
+func (app *StakerApp) completeRetry(op proto.RetryOperation, stakingTxHash *chainhash.Hash) {
+	if err := app.retryQueue.store.DeleteEntry(op, stakingTxHash); err != nil {
+		app.logger.WithFields(logrus.Fields{
+			"operation":     op,
+			"stakingTxHash": stakingTxHash,
+			"err":           err,
+		}).Error("Failed to remove operation from retry queue")
+	}
+}
+
+func (app *StakerApp) isScheduledForRetry(op proto.RetryOperation, stakingTxHash *chainhash.Hash) bool {
+	_, err := app.retryQueue.store.GetEntry(op, stakingTxHash)
+	return err == nil
+}
+
+// RetryQueue returns all operations scheduled for retry, sorted by priority
+func (app *StakerApp) RetryQueue() ([]stakerdb.RetryQueueEntry, error) {
+	entries, err := app.retryQueue.store.Entries()
+
+	if err != nil {
+		return nil, err
+	}
+
+	sortRetryQueueEntries(entries)
+	return entries, nil
+}
+
+// FlushRetryQueue schedules all queued operations for immediate retry. If op
+// is provided, only operations of this type are flushed. Returns number of
+// flushed operations.
+func (app *StakerApp) FlushRetryQueue(op *proto.RetryOperation) (int, error) {
+	entries, err := app.retryQueue.store.Entries()
+
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	flushed := 0
+
+	for i := range entries {
+		entry := entries[i]
+
+		if op != nil && entry.Operation != *op {
+			continue
+		}
+
+		entry.NextAttempt = now
+
+		if err := app.retryQueue.store.PutEntry(&entry); err != nil {
+			return flushed, err
+		}
+
+		flushed++
+	}
+
+	app.retryQueue.wake()
+	return flushed, nil
+}
+
+// retryQueueLoop dispatches queued operations which are due. It must be run in
+// separate go routine.
+func (app *StakerApp) retryQueueLoop() {
+	defer app.wg.Done()
+
+	ticker := time.NewTicker(retryQueuePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			app.dispatchDueRetries()
+		case <-app.retryQueue.wakeup:
+			app.dispatchDueRetries()
+		case <-app.quit:
+			return
+		}
+	}
+}
+
+func (app *StakerApp) dispatchDueRetries() {
+	entries, err := app.retryQueue.store.Entries()
+
+	if err != nil {
+		app.logger.WithFields(logrus.Fields{
+			"err": err,
+		}).Error("Failed to read retry queue")
+		return
+	}
+
+	app.m.RetryQueueLength.Set(float64(len(entries)))
+
+	sortRetryQueueEntries(entries)
+	now := time.Now()
+
+	for i := range entries {
+		entry := entries[i]
+
+		if entry.NextAttempt.After(now) {
+			continue
+		}
+
+		key := retryQueueKey{op: entry.Operation, txHash: entry.StakingTxHash}
+
+		if !app.retryQueue.tryStart(key) {
+			continue
+		}
+
+		app.logger.WithFields(logrus.Fields{
+			"operation":     entry.Operation,
+			"stakingTxHash": entry.StakingTxHash,
+			"attempt":       entry.Attempts + 1,
+		}).Info("Retrying operation")
+
+		app.wg.Add(1)
+		go func() {
+			defer app.wg.Done()
+			defer app.retryQueue.finish(key)
+			app.retryOperation(&entry)
+		}()
+	}
+}
+
+func (app *StakerApp) retryOperation(entry *stakerdb.RetryQueueEntry) {
+	stakingTxHash := &entry.StakingTxHash
+	storedTx, err := app.txTracker.GetTransaction(stakingTxHash)
+
+	if err != nil {
+		app.reportCriticialError(*stakingTxHash, err, fmt.Sprintf("Failed to retrieve transaction for retried operation %s", entry.Operation))
+		return
+	}
+
+	stakerAddress, err := btcutil.DecodeAddress(storedTx.StakerAddress, app.network)
+
+	if err != nil {
+		app.reportCriticialError(*stakingTxHash, err, fmt.Sprintf("Failed to decode staker address for retried operation %s", entry.Operation))
+		return
+	}
+
+	switch entry.Operation {
+	case proto.RetryOperation_SEND_DELEGATION_TO_BABYLON:
+		if storedTx.State != proto.TransactionState_CONFIRMED_ON_BTC {
+			// delegation was already delivered by other means
+			app.completeRetry(entry.Operation, stakingTxHash)
+			return
+		}
+
+		req, err := app.sendDelegationRequestFromWallet(stakingTxHash, storedTx)
+
+		if err != nil {
+			app.scheduleRetry(entry.Operation, stakingTxHash, err)
+			return
+		}
+
+		app.wg.Add(1)
+		app.sendDelegationToBabylonTask(req, stakerAddress, storedTx)
+	case proto.RetryOperation_SEND_UNBONDING_TX_TO_BTC:
+		if storedTx.State != proto.TransactionState_DELEGATION_ACTIVE {
+			// unbonding transaction was already confirmed or staking output
+			// was spent by other means
+			app.completeRetry(entry.Operation, stakingTxHash)
+			return
+		}
+
+		app.wg.Add(1)
+		app.sendUnbondingTxToBtcTask(stakingTxHash, stakerAddress, storedTx, storedTx.UnbondingTxData)
+	default:
+		app.logger.WithFields(logrus.Fields{
+			"operation":     entry.Operation,
+			"stakingTxHash": stakingTxHash,
+		}).Error("Unknown operation in retry queue. Removing it")
+		app.completeRetry(entry.Operation, stakingTxHash)
+	}
+}
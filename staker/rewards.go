@@ -0,0 +1,104 @@
+package staker
+
+import (
+	"fmt"
+
+	cl "github.com/babylonchain/btc-staker/babylonclient"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/sirupsen/logrus"
+)
+
+type BabylonRewards struct {
+	// Babylon address which accumulates rewards of staker delegations
+	Address string
+	Gauges  map[string]*cl.RewardGauge
+}
+
+type RewardsWithdrawal struct {
+	TxHash         string
+	WithdrawnCoins sdk.Coins
+	// Empty if rewards were withdrawn to the staker babylon address
+	Recipient string
+}
+
+func (app *StakerApp) stakerBabylonAddress() string {
+	return sdk.MustBech32ifyAddressBytes(
+		app.config.BabylonConfig.AccountPrefix,
+		app.babylonClient.GetKeyAddress(),
+	)
+}
+
+// BabylonRewards returns rewards accumulated on Babylon by the staker babylon key
+func (app *StakerApp) BabylonRewards() (*BabylonRewards, error) {
+	gauges, err := app.babylonClient.QueryRewardGauges(app.babylonClient.GetKeyAddress())
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rewards: %w", err)
+	}
+
+	return &BabylonRewards{
+		Address: app.stakerBabylonAddress(),
+		Gauges:  gauges,
+	}, nil
+}
+
+// WithdrawBabylonRewards withdraws all rewards of given stakeholder type. If recipient
+// is empty, recipient from config is used. If there is no recipient configured,
+// rewards stay on the staker babylon address.
+func (app *StakerApp) WithdrawBabylonRewards(stakeholderType string, recipient string) (*RewardsWithdrawal, error) {
+	if stakeholderType == "" {
+		stakeholderType = cl.BTCDelegationRewardType
+	}
+
+	if recipient == "" {
+		recipient = app.config.BabylonConfig.RewardRecipient
+	}
+
+	var recipientAddress sdk.AccAddress
+	if recipient != "" {
+		bz, err := sdk.GetFromBech32(recipient, app.config.BabylonConfig.AccountPrefix)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid reward recipient address %s: %w", recipient, err)
+		}
+
+		recipientAddress = sdk.AccAddress(bz)
+	}
+
+	gauges, err := app.babylonClient.QueryRewardGauges(app.babylonClient.GetKeyAddress())
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rewards: %w", err)
+	}
+
+	gauge, found := gauges[stakeholderType]
+
+	if !found {
+		return nil, fmt.Errorf("there are no rewards of type %s", stakeholderType)
+	}
+
+	withdrawable := gauge.Withdrawable()
+
+	if withdrawable.IsZero() {
+		return nil, fmt.Errorf("there are no withdrawable rewards of type %s", stakeholderType)
+	}
+
+	resp, err := app.babylonClient.WithdrawRewards(stakeholderType, recipientAddress, withdrawable)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to withdraw rewards: %w", err)
+	}
+
+	app.logger.WithFields(logrus.Fields{
+		"type":      stakeholderType,
+		"amount":    withdrawable,
+		"recipient": recipient,
+		"txHash":    resp.TxHash,
+	}).Info("Withdrawn Babylon rewards")
+
+	return &RewardsWithdrawal{
+		TxHash:         resp.TxHash,
+		WithdrawnCoins: withdrawable,
+		Recipient:      recipient,
+	}, nil
+}
@@ -0,0 +1,330 @@
+package staker
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// how often scheduled operations are checked for operations which are due
+	scheduledOperationsPollInterval = 30 * time.Second
+)
+
+// scheduledOperations keeps btc height of the last failed attempt of each
+// operation, so that failed operations are retried at most once per block, and
+// time at which height based operations became due. Operations themselves are
+// persisted in stakerdb.ScheduledOperationStore
+type scheduledOperations struct {
+	store *stakerdb.ScheduledOperationStore
+
+	// accessed only from scheduledOperationsLoop
+	lastAttemptHeight map[uint64]uint32
+	dueSince          map[uint64]time.Time
+
+	// serializes scheduling of automatic withdrawals, which happens both from
+	// the event loop and rpc handlers
+	autoWithdrawMu sync.Mutex
+}
+
+func newScheduledOperations(store *stakerdb.ScheduledOperationStore) *scheduledOperations {
+	return &scheduledOperations{
+		store:             store,
+		lastAttemptHeight: make(map[uint64]uint32),
+		dueSince:          make(map[uint64]time.Time),
+	}
+}
+
+func (s *scheduledOperations) forget(id uint64) {
+	delete(s.lastAttemptHeight, id)
+	delete(s.dueSince, id)
+}
+
+// forgetCancelled drops state of operations which are no longer scheduled
+func (s *scheduledOperations) forgetCancelled(scheduled []stakerdb.ScheduledOperation) {
+	ids := make(map[uint64]struct{}, len(scheduled))
+	for _, o := range scheduled {
+		ids[o.Id] = struct{}{}
+	}
+
+	for id := range s.lastAttemptHeight {
+		if _, found := ids[id]; !found {
+			delete(s.lastAttemptHeight, id)
+		}
+	}
+
+	for id := range s.dueSince {
+		if _, found := ids[id]; !found {
+			delete(s.dueSince, id)
+		}
+	}
+}
+
+// waitingSince returns time since which due operation waits for execution. For
+// operations scheduled at height it is the time at which the daemon noticed
+// that the operation is due.
+func (s *scheduledOperations) waitingSince(o *stakerdb.ScheduledOperation, now time.Time) time.Time {
+	if o.ExecuteAtHeight == 0 {
+		return o.ExecuteAtTime
+	}
+
+	since, found := s.dueSince[o.Id]
+
+	if !found {
+		since = now
+		s.dueSince[o.Id] = since
+	}
+
+	return since
+}
+
+func isDue(o *stakerdb.ScheduledOperation, currentHeight uint32, now time.Time) bool {
+	if o.ExecuteAtHeight != 0 {
+		return currentHeight >= o.ExecuteAtHeight
+	}
+
+	return !now.Before(o.ExecuteAtTime)
+}
+
+// ScheduleOperation persists operation on staking transaction, which is executed
+// once btc best block reaches executeAtHeight or at executeAtTime. Exactly one of
+// them must be set. Validity of the operation is checked once again when it is
+// executed, as state of the transaction can change in the meantime.
+func (app *StakerApp) ScheduleOperation(
+	op proto.ScheduledOperationType,
+	stakingTxHash *chainhash.Hash,
+	executeAtHeight uint32,
+	executeAtTime time.Time,
+	note string,
+) (*stakerdb.ScheduledOperation, error) {
+	if (executeAtHeight == 0) == executeAtTime.IsZero() {
+		return nil, fmt.Errorf("exactly one of execution height and execution time must be provided: %w", ErrInvalidStakingRequest)
+	}
+
+	now := time.Now()
+
+	if executeAtHeight != 0 && executeAtHeight <= app.currentBestBlockHeight.Load() {
+		return nil, fmt.Errorf("execution height %d is not above current best block height %d: %w",
+			executeAtHeight, app.currentBestBlockHeight.Load(), ErrInvalidStakingRequest)
+	}
+
+	if !executeAtTime.IsZero() && !executeAtTime.After(now) {
+		return nil, fmt.Errorf("execution time %s is not in the future: %w", executeAtTime, ErrInvalidStakingRequest)
+	}
+
+	tx, err := app.txTracker.GetTransaction(stakingTxHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tx.WatchOnly() {
+		return nil, fmt.Errorf("cannot schedule operation on watched transaction: %w", ErrInvalidTransactionState)
+	}
+
+	scheduled, err := app.scheduledOps.store.Operations()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range scheduled {
+		if o.Operation == op && o.StakingTxHash == *stakingTxHash {
+			return nil, fmt.Errorf("operation %s is already scheduled for transaction %s with id %d: %w",
+				op, stakingTxHash, o.Id, ErrInvalidTransactionState)
+		}
+	}
+
+	operation := &stakerdb.ScheduledOperation{
+		Operation:       op,
+		StakingTxHash:   *stakingTxHash,
+		ExecuteAtHeight: executeAtHeight,
+		ExecuteAtTime:   executeAtTime,
+		Note:            note,
+		CreatedAt:       now,
+	}
+
+	if err := app.scheduledOps.store.AddOperation(operation); err != nil {
+		return nil, err
+	}
+
+	app.logger.WithFields(logrus.Fields{
+		"id":              operation.Id,
+		"operation":       op,
+		"stakingTxHash":   stakingTxHash,
+		"executeAtHeight": executeAtHeight,
+		"executeAtTime":   executeAtTime,
+	}).Info("Scheduled operation")
+
+	return operation, nil
+}
+
+// CancelScheduledOperation removes operation which was not executed yet
+func (app *StakerApp) CancelScheduledOperation(id uint64) error {
+	if err := app.scheduledOps.store.DeleteOperation(id); err != nil {
+		return err
+	}
+
+	app.logger.WithField("id", id).Info("Cancelled scheduled operation")
+
+	return nil
+}
+
+// ScheduledOperations returns all operations waiting for execution
+func (app *StakerApp) ScheduledOperations() ([]stakerdb.ScheduledOperation, error) {
+	return app.scheduledOps.store.Operations()
+}
+
+func (app *StakerApp) scheduledOperationsLoop() {
+	defer app.wg.Done()
+
+	ticker := time.NewTicker(scheduledOperationsPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			app.executeDueOperations()
+		case <-app.quit:
+			return
+		}
+	}
+}
+
+func (app *StakerApp) executeDueOperations() {
+	operations, err := app.scheduledOps.store.Operations()
+
+	if err != nil {
+		app.logger.WithError(err).Error("Failed to read scheduled operations")
+		return
+	}
+
+	app.scheduledOps.forgetCancelled(operations)
+
+	currentHeight := app.currentBestBlockHeight.Load()
+	now := time.Now()
+
+	for i := range operations {
+		o := &operations[i]
+
+		if !isDue(o, currentHeight, now) {
+			continue
+		}
+
+		lastAttempt, attempted := app.scheduledOps.lastAttemptHeight[o.Id]
+
+		if attempted && lastAttempt >= currentHeight {
+			continue
+		}
+
+		// withdrawals are not urgent, so they wait for low fee window. Fee of
+		// unbonding transaction is fixed when it is signed, so there is no point
+		// in waiting.
+		if o.Operation == proto.ScheduledOperationType_SCHEDULED_WITHDRAW {
+			logger := app.logger.WithFields(logrus.Fields{
+				"id":            o.Id,
+				"stakingTxHash": o.StakingTxHash,
+			})
+
+			if !app.nonUrgentTxAllowed(app.scheduledOps.waitingSince(o, now), logger) {
+				continue
+			}
+
+			if o.Automatic && !app.autoWithdrawFeeAllowed(logger) {
+				continue
+			}
+		}
+
+		select {
+		case <-app.quit:
+			return
+		default:
+		}
+
+		app.executeScheduledOperation(o, currentHeight)
+	}
+}
+
+func (app *StakerApp) executeScheduledOperation(o *stakerdb.ScheduledOperation, currentHeight uint32) {
+	logger := app.logger.WithFields(logrus.Fields{
+		"id":            o.Id,
+		"operation":     o.Operation,
+		"stakingTxHash": o.StakingTxHash,
+	})
+
+	var (
+		txHash *chainhash.Hash
+		err    error
+	)
+
+	switch o.Operation {
+	case proto.ScheduledOperationType_SCHEDULED_UNBOND:
+		txHash, err = app.UnbondStaking(o.StakingTxHash, nil, nil)
+	case proto.ScheduledOperationType_SCHEDULED_WITHDRAW:
+		if o.Automatic {
+			txHash, _, err = app.spendStake(&o.StakingTxHash, app.autoWithdrawDest)
+		} else {
+			txHash, _, err = app.SpendStake(&o.StakingTxHash)
+		}
+	default:
+		err = fmt.Errorf("unknown scheduled operation %s", o.Operation)
+	}
+
+	// nil hash without error means app is shutting down
+	if err == nil && txHash == nil {
+		return
+	}
+
+	if err == nil {
+		app.scheduledOps.forget(o.Id)
+
+		if err := app.scheduledOps.store.DeleteOperation(o.Id); err != nil {
+			logger.WithError(err).Error("Failed to remove executed scheduled operation")
+		}
+
+		logger.WithField("txHash", txHash).Info("Executed scheduled operation")
+
+		if o.Automatic {
+			if tx, err := app.txTracker.GetTransaction(&o.StakingTxHash); err == nil {
+				app.publishOperatorLifecycleEvent(LifecycleEventAutoWithdrawExecuted, o.StakingTxHash, tx)
+			}
+		}
+
+		return
+	}
+
+	// operation can't succeed in current state of the transaction, retrying it
+	// would only fail again
+	if errors.Is(err, ErrInvalidTransactionState) ||
+		errors.Is(err, ErrRejectedByPolicy) ||
+		errors.Is(err, stakerdb.ErrTransactionNotFound) {
+		app.scheduledOps.forget(o.Id)
+
+		if err := app.scheduledOps.store.DeleteOperation(o.Id); err != nil {
+			logger.WithError(err).Error("Failed to remove scheduled operation")
+		}
+
+		logger.WithError(err).Error("Scheduled operation can't be executed. Removed it from schedule")
+		return
+	}
+
+	app.scheduledOps.lastAttemptHeight[o.Id] = currentHeight
+
+	o.Attempts++
+	o.LastError = err.Error()
+
+	if err := app.scheduledOps.store.UpdateOperation(o); err != nil {
+		logger.WithError(err).Error("Failed to update scheduled operation")
+	}
+
+	logger.WithFields(logrus.Fields{
+		"attempt": o.Attempts,
+		"err":     err,
+	}).Error("Scheduled operation failed. It will be retried after next btc block")
+}
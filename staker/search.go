@@ -0,0 +1,95 @@
+package staker
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+// TransactionSearch defines which stored transactions are returned by search.
+// Transaction must match all provided criteria.
+type TransactionSearch struct {
+	// Matched case insensitively as prefix of staking tx hash or finality
+	// provider public key, or as substring of metadata key or value, group or
+	// finality provider alias. Empty text matches all transactions.
+	Text string
+	// Zero means no bound
+	MinAmount btcutil.Amount
+	MaxAmount btcutil.Amount
+}
+
+func (s *TransactionSearch) matches(tx *stakerdb.StoredTransaction, fpAliases map[string]string) bool {
+	amount := btcutil.Amount(tx.StakingTx.TxOut[tx.StakingOutputIndex].Value)
+
+	if s.MinAmount > 0 && amount < s.MinAmount {
+		return false
+	}
+
+	if s.MaxAmount > 0 && amount > s.MaxAmount {
+		return false
+	}
+
+	text := strings.ToLower(strings.TrimSpace(s.Text))
+
+	if text == "" {
+		return true
+	}
+
+	if strings.HasPrefix(tx.StakingTx.TxHash().String(), text) {
+		return true
+	}
+
+	for k, v := range tx.Metadata {
+		if strings.Contains(strings.ToLower(k), text) || strings.Contains(strings.ToLower(v), text) {
+			return true
+		}
+	}
+
+	if strings.Contains(strings.ToLower(tx.Group), text) {
+		return true
+	}
+
+	for _, pk := range tx.FinalityProvidersBtcPks {
+		pkHex := hex.EncodeToString(schnorr.SerializePubKey(pk))
+
+		if strings.HasPrefix(pkHex, text) {
+			return true
+		}
+
+		if alias, found := fpAliases[pkHex]; found && strings.Contains(strings.ToLower(alias), text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SearchStoredTransactions returns at most limit stored transactions with index
+// greater than offset, which match the search and time filter
+func (app *StakerApp) SearchStoredTransactions(
+	limit, offset uint64,
+	search TransactionSearch,
+	timeFilter TimeFilter,
+) (*stakerdb.StoredTransactionQueryResult, error) {
+	query := stakerdb.StoredTransactionQuery{
+		IndexOffset:        offset,
+		NumMaxTransactions: limit,
+		Reversed:           false,
+	}
+
+	query = timeFilter.apply(query)
+
+	query = query.WithMatchFunc(func(tx *stakerdb.StoredTransaction) bool {
+		return search.matches(tx, app.fpAliases)
+	})
+
+	resp, err := app.txTracker.QueryStoredTransactions(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,285 @@
+package staker
+
+import (
+	"fmt"
+
+	staking "github.com/babylonchain/babylon/btcstaking"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	SpendPathStakingTimeLock   = "staking_timelock"
+	SpendPathStakingUnbonding  = "staking_unbonding"
+	SpendPathStakingSlashing   = "staking_slashing"
+	SpendPathUnbondingTimeLock = "unbonding_timelock"
+	SpendPathUnbondingSlashing = "unbonding_slashing"
+	SpendPathTaprootKey        = "taproot_key"
+	SpendPathP2WPKH            = "p2wpkh"
+	SpendPathP2SHP2WPKH        = "p2sh_p2wpkh"
+	SpendPathP2PKH             = "p2pkh"
+
+	SignatureTypeSchnorr = "schnorr"
+	SignatureTypeEcdsa   = "ecdsa"
+)
+
+// InputSigHash is digest which must be signed to spend input of the transaction
+// through one of its spend paths
+type InputSigHash struct {
+	InputIndex    int
+	SpendPath     string
+	SignatureType string
+	SigHashType   txscript.SigHashType
+	// only set for taproot script path spends
+	LeafScript []byte
+	SigHash    []byte
+}
+
+// spentOutput is output spent by the transaction input together with ways it
+// can be spent
+type spentOutput struct {
+	output *wire.TxOut
+	// script paths of staking and unbonding outputs, by spend path name
+	scriptPaths []namedSpendPath
+	// only set for P2SH outputs
+	redeemScript []byte
+}
+
+type namedSpendPath struct {
+	name string
+	info *staking.SpendInfo
+}
+
+func (app *StakerApp) delegationSpentOutputs(stakingTxHash *chainhash.Hash, outputs map[wire.OutPoint]*spentOutput) error {
+	tx, err := app.txTracker.GetTransaction(stakingTxHash)
+
+	if err != nil {
+		return err
+	}
+
+	stakerPubKey, err := app.stakerPubKeyForTx(tx)
+
+	if err != nil {
+		return fmt.Errorf("cannot retrieve staker public key: %w", err)
+	}
+
+	params, err := app.babylonClient.Params()
+
+	if err != nil {
+		return fmt.Errorf("error getting params: %w", err)
+	}
+
+	scriptsInfo, err := buildStakingScriptsInfo(
+		stakerPubKey,
+		params.CovenantPks,
+		params.CovenantQuruomThreshold,
+		tx,
+		app.scriptBuilder,
+		app.network,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	outputs[*wire.NewOutPoint(stakingTxHash, tx.StakingOutputIndex)] = &spentOutput{
+		output: scriptsInfo.StakingOutput,
+		scriptPaths: []namedSpendPath{
+			{name: SpendPathStakingTimeLock, info: scriptsInfo.TimeLockPath},
+			{name: SpendPathStakingUnbonding, info: scriptsInfo.UnbondingPath},
+			{name: SpendPathStakingSlashing, info: scriptsInfo.SlashingPath},
+		},
+	}
+
+	if tx.UnbondingTxData == nil || tx.UnbondingTxData.UnbondingTx == nil {
+		return nil
+	}
+
+	unbondingScriptsInfo, err := buildUnbondingScriptsInfo(
+		stakerPubKey,
+		params.CovenantPks,
+		params.CovenantQuruomThreshold,
+		tx,
+		app.scriptBuilder,
+		app.network,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	unbondingTxHash := tx.UnbondingTxData.UnbondingTx.TxHash()
+	outputs[*wire.NewOutPoint(&unbondingTxHash, 0)] = &spentOutput{
+		output: unbondingScriptsInfo.UnbondingOutput,
+		scriptPaths: []namedSpendPath{
+			{name: SpendPathUnbondingTimeLock, info: unbondingScriptsInfo.TimeLockPath},
+			{name: SpendPathUnbondingSlashing, info: unbondingScriptsInfo.SlashingPath},
+		},
+	}
+
+	return nil
+}
+
+// ComputeSigHashes returns sighashes of all inputs of the transaction, for every
+// path through which the input can be spent. Spent outputs must be either
+// outputs of the wallet, or staking and unbonding outputs of tracked
+// delegations. Outputs of staking transactions spent by the transaction are
+// found automatically, unbonding outputs only of the delegation with
+// stakingTxHash, if provided. Taproot inputs use SIGHASH_DEFAULT and other
+// inputs SIGHASH_ALL, the same as transactions signed by the daemon.
+func (app *StakerApp) ComputeSigHashes(tx *wire.MsgTx, stakingTxHash *chainhash.Hash) ([]InputSigHash, error) {
+	if len(tx.TxIn) == 0 {
+		return nil, fmt.Errorf("transaction has no inputs")
+	}
+
+	outputs := make(map[wire.OutPoint]*spentOutput)
+
+	if stakingTxHash != nil {
+		if err := app.delegationSpentOutputs(stakingTxHash, outputs); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, txIn := range tx.TxIn {
+		prevHash := txIn.PreviousOutPoint.Hash
+
+		if _, found := outputs[txIn.PreviousOutPoint]; found {
+			continue
+		}
+
+		if _, err := app.txTracker.GetTransaction(&prevHash); err == nil {
+			if err := app.delegationSpentOutputs(&prevHash, outputs); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	walletOutputs, err := app.wc.ListOutputs(false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, utxo := range walletOutputs {
+		if _, found := outputs[utxo.OutPoint]; found {
+			continue
+		}
+
+		outputs[utxo.OutPoint] = &spentOutput{
+			output:       wire.NewTxOut(int64(utxo.Amount), utxo.PkScript),
+			redeemScript: utxo.RedeemScript,
+		}
+	}
+
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for i, txIn := range tx.TxIn {
+		spent, found := outputs[txIn.PreviousOutPoint]
+
+		if !found {
+			return nil, fmt.Errorf("output %s spent by input %d is neither wallet output nor output of tracked delegation", txIn.PreviousOutPoint, i)
+		}
+
+		fetcher.AddPrevOut(txIn.PreviousOutPoint, spent.output)
+	}
+
+	sigHashes := txscript.NewTxSigHashes(tx, fetcher)
+
+	var result []InputSigHash
+	for i, txIn := range tx.TxIn {
+		spent := outputs[txIn.PreviousOutPoint]
+
+		if len(spent.scriptPaths) > 0 {
+			for _, path := range spent.scriptPaths {
+				sigHash, err := txscript.CalcTapscriptSignaturehash(
+					sigHashes, txscript.SigHashDefault, tx, i, fetcher, path.info.RevealedLeaf,
+				)
+
+				if err != nil {
+					return nil, fmt.Errorf("failed to calculate sighash of input %d: %w", i, err)
+				}
+
+				result = append(result, InputSigHash{
+					InputIndex:    i,
+					SpendPath:     path.name,
+					SignatureType: SignatureTypeSchnorr,
+					SigHashType:   txscript.SigHashDefault,
+					LeafScript:    path.info.RevealedLeaf.Script,
+					SigHash:       sigHash,
+				})
+			}
+
+			continue
+		}
+
+		inputSigHash, err := keySpendSigHash(tx, sigHashes, fetcher, i, spent)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate sighash of input %d: %w", i, err)
+		}
+
+		result = append(result, *inputSigHash)
+	}
+
+	return result, nil
+}
+
+func keySpendSigHash(
+	tx *wire.MsgTx,
+	sigHashes *txscript.TxSigHashes,
+	fetcher txscript.PrevOutputFetcher,
+	idx int,
+	spent *spentOutput,
+) (*InputSigHash, error) {
+	pkScript := spent.output.PkScript
+
+	var (
+		spendPath string
+		sigHash   []byte
+		err       error
+	)
+
+	switch txscript.GetScriptClass(pkScript) {
+	case txscript.WitnessV1TaprootTy:
+		sigHash, err = txscript.CalcTaprootSignatureHash(sigHashes, txscript.SigHashDefault, tx, idx, fetcher)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &InputSigHash{
+			InputIndex:    idx,
+			SpendPath:     SpendPathTaprootKey,
+			SignatureType: SignatureTypeSchnorr,
+			SigHashType:   txscript.SigHashDefault,
+			SigHash:       sigHash,
+		}, nil
+	case txscript.WitnessV0PubKeyHashTy:
+		spendPath = SpendPathP2WPKH
+		sigHash, err = txscript.CalcWitnessSigHash(pkScript, sigHashes, txscript.SigHashAll, tx, idx, spent.output.Value)
+	case txscript.ScriptHashTy:
+		if !txscript.IsPayToWitnessPubKeyHash(spent.redeemScript) {
+			return nil, fmt.Errorf("only P2SH outputs nesting P2WPKH are supported")
+		}
+
+		spendPath = SpendPathP2SHP2WPKH
+		sigHash, err = txscript.CalcWitnessSigHash(spent.redeemScript, sigHashes, txscript.SigHashAll, tx, idx, spent.output.Value)
+	case txscript.PubKeyHashTy:
+		spendPath = SpendPathP2PKH
+		sigHash, err = txscript.CalcSignatureHash(pkScript, txscript.SigHashAll, tx, idx)
+	default:
+		return nil, fmt.Errorf("unsupported script type: %s", txscript.GetScriptClass(pkScript))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &InputSigHash{
+		InputIndex:    idx,
+		SpendPath:     spendPath,
+		SignatureType: SignatureTypeEcdsa,
+		SigHashType:   txscript.SigHashAll,
+		SigHash:       sigHash,
+	}, nil
+}
@@ -0,0 +1,31 @@
+package staker
+
+import (
+	"fmt"
+
+	"github.com/babylonchain/btc-staker/utils"
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+// SignMessage signs arbitrary message with the key controlling given staker address.
+// Signature follows BIP-322 simple format i.e it is serialized witness of
+// the virtual to_sign transaction.
+func (app *StakerApp) SignMessage(stakerAddress btcutil.Address, message string) ([]byte, error) {
+	if !stakerAddress.IsForNet(app.network) {
+		return nil, fmt.Errorf("address %s is not for network %s", stakerAddress, app.network.Name)
+	}
+
+	privKey, err := app.stakerPrivateKey(stakerAddress)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.SignBip322Simple(privKey, stakerAddress, []byte(message))
+}
+
+// VerifyMessage verifies BIP-322 simple signature of the message made by the owner
+// of given address
+func VerifyMessage(address btcutil.Address, message string, signature []byte) error {
+	return utils.VerifyBip322Simple(address, []byte(message), signature)
+}
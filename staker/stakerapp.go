@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -11,11 +12,15 @@ import (
 	pv "github.com/cosmos/relayer/v2/relayer/provider"
 	"go.uber.org/zap"
 
-	"github.com/avast/retry-go/v4"
 	staking "github.com/babylonchain/babylon/btcstaking"
+	bbn "github.com/babylonchain/babylon/types"
 	cl "github.com/babylonchain/btc-staker/babylonclient"
+	"github.com/babylonchain/btc-staker/eventsink"
+	"github.com/babylonchain/btc-staker/faultinjection"
 	"github.com/babylonchain/btc-staker/metrics"
+	"github.com/babylonchain/btc-staker/mockbabylon"
 	"github.com/babylonchain/btc-staker/proto"
+	"github.com/babylonchain/btc-staker/scriptbuilder"
 	scfg "github.com/babylonchain/btc-staker/stakercfg"
 	"github.com/babylonchain/btc-staker/stakerdb"
 	"github.com/babylonchain/btc-staker/types"
@@ -53,36 +58,6 @@ type stakingDbInfo struct {
 	stakingTxState proto.TransactionState
 }
 
-// TODO: stop-gap solution for long running retry operations. Ultimately we need to
-// bound number of total pending bonding/unboning operation.
-var (
-	longRetryNum      = uint(30)
-	longRetryAttempts = retry.Attempts(longRetryNum)
-	RtyErr            = retry.LastErrorOnly(true)
-)
-
-func longRetryOps(ctx context.Context, fixedDelay time.Duration, onRetryFn retry.OnRetryFunc) []retry.Option {
-	return []retry.Option{
-		retry.Context(ctx),
-		retry.DelayType(retry.FixedDelay),
-		retry.Delay(fixedDelay),
-		longRetryAttempts,
-		retry.OnRetry(onRetryFn),
-		RtyErr,
-	}
-}
-
-func (app *StakerApp) onLongRetryFunc(stakingTxHash *chainhash.Hash, msg string) retry.OnRetryFunc {
-	return func(n uint, err error) {
-		app.logger.WithFields(logrus.Fields{
-			"attempt":      n + 1,
-			"max_attempts": longRetryNum,
-			"error":        err,
-			"txHash":       stakingTxHash,
-		}).Error(msg)
-	}
-}
-
 const (
 	// Internal slashing fee to adjust to in case babylon provide too small fee
 	// Slashing tx is around 113 bytes (depending on output address which we need to chose), with fee 8sats/b
@@ -137,11 +112,84 @@ type StakerApp struct {
 	notifier         notifier.ChainNotifier
 	feeEstimator     FeeEstimator
 	network          *chaincfg.Params
+	scriptBuilder    scriptbuilder.StakingScriptBuilder
 	config           *scfg.Config
 	logger           *logrus.Logger
 	txTracker        *stakerdb.TrackedTransactionStore
+	confTracker      *confirmationTracker
+	retryQueue       *retryQueue
+	auditLog         *stakerdb.AuditLogStore
+	feeSpends        *stakerdb.FeeSpendStore
 	babylonMsgSender *cl.BabylonMsgSender
 	m                *metrics.StakerMetrics
+	signings         *signingLimiter
+	unconfirmedTxs   *unconfirmedTxLimiter
+
+	// outputs which must never be used to fund transactions
+	utxoBlocklist      *utxoBlocklist
+	utxoBlocklistStore *stakerdb.UtxoBlocklistStore
+
+	// outputs reserved by the operator for non staking purposes
+	frozenOutputs     *frozenOutputs
+	frozenOutputStore *stakerdb.FrozenOutputStore
+
+	// unbondings and withdrawals scheduled for execution in the future
+	scheduledOps *scheduledOperations
+
+	// nonces of musig2 signing sessions
+	musig2Nonces *stakerdb.MuSig2NonceStore
+
+	// named staking parameters referenced in stake calls
+	stakingPresets     *stakingPresets
+	stakingPresetStore *stakerdb.StakingPresetStore
+
+	// aliases of finality providers keyed by hex encoded public key, used in search
+	fpAliases map[string]string
+
+	// time since which automatic consolidation waits for low fee window,
+	// accessed only from consolidateOutputsLoop
+	consolidationWaitingSince time.Time
+
+	// relay fee rates of connected node
+	mempoolPolicy *mempoolPolicy
+
+	withdrawalPolicy *withdrawalPolicy
+
+	// destination of automatic withdrawals, nil if funds are withdrawn to
+	// staker address
+	autoWithdrawDest btcutil.Address
+
+	// endpoints to which sent transactions are announced in addition to the
+	// wallet node
+	broadcastEndpoints []walletcontroller.BroadcastEndpoint
+
+	// external service approving requests which move funds
+	policyHook *policyHook
+
+	// testnet faucet topping up babylon fee account
+	faucet *faucetHook
+
+	// message brokers receiving lifecycle events of delegations
+	eventPublisher *eventsink.Publisher
+
+	// secondary btc backend used when wallet node is unreachable or stalls,
+	// nil if failover is disabled
+	failover *walletcontroller.FailoverWalletController
+
+	// delegations waiting for covenant signatures and delegations of external
+	// staker keys waiting for registration on babylon
+	unbondingSigsPoller      *delegationPoller
+	externalDelegationPoller *delegationPoller
+
+	// last checked balance of babylon fee account
+	babylonBalance babylonBalanceState
+
+	// last comparison of babylon btc light client with btc node
+	btcLightClient btcLightClientState
+
+	// wraps wallet and babylon clients in binaries built with faultinjection
+	// tag, nil if clients were provided by the caller
+	faultInjector *faultinjection.Injector
 
 	stakingRequestedEvChan                        chan *stakingRequestedEvent
 	stakingTxBtcConfirmedEvChan                   chan *stakingTxBtcConfirmedEvent
@@ -150,7 +198,18 @@ type StakerApp struct {
 	unbondingTxConfirmedOnBtcEvChan               chan *unbondingTxConfirmedOnBtcEvent
 	spendStakeTxConfirmedOnBtcEvChan              chan *spendStakeTxConfirmedOnBtcEvent
 	criticalErrorEvChan                           chan *criticalErrorEvent
+	eventLoopPingChan                             chan chan struct{}
 	currentBestBlockHeight                        atomic.Uint32
+
+	// set while unbond all run is in progress
+	unbondAllRunning atomic.Bool
+
+	newBlockListenersMu sync.Mutex
+	newBlockListeners   []func(height uint32)
+
+	// ids of rpc requests which created staking transactions, used only for logging.
+	// Bounded, evicted ids are loaded again from database
+	requestIds *utils.LruCache[chainhash.Hash, string]
 }
 
 func NewStakerAppFromConfig(
@@ -167,18 +226,96 @@ func NewStakerAppFromConfig(
 		return nil, err
 	}
 
+	var wallet walletcontroller.WalletController = walletClient
+	var failover *walletcontroller.FailoverWalletController
+
+	if config.FailoverConfig.Enabled() {
+		failover, err = walletcontroller.NewFailoverWalletController(
+			walletClient,
+			config.FailoverConfig,
+			newBtcBackendSwitchHandler(logger, m),
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to create failover btc backend: %w", err)
+		}
+
+		wallet = failover
+	}
+
+	faultInjector := faultinjection.NewInjector(logger)
+
+	if faultinjection.Enabled {
+		logger.Warn("Daemon is built with fault injection, it must not be used with real funds")
+	}
+
 	tracker, err := stakerdb.NewTrackedTransactionStore(db)
 
 	if err != nil {
 		return nil, err
 	}
 
-	babylonClient, err := cl.NewBabylonController(config.BabylonConfig, &config.ActiveNetParams, logger, rpcClientLogger)
+	retryQueueStore, err := stakerdb.NewRetryQueueStore(db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	auditLog, err := stakerdb.NewAuditLogStore(db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	feeSpends, err := stakerdb.NewFeeSpendStore(db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	utxoBlocklistStore, err := stakerdb.NewUtxoBlocklistStore(db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	frozenOutputStore, err := stakerdb.NewFrozenOutputStore(db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	scheduledOperationStore, err := stakerdb.NewScheduledOperationStore(db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	musig2NonceStore, err := stakerdb.NewMuSig2NonceStore(db)
 
 	if err != nil {
 		return nil, err
 	}
 
+	stakingPresetStore, err := stakerdb.NewStakingPresetStore(db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var babylonClient cl.BabylonClient
+
+	if config.BabylonConfig.MockURL != "" {
+		logger.WithField("url", config.BabylonConfig.MockURL).Warn("Using mock babylon, it must not be used with real funds")
+		babylonClient = mockbabylon.NewClient(config.BabylonConfig.MockURL, &config.ActiveNetParams)
+	} else {
+		babylonClient, err = cl.NewBabylonController(config.BabylonConfig, &config.ActiveNetParams, logger, rpcClientLogger, m.Babylon)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	hintCache, err := channeldb.NewHeightHintCache(
 		channeldb.CacheConfig{
 			// TODO: Investigate this option. Lighting docs mention that this is necessary for some edge case
@@ -209,19 +346,38 @@ func NewStakerAppFromConfig(
 		return nil, fmt.Errorf("unknown fee estimation mode: %d", config.BtcNodeBackendConfig.EstimationMode)
 	}
 
-	babylonMsgSender := cl.NewBabylonMsgSender(babylonClient, logger, config.StakerConfig.MaxConcurrentTransactions)
+	faultyBabylonClient := faultInjector.WrapBabylonClient(babylonClient)
+
+	babylonMsgSender := cl.NewBabylonMsgSender(faultyBabylonClient, logger, config.StakerConfig.MaxConcurrentTransactions)
 
-	return NewStakerAppFromDeps(
+	app, err := NewStakerAppFromDeps(
 		config,
 		logger,
-		babylonClient,
-		walletClient,
+		faultyBabylonClient,
+		faultInjector.WrapWallet(wallet),
 		nodeNotifier,
 		feeEstimator,
 		tracker,
+		retryQueueStore,
+		auditLog,
+		feeSpends,
+		utxoBlocklistStore,
+		frozenOutputStore,
+		scheduledOperationStore,
+		musig2NonceStore,
+		stakingPresetStore,
 		babylonMsgSender,
 		m,
 	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	app.faultInjector = faultInjector
+	app.failover = failover
+
+	return app, nil
 }
 
 func NewStakerAppFromDeps(
@@ -232,18 +388,132 @@ func NewStakerAppFromDeps(
 	nodeNotifier notifier.ChainNotifier,
 	feeEestimator FeeEstimator,
 	tracker *stakerdb.TrackedTransactionStore,
+	retryQueueStore *stakerdb.RetryQueueStore,
+	auditLog *stakerdb.AuditLogStore,
+	feeSpends *stakerdb.FeeSpendStore,
+	utxoBlocklistStore *stakerdb.UtxoBlocklistStore,
+	frozenOutputStore *stakerdb.FrozenOutputStore,
+	scheduledOperationStore *stakerdb.ScheduledOperationStore,
+	musig2NonceStore *stakerdb.MuSig2NonceStore,
+	stakingPresetStore *stakerdb.StakingPresetStore,
 	babylonMsgSender *cl.BabylonMsgSender,
 	metrics *metrics.StakerMetrics,
 ) (*StakerApp, error) {
-	return &StakerApp{
-		babylonClient:          cl,
-		wc:                     walletClient,
-		notifier:               nodeNotifier,
-		feeEstimator:           feeEestimator,
-		network:                &config.ActiveNetParams,
-		txTracker:              tracker,
-		babylonMsgSender:       babylonMsgSender,
-		m:                      metrics,
+	blocklist, err := newUtxoBlocklist(config.UtxoBlocklistConfig, &config.ActiveNetParams, utxoBlocklistStore)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to load utxo blocklist: %w", err)
+	}
+
+	frozen, err := newFrozenOutputs(frozenOutputStore)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to load frozen outputs: %w", err)
+	}
+
+	presets, err := newStakingPresets(config.PresetsConfig, stakingPresetStore)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to load staking presets: %w", err)
+	}
+
+	fpAliases, err := config.FpAliasConfig.ParseAliases()
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid finality provider aliases: %w", err)
+	}
+
+	broadcastEndpoints, err := walletcontroller.NewBroadcastEndpoints(config.BroadcastConfig, &config.ActiveNetParams)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create broadcast endpoints: %w", err)
+	}
+
+	withdrawals, err := newWithdrawalPolicy(config.WithdrawalPolicyConfig, &config.ActiveNetParams)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to load withdrawal policy: %w", err)
+	}
+
+	autoWithdrawDest, err := config.AutoWithdrawConfig.DestinationAddress(&config.ActiveNetParams)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid auto withdraw destination: %w", err)
+	}
+
+	// fail early instead of failing every automatic withdrawal
+	if autoWithdrawDest != nil {
+		if err := withdrawals.checkAddress(autoWithdrawDest); err != nil {
+			return nil, fmt.Errorf("auto withdraw destination rejected by withdrawal policy: %w", err)
+		}
+	}
+
+	// fail early instead of failing every automatic consolidation
+	if config.ConsolidationConfig.Interval > 0 {
+		consolidationAddress, err := btcutil.DecodeAddress(config.ConsolidationConfig.Address, &config.ActiveNetParams)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid consolidation address: %w", err)
+		}
+
+		if err := withdrawals.checkAddress(consolidationAddress); err != nil {
+			return nil, fmt.Errorf("consolidation address rejected by withdrawal policy: %w", err)
+		}
+	}
+
+	builder, err := scriptbuilder.Get(config.StakerConfig.ScriptBuilder)
+
+	if err != nil {
+		return nil, err
+	}
+
+	policy := newMempoolPolicy(config.MempoolPolicyConfig)
+
+	walletClient.SetUtxoFilter(func(utxo *walletcontroller.Utxo) bool {
+		return blocklist.allowed(utxo) && frozen.allowed(utxo)
+	})
+
+	app := &StakerApp{
+		babylonClient:    cl,
+		wc:               walletClient,
+		notifier:         nodeNotifier,
+		feeEstimator:     &policyFeeEstimator{FeeEstimator: feeEestimator, policy: policy},
+		mempoolPolicy:    policy,
+		withdrawalPolicy: withdrawals,
+		network:          &config.ActiveNetParams,
+		scriptBuilder:    builder,
+		txTracker:        tracker,
+		confTracker:      newConfirmationTracker(walletClient, nodeNotifier, logger, metrics),
+		retryQueue:       newRetryQueue(retryQueueStore),
+		auditLog:         auditLog,
+		feeSpends:        feeSpends,
+		babylonMsgSender: babylonMsgSender,
+		m:                metrics,
+		signings: newSigningLimiter(
+			config.StakerConfig.MaxConcurrentSignings,
+			metrics.QueuedStakingRequests,
+			metrics.SigningsInProgress,
+		),
+		unconfirmedTxs: newUnconfirmedTxLimiter(
+			config.StakerConfig.MaxUnconfirmedStakingTxs,
+			metrics.QueuedStakingRequests,
+			metrics.UnconfirmedStakingTransactions,
+		),
+		utxoBlocklist:          blocklist,
+		utxoBlocklistStore:     utxoBlocklistStore,
+		frozenOutputs:          frozen,
+		frozenOutputStore:      frozenOutputStore,
+		scheduledOps:           newScheduledOperations(scheduledOperationStore),
+		autoWithdrawDest:       autoWithdrawDest,
+		musig2Nonces:           musig2NonceStore,
+		stakingPresets:         presets,
+		stakingPresetStore:     stakingPresetStore,
+		fpAliases:              fpAliases,
+		broadcastEndpoints:     broadcastEndpoints,
+		policyHook:             newPolicyHook(config.PolicyHookConfig),
+		faucet:                 newFaucetHook(config.FaucetConfig),
+		eventPublisher:         eventsink.NewPublisher(config.EventSinkConfig, logger, metrics.FailedEventSinkPublications),
+		requestIds:             utils.NewLruCache[chainhash.Hash, string](config.CacheConfig.RequestIds),
 		config:                 config,
 		logger:                 logger,
 		quit:                   make(chan struct{}),
@@ -268,7 +538,26 @@ func NewStakerAppFromDeps(
 		// how to handle, so we just log them. It is up to user to investigate, what had happend
 		// and report the situation
 		criticalErrorEvChan: make(chan *criticalErrorEvent),
-	}, nil
+
+		// channel which receives pings checking that event loop is not stuck
+		eventLoopPingChan: make(chan chan struct{}),
+	}
+
+	app.unbondingSigsPoller = newDelegationPoller(
+		config.StakerConfig.UnbondingTxCheckInterval,
+		config.StakerConfig.BabylonPollWorkers,
+		app.prepareUnbondingTxSignaturesCheck,
+	)
+
+	app.externalDelegationPoller = newDelegationPoller(
+		config.StakerConfig.BabylonStallingInterval,
+		config.StakerConfig.BabylonPollWorkers,
+		func() (delegationCheck, bool) {
+			return app.checkExternalDelegationOnBabylon, true
+		},
+	)
+
+	return app, nil
 }
 
 func (app *StakerApp) Start() error {
@@ -288,6 +577,11 @@ func (app *StakerApp) Start() error {
 
 		app.logger.Infof("Successfully connected to node backend: %s", app.config.BtcNodeBackendConfig.Nodetype)
 
+		if err := app.confTracker.start(); err != nil {
+			startErr = err
+			return
+		}
+
 		blockEventNotifier, err := app.notifier.RegisterBlockEpochNtfn(nil)
 
 		if err != nil {
@@ -314,7 +608,21 @@ func (app *StakerApp) Start() error {
 
 		app.logger.Infof("Initial btc best block height is: %d", app.currentBestBlockHeight.Load())
 
+		app.probeMempoolPolicy()
+
+		if interval := app.config.MempoolPolicyConfig.ProbeInterval; interval > 0 {
+			app.wg.Add(1)
+			go app.mempoolPolicyLoop(interval)
+		}
+
 		app.babylonMsgSender.Start()
+		app.eventPublisher.Start()
+
+		// spent fees leave the rolling windows as time passes
+		app.refreshFeeBudgetMetrics()
+		app.OnNewBlock(func(_ uint32) {
+			app.refreshFeeBudgetMetrics()
+		})
 
 		app.wg.Add(2)
 		go app.handleNewBlocks(blockEventNotifier)
@@ -324,6 +632,49 @@ func (app *StakerApp) Start() error {
 			startErr = err
 			return
 		}
+
+		app.wg.Add(2)
+		go app.unbondingSigsPoller.run(&app.wg, app.quit)
+		go app.externalDelegationPoller.run(&app.wg, app.quit)
+
+		// operations which failed before restart are resumed by retry queue
+		app.wg.Add(1)
+		go app.retryQueueLoop()
+
+		app.wg.Add(1)
+		go app.scheduledOperationsLoop()
+
+		app.wg.Add(1)
+		go app.stateAgeMetricsLoop()
+
+		// importing addresses can trigger long wallet rescan
+		app.wg.Add(1)
+		go app.trackStoredChangeAddresses()
+
+		if app.config.ConsolidationConfig.Interval > 0 {
+			app.wg.Add(1)
+			go app.consolidateOutputsLoop(app.config.ConsolidationConfig.Interval)
+		}
+
+		if app.config.BalanceMonitorConfig.Interval > 0 {
+			app.wg.Add(1)
+			go app.babylonBalanceLoop(app.config.BalanceMonitorConfig.Interval)
+		}
+
+		if app.config.BtcLightClientConfig.Interval > 0 {
+			app.wg.Add(1)
+			go app.btcLightClientLoop(app.config.BtcLightClientConfig.Interval)
+		}
+
+		if app.failover != nil {
+			app.wg.Add(1)
+			go app.btcBackendFailoverLoop(app.config.FailoverConfig.CheckInterval)
+		}
+
+		if interval := app.config.TrackingSnapshotConfig.Interval; interval > 0 {
+			app.wg.Add(1)
+			go app.trackingSnapshotLoop(interval)
+		}
 	})
 
 	return startErr
@@ -341,6 +692,12 @@ func (app *StakerApp) handleNewBlocks(blockNotifier *notifier.BlockEpochEvent) {
 			app.m.CurrentBtcBlockHeight.Set(float64(block.Height))
 			app.currentBestBlockHeight.Store(uint32(block.Height))
 
+			app.newBlockListenersMu.Lock()
+			for _, listener := range app.newBlockListeners {
+				listener(uint32(block.Height))
+			}
+			app.newBlockListenersMu.Unlock()
+
 			app.logger.WithFields(logrus.Fields{
 				"btcBlockHeight": block.Height,
 				"btcBlockHash":   block.Hash.String(),
@@ -351,14 +708,33 @@ func (app *StakerApp) handleNewBlocks(blockNotifier *notifier.BlockEpochEvent) {
 	}
 }
 
+// OnNewBlock registers function called on every new best btc block. Function is
+// called from block handling routine, so it must not block
+func (app *StakerApp) OnNewBlock(listener func(height uint32)) {
+	app.newBlockListenersMu.Lock()
+	defer app.newBlockListenersMu.Unlock()
+	app.newBlockListeners = append(app.newBlockListeners, listener)
+}
+
 func (app *StakerApp) Stop() error {
 	var stopErr error
 	app.stopOnce.Do(func() {
 		app.logger.Infof("Stopping StakerApp")
+
+		// snapshot must be taken before quit, as waiting goroutines cancel their
+		// subscriptions on quit
+		if app.config.TrackingSnapshotConfig.Interval > 0 {
+			if err := app.writeTrackingSnapshot(); err != nil {
+				app.logger.WithError(err).Warn("Failed to write tracking snapshot")
+			}
+		}
+
 		close(app.quit)
 		app.wg.Wait()
 
+		app.confTracker.stop()
 		app.babylonMsgSender.Stop()
+		app.eventPublisher.Stop()
 
 		err := app.feeEstimator.Stop()
 
@@ -398,25 +774,18 @@ func (app *StakerApp) waitForStakingTransactionConfirmation(
 	stakingTxHash *chainhash.Hash,
 	stakingTxPkScript []byte,
 	requiredBlockDepth uint32,
-	currentBestBlockHeight uint32,
-) error {
+) {
 	app.logger.WithFields(logrus.Fields{
 		"stakingTxHash": stakingTxHash.String(),
 	}).Debug("Register waiting for tx confirmation")
 
-	confEvent, err := app.notifier.RegisterConfirmationsNtfn(
+	confSub := app.confTracker.track(
 		stakingTxHash,
 		stakingTxPkScript,
 		requiredBlockDepth+1,
-		currentBestBlockHeight,
-		notifier.WithIncludeBlock(),
 	)
-	if err != nil {
-		return err
-	}
 
-	go app.waitForStakingTxConfirmation(*stakingTxHash, requiredBlockDepth, confEvent)
-	return nil
+	go app.waitForStakingTxConfirmation(*stakingTxHash, requiredBlockDepth, confSub)
 }
 
 func (app *StakerApp) handleBtcTxInfo(
@@ -441,14 +810,11 @@ func (app *StakerApp) handleBtcTxInfo(
 			"btcTxHash": stakingTxHash,
 		}).Debug("Transaction found in mempool. Stat waiting for confirmation")
 
-		if err := app.waitForStakingTransactionConfirmation(
+		app.waitForStakingTransactionConfirmation(
 			stakingTxHash,
 			txInfo.StakingTx.TxOut[txInfo.StakingOutputIndex].PkScript,
 			params.ConfirmationTimeBlocks,
-			currentBestBlockHeight,
-		); err != nil {
-			return err
-		}
+		)
 
 	case walletcontroller.TxInChain:
 		app.logger.WithFields(logrus.Fields{
@@ -503,14 +869,11 @@ func (app *StakerApp) handleBtcTxInfo(
 				"currentBestBlockHeight": currentBestBlockHeight,
 			}).Debug("Transaction not deep enough in btc chain to be sent to Babylon. Waiting for confirmation")
 
-			if err := app.waitForStakingTransactionConfirmation(
+			app.waitForStakingTransactionConfirmation(
 				stakingTxHash,
 				txInfo.StakingTx.TxOut[txInfo.StakingOutputIndex].PkScript,
 				params.ConfirmationTimeBlocks,
-				currentBestBlockHeight,
-			); err != nil {
-				return err
-			}
+			)
 		}
 	}
 	return nil
@@ -546,8 +909,14 @@ func (app *StakerApp) checkTransactionsStatus() error {
 		// info about transaction sent (hash) to check wheter it was confirmed after staker
 		// restarts
 		stakingTxHash := tx.StakingTx.TxHash()
+
 		switch tx.State {
 		case proto.TransactionState_SENT_TO_BTC:
+			if !tx.Watched {
+				// transaction was sent by us, so it counts towards limit of unconfirmed
+				// staking transactions
+				app.unconfirmedTxs.track(stakingTxHash)
+			}
 			transactionsSentToBtc = append(transactionsSentToBtc, &stakingTxHash)
 			return nil
 		case proto.TransactionState_CONFIRMED_ON_BTC:
@@ -582,9 +951,16 @@ func (app *StakerApp) checkTransactionsStatus() error {
 		return err
 	}
 
+	snapshot := app.loadTrackingSnapshot()
+
 	for _, txHash := range transactionsSentToBtc {
 		stakingTxHash := txHash
 		tx, _ := app.mustGetTransactionAndStakerAddress(stakingTxHash)
+
+		if snapshot != nil && app.resumeFromSnapshot(stakingTxHash, tx, stakingParams, snapshot) {
+			continue
+		}
+
 		details, status, err := app.wc.TxDetails(stakingTxHash, tx.StakingTx.TxOut[tx.StakingOutputIndex].PkScript)
 
 		if err != nil {
@@ -630,6 +1006,18 @@ func (app *StakerApp) checkTransactionsStatus() error {
 			// get all necessary info and send it to babylon
 
 			tx, stakerAddress := app.mustGetTransactionAndStakerAddress(stakingTxHash)
+
+			if app.isScheduledForRetry(proto.RetryOperation_SEND_DELEGATION_TO_BABYLON, stakingTxHash) {
+				// sending already failed before restart, retry queue will resume
+				// it respecting backoff
+				continue
+			}
+
+			if tx.ExternalStakerBtcPk != nil {
+				app.externalDelegationPoller.add(stakingTxHash)
+				continue
+			}
+
 			details, status, err := app.wc.TxDetails(stakingTxHash, tx.StakingTx.TxOut[tx.StakingOutputIndex].PkScript)
 
 			if err != nil {
@@ -668,8 +1056,7 @@ func (app *StakerApp) checkTransactionsStatus() error {
 		if localInfo.stakingTxState == proto.TransactionState_SENT_TO_BABYLON {
 			stakingTxHash := localInfo.stakingTxHash
 			// we crashed after succesful send to babaylon, restart checking for unbonding signatures
-			app.wg.Add(1)
-			go app.checkForUnbondingTxSignaturesOnBabylon(stakingTxHash)
+			app.unbondingSigsPoller.add(stakingTxHash)
 		} else {
 			// we should not have any other state here, so kill app
 			return fmt.Errorf("unexpected local transaction state: %s, expected: %s", localInfo.stakingTxState, proto.TransactionState_SENT_TO_BABYLON)
@@ -682,7 +1069,7 @@ func (app *StakerApp) checkTransactionsStatus() error {
 func (app *StakerApp) waitForStakingTxConfirmation(
 	txHash chainhash.Hash,
 	depthOnBtcChain uint32,
-	ev *notifier.ConfirmationEvent) {
+	ev *confirmationSubscription) {
 	// check we are not shutting down
 	select {
 	case <-app.quit:
@@ -697,6 +1084,27 @@ func (app *StakerApp) waitForStakingTxConfirmation(
 		// transaction have beer reorged out of the chain
 		select {
 		case conf := <-ev.Confirmed:
+			if conf.Block == nil {
+				// inclusion resumed from tracking snapshot does not carry the block
+				block, err := app.wc.GetBlock(conf.BlockHash)
+
+				if err != nil {
+					app.logger.WithFields(logrus.Fields{
+						"btcTxHash": txHash,
+						"err":       err,
+					}).Error("Failed to retrieve block of confirmed transaction. Checking transaction again")
+
+					ev = app.confTracker.track(&txHash, ev.pkScript, ev.numConfs)
+					continue
+				}
+
+				withBlock := *conf
+				withBlock.Block = block
+				conf = &withBlock
+			}
+
+			app.recordFeeSpendConfirmation(conf.Tx.TxHash(), conf.BlockHeight)
+
 			stakingEvent := &stakingTxBtcConfirmedEvent{
 				stakingTxHash: conf.Tx.TxHash(),
 				txIndex:       conf.TxIndex,
@@ -831,6 +1239,7 @@ func (app *StakerApp) sendUnbondingTxToBtcWithWitness(
 		storedTx,
 		unbondingData,
 		params,
+		app.scriptBuilder,
 		app.network,
 	)
 
@@ -846,77 +1255,26 @@ func (app *StakerApp) sendUnbondingTxToBtcWithWitness(
 
 	unbondingTx.TxIn[0].Witness = witness
 
-	_, err = app.wc.SendRawTransaction(unbondingTx, true)
+	_, err = app.sendRawTransaction(unbondingTx, true)
 
 	if err != nil {
 		return err
 	}
 
-	return nil
-}
-
-// sendUnbondingTxToBtc sends unbonding tx to btc and registers for inclusion notification.
-// It retries until it successfully sends unbonding tx to btc and registers for notification.or until program finishes
-// TODO: Investigate wheter some of the errors should be treated as fatal and abort whole process
-func (app *StakerApp) sendUnbondingTxToBtc(
-	ctx context.Context,
-	stakingTxHash *chainhash.Hash,
-	stakerAddress btcutil.Address,
-	storedTx *stakerdb.StoredTransaction,
-	unbondingData *stakerdb.UnbondingStoreData) (*notifier.ConfirmationEvent, error) {
-
-	err := retry.Do(func() error {
-		return app.sendUnbondingTxToBtcWithWitness(
-			stakingTxHash,
-			stakerAddress,
-			storedTx,
-			unbondingData,
-		)
-	},
-		longRetryOps(
-			ctx,
-			unbondingSendRetryTimeout,
-			app.onLongRetryFunc(stakingTxHash, "failed to send unbonding tx to btc"),
-		)...,
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	bestBlockAfterSend := app.currentBestBlockHeight.Load()
-	unbondingTxHash := unbondingData.UnbondingTx.TxHash()
-
-	var notificationEv *notifier.ConfirmationEvent
-	err = retry.Do(func() error {
-		ev, err := app.notifier.RegisterConfirmationsNtfn(
-			&unbondingTxHash,
-			unbondingData.UnbondingTx.TxOut[0].PkScript,
-			UnbondingTxConfirmations,
-			bestBlockAfterSend,
-		)
+	app.labelOutputAddress(unbondingTx.TxOut[0].PkScript, unbondingTxLabel(stakingTxHash))
 
-		if err != nil {
-			return err
-		}
-		notificationEv = ev
-		return nil
-	},
-		longRetryOps(
-			ctx,
-			unbondingSendRetryTimeout,
-			app.onLongRetryFunc(stakingTxHash, "failed to register for unbonding tx confirmation notification"),
-		)...,
+	// unbonding fee is paid from the staking output
+	app.recordFeeSpend(
+		FeeSpendKindUnbonding,
+		unbondingTx,
+		btcutil.Amount(storedTx.StakingTx.TxOut[storedTx.StakingOutputIndex].Value-unbondingTx.TxOut[0].Value),
 	)
 
-	if err != nil {
-		return nil, err
-	}
-	return notificationEv, nil
+	return nil
 }
 
 func (app *StakerApp) waitForUnbondingTxConfirmation(
-	waitEv *notifier.ConfirmationEvent,
+	waitEv *confirmationSubscription,
 	unbondingData *stakerdb.UnbondingStoreData,
 	stakingTxHash *chainhash.Hash,
 ) {
@@ -933,6 +1291,8 @@ func (app *StakerApp) waitForUnbondingTxConfirmation(
 				"blockHeight":     conf.BlockHeight,
 			}).Debug("Unbonding tx confirmed")
 
+			app.recordFeeSpendConfirmation(unbondingTxHash, conf.BlockHeight)
+
 			req := &unbondingTxConfirmedOnBtcEvent{
 				stakingTxHash: *stakingTxHash,
 				blockHash:     *conf.BlockHash,
@@ -957,7 +1317,8 @@ func (app *StakerApp) waitForUnbondingTxConfirmation(
 	}
 }
 
-// sendUnbondingTxToBtcTask tries to send unbonding tx to btc and register for confirmation notification.
+// sendUnbondingTxToBtcTask makes one attempt to send unbonding tx to btc and then waits
+// for its confirmation. If sending fails, it is scheduled in retry queue.
 // it should be run in separate go routine.
 func (app *StakerApp) sendUnbondingTxToBtcTask(
 	stakingTxHash *chainhash.Hash,
@@ -965,11 +1326,8 @@ func (app *StakerApp) sendUnbondingTxToBtcTask(
 	storedTx *stakerdb.StoredTransaction,
 	unbondingData *stakerdb.UnbondingStoreData) {
 	defer app.wg.Done()
-	quitCtx, cancel := app.appQuitContext()
-	defer cancel()
 
-	waitEv, err := app.sendUnbondingTxToBtc(
-		quitCtx,
+	err := app.sendUnbondingTxToBtcWithWitness(
 		stakingTxHash,
 		stakerAddress,
 		storedTx,
@@ -977,10 +1335,20 @@ func (app *StakerApp) sendUnbondingTxToBtcTask(
 	)
 
 	if err != nil {
-		app.reportCriticialError(*stakingTxHash, err, "Failed failed to send unbonding tx to btc")
+		app.scheduleRetry(proto.RetryOperation_SEND_UNBONDING_TX_TO_BTC, stakingTxHash, err)
 		return
 	}
 
+	app.completeRetry(proto.RetryOperation_SEND_UNBONDING_TX_TO_BTC, stakingTxHash)
+
+	unbondingTxHash := unbondingData.UnbondingTx.TxHash()
+
+	waitEv := app.confTracker.track(
+		&unbondingTxHash,
+		unbondingData.UnbondingTx.TxOut[0].PkScript,
+		UnbondingTxConfirmations,
+	)
+
 	app.waitForUnbondingTxConfirmation(
 		waitEv,
 		unbondingData,
@@ -1011,11 +1379,16 @@ func (app *StakerApp) buildAndSendDelegation(
 	stakerAddress btcutil.Address,
 	storedTx *stakerdb.StoredTransaction,
 ) (*pv.RelayerTxResponse, *cl.DelegationData, error) {
-	delegation, err := app.buildDelegation(req, stakerAddress, storedTx)
+	if err := app.checkBtcLightClientReady(); err != nil {
+		return nil, nil, err
+	}
+
+	delegation, err := app.buildDelegation(req, stakerAddress, storedTx)
 	if err != nil {
 		return nil, nil, err
 	}
 	resp, err := app.babylonMsgSender.SendDelegation(delegation, req.requiredInclusionBlockDepth)
+	app.recordBabylonTxResponse(&req.txHash, cl.MsgCreateBTCDelegationTypeURL, resp, err)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1023,6 +1396,8 @@ func (app *StakerApp) buildAndSendDelegation(
 	return resp, delegation, nil
 }
 
+// sendDelegationToBabylonTask makes one attempt to send delegation to babylon.
+// If it fails with transient error, sending is scheduled in retry queue.
 func (app *StakerApp) sendDelegationToBabylonTask(
 	req *sendDelegationRequest,
 	stakerAddress btcutil.Address,
@@ -1030,51 +1405,82 @@ func (app *StakerApp) sendDelegationToBabylonTask(
 ) {
 	defer app.wg.Done()
 
-	// using app quit context to cancel retrying when app is shutting down
-	ctx, cancel := app.appQuitContext()
-	defer cancel()
+	app.m.PendingBabylonSubmissions.Inc()
+	defer app.m.PendingBabylonSubmissions.Dec()
 
-	var delegationData *cl.DelegationData
-	err := retry.Do(func() error {
-		_, del, err := app.buildAndSendDelegation(req, stakerAddress, storedTx)
+	_, delegationData, err := app.buildAndSendDelegation(req, stakerAddress, storedTx)
 
-		if err != nil {
-			if errors.Is(err, cl.ErrInvalidBabylonExecution) {
-				return retry.Unrecoverable(err)
-			}
-			return err
+	if err != nil {
+		if errors.Is(err, cl.ErrInvalidBabylonExecution) {
+			// retrying invalid delegation won't help
+			app.completeRetry(proto.RetryOperation_SEND_DELEGATION_TO_BABYLON, &req.txHash)
+			app.reportCriticialError(
+				req.txHash,
+				err,
+				"Failed to deliver delegation to babylon due to error.",
+			)
+			return
 		}
 
-		delegationData = del
-		return nil
-	},
-		longRetryOps(
-			ctx,
-			app.config.StakerConfig.BabylonStallingInterval,
-			app.onLongRetryFunc(&req.txHash, "Failed to deliver delegation to babylon due to error."),
-		)...,
+		app.scheduleRetry(proto.RetryOperation_SEND_DELEGATION_TO_BABYLON, &req.txHash, err)
+		return
+	}
+
+	app.completeRetry(proto.RetryOperation_SEND_DELEGATION_TO_BABYLON, &req.txHash)
+	app.reportDelegationSubmitted(req, delegationData)
+}
+
+// reportDelegationSubmitted reports success with the values we sent to Babylon
+func (app *StakerApp) reportDelegationSubmitted(
+	req *sendDelegationRequest,
+	delegationData *cl.DelegationData,
+) {
+	slashingTxHash := delegationData.SlashingTransaction.TxHash()
+	unbondingSlashingTxHash := delegationData.Ud.SlashUnbondingTransaction.TxHash()
+
+	ev := &delegationSubmittedToBabylonEvent{
+		stakingTxHash:           req.txHash,
+		unbondingTx:             delegationData.Ud.UnbondingTransaction,
+		unbondingTime:           delegationData.Ud.UnbondingTxUnbondingTime,
+		slashingTxHash:          &slashingTxHash,
+		unbondingSlashingTxHash: &unbondingSlashingTxHash,
+	}
+
+	utils.PushOrQuit[*delegationSubmittedToBabylonEvent](
+		app.delegationSubmittedToBabylonEvChan,
+		ev,
+		app.quit,
 	)
+}
+
+// sendDelegationRequestFromWallet rebuilds request to send delegation to babylon
+// from data about confirmed staking transaction stored in btc wallet
+func (app *StakerApp) sendDelegationRequestFromWallet(
+	stakingTxHash *chainhash.Hash,
+	storedTx *stakerdb.StoredTransaction,
+) (*sendDelegationRequest, error) {
+	params, err := app.babylonClient.Params()
 
 	if err != nil {
-		app.reportCriticialError(
-			req.txHash,
-			err,
-			"Failed to deliver delegation to babylon due to error.",
-		)
-	} else {
-		// report success with the values we sent to Babylon
-		ev := &delegationSubmittedToBabylonEvent{
-			stakingTxHash: req.txHash,
-			unbondingTx:   delegationData.Ud.UnbondingTransaction,
-			unbondingTime: delegationData.Ud.UnbondingTxUnbondingTime,
-		}
+		return nil, err
+	}
 
-		utils.PushOrQuit[*delegationSubmittedToBabylonEvent](
-			app.delegationSubmittedToBabylonEvChan,
-			ev,
-			app.quit,
-		)
+	details, status, err := app.wc.TxDetails(stakingTxHash, storedTx.StakingTx.TxOut[storedTx.StakingOutputIndex].PkScript)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if status != walletcontroller.TxInChain {
+		return nil, fmt.Errorf("confirmed staking transaction %s not found on btc chain", stakingTxHash)
 	}
+
+	return &sendDelegationRequest{
+		txHash:                      *stakingTxHash,
+		txIndex:                     details.TxIndex,
+		inclusionBlock:              details.Block,
+		requiredInclusionBlockDepth: uint64(params.ConfirmationTimeBlocks),
+	}, nil
 }
 
 // main event loop for the staker app
@@ -1086,8 +1492,6 @@ func (app *StakerApp) handleStakingEvents() {
 		case ev := <-app.stakingRequestedEvChan:
 			app.logStakingEventReceived(ev)
 
-			bestBlockHeight := app.currentBestBlockHeight.Load()
-
 			if ev.isWatched() {
 				err := app.txTracker.AddWatchedTransaction(
 					ev.stakingTx,
@@ -1096,6 +1500,7 @@ func (app *StakerApp) handleStakingEvents() {
 					ev.fpBtcPks,
 					babylonPopToDbPop(ev.pop),
 					ev.stakerAddress,
+					ev.metadata,
 					ev.watchTxData.slashingTx,
 					ev.watchTxData.slashingTxSig,
 					ev.watchTxData.stakerBabylonPubKey,
@@ -1112,39 +1517,61 @@ func (app *StakerApp) handleStakingEvents() {
 				}
 			} else {
 				// in case of owend transaction we need to send it, and then add to our tracking db.
-				_, err := app.wc.SendRawTransaction(ev.stakingTx, true)
+				_, err := app.sendRawTransaction(ev.stakingTx, true)
 				if err != nil {
 					ev.errChan <- err
 					continue
 				}
 
-				err = app.txTracker.AddTransaction(
-					ev.stakingTx,
-					ev.stakingOutputIdx,
-					ev.stakingTime,
-					ev.fpBtcPks,
-					babylonPopToDbPop(ev.pop),
-					ev.stakerAddress,
-				)
+				app.recordFeeSpend(FeeSpendKindStaking, ev.stakingTx, ev.stakingTxFee)
+				app.labelOutputAddress(ev.stakingTx.TxOut[ev.stakingOutputIdx].PkScript, stakingTxLabel(&ev.stakingTxHash))
+
+				if ev.isExternalKey() {
+					err = app.txTracker.AddExternalKeyTransaction(
+						ev.stakingTx,
+						ev.stakingOutputIdx,
+						ev.stakingTime,
+						ev.fpBtcPks,
+						ev.stakerAddress,
+						ev.externalStakerBtcPk,
+						ev.metadata,
+						ev.stakingTxFee,
+						ev.requestId,
+					)
+				} else {
+					err = app.txTracker.AddTransaction(
+						ev.stakingTx,
+						ev.stakingOutputIdx,
+						ev.stakingTime,
+						ev.fpBtcPks,
+						babylonPopToDbPop(ev.pop),
+						ev.stakerAddress,
+						ev.metadata,
+						ev.stakingTxFee,
+						ev.requestId,
+					)
+				}
 
 				if err != nil {
 					ev.errChan <- err
 					continue
 				}
+
+				app.unconfirmedTxs.commit(ev.unconfirmedSlot, ev.stakingTxHash)
+				app.trackRequestId(ev.stakingTxHash, ev.requestId)
 			}
 
-			if err := app.waitForStakingTransactionConfirmation(
+			app.exportExitTemplates(ev.stakingTxHash)
+
+			app.waitForStakingTransactionConfirmation(
 				&ev.stakingTxHash,
 				ev.stakingOutputPkScript,
 				ev.requiredDepthOnBtcChain,
-				uint32(bestBlockHeight),
-			); err != nil {
-				ev.errChan <- err
-				continue
-			}
+			)
 
 			app.m.ValidReceivedDelegationRequests.Inc()
 			ev.successChan <- &ev.stakingTxHash
+			app.publishLifecycleEvent(ev)
 			app.logStakingEventProcessed(ev)
 
 		case ev := <-app.stakingTxBtcConfirmedEvChan:
@@ -1160,6 +1587,8 @@ func (app *StakerApp) handleStakingEvents() {
 				app.logger.Fatalf("Error setting state for tx %s: %s", ev.stakingTxHash, err)
 			}
 
+			app.unconfirmedTxs.release(ev.stakingTxHash)
+
 			req := &sendDelegationRequest{
 				txHash:                      ev.stakingTxHash,
 				txIndex:                     ev.txIndex,
@@ -1170,11 +1599,23 @@ func (app *StakerApp) handleStakingEvents() {
 			storedTx, stakerAddress := app.mustGetTransactionAndStakerAddress(&ev.stakingTxHash)
 
 			app.m.DelegationsConfirmedOnBtc.Inc()
+
+			if storedTx.ExternalStakerBtcPk != nil {
+				// we do not control staker key, so we cannot build delegation. Owner of
+				// the key needs to submit it to babylon, we only track its progress.
+				app.externalDelegationPoller.add(&ev.stakingTxHash)
+				app.publishLifecycleEvent(ev)
+				app.logStakingEventProcessed(ev)
+				continue
+			}
+
 			// TODO: Introduce max number of sendToDelegationToBabylonTasks. It should be tied to
 			// accepting new staking delegations i.e we will hit it we should stop accepting new stakingrequests
 			// as either babylon node is not healthy or we are constructing invalid delegations
 			app.wg.Add(1)
 			go app.sendDelegationToBabylonTask(req, stakerAddress, storedTx)
+			app.maybeScheduleAutoWithdraw(&ev.stakingTxHash)
+			app.publishLifecycleEvent(ev)
 			app.logStakingEventProcessed(ev)
 
 		case ev := <-app.delegationSubmittedToBabylonEvChan:
@@ -1185,12 +1626,28 @@ func (app *StakerApp) handleStakingEvents() {
 				app.logger.Fatalf("Error setting state for tx %s: %s", ev.stakingTxHash, err)
 			}
 
+			if ev.slashingTxHash != nil {
+				if err := app.txTracker.SetTxSlashingTxHashes(
+					&ev.stakingTxHash,
+					ev.slashingTxHash,
+					ev.unbondingSlashingTxHash,
+				); err != nil {
+					// hashes are only used to describe the delegation, so failure
+					// does not stop processing of the delegation
+					app.logger.WithFields(logrus.Fields{
+						"stakingTxHash": ev.stakingTxHash,
+						"err":           err,
+					}).Error("Failed to record slashing transaction hashes")
+				}
+			}
+
 			app.m.DelegationsSentToBabylon.Inc()
 			// start checking for covenant signatures on unbodning transactions
 			// when we receive them we treat delegation as active
-			app.wg.Add(1)
-			go app.checkForUnbondingTxSignaturesOnBabylon(&ev.stakingTxHash)
+			app.unbondingSigsPoller.add(&ev.stakingTxHash)
 
+			app.exportExitTemplates(ev.stakingTxHash)
+			app.publishLifecycleEvent(ev)
 			app.logStakingEventProcessed(ev)
 
 		case ev := <-app.unbondingTxSignaturesConfirmedOnBabylonEvChan:
@@ -1205,6 +1662,8 @@ func (app *StakerApp) handleStakingEvents() {
 			}
 
 			app.m.DelegationsActivatedOnBabylon.Inc()
+			app.exportExitTemplates(ev.stakingTxHash)
+			app.publishLifecycleEvent(ev)
 			app.logStakingEventProcessed(ev)
 
 		case ev := <-app.unbondingTxConfirmedOnBtcEvChan:
@@ -1218,15 +1677,18 @@ func (app *StakerApp) handleStakingEvents() {
 				// which is seems like programming error. Maybe panic?
 				app.logger.Fatalf("Error setting state for tx %s: %s", ev.stakingTxHash, err)
 			}
+			app.maybeScheduleAutoWithdraw(&ev.stakingTxHash)
+			app.publishLifecycleEvent(ev)
 			app.logStakingEventProcessed(ev)
 
 		case ev := <-app.spendStakeTxConfirmedOnBtcEvChan:
 			app.logStakingEventReceived(ev)
-			if err := app.txTracker.SetTxSpentOnBtc(&ev.stakingTxHash); err != nil {
+			if err := app.txTracker.SetTxSpentOnBtc(&ev.stakingTxHash, &ev.spendTxHash, ev.spendTxFee); err != nil {
 				// TODO: handle this error somehow, it means we received spend stake confirmation for tx which we do not store
 				// which is seems like programming error. Maybe panic?
 				app.logger.Fatalf("Error setting state for tx %s: %s", ev.stakingTxHash, err)
 			}
+			app.publishLifecycleEvent(ev)
 			app.logStakingEventProcessed(ev)
 
 		case ev := <-app.criticalErrorEvChan:
@@ -1244,6 +1706,7 @@ func (app *StakerApp) handleStakingEvents() {
 			if app.config.StakerConfig.ExitOnCriticalError {
 				app.logger.WithFields(logrus.Fields{
 					"stakingTxHash": ev.stakingTxHash,
+					"requestId":     app.requestIdOf(ev),
 					"err":           ev.err,
 					"info":          ev.additionalContext,
 				}).Fatalf("Critical error received. Exiting...")
@@ -1255,17 +1718,40 @@ func (app *StakerApp) handleStakingEvents() {
 			// procsess from latest state
 			app.logger.WithFields(logrus.Fields{
 				"stakingTxHash": ev.stakingTxHash,
+				"requestId":     app.requestIdOf(ev),
 				"err":           ev.err,
 				"info":          ev.additionalContext,
 			}).Error("Critical error received")
+			app.publishLifecycleEvent(ev)
 			app.logStakingEventProcessed(ev)
 
+		case reply := <-app.eventLoopPingChan:
+			close(reply)
+
 		case <-app.quit:
 			return
 		}
 	}
 }
 
+// PingEventLoop returns nil once staking event loop handles the ping. Event loop
+// which does not handle the ping before ctx is done is likely deadlocked.
+func (app *StakerApp) PingEventLoop(ctx context.Context) error {
+	reply := make(chan struct{})
+
+	select {
+	case app.eventLoopPingChan <- reply:
+	case <-ctx.Done():
+		return fmt.Errorf("staking event loop did not respond: %w", ctx.Err())
+	case <-app.quit:
+		return ErrStakerStopped
+	}
+
+	<-reply
+
+	return nil
+}
+
 func (app *StakerApp) Wallet() walletcontroller.WalletController {
 	return app.wc
 }
@@ -1274,6 +1760,17 @@ func (app *StakerApp) BabylonController() cl.BabylonClient {
 	return app.babylonClient
 }
 
+// Metrics returns metrics of the app, shared with the rpc service
+func (app *StakerApp) Metrics() *metrics.StakerMetrics {
+	return app.m
+}
+
+// FaultInjector returns injector of faults into clients of the app, nil if
+// clients were provided by the caller
+func (app *StakerApp) FaultInjector() *faultinjection.Injector {
+	return app.faultInjector
+}
+
 // Generate proof of possessions for staker address.
 // Requires btc wallet to be unlocked!
 func (app *StakerApp) generatePop(stakerPrivKey *btcec.PrivateKey) (*cl.BabylonPop, error) {
@@ -1336,6 +1833,7 @@ func (app *StakerApp) WatchStaking(
 	slashUnbondingTx *wire.MsgTx,
 	slashUnbondingTxSig *schnorr.Signature,
 	unbondingTime uint16,
+	metadata map[string]string,
 ) (*chainhash.Hash, error) {
 	currentParams, err := app.babylonClient.Params()
 
@@ -1344,11 +1842,11 @@ func (app *StakerApp) WatchStaking(
 	}
 
 	if len(fpPks) == 0 {
-		return nil, fmt.Errorf("no finality provider public keys provided")
+		return nil, fmt.Errorf("no finality provider public keys provided: %w", ErrInvalidStakingRequest)
 	}
 
 	if haveDuplicates(fpPks) {
-		return nil, fmt.Errorf("duplicate finality provider public keys provided")
+		return nil, fmt.Errorf("duplicate finality provider public keys provided: %w", ErrInvalidStakingRequest)
 	}
 
 	watchedRequest, err := parseWatchStakingRequest(
@@ -1366,7 +1864,9 @@ func (app *StakerApp) WatchStaking(
 		slashUnbondingTx,
 		slashUnbondingTxSig,
 		unbondingTime,
+		metadata,
 		currentParams,
+		app.scriptBuilder,
 		app.network,
 	)
 
@@ -1404,31 +1904,23 @@ func (app *StakerApp) WatchStaking(
 	case hash := <-watchedRequest.successChan:
 		return hash, nil
 	case <-app.quit:
-		return nil, nil
+		return nil, ErrStakerStopped
 	}
 }
 
-func (app *StakerApp) StakeFunds(
-	stakerAddress btcutil.Address,
+// validateStakingRequest checks that new staking request is valid against current
+// babylon params and returns those params
+func (app *StakerApp) validateStakingRequest(
 	stakingAmount btcutil.Amount,
 	fpPks []*btcec.PublicKey,
 	stakingTimeBlocks uint16,
-) (*chainhash.Hash, error) {
-
-	// check we are not shutting down
-	select {
-	case <-app.quit:
-		return nil, nil
-
-	default:
-	}
-
+) (*cl.StakingParams, error) {
 	if len(fpPks) == 0 {
-		return nil, fmt.Errorf("no finality providers public keys provided")
+		return nil, fmt.Errorf("no finality providers public keys provided: %w", ErrInvalidStakingRequest)
 	}
 
 	if haveDuplicates(fpPks) {
-		return nil, fmt.Errorf("duplicate finality provider public keys provided")
+		return nil, fmt.Errorf("duplicate finality provider public keys provided: %w", ErrInvalidStakingRequest)
 	}
 
 	for _, fpPk := range fpPks {
@@ -1446,16 +1938,83 @@ func (app *StakerApp) StakeFunds(
 	slashingFee := app.getSlashingFee(params.MinSlashingTxFeeSat)
 
 	if stakingAmount <= slashingFee {
-		return nil, fmt.Errorf("staking amount %d is less than minimum slashing fee %d",
-			stakingAmount, slashingFee)
+		return nil, fmt.Errorf("staking amount %d is less than minimum slashing fee %d: %w",
+			stakingAmount, slashingFee, ErrInvalidStakingRequest)
 	}
 
 	minStakingTime := GetMinStakingTime(params)
 	if uint32(stakingTimeBlocks) < minStakingTime {
-		return nil, fmt.Errorf("staking time %d is less than minimum staking time %d",
-			stakingTimeBlocks, minStakingTime)
+		return nil, fmt.Errorf("staking time %d is less than minimum staking time %d: %w",
+			stakingTimeBlocks, minStakingTime, ErrInvalidStakingRequest)
+	}
+
+	return params, nil
+}
+
+func (app *StakerApp) StakeFunds(
+	stakerAddress btcutil.Address,
+	stakingAmount btcutil.Amount,
+	fpPks []*btcec.PublicKey,
+	stakingTimeBlocks uint16,
+	minConfirmations uint32,
+	metadata map[string]string,
+	requestId string,
+) (*chainhash.Hash, error) {
+	return app.stakeFunds(stakerAddress, stakingAmount, fpPks, stakingTimeBlocks, 0, minConfirmations, metadata, requestId)
+}
+
+// stakeFunds sends staking transaction from the wallet. If maxFeeRate (in
+// sat/vbyte) is not 0, staking is rejected when estimated fee rate is higher.
+// Transaction is funded only from outputs with at least minConfirmations
+// confirmations.
+func (app *StakerApp) stakeFunds(
+	stakerAddress btcutil.Address,
+	stakingAmount btcutil.Amount,
+	fpPks []*btcec.PublicKey,
+	stakingTimeBlocks uint16,
+	maxFeeRate uint64,
+	minConfirmations uint32,
+	metadata map[string]string,
+	requestId string,
+) (*chainhash.Hash, error) {
+
+	// check we are not shutting down
+	select {
+	case <-app.quit:
+		return nil, ErrStakerStopped
+
+	default:
+	}
+
+	params, err := app.validateStakingRequest(stakingAmount, fpPks, stakingTimeBlocks)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.checkPolicy(policyStakeRequest(
+		PolicyActionStake,
+		stakerAddress,
+		nil,
+		stakingAmount,
+		fpPks,
+		stakingTimeBlocks,
+		metadata,
+		requestId,
+	))
+
+	if err != nil {
+		return nil, err
+	}
+
+	slot, releaseSlots, err := app.acquireStakingSlots()
+
+	if err != nil {
+		return nil, err
 	}
 
+	defer releaseSlots()
+
 	// unlock wallet for the rest of the operations
 	// TODO consider unlock/lock with defer
 	err = app.wc.UnlockWallet(defaultWalletUnlockTimeout)
@@ -1479,7 +2038,7 @@ func (app *StakerApp) StakeFunds(
 		return nil, err
 	}
 
-	stakingInfo, err := staking.BuildStakingInfo(
+	stakingInfo, err := app.scriptBuilder.BuildStakingScripts(
 		stakerPrivKey.PubKey(),
 		fpPks,
 		params.CovenantPks,
@@ -1495,7 +2054,33 @@ func (app *StakerApp) StakeFunds(
 
 	feeRate := app.feeEstimator.EstimateFeePerKb()
 
-	tx, err := app.wc.CreateAndSignTx([]*wire.TxOut{stakingInfo.StakingOutput}, btcutil.Amount(feeRate), stakerAddress)
+	// estimator returns fee per kvbyte, limit is in sat/vbyte
+	if maxFeeRate > 0 && uint64(feeRate/1000) > maxFeeRate {
+		return nil, fmt.Errorf("estimated fee rate %d sat/vbyte is higher than max fee rate %d sat/vbyte: %w",
+			uint64(feeRate/1000), maxFeeRate, ErrInvalidStakingRequest)
+	}
+
+	changeAddress, err := app.changeAddress(app.config.ChangePolicyConfig.Stake, stakerAddress)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := app.wc.CreateAndSignTx([]*wire.TxOut{stakingInfo.Output()}, btcutil.Amount(feeRate), changeAddress, minConfirmations)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// staking output is not necessarily first output in the transaction
+	// e.g when transaction outputs are sorted in deterministic mode
+	stakingOutputIdx, err := bbn.GetOutputIdxInBTCTx(tx, stakingInfo.Output())
+
+	if err != nil {
+		return nil, err
+	}
+
+	stakingTxFee, err := app.walletTxFee(tx)
 
 	if err != nil {
 		return nil, err
@@ -1503,22 +2088,28 @@ func (app *StakerApp) StakeFunds(
 
 	app.logger.WithFields(logrus.Fields{
 		"stakerAddress": stakerAddress,
-		"stakingAmount": stakingInfo.StakingOutput,
+		"stakingAmount": stakingInfo.Output(),
 		"btxTxHash":     tx.TxHash(),
 		"fee":           feeRate,
+		"txFee":         stakingTxFee,
+		"requestId":     requestId,
 	}).Info("Created and signed staking transaction")
 
 	req := newOwnedStakingRequest(
 		stakerAddress,
 		tx,
-		0,
-		stakingInfo.StakingOutput.PkScript,
+		stakingOutputIdx,
+		stakingInfo.Output().PkScript,
 		stakingTimeBlocks,
 		stakingAmount,
 		fpPks,
 		params.ConfirmationTimeBlocks,
 		pop,
+		metadata,
+		stakingTxFee,
 	)
+	req.unconfirmedSlot = slot
+	req.requestId = requestId
 
 	utils.PushOrQuit[*stakingRequestedEvent](
 		app.stakingRequestedEvChan,
@@ -1530,6 +2121,7 @@ func (app *StakerApp) StakeFunds(
 	case reqErr := <-req.errChan:
 		app.logger.WithFields(logrus.Fields{
 			"stakerAddress": stakerAddress,
+			"requestId":     requestId,
 			"err":           reqErr,
 		}).Debugf("Sending staking tx failed")
 
@@ -1537,16 +2129,52 @@ func (app *StakerApp) StakeFunds(
 	case hash := <-req.successChan:
 		return hash, nil
 	case <-app.quit:
-		return nil, nil
+		return nil, ErrStakerStopped
+	}
+}
+
+// TimeFilter restricts stored transactions to those created, or which changed
+// state, in given time ranges. Zero ranges do not restrict transactions.
+type TimeFilter struct {
+	Created         stakerdb.TimeRange
+	StateTransition stakerdb.TimeRange
+	// if set, only transitions to this state are considered
+	TransitionState *proto.TransactionState
+}
+
+func (f *TimeFilter) apply(query stakerdb.StoredTransactionQuery) stakerdb.StoredTransactionQuery {
+	if !f.Created.IsZero() {
+		query = query.WithCreatedRange(f.Created)
+	}
+
+	if !f.StateTransition.IsZero() || f.TransitionState != nil {
+		query = query.WithStateTransitionRange(f.TransitionState, f.StateTransition)
 	}
+
+	return query
 }
 
-func (app *StakerApp) StoredTransactions(limit, offset uint64) (*stakerdb.StoredTransactionQueryResult, error) {
+func (app *StakerApp) StoredTransactions(
+	limit, offset uint64,
+	metadataFilter map[string]string,
+	group *string,
+	timeFilter TimeFilter,
+) (*stakerdb.StoredTransactionQueryResult, error) {
 	query := stakerdb.StoredTransactionQuery{
 		IndexOffset:        offset,
 		NumMaxTransactions: limit,
 		Reversed:           false,
 	}
+
+	query = timeFilter.apply(query)
+
+	if len(metadataFilter) > 0 {
+		query = query.WithMetadataFilter(metadataFilter)
+	}
+
+	if group != nil {
+		query = query.WithGroupFilter(*group)
+	}
 	resp, err := app.txTracker.QueryStoredTransactions(query)
 	if err != nil {
 		return nil, err
@@ -1555,6 +2183,78 @@ func (app *StakerApp) StoredTransactions(limit, offset uint64) (*stakerdb.Stored
 	return &resp, nil
 }
 
+// StreamStoredTransactions iterates over stored transactions with index greater
+// than fromIdx in batches of batchSize. Each batch is read in separate db
+// transaction and next batch is read only after fn returns, so slow consumer
+// does not keep db transaction open nor forces loading all transactions to memory.
+func (app *StakerApp) StreamStoredTransactions(
+	ctx context.Context,
+	fromIdx uint64,
+	metadataFilter map[string]string,
+	group *string,
+	batchSize uint64,
+	fn func(batch []stakerdb.StoredTransaction) error,
+) error {
+	offset := fromIdx
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		resp, err := app.StoredTransactions(batchSize, offset, metadataFilter, group)
+
+		if err != nil {
+			return err
+		}
+
+		if len(resp.Transactions) == 0 {
+			return nil
+		}
+
+		if err := fn(resp.Transactions); err != nil {
+			return err
+		}
+
+		offset = resp.Transactions[len(resp.Transactions)-1].StoredTransactionIdx
+	}
+}
+
+// StoredTransactionsCreatedBetween returns all stored transactions added to database
+// in time range [from, to]. Zero time means range is not bounded from given side.
+// Transactions with unknown creation time are only returned if range is unbounded.
+func (app *StakerApp) StoredTransactionsCreatedBetween(from, to time.Time) ([]stakerdb.StoredTransaction, error) {
+	if from.IsZero() && to.IsZero() {
+		return app.txTracker.GetAllStoredTransactions()
+	}
+
+	created := stakerdb.TimeRange{After: from}
+
+	// end of report range is inclusive, timestamps have second precision
+	if !to.IsZero() {
+		created.Before = to.Add(time.Second)
+	}
+
+	query := stakerdb.StoredTransactionQuery{
+		NumMaxTransactions: math.MaxUint64,
+	}
+
+	query = query.WithCreatedRange(created)
+
+	resp, err := app.txTracker.QueryStoredTransactions(query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Transactions, nil
+}
+
+// BestBlockHeight returns height of the best btc block known to the staker
+func (app *StakerApp) BestBlockHeight() uint32 {
+	return app.currentBestBlockHeight.Load()
+}
+
 func (app *StakerApp) WithdrawableTransactions(limit, offset uint64) (*stakerdb.StoredTransactionQueryResult, error) {
 	query := stakerdb.StoredTransactionQuery{
 		IndexOffset:        offset,
@@ -1573,11 +2273,138 @@ func (app *StakerApp) GetStoredTransaction(txHash *chainhash.Hash) (*stakerdb.St
 	return app.txTracker.GetTransaction(txHash)
 }
 
+// stakerPubKeyForTx returns btc public key of the staker which created given
+// staking transaction
+func (app *StakerApp) stakerPubKeyForTx(tx *stakerdb.StoredTransaction) (*btcec.PublicKey, error) {
+	if tx.ExternalStakerBtcPk != nil {
+		return tx.ExternalStakerBtcPk, nil
+	}
+
+	if tx.Watched {
+		stakingTxHash := tx.StakingTx.TxHash()
+		watchedData, err := app.txTracker.GetWatchedTransactionData(&stakingTxHash)
+		if err != nil {
+			return nil, err
+		}
+
+		return watchedData.StakerBtcPubKey, nil
+	}
+
+	stakerAddress, err := btcutil.DecodeAddress(tx.StakerAddress, app.network)
+
+	if err != nil {
+		return nil, fmt.Errorf("error decoding staker address: %w", err)
+	}
+
+	privKey, err := app.stakerPrivateKey(stakerAddress)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return privKey.PubKey(), nil
+}
+
+// StakingScriptsInfo returns spend paths of the staking output of staking
+// transaction with given hash
+func (app *StakerApp) StakingScriptsInfo(stakingTxHash *chainhash.Hash) (*StakingScriptsInfo, error) {
+	tx, err := app.txTracker.GetTransaction(stakingTxHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stakerPubKey, err := app.stakerPubKeyForTx(tx)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot retrieve staker public key: %w", err)
+	}
+
+	params, err := app.babylonClient.Params()
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting params: %w", err)
+	}
+
+	return buildStakingScriptsInfo(
+		stakerPubKey,
+		params.CovenantPks,
+		params.CovenantQuruomThreshold,
+		tx,
+		app.scriptBuilder,
+		app.network,
+	)
+}
+
+// applyAntiFeeSniping sets locktime of not yet signed transaction to current best
+// block height, if enabled in config. It is skipped when deterministic transactions
+// are enabled, as those must always have locktime 0.
+func (app *StakerApp) applyAntiFeeSniping(tx *wire.MsgTx) {
+	if !app.config.StakerConfig.AntiFeeSniping || app.config.WalletConfig.DeterministicTxs {
+		return
+	}
+
+	tx.LockTime = antiFeeSnipingLockTime(app.currentBestBlockHeight.Load())
+
+	// locktime is only enforced if at least one input has non final sequence
+	for _, in := range tx.TxIn {
+		if in.Sequence == wire.MaxTxInSequenceNum {
+			in.Sequence = wire.MaxTxInSequenceNum - 1
+		}
+	}
+}
+
+// walletTxFee calculates fee of not yet sent transaction funded from wallet outputs
+func (app *StakerApp) walletTxFee(tx *wire.MsgTx) (btcutil.Amount, error) {
+	utxos, err := app.wc.ListOutputs(false)
+
+	if err != nil {
+		return 0, err
+	}
+
+	utxoValues := make(map[wire.OutPoint]btcutil.Amount)
+	for _, utxo := range utxos {
+		utxoValues[utxo.OutPoint] = utxo.Amount
+	}
+
+	var inputsValue btcutil.Amount
+	for _, in := range tx.TxIn {
+		value, found := utxoValues[in.PreviousOutPoint]
+
+		if !found {
+			return 0, fmt.Errorf("input %s of transaction %s is not wallet output", in.PreviousOutPoint, tx.TxHash())
+		}
+
+		inputsValue += value
+	}
+
+	var outputsValue btcutil.Amount
+	for _, out := range tx.TxOut {
+		outputsValue += btcutil.Amount(out.Value)
+	}
+
+	return inputsValue - outputsValue, nil
+}
+
+// CurrentFeeRate returns fee rate which would be used for new staking transaction
+func (app *StakerApp) CurrentFeeRate() btcutil.Amount {
+	return btcutil.Amount(app.feeEstimator.EstimateFeePerKb())
+}
+
+// MinFeeRate returns minimum fee rate of transactions accepted by connected node
+func (app *StakerApp) MinFeeRate() btcutil.Amount {
+	return app.mempoolPolicy.feeFloor()
+}
+
 func (app *StakerApp) ListUnspentOutputs() ([]walletcontroller.Utxo, error) {
 	return app.wc.ListOutputs(false)
 }
 
-func (app *StakerApp) waitForSpendConfirmation(stakingTxHash chainhash.Hash, ev *notifier.ConfirmationEvent) {
+func (app *StakerApp) waitForSpendConfirmation(
+	stakingTxHash chainhash.Hash,
+	spendTxFee btcutil.Amount,
+	ev *confirmationSubscription,
+) {
 	// check we are not shutting down
 	select {
 	case <-app.quit:
@@ -1591,9 +2418,13 @@ func (app *StakerApp) waitForSpendConfirmation(stakingTxHash chainhash.Hash, ev
 	defer cancel()
 	for {
 		select {
-		case <-ev.Confirmed:
+		case conf := <-ev.Confirmed:
+			app.recordFeeSpendConfirmation(conf.Tx.TxHash(), conf.BlockHeight)
+
 			stakingEvent := &spendStakeTxConfirmedOnBtcEvent{
-				stakingTxHash,
+				stakingTxHash: stakingTxHash,
+				spendTxHash:   conf.Tx.TxHash(),
+				spendTxFee:    spendTxFee,
 			}
 
 			// transaction which spends staking transaction is confirmed on BTC inform
@@ -1608,6 +2439,7 @@ func (app *StakerApp) waitForSpendConfirmation(stakingTxHash chainhash.Hash, ev
 			return
 		case <-ctx.Done():
 			// we timed out waiting for confirmation, transaction is stuck in mempool
+			ev.Cancel()
 			return
 
 		case <-app.quit:
@@ -1626,10 +2458,20 @@ func (app *StakerApp) waitForSpendConfirmation(stakingTxHash chainhash.Hash, ev
 // We find in which type of output stake is locked by checking state of staking transaction, and build
 // proper spend transaction based on that state.
 func (app *StakerApp) SpendStake(stakingTxHash *chainhash.Hash) (*chainhash.Hash, *btcutil.Amount, error) {
+	return app.spendStake(stakingTxHash, nil)
+}
+
+// spendStake spends stake to withdrawal address of the delegation, or to
+// defaultDestination if delegation has none. Nil defaultDestination means staker
+// address.
+func (app *StakerApp) spendStake(
+	stakingTxHash *chainhash.Hash,
+	defaultDestination btcutil.Address,
+) (*chainhash.Hash, *btcutil.Amount, error) {
 	// check we are not shutting down
 	select {
 	case <-app.quit:
-		return nil, nil, nil
+		return nil, nil, ErrStakerStopped
 
 	default:
 	}
@@ -1642,23 +2484,45 @@ func (app *StakerApp) SpendStake(stakingTxHash *chainhash.Hash) (*chainhash.Hash
 
 	// we cannont spend tx which is watch only.
 	// TODO. To make it possible additional endpoint is needed
-	if tx.Watched {
-		return nil, nil, fmt.Errorf("cannot spend staking which which is in watch only mode")
+	if tx.WatchOnly() {
+		return nil, nil, fmt.Errorf("cannot spend staking which which is in watch only mode: %w", ErrInvalidTransactionState)
+	}
+
+	err = app.checkPolicy(policyStoredTxRequest(PolicyActionWithdraw, stakingTxHash.String(), tx, nil))
+
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// this coud happen if we stared staker on wrong network.
 	// TODO: consider storing data for different networks in different folders
 	// to avoid this
-	// Currently we spend funds from staking transaction to the same address. This
-	// could be improved by allowing user to specify destination address, although
-	// this destination address would need to control the expcted priv key to sign
-	// transaction
-	destAddress, err := btcutil.DecodeAddress(tx.StakerAddress, app.network)
+	stakerAddress, err := btcutil.DecodeAddress(tx.StakerAddress, app.network)
 
 	if err != nil {
 		return nil, nil, fmt.Errorf("cannot spend staking output. Error decoding staker address: %w", err)
 	}
 
+	// funds are withdrawn to staker address, unless operator requested other
+	// destination when unbonding
+	destAddress := stakerAddress
+
+	if defaultDestination != nil {
+		destAddress = defaultDestination
+	}
+
+	if tx.WithdrawalAddress != "" {
+		destAddress, err = btcutil.DecodeAddress(tx.WithdrawalAddress, app.network)
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot spend staking output. Error decoding withdrawal address: %w", err)
+		}
+	}
+
+	if err := app.checkWithdrawalDestination(destAddress); err != nil {
+		return nil, nil, fmt.Errorf("cannot spend staking output: %w", err)
+	}
+
 	destAddressScript, err := txscript.PayToAddrScript(destAddress)
 
 	if err != nil {
@@ -1671,7 +2535,7 @@ func (app *StakerApp) SpendStake(stakingTxHash *chainhash.Hash) (*chainhash.Hash
 		return nil, nil, fmt.Errorf("cannot spend staking output. Error getting params: %w", err)
 	}
 
-	privKey, err := app.stakerPrivateKey(destAddress)
+	privKey, err := app.stakerPrivateKey(stakerAddress)
 
 	if err != nil {
 		return nil, nil, fmt.Errorf("cannot spend staking output. Error getting private key: %w", err)
@@ -1686,6 +2550,7 @@ func (app *StakerApp) SpendStake(stakingTxHash *chainhash.Hash) (*chainhash.Hash
 		tx,
 		destAddressScript,
 		currentFeeRate,
+		app.scriptBuilder,
 		app.network,
 	)
 
@@ -1693,6 +2558,8 @@ func (app *StakerApp) SpendStake(stakingTxHash *chainhash.Hash) (*chainhash.Hash
 		return nil, nil, err
 	}
 
+	app.applyAntiFeeSniping(spendStakeTxInfo.spendStakeTx)
+
 	stakerSig, err := staking.SignTxWithOneScriptSpendInputFromTapLeaf(
 		spendStakeTxInfo.spendStakeTx,
 		spendStakeTxInfo.fundingOutput,
@@ -1717,12 +2584,14 @@ func (app *StakerApp) SpendStake(stakingTxHash *chainhash.Hash) (*chainhash.Hash
 	// We do not check if transaction is spendable i.e the staking time has passed
 	// as this is validated in mempool so in of not meeting this time requirement
 	// we will receive error here: `transaction's sequence locks on inputs not met`
-	spendTxHash, err := app.wc.SendRawTransaction(spendStakeTxInfo.spendStakeTx, true)
+	spendTxHash, err := app.sendRawTransaction(spendStakeTxInfo.spendStakeTx, true)
 
 	if err != nil {
 		return nil, nil, fmt.Errorf("cannot spend staking output. Error sending tx: %w", err)
 	}
 
+	app.recordFeeSpend(FeeSpendKindSpendStake, spendStakeTxInfo.spendStakeTx, spendStakeTxInfo.calculatedFee)
+
 	spendTxValue := btcutil.Amount(spendStakeTxInfo.spendStakeTx.TxOut[0].Value)
 
 	app.logger.WithFields(logrus.Fields{
@@ -1730,27 +2599,22 @@ func (app *StakerApp) SpendStake(stakingTxHash *chainhash.Hash) (*chainhash.Hash
 		"spendTxHash":   spendTxHash,
 		"spendTxValue":  spendTxValue,
 		"fee":           spendStakeTxInfo.calculatedFee,
-		"stakerAddress": destAddress,
+		"stakerAddress": stakerAddress,
 		"destAddress":   destAddress,
 	}).Infof("Successfully sent transaction spending staking output")
 
-	confEvent, err := app.notifier.RegisterConfirmationsNtfn(
+	confEvent := app.confTracker.track(
 		spendTxHash,
 		spendStakeTxInfo.spendStakeTx.TxOut[0].PkScript,
 		SpendStakeTxConfirmations,
-		app.currentBestBlockHeight.Load(),
 	)
 
-	if err != nil {
-		return nil, nil, fmt.Errorf("spend tx sent. Error registering confirmation notifcation: %w", err)
-	}
-
 	// We are gonna mark our staking transaction as spent on BTC network, only when
 	// we receive enough confirmations on btc network. This means that btc staker can send another
 	// tx which will spend this staking output concurrently. In that case the first one
 	// confirmed on btc networks which will mark our staking transaction as spent on BTC network.
 	// TODO: we can reconsider this approach in the future.
-	go app.waitForSpendConfirmation(*stakingTxHash, confEvent)
+	go app.waitForSpendConfirmation(*stakingTxHash, spendStakeTxInfo.calculatedFee, confEvent)
 
 	return spendTxHash, &spendTxValue, nil
 }
@@ -1769,12 +2633,17 @@ func (app *StakerApp) ListActiveFinalityProviders(limit uint64, offset uint64) (
 // 5. After gathering all signatures, unbonding transaction is sent to bitcoin
 // This function returns control to the caller after step 3. Later is up to the caller
 // to check what is state of unbonding transaction
+// If destinationAddress is not nil, funds are later withdrawn from the
+// unbonding output to this address instead of staker address.
 func (app *StakerApp) UnbondStaking(
-	stakingTxHash chainhash.Hash, feeRate *btcutil.Amount) (*chainhash.Hash, error) {
+	stakingTxHash chainhash.Hash,
+	feeRate *btcutil.Amount,
+	destinationAddress btcutil.Address,
+) (*chainhash.Hash, error) {
 	// check we are not shutting down
 	select {
 	case <-app.quit:
-		return nil, nil
+		return nil, ErrStakerStopped
 
 	default:
 	}
@@ -1787,12 +2656,18 @@ func (app *StakerApp) UnbondStaking(
 	}
 
 	// 2. Check tx is not watched and is in valid state
-	if tx.Watched {
-		return nil, fmt.Errorf("cannot unbond watched transaction")
+	if tx.WatchOnly() {
+		return nil, fmt.Errorf("cannot unbond watched transaction: %w", ErrInvalidTransactionState)
 	}
 
 	if tx.State != proto.TransactionState_DELEGATION_ACTIVE {
-		return nil, fmt.Errorf("cannot unbond transaction which is not active")
+		return nil, fmt.Errorf("cannot unbond transaction which is not active: %w", ErrInvalidTransactionState)
+	}
+
+	err = app.checkPolicy(policyStoredTxRequest(PolicyActionUnbond, stakingTxHash.String(), tx, feeRate))
+
+	if err != nil {
+		return nil, err
 	}
 
 	stakerAddress, err := btcutil.DecodeAddress(tx.StakerAddress, app.network)
@@ -1801,6 +2676,23 @@ func (app *StakerApp) UnbondStaking(
 		return nil, fmt.Errorf("error decoding staker address: %s. Err: %v", tx.StakerAddress, err)
 	}
 
+	// funds are going to be withdrawn to staker address, unless other
+	// destination is provided, so check it before unbonding
+	withdrawalAddress := stakerAddress
+	if destinationAddress != nil {
+		withdrawalAddress = destinationAddress
+	}
+
+	if err := app.checkWithdrawalDestination(withdrawalAddress); err != nil {
+		return nil, fmt.Errorf("cannot unbond: %w", err)
+	}
+
+	if destinationAddress != nil {
+		if err := app.setWithdrawalAddress(&stakingTxHash, destinationAddress); err != nil {
+			return nil, err
+		}
+	}
+
 	// TODO: Move this to event handler to avoid somebody starting multiple unbonding routines
 	app.wg.Add(1)
 	go app.sendUnbondingTxToBtcTask(
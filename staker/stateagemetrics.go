@@ -0,0 +1,134 @@
+package staker
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/babylonchain/btc-staker/types"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+// how often ages of delegations in non terminal states are recalculated
+const stateAgeMetricsInterval = 30 * time.Second
+
+// nonTerminalStates are states from which delegation is expected to move on,
+// delegation stuck in any of them for too long means that part of the pipeline
+// is broken
+var nonTerminalStates = []proto.TransactionState{
+	proto.TransactionState_SENT_TO_BTC,
+	proto.TransactionState_CONFIRMED_ON_BTC,
+	proto.TransactionState_SENT_TO_BABYLON,
+	proto.TransactionState_DELEGATION_ACTIVE,
+	proto.TransactionState_UNBONDING_CONFIRMED_ON_BTC,
+}
+
+func isNonTerminalState(state proto.TransactionState) bool {
+	for _, s := range nonTerminalStates {
+		if s == state {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fpMetricLabel returns alias of finality provider if it has one, hex encoded
+// public key otherwise
+func (app *StakerApp) fpMetricLabel(pkHex string) string {
+	if alias, found := app.fpAliases[pkHex]; found {
+		return alias
+	}
+
+	return pkHex
+}
+
+// refreshStateAgeMetrics sets age of the oldest delegation in every non terminal
+// state, number of delegations in every state and delegations of every finality
+// provider. Delegations which do not have state transitions recorded are
+// skipped when calculating ages.
+func (app *StakerApp) refreshStateAgeMetrics() {
+	now := time.Now()
+	oldest := make(map[proto.TransactionState]time.Time, len(nonTerminalStates))
+	counts := make(map[types.DelegationState]int)
+	fpCounts := make(map[string]int)
+	fpStakes := make(map[string]int64)
+
+	err := app.txTracker.ScanTrackedTransactions(func(tx *stakerdb.StoredTransaction) error {
+		counts[types.CanonicalDelegationState(tx.State)]++
+
+		if isNonTerminalState(tx.State) {
+			stake := tx.StakingTx.TxOut[tx.StakingOutputIndex].Value
+
+			for _, pk := range tx.FinalityProvidersBtcPks {
+				fp := app.fpMetricLabel(hex.EncodeToString(schnorr.SerializePubKey(pk)))
+				fpCounts[fp]++
+				fpStakes[fp] += stake
+			}
+		}
+
+		enteredAt, found := tx.StateTimestamp(tx.State)
+
+		if !found {
+			return nil
+		}
+
+		if current, found := oldest[tx.State]; !found || enteredAt.Before(current) {
+			oldest[tx.State] = enteredAt
+		}
+
+		return nil
+	}, func() {
+		oldest = make(map[proto.TransactionState]time.Time, len(nonTerminalStates))
+		counts = make(map[types.DelegationState]int)
+		fpCounts = make(map[string]int)
+		fpStakes = make(map[string]int64)
+	})
+
+	if err != nil {
+		app.logger.WithError(err).Error("Failed to calculate ages of delegations")
+		return
+	}
+
+	for _, state := range nonTerminalStates {
+		var age float64
+
+		if enteredAt, found := oldest[state]; found {
+			age = now.Sub(enteredAt).Seconds()
+		}
+
+		app.m.OldestDelegationAge.WithLabelValues(string(types.CanonicalDelegationState(state))).Set(age)
+	}
+
+	for _, state := range types.DelegationStates() {
+		app.m.Delegations.WithLabelValues(string(state.State)).Set(float64(counts[state.State]))
+	}
+
+	// finality providers without delegations must disappear from dashboards
+	app.m.FinalityProviderDelegations.Reset()
+	app.m.FinalityProviderStake.Reset()
+
+	for fp, count := range fpCounts {
+		app.m.FinalityProviderDelegations.WithLabelValues(fp).Set(float64(count))
+		app.m.FinalityProviderStake.WithLabelValues(fp).Set(float64(fpStakes[fp]))
+	}
+}
+
+func (app *StakerApp) stateAgeMetricsLoop() {
+	defer app.wg.Done()
+
+	app.refreshStateAgeMetrics()
+
+	ticker := time.NewTicker(stateAgeMetricsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			app.refreshStateAgeMetrics()
+		case <-app.quit:
+			return
+		}
+	}
+}
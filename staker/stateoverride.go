@@ -0,0 +1,105 @@
+package staker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/sirupsen/logrus"
+)
+
+const overrideDelegationStateAction = "override_delegation_state"
+
+type stateOverride struct {
+	from proto.TransactionState
+	to   proto.TransactionState
+}
+
+// allowedStateOverrides are the only state changes which can be forced by the
+// operator. Staking output spent outside of the daemon (e.g by manually
+// broadcast transaction) can be marked as spent from any non terminal state, and
+// delegation lost by babylon can be moved back to be sent again.
+var allowedStateOverrides = map[stateOverride]struct{}{
+	{proto.TransactionState_SENT_TO_BTC, proto.TransactionState_SPENT_ON_BTC}:                {},
+	{proto.TransactionState_CONFIRMED_ON_BTC, proto.TransactionState_SPENT_ON_BTC}:           {},
+	{proto.TransactionState_SENT_TO_BABYLON, proto.TransactionState_SPENT_ON_BTC}:            {},
+	{proto.TransactionState_DELEGATION_ACTIVE, proto.TransactionState_SPENT_ON_BTC}:          {},
+	{proto.TransactionState_UNBONDING_CONFIRMED_ON_BTC, proto.TransactionState_SPENT_ON_BTC}: {},
+	{proto.TransactionState_SENT_TO_BABYLON, proto.TransactionState_CONFIRMED_ON_BTC}:        {},
+}
+
+// StateOverrideRequest describes manual change of delegation state requested by
+// the operator
+type StateOverrideRequest struct {
+	StakingTxHash chainhash.Hash
+	// state in which delegation must be, protects against overriding state which
+	// changed in the meantime
+	From   proto.TransactionState
+	To     proto.TransactionState
+	Reason string
+	// origin of the request, recorded in audit log
+	RequestId  string
+	RemoteAddr string
+}
+
+// OverrideDelegationState forces state of the delegation and records it in audit
+// log. It is an escape hatch for delegations whose state got out of sync with
+// btc or babylon due to actions performed outside of the daemon. Tasks already
+// running for the delegation are not cancelled, so daemon should be restarted
+// after override.
+func (app *StakerApp) OverrideDelegationState(req *StateOverrideRequest) error {
+	if _, allowed := allowedStateOverrides[stateOverride{req.From, req.To}]; !allowed {
+		return fmt.Errorf("override from %s to %s is not allowed: %w", req.From, req.To, ErrInvalidTransactionState)
+	}
+
+	if req.Reason == "" {
+		return fmt.Errorf("reason of state override must be provided: %w", ErrInvalidStakingRequest)
+	}
+
+	if err := app.txTracker.OverrideTxState(&req.StakingTxHash, req.From, req.To); err != nil {
+		return err
+	}
+
+	logger := app.logger.WithFields(logrus.Fields{
+		"stakingTxHash": req.StakingTxHash,
+		"from":          req.From,
+		"to":            req.To,
+		"reason":        req.Reason,
+		"requestId":     req.RequestId,
+		"remoteAddr":    req.RemoteAddr,
+	})
+
+	logger.Warn("Delegation state overridden by operator")
+
+	if storedTx, err := app.txTracker.GetTransaction(&req.StakingTxHash); err == nil {
+		app.publishOperatorLifecycleEvent(LifecycleEventStateOverridden, req.StakingTxHash, storedTx)
+	}
+
+	err := app.auditLog.AddEntry(&stakerdb.AuditLogEntry{
+		Timestamp:     time.Now(),
+		Action:        overrideDelegationStateAction,
+		StakingTxHash: req.StakingTxHash,
+		PreviousState: req.From,
+		NewState:      req.To,
+		Reason:        req.Reason,
+		RequestId:     req.RequestId,
+		RemoteAddr:    req.RemoteAddr,
+	})
+
+	if err != nil {
+		// state is already changed, so only thing left is to make sure operator
+		// notices missing audit entry
+		app.reportCriticialError(req.StakingTxHash, err, "Failed to record state override in audit log")
+		return fmt.Errorf("state overridden, but failed to record it in audit log: %w", err)
+	}
+
+	return nil
+}
+
+// AuditLog returns recorded operator actions, optionally only for given
+// staking transaction
+func (app *StakerApp) AuditLog(stakingTxHash *chainhash.Hash) ([]stakerdb.AuditLogEntry, error) {
+	return app.auditLog.Entries(stakingTxHash)
+}
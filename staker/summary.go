@@ -0,0 +1,109 @@
+package staker
+
+import (
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+// StakingSummary aggregates amounts, fees and counts over all tracked staking
+// transactions
+type StakingSummary struct {
+	// Amount currently locked in staking or unbonding outputs
+	TotalStaked btcutil.Amount
+	// Staking transactions which are not active on Babylon yet
+	PendingStaked btcutil.Amount
+	ActiveStaked  btcutil.Amount
+	// Funds locked in confirmed unbonding transactions
+	UnbondingStaked btcutil.Amount
+	// Funds which were already spent back to the staker
+	WithdrawnAmount btcutil.Amount
+
+	StakingTxFees   btcutil.Amount
+	UnbondingTxFees btcutil.Amount
+	SpendTxFees     btcutil.Amount
+
+	TotalTransactions    uint64
+	TransactionsPerState map[proto.TransactionState]uint64
+	// Staking fees of watched transactions are not known to staker
+	WatchedTransactions uint64
+	// Transactions with missing or zero staking or spend fee, e.g stored by older
+	// versions of staker
+	TransactionsWithoutFeeData uint64
+}
+
+func newStakingSummary() *StakingSummary {
+	return &StakingSummary{
+		TransactionsPerState: make(map[proto.TransactionState]uint64),
+	}
+}
+
+func (s *StakingSummary) add(tx *stakerdb.StoredTransaction) {
+	stakingValue := btcutil.Amount(tx.StakingTx.TxOut[tx.StakingOutputIndex].Value)
+
+	s.TotalTransactions++
+	s.TransactionsPerState[tx.State]++
+
+	if tx.Watched {
+		s.WatchedTransactions++
+	}
+
+	if tx.MissingFeeData() {
+		s.TransactionsWithoutFeeData++
+	}
+
+	unbondingConfirmed := tx.UnbondingTxData != nil && tx.UnbondingTxData.UnbondingTxConfirmationInfo != nil
+
+	switch tx.State {
+	case proto.TransactionState_SENT_TO_BTC,
+		proto.TransactionState_CONFIRMED_ON_BTC,
+		proto.TransactionState_SENT_TO_BABYLON:
+		s.PendingStaked += stakingValue
+		s.TotalStaked += stakingValue
+	case proto.TransactionState_DELEGATION_ACTIVE:
+		s.ActiveStaked += stakingValue
+		s.TotalStaked += stakingValue
+	case proto.TransactionState_UNBONDING_CONFIRMED_ON_BTC:
+		unbondingValue := stakingValue - tx.UnbondingTxFee()
+		s.UnbondingStaked += unbondingValue
+		s.TotalStaked += unbondingValue
+	case proto.TransactionState_SPENT_ON_BTC:
+		withdrawn := stakingValue - tx.SpendTxFee
+		if unbondingConfirmed {
+			withdrawn -= tx.UnbondingTxFee()
+		}
+		s.WithdrawnAmount += withdrawn
+	}
+
+	s.StakingTxFees += tx.StakingTxFee
+	s.SpendTxFees += tx.SpendTxFee
+
+	// unbonding transaction fee is only paid if unbonding transaction was
+	// confirmed on btc
+	if unbondingConfirmed {
+		s.UnbondingTxFees += tx.UnbondingTxFee()
+	}
+}
+
+// StakingSummary returns aggregated view over all tracked staking transactions,
+// or only over transactions assigned to given group if group is not nil
+func (app *StakerApp) StakingSummary(group *string) (*StakingSummary, error) {
+	summary := newStakingSummary()
+
+	err := app.txTracker.ScanTrackedTransactions(func(tx *stakerdb.StoredTransaction) error {
+		if group != nil && *group != tx.Group {
+			return nil
+		}
+
+		summary.add(tx)
+		return nil
+	}, func() {
+		summary = newStakingSummary()
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
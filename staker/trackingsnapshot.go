@@ -0,0 +1,248 @@
+package staker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	cl "github.com/babylonchain/btc-staker/babylonclient"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	notifier "github.com/lightningnetwork/lnd/chainntnfs"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	trackingSnapshotVersion  = 1
+	trackingSnapshotFileName = "tracking_snapshot.json"
+)
+
+// trackingSnapshot is persisted state of confirmation tracker. It is valid as
+// long as its best block stays in the main chain, as inclusions in blocks up to
+// best height can't change without reorg of that block.
+type trackingSnapshot struct {
+	Version    int       `json:"version"`
+	TakenAt    time.Time `json:"taken_at"`
+	BestHeight uint32    `json:"best_height"`
+	BestHash   string    `json:"best_hash"`
+	// tracked transactions keyed by hash. Transactions without block are known
+	// not to be included in blocks up to best height.
+	Transactions map[string]snapshotTx `json:"transactions"`
+}
+
+type snapshotTx struct {
+	BlockHeight uint32 `json:"block_height,omitempty"`
+	BlockHash   string `json:"block_hash,omitempty"`
+	TxIndex     uint32 `json:"tx_index,omitempty"`
+}
+
+// inclusion returns inclusion of the transaction without block, nil if
+// transaction is not included up to best height of the snapshot
+func (s *snapshotTx) inclusion() (*notifier.TxConfirmation, error) {
+	if s.BlockHash == "" {
+		return nil, nil
+	}
+
+	hash, err := chainhash.NewHashFromStr(s.BlockHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &notifier.TxConfirmation{
+		BlockHash:   hash,
+		BlockHeight: s.BlockHeight,
+		TxIndex:     s.TxIndex,
+	}, nil
+}
+
+// snapshot returns state of verified subscriptions, nil if best block is not
+// known because of reorg in progress
+func (t *confirmationTracker) snapshot() *trackingSnapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.bestHash == nil {
+		return nil
+	}
+
+	s := &trackingSnapshot{
+		Version:      trackingSnapshotVersion,
+		TakenAt:      time.Now(),
+		BestHeight:   t.bestHeight,
+		BestHash:     t.bestHash.String(),
+		Transactions: make(map[string]snapshotTx, len(t.subs)),
+	}
+
+	for txHash, subs := range t.subs {
+		for _, sub := range subs {
+			if !sub.verified {
+				continue
+			}
+
+			var tx snapshotTx
+
+			if sub.inclusion != nil && sub.inclusion.BlockHeight <= t.bestHeight {
+				tx.BlockHeight = sub.inclusion.BlockHeight
+				tx.BlockHash = sub.inclusion.BlockHash.String()
+				tx.TxIndex = sub.inclusion.TxIndex
+			}
+
+			s.Transactions[txHash.String()] = tx
+			break
+		}
+	}
+
+	return s
+}
+
+func (app *StakerApp) trackingSnapshotPath() string {
+	return filepath.Join(app.config.DBConfig.DBPath, trackingSnapshotFileName)
+}
+
+func (app *StakerApp) writeTrackingSnapshot() error {
+	s := app.confTracker.snapshot()
+
+	if s == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(s)
+
+	if err != nil {
+		return err
+	}
+
+	path := app.trackingSnapshotPath()
+	tmpPath := path + ".tmp"
+
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+
+	// rename is atomic, so that crash during write never leaves partial snapshot
+	return os.Rename(tmpPath, path)
+}
+
+func (app *StakerApp) trackingSnapshotLoop(interval time.Duration) {
+	defer app.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := app.writeTrackingSnapshot(); err != nil {
+				app.logger.WithError(err).Warn("Failed to write tracking snapshot")
+			}
+		case <-app.quit:
+			return
+		}
+	}
+}
+
+// loadTrackingSnapshot returns snapshot which can be used to resume tracking,
+// nil if there is no usable snapshot. Snapshot is unusable if it is too old, or
+// if its best block is no longer part of the main chain.
+func (app *StakerApp) loadTrackingSnapshot() *trackingSnapshot {
+	cfg := app.config.TrackingSnapshotConfig
+
+	if cfg.Interval == 0 {
+		return nil
+	}
+
+	data, err := os.ReadFile(app.trackingSnapshotPath())
+
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	discard := func(reason string) *trackingSnapshot {
+		app.logger.WithField("reason", reason).Info("Tracking snapshot not used, deriving tracking state from btc node")
+		return nil
+	}
+
+	if err != nil {
+		return discard(err.Error())
+	}
+
+	var s trackingSnapshot
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return discard(fmt.Sprintf("invalid snapshot: %s", err))
+	}
+
+	if s.Version != trackingSnapshotVersion {
+		return discard(fmt.Sprintf("unsupported snapshot version %d", s.Version))
+	}
+
+	if age := time.Since(s.TakenAt); age > cfg.MaxAge {
+		return discard(fmt.Sprintf("snapshot is %s old", age.Round(time.Second)))
+	}
+
+	hash, err := app.wc.GetBlockHash(int64(s.BestHeight))
+
+	if err != nil {
+		return discard(fmt.Sprintf("failed to get block at snapshot height %d: %s", s.BestHeight, err))
+	}
+
+	if hash.String() != s.BestHash {
+		return discard(fmt.Sprintf("snapshot block %s at height %d was reorged", s.BestHash, s.BestHeight))
+	}
+
+	app.logger.WithFields(logrus.Fields{
+		"takenAt":      s.TakenAt,
+		"bestHeight":   s.BestHeight,
+		"transactions": len(s.Transactions),
+	}).Info("Resuming confirmation tracking from snapshot")
+
+	return &s
+}
+
+// resumeFromSnapshot resumes waiting for confirmation of staking transaction
+// sent to btc based on its state in the snapshot. Returns false if transaction
+// must be checked against btc node, i.e it is not in the snapshot or is already
+// deep enough and its block is needed to build inclusion proof.
+func (app *StakerApp) resumeFromSnapshot(
+	stakingTxHash *chainhash.Hash,
+	tx *stakerdb.StoredTransaction,
+	params *cl.StakingParams,
+	s *trackingSnapshot,
+) bool {
+	entry, found := s.Transactions[stakingTxHash.String()]
+
+	if !found {
+		return false
+	}
+
+	inclusion, err := entry.inclusion()
+
+	if err != nil {
+		return false
+	}
+
+	if inclusion != nil {
+		bestHeight := app.currentBestBlockHeight.Load()
+
+		if inclusion.BlockHeight > bestHeight || bestHeight-inclusion.BlockHeight >= params.ConfirmationTimeBlocks {
+			return false
+		}
+
+		inclusion.Tx = tx.StakingTx
+	}
+
+	sub := app.confTracker.trackFromSnapshot(
+		stakingTxHash,
+		tx.StakingTx.TxOut[tx.StakingOutputIndex].PkScript,
+		params.ConfirmationTimeBlocks+1,
+		inclusion,
+		s.BestHeight,
+	)
+
+	go app.waitForStakingTxConfirmation(*stakingTxHash, params.ConfirmationTimeBlocks, sub)
+
+	return true
+}
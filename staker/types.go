@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/hex"
 	"fmt"
+	"math/rand"
 	"sort"
 
 	sdkmath "cosmossdk.io/math"
@@ -13,6 +14,7 @@ import (
 	bbn "github.com/babylonchain/babylon/types"
 	cl "github.com/babylonchain/btc-staker/babylonclient"
 	"github.com/babylonchain/btc-staker/proto"
+	"github.com/babylonchain/btc-staker/scriptbuilder"
 	"github.com/babylonchain/btc-staker/stakerdb"
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcec/v2/schnorr"
@@ -102,12 +104,15 @@ func createWitnessSignaturesForPubKeys(
 
 func buildSlashingTxAndSig(
 	slashingFee btcutil.Amount,
+	unbondingTime uint16,
 	delegationData *externalDelegationData,
 	storedTx *stakerdb.StoredTransaction,
+	builder scriptbuilder.StakingScriptBuilder,
 	net *chaincfg.Params,
 ) (*wire.MsgTx, *schnorr.Signature, error) {
 	stakerPubKey := delegationData.stakerPrivKey.PubKey()
-	lockSlashTxLockTime := delegationData.babylonParams.MinUnbondingTime + 1
+	// change output of slashing tx is locked for unbonding time of the delegation
+	lockSlashTxLockTime := unbondingTime
 
 	slashingTx, err := staking.BuildSlashingTxFromStakingTxStrict(
 		storedTx.StakingTx,
@@ -124,7 +129,7 @@ func buildSlashingTxAndSig(
 		return nil, nil, fmt.Errorf("buidling slashing transaction failed: %w", err)
 	}
 
-	stakingInfo, err := staking.BuildStakingInfo(
+	stakingInfo, err := builder.BuildStakingScripts(
 		delegationData.stakerPrivKey.PubKey(),
 		storedTx.FinalityProvidersBtcPks,
 		delegationData.babylonParams.CovenantPks,
@@ -230,6 +235,7 @@ func createSpendStakeTxFromStoredTx(
 	storedtx *stakerdb.StoredTransaction,
 	destinationScript []byte,
 	feeRate chainfee.SatPerKVByte,
+	builder scriptbuilder.StakingScriptBuilder,
 	net *chaincfg.Params,
 ) (*spendStakeTxInfo, error) {
 	// Note: we enable withdrawal only even if staking transaction is confirmed on btc.
@@ -237,7 +243,7 @@ func createSpendStakeTxFromStoredTx(
 	// - staker is unable to sent delegation to babylon
 	// - staking transaction on babylon fail to get covenant signatures
 	if storedtx.StakingTxConfirmedOnBtc() {
-		stakingInfo, err := staking.BuildStakingInfo(
+		stakingInfo, err := builder.BuildStakingScripts(
 			stakerBtcPk,
 			storedtx.FinalityProvidersBtcPks,
 			covenantPublicKeys,
@@ -281,7 +287,7 @@ func createSpendStakeTxFromStoredTx(
 	} else if storedtx.IsUnbonded() {
 		data := storedtx.UnbondingTxData
 
-		unbondingInfo, err := staking.BuildUnbondingInfo(
+		unbondingInfo, err := builder.BuildUnbondingScripts(
 			stakerBtcPk,
 			storedtx.FinalityProvidersBtcPks,
 			covenantPublicKeys,
@@ -323,7 +329,7 @@ func createSpendStakeTxFromStoredTx(
 			calculatedFee:          *calculatedFee,
 		}, nil
 	} else {
-		return nil, fmt.Errorf("cannot build spend stake transactions.Staking transaction is in invalid state: %s", storedtx.State)
+		return nil, fmt.Errorf("cannot build spend stake transactions.Staking transaction is in invalid state: %s: %w", storedtx.State, ErrInvalidTransactionState)
 	}
 }
 
@@ -337,6 +343,7 @@ func createUndelegationData(
 	unbondingTime uint16,
 	slashingFee btcutil.Amount,
 	slashingRate sdkmath.LegacyDec,
+	builder scriptbuilder.StakingScriptBuilder,
 	btcNetwork *chaincfg.Params,
 ) (*cl.UndelegationData, error) {
 	stakingTxHash := storedTx.StakingTx.TxHash()
@@ -361,7 +368,7 @@ func createUndelegationData(
 		)
 	}
 
-	unbondingInfo, err := staking.BuildUnbondingInfo(
+	unbondingInfo, err := builder.BuildUnbondingScripts(
 		stakerPubKey,
 		storedTx.FinalityProvidersBtcPks,
 		covenantPubKeys,
@@ -377,7 +384,7 @@ func createUndelegationData(
 
 	unbondingTx := wire.NewMsgTx(2)
 	unbondingTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&stakingTxHash, storedTx.StakingOutputIndex), nil, nil))
-	unbondingTx.AddTxOut(unbondingInfo.UnbondingOutput)
+	unbondingTx.AddTxOut(unbondingInfo.Output())
 
 	slashUnbondingTx, err := staking.BuildSlashingTxFromStakingTxStrict(
 		unbondingTx,
@@ -402,7 +409,7 @@ func createUndelegationData(
 
 	slashUnbondingTxSignature, err := staking.SignTxWithOneScriptSpendInputFromScript(
 		slashUnbondingTx,
-		unbondingInfo.UnbondingOutput,
+		unbondingInfo.Output(),
 		stakerPrivKey,
 		slashingPathInfo.RevealedLeaf.Script,
 	)
@@ -425,10 +432,11 @@ func createWitnessToSendUnbondingTx(
 	storedTx *stakerdb.StoredTransaction,
 	unbondingData *stakerdb.UnbondingStoreData,
 	params *cl.StakingParams,
+	builder scriptbuilder.StakingScriptBuilder,
 	net *chaincfg.Params,
 ) (wire.TxWitness, error) {
 	if storedTx.State < proto.TransactionState_DELEGATION_ACTIVE {
-		return nil, fmt.Errorf("cannot create witness for sending unbonding tx. Staking transaction is in invalid state: %s", storedTx.State)
+		return nil, fmt.Errorf("cannot create witness for sending unbonding tx. Staking transaction is in invalid state: %s: %w", storedTx.State, ErrInvalidTransactionState)
 	}
 
 	if unbondingData.UnbondingTx == nil {
@@ -439,7 +447,7 @@ func createWitnessToSendUnbondingTx(
 		return nil, fmt.Errorf("cannot create witness for sending unbonding tx. Unbonding data does not contain all necessary signatures. Required: %d, received: %d", params.CovenantQuruomThreshold, len(unbondingData.CovenantSignatures))
 	}
 
-	stakingInfo, err := staking.BuildStakingInfo(
+	stakingInfo, err := builder.BuildStakingScripts(
 		stakerPrivKey.PubKey(),
 		storedTx.FinalityProvidersBtcPks,
 		params.CovenantPks,
@@ -496,10 +504,12 @@ func parseWatchStakingRequest(
 	slashUnbondingTx *wire.MsgTx,
 	slashUnbondingTxSig *schnorr.Signature,
 	unbondingTime uint16,
+	metadata map[string]string,
 	currentParams *cl.StakingParams,
+	builder scriptbuilder.StakingScriptBuilder,
 	network *chaincfg.Params,
 ) (*stakingRequestedEvent, error) {
-	stakingInfo, err := staking.BuildStakingInfo(
+	stakingInfo, err := builder.BuildStakingScripts(
 		stakerBtcPk,
 		fpBtcPks,
 		currentParams.CovenantPks,
@@ -512,7 +522,7 @@ func parseWatchStakingRequest(
 		return nil, fmt.Errorf("failed to watch staking tx due to invalid staking info: %w", err)
 	}
 
-	stakingOutputIdx, err := bbn.GetOutputIdxInBTCTx(stakingTx, stakingInfo.StakingOutput)
+	stakingOutputIdx, err := bbn.GetOutputIdxInBTCTx(stakingTx, stakingInfo.Output())
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to watch staking tx due to tx not matching current data: %w", err)
@@ -573,7 +583,7 @@ func parseWatchStakingRequest(
 
 	unbondingValue := btcutil.Amount(unbondingTxValue)
 
-	unbondingInfo, err := staking.BuildUnbondingInfo(
+	unbondingInfo, err := builder.BuildUnbondingScripts(
 		stakerBtcPk,
 		fpBtcPks,
 		currentParams.CovenantPks,
@@ -587,7 +597,7 @@ func parseWatchStakingRequest(
 		return nil, fmt.Errorf("failed to watch staking tx. Failed to build unbonding scripts: %w", err)
 	}
 
-	if unbondingInfo.UnbondingOutput.Value != unbondingTxValue || !bytes.Equal(unbondingInfo.UnbondingOutput.PkScript, unbondingTxPkScript) {
+	if unbondingInfo.Output().Value != unbondingTxValue || !bytes.Equal(unbondingInfo.Output().PkScript, unbondingTxPkScript) {
 		return nil, fmt.Errorf("failed to watch staking tx. Unbonding output does not match output produced from provided values")
 	}
 
@@ -654,6 +664,7 @@ func parseWatchStakingRequest(
 		slashUnbondingTx,
 		slashUnbondingTxSig,
 		unbondingTime,
+		metadata,
 	)
 
 	return req, nil
@@ -674,3 +685,147 @@ func haveDuplicates(btcPKs []*btcec.PublicKey) bool {
 
 	return false
 }
+
+// StakingScriptsInfo contains all spend paths of the staking output of
+// the given staking transaction
+type StakingScriptsInfo struct {
+	StakingOutput *wire.TxOut
+	TimeLockPath  *staking.SpendInfo
+	UnbondingPath *staking.SpendInfo
+	SlashingPath  *staking.SpendInfo
+}
+
+func buildStakingScriptsInfo(
+	stakerBtcPk *btcec.PublicKey,
+	covenantPublicKeys []*btcec.PublicKey,
+	covenantThreshold uint32,
+	storedTx *stakerdb.StoredTransaction,
+	builder scriptbuilder.StakingScriptBuilder,
+	net *chaincfg.Params,
+) (*StakingScriptsInfo, error) {
+	stakingOutput := storedTx.StakingTx.TxOut[storedTx.StakingOutputIndex]
+
+	stakingInfo, err := builder.BuildStakingScripts(
+		stakerBtcPk,
+		storedTx.FinalityProvidersBtcPks,
+		covenantPublicKeys,
+		covenantThreshold,
+		storedTx.StakingTime,
+		btcutil.Amount(stakingOutput.Value),
+		net,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build staking info: %w", err)
+	}
+
+	// sanity check that scripts built from current data, match the staking output
+	// which was sent to btc. This can fail if covenant committee changed after
+	// staking transaction was created.
+	if !bytes.Equal(stakingInfo.Output().PkScript, stakingOutput.PkScript) {
+		return nil, fmt.Errorf("staking output built from current parameters does not match staking output of staking transaction")
+	}
+
+	timeLockPathInfo, err := stakingInfo.TimeLockPathSpendInfo()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build time lock path info: %w", err)
+	}
+
+	unbondingPathInfo, err := stakingInfo.UnbondingPathSpendInfo()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build unbonding path info: %w", err)
+	}
+
+	slashingPathInfo, err := stakingInfo.SlashingPathSpendInfo()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build slashing path info: %w", err)
+	}
+
+	return &StakingScriptsInfo{
+		StakingOutput: stakingOutput,
+		TimeLockPath:  timeLockPathInfo,
+		UnbondingPath: unbondingPathInfo,
+		SlashingPath:  slashingPathInfo,
+	}, nil
+}
+
+// UnbondingScriptsInfo contains spend paths of the unbonding output of the
+// given staking transaction
+type UnbondingScriptsInfo struct {
+	UnbondingOutput *wire.TxOut
+	TimeLockPath    *staking.SpendInfo
+	SlashingPath    *staking.SpendInfo
+}
+
+func buildUnbondingScriptsInfo(
+	stakerBtcPk *btcec.PublicKey,
+	covenantPublicKeys []*btcec.PublicKey,
+	covenantThreshold uint32,
+	storedTx *stakerdb.StoredTransaction,
+	builder scriptbuilder.StakingScriptBuilder,
+	net *chaincfg.Params,
+) (*UnbondingScriptsInfo, error) {
+	if storedTx.UnbondingTxData == nil || storedTx.UnbondingTxData.UnbondingTx == nil {
+		return nil, fmt.Errorf("staking transaction does not have unbonding transaction yet. Current state: %s: %w", storedTx.State, ErrInvalidTransactionState)
+	}
+
+	data := storedTx.UnbondingTxData
+	// unbonding tx has only one output
+	unbondingOutput := data.UnbondingTx.TxOut[0]
+
+	unbondingInfo, err := builder.BuildUnbondingScripts(
+		stakerBtcPk,
+		storedTx.FinalityProvidersBtcPks,
+		covenantPublicKeys,
+		covenantThreshold,
+		data.UnbondingTime,
+		btcutil.Amount(unbondingOutput.Value),
+		net,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build unbonding info: %w", err)
+	}
+
+	if !bytes.Equal(unbondingInfo.Output().PkScript, unbondingOutput.PkScript) {
+		return nil, fmt.Errorf("unbonding output built from current parameters does not match unbonding output of unbonding transaction")
+	}
+
+	timeLockPathInfo, err := unbondingInfo.TimeLockPathSpendInfo()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build unbonding time lock path info: %w", err)
+	}
+
+	slashingPathInfo, err := unbondingInfo.SlashingPathSpendInfo()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build unbonding slashing path info: %w", err)
+	}
+
+	return &UnbondingScriptsInfo{
+		UnbondingOutput: unbondingOutput,
+		TimeLockPath:    timeLockPathInfo,
+		SlashingPath:    slashingPathInfo,
+	}, nil
+}
+
+// antiFeeSnipingLockTime returns locktime which discourages fee sniping, following
+// bitcoin core wallet behaviour: locktime is set to current best block height and
+// occasionally moved further back to improve privacy of transactions which
+// were delayed in propagation.
+func antiFeeSnipingLockTime(bestBlockHeight uint32) uint32 {
+	lockTime := bestBlockHeight
+
+	if rand.Intn(10) == 0 {
+		back := uint32(rand.Intn(100))
+		if back < lockTime {
+			lockTime -= back
+		}
+	}
+
+	return lockTime
+}
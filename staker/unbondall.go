@@ -0,0 +1,135 @@
+package staker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/sirupsen/logrus"
+)
+
+// UnbondAllResult lists active delegations selected by unbond all run
+type UnbondAllResult struct {
+	// delegations which are unbonded by the run, in this order
+	ToUnbond []chainhash.Hash
+	// active delegations which can't be unbonded by the daemon, as staker key
+	// is not controlled by it
+	Skipped []chainhash.Hash
+}
+
+func (app *StakerApp) activeDelegations() (*UnbondAllResult, error) {
+	result := &UnbondAllResult{}
+
+	reset := func() {
+		result = &UnbondAllResult{}
+	}
+
+	err := app.txTracker.ScanTrackedTransactions(func(tx *stakerdb.StoredTransaction) error {
+		if tx.State != proto.TransactionState_DELEGATION_ACTIVE {
+			return nil
+		}
+
+		stakingTxHash := tx.StakingTx.TxHash()
+
+		if tx.WatchOnly() {
+			result.Skipped = append(result.Skipped, stakingTxHash)
+		} else {
+			result.ToUnbond = append(result.ToUnbond, stakingTxHash)
+		}
+
+		return nil
+	}, reset)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UnbondAll initiates unbonding of all active delegations, meant for incident
+// response when all positions must be exited quickly. Unbondings are started in
+// the background one by one, waiting interval between them so that btc and
+// babylon nodes are not overloaded. In dry run mode, delegations which would be
+// unbonded are only returned. Only one run can be in progress at a time.
+func (app *StakerApp) UnbondAll(interval time.Duration, dryRun bool) (*UnbondAllResult, error) {
+	if interval < 0 {
+		return nil, fmt.Errorf("interval between unbondings must not be negative: %w", ErrInvalidStakingRequest)
+	}
+
+	if !dryRun && !app.unbondAllRunning.CompareAndSwap(false, true) {
+		return nil, fmt.Errorf("unbond all is already in progress: %w", ErrInvalidStakingRequest)
+	}
+
+	result, err := app.activeDelegations()
+
+	if err != nil {
+		if !dryRun {
+			app.unbondAllRunning.Store(false)
+		}
+		return nil, err
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	app.logger.WithFields(logrus.Fields{
+		"toUnbond": len(result.ToUnbond),
+		"skipped":  len(result.Skipped),
+		"interval": interval,
+	}).Warn("Unbonding all active delegations")
+
+	app.wg.Add(1)
+	go app.unbondAllTask(result.ToUnbond, interval)
+
+	return result, nil
+}
+
+func (app *StakerApp) unbondAllTask(stakingTxHashes []chainhash.Hash, interval time.Duration) {
+	defer app.wg.Done()
+	defer app.unbondAllRunning.Store(false)
+
+	var numFailed int
+
+	for i, stakingTxHash := range stakingTxHashes {
+		if i > 0 && interval > 0 {
+			select {
+			case <-time.After(interval):
+			case <-app.quit:
+				return
+			}
+		}
+
+		select {
+		case <-app.quit:
+			return
+		default:
+		}
+
+		// delegation could have changed state since it was selected e.g it was
+		// unbonded manually, such delegations are rejected by UnbondStaking
+		unbondingTxHash, err := app.UnbondStaking(stakingTxHash, nil, nil)
+
+		if err != nil {
+			numFailed++
+			app.logger.WithFields(logrus.Fields{
+				"stakingTxHash": stakingTxHash,
+				"err":           err,
+			}).Error("Failed to start unbonding of delegation")
+			continue
+		}
+
+		app.logger.WithFields(logrus.Fields{
+			"stakingTxHash":   stakingTxHash,
+			"unbondingTxHash": unbondingTxHash,
+		}).Info("Started unbonding of delegation")
+	}
+
+	app.logger.WithFields(logrus.Fields{
+		"started": len(stakingTxHashes) - numFailed,
+		"failed":  numFailed,
+	}).Warn("Unbond all finished")
+}
@@ -0,0 +1,115 @@
+package staker
+
+import (
+	"fmt"
+
+	cl "github.com/babylonchain/btc-staker/babylonclient"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcwallet/wallet/txrules"
+	"github.com/sirupsen/logrus"
+)
+
+// unbondingParamsOfDelegation returns unbonding time and fee rate (sat/kvbyte)
+// of unbonding transaction of the delegation. Overrides set by the operator take
+// precedence over the defaults i.e minimal unbonding time allowed by babylon and
+// estimated fee rate.
+func (app *StakerApp) unbondingParamsOfDelegation(
+	storedTx *stakerdb.StoredTransaction,
+	params *cl.StakingParams,
+) (uint16, btcutil.Amount) {
+	unbondingTime := params.MinUnbondingTime + 1
+
+	if storedTx.UnbondingTimeOverride != 0 {
+		unbondingTime = storedTx.UnbondingTimeOverride
+	}
+
+	feeRate := btcutil.Amount(app.feeEstimator.EstimateFeePerKb())
+
+	if storedTx.UnbondingFeeRateOverride != 0 {
+		feeRate = storedTx.UnbondingFeeRateOverride
+	}
+
+	return unbondingTime, feeRate
+}
+
+// SetUnbondingOverrides sets unbonding time and fee rate (sat/kvbyte) of
+// unbonding transaction of the delegation, zero value restores the default.
+// Unbonding transaction is pre-signed when delegation is sent to babylon, so
+// overrides can be set only before that happens.
+func (app *StakerApp) SetUnbondingOverrides(
+	stakingTxHash *chainhash.Hash,
+	unbondingTime uint16,
+	feeRate btcutil.Amount,
+) error {
+	tx, err := app.txTracker.GetTransaction(stakingTxHash)
+
+	if err != nil {
+		return err
+	}
+
+	if tx.WatchOnly() {
+		return fmt.Errorf("cannot override unbonding of watched transaction: %w", ErrInvalidTransactionState)
+	}
+
+	params, err := app.babylonClient.Params()
+
+	if err != nil {
+		return err
+	}
+
+	if unbondingTime != 0 && unbondingTime <= params.MinUnbondingTime {
+		return fmt.Errorf("unbonding time %d must be greater than min unbonding time %d: %w",
+			unbondingTime, params.MinUnbondingTime, ErrInvalidStakingRequest)
+	}
+
+	if feeRate != 0 {
+		if floor := app.mempoolPolicy.feeFloor(); feeRate < floor {
+			return fmt.Errorf("unbonding fee rate %d sat/kvbyte is lower than min fee rate %d sat/kvbyte accepted by the node: %w",
+				feeRate, floor, ErrInvalidStakingRequest)
+		}
+
+		// unbonding output must be able to pay slashing fee, the same check is
+		// done when unbonding transaction is created
+		stakingValue := btcutil.Amount(tx.StakingTx.TxOut[tx.StakingOutputIndex].Value)
+		unbondingTxFee := txrules.FeeForSerializeSize(feeRate, slashingPathSpendTxVSize)
+		slashingFee := app.getSlashingFee(params.MinSlashingTxFeeSat)
+
+		if stakingValue-unbondingTxFee <= slashingFee {
+			return fmt.Errorf("unbonding fee rate %d sat/kvbyte is too large, unbonding output value %d would not cover slashing fee %d: %w",
+				feeRate, stakingValue-unbondingTxFee, slashingFee, ErrInvalidStakingRequest)
+		}
+	}
+
+	if err := app.txTracker.SetTxUnbondingOverrides(stakingTxHash, unbondingTime, feeRate); err != nil {
+		return err
+	}
+
+	app.logger.WithFields(logrus.Fields{
+		"stakingTxHash":    stakingTxHash,
+		"unbondingTime":    unbondingTime,
+		"unbondingFeeRate": feeRate,
+	}).Info("Unbonding overrides of delegation set")
+
+	return nil
+}
+
+// setWithdrawalAddress makes funds of the delegation withdrawn to given address
+// instead of staker address
+func (app *StakerApp) setWithdrawalAddress(stakingTxHash *chainhash.Hash, address btcutil.Address) error {
+	if !address.IsForNet(app.network) {
+		return fmt.Errorf("withdrawal address %s is not valid on network %s: %w", address, app.network.Name, ErrInvalidStakingRequest)
+	}
+
+	if err := app.txTracker.SetTxWithdrawalAddress(stakingTxHash, address.EncodeAddress()); err != nil {
+		return err
+	}
+
+	app.logger.WithFields(logrus.Fields{
+		"stakingTxHash":     stakingTxHash,
+		"withdrawalAddress": address.EncodeAddress(),
+	}).Info("Withdrawal address of delegation set")
+
+	return nil
+}
@@ -0,0 +1,325 @@
+package staker
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/babylonchain/btc-staker/walletcontroller"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	UtxoBlocklistSourceConfig = "config"
+	UtxoBlocklistSourceRpc    = "rpc"
+)
+
+// UtxoBlocklistEntry blocks either single outpoint or all outputs paying to
+// given address
+type UtxoBlocklistEntry struct {
+	Outpoint *wire.OutPoint
+	Address  btcutil.Address
+	// zero for entries from config
+	Timestamp time.Time
+	Reason    string
+	Source    string
+}
+
+// utxoBlocklist keeps outputs which must never be used to fund transactions,
+// either because they were screened as tainted or because operator has other
+// compliance reasons to not spend them
+type utxoBlocklist struct {
+	mu        sync.RWMutex
+	outpoints map[wire.OutPoint]*UtxoBlocklistEntry
+	// keyed by pk script, so that any encoding of the address matches
+	pkScripts map[string]*UtxoBlocklistEntry
+}
+
+func newUtxoBlocklist(
+	cfg *scfg.UtxoBlocklistConfig,
+	net *chaincfg.Params,
+	store *stakerdb.UtxoBlocklistStore,
+) (*utxoBlocklist, error) {
+	b := &utxoBlocklist{
+		outpoints: make(map[wire.OutPoint]*UtxoBlocklistEntry),
+		pkScripts: make(map[string]*UtxoBlocklistEntry),
+	}
+
+	stored, err := store.Entries()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range stored {
+		entry := &UtxoBlocklistEntry{
+			Outpoint:  e.Outpoint,
+			Timestamp: e.Timestamp,
+			Reason:    e.Reason,
+			Source:    UtxoBlocklistSourceRpc,
+		}
+
+		if e.Outpoint != nil {
+			b.outpoints[*e.Outpoint] = entry
+			continue
+		}
+
+		_, addresses, _, err := txscript.ExtractPkScriptAddrs(e.PkScript, net)
+
+		if err != nil || len(addresses) != 1 {
+			return nil, fmt.Errorf("utxo blocklist contains pk script %x which is not valid address on network %s", e.PkScript, net.Name)
+		}
+
+		entry.Address = addresses[0]
+		b.pkScripts[string(e.PkScript)] = entry
+	}
+
+	// entries from config take precedence, so that they can't be removed through rpc
+	outpoints, addresses, err := cfg.Parse(net)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range outpoints {
+		b.outpoints[outpoints[i]] = &UtxoBlocklistEntry{
+			Outpoint: &outpoints[i],
+			Source:   UtxoBlocklistSourceConfig,
+		}
+	}
+
+	for _, address := range addresses {
+		pkScript, err := txscript.PayToAddrScript(address)
+
+		if err != nil {
+			return nil, err
+		}
+
+		b.pkScripts[string(pkScript)] = &UtxoBlocklistEntry{
+			Address: address,
+			Source:  UtxoBlocklistSourceConfig,
+		}
+	}
+
+	return b, nil
+}
+
+func (b *utxoBlocklist) allowed(utxo *walletcontroller.Utxo) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if _, blocked := b.outpoints[utxo.OutPoint]; blocked {
+		return false
+	}
+
+	_, blocked := b.pkScripts[string(utxo.PkScript)]
+	return !blocked
+}
+
+func (b *utxoBlocklist) entries() []UtxoBlocklistEntry {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	entries := make([]UtxoBlocklistEntry, 0, len(b.outpoints)+len(b.pkScripts))
+
+	for _, e := range b.outpoints {
+		entries = append(entries, *e)
+	}
+
+	for _, e := range b.pkScripts {
+		entries = append(entries, *e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entryDisplayValue(&entries[i]) < entryDisplayValue(&entries[j])
+	})
+
+	return entries
+}
+
+func entryDisplayValue(e *UtxoBlocklistEntry) string {
+	if e.Outpoint != nil {
+		return e.Outpoint.String()
+	}
+	return e.Address.EncodeAddress()
+}
+
+func toStoreEntries(
+	outpoints []wire.OutPoint,
+	addresses []btcutil.Address,
+	timestamp time.Time,
+	reason string,
+) ([]stakerdb.UtxoBlocklistEntry, error) {
+	entries := make([]stakerdb.UtxoBlocklistEntry, 0, len(outpoints)+len(addresses))
+
+	for i := range outpoints {
+		entries = append(entries, stakerdb.UtxoBlocklistEntry{
+			Outpoint:  &outpoints[i],
+			Timestamp: timestamp,
+			Reason:    reason,
+		})
+	}
+
+	for _, address := range addresses {
+		pkScript, err := txscript.PayToAddrScript(address)
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot build pk script of address %s: %w", address, err)
+		}
+
+		entries = append(entries, stakerdb.UtxoBlocklistEntry{
+			PkScript:  pkScript,
+			Timestamp: timestamp,
+			Reason:    reason,
+		})
+	}
+
+	return entries, nil
+}
+
+// UtxoBlocklist returns all outpoints and addresses which outputs are never used
+// to fund transactions
+func (app *StakerApp) UtxoBlocklist() []UtxoBlocklistEntry {
+	return app.utxoBlocklist.entries()
+}
+
+// AddToUtxoBlocklist blocks given outpoints and addresses. Blocking is
+// persistent and applies to all transactions created after this call returns.
+func (app *StakerApp) AddToUtxoBlocklist(
+	outpoints []wire.OutPoint,
+	addresses []btcutil.Address,
+	reason string,
+) error {
+	if len(outpoints) == 0 && len(addresses) == 0 {
+		return fmt.Errorf("at least one outpoint or address must be provided: %w", ErrInvalidStakingRequest)
+	}
+
+	if reason == "" {
+		return fmt.Errorf("reason of blocking must be provided: %w", ErrInvalidStakingRequest)
+	}
+
+	b := app.utxoBlocklist
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var toStoreOutpoints []wire.OutPoint
+	var toStoreAddresses []btcutil.Address
+
+	// entries from config are already blocked and can't be overridden
+	for _, o := range outpoints {
+		if e, found := b.outpoints[o]; !found || e.Source != UtxoBlocklistSourceConfig {
+			toStoreOutpoints = append(toStoreOutpoints, o)
+		}
+	}
+
+	for _, a := range addresses {
+		pkScript, err := txscript.PayToAddrScript(a)
+
+		if err != nil {
+			return fmt.Errorf("cannot build pk script of address %s: %w", a, ErrInvalidStakingRequest)
+		}
+
+		if e, found := b.pkScripts[string(pkScript)]; !found || e.Source != UtxoBlocklistSourceConfig {
+			toStoreAddresses = append(toStoreAddresses, a)
+		}
+	}
+
+	now := time.Now()
+
+	entries, err := toStoreEntries(toStoreOutpoints, toStoreAddresses, now, reason)
+
+	if err != nil {
+		return err
+	}
+
+	if err := app.utxoBlocklistStore.AddEntries(entries); err != nil {
+		return err
+	}
+
+	for i := range toStoreOutpoints {
+		b.outpoints[toStoreOutpoints[i]] = &UtxoBlocklistEntry{
+			Outpoint:  &toStoreOutpoints[i],
+			Timestamp: now,
+			Reason:    reason,
+			Source:    UtxoBlocklistSourceRpc,
+		}
+	}
+
+	for i, a := range toStoreAddresses {
+		b.pkScripts[string(entries[len(toStoreOutpoints)+i].PkScript)] = &UtxoBlocklistEntry{
+			Address:   a,
+			Timestamp: now,
+			Reason:    reason,
+			Source:    UtxoBlocklistSourceRpc,
+		}
+	}
+
+	app.logger.WithFields(logrus.Fields{
+		"outpoints": outpoints,
+		"addresses": addresses,
+		"reason":    reason,
+	}).Warn("Outputs added to utxo blocklist")
+
+	return nil
+}
+
+// RemoveFromUtxoBlocklist unblocks given outpoints and addresses. Entries from
+// config can be removed only by changing the config.
+func (app *StakerApp) RemoveFromUtxoBlocklist(
+	outpoints []wire.OutPoint,
+	addresses []btcutil.Address,
+) error {
+	if len(outpoints) == 0 && len(addresses) == 0 {
+		return fmt.Errorf("at least one outpoint or address must be provided: %w", ErrInvalidStakingRequest)
+	}
+
+	b := app.utxoBlocklist
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, o := range outpoints {
+		if e, found := b.outpoints[o]; found && e.Source == UtxoBlocklistSourceConfig {
+			return fmt.Errorf("outpoint %s is blocked in config and can't be removed through rpc: %w", o, ErrInvalidStakingRequest)
+		}
+	}
+
+	entries, err := toStoreEntries(outpoints, addresses, time.Time{}, "")
+
+	if err != nil {
+		return err
+	}
+
+	for i, a := range addresses {
+		pkScript := entries[len(outpoints)+i].PkScript
+
+		if e, found := b.pkScripts[string(pkScript)]; found && e.Source == UtxoBlocklistSourceConfig {
+			return fmt.Errorf("address %s is blocked in config and can't be removed through rpc: %w", a, ErrInvalidStakingRequest)
+		}
+	}
+
+	if err := app.utxoBlocklistStore.RemoveEntries(entries); err != nil {
+		return err
+	}
+
+	for _, o := range outpoints {
+		delete(b.outpoints, o)
+	}
+
+	for _, e := range entries[len(outpoints):] {
+		delete(b.pkScripts, string(e.PkScript))
+	}
+
+	app.logger.WithFields(logrus.Fields{
+		"outpoints": outpoints,
+		"addresses": addresses,
+	}).Warn("Outputs removed from utxo blocklist")
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package staker
+
+import (
+	"fmt"
+
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// withdrawalPolicy restricts destinations of transactions which move funds out
+// of the delegations or the wallet i.e withdrawals, unbonding destinations and
+// consolidations. Disabled policy allows any destination.
+type withdrawalPolicy struct {
+	enabled bool
+	// keyed by pk script, so that any encoding of the address matches
+	pkScripts map[string]struct{}
+}
+
+func newWithdrawalPolicy(cfg *scfg.WithdrawalPolicyConfig, net *chaincfg.Params) (*withdrawalPolicy, error) {
+	scripts, err := cfg.PkScripts(net)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := &withdrawalPolicy{
+		enabled:   cfg.Enabled,
+		pkScripts: make(map[string]struct{}, len(scripts)),
+	}
+
+	for _, s := range scripts {
+		p.pkScripts[string(s)] = struct{}{}
+	}
+
+	return p, nil
+}
+
+func (p *withdrawalPolicy) allowedScript(pkScript []byte) bool {
+	if !p.enabled {
+		return true
+	}
+
+	_, found := p.pkScripts[string(pkScript)]
+	return found
+}
+
+func (p *withdrawalPolicy) checkAddress(address btcutil.Address) error {
+	pkScript, err := txscript.PayToAddrScript(address)
+
+	if err != nil {
+		return err
+	}
+
+	if !p.allowedScript(pkScript) {
+		return fmt.Errorf("destination %s: %w", address.EncodeAddress(), ErrDestinationNotWhitelisted)
+	}
+
+	return nil
+}
+
+// checkWithdrawalDestination returns ErrDestinationNotWhitelisted if withdrawal
+// policy is enabled and given address is not whitelisted
+func (app *StakerApp) checkWithdrawalDestination(address btcutil.Address) error {
+	return app.withdrawalPolicy.checkAddress(address)
+}
@@ -0,0 +1,30 @@
+package stakercfg
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultSlowRequestThreshold = 5 * time.Second
+)
+
+// AccessLogConfig defines logging of requests served by the rpc server
+type AccessLogConfig struct {
+	Enabled       bool          `long:"enabled" description:"Log every rpc request at info level with its method, identity of the caller, duration, sizes and result"`
+	SlowThreshold time.Duration `long:"slowthreshold" description:"Requests taking at least this long are logged at warn level and counted by rpc_slow_requests metric, even if access log is disabled. 0 disables slow request flagging"`
+}
+
+func (cfg *AccessLogConfig) Validate() error {
+	if cfg.SlowThreshold < 0 {
+		return fmt.Errorf("slowthreshold must not be negative")
+	}
+
+	return nil
+}
+
+func DefaultAccessLogConfig() AccessLogConfig {
+	return AccessLogConfig{
+		SlowThreshold: defaultSlowRequestThreshold,
+	}
+}
@@ -0,0 +1,83 @@
+package stakercfg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	defaultApprovalTimeout = time.Hour
+)
+
+// ApprovalConfig defines two person approval mode. In this mode every spend
+// capable rpc call is only queued, and executed after another operator approves
+// it.
+type ApprovalConfig struct {
+	Enabled   bool          `long:"enabled" description:"require approval of every spend capable rpc call by second operator"`
+	Operators []string      `long:"operator" description:"Operator in format <name>:<hex encoded sha256 of operator token>, can be specified multiple times. At least two operators are required"`
+	Timeout   time.Duration `long:"timeout" description:"Time in which queued action must be approved, otherwise it expires"`
+}
+
+// OperatorsByTokenHash returns operator names keyed by sha256 hash of their
+// tokens
+func (cfg *ApprovalConfig) OperatorsByTokenHash() (map[[sha256.Size]byte]string, error) {
+	operators := make(map[[sha256.Size]byte]string, len(cfg.Operators))
+	names := make(map[string]struct{}, len(cfg.Operators))
+
+	for _, o := range cfg.Operators {
+		name, hashHex, found := strings.Cut(strings.TrimSpace(o), ":")
+
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid operator %s, expected format <name>:<token sha256>", o)
+		}
+
+		hashBytes, err := hex.DecodeString(hashHex)
+
+		if err != nil || len(hashBytes) != sha256.Size {
+			return nil, fmt.Errorf("invalid token hash of operator %s, expected hex encoded sha256 hash", name)
+		}
+
+		if _, exists := names[name]; exists {
+			return nil, fmt.Errorf("duplicate operator %s", name)
+		}
+
+		var hash [sha256.Size]byte
+		copy(hash[:], hashBytes)
+
+		if _, exists := operators[hash]; exists {
+			return nil, fmt.Errorf("operator %s has the same token as another operator", name)
+		}
+
+		names[name] = struct{}{}
+		operators[hash] = name
+	}
+
+	return operators, nil
+}
+
+func (cfg *ApprovalConfig) Validate() error {
+	operators, err := cfg.OperatorsByTokenHash()
+
+	if err != nil {
+		return err
+	}
+
+	if cfg.Enabled && len(operators) < 2 {
+		return fmt.Errorf("at least two operators are required when approval mode is enabled")
+	}
+
+	if cfg.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+
+	return nil
+}
+
+func DefaultApprovalConfig() ApprovalConfig {
+	return ApprovalConfig{
+		Timeout: defaultApprovalTimeout,
+	}
+}
@@ -0,0 +1,44 @@
+package stakercfg
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// AutoWithdrawConfig defines how delegations which opted in for automatic
+// withdrawal are withdrawn once their timelock expires
+type AutoWithdrawConfig struct {
+	Destination string `long:"destination" description:"address receiving automatically withdrawn funds, staker address is used if empty. Withdrawal address set for the delegation takes precedence"`
+	MaxFeeRate  uint64 `long:"maxfeerate" description:"automatic withdrawal is postponed while estimated fee rate (in sat/vbyte) is above this value, 0 means no limit"`
+}
+
+// DestinationAddress returns configured destination, or nil if funds are
+// withdrawn to staker address
+func (cfg *AutoWithdrawConfig) DestinationAddress(net *chaincfg.Params) (btcutil.Address, error) {
+	if cfg.Destination == "" {
+		return nil, nil
+	}
+
+	address, err := btcutil.DecodeAddress(cfg.Destination, net)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination %s: %w", cfg.Destination, err)
+	}
+
+	if !address.IsForNet(net) {
+		return nil, fmt.Errorf("destination %s is not valid for network %s", cfg.Destination, net.Name)
+	}
+
+	return address, nil
+}
+
+func (cfg *AutoWithdrawConfig) Validate(net *chaincfg.Params) error {
+	_, err := cfg.DestinationAddress(net)
+	return err
+}
+
+func DefaultAutoWithdrawConfig() AutoWithdrawConfig {
+	return AutoWithdrawConfig{}
+}
@@ -7,20 +7,22 @@ import (
 )
 
 type BBNConfig struct {
-	Key            string        `long:"key" description:"name of the key to sign transactions with"`
-	ChainID        string        `long:"chain-id" description:"chain id of the chain to connect to"`
-	RPCAddr        string        `long:"rpc-address" description:"address of the rpc server to connect to"`
-	GRPCAddr       string        `long:"grpc-address" description:"address of the grpc server to connect to"`
-	AccountPrefix  string        `long:"acc-prefix" description:"account prefix to use for addresses"`
-	KeyringBackend string        `long:"keyring-type" description:"type of keyring to use"`
-	GasAdjustment  float64       `long:"gas-adjustment" description:"adjustment factor when using gas estimation"`
-	GasPrices      string        `long:"gas-prices" description:"comma separated minimum gas prices to accept for transactions"`
-	KeyDirectory   string        `long:"key-dir" description:"directory to store keys in"`
-	Debug          bool          `long:"debug" description:"flag to print debug output"`
-	Timeout        time.Duration `long:"timeout" description:"client timeout when doing queries"`
-	BlockTimeout   time.Duration `long:"block-timeout" description:"block timeout when waiting for block events"`
-	OutputFormat   string        `long:"output-format" description:"default output when printint responses"`
-	SignModeStr    string        `long:"sign-mode" description:"sign mode to use"`
+	Key             string        `long:"key" description:"name of the key to sign transactions with"`
+	ChainID         string        `long:"chain-id" description:"chain id of the chain to connect to"`
+	RPCAddr         string        `long:"rpc-address" description:"address of the rpc server to connect to"`
+	GRPCAddr        string        `long:"grpc-address" description:"address of the grpc server to connect to"`
+	AccountPrefix   string        `long:"acc-prefix" description:"account prefix to use for addresses"`
+	KeyringBackend  string        `long:"keyring-type" description:"type of keyring to use"`
+	GasAdjustment   float64       `long:"gas-adjustment" description:"adjustment factor when using gas estimation"`
+	GasPrices       string        `long:"gas-prices" description:"comma separated minimum gas prices to accept for transactions"`
+	KeyDirectory    string        `long:"key-dir" description:"directory to store keys in"`
+	Debug           bool          `long:"debug" description:"flag to print debug output"`
+	Timeout         time.Duration `long:"timeout" description:"client timeout when doing queries"`
+	BlockTimeout    time.Duration `long:"block-timeout" description:"block timeout when waiting for block events"`
+	OutputFormat    string        `long:"output-format" description:"default output when printint responses"`
+	SignModeStr     string        `long:"sign-mode" description:"sign mode to use"`
+	RewardRecipient string        `long:"reward-recipient" description:"babylon address which receives withdrawn staking rewards. If empty, rewards stay on the address of the configured key"`
+	MockURL         string        `long:"mock-url" description:"url of mock babylon started by stakercli dev mock-babylon, used instead of babylon node. Only for testing"`
 }
 
 func DefaultBBNConfig() BBNConfig {
@@ -0,0 +1,45 @@
+package stakercfg
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultBalanceMonitorInterval = 5 * time.Minute
+	defaultBalanceMonitorDenom    = "ubbn"
+	// enough for a few hundred delegations with default gas prices
+	defaultBalanceMonitorMinBalance = 1_000_000
+)
+
+// BalanceMonitorConfig defines how balance of babylon account paying fees of
+// delegation submissions is monitored
+type BalanceMonitorConfig struct {
+	Interval   time.Duration `long:"interval" description:"How often balance of babylon fee account is checked, 0 disables monitoring"`
+	Denom      string        `long:"denom" description:"Denom in which fees are paid"`
+	MinBalance uint64        `long:"minbalance" description:"Balance below which low balance alert is raised"`
+}
+
+func (cfg *BalanceMonitorConfig) Validate() error {
+	if cfg.Interval < 0 {
+		return fmt.Errorf("interval cannot be negative")
+	}
+
+	if cfg.Interval == 0 {
+		return nil
+	}
+
+	if cfg.Denom == "" {
+		return fmt.Errorf("denom is required when balance monitoring is enabled")
+	}
+
+	return nil
+}
+
+func DefaultBalanceMonitorConfig() BalanceMonitorConfig {
+	return BalanceMonitorConfig{
+		Interval:   defaultBalanceMonitorInterval,
+		Denom:      defaultBalanceMonitorDenom,
+		MinBalance: defaultBalanceMonitorMinBalance,
+	}
+}
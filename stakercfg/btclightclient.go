@@ -0,0 +1,35 @@
+package stakercfg
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultBtcLightClientInterval = time.Minute
+	// reporters usually relay headers within a few blocks
+	defaultBtcLightClientMaxBlocksBehind = 3
+)
+
+// BtcLightClientConfig defines how height of babylon btc light client is
+// compared with the height of connected btc node
+type BtcLightClientConfig struct {
+	Interval               time.Duration `long:"interval" description:"How often height of babylon btc light client is compared with btc node, 0 disables monitoring"`
+	MaxBlocksBehind        uint32        `long:"maxblocksbehind" description:"Number of blocks by which babylon btc light client can lag behind btc node before alert is raised"`
+	DisableSubmissionDelay bool          `long:"disablesubmissiondelay" description:"Do not delay submission of delegations to babylon while btc light client lags behind btc node"`
+}
+
+func (cfg *BtcLightClientConfig) Validate() error {
+	if cfg.Interval < 0 {
+		return fmt.Errorf("interval cannot be negative")
+	}
+
+	return nil
+}
+
+func DefaultBtcLightClientConfig() BtcLightClientConfig {
+	return BtcLightClientConfig{
+		Interval:        defaultBtcLightClientInterval,
+		MaxBlocksBehind: defaultBtcLightClientMaxBlocksBehind,
+	}
+}
@@ -0,0 +1,42 @@
+package stakercfg
+
+import "fmt"
+
+const (
+	defaultRequestIdCacheSize = 10000
+	defaultWalletTxCacheSize  = 10000
+	defaultAddressCacheSize   = 10000
+)
+
+// CacheConfig bounds in-memory caches which would otherwise grow with number of
+// tracked delegations. Evicted entries are loaded again from database or wallet
+// when needed.
+type CacheConfig struct {
+	RequestIds int `long:"requestids" description:"Maximum number of request ids of staking transactions kept in memory for log correlation"`
+	WalletTxs  int `long:"wallettxs" description:"Maximum number of wallet transactions for which it is remembered whether they spend wallet outputs"`
+	Addresses  int `long:"addresses" description:"Maximum number of addresses remembered as tracked by the wallet"`
+}
+
+func (cfg *CacheConfig) Validate() error {
+	if cfg.RequestIds <= 0 {
+		return fmt.Errorf("requestids must be positive")
+	}
+
+	if cfg.WalletTxs <= 0 {
+		return fmt.Errorf("wallettxs must be positive")
+	}
+
+	if cfg.Addresses <= 0 {
+		return fmt.Errorf("addresses must be positive")
+	}
+
+	return nil
+}
+
+func DefaultCacheConfig() CacheConfig {
+	return CacheConfig{
+		RequestIds: defaultRequestIdCacheSize,
+		WalletTxs:  defaultWalletTxCacheSize,
+		Addresses:  defaultAddressCacheSize,
+	}
+}
@@ -0,0 +1,93 @@
+package stakercfg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+const (
+	// change goes back to the address which funded the transaction
+	ChangeDestinationFunding = "funding"
+	// change goes to new address of the wallet
+	ChangeDestinationFresh = "fresh"
+	// change goes to configured cold address
+	ChangeDestinationCold = "cold"
+)
+
+// ChangePolicyConfig defines where change of transactions funded by the wallet
+// goes, separately for every operation which creates change
+type ChangePolicyConfig struct {
+	Stake         string `long:"stake" description:"Change destination of staking transactions: funding (staker address), fresh (new wallet address) or cold (cold address)"`
+	ExternalStake string `long:"externalstake" description:"Change destination of staking transactions of external staker keys: funding (funding address), fresh (new wallet address) or cold (cold address)"`
+	ColdAddress   string `long:"coldaddress" description:"Address or addr() descriptor receiving change of operations with cold change destination"`
+}
+
+// ColdChangeAddress returns configured cold address, nil if it is not set
+func (cfg *ChangePolicyConfig) ColdChangeAddress(net *chaincfg.Params) (btcutil.Address, error) {
+	cold := strings.TrimSpace(cfg.ColdAddress)
+
+	if cold == "" {
+		return nil, nil
+	}
+
+	if !strings.Contains(cold, "(") {
+		return btcutil.DecodeAddress(cold, net)
+	}
+
+	script, err := descriptorPkScript(cold, net)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, addresses, _, err := txscript.ExtractPkScriptAddrs(script, net)
+
+	if err != nil || len(addresses) != 1 {
+		return nil, fmt.Errorf("descriptor %s does not describe single address", cold)
+	}
+
+	return addresses[0], nil
+}
+
+func (cfg *ChangePolicyConfig) Validate(net *chaincfg.Params) error {
+	destinations := map[string]string{
+		"stake":         cfg.Stake,
+		"externalstake": cfg.ExternalStake,
+	}
+
+	coldRequired := false
+
+	for operation, destination := range destinations {
+		switch destination {
+		case ChangeDestinationFunding, ChangeDestinationFresh:
+		case ChangeDestinationCold:
+			coldRequired = true
+		default:
+			return fmt.Errorf("invalid change destination %q of %s, expected one of %s, %s, %s",
+				destination, operation, ChangeDestinationFunding, ChangeDestinationFresh, ChangeDestinationCold)
+		}
+	}
+
+	cold, err := cfg.ColdChangeAddress(net)
+
+	if err != nil {
+		return fmt.Errorf("invalid cold address: %w", err)
+	}
+
+	if coldRequired && cold == nil {
+		return fmt.Errorf("coldaddress must be provided when change of any operation goes to cold address")
+	}
+
+	return nil
+}
+
+func DefaultChangePolicyConfig() ChangePolicyConfig {
+	return ChangePolicyConfig{
+		Stake:         ChangeDestinationFunding,
+		ExternalStake: ChangeDestinationFunding,
+	}
+}
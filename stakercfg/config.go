@@ -12,7 +12,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/babylonchain/btc-staker/scriptbuilder"
 	"github.com/babylonchain/btc-staker/types"
+	"github.com/babylonchain/btc-staker/utils"
 	"go.uber.org/zap"
 
 	"github.com/btcsuite/btcd/btcutil"
@@ -41,6 +43,8 @@ const (
 	// we risk into having transactions rejected by the network due to low fee.
 	DefaultMinFeeRate = 2
 	DefaultMaxFeeRate = 25
+	// listing unspent outputs of big wallets can take a while
+	defaultWalletRpcTimeout = time.Minute
 )
 
 var (
@@ -57,8 +61,10 @@ var (
 )
 
 type ChainConfig struct {
-	Network         string `long:"network" description:"network to run on" choice:"regtest" choice:"testnet" choice:"simnet" choice:"signet"`
+	Network         string `long:"network" description:"network to run on" choice:"regtest" choice:"testnet" choice:"simnet" choice:"signet" choice:"custom"`
 	SigNetChallenge string `long:"signetchallenge" description:"Connect to a custom signet network defined by this challenge instead of using the global default signet test network -- Can be specified multiple times"`
+
+	NetworkParamsFile string `long:"networkparamsfile" description:"Path to json file with parameters of custom network i.e private signet with its own genesis block. Required if network is custom"`
 }
 
 func DefaultChainConfig() ChainConfig {
@@ -68,14 +74,24 @@ func DefaultChainConfig() ChainConfig {
 }
 
 type WalletConfig struct {
-	WalletName string `long:"walletname" description:"name of the wallet to sign Bitcoin transactions"`
-	WalletPass string `long:"walletpassphrase" description:"passphrase to unlock the wallet"`
+	WalletName       string `long:"walletname" description:"name of the wallet to sign Bitcoin transactions"`
+	WalletPass       string `long:"walletpassphrase" description:"passphrase to unlock the wallet"`
+	DeterministicTxs bool   `long:"deterministictxs" description:"build transactions deterministically. Inputs are selected in a stable order, inputs and outputs are sorted according to BIP-69 and locktime is always 0, so the same set of utxos and outputs always produces byte-identical transaction"`
+
+	DisableChangeTracking bool `long:"disablechangetracking" description:"do not import change addresses of staking transactions which are unknown to bitcoind wallet as watch only addresses"`
+	DisableTxLabels       bool `long:"disabletxlabels" description:"do not label addresses of staking and unbonding outputs in bitcoind wallet"`
+
+	MultisigWitnessScripts  []string `long:"multisigwitnessscript" description:"hex encoded multisig witness script of P2WSH or P2SH-P2WSH wallet outputs, used to estimate fees of transactions spending them. Can be specified multiple times"`
+	TaprootScriptPathSpends []string `long:"taprootscriptpathspend" description:"taproot wallet outputs spent through script path, in format <leaf_script_hex>:<control_block_hex>, used to estimate fees of transactions spending them. Can be specified multiple times"`
+
+	UnconfirmedFunding string `long:"unconfirmedfunding" description:"unconfirmed outputs which can fund transactions when minimum funding confirmations is 0 {own, any}. own allows only change and other outputs of transactions spending wallet outputs, so that transactions are never built on top of unconfirmed transactions of third parties" choice:"own" choice:"any"`
 }
 
 func DefaultWalletConfig() WalletConfig {
 	return WalletConfig{
-		WalletName: "wallet",
-		WalletPass: "walletpass",
+		WalletName:         "wallet",
+		WalletPass:         "walletpass",
+		UnconfirmedFunding: "own",
 	}
 }
 
@@ -86,6 +102,8 @@ type WalletRpcConfig struct {
 	DisableTls       bool   `long:"noclienttls" description:"disables tls for the wallet rpc client"`
 	RPCWalletCert    string `long:"rpcwalletcert" description:"File containing the wallet daemon's certificate file"`
 	RawRPCWalletCert string `long:"rawrpcwalletcert" description:"The raw bytes of the wallet daemon's PEM-encoded certificate chain which will be used to authenticate the RPC connection."`
+
+	RpcTimeout time.Duration `long:"rpctimeout" description:"time after which the daemon stops waiting for response to a single wallet rpc call. 0 means waiting indefinitely"`
 }
 
 func DefaultWalletRpcConfig() WalletRpcConfig {
@@ -94,6 +112,7 @@ func DefaultWalletRpcConfig() WalletRpcConfig {
 		Host:       "localhost:18556",
 		User:       "rpcuser",
 		Pass:       "rpcpass",
+		RpcTimeout: defaultWalletRpcTimeout,
 	}
 }
 
@@ -133,6 +152,50 @@ type StakerConfig struct {
 	UnbondingTxCheckInterval  time.Duration `long:"unbondingtxcheckinterval" description:"The interval for staker whether delegation received all covenant signatures"`
 	MaxConcurrentTransactions uint32        `long:"maxconcurrenttransactions" description:"Maximum concurrent transactions in flight to babylon node"`
 	ExitOnCriticalError       bool          `long:"exitoncriticalerror" description:"Exit stakerd on critical error"`
+	AntiFeeSniping            bool          `long:"antifeesniping" description:"Set locktime of withdrawal and consolidation transactions to current best block height to discourage fee sniping. Ignored when deterministictxs is enabled, as locktime of deterministic transactions is always 0"`
+	EnableDevApi              bool          `long:"enabledevapi" description:"Enable developer endpoints which allow providing covenant signatures directly to the staker. Should only be used in tests and private deployments running their own covenant committee"`
+	AllowExternalStakerKeys   bool          `long:"allowexternalstakerkeys" description:"Allow funding staking transactions from connected wallet on behalf of external staker public keys. Funds locked in such transactions can only be spent by the owner of external key"`
+	MaxConcurrentSignings     uint32        `long:"maxconcurrentsignings" description:"Maximum number of staking transactions created, signed and sent by the wallet in parallel. Additional staking requests are queued"`
+	MaxUnconfirmedStakingTxs  uint32        `long:"maxunconfirmedstakingtxs" description:"Maximum number of staking transactions sent to btc which are not confirmed yet. Additional staking requests are queued until previous transactions confirm. 0 means no limit"`
+	StakingQueueTimeout       time.Duration `long:"stakingqueuetimeout" description:"Maximum time staking request waits in queue for free slot before it is rejected"`
+	RetryMaxDelay             time.Duration `long:"retrymaxdelay" description:"Maximum delay between retries of failed delegation operations. Delay grows exponentially up to this value"`
+	RetryMaxAttempts          uint32        `long:"retrymaxattempts" description:"Number of failed attempts of delegation operation after which critical error is reported. Operation is still retried afterwards"`
+
+	BabylonPollWorkers uint32 `long:"babylonpollworkers" description:"Maximum number of pending delegations whose status is queried on babylon in parallel"`
+
+	PersistBabylonTxResponses bool `long:"persistbabylontxresponses" description:"Store hash, height and response code of every babylon transaction sent on behalf of delegation, so that it can be looked up in babylon explorers"`
+
+	MinFundingConfirmations uint32 `long:"minfundingconfirmations" description:"Minimum number of confirmations of wallet outputs used to fund staking transactions. 0 allows funding from unconfirmed outputs, e.g trusted self transfers. Can be overridden per stake request"`
+
+	ScriptBuilder string `long:"scriptbuilder" description:"Name of the builder of staking and unbonding scripts. Deployments using custom script layout can register their own builder, default builds scripts accepted by Babylon"`
+}
+
+func (c *StakerConfig) Validate() error {
+	if c.MaxConcurrentSignings == 0 {
+		return fmt.Errorf("maxconcurrentsignings must be greater than 0")
+	}
+
+	if c.StakingQueueTimeout <= 0 {
+		return fmt.Errorf("stakingqueuetimeout must be positive")
+	}
+
+	if c.RetryMaxDelay <= 0 {
+		return fmt.Errorf("retrymaxdelay must be positive")
+	}
+
+	if c.RetryMaxAttempts == 0 {
+		return fmt.Errorf("retrymaxattempts must be greater than 0")
+	}
+
+	if c.BabylonPollWorkers == 0 {
+		return fmt.Errorf("babylonpollworkers must be greater than 0")
+	}
+
+	if _, err := scriptbuilder.Get(c.ScriptBuilder); err != nil {
+		return fmt.Errorf("invalid scriptbuilder: %w", err)
+	}
+
+	return nil
 }
 
 func DefaultStakerConfig() StakerConfig {
@@ -141,6 +204,18 @@ func DefaultStakerConfig() StakerConfig {
 		UnbondingTxCheckInterval:  30 * time.Second,
 		MaxConcurrentTransactions: 1,
 		ExitOnCriticalError:       true,
+		AntiFeeSniping:            true,
+		EnableDevApi:              false,
+		AllowExternalStakerKeys:   false,
+		MaxConcurrentSignings:     1,
+		MaxUnconfirmedStakingTxs:  0,
+		StakingQueueTimeout:       5 * time.Minute,
+		RetryMaxDelay:             1 * time.Hour,
+		RetryMaxAttempts:          30,
+		BabylonPollWorkers:        8,
+		PersistBabylonTxResponses: true,
+		MinFundingConfirmations:   1,
+		ScriptBuilder:             scriptbuilder.DefaultBuilderName,
 	}
 }
 
@@ -170,6 +245,74 @@ type Config struct {
 
 	MetricsConfig *MetricsConfig `group:"metricsconfig" namespace:"metricsconfig"`
 
+	ConsolidationConfig *ConsolidationConfig `group:"consolidationconfig" namespace:"consolidationconfig"`
+
+	MonitorConfig *MonitorConfig `group:"monitor" namespace:"monitor"`
+
+	RpcCacheConfig *RpcCacheConfig `group:"rpccache" namespace:"rpccache"`
+
+	ReadinessConfig *ReadinessConfig `group:"readiness" namespace:"readiness"`
+
+	ResponseSigningConfig *ResponseSigningConfig `group:"responsesigning" namespace:"responsesigning"`
+
+	RpcAclConfig *RpcAclConfig `group:"rpcacl" namespace:"rpcacl"`
+
+	ExitTemplatesConfig *ExitTemplatesConfig `group:"exittemplates" namespace:"exittemplates"`
+
+	FeeBudgetConfig *FeeBudgetConfig `group:"feebudget" namespace:"feebudget"`
+
+	UtxoBlocklistConfig *UtxoBlocklistConfig `group:"utxoblocklist" namespace:"utxoblocklist"`
+
+	BroadcastConfig *BroadcastConfig `group:"broadcast" namespace:"broadcast"`
+
+	MempoolPolicyConfig *MempoolPolicyConfig `group:"mempoolpolicy" namespace:"mempoolpolicy"`
+
+	PolicyHookConfig *PolicyHookConfig `group:"policyhook" namespace:"policyhook"`
+
+	ApprovalConfig *ApprovalConfig `group:"approval" namespace:"approval"`
+
+	FeeWindowConfig *FeeWindowConfig `group:"feewindow" namespace:"feewindow"`
+
+	PresetsConfig *PresetsConfig `group:"presets" namespace:"presets"`
+
+	WithdrawalPolicyConfig *WithdrawalPolicyConfig `group:"withdrawalpolicy" namespace:"withdrawalpolicy"`
+
+	ChangePolicyConfig *ChangePolicyConfig `group:"changepolicy" namespace:"changepolicy"`
+
+	DebugConfig *DebugConfig `group:"debug" namespace:"debug"`
+
+	BalanceMonitorConfig *BalanceMonitorConfig `group:"balancemonitor" namespace:"balancemonitor"`
+
+	FaucetConfig *FaucetConfig `group:"faucet" namespace:"faucet"`
+
+	QuotaConfig *QuotaConfig `group:"quota" namespace:"quota"`
+
+	ConfirmationSlaConfig *ConfirmationSlaConfig `group:"confirmationsla" namespace:"confirmationsla"`
+
+	FailoverConfig *FailoverConfig `group:"failover" namespace:"failover"`
+
+	BtcLightClientConfig *BtcLightClientConfig `group:"btclightclient" namespace:"btclightclient"`
+
+	RequestSigningConfig *RequestSigningConfig `group:"requestsigning" namespace:"requestsigning"`
+
+	SecretsConfig *SecretsConfig `group:"secrets" namespace:"secrets"`
+
+	SystemdConfig *SystemdConfig `group:"systemd" namespace:"systemd"`
+
+	TrackingSnapshotConfig *TrackingSnapshotConfig `group:"trackingsnapshot" namespace:"trackingsnapshot"`
+
+	CacheConfig *CacheConfig `group:"cache" namespace:"cache"`
+
+	FpAliasConfig *FpAliasConfig `group:"fpalias" namespace:"fpalias"`
+
+	ResponseMaskingConfig *ResponseMaskingConfig `group:"responsemasking" namespace:"responsemasking"`
+
+	EventSinkConfig *EventSinkConfig `group:"eventsink" namespace:"eventsink"`
+
+	AccessLogConfig *AccessLogConfig `group:"accesslog" namespace:"accesslog"`
+
+	AutoWithdrawConfig *AutoWithdrawConfig `group:"autowithdraw" namespace:"autowithdraw"`
+
 	JsonRpcServerConfig *JsonRpcServerConfig
 
 	ActiveNetParams chaincfg.Params
@@ -186,20 +329,88 @@ func DefaultConfig() Config {
 	dbConfig := DefaultDBConfig()
 	stakerConfig := DefaultStakerConfig()
 	metricsCfg := DefaultMetricsConfig()
+	consolidationCfg := DefaultConsolidationConfig()
+	monitorCfg := DefaultMonitorConfig()
+	rpcCacheCfg := DefaultRpcCacheConfig()
+	readinessCfg := DefaultReadinessConfig()
+	responseSigningCfg := DefaultResponseSigningConfig()
+	rpcAclCfg := DefaultRpcAclConfig()
+	exitTemplatesCfg := DefaultExitTemplatesConfig()
+	feeBudgetCfg := DefaultFeeBudgetConfig()
+	utxoBlocklistCfg := DefaultUtxoBlocklistConfig()
+	broadcastCfg := DefaultBroadcastConfig()
+	mempoolPolicyCfg := DefaultMempoolPolicyConfig()
+	policyHookCfg := DefaultPolicyHookConfig()
+	approvalCfg := DefaultApprovalConfig()
+	feeWindowCfg := DefaultFeeWindowConfig()
+	presetsCfg := DefaultPresetsConfig()
+	withdrawalPolicyCfg := DefaultWithdrawalPolicyConfig()
+	changePolicyCfg := DefaultChangePolicyConfig()
+	debugCfg := DefaultDebugConfig()
+	balanceMonitorCfg := DefaultBalanceMonitorConfig()
+	faucetCfg := DefaultFaucetConfig()
+	quotaCfg := DefaultQuotaConfig()
+	confirmationSlaCfg := DefaultConfirmationSlaConfig()
+	failoverCfg := DefaultFailoverConfig()
+	btcLightClientCfg := DefaultBtcLightClientConfig()
+	requestSigningCfg := DefaultRequestSigningConfig()
+	secretsCfg := DefaultSecretsConfig()
+	systemdCfg := DefaultSystemdConfig()
+	trackingSnapshotCfg := DefaultTrackingSnapshotConfig()
+	cacheCfg := DefaultCacheConfig()
+	fpAliasCfg := DefaultFpAliasConfig()
+	responseMaskingCfg := DefaultResponseMaskingConfig()
+	eventSinkCfg := DefaultEventSinkConfig()
+	accessLogCfg := DefaultAccessLogConfig()
+	autoWithdrawCfg := DefaultAutoWithdrawConfig()
 	return Config{
-		StakerdDir:           DefaultStakerdDir,
-		ConfigFile:           DefaultConfigFile,
-		DataDir:              defaultDataDir,
-		DebugLevel:           defaultLogLevel,
-		LogDir:               defaultLogDir,
-		WalletConfig:         &walletConf,
-		WalletRpcConfig:      &rpcConf,
-		ChainConfig:          &chainCfg,
-		BtcNodeBackendConfig: &nodeBackendCfg,
-		BabylonConfig:        &bbnConfig,
-		DBConfig:             &dbConfig,
-		StakerConfig:         &stakerConfig,
-		MetricsConfig:        &metricsCfg,
+		StakerdDir:             DefaultStakerdDir,
+		ConfigFile:             DefaultConfigFile,
+		DataDir:                defaultDataDir,
+		DebugLevel:             defaultLogLevel,
+		LogDir:                 defaultLogDir,
+		WalletConfig:           &walletConf,
+		WalletRpcConfig:        &rpcConf,
+		ChainConfig:            &chainCfg,
+		BtcNodeBackendConfig:   &nodeBackendCfg,
+		BabylonConfig:          &bbnConfig,
+		DBConfig:               &dbConfig,
+		StakerConfig:           &stakerConfig,
+		MetricsConfig:          &metricsCfg,
+		ConsolidationConfig:    &consolidationCfg,
+		MonitorConfig:          &monitorCfg,
+		RpcCacheConfig:         &rpcCacheCfg,
+		ReadinessConfig:        &readinessCfg,
+		ResponseSigningConfig:  &responseSigningCfg,
+		RpcAclConfig:           &rpcAclCfg,
+		ExitTemplatesConfig:    &exitTemplatesCfg,
+		FeeBudgetConfig:        &feeBudgetCfg,
+		UtxoBlocklistConfig:    &utxoBlocklistCfg,
+		BroadcastConfig:        &broadcastCfg,
+		MempoolPolicyConfig:    &mempoolPolicyCfg,
+		PolicyHookConfig:       &policyHookCfg,
+		ApprovalConfig:         &approvalCfg,
+		FeeWindowConfig:        &feeWindowCfg,
+		PresetsConfig:          &presetsCfg,
+		WithdrawalPolicyConfig: &withdrawalPolicyCfg,
+		ChangePolicyConfig:     &changePolicyCfg,
+		DebugConfig:            &debugCfg,
+		BalanceMonitorConfig:   &balanceMonitorCfg,
+		FaucetConfig:           &faucetCfg,
+		QuotaConfig:            &quotaCfg,
+		ConfirmationSlaConfig:  &confirmationSlaCfg,
+		FailoverConfig:         &failoverCfg,
+		BtcLightClientConfig:   &btcLightClientCfg,
+		RequestSigningConfig:   &requestSigningCfg,
+		SecretsConfig:          &secretsCfg,
+		SystemdConfig:          &systemdCfg,
+		TrackingSnapshotConfig: &trackingSnapshotCfg,
+		CacheConfig:            &cacheCfg,
+		FpAliasConfig:          &fpAliasCfg,
+		ResponseMaskingConfig:  &responseMaskingCfg,
+		EventSinkConfig:        &eventSinkCfg,
+		AccessLogConfig:        &accessLogCfg,
+		AutoWithdrawConfig:     &autoWithdrawCfg,
 	}
 }
 
@@ -285,8 +496,18 @@ func LoadConfig() (*Config, *logrus.Logger, *zap.Logger, error) {
 
 	cfgLogger := logrus.New()
 	cfgLogger.Out = os.Stdout
+
+	// Secrets are resolved into a copy of the config, so that resolved values
+	// are never written to the config file by dumpcfg.
+	resolvedCfg := cfg
+	resolvedSecrets, err := ResolveSecrets(&resolvedCfg)
+	if err != nil {
+		cfgLogger.Warnf("Error resolving config secrets: %v", err)
+		return nil, nil, nil, err
+	}
+
 	// Make sure everything we just loaded makes sense.
-	cleanCfg, err := ValidateConfig(cfg)
+	cleanCfg, err := ValidateConfig(resolvedCfg)
 	if err != nil {
 		// Log help message in case of usage error.
 		if _, ok := err.(*usageError); ok {
@@ -328,6 +549,10 @@ func LoadConfig() (*Config, *logrus.Logger, *zap.Logger, error) {
 		}
 	}
 
+	for _, name := range resolvedSecrets {
+		cfgLogger.Infof("Resolved %s from secret provider", name)
+	}
+
 	// Zap logger for rpc client
 	// TODO: Migrate fully to zap
 	zapLogger, err := NewRootLogger("console", cleanCfg.DebugLevel)
@@ -348,6 +573,10 @@ func ValidateConfig(cfg Config) (*Config, error) {
 	if stakerdDir != DefaultStakerdDir {
 		cfg.DataDir = filepath.Join(stakerdDir, defaultDataDirname)
 		cfg.LogDir = filepath.Join(stakerdDir, defaultLogDirname)
+
+		if cfg.ResponseSigningConfig.KeyFile == defaultResponseSigningKeyFile {
+			cfg.ResponseSigningConfig.KeyFile = filepath.Join(stakerdDir, defaultResponseSigningKeyFilename)
+		}
 	}
 
 	funcName := "ValidateConfig"
@@ -380,6 +609,8 @@ func ValidateConfig(cfg Config) (*Config, error) {
 	// attempting to use them later on.
 	cfg.DataDir = CleanAndExpandPath(cfg.DataDir)
 	cfg.LogDir = CleanAndExpandPath(cfg.LogDir)
+	cfg.ResponseSigningConfig.KeyFile = CleanAndExpandPath(cfg.ResponseSigningConfig.KeyFile)
+	cfg.ExitTemplatesConfig.ExportDir = CleanAndExpandPath(cfg.ExitTemplatesConfig.ExportDir)
 
 	// Multiple networks can't be selected simultaneously.  Count number of
 	// network flags passed; assign active network params
@@ -415,6 +646,18 @@ func ValidateConfig(cfg Config) (*Config, error) {
 			sigNetChallenge, sigNetSeeds,
 		)
 		cfg.ActiveNetParams = chainParams
+	case "custom":
+		if cfg.ChainConfig.NetworkParamsFile == "" {
+			return nil, mkErr("networkparamsfile must be provided for custom network")
+		}
+
+		cfg.ChainConfig.NetworkParamsFile = CleanAndExpandPath(cfg.ChainConfig.NetworkParamsFile)
+
+		chainParams, err := utils.LoadCustomNetworkParams(cfg.ChainConfig.NetworkParamsFile)
+		if err != nil {
+			return nil, mkErr("invalid custom network: %v", err)
+		}
+		cfg.ActiveNetParams = *chainParams
 	default:
 		return nil, mkErr(fmt.Sprintf("invalid network: %v",
 			cfg.ChainConfig.Network))
@@ -453,6 +696,166 @@ func ValidateConfig(cfg Config) (*Config, error) {
 		return nil, mkErr(fmt.Sprintf("minfeerate must be less or equal maxfeerate. minfeerate: %d, maxfeerate: %d", cfg.BtcNodeBackendConfig.MinFeeRate, cfg.BtcNodeBackendConfig.MaxFeeRate))
 	}
 
+	if cfg.WalletRpcConfig.RpcTimeout < 0 {
+		return nil, mkErr("wallet rpc timeout must be non-negative")
+	}
+
+	if err := cfg.StakerConfig.Validate(); err != nil {
+		return nil, mkErr("invalid staker config: %v", err)
+	}
+
+	if err := cfg.ConsolidationConfig.Validate(); err != nil {
+		return nil, mkErr("invalid consolidation config: %v", err)
+	}
+
+	if cfg.ConsolidationConfig.Address != "" {
+		if _, err := btcutil.DecodeAddress(cfg.ConsolidationConfig.Address, &cfg.ActiveNetParams); err != nil {
+			return nil, mkErr("invalid consolidation address: %v", err)
+		}
+	}
+
+	if err := cfg.MonitorConfig.Validate(); err != nil {
+		return nil, mkErr("invalid monitor config: %v", err)
+	}
+
+	if err := cfg.RpcCacheConfig.Validate(); err != nil {
+		return nil, mkErr("invalid rpc cache config: %v", err)
+	}
+
+	if err := cfg.ReadinessConfig.Validate(); err != nil {
+		return nil, mkErr("invalid readiness config: %v", err)
+	}
+
+	if err := cfg.ResponseSigningConfig.Validate(); err != nil {
+		return nil, mkErr("invalid response signing config: %v", err)
+	}
+
+	if err := cfg.RpcAclConfig.Validate(); err != nil {
+		return nil, mkErr("invalid rpc acl config: %v", err)
+	}
+
+	if err := cfg.ExitTemplatesConfig.Validate(); err != nil {
+		return nil, mkErr("invalid exit templates config: %v", err)
+	}
+
+	if err := cfg.FeeBudgetConfig.Validate(); err != nil {
+		return nil, mkErr("invalid fee budget config: %v", err)
+	}
+
+	if err := cfg.UtxoBlocklistConfig.Validate(&cfg.ActiveNetParams); err != nil {
+		return nil, mkErr("invalid utxo blocklist config: %v", err)
+	}
+
+	if err := cfg.BroadcastConfig.Validate(); err != nil {
+		return nil, mkErr("invalid broadcast config: %v", err)
+	}
+
+	if err := cfg.MempoolPolicyConfig.Validate(); err != nil {
+		return nil, mkErr("invalid mempool policy config: %v", err)
+	}
+
+	if err := cfg.PolicyHookConfig.Validate(); err != nil {
+		return nil, mkErr("invalid policy hook config: %v", err)
+	}
+
+	if err := cfg.ApprovalConfig.Validate(); err != nil {
+		return nil, mkErr("invalid approval config: %v", err)
+	}
+
+	if err := cfg.FeeWindowConfig.Validate(); err != nil {
+		return nil, mkErr("invalid fee window config: %v", err)
+	}
+
+	if err := cfg.PresetsConfig.Validate(); err != nil {
+		return nil, mkErr("invalid presets config: %v", err)
+	}
+
+	if err := cfg.WithdrawalPolicyConfig.Validate(&cfg.ActiveNetParams); err != nil {
+		return nil, mkErr("invalid withdrawal policy config: %v", err)
+	}
+
+	if err := cfg.ChangePolicyConfig.Validate(&cfg.ActiveNetParams); err != nil {
+		return nil, mkErr("invalid change policy config: %v", err)
+	}
+
+	if err := cfg.DebugConfig.Validate(); err != nil {
+		return nil, mkErr("invalid debug config: %v", err)
+	}
+
+	if err := cfg.BalanceMonitorConfig.Validate(); err != nil {
+		return nil, mkErr("invalid balance monitor config: %v", err)
+	}
+
+	if err := cfg.FaucetConfig.Validate(); err != nil {
+		return nil, mkErr("invalid faucet config: %v", err)
+	}
+
+	if err := cfg.QuotaConfig.Validate(); err != nil {
+		return nil, mkErr("invalid quota config: %v", err)
+	}
+
+	if err := cfg.ConfirmationSlaConfig.Validate(); err != nil {
+		return nil, mkErr("invalid confirmation sla config: %v", err)
+	}
+
+	if err := cfg.FailoverConfig.Validate(); err != nil {
+		return nil, mkErr("invalid failover config: %v", err)
+	}
+
+	if err := cfg.BtcLightClientConfig.Validate(); err != nil {
+		return nil, mkErr("invalid btc light client config: %v", err)
+	}
+
+	if err := cfg.RequestSigningConfig.Validate(); err != nil {
+		return nil, mkErr("invalid request signing config: %v", err)
+	}
+
+	if err := cfg.SecretsConfig.Validate(); err != nil {
+		return nil, mkErr("invalid secrets config: %v", err)
+	}
+
+	if err := cfg.SystemdConfig.Validate(); err != nil {
+		return nil, mkErr("invalid systemd config: %v", err)
+	}
+
+	if err := cfg.TrackingSnapshotConfig.Validate(); err != nil {
+		return nil, mkErr("invalid tracking snapshot config: %v", err)
+	}
+
+	if err := cfg.CacheConfig.Validate(); err != nil {
+		return nil, mkErr("invalid cache config: %v", err)
+	}
+
+	if err := cfg.FpAliasConfig.Validate(); err != nil {
+		return nil, mkErr("invalid finality provider alias config: %v", err)
+	}
+
+	if err := cfg.ResponseMaskingConfig.Validate(); err != nil {
+		return nil, mkErr("invalid response masking config: %v", err)
+	}
+
+	if err := cfg.EventSinkConfig.Validate(); err != nil {
+		return nil, mkErr("invalid event sink config: %v", err)
+	}
+
+	if err := cfg.AccessLogConfig.Validate(); err != nil {
+		return nil, mkErr("invalid access log config: %v", err)
+	}
+
+	if err := cfg.AutoWithdrawConfig.Validate(&cfg.ActiveNetParams); err != nil {
+		return nil, mkErr("invalid auto withdraw config: %v", err)
+	}
+
+	if cfg.FaucetConfig.Url != "" {
+		if cfg.ActiveNetParams.Name == chaincfg.MainNetParams.Name {
+			return nil, mkErr("faucet can be used only on test networks")
+		}
+
+		if cfg.BalanceMonitorConfig.Interval == 0 {
+			return nil, mkErr("faucet requires balance monitoring to be enabled")
+		}
+	}
+
 	// TODO: Validate node host and port
 	// TODO: Validate babylon config!
 
@@ -0,0 +1,30 @@
+package stakercfg
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultConfirmationSlaTarget = time.Hour
+)
+
+// ConfirmationSlaConfig defines target time in which transactions sent by the
+// daemon are expected to receive first confirmation
+type ConfirmationSlaConfig struct {
+	Target time.Duration `long:"target" description:"Time in which sent transaction should receive first confirmation, transactions confirmed later are flagged. 0 disables flagging"`
+}
+
+func (cfg *ConfirmationSlaConfig) Validate() error {
+	if cfg.Target < 0 {
+		return fmt.Errorf("target cannot be negative")
+	}
+
+	return nil
+}
+
+func DefaultConfirmationSlaConfig() ConfirmationSlaConfig {
+	return ConfirmationSlaConfig{
+		Target: defaultConfirmationSlaTarget,
+	}
+}
@@ -0,0 +1,56 @@
+package stakercfg
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultConsolidationMaxUtxoValue = 100_000
+	defaultConsolidationMinUtxos     = 10
+	defaultConsolidationMaxFeeRate   = 3
+)
+
+// ConsolidationConfig defines how small wallet outputs are swept together
+type ConsolidationConfig struct {
+	MaxUtxoValue int64         `long:"maxutxovalue" description:"outputs with value lower or equal to this value (in satoshis) are considered for consolidation"`
+	MinUtxos     uint32        `long:"minutxos" description:"minimum number of small outputs in the wallet required to trigger consolidation"`
+	Interval     time.Duration `long:"interval" description:"how often to check whether wallet should be consolidated. 0 disables automatic consolidation, in that case consolidation is only possible through consolidate_outputs endpoint"`
+	MaxFeeRate   uint64        `long:"maxfeerate" description:"automatic consolidation is performed only when estimated fee rate (in sat/vbyte) is lower or equal to this value"`
+	Address      string        `long:"address" description:"wallet address which receives consolidated funds during automatic consolidation. Required if automatic consolidation is enabled"`
+}
+
+func (cfg *ConsolidationConfig) Validate() error {
+	if cfg.MaxUtxoValue <= 0 {
+		return fmt.Errorf("maxutxovalue must be positive")
+	}
+
+	if cfg.MinUtxos < 2 {
+		return fmt.Errorf("minutxos must be at least 2")
+	}
+
+	if cfg.Interval < 0 {
+		return fmt.Errorf("interval must be non-negative")
+	}
+
+	if cfg.Interval > 0 {
+		if cfg.MaxFeeRate == 0 {
+			return fmt.Errorf("maxfeerate must be positive when automatic consolidation is enabled")
+		}
+
+		if cfg.Address == "" {
+			return fmt.Errorf("address must be provided when automatic consolidation is enabled")
+		}
+	}
+
+	return nil
+}
+
+func DefaultConsolidationConfig() ConsolidationConfig {
+	return ConsolidationConfig{
+		MaxUtxoValue: defaultConsolidationMaxUtxoValue,
+		MinUtxos:     defaultConsolidationMinUtxos,
+		Interval:     0,
+		MaxFeeRate:   defaultConsolidationMaxFeeRate,
+	}
+}
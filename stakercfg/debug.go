@@ -0,0 +1,51 @@
+package stakercfg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+)
+
+// DebugConfig defines optional listener exposing pprof and diagnostics of in
+// memory state of the daemon. Unlike the profile option, every request must
+// be authenticated with bearer token.
+type DebugConfig struct {
+	Listen    string `long:"listen" description:"host:port on which debug listener is started, empty disables the listener"`
+	TokenHash string `long:"tokenhash" description:"Hex encoded sha256 of bearer token required by every request to debug listener"`
+}
+
+// TokenHashBytes returns decoded sha256 hash of the bearer token
+func (cfg *DebugConfig) TokenHashBytes() ([]byte, error) {
+	hashBytes, err := hex.DecodeString(cfg.TokenHash)
+
+	if err != nil || len(hashBytes) != sha256.Size {
+		return nil, fmt.Errorf("invalid token hash, expected hex encoded sha256 hash")
+	}
+
+	return hashBytes, nil
+}
+
+func (cfg *DebugConfig) Validate() error {
+	if cfg.Listen == "" {
+		return nil
+	}
+
+	if _, _, err := net.SplitHostPort(cfg.Listen); err != nil {
+		return fmt.Errorf("invalid listen address %s: %w", cfg.Listen, err)
+	}
+
+	if cfg.TokenHash == "" {
+		return fmt.Errorf("token hash is required when debug listener is enabled")
+	}
+
+	if _, err := cfg.TokenHashBytes(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func DefaultDebugConfig() DebugConfig {
+	return DebugConfig{}
+}
@@ -0,0 +1,92 @@
+package stakercfg
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+)
+
+const (
+	EventSinkFormatJson     = "json"
+	EventSinkFormatProtobuf = "protobuf"
+
+	defaultEventSinkFormat      = EventSinkFormatJson
+	defaultEventSinkNatsSubject = "btcstaker.events"
+	defaultEventSinkKafkaTopic  = "btcstaker-events"
+	defaultEventSinkTimeout     = 10 * time.Second
+	defaultEventSinkQueueSize   = 1000
+)
+
+// EventSinkConfig defines message brokers to which lifecycle events of
+// delegations are published
+type EventSinkConfig struct {
+	Format       string        `long:"format" description:"Encoding of published events" choice:"json" choice:"protobuf"`
+	NatsUrl      string        `long:"natsurl" description:"Url of NATS server in format nats://[user:password@]host:port, tls:// scheme connects over tls. Token can be passed as user without password. Empty url disables the NATS sink"`
+	NatsSubject  string        `long:"natssubject" description:"Prefix of NATS subjects, event is published to <prefix>.<lowercase event type>"`
+	KafkaBrokers []string      `long:"kafkabroker" description:"Address of Kafka bootstrap broker in format host:port. Can be passed multiple times. No broker disables the Kafka sink"`
+	KafkaTopic   string        `long:"kafkatopic" description:"Kafka topic to which events are produced, keyed by staking transaction hash"`
+	KafkaTls     bool          `long:"kafkatls" description:"Connect to Kafka brokers over tls"`
+	Timeout      time.Duration `long:"timeout" description:"Timeout of publishing single event to a sink"`
+	QueueSize    int           `long:"queuesize" description:"Maximum number of events waiting to be published, events are dropped when queue is full"`
+}
+
+func (cfg *EventSinkConfig) Validate() error {
+	if cfg.Format != EventSinkFormatJson && cfg.Format != EventSinkFormatProtobuf {
+		return fmt.Errorf("unknown format %s", cfg.Format)
+	}
+
+	if cfg.NatsUrl != "" {
+		u, err := url.Parse(cfg.NatsUrl)
+
+		if err != nil {
+			return fmt.Errorf("invalid natsurl: %w", err)
+		}
+
+		if u.Scheme != "nats" && u.Scheme != "tls" {
+			return fmt.Errorf("natsurl must have nats or tls scheme")
+		}
+
+		if u.Host == "" {
+			return fmt.Errorf("natsurl must contain host")
+		}
+
+		if cfg.NatsSubject == "" {
+			return fmt.Errorf("natssubject must not be empty")
+		}
+	}
+
+	for _, broker := range cfg.KafkaBrokers {
+		if _, _, err := net.SplitHostPort(broker); err != nil {
+			return fmt.Errorf("invalid kafka broker %s: %w", broker, err)
+		}
+	}
+
+	if len(cfg.KafkaBrokers) > 0 && cfg.KafkaTopic == "" {
+		return fmt.Errorf("kafkatopic must not be empty")
+	}
+
+	if cfg.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+
+	if cfg.QueueSize <= 0 {
+		return fmt.Errorf("queuesize must be positive")
+	}
+
+	return nil
+}
+
+func (cfg *EventSinkConfig) Enabled() bool {
+	return cfg.NatsUrl != "" || len(cfg.KafkaBrokers) > 0
+}
+
+func DefaultEventSinkConfig() EventSinkConfig {
+	return EventSinkConfig{
+		Format:      defaultEventSinkFormat,
+		NatsSubject: defaultEventSinkNatsSubject,
+		KafkaTopic:  defaultEventSinkKafkaTopic,
+		Timeout:     defaultEventSinkTimeout,
+		QueueSize:   defaultEventSinkQueueSize,
+	}
+}
@@ -0,0 +1,33 @@
+package stakercfg
+
+import (
+	"fmt"
+	"os"
+)
+
+// ExitTemplatesConfig defines export of pre-built exit transactions of
+// delegations to cold storage
+type ExitTemplatesConfig struct {
+	ExportDir string `long:"exportdir" description:"Directory to which pre-built unbonding and timelock spend transactions of each delegation are exported as <staking tx hash>.json whenever delegation changes state. Empty value disables export"`
+}
+
+func (cfg *ExitTemplatesConfig) Validate() error {
+	if cfg.ExportDir == "" {
+		return nil
+	}
+
+	// directory is created on first export if it does not exist
+	info, err := os.Stat(cfg.ExportDir)
+
+	if err == nil && !info.IsDir() {
+		return fmt.Errorf("exportdir %s is not a directory", cfg.ExportDir)
+	}
+
+	return nil
+}
+
+func DefaultExitTemplatesConfig() ExitTemplatesConfig {
+	return ExitTemplatesConfig{
+		ExportDir: "",
+	}
+}
@@ -0,0 +1,44 @@
+package stakercfg
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultFaucetTimeout  = 30 * time.Second
+	defaultFaucetCooldown = time.Hour
+)
+
+// FaucetConfig defines faucet from which funds are requested when balance of
+// babylon fee account is low. Intended only for test networks.
+type FaucetConfig struct {
+	Url      string        `long:"url" description:"Url of the faucet which receives POST request with json {\"address\": <babylon address>, \"denom\": <denom>} when balance of babylon fee account is below balance monitor minimum. Empty url disables the hook"`
+	Timeout  time.Duration `long:"timeout" description:"Timeout of single request to the faucet"`
+	Cooldown time.Duration `long:"cooldown" description:"Minimum time between two requests to the faucet, faucets usually rate limit requests from the same address"`
+}
+
+func (cfg *FaucetConfig) Validate() error {
+	if cfg.Url != "" {
+		if err := validateBroadcastUrl(cfg.Url); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+
+	if cfg.Cooldown < 0 {
+		return fmt.Errorf("cooldown cannot be negative")
+	}
+
+	return nil
+}
+
+func DefaultFaucetConfig() FaucetConfig {
+	return FaucetConfig{
+		Timeout:  defaultFaucetTimeout,
+		Cooldown: defaultFaucetCooldown,
+	}
+}
@@ -0,0 +1,49 @@
+package stakercfg
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultFeeHistoryRetention = 90 * 24 * time.Hour
+	// fee budget windows are calculated from fee history
+	minFeeHistoryRetention = 7 * 24 * time.Hour
+)
+
+// FeeBudgetConfig defines limits of btc fees spent by the daemon
+type FeeBudgetConfig struct {
+	DailyBudget           int64         `long:"dailybudget" description:"Budget (in satoshis) of btc fees spent by the daemon in rolling 24h window. 0 means no budget"`
+	WeeklyBudget          int64         `long:"weeklybudget" description:"Budget (in satoshis) of btc fees spent by the daemon in rolling 7 day window. 0 means no budget"`
+	BlockAutomatedActions bool          `long:"blockautomatedactions" description:"Whether automated fee consuming actions (e.g automatic consolidation) are skipped while any budget is exceeded. Actions requested explicitly through rpc are never blocked"`
+	HistoryRetention      time.Duration `long:"historyretention" description:"How long fees of sent transactions are kept for fee analytics, at least 7 days"`
+}
+
+func (cfg *FeeBudgetConfig) Validate() error {
+	if cfg.DailyBudget < 0 {
+		return fmt.Errorf("dailybudget must not be negative")
+	}
+
+	if cfg.WeeklyBudget < 0 {
+		return fmt.Errorf("weeklybudget must not be negative")
+	}
+
+	if cfg.BlockAutomatedActions && cfg.DailyBudget == 0 && cfg.WeeklyBudget == 0 {
+		return fmt.Errorf("blockautomatedactions requires dailybudget or weeklybudget to be set")
+	}
+
+	if cfg.HistoryRetention < minFeeHistoryRetention {
+		return fmt.Errorf("historyretention must be at least %s", minFeeHistoryRetention)
+	}
+
+	return nil
+}
+
+func DefaultFeeBudgetConfig() FeeBudgetConfig {
+	return FeeBudgetConfig{
+		DailyBudget:           0,
+		WeeklyBudget:          0,
+		BlockAutomatedActions: false,
+		HistoryRetention:      defaultFeeHistoryRetention,
+	}
+}
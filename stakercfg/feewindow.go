@@ -0,0 +1,43 @@
+package stakercfg
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultFeeWindowMaxFeeRate = 5
+	defaultFeeWindowMaxWait    = 24 * time.Hour
+)
+
+// FeeWindowConfig defines low fee window in which non urgent transactions are
+// sent. Non urgent transactions are automatic consolidations and scheduled
+// withdrawals.
+type FeeWindowConfig struct {
+	Enabled    bool          `long:"enabled" description:"send non urgent transactions only when estimated fee rate drops to maxfeerate or after they waited for maxwait"`
+	MaxFeeRate uint64        `long:"maxfeerate" description:"fee rate (in sat/vbyte) at or below which the low fee window is open. When enabled, replaces maxfeerate of automatic consolidation"`
+	MaxWait    time.Duration `long:"maxwait" description:"maximum time non urgent transaction waits for the low fee window, after that it is sent with current fee rate"`
+}
+
+func (cfg *FeeWindowConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.MaxFeeRate == 0 {
+		return fmt.Errorf("maxfeerate must be positive")
+	}
+
+	if cfg.MaxWait <= 0 {
+		return fmt.Errorf("maxwait must be positive")
+	}
+
+	return nil
+}
+
+func DefaultFeeWindowConfig() FeeWindowConfig {
+	return FeeWindowConfig{
+		MaxFeeRate: defaultFeeWindowMaxFeeRate,
+		MaxWait:    defaultFeeWindowMaxWait,
+	}
+}
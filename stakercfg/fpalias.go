@@ -0,0 +1,61 @@
+package stakercfg
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+// FpAliasConfig defines human readable names of finality providers, so that
+// operators can search delegations by name instead of public key
+type FpAliasConfig struct {
+	Aliases []string `long:"alias" description:"Alias of finality provider in format <alias>=<hex encoded BIP340 public key>, can be specified multiple times"`
+}
+
+// ParseAliases returns aliases of finality providers keyed by hex encoded
+// BIP340 public key
+func (cfg *FpAliasConfig) ParseAliases() (map[string]string, error) {
+	aliases := make(map[string]string, len(cfg.Aliases))
+
+	for _, a := range cfg.Aliases {
+		alias, pkHex, found := strings.Cut(a, "=")
+		alias = strings.TrimSpace(alias)
+
+		if !found || alias == "" {
+			return nil, fmt.Errorf("invalid finality provider alias %s, expected <alias>=<public key>", a)
+		}
+
+		pkBytes, err := hex.DecodeString(strings.TrimSpace(pkHex))
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key of finality provider alias %s: %w", alias, err)
+		}
+
+		pk, err := schnorr.ParsePubKey(pkBytes)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key of finality provider alias %s: %w", alias, err)
+		}
+
+		key := hex.EncodeToString(schnorr.SerializePubKey(pk))
+
+		if _, duplicate := aliases[key]; duplicate {
+			return nil, fmt.Errorf("finality provider %s has more than one alias", key)
+		}
+
+		aliases[key] = alias
+	}
+
+	return aliases, nil
+}
+
+func (cfg *FpAliasConfig) Validate() error {
+	_, err := cfg.ParseAliases()
+	return err
+}
+
+func DefaultFpAliasConfig() FpAliasConfig {
+	return FpAliasConfig{}
+}
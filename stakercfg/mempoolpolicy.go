@@ -0,0 +1,40 @@
+package stakercfg
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// default -dustrelayfee of bitcoind in sat/kvbyte
+	defaultDustRelayFee         = 3000
+	defaultMempoolProbeInterval = 10 * time.Minute
+)
+
+// MempoolPolicyConfig defines how relay policy of the connected node is taken
+// into account when calculating fees
+type MempoolPolicyConfig struct {
+	MinRelayFee   uint64        `long:"minrelayfee" description:"minimum relay fee rate of the connected node in sat/kvbyte. 0 means it is queried from the node"`
+	DustRelayFee  uint64        `long:"dustrelayfee" description:"dust relay fee rate of the connected node in sat/kvbyte, as set by -dustrelayfee option of bitcoind. It can't be queried from the node"`
+	ProbeInterval time.Duration `long:"probeinterval" description:"how often mempool policy of the connected node is queried. 0 means it is only queried at startup"`
+}
+
+func (cfg *MempoolPolicyConfig) Validate() error {
+	if cfg.DustRelayFee == 0 {
+		return fmt.Errorf("dustrelayfee must be positive")
+	}
+
+	if cfg.ProbeInterval < 0 {
+		return fmt.Errorf("probeinterval must be non-negative")
+	}
+
+	return nil
+}
+
+func DefaultMempoolPolicyConfig() MempoolPolicyConfig {
+	return MempoolPolicyConfig{
+		MinRelayFee:   0,
+		DustRelayFee:  defaultDustRelayFee,
+		ProbeInterval: defaultMempoolProbeInterval,
+	}
+}
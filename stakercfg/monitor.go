@@ -0,0 +1,71 @@
+package stakercfg
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	defaultMonitorBabylonPollInterval = 1 * time.Minute
+	defaultMonitorWebhookTimeout      = 10 * time.Second
+	defaultMonitorBabylonPollWorkers  = 8
+)
+
+// MonitorConfig defines watch only monitoring mode, in which daemon runs without
+// wallet and babylon key and only tracks configured staking transactions
+type MonitorConfig struct {
+	Enabled             bool          `long:"enabled" description:"Run daemon in watch only monitoring mode. In this mode btc wallet and babylon key are not used, daemon only tracks configured staking transactions and serves read only endpoints"`
+	StakingTxs          []string      `long:"stakingtx" description:"Staking transaction to monitor in format <staking_tx_hash>:<staking_output_address>, can be specified multiple times"`
+	StartHeight         uint32        `long:"startheight" description:"Btc block height from which monitored staking transactions are searched for. Should be lower than the height of the oldest monitored transaction"`
+	BabylonPollInterval time.Duration `long:"babylonpollinterval" description:"How often to check status of monitored delegations on babylon"`
+	WebhookUrls         []string      `long:"webhookurl" description:"Url which receives POST request with json payload on every change of monitored transaction, can be specified multiple times"`
+	WebhookTimeout      time.Duration `long:"webhooktimeout" description:"Timeout of single webhook request"`
+	BabylonPollWorkers  uint32        `long:"babylonpollworkers" description:"Maximum number of monitored delegations whose status is queried on babylon in parallel"`
+}
+
+func (cfg *MonitorConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if len(cfg.StakingTxs) == 0 {
+		return fmt.Errorf("at least one staking transaction must be provided in monitoring mode")
+	}
+
+	if cfg.BabylonPollInterval <= 0 {
+		return fmt.Errorf("babylonpollinterval must be positive")
+	}
+
+	if cfg.BabylonPollWorkers == 0 {
+		return fmt.Errorf("babylonpollworkers must be greater than 0")
+	}
+
+	if cfg.WebhookTimeout <= 0 {
+		return fmt.Errorf("webhooktimeout must be positive")
+	}
+
+	for _, webhookUrl := range cfg.WebhookUrls {
+		u, err := url.Parse(webhookUrl)
+
+		if err != nil {
+			return fmt.Errorf("invalid webhook url %s: %w", webhookUrl, err)
+		}
+
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("invalid webhook url %s: only http and https urls are supported", webhookUrl)
+		}
+	}
+
+	return nil
+}
+
+func DefaultMonitorConfig() MonitorConfig {
+	return MonitorConfig{
+		Enabled:             false,
+		StartHeight:         0,
+		BabylonPollInterval: defaultMonitorBabylonPollInterval,
+		WebhookTimeout:      defaultMonitorWebhookTimeout,
+		BabylonPollWorkers:  defaultMonitorBabylonPollWorkers,
+	}
+}
@@ -0,0 +1,38 @@
+package stakercfg
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultPolicyHookTimeout = 10 * time.Second
+)
+
+// PolicyHookConfig defines external policy service which must approve every
+// stake, unbond and withdraw request before it is executed
+type PolicyHookConfig struct {
+	Url      string        `long:"url" description:"Url of the policy service which receives POST request with json description of every stake, unbond and withdraw request. Request is executed only if service responds with {\"approved\": true}. Basic auth credentials can be passed in the url. Empty url disables the hook"`
+	Timeout  time.Duration `long:"timeout" description:"Timeout of single request to the policy service"`
+	FailOpen bool          `long:"failopen" description:"execute requests if the policy service is unreachable or responds with an error. By default such requests are rejected"`
+}
+
+func (cfg *PolicyHookConfig) Validate() error {
+	if cfg.Url != "" {
+		if err := validateBroadcastUrl(cfg.Url); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+
+	return nil
+}
+
+func DefaultPolicyHookConfig() PolicyHookConfig {
+	return PolicyHookConfig{
+		Timeout: defaultPolicyHookTimeout,
+	}
+}
@@ -0,0 +1,166 @@
+package stakercfg
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+var presetNameRegex = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// StakingPreset is named set of staking parameters, which can be referenced in
+// stake calls instead of providing the parameters one by one
+type StakingPreset struct {
+	Name              string
+	FinalityProviders []*btcec.PublicKey
+	StakingTimeBlocks uint16
+	// if not empty, staking amount must be one of the tiers
+	AmountTiers []btcutil.Amount
+	// max fee rate in sat/vbyte at which staking transaction is sent, 0 means
+	// no limit
+	MaxFeeRate uint64
+}
+
+// Validate checks that the preset defines complete and consistent staking
+// parameters
+func (p *StakingPreset) Validate() error {
+	if !presetNameRegex.MatchString(p.Name) {
+		return fmt.Errorf("preset name %q must have at most 64 characters from [A-Za-z0-9_-]", p.Name)
+	}
+
+	if len(p.FinalityProviders) == 0 {
+		return fmt.Errorf("preset %s must define at least one finality provider", p.Name)
+	}
+
+	if p.StakingTimeBlocks == 0 {
+		return fmt.Errorf("preset %s must define staking time", p.Name)
+	}
+
+	seen := make(map[btcutil.Amount]struct{}, len(p.AmountTiers))
+
+	for _, a := range p.AmountTiers {
+		if a <= 0 {
+			return fmt.Errorf("amount tier %d of preset %s must be positive", a, p.Name)
+		}
+
+		if _, found := seen[a]; found {
+			return fmt.Errorf("amount tier %d of preset %s is duplicated", a, p.Name)
+		}
+
+		seen[a] = struct{}{}
+	}
+
+	return nil
+}
+
+// PresetsConfig defines named staking presets. Presets can be also managed
+// through rpc, presets from config can't be changed or removed that way.
+type PresetsConfig struct {
+	Presets []string `long:"preset" description:"Staking preset in format <name>:fp=<hex>,time=<blocks>,amount=<sat>,maxfeerate=<sat/vbyte>. fp and amount can be repeated, amount and maxfeerate are optional. Can be specified multiple times"`
+}
+
+func parsePreset(entry string) (*StakingPreset, error) {
+	name, params, found := strings.Cut(strings.TrimSpace(entry), ":")
+
+	if !found {
+		return nil, fmt.Errorf("preset %s must be in format <name>:<params>", entry)
+	}
+
+	preset := &StakingPreset{Name: name}
+
+	for _, param := range strings.Split(params, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+
+		if !found {
+			return nil, fmt.Errorf("parameter %s of preset %s must be in format <key>=<value>", param, name)
+		}
+
+		switch key {
+		case "fp":
+			pkBytes, err := hex.DecodeString(value)
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid finality provider key %s of preset %s: %w", value, name, err)
+			}
+
+			pk, err := schnorr.ParsePubKey(pkBytes)
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid finality provider key %s of preset %s: %w", value, name, err)
+			}
+
+			preset.FinalityProviders = append(preset.FinalityProviders, pk)
+		case "time":
+			blocks, err := strconv.ParseUint(value, 10, 16)
+
+			if err != nil {
+				return nil, fmt.Errorf("staking time of preset %s must be number of blocks lower than %d", name, math.MaxUint16)
+			}
+
+			preset.StakingTimeBlocks = uint16(blocks)
+		case "amount":
+			amount, err := strconv.ParseInt(value, 10, 64)
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid amount tier %s of preset %s: %w", value, name, err)
+			}
+
+			preset.AmountTiers = append(preset.AmountTiers, btcutil.Amount(amount))
+		case "maxfeerate":
+			feeRate, err := strconv.ParseUint(value, 10, 64)
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid max fee rate %s of preset %s: %w", value, name, err)
+			}
+
+			preset.MaxFeeRate = feeRate
+		default:
+			return nil, fmt.Errorf("unknown parameter %s of preset %s", key, name)
+		}
+	}
+
+	if err := preset.Validate(); err != nil {
+		return nil, err
+	}
+
+	return preset, nil
+}
+
+// Parse returns presets defined in config
+func (cfg *PresetsConfig) Parse() ([]*StakingPreset, error) {
+	presets := make([]*StakingPreset, 0, len(cfg.Presets))
+	names := make(map[string]struct{}, len(cfg.Presets))
+
+	for _, entry := range cfg.Presets {
+		preset, err := parsePreset(entry)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if _, found := names[preset.Name]; found {
+			return nil, fmt.Errorf("preset %s is defined more than once", preset.Name)
+		}
+
+		names[preset.Name] = struct{}{}
+		presets = append(presets, preset)
+	}
+
+	return presets, nil
+}
+
+func (cfg *PresetsConfig) Validate() error {
+	_, err := cfg.Parse()
+	return err
+}
+
+func DefaultPresetsConfig() PresetsConfig {
+	return PresetsConfig{}
+}
@@ -0,0 +1,115 @@
+package stakercfg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultQuotaIdentity is identity of callers which did not authenticate with
+// api token
+const DefaultQuotaIdentity = "default"
+
+// StakeQuota limits stake requests of single api identity in rolling 24 hour
+// window. Zero means no limit.
+type StakeQuota struct {
+	Name      string
+	MaxStakes uint32
+	MaxAmount int64
+}
+
+// QuotaConfig defines per identity quotas of stake and stake_external requests.
+// Identities authenticate with api token, callers without token share default
+// quota.
+type QuotaConfig struct {
+	Identities       []string `long:"identity" description:"Api identity in format <name>:<hex encoded sha256 of api token>:<max stakes per day>:<max staked satoshis per day>, 0 means no limit. Can be specified multiple times"`
+	DefaultMaxStakes uint32   `long:"defaultmaxstakes" description:"Maximum number of stakes per day of callers without api token, 0 means no limit"`
+	DefaultMaxAmount int64    `long:"defaultmaxamount" description:"Maximum staked satoshis per day of callers without api token, 0 means no limit"`
+}
+
+// Enabled returns true if any quota is configured
+func (cfg *QuotaConfig) Enabled() bool {
+	return len(cfg.Identities) > 0 || cfg.DefaultMaxStakes > 0 || cfg.DefaultMaxAmount > 0
+}
+
+// DefaultQuota returns quota of callers without api token
+func (cfg *QuotaConfig) DefaultQuota() StakeQuota {
+	return StakeQuota{
+		Name:      DefaultQuotaIdentity,
+		MaxStakes: cfg.DefaultMaxStakes,
+		MaxAmount: cfg.DefaultMaxAmount,
+	}
+}
+
+// QuotasByTokenHash returns quotas of identities keyed by sha256 hash of their
+// api tokens
+func (cfg *QuotaConfig) QuotasByTokenHash() (map[[sha256.Size]byte]StakeQuota, error) {
+	quotas := make(map[[sha256.Size]byte]StakeQuota, len(cfg.Identities))
+	names := map[string]struct{}{DefaultQuotaIdentity: {}}
+
+	for _, identity := range cfg.Identities {
+		parts := strings.Split(strings.TrimSpace(identity), ":")
+
+		if len(parts) != 4 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid identity %s, expected format <name>:<token sha256>:<max stakes>:<max amount>", identity)
+		}
+
+		name := parts[0]
+
+		if _, exists := names[name]; exists {
+			return nil, fmt.Errorf("duplicate or reserved identity name %s", name)
+		}
+
+		hashBytes, err := hex.DecodeString(parts[1])
+
+		if err != nil || len(hashBytes) != sha256.Size {
+			return nil, fmt.Errorf("invalid token hash of identity %s, expected hex encoded sha256 hash", name)
+		}
+
+		maxStakes, err := strconv.ParseUint(parts[2], 10, 32)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid max stakes of identity %s: %w", name, err)
+		}
+
+		maxAmount, err := strconv.ParseInt(parts[3], 10, 64)
+
+		if err != nil || maxAmount < 0 {
+			return nil, fmt.Errorf("invalid max amount of identity %s, expected non negative number of satoshis", name)
+		}
+
+		var hash [sha256.Size]byte
+		copy(hash[:], hashBytes)
+
+		if _, exists := quotas[hash]; exists {
+			return nil, fmt.Errorf("identity %s has the same token as another identity", name)
+		}
+
+		names[name] = struct{}{}
+		quotas[hash] = StakeQuota{
+			Name:      name,
+			MaxStakes: uint32(maxStakes),
+			MaxAmount: maxAmount,
+		}
+	}
+
+	return quotas, nil
+}
+
+func (cfg *QuotaConfig) Validate() error {
+	if _, err := cfg.QuotasByTokenHash(); err != nil {
+		return err
+	}
+
+	if cfg.DefaultMaxAmount < 0 {
+		return fmt.Errorf("defaultmaxamount cannot be negative")
+	}
+
+	return nil
+}
+
+func DefaultQuotaConfig() QuotaConfig {
+	return QuotaConfig{}
+}
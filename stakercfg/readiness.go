@@ -0,0 +1,41 @@
+package stakercfg
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultReadinessCheckTimeout    = 5 * time.Second
+	defaultReadinessMaxBlocksBehind = 2
+)
+
+// ReadinessConfig defines which dependencies must be healthy for the daemon to
+// report itself as ready on /ready http probe
+type ReadinessConfig struct {
+	CheckTimeout        time.Duration `long:"checktimeout" description:"Time after which readiness check is considered failed"`
+	RequireBtcBackend   bool          `long:"requirebtcbackend" description:"Whether btc node and wallet must be reachable"`
+	RequireBtcSynced    bool          `long:"requirebtcsynced" description:"Whether btc node must be out of initial block download and synced to the best known header"`
+	MaxBlocksBehind     uint32        `long:"maxblocksbehind" description:"Maximum number of blocks by which btc node can lag behind best known header and still be considered synced"`
+	RequireBabylon      bool          `long:"requirebabylon" description:"Whether babylon node must be reachable"`
+	RequireWalletUnlock bool          `long:"requirewalletunlocked" description:"Whether wallet must be unlocked. Staker unlocks the wallet on demand, so this should be enabled only if wallet is unlocked externally"`
+}
+
+func (cfg *ReadinessConfig) Validate() error {
+	if cfg.CheckTimeout <= 0 {
+		return fmt.Errorf("checktimeout must be positive")
+	}
+
+	return nil
+}
+
+func DefaultReadinessConfig() ReadinessConfig {
+	return ReadinessConfig{
+		CheckTimeout:        defaultReadinessCheckTimeout,
+		RequireBtcBackend:   true,
+		RequireBtcSynced:    true,
+		MaxBlocksBehind:     defaultReadinessMaxBlocksBehind,
+		RequireBabylon:      true,
+		RequireWalletUnlock: false,
+	}
+}
@@ -0,0 +1,69 @@
+package stakercfg
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+const (
+	defaultRequestSigningReplayWindow = 5 * time.Minute
+)
+
+// RequestSigningConfig defines mandatory signing of spend capable rpc requests
+// by client keys. It protects against forged and replayed requests when the
+// transport between clients and the daemon can't be trusted.
+type RequestSigningConfig struct {
+	ClientKeys   []string      `long:"clientkey" description:"Hex encoded BIP340 public key of client allowed to send spend capable requests, can be specified multiple times. If no key is provided, requests are not required to be signed"`
+	ReplayWindow time.Duration `long:"replaywindow" description:"Maximum difference between request timestamp and daemon clock. Nonces are remembered for this long, so that requests can't be replayed"`
+}
+
+func (cfg *RequestSigningConfig) Enabled() bool {
+	return len(cfg.ClientKeys) > 0
+}
+
+// ParseClientKeys returns public keys of clients allowed to send spend capable
+// requests
+func (cfg *RequestSigningConfig) ParseClientKeys() ([]*btcec.PublicKey, error) {
+	keys := make([]*btcec.PublicKey, 0, len(cfg.ClientKeys))
+
+	for _, k := range cfg.ClientKeys {
+		keyBytes, err := hex.DecodeString(strings.TrimSpace(k))
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid client key %s: %w", k, err)
+		}
+
+		key, err := schnorr.ParsePubKey(keyBytes)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid client key %s: %w", k, err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func (cfg *RequestSigningConfig) Validate() error {
+	if _, err := cfg.ParseClientKeys(); err != nil {
+		return err
+	}
+
+	if cfg.ReplayWindow <= 0 {
+		return fmt.Errorf("replaywindow must be positive")
+	}
+
+	return nil
+}
+
+func DefaultRequestSigningConfig() RequestSigningConfig {
+	return RequestSigningConfig{
+		ReplayWindow: defaultRequestSigningReplayWindow,
+	}
+}
@@ -0,0 +1,60 @@
+package stakercfg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ResponseMaskingConfig defines fields removed from responses of read only rpc
+// methods for callers which are not operators, so that e.g. change addresses or
+// internal labels are not exposed to every client of the daemon. Operators are
+// callers presenting token of operator from approval config.
+type ResponseMaskingConfig struct {
+	Fields []string `long:"field" description:"Json field removed from read only responses for callers which are not operators, in format <field> for all methods or <method>:<field> for single method. Fields are matched at any depth of the response. Can be specified multiple times"`
+}
+
+// FieldRules returns fields masked in responses of all methods and fields
+// masked in responses of single method, keyed by method
+func (cfg *ResponseMaskingConfig) FieldRules() (map[string]struct{}, map[string]map[string]struct{}, error) {
+	all := make(map[string]struct{})
+	byMethod := make(map[string]map[string]struct{})
+
+	for _, entry := range cfg.Fields {
+		entry = strings.TrimSpace(entry)
+		method, field, forMethod := strings.Cut(entry, ":")
+
+		if !forMethod {
+			field = method
+		}
+
+		if field == "" || (forMethod && method == "") {
+			return nil, nil, fmt.Errorf("invalid field %s, expected format <field> or <method>:<field>", entry)
+		}
+
+		if !forMethod {
+			all[field] = struct{}{}
+			continue
+		}
+
+		if byMethod[method] == nil {
+			byMethod[method] = make(map[string]struct{})
+		}
+
+		byMethod[method][field] = struct{}{}
+	}
+
+	return all, byMethod, nil
+}
+
+func (cfg *ResponseMaskingConfig) Enabled() bool {
+	return len(cfg.Fields) > 0
+}
+
+func (cfg *ResponseMaskingConfig) Validate() error {
+	_, _, err := cfg.FieldRules()
+	return err
+}
+
+func DefaultResponseMaskingConfig() ResponseMaskingConfig {
+	return ResponseMaskingConfig{}
+}
@@ -0,0 +1,35 @@
+package stakercfg
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+const (
+	defaultResponseSigningKeyFilename = "response_signing.key"
+)
+
+var (
+	defaultResponseSigningKeyFile = filepath.Join(DefaultStakerdDir, defaultResponseSigningKeyFilename)
+)
+
+// ResponseSigningConfig defines signing of json rpc responses with service key
+type ResponseSigningConfig struct {
+	Enabled bool   `long:"enabled" description:"Whether json rpc responses are signed with service key"`
+	KeyFile string `long:"keyfile" description:"Path to the file with hex encoded secp256k1 private key used to sign responses. New key is generated if the file does not exist"`
+}
+
+func (cfg *ResponseSigningConfig) Validate() error {
+	if cfg.Enabled && cfg.KeyFile == "" {
+		return fmt.Errorf("keyfile must be provided if response signing is enabled")
+	}
+
+	return nil
+}
+
+func DefaultResponseSigningConfig() ResponseSigningConfig {
+	return ResponseSigningConfig{
+		Enabled: false,
+		KeyFile: defaultResponseSigningKeyFile,
+	}
+}
@@ -0,0 +1,165 @@
+package stakercfg
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+const (
+	// AclOperatorIdentityPrefix prefixes names of operators authenticated with
+	// operator token in access control rules
+	AclOperatorIdentityPrefix = "operator:"
+	// AclApiIdentityPrefix prefixes names of api identities authenticated with
+	// api token in access control rules
+	AclApiIdentityPrefix = "api:"
+)
+
+var (
+	defaultRpcAclCidrs = []string{"127.0.0.0/8", "::1/128"}
+)
+
+// RpcAclConfig defines which source addresses and caller identities may call
+// rpc methods of the daemon. Methods are split into read only and spend capable
+// ones, the latter being methods which move funds, sign with wallet or babylon
+// keys or change daemon state.
+type RpcAclConfig struct {
+	Enabled            bool     `long:"enabled" description:"Whether access control of rpc methods is enforced"`
+	ReadOnlyCidrs      []string `long:"readonlycidr" description:"Source network allowed to call read only methods, can be specified multiple times"`
+	SpendCidrs         []string `long:"spendcidr" description:"Source network allowed to call spend capable methods, can be specified multiple times"`
+	MethodCidrs        []string `long:"methodcidr" description:"Override of allowed source networks for single method in format <method>=<cidr>[,<cidr>...], can be specified multiple times"`
+	ReadOnlyIdentities []string `long:"readonlyidentity" description:"Caller identity allowed to call read only methods in format operator:<name> or api:<name>, can be specified multiple times. If not set, read only methods are not restricted by identity"`
+	SpendIdentities    []string `long:"spendidentity" description:"Caller identity allowed to call spend capable methods in format operator:<name> or api:<name>, can be specified multiple times. If not set, spend capable methods are not restricted by identity"`
+	MethodIdentities   []string `long:"methodidentity" description:"Override of allowed caller identities for single method in format <method>=<identity>[,<identity>...], can be specified multiple times"`
+}
+
+func ParseCidrs(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+
+		if err != nil {
+			return nil, err
+		}
+
+		nets = append(nets, ipNet)
+	}
+
+	return nets, nil
+}
+
+// MethodRules parses per method overrides
+func (cfg *RpcAclConfig) MethodRules() (map[string][]*net.IPNet, error) {
+	rules := make(map[string][]*net.IPNet)
+
+	for _, entry := range cfg.MethodCidrs {
+		method, cidrs, found := strings.Cut(entry, "=")
+
+		if !found || method == "" {
+			return nil, fmt.Errorf("invalid methodcidr %s, expected format <method>=<cidr>[,<cidr>...]", entry)
+		}
+
+		nets, err := ParseCidrs(strings.Split(cidrs, ","))
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid methodcidr %s: %w", entry, err)
+		}
+
+		rules[method] = append(rules[method], nets...)
+	}
+
+	return rules, nil
+}
+
+// ParseAclIdentities parses caller identities of access control rules. Nil is
+// returned for empty list, which means no restriction by identity.
+func ParseAclIdentities(identities []string) (map[string]struct{}, error) {
+	if len(identities) == 0 {
+		return nil, nil
+	}
+
+	parsed := make(map[string]struct{}, len(identities))
+
+	for _, identity := range identities {
+		identity = strings.TrimSpace(identity)
+
+		name, found := strings.CutPrefix(identity, AclOperatorIdentityPrefix)
+		if !found {
+			name, found = strings.CutPrefix(identity, AclApiIdentityPrefix)
+		}
+
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid identity %s, expected format operator:<name> or api:<name>", identity)
+		}
+
+		parsed[identity] = struct{}{}
+	}
+
+	return parsed, nil
+}
+
+// MethodIdentityRules parses per method identity overrides
+func (cfg *RpcAclConfig) MethodIdentityRules() (map[string]map[string]struct{}, error) {
+	rules := make(map[string]map[string]struct{})
+
+	for _, entry := range cfg.MethodIdentities {
+		method, identities, found := strings.Cut(entry, "=")
+
+		if !found || method == "" {
+			return nil, fmt.Errorf("invalid methodidentity %s, expected format <method>=<identity>[,<identity>...]", entry)
+		}
+
+		parsed, err := ParseAclIdentities(strings.Split(identities, ","))
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid methodidentity %s: %w", entry, err)
+		}
+
+		if rules[method] == nil {
+			rules[method] = make(map[string]struct{}, len(parsed))
+		}
+
+		for identity := range parsed {
+			rules[method][identity] = struct{}{}
+		}
+	}
+
+	return rules, nil
+}
+
+func (cfg *RpcAclConfig) Validate() error {
+	if _, err := ParseCidrs(cfg.ReadOnlyCidrs); err != nil {
+		return fmt.Errorf("invalid readonlycidr: %w", err)
+	}
+
+	if _, err := ParseCidrs(cfg.SpendCidrs); err != nil {
+		return fmt.Errorf("invalid spendcidr: %w", err)
+	}
+
+	if _, err := cfg.MethodRules(); err != nil {
+		return err
+	}
+
+	if _, err := ParseAclIdentities(cfg.ReadOnlyIdentities); err != nil {
+		return fmt.Errorf("invalid readonlyidentity: %w", err)
+	}
+
+	if _, err := ParseAclIdentities(cfg.SpendIdentities); err != nil {
+		return fmt.Errorf("invalid spendidentity: %w", err)
+	}
+
+	if _, err := cfg.MethodIdentityRules(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func DefaultRpcAclConfig() RpcAclConfig {
+	return RpcAclConfig{
+		Enabled:       false,
+		ReadOnlyCidrs: defaultRpcAclCidrs,
+		SpendCidrs:    defaultRpcAclCidrs,
+	}
+}
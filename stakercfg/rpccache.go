@@ -0,0 +1,29 @@
+package stakercfg
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultRpcCacheTTL = 30 * time.Second
+)
+
+// RpcCacheConfig defines caching of expensive read only rpc responses
+type RpcCacheConfig struct {
+	TTL time.Duration `long:"ttl" description:"How long responses of expensive read only rpc calls (finality providers, babylon staking params, fee estimates) are cached. 0 disables caching"`
+}
+
+func (cfg *RpcCacheConfig) Validate() error {
+	if cfg.TTL < 0 {
+		return fmt.Errorf("ttl must not be negative")
+	}
+
+	return nil
+}
+
+func DefaultRpcCacheConfig() RpcCacheConfig {
+	return RpcCacheConfig{
+		TTL: defaultRpcCacheTTL,
+	}
+}
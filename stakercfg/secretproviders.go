@@ -0,0 +1,279 @@
+package stakercfg
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+const (
+	// responses of secret providers are small
+	maxSecretProviderResponseBytes = 1 << 20
+)
+
+// endpoints of cloud providers, variables so that tests can point them to
+// local servers
+var (
+	// overrides KMS endpoint of the region resolved by AWS SDK
+	awsKmsEndpoint      = ""
+	gcpKmsEndpoint      = "https://cloudkms.googleapis.com"
+	gcpMetadataTokenUrl = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// httpClient returns client of requests to secret providers. Requests are
+// bounded by the timeout even if provider keeps connection open.
+func (cfg *SecretsConfig) httpClient() *http.Client {
+	return &http.Client{Timeout: cfg.Timeout}
+}
+
+// doSecretRequest executes request and decodes json response into result
+func doSecretRequest(client *http.Client, req *http.Request, result interface{}) error {
+	resp, err := client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSecretProviderResponseBytes))
+
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("provider responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("invalid response of provider: %w", err)
+	}
+
+	return nil
+}
+
+func envOrDefault(value string, env string) string {
+	if value != "" {
+		return value
+	}
+
+	return os.Getenv(env)
+}
+
+// resolveVault reads field of the secret from Vault. Both kv version 1 and
+// version 2 secrets engines are supported, for version 2 the path must contain
+// data segment i.e secret/data/staker.
+func (cfg *SecretsConfig) resolveVault(ctx context.Context, ref string) (string, error) {
+	path, field, found := strings.Cut(ref, "#")
+
+	if !found || path == "" || field == "" {
+		return "", fmt.Errorf("vault reference must be in format <secret path>#<field>")
+	}
+
+	address := envOrDefault(cfg.VaultAddress, "VAULT_ADDR")
+
+	if address == "" {
+		return "", fmt.Errorf("vault address is not configured")
+	}
+
+	token := envOrDefault(cfg.VaultToken, "VAULT_TOKEN")
+
+	if provider, tokenRef := secretRef(token); provider == secretProviderEnv || provider == secretProviderFile {
+		t, err := resolveLocalSecret(provider, tokenRef)
+
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve vault token: %w", err)
+		}
+
+		token = t
+	}
+
+	if token == "" {
+		return "", fmt.Errorf("vault token is not configured")
+	}
+
+	endpoint := strings.TrimSuffix(address, "/") + "/v1/" + strings.TrimPrefix(path, "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("X-Vault-Token", token)
+
+	if cfg.VaultNamespace != "" {
+		req.Header.Set("X-Vault-Namespace", cfg.VaultNamespace)
+	}
+
+	var resp struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+
+	if err := doSecretRequest(cfg.httpClient(), req, &resp); err != nil {
+		return "", err
+	}
+
+	data := resp.Data
+
+	// kv version 2 nests secret data together with its metadata
+	if nested, hasData := data["data"]; hasData {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = nil
+			if err := json.Unmarshal(nested, &data); err != nil {
+				return "", fmt.Errorf("invalid response of provider: %w", err)
+			}
+		}
+	}
+
+	rawValue, found := data[field]
+
+	if !found {
+		return "", fmt.Errorf("secret %s has no field %s", path, field)
+	}
+
+	var value string
+	if err := json.Unmarshal(rawValue, &value); err != nil {
+		return "", fmt.Errorf("field %s of secret %s is not a string", field, path)
+	}
+
+	return value, nil
+}
+
+// resolveAwsKms decrypts ciphertext with AWS KMS. Ciphertext contains id of the
+// key, so the key does not need to be configured. Region and credentials are
+// resolved by default chains of AWS SDK: environment variables, shared config
+// and credentials files including SSO and web identity profiles, and roles of
+// ECS tasks and EC2 instances.
+func (cfg *SecretsConfig) resolveAwsKms(ctx context.Context, ref string) (string, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(ref)
+
+	if err != nil {
+		return "", fmt.Errorf("awskms reference must be base64 encoded ciphertext: %w", err)
+	}
+
+	// buildable client keeps support of custom CA bundle of AWS config
+	opts := []func(*awsconfig.LoadOptions) error{
+		awsconfig.WithHTTPClient(awshttp.NewBuildableClient().WithTimeout(cfg.Timeout)),
+	}
+
+	if cfg.AwsRegion != "" {
+		opts = append(opts, awsconfig.WithRegion(cfg.AwsRegion))
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to load aws config: %w", err)
+	}
+
+	if awsCfg.Region == "" {
+		return "", fmt.Errorf("aws region is not configured")
+	}
+
+	client := kms.NewFromConfig(awsCfg, func(o *kms.Options) {
+		if awsKmsEndpoint != "" {
+			o.BaseEndpoint = aws.String(awsKmsEndpoint)
+		}
+	})
+
+	resp, err := client.Decrypt(ctx, &kms.DecryptInput{CiphertextBlob: ciphertext})
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(resp.Plaintext), nil
+}
+
+// gcpAccessToken returns access token from GOOGLE_OAUTH_ACCESS_TOKEN environment
+// variable, or of the default service account from GCE metadata server
+func gcpAccessToken(ctx context.Context, client *http.Client) (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcpMetadataTokenUrl, nil)
+
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+	}
+
+	if err := doSecretRequest(client, req, &resp); err != nil {
+		return "", fmt.Errorf("failed to get access token from metadata server, set GOOGLE_OAUTH_ACCESS_TOKEN outside of GCP: %w", err)
+	}
+
+	return resp.AccessToken, nil
+}
+
+// resolveGcpKms decrypts ciphertext with GCP KMS key
+func (cfg *SecretsConfig) resolveGcpKms(ctx context.Context, ref string) (string, error) {
+	keyName, ciphertext, found := strings.Cut(ref, "#")
+
+	if !found || keyName == "" || ciphertext == "" {
+		return "", fmt.Errorf("gcpkms reference must be in format <crypto key resource name>#<base64 encoded ciphertext>")
+	}
+
+	if _, err := base64.StdEncoding.DecodeString(ciphertext); err != nil {
+		return "", fmt.Errorf("gcpkms ciphertext must be base64 encoded: %w", err)
+	}
+
+	client := cfg.httpClient()
+
+	token, err := gcpAccessToken(ctx, client)
+
+	if err != nil {
+		return "", err
+	}
+
+	body, err := json.Marshal(map[string]string{"ciphertext": ciphertext})
+
+	if err != nil {
+		return "", err
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/%s:decrypt", gcpKmsEndpoint, strings.TrimPrefix(keyName, "/"))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var resp struct {
+		Plaintext string `json:"plaintext"`
+	}
+
+	if err := doSecretRequest(client, req, &resp); err != nil {
+		return "", err
+	}
+
+	plaintext, err := base64.StdEncoding.DecodeString(resp.Plaintext)
+
+	if err != nil {
+		return "", fmt.Errorf("invalid plaintext returned by provider: %w", err)
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,247 @@
+package stakercfg
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveVault(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		response string
+	}{
+		{
+			name:     "kv version 1",
+			path:     "/v1/secret/staker",
+			response: `{"data":{"passphrase":"s3cret"}}`,
+		},
+		{
+			name:     "kv version 2",
+			path:     "/v1/secret/data/staker",
+			response: `{"data":{"data":{"passphrase":"s3cret"},"metadata":{"version":3}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet || r.URL.Path != tt.path ||
+					r.Header.Get("X-Vault-Token") != "vault-token" ||
+					r.Header.Get("X-Vault-Namespace") != "staking" {
+					http.Error(w, "unexpected request", http.StatusBadRequest)
+					return
+				}
+
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer srv.Close()
+
+			cfg := &SecretsConfig{
+				VaultAddress:   srv.URL + "/",
+				VaultToken:     "vault-token",
+				VaultNamespace: "staking",
+			}
+
+			value, err := cfg.resolveVault(context.Background(), strings.TrimPrefix(tt.path, "/v1/")+"#passphrase")
+			require.NoError(t, err)
+			require.Equal(t, "s3cret", value)
+
+			_, err = cfg.resolveVault(context.Background(), strings.TrimPrefix(tt.path, "/v1/")+"#missing")
+			require.ErrorContains(t, err, "has no field missing")
+		})
+	}
+}
+
+func TestResolveVaultErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	cfg := &SecretsConfig{VaultAddress: srv.URL, VaultToken: "vault-token"}
+
+	_, err := cfg.resolveVault(context.Background(), "secret/staker#passphrase")
+	require.ErrorContains(t, err, "status 403")
+	require.ErrorContains(t, err, "permission denied")
+}
+
+func TestResolveVaultTimeout(t *testing.T) {
+	release := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	cfg := &SecretsConfig{VaultAddress: srv.URL, VaultToken: "vault-token", Timeout: 100 * time.Millisecond}
+
+	// request is bounded by the timeout of the client, even without deadline of
+	// the context
+	_, err := cfg.resolveVault(context.Background(), "secret/staker#passphrase")
+	require.ErrorContains(t, err, "Client.Timeout exceeded")
+}
+
+// isolateAwsConfig points AWS SDK to given shared config and credentials files,
+// clears AWS environment variables and disables EC2 instance metadata
+func isolateAwsConfig(t *testing.T, config string, credentials string) {
+	dir := t.TempDir()
+
+	configFile := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(configFile, []byte(config), 0600))
+	credentialsFile := filepath.Join(dir, "credentials")
+	require.NoError(t, os.WriteFile(credentialsFile, []byte(credentials), 0600))
+
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+	for _, env := range []string{
+		"AWS_PROFILE", "AWS_REGION", "AWS_DEFAULT_REGION", "AWS_ACCESS_KEY_ID",
+		"AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_WEB_IDENTITY_TOKEN_FILE",
+		"AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "AWS_CONTAINER_CREDENTIALS_FULL_URI", "AWS_CA_BUNDLE",
+	} {
+		t.Setenv(env, "")
+	}
+}
+
+func TestResolveAwsKms(t *testing.T) {
+	ciphertext := base64.StdEncoding.EncodeToString([]byte("ciphertext"))
+
+	var authorization, sessionToken string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		if r.Header.Get("X-Amz-Target") != "TrentService.Decrypt" ||
+			r.Header.Get("Content-Type") != "application/x-amz-json-1.1" ||
+			req["CiphertextBlob"] != ciphertext {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+
+		authorization = r.Header.Get("Authorization")
+		sessionToken = r.Header.Get("X-Amz-Security-Token")
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"KeyId":     "arn:aws:kms:eu-west-1:111122223333:key/test",
+			"Plaintext": base64.StdEncoding.EncodeToString([]byte("s3cret")),
+		})
+	}))
+	defer srv.Close()
+
+	defaultEndpoint := awsKmsEndpoint
+	awsKmsEndpoint = srv.URL
+	defer func() { awsKmsEndpoint = defaultEndpoint }()
+
+	t.Run("environment credentials", func(t *testing.T) {
+		isolateAwsConfig(t, "", "")
+		t.Setenv("AWS_ACCESS_KEY_ID", "env-access-key")
+		t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret-key")
+		t.Setenv("AWS_SESSION_TOKEN", "session-token")
+
+		cfg := &SecretsConfig{AwsRegion: "eu-west-1"}
+
+		value, err := cfg.resolveAwsKms(context.Background(), ciphertext)
+		require.NoError(t, err)
+		require.Equal(t, "s3cret", value)
+		require.True(t, strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=env-access-key/"), authorization)
+		require.Contains(t, authorization, "/eu-west-1/kms/aws4_request")
+		require.Equal(t, "session-token", sessionToken)
+	})
+
+	t.Run("shared config profile", func(t *testing.T) {
+		isolateAwsConfig(t,
+			"[profile staker]\nregion = eu-central-1\n",
+			"[staker]\naws_access_key_id = file-access-key\naws_secret_access_key = file-secret-key\n",
+		)
+		t.Setenv("AWS_PROFILE", "staker")
+
+		// region and credentials come from the profile
+		cfg := &SecretsConfig{}
+
+		value, err := cfg.resolveAwsKms(context.Background(), ciphertext)
+		require.NoError(t, err)
+		require.Equal(t, "s3cret", value)
+		require.True(t, strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=file-access-key/"), authorization)
+		require.Contains(t, authorization, "/eu-central-1/kms/aws4_request")
+		require.Empty(t, sessionToken)
+	})
+
+	t.Run("invalid reference", func(t *testing.T) {
+		cfg := &SecretsConfig{AwsRegion: "eu-west-1"}
+
+		_, err := cfg.resolveAwsKms(context.Background(), "not base64")
+		require.ErrorContains(t, err, "must be base64 encoded")
+	})
+
+	t.Run("no region", func(t *testing.T) {
+		isolateAwsConfig(t, "", "")
+
+		cfg := &SecretsConfig{}
+
+		_, err := cfg.resolveAwsKms(context.Background(), ciphertext)
+		require.ErrorContains(t, err, "aws region is not configured")
+	})
+}
+
+func TestResolveGcpKms(t *testing.T) {
+	const keyName = "projects/p/locations/global/keyRings/r/cryptoKeys/k"
+	ciphertext := base64.StdEncoding.EncodeToString([]byte("ciphertext"))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if r.Header.Get("Metadata-Flavor") != "Google" {
+				http.Error(w, "missing metadata flavor", http.StatusForbidden)
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"access_token":"metadata-token","expires_in":3599,"token_type":"Bearer"}`))
+		case "/v1/" + keyName + ":decrypt":
+			var req map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&req)
+
+			if r.Header.Get("Authorization") != "Bearer metadata-token" || req["ciphertext"] != ciphertext {
+				http.Error(w, "unexpected request", http.StatusUnauthorized)
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"plaintext":"` + base64.StdEncoding.EncodeToString([]byte("s3cret")) + `"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	defaultKmsEndpoint, defaultTokenUrl := gcpKmsEndpoint, gcpMetadataTokenUrl
+	gcpKmsEndpoint, gcpMetadataTokenUrl = srv.URL, srv.URL+"/token"
+	defer func() { gcpKmsEndpoint, gcpMetadataTokenUrl = defaultKmsEndpoint, defaultTokenUrl }()
+
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "")
+
+	cfg := &SecretsConfig{Timeout: defaultSecretsTimeout}
+
+	value, err := cfg.resolveGcpKms(context.Background(), keyName+"#"+ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, "s3cret", value)
+
+	// token from environment takes precedence over metadata server
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "other-token")
+
+	_, err = cfg.resolveGcpKms(context.Background(), keyName+"#"+ciphertext)
+	require.ErrorContains(t, err, "status 401")
+}
@@ -0,0 +1,185 @@
+package stakercfg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSecretsTimeout = 30 * time.Second
+
+	secretProviderEnv    = "env"
+	secretProviderFile   = "file"
+	secretProviderVault  = "vault"
+	secretProviderAwsKms = "awskms"
+	secretProviderGcpKms = "gcpkms"
+	// plain forces value to be used as is, even if it looks like reference
+	secretProviderPlain = "plain"
+)
+
+// SecretsConfig defines providers of secrets referenced in the config. Secret
+// options (wallet passphrase, rpc passwords, urls with credentials) can contain
+// reference in format <provider>:<reference> instead of plaintext value:
+//   - env:<variable name>
+//   - file:<path to file>
+//   - vault:<secret path>#<field>
+//   - awskms:<base64 encoded ciphertext>
+//   - gcpkms:<crypto key resource name>#<base64 encoded ciphertext>
+//
+// References are resolved once, at daemon startup.
+type SecretsConfig struct {
+	VaultAddress   string        `long:"vaultaddress" description:"Address of Vault server resolving vault: references. Defaults to VAULT_ADDR environment variable"`
+	VaultToken     string        `long:"vaulttoken" description:"Vault token, can be env: or file: reference. Defaults to VAULT_TOKEN environment variable"`
+	VaultNamespace string        `long:"vaultnamespace" description:"Vault enterprise namespace"`
+	AwsRegion      string        `long:"awsregion" description:"AWS region of KMS resolving awskms: references. Defaults to region of AWS SDK default config, i.e AWS_REGION environment variable or region of the profile in shared config file. Credentials are resolved by AWS SDK default credential chain"`
+	Timeout        time.Duration `long:"timeout" description:"Timeout of resolving single secret from external provider"`
+}
+
+func (cfg *SecretsConfig) Validate() error {
+	if cfg.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+
+	return nil
+}
+
+func DefaultSecretsConfig() SecretsConfig {
+	return SecretsConfig{
+		Timeout: defaultSecretsTimeout,
+	}
+}
+
+// secretRef returns provider and reference of the value. Values which are not
+// references are returned with empty provider.
+func secretRef(value string) (string, string) {
+	provider, ref, found := strings.Cut(value, ":")
+
+	if !found {
+		return "", value
+	}
+
+	switch provider {
+	case secretProviderEnv, secretProviderFile, secretProviderVault,
+		secretProviderAwsKms, secretProviderGcpKms, secretProviderPlain:
+		return provider, ref
+	default:
+		return "", value
+	}
+}
+
+// resolveLocalSecret resolves env: and file: references
+func resolveLocalSecret(provider string, ref string) (string, error) {
+	switch provider {
+	case secretProviderEnv:
+		value, found := os.LookupEnv(ref)
+
+		if !found {
+			return "", fmt.Errorf("environment variable %s is not set", ref)
+		}
+
+		return value, nil
+	case secretProviderFile:
+		value, err := os.ReadFile(CleanAndExpandPath(ref))
+
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file: %w", err)
+		}
+
+		// files usually end with new line, which is never part of the secret
+		return strings.TrimRight(string(value), "\r\n"), nil
+	default:
+		return "", fmt.Errorf("unsupported provider %s", provider)
+	}
+}
+
+func (cfg *SecretsConfig) resolve(ctx context.Context, provider string, ref string) (string, error) {
+	switch provider {
+	case secretProviderPlain:
+		return ref, nil
+	case secretProviderEnv, secretProviderFile:
+		return resolveLocalSecret(provider, ref)
+	case secretProviderVault:
+		return cfg.resolveVault(ctx, ref)
+	case secretProviderAwsKms:
+		return cfg.resolveAwsKms(ctx, ref)
+	case secretProviderGcpKms:
+		return cfg.resolveGcpKms(ctx, ref)
+	default:
+		return "", fmt.Errorf("unsupported provider %s", provider)
+	}
+}
+
+// ResolveSecrets replaces secret references in cfg with values returned by their
+// providers. Resolved values are stored in copies of affected config groups, so
+// that config groups shared with other copies of the config, i.e. the one written
+// by dumpcfg, keep the references. Returns names of resolved options.
+func ResolveSecrets(cfg *Config) ([]string, error) {
+	if err := cfg.SecretsConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid secrets config: %w", err)
+	}
+
+	walletCfg := *cfg.WalletConfig
+	walletRpcCfg := *cfg.WalletRpcConfig
+	nodeBackendCfg := *cfg.BtcNodeBackendConfig
+	btcdCfg := *nodeBackendCfg.Btcd
+	bitcoindCfg := *nodeBackendCfg.Bitcoind
+	failoverCfg := *cfg.FailoverConfig
+	policyHookCfg := *cfg.PolicyHookConfig
+	faucetCfg := *cfg.FaucetConfig
+	eventSinkCfg := *cfg.EventSinkConfig
+
+	secrets := []struct {
+		name  string
+		value *string
+	}{
+		{"walletconfig.walletpassphrase", &walletCfg.WalletPass},
+		{"walletrpcconfig.walletpassword", &walletRpcCfg.Pass},
+		{"btcnodebackend.btcd.rpcpass", &btcdCfg.RPCPass},
+		{"btcnodebackend.bitcoind.rpcpass", &bitcoindCfg.RPCPass},
+		{"failover.url", &failoverCfg.Url},
+		{"policyhook.url", &policyHookCfg.Url},
+		{"faucet.url", &faucetCfg.Url},
+		{"eventsink.natsurl", &eventSinkCfg.NatsUrl},
+	}
+
+	var resolved []string
+
+	for _, s := range secrets {
+		provider, ref := secretRef(*s.value)
+
+		if provider == "" {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.SecretsConfig.Timeout)
+		value, err := cfg.SecretsConfig.resolve(ctx, provider, ref)
+		cancel()
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s from %s provider: %w", s.name, provider, err)
+		}
+
+		if value == "" {
+			return nil, fmt.Errorf("%s resolved to empty value by %s provider", s.name, provider)
+		}
+
+		*s.value = value
+		resolved = append(resolved, s.name)
+	}
+
+	nodeBackendCfg.Btcd = &btcdCfg
+	nodeBackendCfg.Bitcoind = &bitcoindCfg
+
+	cfg.WalletConfig = &walletCfg
+	cfg.WalletRpcConfig = &walletRpcCfg
+	cfg.BtcNodeBackendConfig = &nodeBackendCfg
+	cfg.FailoverConfig = &failoverCfg
+	cfg.PolicyHookConfig = &policyHookCfg
+	cfg.FaucetConfig = &faucetCfg
+	cfg.EventSinkConfig = &eventSinkCfg
+
+	return resolved, nil
+}
@@ -0,0 +1,32 @@
+package stakercfg
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultSystemdSelfCheckTimeout = 30 * time.Second
+)
+
+// SystemdConfig defines integration with systemd service manager. Readiness
+// notifications and watchdog pings are sent only if the daemon is started by
+// systemd with Type=notify and WatchdogSec respectively.
+type SystemdConfig struct {
+	SelfCheck        bool          `long:"selfcheck" description:"before every watchdog ping check that staking event loop is responsive, and stop pinging the watchdog if it is not, so that systemd restarts deadlocked daemon"`
+	SelfCheckTimeout time.Duration `long:"selfchecktimeout" description:"Time in which staking event loop must respond to self check"`
+}
+
+func (cfg *SystemdConfig) Validate() error {
+	if cfg.SelfCheckTimeout <= 0 {
+		return fmt.Errorf("selfchecktimeout must be positive")
+	}
+
+	return nil
+}
+
+func DefaultSystemdConfig() SystemdConfig {
+	return SystemdConfig{
+		SelfCheckTimeout: defaultSystemdSelfCheckTimeout,
+	}
+}
@@ -0,0 +1,38 @@
+package stakercfg
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultTrackingSnapshotInterval = 5 * time.Minute
+	defaultTrackingSnapshotMaxAge   = 24 * time.Hour
+)
+
+// TrackingSnapshotConfig defines periodic snapshot of confirmation tracking
+// state, which lets the daemon resume tracking after restart without querying
+// btc node for every tracked transaction
+type TrackingSnapshotConfig struct {
+	Interval time.Duration `long:"interval" description:"Interval of writing snapshot of confirmation tracking state next to the database. Snapshot is also written on shutdown. 0 disables snapshots"`
+	MaxAge   time.Duration `long:"maxage" description:"Snapshots older than this are ignored on startup and tracking state is derived from btc node"`
+}
+
+func (cfg *TrackingSnapshotConfig) Validate() error {
+	if cfg.Interval < 0 {
+		return fmt.Errorf("interval must be non-negative")
+	}
+
+	if cfg.MaxAge <= 0 {
+		return fmt.Errorf("maxage must be positive")
+	}
+
+	return nil
+}
+
+func DefaultTrackingSnapshotConfig() TrackingSnapshotConfig {
+	return TrackingSnapshotConfig{
+		Interval: defaultTrackingSnapshotInterval,
+		MaxAge:   defaultTrackingSnapshotMaxAge,
+	}
+}
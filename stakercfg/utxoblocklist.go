@@ -0,0 +1,56 @@
+package stakercfg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// UtxoBlocklistConfig defines outputs which must never be used to fund
+// transactions created by the daemon. Entries can be also managed through rpc,
+// entries from config can't be removed that way.
+type UtxoBlocklistConfig struct {
+	Outpoints []string `long:"outpoint" description:"Outpoint in format <txid>:<vout> which must never be spent by the daemon, can be specified multiple times"`
+	Addresses []string `long:"address" description:"Address which outputs must never be spent by the daemon, can be specified multiple times"`
+}
+
+// Parse returns blocked outpoints and addresses
+func (cfg *UtxoBlocklistConfig) Parse(net *chaincfg.Params) ([]wire.OutPoint, []btcutil.Address, error) {
+	outpoints := make([]wire.OutPoint, 0, len(cfg.Outpoints))
+
+	for _, o := range cfg.Outpoints {
+		outpoint, err := wire.NewOutPointFromString(strings.TrimSpace(o))
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid outpoint %s: %w", o, err)
+		}
+
+		outpoints = append(outpoints, *outpoint)
+	}
+
+	addresses := make([]btcutil.Address, 0, len(cfg.Addresses))
+
+	for _, a := range cfg.Addresses {
+		address, err := btcutil.DecodeAddress(strings.TrimSpace(a), net)
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid address %s: %w", a, err)
+		}
+
+		addresses = append(addresses, address)
+	}
+
+	return outpoints, addresses, nil
+}
+
+func (cfg *UtxoBlocklistConfig) Validate(net *chaincfg.Params) error {
+	_, _, err := cfg.Parse(net)
+	return err
+}
+
+func DefaultUtxoBlocklistConfig() UtxoBlocklistConfig {
+	return UtxoBlocklistConfig{}
+}
@@ -0,0 +1,175 @@
+package stakercfg
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+const (
+	descriptorInputCharset    = "0123456789()[],'/*abcdefgh@:$%{}IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+	descriptorChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	descriptorChecksumLength  = 8
+)
+
+var descriptorChecksumGenerator = [5]uint64{0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a, 0x644d626ffd}
+
+// WithdrawalPolicyConfig defines whitelist of destinations to which the daemon
+// is allowed to send withdrawn or consolidated funds
+type WithdrawalPolicyConfig struct {
+	Enabled     bool     `long:"enabled" description:"refuse to create any withdrawal, unbonding destination or consolidation transaction paying to address outside of the whitelist"`
+	Addresses   []string `long:"address" description:"Whitelisted withdrawal address, can be specified multiple times"`
+	Descriptors []string `long:"descriptor" description:"Whitelisted withdrawal output descriptor, either addr(<address>) or raw(<hex script>) with optional #checksum, can be specified multiple times"`
+}
+
+// descriptorChecksum computes checksum of output descriptor as defined in BIP380
+func descriptorChecksum(desc string) (string, error) {
+	polymod := func(chk uint64, value uint64) uint64 {
+		top := chk >> 35
+		chk = (chk&0x7ffffffff)<<5 ^ value
+
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= descriptorChecksumGenerator[i]
+			}
+		}
+
+		return chk
+	}
+
+	var (
+		chk      uint64 = 1
+		cls      uint64
+		clsCount int
+	)
+
+	for _, c := range desc {
+		pos := strings.IndexRune(descriptorInputCharset, c)
+
+		if pos < 0 {
+			return "", fmt.Errorf("invalid character %q in descriptor", c)
+		}
+
+		chk = polymod(chk, uint64(pos&31))
+		cls = cls*3 + uint64(pos>>5)
+		clsCount++
+
+		if clsCount == 3 {
+			chk = polymod(chk, cls)
+			cls = 0
+			clsCount = 0
+		}
+	}
+
+	if clsCount > 0 {
+		chk = polymod(chk, cls)
+	}
+
+	for i := 0; i < descriptorChecksumLength; i++ {
+		chk = polymod(chk, 0)
+	}
+
+	chk ^= 1
+
+	var checksum [descriptorChecksumLength]byte
+	for i := 0; i < descriptorChecksumLength; i++ {
+		checksum[i] = descriptorChecksumCharset[(chk>>(5*(7-i)))&31]
+	}
+
+	return string(checksum[:]), nil
+}
+
+// descriptorPkScript returns output script of addr() or raw() descriptor. Other
+// descriptor types describe whole key ranges and can't be matched against
+// single destination.
+func descriptorPkScript(desc string, net *chaincfg.Params) ([]byte, error) {
+	body, checksum, hasChecksum := strings.Cut(strings.TrimSpace(desc), "#")
+
+	if hasChecksum {
+		expected, err := descriptorChecksum(body)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if checksum != expected {
+			return nil, fmt.Errorf("invalid checksum of descriptor %s, expected %s", desc, expected)
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(body, "addr(") && strings.HasSuffix(body, ")"):
+		address, err := btcutil.DecodeAddress(strings.TrimSuffix(strings.TrimPrefix(body, "addr("), ")"), net)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid address in descriptor %s: %w", desc, err)
+		}
+
+		return txscript.PayToAddrScript(address)
+	case strings.HasPrefix(body, "raw(") && strings.HasSuffix(body, ")"):
+		script, err := hex.DecodeString(strings.TrimSuffix(strings.TrimPrefix(body, "raw("), ")"))
+
+		if err != nil || len(script) == 0 {
+			return nil, fmt.Errorf("invalid script in descriptor %s", desc)
+		}
+
+		return script, nil
+	default:
+		return nil, fmt.Errorf("unsupported descriptor %s, only addr() and raw() descriptors are supported", desc)
+	}
+}
+
+// PkScripts returns output scripts of all whitelisted destinations
+func (cfg *WithdrawalPolicyConfig) PkScripts(net *chaincfg.Params) ([][]byte, error) {
+	scripts := make([][]byte, 0, len(cfg.Addresses)+len(cfg.Descriptors))
+
+	for _, a := range cfg.Addresses {
+		address, err := btcutil.DecodeAddress(strings.TrimSpace(a), net)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %s: %w", a, err)
+		}
+
+		script, err := txscript.PayToAddrScript(address)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %s: %w", a, err)
+		}
+
+		scripts = append(scripts, script)
+	}
+
+	for _, d := range cfg.Descriptors {
+		script, err := descriptorPkScript(d, net)
+
+		if err != nil {
+			return nil, err
+		}
+
+		scripts = append(scripts, script)
+	}
+
+	return scripts, nil
+}
+
+func (cfg *WithdrawalPolicyConfig) Validate(net *chaincfg.Params) error {
+	scripts, err := cfg.PkScripts(net)
+
+	if err != nil {
+		return err
+	}
+
+	if cfg.Enabled && len(scripts) == 0 {
+		return fmt.Errorf("at least one address or descriptor must be whitelisted when withdrawal policy is enabled")
+	}
+
+	return nil
+}
+
+func DefaultWithdrawalPolicyConfig() WithdrawalPolicyConfig {
+	return WithdrawalPolicyConfig{}
+}
@@ -0,0 +1,151 @@
+package stakerdb
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/lightningnetwork/lnd/kvdb"
+	pm "google.golang.org/protobuf/proto"
+)
+
+var (
+	// mapping sequence number -> proto.AuditLogEntry
+	auditLogBucketName = []byte("auditLog")
+)
+
+// AuditLogEntry records manual operator action performed on staking transaction
+type AuditLogEntry struct {
+	Timestamp     time.Time
+	Action        string
+	StakingTxHash chainhash.Hash
+	PreviousState proto.TransactionState
+	NewState      proto.TransactionState
+	Reason        string
+	RequestId     string
+	RemoteAddr    string
+}
+
+// AuditLogStore is append only log of manual operator actions
+type AuditLogStore struct {
+	db kvdb.Backend
+}
+
+// NewAuditLogStore returns a new audit log store backed by db
+func NewAuditLogStore(db kvdb.Backend) (*AuditLogStore, error) {
+	store := &AuditLogStore{db}
+
+	if err := kvdb.Batch(db, func(tx kvdb.RwTx) error {
+		_, err := tx.CreateTopLevelBucket(auditLogBucketName)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func auditLogEntryToProto(e *AuditLogEntry) *proto.AuditLogEntry {
+	return &proto.AuditLogEntry{
+		Timestamp:     e.Timestamp.Unix(),
+		Action:        e.Action,
+		StakingTxHash: e.StakingTxHash.CloneBytes(),
+		PreviousState: e.PreviousState,
+		NewState:      e.NewState,
+		Reason:        e.Reason,
+		RequestId:     e.RequestId,
+		RemoteAddr:    e.RemoteAddr,
+	}
+}
+
+func protoToAuditLogEntry(e *proto.AuditLogEntry) (*AuditLogEntry, error) {
+	txHash, err := chainhash.NewHash(e.StakingTxHash)
+
+	if err != nil {
+		return nil, ErrCorruptedTransactionsDb
+	}
+
+	return &AuditLogEntry{
+		Timestamp:     time.Unix(e.Timestamp, 0),
+		Action:        e.Action,
+		StakingTxHash: *txHash,
+		PreviousState: e.PreviousState,
+		NewState:      e.NewState,
+		Reason:        e.Reason,
+		RequestId:     e.RequestId,
+		RemoteAddr:    e.RemoteAddr,
+	}, nil
+}
+
+// AddEntry appends entry to the log
+func (s *AuditLogStore) AddEntry(e *AuditLogEntry) error {
+	marshalled, err := pm.Marshal(auditLogEntryToProto(e))
+
+	if err != nil {
+		return err
+	}
+
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(auditLogBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		seq, err := bucket.NextSequence()
+
+		if err != nil {
+			return err
+		}
+
+		// big endian keys keep entries sorted by insertion order
+		var key [8]byte
+		binary.BigEndian.PutUint64(key[:], seq)
+
+		return bucket.Put(key[:], marshalled)
+	})
+}
+
+// Entries returns all entries in insertion order. If stakingTxHash is provided,
+// only entries of this transaction are returned.
+func (s *AuditLogStore) Entries(stakingTxHash *chainhash.Hash) ([]AuditLogEntry, error) {
+	var entries []AuditLogEntry
+
+	err := s.db.View(func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(auditLogBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var entryProto proto.AuditLogEntry
+
+			if err := pm.Unmarshal(v, &entryProto); err != nil {
+				return ErrCorruptedTransactionsDb
+			}
+
+			e, err := protoToAuditLogEntry(&entryProto)
+
+			if err != nil {
+				return err
+			}
+
+			if stakingTxHash != nil && !e.StakingTxHash.IsEqual(stakingTxHash) {
+				return nil
+			}
+
+			entries = append(entries, *e)
+			return nil
+		})
+	}, func() {
+		entries = nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
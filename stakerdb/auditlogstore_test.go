@@ -0,0 +1,77 @@
+package stakerdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/stretchr/testify/require"
+)
+
+func MakeTestAuditLogStore(t *testing.T) *stakerdb.AuditLogStore {
+	cfg := stakercfg.DefaultDBConfig()
+
+	cfg.DBPath = t.TempDir()
+
+	backend, err := stakercfg.GetDbBackend(&cfg)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		backend.Close()
+	})
+
+	store, err := stakerdb.NewAuditLogStore(backend)
+	require.NoError(t, err)
+
+	return store
+}
+
+func TestAuditLogStore(t *testing.T) {
+	s := MakeTestAuditLogStore(t)
+
+	entries, err := s.Entries(nil)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	txHash1 := chainhash.HashH([]byte("staking tx 1"))
+	txHash2 := chainhash.HashH([]byte("staking tx 2"))
+	now := time.Unix(time.Now().Unix(), 0)
+
+	// more than 255 entries to check entries are kept in insertion order
+	var added []stakerdb.AuditLogEntry
+	for i := 0; i < 300; i++ {
+		txHash := txHash1
+		if i%2 == 1 {
+			txHash = txHash2
+		}
+
+		e := stakerdb.AuditLogEntry{
+			Timestamp:     now.Add(time.Duration(i) * time.Second),
+			Action:        "override_delegation_state",
+			StakingTxHash: txHash,
+			PreviousState: proto.TransactionState_SENT_TO_BABYLON,
+			NewState:      proto.TransactionState_CONFIRMED_ON_BTC,
+			Reason:        "babylon chain reset",
+			RequestId:     "request",
+			RemoteAddr:    "127.0.0.1:1234",
+		}
+
+		require.NoError(t, s.AddEntry(&e))
+		added = append(added, e)
+	}
+
+	entries, err = s.Entries(nil)
+	require.NoError(t, err)
+	require.Equal(t, added, entries)
+
+	entries, err = s.Entries(&txHash2)
+	require.NoError(t, err)
+	require.Len(t, entries, 150)
+
+	for _, e := range entries {
+		require.Equal(t, txHash2, e.StakingTxHash)
+	}
+}
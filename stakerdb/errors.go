@@ -18,4 +18,41 @@ var (
 	ErrInvalidUnbondingDataUpdate = errors.New("invalid unbonding data update")
 
 	ErrUnbondingDataNotFound = errors.New("unbonding transaction data not found")
+
+	// ErrRetryQueueEntryNotFound given operation is not scheduled for retry
+	ErrRetryQueueEntryNotFound = errors.New("retry queue entry not found")
+
+	// ErrUnexpectedTransactionState transaction is not in the state expected by
+	// the update
+	ErrUnexpectedTransactionState = errors.New("transaction is not in expected state")
+
+	// ErrUtxoBlocklistEntryNotFound given outpoint or address is not blocklisted
+	ErrUtxoBlocklistEntryNotFound = errors.New("utxo blocklist entry not found")
+
+	// ErrOutputNotFrozen given output is not frozen
+	ErrOutputNotFrozen = errors.New("output is not frozen")
+
+	// ErrScheduledOperationNotFound given operation is not scheduled
+	ErrScheduledOperationNotFound = errors.New("scheduled operation not found")
+
+	// ErrMuSig2NonceNotFound no nonce was generated for given session
+	ErrMuSig2NonceNotFound = errors.New("musig2 nonce not found")
+
+	// ErrDuplicateMuSig2Session nonce was already generated for given session
+	ErrDuplicateMuSig2Session = errors.New("musig2 session already exists")
+
+	// ErrMuSig2NonceUsed secret nonce of the session was already used
+	ErrMuSig2NonceUsed = errors.New("musig2 nonce was already used")
+
+	// ErrMuSig2NonceExpired nonce of the session expired
+	ErrMuSig2NonceExpired = errors.New("musig2 nonce expired")
+
+	// ErrStakingPresetNotFound preset with given name does not exist
+	ErrStakingPresetNotFound = errors.New("staking preset not found")
+
+	// ErrFeeSpendNotFound no fee was recorded for given transaction
+	ErrFeeSpendNotFound = errors.New("fee spend not found")
+
+	// ErrTooManyDelegationNotes delegation reached maximum number of notes
+	ErrTooManyDelegationNotes = errors.New("too many delegation notes")
 )
@@ -0,0 +1,336 @@
+package stakerdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/lightningnetwork/lnd/kvdb"
+	pm "google.golang.org/protobuf/proto"
+)
+
+var (
+	// mapping timestamp || sequence number -> proto.FeeSpendEntry
+	feeSpendsBucketName = []byte("feeSpends")
+)
+
+// FeeSpendEntry records btc fee paid by transaction sent by the daemon
+type FeeSpendEntry struct {
+	Timestamp time.Time
+	Kind      string
+	TxHash    chainhash.Hash
+	Fee       btcutil.Amount
+	// 0 for entries recorded before virtual size was tracked
+	VSize              int64
+	SentHeight         uint32
+	ConfirmationHeight uint32
+	// zero if first confirmation was not observed by the daemon
+	ConfirmedAt time.Time
+}
+
+// FeeRate returns fee rate of the transaction in sat/vbyte, 0 if its virtual
+// size is unknown
+func (e *FeeSpendEntry) FeeRate() float64 {
+	if e.VSize == 0 {
+		return 0
+	}
+
+	return float64(e.Fee) / float64(e.VSize)
+}
+
+// ConfirmationBlocks returns number of blocks it took to confirm transaction,
+// 1 means it was included in the first block after it was sent. Returns false
+// if confirmation was not observed.
+func (e *FeeSpendEntry) ConfirmationBlocks() (uint32, bool) {
+	if e.ConfirmationHeight == 0 || e.ConfirmationHeight <= e.SentHeight {
+		return 0, e.ConfirmationHeight != 0
+	}
+
+	return e.ConfirmationHeight - e.SentHeight, true
+}
+
+// TimeToFirstConfirmation returns time between sending transaction and
+// observing its first confirmation. Returns false if first confirmation was not
+// observed.
+func (e *FeeSpendEntry) TimeToFirstConfirmation() (time.Duration, bool) {
+	if e.ConfirmedAt.IsZero() {
+		return 0, false
+	}
+
+	if e.ConfirmedAt.Before(e.Timestamp) {
+		return 0, true
+	}
+
+	return e.ConfirmedAt.Sub(e.Timestamp), true
+}
+
+func feeSpendEntryToProto(e *FeeSpendEntry) *proto.FeeSpendEntry {
+	var confirmedAt int64
+	if !e.ConfirmedAt.IsZero() {
+		confirmedAt = e.ConfirmedAt.Unix()
+	}
+
+	return &proto.FeeSpendEntry{
+		Timestamp:          e.Timestamp.Unix(),
+		Kind:               e.Kind,
+		TxHash:             e.TxHash.CloneBytes(),
+		Fee:                int64(e.Fee),
+		Vsize:              e.VSize,
+		SentHeight:         e.SentHeight,
+		ConfirmationHeight: e.ConfirmationHeight,
+		ConfirmedAt:        confirmedAt,
+	}
+}
+
+func feeSpendEntryFromProto(entryProto *proto.FeeSpendEntry) (*FeeSpendEntry, error) {
+	txHash, err := chainhash.NewHash(entryProto.TxHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var confirmedAt time.Time
+	if entryProto.ConfirmedAt != 0 {
+		confirmedAt = time.Unix(entryProto.ConfirmedAt, 0)
+	}
+
+	return &FeeSpendEntry{
+		Timestamp:          time.Unix(entryProto.Timestamp, 0),
+		Kind:               entryProto.Kind,
+		TxHash:             *txHash,
+		Fee:                btcutil.Amount(entryProto.Fee),
+		VSize:              entryProto.Vsize,
+		SentHeight:         entryProto.SentHeight,
+		ConfirmationHeight: entryProto.ConfirmationHeight,
+		ConfirmedAt:        confirmedAt,
+	}, nil
+}
+
+// FeeSpendStore keeps fees paid by the daemon, ordered by time of the payment
+type FeeSpendStore struct {
+	db kvdb.Backend
+}
+
+// NewFeeSpendStore returns a new fee spend store backed by db
+func NewFeeSpendStore(db kvdb.Backend) (*FeeSpendStore, error) {
+	store := &FeeSpendStore{db}
+
+	if err := kvdb.Batch(db, func(tx kvdb.RwTx) error {
+		_, err := tx.CreateTopLevelBucket(feeSpendsBucketName)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// timestamp prefix keeps entries sorted by time, sequence number keeps keys of
+// entries with the same timestamp unique
+func feeSpendKey(timestamp time.Time, seq uint64) []byte {
+	var key [16]byte
+	binary.BigEndian.PutUint64(key[:8], uint64(timestamp.Unix()))
+	binary.BigEndian.PutUint64(key[8:], seq)
+	return key[:]
+}
+
+// AddSpend records fee paid by the daemon
+func (s *FeeSpendStore) AddSpend(e *FeeSpendEntry) error {
+	marshalled, err := pm.Marshal(feeSpendEntryToProto(e))
+
+	if err != nil {
+		return err
+	}
+
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(feeSpendsBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		seq, err := bucket.NextSequence()
+
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(feeSpendKey(e.Timestamp, seq), marshalled)
+	})
+}
+
+// SpendsSince returns all fees paid at or after since, ordered by time
+func (s *FeeSpendStore) SpendsSince(since time.Time) ([]FeeSpendEntry, error) {
+	var entries []FeeSpendEntry
+
+	err := s.db.View(func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(feeSpendsBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		cursor := bucket.ReadCursor()
+
+		for k, v := cursor.Seek(feeSpendKey(since, 0)); k != nil; k, v = cursor.Next() {
+			var entryProto proto.FeeSpendEntry
+
+			if err := pm.Unmarshal(v, &entryProto); err != nil {
+				return ErrCorruptedTransactionsDb
+			}
+
+			entry, err := feeSpendEntryFromProto(&entryProto)
+
+			if err != nil {
+				return ErrCorruptedTransactionsDb
+			}
+
+			entries = append(entries, *entry)
+		}
+
+		return nil
+	}, func() {
+		entries = nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// SetConfirmation records height of the block which included transaction with
+// given hash, and time when the daemon observed it. Zero confirmedAt keeps
+// previously recorded time. Transactions are usually confirmed soon after they
+// are sent, so entries are searched from the newest one. Returns updated entry,
+// or ErrFeeSpendNotFound if there is no entry of the transaction.
+func (s *FeeSpendStore) SetConfirmation(
+	txHash chainhash.Hash,
+	height uint32,
+	confirmedAt time.Time,
+) (*FeeSpendEntry, error) {
+	var updated *FeeSpendEntry
+
+	err := kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(feeSpendsBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		cursor := bucket.ReadWriteCursor()
+
+		for k, v := cursor.Last(); k != nil; k, v = cursor.Prev() {
+			var entryProto proto.FeeSpendEntry
+
+			if err := pm.Unmarshal(v, &entryProto); err != nil {
+				return ErrCorruptedTransactionsDb
+			}
+
+			if !bytes.Equal(entryProto.TxHash, txHash[:]) {
+				continue
+			}
+
+			entryProto.ConfirmationHeight = height
+
+			if !confirmedAt.IsZero() {
+				entryProto.ConfirmedAt = confirmedAt.Unix()
+			}
+
+			entry, err := feeSpendEntryFromProto(&entryProto)
+
+			if err != nil {
+				return ErrCorruptedTransactionsDb
+			}
+
+			marshalled, err := pm.Marshal(&entryProto)
+
+			if err != nil {
+				return err
+			}
+
+			updated = entry
+			return bucket.Put(append([]byte(nil), k...), marshalled)
+		}
+
+		return ErrFeeSpendNotFound
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// PruneBefore removes entries of fees paid before given time
+func (s *FeeSpendStore) PruneBefore(before time.Time) error {
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(feeSpendsBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		end := feeSpendKey(before, 0)
+
+		var toDelete [][]byte
+		cursor := bucket.ReadCursor()
+
+		for k, _ := cursor.First(); k != nil && bytes.Compare(k, end) < 0; k, _ = cursor.Next() {
+			toDelete = append(toDelete, append([]byte(nil), k...))
+		}
+
+		for _, k := range toDelete {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// deleteFeeSpendsOfTxs removes entries of fees paid by transactions with given
+// hashes, as part of rwTx
+func deleteFeeSpendsOfTxs(rwTx kvdb.RwTx, txHashes [][]byte) error {
+	bucket := rwTx.ReadWriteBucket(feeSpendsBucketName)
+
+	if bucket == nil {
+		// store was never created, so there is nothing to delete
+		return nil
+	}
+
+	var toDelete [][]byte
+	err := bucket.ForEach(func(k, v []byte) error {
+		var entryProto proto.FeeSpendEntry
+
+		if err := pm.Unmarshal(v, &entryProto); err != nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		for _, txHash := range txHashes {
+			if bytes.Equal(entryProto.TxHash, txHash) {
+				toDelete = append(toDelete, append([]byte(nil), k...))
+				break
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, k := range toDelete {
+		if err := bucket.Delete(k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
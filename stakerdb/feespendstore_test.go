@@ -0,0 +1,123 @@
+package stakerdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/stretchr/testify/require"
+)
+
+func MakeTestFeeSpendStore(t *testing.T) *stakerdb.FeeSpendStore {
+	cfg := stakercfg.DefaultDBConfig()
+
+	cfg.DBPath = t.TempDir()
+
+	backend, err := stakercfg.GetDbBackend(&cfg)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		backend.Close()
+	})
+
+	store, err := stakerdb.NewFeeSpendStore(backend)
+	require.NoError(t, err)
+
+	return store
+}
+
+func TestFeeSpendStore(t *testing.T) {
+	s := MakeTestFeeSpendStore(t)
+
+	now := time.Unix(time.Now().Unix(), 0)
+
+	entries, err := s.SpendsSince(now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	// two entries per hour, over 10 hours
+	var added []stakerdb.FeeSpendEntry
+	for i := 0; i < 20; i++ {
+		e := stakerdb.FeeSpendEntry{
+			Timestamp: now.Add(-time.Duration(10-i/2) * time.Hour),
+			Kind:      "staking",
+			TxHash:    chainhash.HashH([]byte{byte(i)}),
+			Fee:       btcutil.Amount(1000 + i),
+		}
+
+		require.NoError(t, s.AddSpend(&e))
+		added = append(added, e)
+	}
+
+	entries, err = s.SpendsSince(now.Add(-24 * time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, added, entries)
+
+	entries, err = s.SpendsSince(now.Add(-5 * time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, added[10:], entries)
+
+	require.NoError(t, s.PruneBefore(now.Add(-8*time.Hour)))
+
+	entries, err = s.SpendsSince(now.Add(-24 * time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, added[4:], entries)
+}
+
+func TestFeeSpendStoreConfirmationHeight(t *testing.T) {
+	s := MakeTestFeeSpendStore(t)
+
+	now := time.Unix(time.Now().Unix(), 0)
+
+	var added []stakerdb.FeeSpendEntry
+	for i := 0; i < 5; i++ {
+		e := stakerdb.FeeSpendEntry{
+			Timestamp:  now.Add(-time.Duration(5-i) * time.Hour),
+			Kind:       "unbonding",
+			TxHash:     chainhash.HashH([]byte{byte(i)}),
+			Fee:        btcutil.Amount(2000),
+			VSize:      200,
+			SentHeight: uint32(100 + i),
+		}
+
+		require.NoError(t, s.AddSpend(&e))
+		added = append(added, e)
+	}
+
+	require.Equal(t, float64(10), added[0].FeeRate())
+
+	_, confirmed := added[1].ConfirmationBlocks()
+	require.False(t, confirmed)
+
+	confirmedAt := added[1].Timestamp.Add(20 * time.Minute)
+	updated, err := s.SetConfirmation(added[1].TxHash, 103, confirmedAt)
+	require.NoError(t, err)
+	added[1].ConfirmationHeight = 103
+	added[1].ConfirmedAt = confirmedAt
+	require.Equal(t, added[1], *updated)
+
+	// zero time keeps observed confirmation time
+	_, err = s.SetConfirmation(added[1].TxHash, 103, time.Time{})
+	require.NoError(t, err)
+
+	_, err = s.SetConfirmation(chainhash.HashH([]byte("unknown")), 103, time.Time{})
+	require.ErrorIs(t, err, stakerdb.ErrFeeSpendNotFound)
+
+	entries, err := s.SpendsSince(now.Add(-24 * time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, added, entries)
+
+	blocks, confirmed := entries[1].ConfirmationBlocks()
+	require.True(t, confirmed)
+	require.Equal(t, uint32(2), blocks)
+
+	ttfc, observed := entries[1].TimeToFirstConfirmation()
+	require.True(t, observed)
+	require.Equal(t, 20*time.Minute, ttfc)
+
+	_, observed = entries[2].TimeToFirstConfirmation()
+	require.False(t, observed)
+}
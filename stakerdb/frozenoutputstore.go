@@ -0,0 +1,163 @@
+package stakerdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/kvdb"
+	pm "google.golang.org/protobuf/proto"
+)
+
+var (
+	// mapping outpoint -> proto.FrozenOutputEntry
+	frozenOutputsBucketName = []byte("frozenOutputs")
+)
+
+// FrozenOutput is wallet output reserved by the operator for non staking
+// purposes
+type FrozenOutput struct {
+	Outpoint  wire.OutPoint
+	Timestamp time.Time
+	Note      string
+}
+
+// FrozenOutputStore keeps wallet outputs which must not be used to fund
+// transactions of the daemon
+type FrozenOutputStore struct {
+	db kvdb.Backend
+}
+
+// NewFrozenOutputStore returns a new frozen output store backed by db
+func NewFrozenOutputStore(db kvdb.Backend) (*FrozenOutputStore, error) {
+	store := &FrozenOutputStore{db}
+
+	if err := kvdb.Batch(db, func(tx kvdb.RwTx) error {
+		_, err := tx.CreateTopLevelBucket(frozenOutputsBucketName)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func outpointKey(o *wire.OutPoint) []byte {
+	key := make([]byte, chainhash.HashSize+4)
+	copy(key, o.Hash[:])
+	binary.BigEndian.PutUint32(key[chainhash.HashSize:], o.Index)
+	return key
+}
+
+// Freeze marks output as frozen, freezing already frozen output updates its note
+func (s *FrozenOutputStore) Freeze(o *FrozenOutput) error {
+	marshalled, err := pm.Marshal(&proto.FrozenOutputEntry{
+		Timestamp: o.Timestamp.Unix(),
+		Note:      o.Note,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(frozenOutputsBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		return bucket.Put(outpointKey(&o.Outpoint), marshalled)
+	})
+}
+
+// Unfreeze makes output available again
+func (s *FrozenOutputStore) Unfreeze(outpoint *wire.OutPoint) error {
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(frozenOutputsBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		key := outpointKey(outpoint)
+
+		if bucket.Get(key) == nil {
+			return ErrOutputNotFrozen
+		}
+
+		return bucket.Delete(key)
+	})
+}
+
+// FrozenOutputs returns all frozen outputs
+func (s *FrozenOutputStore) FrozenOutputs() ([]FrozenOutput, error) {
+	var outputs []FrozenOutput
+
+	err := s.db.View(func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(frozenOutputsBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			if len(k) != chainhash.HashSize+4 {
+				return ErrCorruptedTransactionsDb
+			}
+
+			var entryProto proto.FrozenOutputEntry
+
+			if err := pm.Unmarshal(v, &entryProto); err != nil {
+				return ErrCorruptedTransactionsDb
+			}
+
+			var hash chainhash.Hash
+			copy(hash[:], k[:chainhash.HashSize])
+
+			outputs = append(outputs, FrozenOutput{
+				Outpoint:  *wire.NewOutPoint(&hash, binary.BigEndian.Uint32(k[chainhash.HashSize:])),
+				Timestamp: time.Unix(entryProto.Timestamp, 0),
+				Note:      entryProto.Note,
+			})
+			return nil
+		})
+	}, func() {
+		outputs = nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return outputs, nil
+}
+
+// deleteFrozenOutputsOfTx unfreezes all outputs of transaction with given hash,
+// as part of rwTx
+func deleteFrozenOutputsOfTx(rwTx kvdb.RwTx, txHash []byte) error {
+	bucket := rwTx.ReadWriteBucket(frozenOutputsBucketName)
+
+	if bucket == nil {
+		// store was never created, so there is nothing to delete
+		return nil
+	}
+
+	var toDelete [][]byte
+	cursor := bucket.ReadCursor()
+
+	for k, _ := cursor.Seek(txHash); k != nil && bytes.HasPrefix(k, txHash); k, _ = cursor.Next() {
+		toDelete = append(toDelete, append([]byte(nil), k...))
+	}
+
+	for _, k := range toDelete {
+		if err := bucket.Delete(k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package stakerdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func MakeTestFrozenOutputStore(t *testing.T) *stakerdb.FrozenOutputStore {
+	cfg := stakercfg.DefaultDBConfig()
+
+	cfg.DBPath = t.TempDir()
+
+	backend, err := stakercfg.GetDbBackend(&cfg)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		backend.Close()
+	})
+
+	store, err := stakerdb.NewFrozenOutputStore(backend)
+	require.NoError(t, err)
+
+	return store
+}
+
+func TestFrozenOutputStore(t *testing.T) {
+	s := MakeTestFrozenOutputStore(t)
+
+	outputs, err := s.FrozenOutputs()
+	require.NoError(t, err)
+	require.Empty(t, outputs)
+
+	now := time.Unix(time.Now().Unix(), 0)
+	txHash := chainhash.HashH([]byte("funding tx"))
+
+	o1 := stakerdb.FrozenOutput{
+		Outpoint:  *wire.NewOutPoint(&txHash, 0),
+		Timestamp: now,
+		Note:      "reserved for payroll",
+	}
+	o2 := stakerdb.FrozenOutput{
+		Outpoint:  *wire.NewOutPoint(&txHash, 1),
+		Timestamp: now,
+	}
+
+	require.NoError(t, s.Freeze(&o1))
+	require.NoError(t, s.Freeze(&o2))
+
+	// freezing again updates the note
+	o2.Note = "cold storage top up"
+	require.NoError(t, s.Freeze(&o2))
+
+	outputs, err = s.FrozenOutputs()
+	require.NoError(t, err)
+	require.Equal(t, []stakerdb.FrozenOutput{o1, o2}, outputs)
+
+	require.NoError(t, s.Unfreeze(&o1.Outpoint))
+	require.ErrorIs(t, s.Unfreeze(&o1.Outpoint), stakerdb.ErrOutputNotFrozen)
+
+	outputs, err = s.FrozenOutputs()
+	require.NoError(t, err)
+	require.Equal(t, []stakerdb.FrozenOutput{o2}, outputs)
+}
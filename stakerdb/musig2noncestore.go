@@ -0,0 +1,265 @@
+package stakerdb
+
+import (
+	"strings"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/lightningnetwork/lnd/kvdb"
+	pm "google.golang.org/protobuf/proto"
+)
+
+var (
+	// mapping session id -> proto.MuSig2NonceEntry
+	musig2NoncesBucketName = []byte("musig2Nonces")
+)
+
+// MuSig2Nonce is nonce pair generated for one MuSig2 signing session
+type MuSig2Nonce struct {
+	SessionId string
+	SignerPk  []byte
+	PubNonce  []byte
+	SecNonce  []byte
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	Used      bool
+}
+
+func (n *MuSig2Nonce) Expired(now time.Time) bool {
+	return !now.Before(n.ExpiresAt)
+}
+
+// MuSig2NonceStore keeps nonces of MuSig2 signing sessions, so that secret
+// nonce survives daemon restarts and is never used twice
+type MuSig2NonceStore struct {
+	db kvdb.Backend
+}
+
+// NewMuSig2NonceStore returns a new MuSig2 nonce store backed by db
+func NewMuSig2NonceStore(db kvdb.Backend) (*MuSig2NonceStore, error) {
+	store := &MuSig2NonceStore{db}
+
+	if err := kvdb.Batch(db, func(tx kvdb.RwTx) error {
+		_, err := tx.CreateTopLevelBucket(musig2NoncesBucketName)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func musig2NonceFromProto(sessionId string, entry *proto.MuSig2NonceEntry) *MuSig2Nonce {
+	return &MuSig2Nonce{
+		SessionId: sessionId,
+		SignerPk:  entry.SignerPk,
+		PubNonce:  entry.PubNonce,
+		SecNonce:  entry.SecNonce,
+		CreatedAt: time.Unix(entry.CreatedAt, 0),
+		ExpiresAt: time.Unix(entry.ExpiresAt, 0),
+		Used:      entry.Used,
+	}
+}
+
+func getMuSig2Nonce(bucket kvdb.RBucket, sessionId string) (*MuSig2Nonce, error) {
+	v := bucket.Get([]byte(sessionId))
+
+	if v == nil {
+		return nil, ErrMuSig2NonceNotFound
+	}
+
+	var entry proto.MuSig2NonceEntry
+	if err := pm.Unmarshal(v, &entry); err != nil {
+		return nil, ErrCorruptedTransactionsDb
+	}
+
+	return musig2NonceFromProto(sessionId, &entry), nil
+}
+
+func putMuSig2Nonce(bucket kvdb.RwBucket, n *MuSig2Nonce) error {
+	marshalled, err := pm.Marshal(&proto.MuSig2NonceEntry{
+		SignerPk:  n.SignerPk,
+		PubNonce:  n.PubNonce,
+		SecNonce:  n.SecNonce,
+		CreatedAt: n.CreatedAt.Unix(),
+		ExpiresAt: n.ExpiresAt.Unix(),
+		Used:      n.Used,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return bucket.Put([]byte(n.SessionId), marshalled)
+}
+
+// Add stores nonce of new session. Session ids can't be reused, even after
+// nonce of the session expired.
+func (s *MuSig2NonceStore) Add(n *MuSig2Nonce) error {
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(musig2NoncesBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		if bucket.Get([]byte(n.SessionId)) != nil {
+			return ErrDuplicateMuSig2Session
+		}
+
+		return putMuSig2Nonce(bucket, n)
+	})
+}
+
+// Get returns nonce of the session
+func (s *MuSig2NonceStore) Get(sessionId string) (*MuSig2Nonce, error) {
+	var nonce *MuSig2Nonce
+
+	err := s.db.View(func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(musig2NoncesBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		n, err := getMuSig2Nonce(bucket, sessionId)
+
+		if err != nil {
+			return err
+		}
+
+		nonce = n
+		return nil
+	}, func() {
+		nonce = nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return nonce, nil
+}
+
+// Use marks nonce of the session as used and returns it together with its
+// secret nonce. Only one call succeeds for every session, and only until the
+// nonce expires. Secret nonce is erased from db once it is used.
+func (s *MuSig2NonceStore) Use(sessionId string, now time.Time) (*MuSig2Nonce, error) {
+	var nonce *MuSig2Nonce
+
+	err := kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(musig2NoncesBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		n, err := getMuSig2Nonce(bucket, sessionId)
+
+		if err != nil {
+			return err
+		}
+
+		if n.Used {
+			return ErrMuSig2NonceUsed
+		}
+
+		if n.Expired(now) {
+			return ErrMuSig2NonceExpired
+		}
+
+		nonce = &MuSig2Nonce{}
+		*nonce = *n
+
+		n.Used = true
+		n.SecNonce = nil
+		return putMuSig2Nonce(bucket, n)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return nonce, nil
+}
+
+// PruneExpired erases secret nonces of sessions which expired before now. Public
+// data of sessions are kept, so that their ids can't be reused.
+func (s *MuSig2NonceStore) PruneExpired(now time.Time) (int, error) {
+	var pruned int
+
+	err := kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(musig2NoncesBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		var expired []*MuSig2Nonce
+		err := bucket.ForEach(func(k, v []byte) error {
+			var entry proto.MuSig2NonceEntry
+			if err := pm.Unmarshal(v, &entry); err != nil {
+				return ErrCorruptedTransactionsDb
+			}
+
+			n := musig2NonceFromProto(string(k), &entry)
+
+			if len(n.SecNonce) > 0 && n.Expired(now) {
+				expired = append(expired, n)
+			}
+			return nil
+		})
+
+		if err != nil {
+			return err
+		}
+
+		for _, n := range expired {
+			n.SecNonce = nil
+			if err := putMuSig2Nonce(bucket, n); err != nil {
+				return err
+			}
+		}
+
+		pruned = len(expired)
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return pruned, nil
+}
+
+// deleteMuSig2NoncesOfTx removes sessions whose id contains hex encoded hash of
+// the transaction, as part of rwTx. Sessions are not linked to transactions in
+// any other way.
+func deleteMuSig2NoncesOfTx(rwTx kvdb.RwTx, txHash string) error {
+	bucket := rwTx.ReadWriteBucket(musig2NoncesBucketName)
+
+	if bucket == nil {
+		// store was never created, so there is nothing to delete
+		return nil
+	}
+
+	var toDelete [][]byte
+	err := bucket.ForEach(func(k, _ []byte) error {
+		if strings.Contains(strings.ToLower(string(k)), txHash) {
+			toDelete = append(toDelete, append([]byte(nil), k...))
+		}
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, k := range toDelete {
+		if err := bucket.Delete(k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
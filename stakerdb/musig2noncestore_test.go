@@ -0,0 +1,92 @@
+package stakerdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/stretchr/testify/require"
+)
+
+func MakeTestMuSig2NonceStore(t *testing.T) *stakerdb.MuSig2NonceStore {
+	cfg := stakercfg.DefaultDBConfig()
+
+	cfg.DBPath = t.TempDir()
+
+	backend, err := stakercfg.GetDbBackend(&cfg)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		backend.Close()
+	})
+
+	store, err := stakerdb.NewMuSig2NonceStore(backend)
+	require.NoError(t, err)
+
+	return store
+}
+
+func TestMuSig2NonceStore(t *testing.T) {
+	s := MakeTestMuSig2NonceStore(t)
+
+	now := time.Unix(time.Now().Unix(), 0)
+
+	n1 := stakerdb.MuSig2Nonce{
+		SessionId: "session-1",
+		SignerPk:  []byte{2, 1},
+		PubNonce:  []byte{3, 1},
+		SecNonce:  []byte{4, 1},
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Hour),
+	}
+	n2 := stakerdb.MuSig2Nonce{
+		SessionId: "session-2",
+		SignerPk:  []byte{2, 2},
+		PubNonce:  []byte{3, 2},
+		SecNonce:  []byte{4, 2},
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Minute),
+	}
+
+	_, err := s.Get(n1.SessionId)
+	require.ErrorIs(t, err, stakerdb.ErrMuSig2NonceNotFound)
+
+	require.NoError(t, s.Add(&n1))
+	require.NoError(t, s.Add(&n2))
+	require.ErrorIs(t, s.Add(&n1), stakerdb.ErrDuplicateMuSig2Session)
+
+	stored, err := s.Get(n1.SessionId)
+	require.NoError(t, err)
+	require.Equal(t, n1, *stored)
+
+	// nonce can be used only once
+	used, err := s.Use(n1.SessionId, now)
+	require.NoError(t, err)
+	require.Equal(t, n1, *used)
+
+	_, err = s.Use(n1.SessionId, now)
+	require.ErrorIs(t, err, stakerdb.ErrMuSig2NonceUsed)
+
+	stored, err = s.Get(n1.SessionId)
+	require.NoError(t, err)
+	require.True(t, stored.Used)
+	require.Empty(t, stored.SecNonce)
+
+	// expired nonce can't be used and its secret is pruned
+	later := now.Add(2 * time.Minute)
+	_, err = s.Use(n2.SessionId, later)
+	require.ErrorIs(t, err, stakerdb.ErrMuSig2NonceExpired)
+
+	pruned, err := s.PruneExpired(later)
+	require.NoError(t, err)
+	require.Equal(t, 1, pruned)
+
+	stored, err = s.Get(n2.SessionId)
+	require.NoError(t, err)
+	require.False(t, stored.Used)
+	require.Empty(t, stored.SecNonce)
+
+	// expired session id still can't be reused
+	require.ErrorIs(t, s.Add(&n2), stakerdb.ErrDuplicateMuSig2Session)
+}
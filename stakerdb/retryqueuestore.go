@@ -0,0 +1,225 @@
+package stakerdb
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/lightningnetwork/lnd/kvdb"
+	pm "google.golang.org/protobuf/proto"
+)
+
+var (
+	// mapping operation || txHash -> proto.RetryQueueEntry
+	retryQueueBucketName = []byte("retryQueue")
+)
+
+// RetryQueueEntry is failed operation scheduled to be retried
+type RetryQueueEntry struct {
+	Operation     proto.RetryOperation
+	StakingTxHash chainhash.Hash
+	// Number of failed attempts so far
+	Attempts    uint32
+	NextAttempt time.Time
+	LastError   string
+	// Time at which operation failed for the first time
+	CreatedAt time.Time
+}
+
+// RetryQueueStore persists operations which need to be retried, so that they
+// survive restarts
+type RetryQueueStore struct {
+	db kvdb.Backend
+}
+
+// NewRetryQueueStore returns a new retry queue store backed by db
+func NewRetryQueueStore(db kvdb.Backend) (*RetryQueueStore, error) {
+	store := &RetryQueueStore{db}
+
+	if err := kvdb.Batch(db, func(tx kvdb.RwTx) error {
+		_, err := tx.CreateTopLevelBucket(retryQueueBucketName)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func retryQueueKey(op proto.RetryOperation, txHash *chainhash.Hash) []byte {
+	key := make([]byte, 0, 1+chainhash.HashSize)
+	key = append(key, byte(op))
+	return append(key, txHash.CloneBytes()...)
+}
+
+func retryQueueEntryToProto(e *RetryQueueEntry) *proto.RetryQueueEntry {
+	return &proto.RetryQueueEntry{
+		Operation:       e.Operation,
+		StakingTxHash:   e.StakingTxHash.CloneBytes(),
+		Attempts:        e.Attempts,
+		NextAttemptTime: e.NextAttempt.Unix(),
+		LastError:       e.LastError,
+		CreatedAt:       e.CreatedAt.Unix(),
+	}
+}
+
+func protoToRetryQueueEntry(e *proto.RetryQueueEntry) (*RetryQueueEntry, error) {
+	txHash, err := chainhash.NewHash(e.StakingTxHash)
+
+	if err != nil {
+		return nil, ErrCorruptedTransactionsDb
+	}
+
+	return &RetryQueueEntry{
+		Operation:     e.Operation,
+		StakingTxHash: *txHash,
+		Attempts:      e.Attempts,
+		NextAttempt:   time.Unix(e.NextAttemptTime, 0),
+		LastError:     e.LastError,
+		CreatedAt:     time.Unix(e.CreatedAt, 0),
+	}, nil
+}
+
+// PutEntry adds entry to the queue or replaces existing entry for the same
+// operation and staking transaction
+func (s *RetryQueueStore) PutEntry(e *RetryQueueEntry) error {
+	marshalled, err := pm.Marshal(retryQueueEntryToProto(e))
+
+	if err != nil {
+		return err
+	}
+
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(retryQueueBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		return bucket.Put(retryQueueKey(e.Operation, &e.StakingTxHash), marshalled)
+	})
+}
+
+// GetEntry returns entry for given operation and staking transaction
+func (s *RetryQueueStore) GetEntry(op proto.RetryOperation, txHash *chainhash.Hash) (*RetryQueueEntry, error) {
+	var entry *RetryQueueEntry
+
+	err := s.db.View(func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(retryQueueBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		v := bucket.Get(retryQueueKey(op, txHash))
+
+		if v == nil {
+			return ErrRetryQueueEntryNotFound
+		}
+
+		var entryProto proto.RetryQueueEntry
+
+		if err := pm.Unmarshal(v, &entryProto); err != nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		e, err := protoToRetryQueueEntry(&entryProto)
+
+		if err != nil {
+			return err
+		}
+
+		entry = e
+		return nil
+	}, func() {})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// DeleteEntry removes entry from the queue. It is not an error to delete
+// entry which does not exist
+func (s *RetryQueueStore) DeleteEntry(op proto.RetryOperation, txHash *chainhash.Hash) error {
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(retryQueueBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		return bucket.Delete(retryQueueKey(op, txHash))
+	})
+}
+
+// Entries returns all entries in the queue
+func (s *RetryQueueStore) Entries() ([]RetryQueueEntry, error) {
+	var entries []RetryQueueEntry
+
+	err := s.db.View(func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(retryQueueBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var entryProto proto.RetryQueueEntry
+
+			if err := pm.Unmarshal(v, &entryProto); err != nil {
+				return ErrCorruptedTransactionsDb
+			}
+
+			e, err := protoToRetryQueueEntry(&entryProto)
+
+			if err != nil {
+				return err
+			}
+
+			entries = append(entries, *e)
+			return nil
+		})
+	}, func() {
+		entries = nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// deleteRetryEntriesOfTx removes retry queue entries of all operations on
+// transaction with given hash, as part of rwTx
+func deleteRetryEntriesOfTx(rwTx kvdb.RwTx, txHash []byte) error {
+	bucket := rwTx.ReadWriteBucket(retryQueueBucketName)
+
+	if bucket == nil {
+		// store was never created, so there is nothing to delete
+		return nil
+	}
+
+	var toDelete [][]byte
+	err := bucket.ForEach(func(k, _ []byte) error {
+		if len(k) == 1+chainhash.HashSize && bytes.Equal(k[1:], txHash) {
+			toDelete = append(toDelete, append([]byte(nil), k...))
+		}
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, k := range toDelete {
+		if err := bucket.Delete(k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package stakerdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/stretchr/testify/require"
+)
+
+func MakeTestRetryQueueStore(t *testing.T) *stakerdb.RetryQueueStore {
+	cfg := stakercfg.DefaultDBConfig()
+
+	cfg.DBPath = t.TempDir()
+
+	backend, err := stakercfg.GetDbBackend(&cfg)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		backend.Close()
+	})
+
+	store, err := stakerdb.NewRetryQueueStore(backend)
+	require.NoError(t, err)
+
+	return store
+}
+
+func TestRetryQueueStore(t *testing.T) {
+	s := MakeTestRetryQueueStore(t)
+
+	entries, err := s.Entries()
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	txHash := chainhash.HashH([]byte("staking tx"))
+	now := time.Unix(time.Now().Unix(), 0)
+
+	delegationEntry := &stakerdb.RetryQueueEntry{
+		Operation:     proto.RetryOperation_SEND_DELEGATION_TO_BABYLON,
+		StakingTxHash: txHash,
+		Attempts:      1,
+		NextAttempt:   now.Add(time.Minute),
+		LastError:     "babylon node not available",
+		CreatedAt:     now,
+	}
+
+	unbondingEntry := &stakerdb.RetryQueueEntry{
+		Operation:     proto.RetryOperation_SEND_UNBONDING_TX_TO_BTC,
+		StakingTxHash: txHash,
+		Attempts:      3,
+		NextAttempt:   now.Add(time.Hour),
+		LastError:     "btc node not available",
+		CreatedAt:     now,
+	}
+
+	require.NoError(t, s.PutEntry(delegationEntry))
+	require.NoError(t, s.PutEntry(unbondingEntry))
+
+	// the same staking tx can have entries for different operations
+	entries, err = s.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	stored, err := s.GetEntry(proto.RetryOperation_SEND_DELEGATION_TO_BABYLON, &txHash)
+	require.NoError(t, err)
+	require.Equal(t, delegationEntry, stored)
+
+	// putting entry again replaces it
+	delegationEntry.Attempts = 2
+	require.NoError(t, s.PutEntry(delegationEntry))
+
+	stored, err = s.GetEntry(proto.RetryOperation_SEND_DELEGATION_TO_BABYLON, &txHash)
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), stored.Attempts)
+
+	require.NoError(t, s.DeleteEntry(proto.RetryOperation_SEND_DELEGATION_TO_BABYLON, &txHash))
+
+	_, err = s.GetEntry(proto.RetryOperation_SEND_DELEGATION_TO_BABYLON, &txHash)
+	require.ErrorIs(t, err, stakerdb.ErrRetryQueueEntryNotFound)
+
+	// deleting not existing entry is not an error
+	require.NoError(t, s.DeleteEntry(proto.RetryOperation_SEND_DELEGATION_TO_BABYLON, &txHash))
+
+	entries, err = s.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, *unbondingEntry, entries[0])
+}
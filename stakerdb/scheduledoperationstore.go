@@ -0,0 +1,254 @@
+package stakerdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/lightningnetwork/lnd/kvdb"
+	pm "google.golang.org/protobuf/proto"
+)
+
+var (
+	// mapping id -> proto.ScheduledOperationEntry
+	scheduledOperationsBucketName = []byte("scheduledOperations")
+)
+
+// ScheduledOperation is operation on staking transaction which must be executed
+// at given btc height or time
+type ScheduledOperation struct {
+	Id            uint64
+	Operation     proto.ScheduledOperationType
+	StakingTxHash chainhash.Hash
+	// zero if operation is not scheduled at height
+	ExecuteAtHeight uint32
+	// zero if operation is not scheduled at time
+	ExecuteAtTime time.Time
+	Note          string
+	CreatedAt     time.Time
+	// Number of failed execution attempts so far
+	Attempts  uint32
+	LastError string
+	// Set for operations scheduled by the daemon for delegations with automatic
+	// withdrawal
+	Automatic bool
+}
+
+// ScheduledOperationStore persists scheduled operations, so that they survive
+// restarts
+type ScheduledOperationStore struct {
+	db kvdb.Backend
+}
+
+// NewScheduledOperationStore returns a new scheduled operation store backed by db
+func NewScheduledOperationStore(db kvdb.Backend) (*ScheduledOperationStore, error) {
+	store := &ScheduledOperationStore{db}
+
+	if err := kvdb.Batch(db, func(tx kvdb.RwTx) error {
+		_, err := tx.CreateTopLevelBucket(scheduledOperationsBucketName)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func scheduledOperationKey(id uint64) []byte {
+	var key [8]byte
+	binary.BigEndian.PutUint64(key[:], id)
+	return key[:]
+}
+
+func scheduledOperationToProto(o *ScheduledOperation) *proto.ScheduledOperationEntry {
+	var executeAtTime int64
+	if !o.ExecuteAtTime.IsZero() {
+		executeAtTime = o.ExecuteAtTime.Unix()
+	}
+
+	return &proto.ScheduledOperationEntry{
+		Operation:       o.Operation,
+		StakingTxHash:   o.StakingTxHash.CloneBytes(),
+		ExecuteAtHeight: o.ExecuteAtHeight,
+		ExecuteAtTime:   executeAtTime,
+		Note:            o.Note,
+		CreatedAt:       o.CreatedAt.Unix(),
+		Attempts:        o.Attempts,
+		LastError:       o.LastError,
+		Automatic:       o.Automatic,
+	}
+}
+
+func protoToScheduledOperation(id uint64, e *proto.ScheduledOperationEntry) (*ScheduledOperation, error) {
+	txHash, err := chainhash.NewHash(e.StakingTxHash)
+
+	if err != nil {
+		return nil, ErrCorruptedTransactionsDb
+	}
+
+	var executeAtTime time.Time
+	if e.ExecuteAtTime != 0 {
+		executeAtTime = time.Unix(e.ExecuteAtTime, 0)
+	}
+
+	return &ScheduledOperation{
+		Id:              id,
+		Operation:       e.Operation,
+		StakingTxHash:   *txHash,
+		ExecuteAtHeight: e.ExecuteAtHeight,
+		ExecuteAtTime:   executeAtTime,
+		Note:            e.Note,
+		CreatedAt:       time.Unix(e.CreatedAt, 0),
+		Attempts:        e.Attempts,
+		LastError:       e.LastError,
+		Automatic:       e.Automatic,
+	}, nil
+}
+
+func putScheduledOperation(bucket kvdb.RwBucket, o *ScheduledOperation) error {
+	marshalled, err := pm.Marshal(scheduledOperationToProto(o))
+
+	if err != nil {
+		return err
+	}
+
+	return bucket.Put(scheduledOperationKey(o.Id), marshalled)
+}
+
+// AddOperation persists new scheduled operation and assigns id to it
+func (s *ScheduledOperationStore) AddOperation(o *ScheduledOperation) error {
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(scheduledOperationsBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		id, err := bucket.NextSequence()
+
+		if err != nil {
+			return err
+		}
+
+		o.Id = id
+
+		return putScheduledOperation(bucket, o)
+	})
+}
+
+// UpdateOperation replaces existing scheduled operation with the same id
+func (s *ScheduledOperationStore) UpdateOperation(o *ScheduledOperation) error {
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(scheduledOperationsBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		if bucket.Get(scheduledOperationKey(o.Id)) == nil {
+			return ErrScheduledOperationNotFound
+		}
+
+		return putScheduledOperation(bucket, o)
+	})
+}
+
+// DeleteOperation removes scheduled operation
+func (s *ScheduledOperationStore) DeleteOperation(id uint64) error {
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(scheduledOperationsBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		key := scheduledOperationKey(id)
+
+		if bucket.Get(key) == nil {
+			return ErrScheduledOperationNotFound
+		}
+
+		return bucket.Delete(key)
+	})
+}
+
+// Operations returns all scheduled operations ordered by id
+func (s *ScheduledOperationStore) Operations() ([]ScheduledOperation, error) {
+	var operations []ScheduledOperation
+
+	err := s.db.View(func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(scheduledOperationsBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			if len(k) != 8 {
+				return ErrCorruptedTransactionsDb
+			}
+
+			var entryProto proto.ScheduledOperationEntry
+
+			if err := pm.Unmarshal(v, &entryProto); err != nil {
+				return ErrCorruptedTransactionsDb
+			}
+
+			o, err := protoToScheduledOperation(binary.BigEndian.Uint64(k), &entryProto)
+
+			if err != nil {
+				return err
+			}
+
+			operations = append(operations, *o)
+			return nil
+		})
+	}, func() {
+		operations = nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return operations, nil
+}
+
+// deleteScheduledOperationsOfTx removes all operations scheduled on staking
+// transaction with given hash, as part of rwTx
+func deleteScheduledOperationsOfTx(rwTx kvdb.RwTx, stakingTxHash []byte) error {
+	bucket := rwTx.ReadWriteBucket(scheduledOperationsBucketName)
+
+	if bucket == nil {
+		// store was never created, so there is nothing to delete
+		return nil
+	}
+
+	var toDelete [][]byte
+	err := bucket.ForEach(func(k, v []byte) error {
+		var entryProto proto.ScheduledOperationEntry
+
+		if err := pm.Unmarshal(v, &entryProto); err != nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		if bytes.Equal(entryProto.StakingTxHash, stakingTxHash) {
+			toDelete = append(toDelete, append([]byte(nil), k...))
+		}
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, k := range toDelete {
+		if err := bucket.Delete(k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package stakerdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/stretchr/testify/require"
+)
+
+func MakeTestScheduledOperationStore(t *testing.T) *stakerdb.ScheduledOperationStore {
+	cfg := stakercfg.DefaultDBConfig()
+
+	cfg.DBPath = t.TempDir()
+
+	backend, err := stakercfg.GetDbBackend(&cfg)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		backend.Close()
+	})
+
+	store, err := stakerdb.NewScheduledOperationStore(backend)
+	require.NoError(t, err)
+
+	return store
+}
+
+func TestScheduledOperationStore(t *testing.T) {
+	s := MakeTestScheduledOperationStore(t)
+
+	operations, err := s.Operations()
+	require.NoError(t, err)
+	require.Empty(t, operations)
+
+	txHash := chainhash.HashH([]byte("staking tx"))
+	now := time.Unix(time.Now().Unix(), 0)
+
+	unbond := stakerdb.ScheduledOperation{
+		Operation:       proto.ScheduledOperationType_SCHEDULED_UNBOND,
+		StakingTxHash:   txHash,
+		ExecuteAtHeight: 850000,
+		Note:            "after review window",
+		CreatedAt:       now,
+	}
+
+	withdraw := stakerdb.ScheduledOperation{
+		Operation:     proto.ScheduledOperationType_SCHEDULED_WITHDRAW,
+		StakingTxHash: txHash,
+		ExecuteAtTime: now.Add(24 * time.Hour),
+		CreatedAt:     now,
+		Automatic:     true,
+	}
+
+	require.NoError(t, s.AddOperation(&unbond))
+	require.NoError(t, s.AddOperation(&withdraw))
+	require.NotEqual(t, unbond.Id, withdraw.Id)
+
+	operations, err = s.Operations()
+	require.NoError(t, err)
+	require.Equal(t, []stakerdb.ScheduledOperation{unbond, withdraw}, operations)
+
+	withdraw.Attempts = 1
+	withdraw.LastError = "btc node not available"
+	require.NoError(t, s.UpdateOperation(&withdraw))
+
+	require.NoError(t, s.DeleteOperation(unbond.Id))
+	require.ErrorIs(t, s.DeleteOperation(unbond.Id), stakerdb.ErrScheduledOperationNotFound)
+	require.ErrorIs(t, s.UpdateOperation(&unbond), stakerdb.ErrScheduledOperationNotFound)
+
+	operations, err = s.Operations()
+	require.NoError(t, err)
+	require.Equal(t, []stakerdb.ScheduledOperation{withdraw}, operations)
+}
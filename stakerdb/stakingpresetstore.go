@@ -0,0 +1,160 @@
+package stakerdb
+
+import (
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/lightningnetwork/lnd/kvdb"
+	pm "google.golang.org/protobuf/proto"
+)
+
+var (
+	// mapping preset name -> proto.StakingPresetEntry
+	stakingPresetsBucketName = []byte("stakingPresets")
+)
+
+// StakingPresetEntry is named set of staking parameters created through rpc
+type StakingPresetEntry struct {
+	Name              string
+	FinalityProviders []*btcec.PublicKey
+	StakingTimeBlocks uint16
+	AmountTiers       []btcutil.Amount
+	MaxFeeRate        uint64
+	UpdatedAt         time.Time
+}
+
+// StakingPresetStore keeps staking presets managed through rpc
+type StakingPresetStore struct {
+	db kvdb.Backend
+}
+
+// NewStakingPresetStore returns a new staking preset store backed by db
+func NewStakingPresetStore(db kvdb.Backend) (*StakingPresetStore, error) {
+	store := &StakingPresetStore{db}
+
+	if err := kvdb.Batch(db, func(tx kvdb.RwTx) error {
+		_, err := tx.CreateTopLevelBucket(stakingPresetsBucketName)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func stakingPresetFromProto(name string, entryProto *proto.StakingPresetEntry) (*StakingPresetEntry, error) {
+	e := &StakingPresetEntry{
+		Name:              name,
+		StakingTimeBlocks: uint16(entryProto.StakingTimeBlocks),
+		MaxFeeRate:        entryProto.MaxFeeRate,
+		UpdatedAt:         time.Unix(entryProto.UpdatedAt, 0),
+	}
+
+	for _, pkBytes := range entryProto.FpBtcPks {
+		pk, err := schnorr.ParsePubKey(pkBytes)
+
+		if err != nil {
+			return nil, ErrCorruptedTransactionsDb
+		}
+
+		e.FinalityProviders = append(e.FinalityProviders, pk)
+	}
+
+	for _, a := range entryProto.AmountTiers {
+		e.AmountTiers = append(e.AmountTiers, btcutil.Amount(a))
+	}
+
+	return e, nil
+}
+
+// PutPreset stores the preset, existing preset with the same name is
+// overwritten
+func (s *StakingPresetStore) PutPreset(e *StakingPresetEntry) error {
+	entryProto := &proto.StakingPresetEntry{
+		StakingTimeBlocks: uint32(e.StakingTimeBlocks),
+		MaxFeeRate:        e.MaxFeeRate,
+		UpdatedAt:         e.UpdatedAt.Unix(),
+	}
+
+	for _, pk := range e.FinalityProviders {
+		entryProto.FpBtcPks = append(entryProto.FpBtcPks, schnorr.SerializePubKey(pk))
+	}
+
+	for _, a := range e.AmountTiers {
+		entryProto.AmountTiers = append(entryProto.AmountTiers, int64(a))
+	}
+
+	marshalled, err := pm.Marshal(entryProto)
+
+	if err != nil {
+		return err
+	}
+
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(stakingPresetsBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		return bucket.Put([]byte(e.Name), marshalled)
+	})
+}
+
+// DeletePreset removes preset with given name
+func (s *StakingPresetStore) DeletePreset(name string) error {
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(stakingPresetsBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		if bucket.Get([]byte(name)) == nil {
+			return ErrStakingPresetNotFound
+		}
+
+		return bucket.Delete([]byte(name))
+	})
+}
+
+// Presets returns all stored presets ordered by name
+func (s *StakingPresetStore) Presets() ([]StakingPresetEntry, error) {
+	var presets []StakingPresetEntry
+
+	err := s.db.View(func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(stakingPresetsBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var entryProto proto.StakingPresetEntry
+
+			if err := pm.Unmarshal(v, &entryProto); err != nil {
+				return ErrCorruptedTransactionsDb
+			}
+
+			e, err := stakingPresetFromProto(string(k), &entryProto)
+
+			if err != nil {
+				return err
+			}
+
+			presets = append(presets, *e)
+			return nil
+		})
+	}, func() {
+		presets = nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return presets, nil
+}
@@ -0,0 +1,84 @@
+package stakerdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/stretchr/testify/require"
+)
+
+func MakeTestStakingPresetStore(t *testing.T) *stakerdb.StakingPresetStore {
+	cfg := stakercfg.DefaultDBConfig()
+
+	cfg.DBPath = t.TempDir()
+
+	backend, err := stakercfg.GetDbBackend(&cfg)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		backend.Close()
+	})
+
+	store, err := stakerdb.NewStakingPresetStore(backend)
+	require.NoError(t, err)
+
+	return store
+}
+
+func TestStakingPresetStore(t *testing.T) {
+	s := MakeTestStakingPresetStore(t)
+
+	presets, err := s.Presets()
+	require.NoError(t, err)
+	require.Empty(t, presets)
+
+	fpKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	now := time.Unix(time.Now().Unix(), 0)
+
+	preset := stakerdb.StakingPresetEntry{
+		Name:              "tier-small",
+		FinalityProviders: []*btcec.PublicKey{fpKey.PubKey()},
+		StakingTimeBlocks: 1000,
+		AmountTiers:       []btcutil.Amount{100000, 500000},
+		MaxFeeRate:        20,
+		UpdatedAt:         now,
+	}
+
+	require.NoError(t, s.PutPreset(&preset))
+
+	presets, err = s.Presets()
+	require.NoError(t, err)
+	require.Len(t, presets, 1)
+	require.Equal(t, preset.Name, presets[0].Name)
+	require.Equal(t, preset.StakingTimeBlocks, presets[0].StakingTimeBlocks)
+	require.Equal(t, preset.AmountTiers, presets[0].AmountTiers)
+	require.Equal(t, preset.MaxFeeRate, presets[0].MaxFeeRate)
+	require.Equal(t, preset.UpdatedAt, presets[0].UpdatedAt)
+	require.Len(t, presets[0].FinalityProviders, 1)
+	// keys are stored as BIP340 keys, so only x coordinate is compared
+	require.Equal(t, preset.FinalityProviders[0].X(), presets[0].FinalityProviders[0].X())
+
+	// existing preset is overwritten
+	preset.StakingTimeBlocks = 2000
+	preset.AmountTiers = nil
+	require.NoError(t, s.PutPreset(&preset))
+
+	presets, err = s.Presets()
+	require.NoError(t, err)
+	require.Len(t, presets, 1)
+	require.Equal(t, uint16(2000), presets[0].StakingTimeBlocks)
+	require.Empty(t, presets[0].AmountTiers)
+
+	require.ErrorIs(t, s.DeletePreset("missing"), stakerdb.ErrStakingPresetNotFound)
+	require.NoError(t, s.DeletePreset(preset.Name))
+
+	presets, err = s.Presets()
+	require.NoError(t, err)
+	require.Empty(t, presets)
+}
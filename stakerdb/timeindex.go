@@ -0,0 +1,171 @@
+package stakerdb
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/lightningnetwork/lnd/kvdb"
+	pm "google.golang.org/protobuf/proto"
+)
+
+var (
+	// mapping creation time || uint64 -> nil
+	// It allows listing transactions created in time range without reading all
+	// transactions
+	createdAtIdxBucketName = []byte("createdAtIdx")
+
+	// mapping transition time || uint64 || state -> nil
+	stateTransitionIdxBucketName = []byte("stateTransitionIdx")
+)
+
+// TimeRange is range of time with inclusive start and exclusive end. Zero time
+// means range is not bounded from that side.
+type TimeRange struct {
+	After  time.Time
+	Before time.Time
+}
+
+func (r TimeRange) IsZero() bool {
+	return r.After.IsZero() && r.Before.IsZero()
+}
+
+// timeIdxKey returns key of time index entry, timestamps are unix seconds as
+// stored in state transitions so keys sort by time
+func timeIdxKey(timestamp int64, txKey []byte) []byte {
+	key := make([]byte, 8, 16)
+	binary.BigEndian.PutUint64(key, uint64(timestamp))
+	return append(key, txKey...)
+}
+
+func stateTransitionIdxKey(transition *proto.StateTransition, txKey []byte) []byte {
+	var state [4]byte
+	binary.BigEndian.PutUint32(state[:], uint32(transition.State))
+	return append(timeIdxKey(transition.Timestamp, txKey), state[:]...)
+}
+
+func putCreatedAtIdx(rwTx kvdb.RwTx, txKey []byte, tx *proto.TrackedTransaction) error {
+	// transactions added before state history was tracked have unknown
+	// creation time
+	if len(tx.StateTransitions) == 0 {
+		return nil
+	}
+
+	bucket := rwTx.ReadWriteBucket(createdAtIdxBucketName)
+	if bucket == nil {
+		return ErrCorruptedTransactionsDb
+	}
+
+	return bucket.Put(timeIdxKey(tx.StateTransitions[0].Timestamp, txKey), nil)
+}
+
+func putStateTransitionIdx(rwTx kvdb.RwTx, txKey []byte, transitions ...*proto.StateTransition) error {
+	bucket := rwTx.ReadWriteBucket(stateTransitionIdxBucketName)
+	if bucket == nil {
+		return ErrCorruptedTransactionsDb
+	}
+
+	for _, transition := range transitions {
+		if err := bucket.Put(stateTransitionIdxKey(transition, txKey), nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func deleteTimeIdx(rwTx kvdb.RwTx, txKey []byte, tx *proto.TrackedTransaction) error {
+	if len(tx.StateTransitions) == 0 {
+		return nil
+	}
+
+	createdAtBucket := rwTx.ReadWriteBucket(createdAtIdxBucketName)
+	transitionBucket := rwTx.ReadWriteBucket(stateTransitionIdxBucketName)
+
+	if createdAtBucket == nil || transitionBucket == nil {
+		return ErrCorruptedTransactionsDb
+	}
+
+	if err := createdAtBucket.Delete(timeIdxKey(tx.StateTransitions[0].Timestamp, txKey)); err != nil {
+		return err
+	}
+
+	for _, transition := range tx.StateTransitions {
+		if err := transitionBucket.Delete(stateTransitionIdxKey(transition, txKey)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// initTimeIdx creates time index buckets, indexing already stored transactions
+// if the buckets did not exist before
+func initTimeIdx(rwTx kvdb.RwTx) error {
+	if rwTx.ReadWriteBucket(createdAtIdxBucketName) != nil &&
+		rwTx.ReadWriteBucket(stateTransitionIdxBucketName) != nil {
+		return nil
+	}
+
+	if _, err := rwTx.CreateTopLevelBucket(createdAtIdxBucketName); err != nil {
+		return err
+	}
+
+	if _, err := rwTx.CreateTopLevelBucket(stateTransitionIdxBucketName); err != nil {
+		return err
+	}
+
+	transactionsBucket := rwTx.ReadWriteBucket(transactionBucketName)
+	if transactionsBucket == nil {
+		return ErrCorruptedTransactionsDb
+	}
+
+	return transactionsBucket.ForEach(func(k, v []byte) error {
+		var storedTx proto.TrackedTransaction
+		if err := pm.Unmarshal(v, &storedTx); err != nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		if err := putCreatedAtIdx(rwTx, k, &storedTx); err != nil {
+			return err
+		}
+
+		return putStateTransitionIdx(rwTx, k, storedTx.StateTransitions...)
+	})
+}
+
+// txKeysInTimeRange returns keys of transactions with time index entry in given
+// range. If state is not nil, only entries of transitions to this state are
+// considered.
+func txKeysInTimeRange(
+	bucket walletdb.ReadBucket,
+	r TimeRange,
+	state *proto.TransactionState,
+) map[uint64]struct{} {
+	keys := make(map[uint64]struct{})
+	cursor := bucket.ReadCursor()
+
+	var k []byte
+	if r.After.IsZero() {
+		k, _ = cursor.First()
+	} else {
+		k, _ = cursor.Seek(timeIdxKey(r.After.Unix(), nil))
+	}
+
+	for ; k != nil; k, _ = cursor.Next() {
+		timestamp := int64(binary.BigEndian.Uint64(k[:8]))
+
+		if !r.Before.IsZero() && timestamp >= r.Before.Unix() {
+			break
+		}
+
+		if state != nil && (len(k) < 20 || proto.TransactionState(binary.BigEndian.Uint32(k[16:20])) != *state) {
+			continue
+		}
+
+		keys[binary.BigEndian.Uint64(k[8:16])] = struct{}{}
+	}
+
+	return keys
+}
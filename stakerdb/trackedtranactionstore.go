@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/babylonchain/btc-staker/proto"
 	"github.com/babylonchain/btc-staker/utils"
@@ -34,8 +35,19 @@ var (
 
 	// key for next transaction
 	numTxKey = []byte("ntk")
+
+	// key for number of purged transactions
+	numPurgedTxKey = []byte("npk")
 )
 
+// MaxBabylonTxResponses is maximum number of babylon responses kept for one
+// delegation, so that repeatedly re-sent delegation does not grow without bound
+const MaxBabylonTxResponses = 32
+
+// MaxDelegationNotes is maximum number of notes attached to one delegation. Notes
+// carry operational context, so they are never dropped silently.
+const MaxDelegationNotes = 64
+
 type StoredTransactionScanFn func(tx *StoredTransaction) error
 
 type TrackedTransactionStore struct {
@@ -114,6 +126,37 @@ type BtcConfirmationInfo struct {
 	BlockHash chainhash.Hash
 }
 
+type StateTransition struct {
+	State     proto.TransactionState
+	Timestamp time.Time
+}
+
+func newStateTransition(state proto.TransactionState) *proto.StateTransition {
+	return &proto.StateTransition{
+		State:     state,
+		Timestamp: time.Now().Unix(),
+	}
+}
+
+// BabylonTxResponse is response of babylon to transaction sent on behalf of
+// delegation
+type BabylonTxResponse struct {
+	MsgType   string
+	TxHash    string
+	Height    int64
+	Codespace string
+	// 0 if transaction was executed successfully
+	Code      uint32
+	Timestamp time.Time
+}
+
+// DelegationNote is free text note attached to delegation by the operator
+type DelegationNote struct {
+	Timestamp time.Time
+	Text      string
+	RequestId string
+}
+
 type StoredTransaction struct {
 	StoredTransactionIdx      uint64
 	StakingTx                 *wire.MsgTx
@@ -128,6 +171,96 @@ type StoredTransaction struct {
 	State           proto.TransactionState
 	Watched         bool
 	UnbondingTxData *UnbondingStoreData
+	Metadata        map[string]string
+	// Transactions added before state history was tracked have empty history
+	StateTransitions []StateTransition
+	StakingTxFee     btcutil.Amount
+	SpendTxFee       btcutil.Amount
+	// Set only for transactions funded on behalf of external staker key, which is
+	// not controlled by connected wallet
+	ExternalStakerBtcPk *btcec.PublicKey
+	// Id of the rpc request which created the transaction, empty for transactions
+	// created before request ids were tracked
+	RequestId string
+	// Group (portfolio) of the delegation, empty if not assigned to any group
+	Group string
+	// Unbonding time and fee rate (sat/kvbyte) used when delegation is sent to
+	// babylon, zero if defaults are used
+	UnbondingTimeOverride    uint16
+	UnbondingFeeRateOverride btcutil.Amount
+	// Address receiving withdrawn funds, empty if funds are withdrawn to staker
+	// address
+	WithdrawalAddress string
+	// Responses of babylon to transactions sent for the delegation, oldest first
+	BabylonTxResponses []BabylonTxResponse
+	// Notes attached to delegation by the operator, oldest first
+	Notes []DelegationNote
+	// Staked funds are withdrawn automatically once timelock of staking or
+	// unbonding output expires
+	AutoWithdraw bool
+	// Hash of transaction which withdrew staked funds, nil if funds were not
+	// withdrawn or were withdrawn before it was tracked
+	SpendTxHash *chainhash.Hash
+	// Hashes of slashing transactions sent to babylon, nil if delegation was not
+	// sent to babylon by this daemon
+	SlashingTxHash          *chainhash.Hash
+	UnbondingSlashingTxHash *chainhash.Hash
+	// Hashes of child transactions which paid for staking transaction, oldest
+	// first
+	CpfpChildTxHashes []chainhash.Hash
+}
+
+// WatchOnly returns true if staker key of the transaction is not controlled by
+// connected wallet i.e staker program cannot sign any spending of staking output
+func (t *StoredTransaction) WatchOnly() bool {
+	return t.Watched || t.ExternalStakerBtcPk != nil
+}
+
+// StateTimestamp returns time at which transaction entered given state, second
+// return value is false if transaction never was in given state
+func (t *StoredTransaction) StateTimestamp(state proto.TransactionState) (time.Time, bool) {
+	for _, transition := range t.StateTransitions {
+		if transition.State == state {
+			return transition.Timestamp, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// CreatedAt returns time at which transaction was added to database, or zero time
+// if it is unknown
+func (t *StoredTransaction) CreatedAt() time.Time {
+	if len(t.StateTransitions) == 0 {
+		return time.Time{}
+	}
+
+	return t.StateTransitions[0].Timestamp
+}
+
+// UnbondingTxFee returns fee paid by unbonding transaction, or 0 if delegation
+// does not have unbonding transaction yet
+func (t *StoredTransaction) UnbondingTxFee() btcutil.Amount {
+	if t.UnbondingTxData == nil || t.UnbondingTxData.UnbondingTx == nil {
+		return 0
+	}
+
+	stakingValue := t.StakingTx.TxOut[t.StakingOutputIndex].Value
+	unbondingValue := t.UnbondingTxData.UnbondingTx.TxOut[0].Value
+
+	return btcutil.Amount(stakingValue - unbondingValue)
+}
+
+// MissingFeeData returns true if fees paid by the transaction are not known, i.e
+// staking fee of not watched transaction, or spend fee of spent transaction, is
+// missing or zero. Transactions stored by older versions of staker have no fee
+// fields.
+func (t *StoredTransaction) MissingFeeData() bool {
+	if !t.Watched && t.StakingTxFee <= 0 {
+		return true
+	}
+
+	return t.State == proto.TransactionState_SPENT_ON_BTC && t.SpendTxFee <= 0
 }
 
 // StakingTxConfirmedOnBtc returns true only if staking transaction was sent and confirmed on bitcoin
@@ -142,6 +275,34 @@ func (t *StoredTransaction) IsUnbonded() bool {
 	return t.State == proto.TransactionState_UNBONDING_CONFIRMED_ON_BTC
 }
 
+// BlocksToMaturity returns number of blocks after which timelocked output of
+// staking or unbonding transaction can be withdrawn, 0 if it can be withdrawn
+// already. Returns false if transaction has no confirmed timelocked output.
+func (t *StoredTransaction) BlocksToMaturity(currentBestBlockHeight uint32) (uint32, bool) {
+	var confirmationHeight uint32
+	var lockTime uint16
+
+	switch {
+	case t.StakingTxConfirmedOnBtc():
+		confirmationHeight = t.StakingTxConfirmationInfo.Height
+		lockTime = t.StakingTime
+	case t.IsUnbonded() && t.UnbondingTxData != nil && t.UnbondingTxData.UnbondingTxConfirmationInfo != nil:
+		confirmationHeight = t.UnbondingTxData.UnbondingTxConfirmationInfo.Height
+		lockTime = t.UnbondingTxData.UnbondingTime
+	default:
+		return 0, false
+	}
+
+	// transaction maybe included/executed only in next possible block
+	remaining := int64(confirmationHeight) + int64(lockTime) - (int64(currentBestBlockHeight) + 1)
+
+	if remaining <= 0 {
+		return 0, true
+	}
+
+	return uint32(remaining), true
+}
+
 type WatchedTransactionData struct {
 	SlashingTx          *wire.MsgTx
 	SlashingTxSig       *schnorr.Signature
@@ -196,6 +357,18 @@ type StoredTransactionQuery struct {
 	Reversed bool
 
 	withdrawableTransactionsFilter *WithdrawableTransactionsFilter
+
+	metadataFilter map[string]string
+
+	groupFilter *string
+
+	matchFn func(tx *StoredTransaction) bool
+
+	createdRange *TimeRange
+
+	stateTransitionRange *TimeRange
+
+	stateTransitionState *proto.TransactionState
 }
 
 func DefaultStoredTransactionQuery() StoredTransactionQuery {
@@ -215,9 +388,121 @@ func (q *StoredTransactionQuery) WithdrawableTransactionsFilter(currentBestBlock
 	return *q
 }
 
+// WithMetadataFilter restricts query results to transactions which have all the
+// key/value pairs from provided filter in their metadata
+func (q *StoredTransactionQuery) WithMetadataFilter(filter map[string]string) StoredTransactionQuery {
+	q.metadataFilter = filter
+	return *q
+}
+
+// WithGroupFilter restricts query results to transactions assigned to given
+// group, empty group matches transactions which are not assigned to any group
+func (q *StoredTransactionQuery) WithGroupFilter(group string) StoredTransactionQuery {
+	q.groupFilter = &group
+	return *q
+}
+
+// WithMatchFunc restricts query results to transactions for which match
+// returns true
+func (q *StoredTransactionQuery) WithMatchFunc(match func(tx *StoredTransaction) bool) StoredTransactionQuery {
+	q.matchFn = match
+	return *q
+}
+
+// WithCreatedRange restricts query results to transactions created in given
+// time range. Transactions with unknown creation time never match.
+func (q *StoredTransactionQuery) WithCreatedRange(r TimeRange) StoredTransactionQuery {
+	q.createdRange = &r
+	return *q
+}
+
+// WithStateTransitionRange restricts query results to transactions which changed
+// state in given time range. If state is not nil, only transitions to this state
+// are considered.
+func (q *StoredTransactionQuery) WithStateTransitionRange(state *proto.TransactionState, r TimeRange) StoredTransactionQuery {
+	q.stateTransitionRange = &r
+	q.stateTransitionState = state
+	return *q
+}
+
+// txKeysFilter returns keys of transactions matching time range filters of the
+// query using time indexes, or nil if query has no time range filter
+func (q *StoredTransactionQuery) txKeysFilter(tx kvdb.RTx) (map[uint64]struct{}, error) {
+	var keys map[uint64]struct{}
+
+	if q.createdRange != nil {
+		bucket := tx.ReadBucket(createdAtIdxBucketName)
+		if bucket == nil {
+			return nil, ErrCorruptedTransactionsDb
+		}
+
+		keys = txKeysInTimeRange(bucket, *q.createdRange, nil)
+	}
+
+	if q.stateTransitionRange != nil {
+		bucket := tx.ReadBucket(stateTransitionIdxBucketName)
+		if bucket == nil {
+			return nil, ErrCorruptedTransactionsDb
+		}
+
+		transitionKeys := txKeysInTimeRange(bucket, *q.stateTransitionRange, q.stateTransitionState)
+
+		if keys == nil {
+			keys = transitionKeys
+		} else {
+			for k := range keys {
+				if _, found := transitionKeys[k]; !found {
+					delete(keys, k)
+				}
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+// hasFilters returns true if query restricts returned transactions in any other
+// way than pagination
+func (q *StoredTransactionQuery) hasFilters() bool {
+	return q.hasTransactionFilters() ||
+		q.createdRange != nil ||
+		q.stateTransitionRange != nil
+}
+
+// hasTransactionFilters returns true if query has filters which can only be
+// checked on decoded transaction i.e which are not answered by time indexes
+func (q *StoredTransactionQuery) hasTransactionFilters() bool {
+	return q.withdrawableTransactionsFilter != nil ||
+		len(q.metadataFilter) > 0 ||
+		q.groupFilter != nil ||
+		q.matchFn != nil
+}
+
+func (q *StoredTransactionQuery) matchesGroupFilter(tx *StoredTransaction) bool {
+	return q.groupFilter == nil || *q.groupFilter == tx.Group
+}
+
+func (q *StoredTransactionQuery) matchesMatchFunc(tx *StoredTransaction) bool {
+	return q.matchFn == nil || q.matchFn(tx)
+}
+
+func (q *StoredTransactionQuery) matchesMetadataFilter(tx *StoredTransaction) bool {
+	for k, v := range q.metadataFilter {
+		if value, found := tx.Metadata[k]; !found || value != v {
+			return false
+		}
+	}
+
+	return true
+}
+
 type StoredTransactionQueryResult struct {
 	Transactions []StoredTransaction
-	Total        uint64
+	// Number of transactions matching all filters of the query, regardless of
+	// pagination
+	Total uint64
+	// Number of all transactions in database
+	TotalStored uint64
 }
 
 // NewTrackedTransactionStore returns a new store backed by db
@@ -249,7 +534,7 @@ func (c *TrackedTransactionStore) initBuckets() error {
 			return err
 		}
 
-		return nil
+		return initTimeIdx(tx)
 	})
 }
 
@@ -340,6 +625,68 @@ func protoTxToStoredTransaction(ttx *proto.TrackedTransaction) (*StoredTransacti
 		return nil, fmt.Errorf("staking time is too large. Max value is %d", math.MaxUint16)
 	}
 
+	var stateTransitions []StateTransition = make([]StateTransition, len(ttx.StateTransitions))
+
+	for i, transition := range ttx.StateTransitions {
+		stateTransitions[i] = StateTransition{
+			State:     transition.State,
+			Timestamp: time.Unix(transition.Timestamp, 0),
+		}
+	}
+
+	var babylonTxResponses []BabylonTxResponse = make([]BabylonTxResponse, len(ttx.BabylonTxResponses))
+
+	for i, resp := range ttx.BabylonTxResponses {
+		babylonTxResponses[i] = BabylonTxResponse{
+			MsgType:   resp.MsgType,
+			TxHash:    resp.TxHash,
+			Height:    resp.Height,
+			Codespace: resp.Codespace,
+			Code:      resp.Code,
+			Timestamp: time.Unix(resp.Timestamp, 0),
+		}
+	}
+
+	var notes []DelegationNote = make([]DelegationNote, len(ttx.Notes))
+
+	for i, note := range ttx.Notes {
+		notes[i] = DelegationNote{
+			Timestamp: time.Unix(note.Timestamp, 0),
+			Text:      note.Text,
+			RequestId: note.RequestId,
+		}
+	}
+
+	spendTxHash, err := optionalHashFromBytes(ttx.SpendTxHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	slashingTxHash, err := optionalHashFromBytes(ttx.SlashingTxHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	unbondingSlashingTxHash, err := optionalHashFromBytes(ttx.UnbondingSlashingTxHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var cpfpChildTxHashes []chainhash.Hash = make([]chainhash.Hash, len(ttx.CpfpChildTxHashes))
+
+	for i, hashBytes := range ttx.CpfpChildTxHashes {
+		childHash, err := chainhash.NewHash(hashBytes)
+
+		if err != nil {
+			return nil, err
+		}
+
+		cpfpChildTxHashes[i] = *childHash
+	}
+
 	var fpPubkeys []*btcec.PublicKey = make([]*btcec.PublicKey, len(ttx.FinalityProvidersBtcPks))
 
 	for i, pk := range ttx.FinalityProvidersBtcPks {
@@ -350,6 +697,15 @@ func protoTxToStoredTransaction(ttx *proto.TrackedTransaction) (*StoredTransacti
 		}
 	}
 
+	var externalStakerBtcPk *btcec.PublicKey
+	if len(ttx.ExternalStakerBtcPk) > 0 {
+		externalStakerBtcPk, err = schnorr.ParsePubKey(ttx.ExternalStakerBtcPk)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &StoredTransaction{
 		StoredTransactionIdx:      ttx.TrackedTransactionIdx,
 		StakingTx:                 &stakingTx,
@@ -362,13 +718,40 @@ func protoTxToStoredTransaction(ttx *proto.TrackedTransaction) (*StoredTransacti
 			BabylonSigOverBtcPk:  ttx.BabylonSigBtcPk,
 			BtcSigOverBabylonSig: ttx.BtcSigBabylonSig,
 		},
-		StakerAddress:   ttx.StakerAddress,
-		State:           ttx.State,
-		Watched:         ttx.Watched,
-		UnbondingTxData: utd,
+		StakerAddress:            ttx.StakerAddress,
+		State:                    ttx.State,
+		Watched:                  ttx.Watched,
+		UnbondingTxData:          utd,
+		Metadata:                 ttx.Metadata,
+		StateTransitions:         stateTransitions,
+		StakingTxFee:             btcutil.Amount(ttx.StakingTxFee),
+		SpendTxFee:               btcutil.Amount(ttx.SpendTxFee),
+		ExternalStakerBtcPk:      externalStakerBtcPk,
+		RequestId:                ttx.RequestId,
+		Group:                    ttx.Group,
+		UnbondingTimeOverride:    uint16(ttx.UnbondingTimeOverride),
+		UnbondingFeeRateOverride: btcutil.Amount(ttx.UnbondingFeeRateOverride),
+		WithdrawalAddress:        ttx.WithdrawalAddress,
+		BabylonTxResponses:       babylonTxResponses,
+		Notes:                    notes,
+		AutoWithdraw:             ttx.AutoWithdraw,
+		SpendTxHash:              spendTxHash,
+		SlashingTxHash:           slashingTxHash,
+		UnbondingSlashingTxHash:  unbondingSlashingTxHash,
+		CpfpChildTxHashes:        cpfpChildTxHashes,
 	}, nil
 }
 
+// optionalHashFromBytes returns nil for empty bytes, which are stored for hashes
+// which are not known
+func optionalHashFromBytes(b []byte) (*chainhash.Hash, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+
+	return chainhash.NewHash(b)
+}
+
 func protoWatchedDataToWatchedTransactionData(wd *proto.WatchedTxData) (*WatchedTransactionData, error) {
 	var slashingTx wire.MsgTx
 	err := slashingTx.Deserialize(bytes.NewReader(wd.SlashingTransaction))
@@ -448,11 +831,21 @@ func nextTxKey(txIdxBucket walletdb.ReadBucket) uint64 {
 	return currKey
 }
 
+func getNumPurgedTx(txIdxBucket walletdb.ReadBucket) uint64 {
+	numPurgedBytes := txIdxBucket.Get(numPurgedTxKey)
+
+	if numPurgedBytes == nil {
+		return 0
+	}
+
+	return binary.BigEndian.Uint64(numPurgedBytes)
+}
+
 func getNumTx(txIdxBucket walletdb.ReadBucket) uint64 {
 	// we are starting indexing transactions from 1, and nextTxKey always return next key
 	// which should be used when indexing transaction, so to get number of transactions
-	// we need to subtract 1
-	return nextTxKey(txIdxBucket) - 1
+	// we need to subtract 1. Indexes of purged transactions are never reused.
+	return nextTxKey(txIdxBucket) - 1 - getNumPurgedTx(txIdxBucket)
 }
 
 // getTxByHash retruns transaction and transaction key if transaction with given hash exsits
@@ -512,6 +905,14 @@ func saveTrackedTransaction(
 		return err
 	}
 
+	if err := putCreatedAtIdx(rwTx, nextTxKeyBytes, tx); err != nil {
+		return err
+	}
+
+	if err := putStateTransitionIdx(rwTx, nextTxKeyBytes, tx.StateTransitions...); err != nil {
+		return err
+	}
+
 	if watchedTxData != nil {
 		watchedTxBucket := rwTx.ReadWriteBucket(watchedTxDataBucketName)
 		if watchedTxBucket == nil {
@@ -569,6 +970,9 @@ func (c *TrackedTransactionStore) AddTransaction(
 	fpPubKeys []*btcec.PublicKey,
 	pop *ProofOfPossession,
 	stakerAddress btcutil.Address,
+	metadata map[string]string,
+	stakingTxFee btcutil.Amount,
+	requestId string,
 ) error {
 	txHash := btcTx.TxHash()
 	txHashBytes := txHash[:]
@@ -603,6 +1007,74 @@ func (c *TrackedTransactionStore) AddTransaction(
 		State:                        proto.TransactionState_SENT_TO_BTC,
 		Watched:                      false,
 		UnbondingTxData:              nil,
+		Metadata:                     metadata,
+		StateTransitions: []*proto.StateTransition{
+			newStateTransition(proto.TransactionState_SENT_TO_BTC),
+		},
+		StakingTxFee: int64(stakingTxFee),
+		RequestId:    requestId,
+	}
+
+	return c.addTransactionInternal(
+		txHashBytes, &msg, nil,
+	)
+}
+
+// AddExternalKeyTransaction adds staking transaction funded by connected wallet,
+// which staker key is controlled by external party. Such transaction does not
+// have proof of possession, as it can only be created by the owner of staker key.
+func (c *TrackedTransactionStore) AddExternalKeyTransaction(
+	btcTx *wire.MsgTx,
+	stakingOutputIndex uint32,
+	stakingTime uint16,
+	fpPubKeys []*btcec.PublicKey,
+	fundingAddress btcutil.Address,
+	stakerBtcPk *btcec.PublicKey,
+	metadata map[string]string,
+	stakingTxFee btcutil.Amount,
+	requestId string,
+) error {
+	txHash := btcTx.TxHash()
+	txHashBytes := txHash[:]
+	serializedTx, err := utils.SerializeBtcTransaction(btcTx)
+
+	if err != nil {
+		return err
+	}
+
+	if len(fpPubKeys) == 0 {
+		return fmt.Errorf("cannot add transaction without finality providers public keys")
+	}
+
+	if stakerBtcPk == nil {
+		return fmt.Errorf("cannot add external key transaction without staker public key")
+	}
+
+	var fpPubKeysBytes [][]byte = make([][]byte, len(fpPubKeys))
+
+	for i, pk := range fpPubKeys {
+		fpPubKeysBytes[i] = schnorr.SerializePubKey(pk)
+	}
+
+	msg := proto.TrackedTransaction{
+		// Setting it to 0, proper number will be filled by `addTransactionInternal`
+		TrackedTransactionIdx:        0,
+		StakingTransaction:           serializedTx,
+		StakingOutputIdx:             stakingOutputIndex,
+		StakerAddress:                fundingAddress.EncodeAddress(),
+		StakingTime:                  uint32(stakingTime),
+		FinalityProvidersBtcPks:      fpPubKeysBytes,
+		StakingTxBtcConfirmationInfo: nil,
+		State:                        proto.TransactionState_SENT_TO_BTC,
+		Watched:                      false,
+		UnbondingTxData:              nil,
+		Metadata:                     metadata,
+		StateTransitions: []*proto.StateTransition{
+			newStateTransition(proto.TransactionState_SENT_TO_BTC),
+		},
+		StakingTxFee:        int64(stakingTxFee),
+		ExternalStakerBtcPk: schnorr.SerializePubKey(stakerBtcPk),
+		RequestId:           requestId,
 	}
 
 	return c.addTransactionInternal(
@@ -617,6 +1089,7 @@ func (c *TrackedTransactionStore) AddWatchedTransaction(
 	fpPubKeys []*btcec.PublicKey,
 	pop *ProofOfPossession,
 	stakerAddress btcutil.Address,
+	metadata map[string]string,
 	slashingTx *wire.MsgTx,
 	slashingTxSig *schnorr.Signature,
 	stakerBabylonPk *secp256k1.PubKey,
@@ -659,6 +1132,10 @@ func (c *TrackedTransactionStore) AddWatchedTransaction(
 		State:                        proto.TransactionState_SENT_TO_BTC,
 		Watched:                      true,
 		UnbondingTxData:              nil,
+		Metadata:                     metadata,
+		StateTransitions: []*proto.StateTransition{
+			newStateTransition(proto.TransactionState_SENT_TO_BTC),
+		},
 	}
 
 	serializedSlashingtx, err := utils.SerializeBtcTransaction(slashingTx)
@@ -727,10 +1204,21 @@ func (c *TrackedTransactionStore) setTxState(
 			return ErrCorruptedTransactionsDb
 		}
 
+		previousState := storedTx.State
+
 		if err := stateTransitionFn(&storedTx); err != nil {
 			return err
 		}
 
+		if storedTx.State != previousState {
+			transition := newStateTransition(storedTx.State)
+			storedTx.StateTransitions = append(storedTx.StateTransitions, transition)
+
+			if err := putStateTransitionIdx(tx, txKey, transition); err != nil {
+				return err
+			}
+		}
+
 		marshalled, err := pm.Marshal(&storedTx)
 
 		if err != nil {
@@ -788,9 +1276,286 @@ func (c *TrackedTransactionStore) SetTxSentToBabylon(
 	return c.setTxState(txHash, setTxSentToBabylon)
 }
 
-func (c *TrackedTransactionStore) SetTxSpentOnBtc(txHash *chainhash.Hash) error {
+// SetTxGroup assigns transaction to given group, empty group removes existing
+// assignment
+func (c *TrackedTransactionStore) SetTxGroup(txHash *chainhash.Hash, group string) error {
+	setGroup := func(tx *proto.TrackedTransaction) error {
+		tx.Group = group
+		return nil
+	}
+
+	return c.setTxState(txHash, setGroup)
+}
+
+// SetTxUnbondingOverrides sets unbonding time and fee rate used when delegation
+// is sent to babylon. Zero value restores the default. Unbonding transaction is
+// created when delegation is sent to babylon, so overrides can't be changed
+// afterwards.
+func (c *TrackedTransactionStore) SetTxUnbondingOverrides(
+	txHash *chainhash.Hash,
+	unbondingTime uint16,
+	feeRate btcutil.Amount,
+) error {
+	setOverrides := func(tx *proto.TrackedTransaction) error {
+		if tx.State >= proto.TransactionState_SENT_TO_BABYLON {
+			return fmt.Errorf("delegation in state %s was already sent to babylon: %w", tx.State, ErrUnexpectedTransactionState)
+		}
+
+		tx.UnbondingTimeOverride = uint32(unbondingTime)
+		tx.UnbondingFeeRateOverride = int64(feeRate)
+		return nil
+	}
+
+	return c.setTxState(txHash, setOverrides)
+}
+
+// SetTxWithdrawalAddress sets address receiving withdrawn funds, empty address
+// restores withdrawal to staker address
+func (c *TrackedTransactionStore) SetTxWithdrawalAddress(txHash *chainhash.Hash, address string) error {
+	setAddress := func(tx *proto.TrackedTransaction) error {
+		if tx.State == proto.TransactionState_SPENT_ON_BTC {
+			return fmt.Errorf("staked funds were already withdrawn: %w", ErrUnexpectedTransactionState)
+		}
+
+		tx.WithdrawalAddress = address
+		return nil
+	}
+
+	return c.setTxState(txHash, setAddress)
+}
+
+// AddTxBabylonResponse appends response of babylon to transaction sent for the
+// delegation. Only the last MaxBabylonTxResponses responses are kept.
+func (c *TrackedTransactionStore) AddTxBabylonResponse(txHash *chainhash.Hash, resp *BabylonTxResponse) error {
+	addResponse := func(tx *proto.TrackedTransaction) error {
+		tx.BabylonTxResponses = append(tx.BabylonTxResponses, &proto.BabylonTxResponse{
+			MsgType:   resp.MsgType,
+			TxHash:    resp.TxHash,
+			Height:    resp.Height,
+			Codespace: resp.Codespace,
+			Code:      resp.Code,
+			Timestamp: resp.Timestamp.Unix(),
+		})
+
+		if len(tx.BabylonTxResponses) > MaxBabylonTxResponses {
+			tx.BabylonTxResponses = tx.BabylonTxResponses[len(tx.BabylonTxResponses)-MaxBabylonTxResponses:]
+		}
+
+		return nil
+	}
+
+	return c.setTxState(txHash, addResponse)
+}
+
+// SetTxAutoWithdraw enables or disables automatic withdrawal of the delegation
+func (c *TrackedTransactionStore) SetTxAutoWithdraw(txHash *chainhash.Hash, autoWithdraw bool) error {
+	setAutoWithdraw := func(tx *proto.TrackedTransaction) error {
+		if autoWithdraw && tx.State == proto.TransactionState_SPENT_ON_BTC {
+			return fmt.Errorf("staked funds were already withdrawn: %w", ErrUnexpectedTransactionState)
+		}
+
+		tx.AutoWithdraw = autoWithdraw
+		return nil
+	}
+
+	return c.setTxState(txHash, setAutoWithdraw)
+}
+
+// SetTxSlashingTxHashes records hashes of slashing transactions of staking and
+// unbonding output sent to babylon with the delegation
+func (c *TrackedTransactionStore) SetTxSlashingTxHashes(
+	txHash *chainhash.Hash,
+	slashingTxHash *chainhash.Hash,
+	unbondingSlashingTxHash *chainhash.Hash,
+) error {
+	setSlashingTxHashes := func(tx *proto.TrackedTransaction) error {
+		tx.SlashingTxHash = slashingTxHash.CloneBytes()
+		tx.UnbondingSlashingTxHash = unbondingSlashingTxHash.CloneBytes()
+		return nil
+	}
+
+	return c.setTxState(txHash, setSlashingTxHashes)
+}
+
+// AddTxCpfpChild records child transaction which paid for staking transaction.
+// Child is already sent at this point, so it is recorded even if staking
+// transaction got confirmed in the meantime.
+func (c *TrackedTransactionStore) AddTxCpfpChild(txHash *chainhash.Hash, childTxHash *chainhash.Hash) error {
+	addCpfpChild := func(tx *proto.TrackedTransaction) error {
+		tx.CpfpChildTxHashes = append(tx.CpfpChildTxHashes, childTxHash.CloneBytes())
+		return nil
+	}
+
+	return c.setTxState(txHash, addCpfpChild)
+}
+
+// AddTxNote appends operator note to the delegation. Note does not change state
+// of the delegation, so it can be added in any state.
+func (c *TrackedTransactionStore) AddTxNote(txHash *chainhash.Hash, note *DelegationNote) error {
+	addNote := func(tx *proto.TrackedTransaction) error {
+		if len(tx.Notes) >= MaxDelegationNotes {
+			return fmt.Errorf("delegation already has %d notes: %w", len(tx.Notes), ErrTooManyDelegationNotes)
+		}
+
+		tx.Notes = append(tx.Notes, &proto.DelegationNote{
+			Timestamp: note.Timestamp.Unix(),
+			Text:      note.Text,
+			RequestId: note.RequestId,
+		})
+
+		return nil
+	}
+
+	return c.setTxState(txHash, addNote)
+}
+
+// OverrideTxState moves transaction from expectedState to newState without
+// checking whether the transition is valid. Unbonding data is created when
+// delegation is sent to babylon, so it is cleared when transaction is moved to
+// a state before SENT_TO_BABYLON.
+func (c *TrackedTransactionStore) OverrideTxState(
+	txHash *chainhash.Hash,
+	expectedState proto.TransactionState,
+	newState proto.TransactionState,
+) error {
+	overrideTxState := func(tx *proto.TrackedTransaction) error {
+		if tx.State != expectedState {
+			return fmt.Errorf("transaction is in state %s, expected %s: %w", tx.State, expectedState, ErrUnexpectedTransactionState)
+		}
+
+		tx.State = newState
+
+		if newState < proto.TransactionState_SENT_TO_BABYLON {
+			tx.UnbondingTxData = nil
+		}
+
+		return nil
+	}
+
+	return c.setTxState(txHash, overrideTxState)
+}
+
+// DeleteTransaction permanently removes transaction together with its metadata
+// and watched data. Transaction must be in expectedState. In the same db
+// transaction it removes all other data referencing the transaction: scheduled
+// operations, retry queue entries, fee spends of its staking, unbonding, spend
+// and cpfp child transactions, its frozen outputs and musig2 sessions whose id
+// contains its hash. Only audit log keeps the hash.
+func (c *TrackedTransactionStore) DeleteTransaction(
+	txHash *chainhash.Hash,
+	expectedState proto.TransactionState,
+) error {
+	txHashBytes := txHash.CloneBytes()
+
+	return kvdb.Batch(c.db, func(tx kvdb.RwTx) error {
+		transactionIdxBucket := tx.ReadWriteBucket(transactionIndexName)
+
+		if transactionIdxBucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		transactionsBucket := tx.ReadWriteBucket(transactionBucketName)
+		if transactionsBucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		watchedTxBucket := tx.ReadWriteBucket(watchedTxDataBucketName)
+		if watchedTxBucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		maybeTx, txKey, err := getTxByHash(txHashBytes, transactionIdxBucket, transactionsBucket)
+
+		if err != nil {
+			return err
+		}
+
+		var storedTx proto.TrackedTransaction
+		if err := pm.Unmarshal(maybeTx, &storedTx); err != nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		if storedTx.State != expectedState {
+			return fmt.Errorf("transaction is in state %s, expected %s: %w", storedTx.State, expectedState, ErrUnexpectedTransactionState)
+		}
+
+		if err := transactionsBucket.Delete(txKey); err != nil {
+			return err
+		}
+
+		if err := transactionIdxBucket.Delete(txHashBytes); err != nil {
+			return err
+		}
+
+		if err := watchedTxBucket.Delete(txHashBytes); err != nil {
+			return err
+		}
+
+		if err := deleteTimeIdx(tx, txKey, &storedTx); err != nil {
+			return err
+		}
+
+		if err := deleteTxReferences(tx, txHash, &storedTx); err != nil {
+			return err
+		}
+
+		return transactionIdxBucket.Put(numPurgedTxKey, uint64KeyToBytes(getNumPurgedTx(transactionIdxBucket)+1))
+	})
+}
+
+// deleteTxReferences removes data of other stores referencing staking
+// transaction storedTx, as part of rwTx
+func deleteTxReferences(rwTx kvdb.RwTx, txHash *chainhash.Hash, storedTx *proto.TrackedTransaction) error {
+	txHashBytes := txHash.CloneBytes()
+
+	if err := deleteScheduledOperationsOfTx(rwTx, txHashBytes); err != nil {
+		return err
+	}
+
+	if err := deleteRetryEntriesOfTx(rwTx, txHashBytes); err != nil {
+		return err
+	}
+
+	if err := deleteFrozenOutputsOfTx(rwTx, txHashBytes); err != nil {
+		return err
+	}
+
+	if err := deleteMuSig2NoncesOfTx(rwTx, txHash.String()); err != nil {
+		return err
+	}
+
+	feeSpendTxs := [][]byte{txHashBytes}
+
+	if storedTx.UnbondingTxData != nil && len(storedTx.UnbondingTxData.UnbondingTransaction) > 0 {
+		var unbondingTx wire.MsgTx
+		err := unbondingTx.Deserialize(bytes.NewReader(storedTx.UnbondingTxData.UnbondingTransaction))
+
+		if err != nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		unbondingTxHash := unbondingTx.TxHash()
+		feeSpendTxs = append(feeSpendTxs, unbondingTxHash[:])
+	}
+
+	if len(storedTx.SpendTxHash) > 0 {
+		feeSpendTxs = append(feeSpendTxs, storedTx.SpendTxHash)
+	}
+
+	feeSpendTxs = append(feeSpendTxs, storedTx.CpfpChildTxHashes...)
+
+	return deleteFeeSpendsOfTxs(rwTx, feeSpendTxs)
+}
+
+func (c *TrackedTransactionStore) SetTxSpentOnBtc(
+	txHash *chainhash.Hash,
+	spendTxHash *chainhash.Hash,
+	spendTxFee btcutil.Amount,
+) error {
 	setTxSpentOnBtc := func(tx *proto.TrackedTransaction) error {
 		tx.State = proto.TransactionState_SPENT_ON_BTC
+		tx.SpendTxHash = spendTxHash.CloneBytes()
+		tx.SpendTxFee = int64(spendTxFee)
 		return nil
 	}
 
@@ -967,25 +1732,69 @@ func (c *TrackedTransactionStore) QueryStoredTransactions(q StoredTransactionQue
 			return nil
 		}
 
-		resp.Total = numTransactions
+		resp.TotalStored = numTransactions
+
+		if !q.hasFilters() {
+			resp.Total = numTransactions
+		}
+
+		txKeys, err := q.txKeysFilter(tx)
+
+		if err != nil {
+			return err
+		}
+
+		indexOffset := q.IndexOffset
+
+		if txKeys != nil {
+			if len(txKeys) == 0 {
+				return nil
+			}
+
+			// no need to read transactions before the first one in time range
+			if !q.Reversed {
+				minKey := uint64(math.MaxUint64)
+				for k := range txKeys {
+					if k < minKey {
+						minKey = k
+					}
+				}
+
+				if minKey-1 > indexOffset {
+					indexOffset = minKey - 1
+				}
+			}
+		}
 
 		paginator := newPaginator(
-			transactionsBucket.ReadCursor(), q.Reversed, q.IndexOffset,
+			transactionsBucket.ReadCursor(), q.Reversed, indexOffset,
 			q.NumMaxTransactions,
 		)
 
-		accumulateTransactions := func(key, transaction []byte) (bool, error) {
+		// matchingTransaction decodes transaction and returns it only if it
+		// matches all filters of the query
+		matchingTransaction := func(key, transaction []byte) (*StoredTransaction, error) {
+			if txKeys != nil {
+				if _, found := txKeys[binary.BigEndian.Uint64(key)]; !found {
+					return nil, nil
+				}
+			}
+
 			protoTx := proto.TrackedTransaction{}
 
 			err := pm.Unmarshal(transaction, &protoTx)
 			if err != nil {
-				return false, err
+				return nil, err
 			}
 
 			txFromDb, err := protoTxToStoredTransaction(&protoTx)
 
 			if err != nil {
-				return false, err
+				return nil, err
+			}
+
+			if !q.matchesMetadataFilter(txFromDb) || !q.matchesGroupFilter(txFromDb) || !q.matchesMatchFunc(txFromDb) {
+				return nil, nil
 			}
 
 			// we have query only for withdrawable transaction i.e transactions which
@@ -994,10 +1803,10 @@ func (c *TrackedTransactionStore) QueryStoredTransactions(q StoredTransactionQue
 				var confirmationHeight uint32
 				var scriptTimeLock uint16
 
-				if txFromDb.Watched {
+				if txFromDb.WatchOnly() {
 					// cannot withdraw watched transaction directly through staker program
 					// at least for now.
-					return false, nil
+					return nil, nil
 				}
 
 				if txFromDb.StakingTxConfirmedOnBtc() {
@@ -1007,7 +1816,7 @@ func (c *TrackedTransactionStore) QueryStoredTransactions(q StoredTransactionQue
 					scriptTimeLock = txFromDb.UnbondingTxData.UnbondingTime
 					confirmationHeight = txFromDb.UnbondingTxData.UnbondingTxConfirmationInfo.Height
 				} else {
-					return false, nil
+					return nil, nil
 				}
 
 				timeLockExpired := isTimeLockExpired(
@@ -1016,22 +1825,74 @@ func (c *TrackedTransactionStore) QueryStoredTransactions(q StoredTransactionQue
 					q.withdrawableTransactionsFilter.currentBestBlockHeight,
 				)
 
-				if timeLockExpired {
-					resp.Transactions = append(resp.Transactions, *txFromDb)
-					return true, nil
-				} else {
-					return false, nil
+				if !timeLockExpired {
+					return nil, nil
 				}
-			} else {
-				resp.Transactions = append(resp.Transactions, *txFromDb)
-				return true, nil
 			}
+
+			return txFromDb, nil
+		}
+
+		accumulateTransactions := func(key, transaction []byte) (bool, error) {
+			txFromDb, err := matchingTransaction(key, transaction)
+
+			if err != nil || txFromDb == nil {
+				return false, err
+			}
+
+			resp.Transactions = append(resp.Transactions, *txFromDb)
+			return true, nil
 		}
 
 		if err := paginator.query(accumulateTransactions); err != nil {
 			return err
 		}
 
+		// filtered queries need to check transactions to count matching ones.
+		// With time range filter only transactions from time indexes are
+		// candidates, and if there is no other filter all of them match.
+		switch {
+		case txKeys != nil && !q.hasTransactionFilters():
+			resp.Total = uint64(len(txKeys))
+		case txKeys != nil:
+			for k := range txKeys {
+				key := uint64KeyToBytes(k)
+				transaction := transactionsBucket.Get(key)
+
+				if transaction == nil {
+					return ErrCorruptedTransactionsDb
+				}
+
+				txFromDb, err := matchingTransaction(key, transaction)
+
+				if err != nil {
+					return err
+				}
+
+				if txFromDb != nil {
+					resp.Total++
+				}
+			}
+		case q.hasFilters():
+			err = transactionsBucket.ForEach(func(key, transaction []byte) error {
+				txFromDb, err := matchingTransaction(key, transaction)
+
+				if err != nil {
+					return err
+				}
+
+				if txFromDb != nil {
+					resp.Total++
+				}
+
+				return nil
+			})
+
+			if err != nil {
+				return err
+			}
+		}
+
 		if q.Reversed {
 			numTx := len(resp.Transactions)
 			for i := 0; i < numTx/2; i++ {
@@ -2,8 +2,11 @@ package stakerdb_test
 
 import (
 	"bytes"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"math/rand"
+	"strconv"
 	"testing"
 	"time"
 
@@ -16,6 +19,8 @@ import (
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/kvdb"
 	"github.com/stretchr/testify/require"
 )
 
@@ -87,6 +92,11 @@ func genStoredTransaction(t *testing.T, r *rand.Rand, maxStakingTime uint16) *st
 			BtcSigOverBabylonSig: datagen.GenRandomByteArray(r, 64),
 		},
 		StakerAddress: stakerAddr.String(),
+		Metadata: map[string]string{
+			"batch": strconv.Itoa(r.Intn(3)),
+		},
+		StakingTxFee: btcutil.Amount(r.Int63n(100000)),
+		RequestId:    hex.EncodeToString(datagen.GenRandomByteArray(r, 16)),
 	}
 }
 
@@ -131,6 +141,9 @@ func FuzzStoringTxs(f *testing.F) {
 				storedTx.FinalityProvidersBtcPks,
 				storedTx.Pop,
 				stakerAddr,
+				storedTx.Metadata,
+				storedTx.StakingTxFee,
+				storedTx.RequestId,
 			)
 			require.NoError(t, err)
 		}
@@ -141,10 +154,13 @@ func FuzzStoringTxs(f *testing.F) {
 			require.NoError(t, err)
 			require.Equal(t, storedTx.StakingTx, tx.StakingTx)
 			require.Equal(t, storedTx.StakingOutputIndex, tx.StakingOutputIndex)
+			require.Equal(t, storedTx.RequestId, tx.RequestId)
 			require.Equal(t, storedTx.StakingTime, tx.StakingTime)
 			require.True(t, pubKeysSliceEqual(storedTx.FinalityProvidersBtcPks, tx.FinalityProvidersBtcPks))
 			require.Equal(t, storedTx.Pop, tx.Pop)
 			require.Equal(t, storedTx.StakerAddress, tx.StakerAddress)
+			require.Equal(t, storedTx.Metadata, tx.Metadata)
+			require.Equal(t, storedTx.StakingTxFee, tx.StakingTxFee)
 			require.Equal(t, expectedIdx, tx.StoredTransactionIdx)
 			expectedIdx++
 		}
@@ -190,6 +206,9 @@ func TestStateTransitions(t *testing.T) {
 		tx.FinalityProvidersBtcPks,
 		tx.Pop,
 		stakerAddr,
+		tx.Metadata,
+		tx.StakingTxFee,
+		tx.RequestId,
 	)
 	require.NoError(t, err)
 
@@ -219,16 +238,420 @@ func TestStateTransitions(t *testing.T) {
 	require.Equal(t, proto.TransactionState_SENT_TO_BABYLON, storedTx.State)
 
 	// Spent on BTC
-	err = s.SetTxSpentOnBtc(&txHash)
+	spendTxHash := datagen.GenRandomBtcdHash(r)
+	spendTxFee := btcutil.Amount(r.Int63n(100000))
+	err = s.SetTxSpentOnBtc(&txHash, &spendTxHash, spendTxFee)
 	require.NoError(t, err)
 	storedTx, err = s.GetTransaction(&txHash)
 	require.NoError(t, err)
 	require.Equal(t, proto.TransactionState_SPENT_ON_BTC, storedTx.State)
+	require.Equal(t, spendTxFee, storedTx.SpendTxFee)
+	require.NotNil(t, storedTx.SpendTxHash)
+	require.True(t, spendTxHash.IsEqual(storedTx.SpendTxHash))
+	// every state change is recorded in history
+	require.Len(t, storedTx.StateTransitions, 4)
+	require.Equal(t, proto.TransactionState_SENT_TO_BTC, storedTx.StateTransitions[0].State)
+	require.Equal(t, proto.TransactionState_SPENT_ON_BTC, storedTx.StateTransitions[3].State)
+	_, found := storedTx.StateTimestamp(proto.TransactionState_CONFIRMED_ON_BTC)
+	require.True(t, found)
+	_, found = storedTx.StateTimestamp(proto.TransactionState_DELEGATION_ACTIVE)
+	require.False(t, found)
 	require.NotNil(t, storedTx.UnbondingTxData)
 	require.Equal(t, tx.StakingTx, storedTx.UnbondingTxData.UnbondingTx)
 	require.Equal(t, tx.StakingTime, storedTx.UnbondingTxData.UnbondingTime)
 }
 
+func TestOverrideTxState(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	s := MakeTestStore(t)
+	tx := genStoredTransaction(t, r, 200)
+	stakerAddr, err := btcutil.DecodeAddress(tx.StakerAddress, &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	txHash := tx.StakingTx.TxHash()
+	err = s.AddTransaction(
+		tx.StakingTx,
+		tx.StakingOutputIndex,
+		tx.StakingTime,
+		tx.FinalityProvidersBtcPks,
+		tx.Pop,
+		stakerAddr,
+		tx.Metadata,
+		tx.StakingTxFee,
+		tx.RequestId,
+	)
+	require.NoError(t, err)
+
+	hash := datagen.GenRandomBtcdHash(r)
+	err = s.SetTxConfirmed(&txHash, &hash, r.Uint32())
+	require.NoError(t, err)
+	err = s.SetTxSentToBabylon(&txHash, tx.StakingTx, tx.StakingTime)
+	require.NoError(t, err)
+
+	// override is rejected if transaction is not in expected state
+	err = s.OverrideTxState(&txHash, proto.TransactionState_DELEGATION_ACTIVE, proto.TransactionState_SPENT_ON_BTC)
+	require.ErrorIs(t, err, stakerdb.ErrUnexpectedTransactionState)
+
+	err = s.OverrideTxState(&txHash, proto.TransactionState_SENT_TO_BABYLON, proto.TransactionState_CONFIRMED_ON_BTC)
+	require.NoError(t, err)
+	storedTx, err := s.GetTransaction(&txHash)
+	require.NoError(t, err)
+	require.Equal(t, proto.TransactionState_CONFIRMED_ON_BTC, storedTx.State)
+	require.Nil(t, storedTx.UnbondingTxData)
+
+	// delegation can be sent to babylon again
+	err = s.SetTxSentToBabylon(&txHash, tx.StakingTx, tx.StakingTime)
+	require.NoError(t, err)
+	storedTx, err = s.GetTransaction(&txHash)
+	require.NoError(t, err)
+	require.Equal(t, proto.TransactionState_SENT_TO_BABYLON, storedTx.State)
+	require.Len(t, storedTx.StateTransitions, 5)
+}
+
+func TestUnbondingOverrides(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	s := MakeTestStore(t)
+	tx := genStoredTransaction(t, r, 200)
+	stakerAddr, err := btcutil.DecodeAddress(tx.StakerAddress, &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	txHash := tx.StakingTx.TxHash()
+	err = s.AddTransaction(
+		tx.StakingTx,
+		tx.StakingOutputIndex,
+		tx.StakingTime,
+		tx.FinalityProvidersBtcPks,
+		tx.Pop,
+		stakerAddr,
+		tx.Metadata,
+		tx.StakingTxFee,
+		tx.RequestId,
+	)
+	require.NoError(t, err)
+
+	err = s.SetTxUnbondingOverrides(&txHash, 500, btcutil.Amount(3000))
+	require.NoError(t, err)
+
+	withdrawalAddr, err := datagen.GenRandomBTCAddress(r, &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	err = s.SetTxWithdrawalAddress(&txHash, withdrawalAddr.EncodeAddress())
+	require.NoError(t, err)
+
+	storedTx, err := s.GetTransaction(&txHash)
+	require.NoError(t, err)
+	require.Equal(t, uint16(500), storedTx.UnbondingTimeOverride)
+	require.Equal(t, btcutil.Amount(3000), storedTx.UnbondingFeeRateOverride)
+	require.Equal(t, withdrawalAddr.EncodeAddress(), storedTx.WithdrawalAddress)
+	// overrides do not change state of the transaction
+	require.Equal(t, proto.TransactionState_SENT_TO_BTC, storedTx.State)
+	require.Len(t, storedTx.StateTransitions, 1)
+
+	hash := datagen.GenRandomBtcdHash(r)
+	err = s.SetTxConfirmed(&txHash, &hash, r.Uint32())
+	require.NoError(t, err)
+	err = s.SetTxSentToBabylon(&txHash, tx.StakingTx, tx.StakingTime)
+	require.NoError(t, err)
+
+	// unbonding transaction already exists
+	err = s.SetTxUnbondingOverrides(&txHash, 0, 0)
+	require.ErrorIs(t, err, stakerdb.ErrUnexpectedTransactionState)
+
+	// withdrawal address can be changed until funds are withdrawn
+	err = s.SetTxWithdrawalAddress(&txHash, "")
+	require.NoError(t, err)
+	storedTx, err = s.GetTransaction(&txHash)
+	require.NoError(t, err)
+	require.Empty(t, storedTx.WithdrawalAddress)
+	require.Equal(t, uint16(500), storedTx.UnbondingTimeOverride)
+}
+
+func TestAutoWithdraw(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	s := MakeTestStore(t)
+	tx := genStoredTransaction(t, r, 200)
+	stakerAddr, err := btcutil.DecodeAddress(tx.StakerAddress, &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	txHash := tx.StakingTx.TxHash()
+	err = s.AddTransaction(
+		tx.StakingTx,
+		tx.StakingOutputIndex,
+		tx.StakingTime,
+		tx.FinalityProvidersBtcPks,
+		tx.Pop,
+		stakerAddr,
+		tx.Metadata,
+		tx.StakingTxFee,
+		tx.RequestId,
+	)
+	require.NoError(t, err)
+
+	storedTx, err := s.GetTransaction(&txHash)
+	require.NoError(t, err)
+	require.False(t, storedTx.AutoWithdraw)
+
+	err = s.SetTxAutoWithdraw(&txHash, true)
+	require.NoError(t, err)
+
+	storedTx, err = s.GetTransaction(&txHash)
+	require.NoError(t, err)
+	require.True(t, storedTx.AutoWithdraw)
+	// flag does not change state of the transaction
+	require.Equal(t, proto.TransactionState_SENT_TO_BTC, storedTx.State)
+	require.Len(t, storedTx.StateTransitions, 1)
+
+	spendTxHash := datagen.GenRandomBtcdHash(r)
+	err = s.SetTxSpentOnBtc(&txHash, &spendTxHash, 0)
+	require.NoError(t, err)
+
+	// funds were already withdrawn, so flag cannot be set anymore and failed
+	// call leaves previously set flag untouched
+	err = s.SetTxAutoWithdraw(&txHash, true)
+	require.ErrorIs(t, err, stakerdb.ErrUnexpectedTransactionState)
+
+	storedTx, err = s.GetTransaction(&txHash)
+	require.NoError(t, err)
+	require.True(t, storedTx.AutoWithdraw)
+
+	// clearing the flag is still allowed after spend
+	err = s.SetTxAutoWithdraw(&txHash, false)
+	require.NoError(t, err)
+
+	storedTx, err = s.GetTransaction(&txHash)
+	require.NoError(t, err)
+	require.False(t, storedTx.AutoWithdraw)
+	require.Equal(t, proto.TransactionState_SPENT_ON_BTC, storedTx.State)
+}
+
+func TestBabylonTxResponses(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	s := MakeTestStore(t)
+	tx := genStoredTransaction(t, r, 200)
+	stakerAddr, err := btcutil.DecodeAddress(tx.StakerAddress, &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	txHash := tx.StakingTx.TxHash()
+	err = s.AddTransaction(
+		tx.StakingTx,
+		tx.StakingOutputIndex,
+		tx.StakingTime,
+		tx.FinalityProvidersBtcPks,
+		tx.Pop,
+		stakerAddr,
+		tx.Metadata,
+		tx.StakingTxFee,
+		tx.RequestId,
+	)
+	require.NoError(t, err)
+
+	failed := &stakerdb.BabylonTxResponse{
+		MsgType:   "/babylon.btcstaking.v1.MsgCreateBTCDelegation",
+		TxHash:    "AB01",
+		Height:    100,
+		Codespace: "btcstaking",
+		Code:      5,
+		Timestamp: time.Unix(1000, 0),
+	}
+	err = s.AddTxBabylonResponse(&txHash, failed)
+	require.NoError(t, err)
+
+	for i := 0; i < stakerdb.MaxBabylonTxResponses; i++ {
+		err = s.AddTxBabylonResponse(&txHash, &stakerdb.BabylonTxResponse{
+			MsgType:   failed.MsgType,
+			TxHash:    fmt.Sprintf("CD%02d", i),
+			Height:    int64(101 + i),
+			Timestamp: time.Unix(int64(1001+i), 0),
+		})
+		require.NoError(t, err)
+	}
+
+	storedTx, err := s.GetTransaction(&txHash)
+	require.NoError(t, err)
+	// the oldest response is dropped
+	require.Len(t, storedTx.BabylonTxResponses, stakerdb.MaxBabylonTxResponses)
+	require.Equal(t, "CD00", storedTx.BabylonTxResponses[0].TxHash)
+	require.Equal(t, int64(101), storedTx.BabylonTxResponses[0].Height)
+	require.Equal(t, time.Unix(1001, 0), storedTx.BabylonTxResponses[0].Timestamp)
+	// responses do not change state of the transaction
+	require.Equal(t, proto.TransactionState_SENT_TO_BTC, storedTx.State)
+	require.Len(t, storedTx.StateTransitions, 1)
+
+	unknownHash := datagen.GenRandomBtcdHash(r)
+	err = s.AddTxBabylonResponse(&unknownHash, failed)
+	require.ErrorIs(t, err, stakerdb.ErrTransactionNotFound)
+}
+
+func TestDelegationNotes(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	s := MakeTestStore(t)
+	tx := genStoredTransaction(t, r, 200)
+	stakerAddr, err := btcutil.DecodeAddress(tx.StakerAddress, &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	txHash := tx.StakingTx.TxHash()
+	err = s.AddTransaction(
+		tx.StakingTx,
+		tx.StakingOutputIndex,
+		tx.StakingTime,
+		tx.FinalityProvidersBtcPks,
+		tx.Pop,
+		stakerAddr,
+		tx.Metadata,
+		tx.StakingTxFee,
+		tx.RequestId,
+	)
+	require.NoError(t, err)
+
+	for i := 0; i < stakerdb.MaxDelegationNotes; i++ {
+		err = s.AddTxNote(&txHash, &stakerdb.DelegationNote{
+			Timestamp: time.Unix(int64(1000+i), 0),
+			Text:      fmt.Sprintf("note %d", i),
+			RequestId: fmt.Sprintf("req-%d", i),
+		})
+		require.NoError(t, err)
+	}
+
+	// notes are never dropped, so new note is rejected
+	err = s.AddTxNote(&txHash, &stakerdb.DelegationNote{
+		Timestamp: time.Unix(2000, 0),
+		Text:      "rejected",
+	})
+	require.ErrorIs(t, err, stakerdb.ErrTooManyDelegationNotes)
+
+	storedTx, err := s.GetTransaction(&txHash)
+	require.NoError(t, err)
+	require.Len(t, storedTx.Notes, stakerdb.MaxDelegationNotes)
+	require.Equal(t, "note 0", storedTx.Notes[0].Text)
+	require.Equal(t, "req-0", storedTx.Notes[0].RequestId)
+	require.Equal(t, time.Unix(1000, 0), storedTx.Notes[0].Timestamp)
+	// notes do not change state of the transaction
+	require.Equal(t, proto.TransactionState_SENT_TO_BTC, storedTx.State)
+	require.Len(t, storedTx.StateTransitions, 1)
+
+	unknownHash := datagen.GenRandomBtcdHash(r)
+	err = s.AddTxNote(&unknownHash, &stakerdb.DelegationNote{Text: "note"})
+	require.ErrorIs(t, err, stakerdb.ErrTransactionNotFound)
+}
+
+func TestRelatedTxHashes(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	s := MakeTestStore(t)
+	tx := genStoredTransaction(t, r, 200)
+	stakerAddr, err := btcutil.DecodeAddress(tx.StakerAddress, &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	txHash := tx.StakingTx.TxHash()
+	err = s.AddTransaction(
+		tx.StakingTx,
+		tx.StakingOutputIndex,
+		tx.StakingTime,
+		tx.FinalityProvidersBtcPks,
+		tx.Pop,
+		stakerAddr,
+		tx.Metadata,
+		tx.StakingTxFee,
+		tx.RequestId,
+	)
+	require.NoError(t, err)
+
+	// related transactions are unknown until they are recorded
+	storedTx, err := s.GetTransaction(&txHash)
+	require.NoError(t, err)
+	require.Nil(t, storedTx.SpendTxHash)
+	require.Nil(t, storedTx.SlashingTxHash)
+	require.Nil(t, storedTx.UnbondingSlashingTxHash)
+	require.Empty(t, storedTx.CpfpChildTxHashes)
+
+	firstChild := datagen.GenRandomBtcdHash(r)
+	secondChild := datagen.GenRandomBtcdHash(r)
+	err = s.AddTxCpfpChild(&txHash, &firstChild)
+	require.NoError(t, err)
+	err = s.AddTxCpfpChild(&txHash, &secondChild)
+	require.NoError(t, err)
+
+	slashingTxHash := datagen.GenRandomBtcdHash(r)
+	unbondingSlashingTxHash := datagen.GenRandomBtcdHash(r)
+	err = s.SetTxSlashingTxHashes(&txHash, &slashingTxHash, &unbondingSlashingTxHash)
+	require.NoError(t, err)
+
+	storedTx, err = s.GetTransaction(&txHash)
+	require.NoError(t, err)
+	require.Equal(t, []chainhash.Hash{firstChild, secondChild}, storedTx.CpfpChildTxHashes)
+	require.True(t, slashingTxHash.IsEqual(storedTx.SlashingTxHash))
+	require.True(t, unbondingSlashingTxHash.IsEqual(storedTx.UnbondingSlashingTxHash))
+	// recorded hashes do not change state of the transaction
+	require.Equal(t, proto.TransactionState_SENT_TO_BTC, storedTx.State)
+	require.Len(t, storedTx.StateTransitions, 1)
+
+	unknownHash := datagen.GenRandomBtcdHash(r)
+	err = s.AddTxCpfpChild(&unknownHash, &firstChild)
+	require.ErrorIs(t, err, stakerdb.ErrTransactionNotFound)
+}
+
+func TestDeleteTransaction(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	s := MakeTestStore(t)
+	txs := genNStoredTransactions(t, r, 3, 200)
+
+	for _, tx := range txs {
+		stakerAddr, err := btcutil.DecodeAddress(tx.StakerAddress, &chaincfg.MainNetParams)
+		require.NoError(t, err)
+		err = s.AddTransaction(
+			tx.StakingTx,
+			tx.StakingOutputIndex,
+			tx.StakingTime,
+			tx.FinalityProvidersBtcPks,
+			tx.Pop,
+			stakerAddr,
+			tx.Metadata,
+			tx.StakingTxFee,
+			tx.RequestId,
+		)
+		require.NoError(t, err)
+	}
+
+	txHash := txs[1].StakingTx.TxHash()
+
+	// only transactions in expected state can be deleted
+	err := s.DeleteTransaction(&txHash, proto.TransactionState_SPENT_ON_BTC)
+	require.ErrorIs(t, err, stakerdb.ErrUnexpectedTransactionState)
+
+	spendTxHash := datagen.GenRandomBtcdHash(r)
+	err = s.SetTxSpentOnBtc(&txHash, &spendTxHash, 0)
+	require.NoError(t, err)
+	err = s.DeleteTransaction(&txHash, proto.TransactionState_SPENT_ON_BTC)
+	require.NoError(t, err)
+
+	_, err = s.GetTransaction(&txHash)
+	require.ErrorIs(t, err, stakerdb.ErrTransactionNotFound)
+
+	err = s.DeleteTransaction(&txHash, proto.TransactionState_SPENT_ON_BTC)
+	require.ErrorIs(t, err, stakerdb.ErrTransactionNotFound)
+
+	query := stakerdb.DefaultStoredTransactionQuery()
+	result, err := s.QueryStoredTransactions(query)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), result.Total)
+	require.Len(t, result.Transactions, 2)
+	require.Equal(t, txs[0].StakingTx.TxHash(), result.Transactions[0].StakingTx.TxHash())
+	require.Equal(t, txs[2].StakingTx.TxHash(), result.Transactions[1].StakingTx.TxHash())
+
+	// indexes of deleted transactions are not reused
+	tx := genStoredTransaction(t, r, 200)
+	stakerAddr, err := btcutil.DecodeAddress(tx.StakerAddress, &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	err = s.AddTransaction(
+		tx.StakingTx,
+		tx.StakingOutputIndex,
+		tx.StakingTime,
+		tx.FinalityProvidersBtcPks,
+		tx.Pop,
+		stakerAddr,
+		tx.Metadata,
+		tx.StakingTxFee,
+		tx.RequestId,
+	)
+	require.NoError(t, err)
+	newTxHash := tx.StakingTx.TxHash()
+	storedTx, err := s.GetTransaction(&newTxHash)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), storedTx.StoredTransactionIdx)
+}
+
 func TestPaginator(t *testing.T) {
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 	s := MakeTestStore(t)
@@ -247,6 +670,9 @@ func TestPaginator(t *testing.T) {
 			storedTx.FinalityProvidersBtcPks,
 			storedTx.Pop,
 			stakerAddr,
+			storedTx.Metadata,
+			storedTx.StakingTxFee,
+			storedTx.RequestId,
 		)
 		require.NoError(t, err)
 	}
@@ -317,6 +743,9 @@ func FuzzQuerySpendableTx(f *testing.F) {
 				storedTx.FinalityProvidersBtcPks,
 				storedTx.Pop,
 				stakerAddr,
+				storedTx.Metadata,
+				storedTx.StakingTxFee,
+				storedTx.RequestId,
 			)
 			require.NoError(t, err)
 		}
@@ -350,7 +779,8 @@ func FuzzQuerySpendableTx(f *testing.F) {
 		storedResult, err = s.QueryStoredTransactions(filteredQuery)
 		require.NoError(t, err)
 		require.Len(t, storedResult.Transactions, len(hashesWithExpiredTimeLock))
-		require.Equal(t, storedResult.Total, uint64(maxCreatedTx))
+		require.Equal(t, uint64(maxCreatedTx), storedResult.TotalStored)
+		require.Equal(t, uint64(len(hashesWithExpiredTimeLock)), storedResult.Total)
 
 		for _, storedTx := range stored {
 			txHash := storedTx.StakingTx.TxHash()
@@ -365,7 +795,8 @@ func FuzzQuerySpendableTx(f *testing.F) {
 		storedResult, err = s.QueryStoredTransactions(filteredQuery)
 		require.NoError(t, err)
 		require.Len(t, storedResult.Transactions, len(hashesWithExpiredTimeLock))
-		require.Equal(t, storedResult.Total, uint64(maxCreatedTx))
+		require.Equal(t, uint64(maxCreatedTx), storedResult.TotalStored)
+		require.Equal(t, uint64(len(hashesWithExpiredTimeLock)), storedResult.Total)
 
 		for _, storedTx := range stored {
 			txHash := storedTx.StakingTx.TxHash()
@@ -381,6 +812,584 @@ func FuzzQuerySpendableTx(f *testing.F) {
 		storedResult, err = s.QueryStoredTransactions(filteredQuery)
 		require.NoError(t, err)
 		require.Len(t, storedResult.Transactions, len(hashesWithExpiredTimeLock))
-		require.Equal(t, storedResult.Total, uint64(maxCreatedTx))
+		require.Equal(t, uint64(maxCreatedTx), storedResult.TotalStored)
+		require.Equal(t, uint64(len(hashesWithExpiredTimeLock)), storedResult.Total)
+	})
+}
+
+func TestMetadataFilter(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	s := MakeTestStore(t)
+	numTx := 30
+	generatedStoredTxs := genNStoredTransactions(t, r, numTx, 200)
+
+	expectedInBatch := make(map[string]int)
+	for _, storedTx := range generatedStoredTxs {
+		stakerAddr, err := btcutil.DecodeAddress(storedTx.StakerAddress, &chaincfg.MainNetParams)
+		require.NoError(t, err)
+		err = s.AddTransaction(
+			storedTx.StakingTx,
+			storedTx.StakingOutputIndex,
+			storedTx.StakingTime,
+			storedTx.FinalityProvidersBtcPks,
+			storedTx.Pop,
+			stakerAddr,
+			storedTx.Metadata,
+			storedTx.StakingTxFee,
+			storedTx.RequestId,
+		)
+		require.NoError(t, err)
+		expectedInBatch[storedTx.Metadata["batch"]]++
+	}
+
+	for batch, expected := range expectedInBatch {
+		query := stakerdb.DefaultStoredTransactionQuery()
+		query.NumMaxTransactions = uint64(numTx)
+		query = query.WithMetadataFilter(map[string]string{"batch": batch})
+		storedResult, err := s.QueryStoredTransactions(query)
+		require.NoError(t, err)
+		require.Len(t, storedResult.Transactions, expected)
+
+		for _, tx := range storedResult.Transactions {
+			require.Equal(t, batch, tx.Metadata["batch"])
+		}
+	}
+
+	query := stakerdb.DefaultStoredTransactionQuery()
+	query = query.WithMetadataFilter(map[string]string{"batch": "unknown"})
+	storedResult, err := s.QueryStoredTransactions(query)
+	require.NoError(t, err)
+	require.Len(t, storedResult.Transactions, 0)
+}
+
+func TestGroupFilter(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	s := MakeTestStore(t)
+	numTx := 10
+	generatedStoredTxs := genNStoredTransactions(t, r, numTx, 200)
+
+	for i, storedTx := range generatedStoredTxs {
+		stakerAddr, err := btcutil.DecodeAddress(storedTx.StakerAddress, &chaincfg.MainNetParams)
+		require.NoError(t, err)
+		err = s.AddTransaction(
+			storedTx.StakingTx,
+			storedTx.StakingOutputIndex,
+			storedTx.StakingTime,
+			storedTx.FinalityProvidersBtcPks,
+			storedTx.Pop,
+			stakerAddr,
+			storedTx.Metadata,
+			storedTx.StakingTxFee,
+			storedTx.RequestId,
+		)
+		require.NoError(t, err)
+
+		if i%2 == 0 {
+			hash := storedTx.StakingTx.TxHash()
+			err = s.SetTxGroup(&hash, "fund-a")
+			require.NoError(t, err)
+		}
+	}
+
+	query := stakerdb.DefaultStoredTransactionQuery()
+	query = query.WithGroupFilter("fund-a")
+	storedResult, err := s.QueryStoredTransactions(query)
+	require.NoError(t, err)
+	require.Len(t, storedResult.Transactions, numTx/2)
+
+	for _, tx := range storedResult.Transactions {
+		require.Equal(t, "fund-a", tx.Group)
+		require.Equal(t, proto.TransactionState_SENT_TO_BTC, tx.State)
+	}
+
+	query = stakerdb.DefaultStoredTransactionQuery()
+	query = query.WithGroupFilter("")
+	storedResult, err = s.QueryStoredTransactions(query)
+	require.NoError(t, err)
+	require.Len(t, storedResult.Transactions, numTx/2)
+
+	// removing delegation from group
+	hash := generatedStoredTxs[0].StakingTx.TxHash()
+	err = s.SetTxGroup(&hash, "")
+	require.NoError(t, err)
+
+	tx, err := s.GetTransaction(&hash)
+	require.NoError(t, err)
+	require.Empty(t, tx.Group)
+
+	unknownHash := datagen.GenRandomBtcdHash(r)
+	err = s.SetTxGroup(&unknownHash, "fund-a")
+	require.ErrorIs(t, err, stakerdb.ErrTransactionNotFound)
+}
+
+func TestTimeRangeFilters(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	s := MakeTestStore(t)
+	numTx := 6
+	generatedStoredTxs := genNStoredTransactions(t, r, numTx, 200)
+	start := time.Now().Add(-time.Second)
+
+	for i, storedTx := range generatedStoredTxs {
+		stakerAddr, err := btcutil.DecodeAddress(storedTx.StakerAddress, &chaincfg.MainNetParams)
+		require.NoError(t, err)
+		err = s.AddTransaction(
+			storedTx.StakingTx,
+			storedTx.StakingOutputIndex,
+			storedTx.StakingTime,
+			storedTx.FinalityProvidersBtcPks,
+			storedTx.Pop,
+			stakerAddr,
+			storedTx.Metadata,
+			storedTx.StakingTxFee,
+			storedTx.RequestId,
+		)
+		require.NoError(t, err)
+
+		if i%2 == 0 {
+			txHash := storedTx.StakingTx.TxHash()
+			blockHash := datagen.GenRandomBtcdHash(r)
+			err = s.SetTxConfirmed(&txHash, &blockHash, r.Uint32())
+			require.NoError(t, err)
+		}
+	}
+
+	query := stakerdb.DefaultStoredTransactionQuery()
+	query = query.WithCreatedRange(stakerdb.TimeRange{After: start})
+	storedResult, err := s.QueryStoredTransactions(query)
+	require.NoError(t, err)
+	require.Len(t, storedResult.Transactions, numTx)
+
+	query = stakerdb.DefaultStoredTransactionQuery()
+	query = query.WithCreatedRange(stakerdb.TimeRange{Before: start})
+	storedResult, err = s.QueryStoredTransactions(query)
+	require.NoError(t, err)
+	require.Empty(t, storedResult.Transactions)
+
+	query = stakerdb.DefaultStoredTransactionQuery()
+	query = query.WithCreatedRange(stakerdb.TimeRange{After: time.Now().Add(time.Hour)})
+	storedResult, err = s.QueryStoredTransactions(query)
+	require.NoError(t, err)
+	require.Empty(t, storedResult.Transactions)
+
+	confirmed := proto.TransactionState_CONFIRMED_ON_BTC
+	query = stakerdb.DefaultStoredTransactionQuery()
+	query = query.WithStateTransitionRange(&confirmed, stakerdb.TimeRange{After: start})
+	storedResult, err = s.QueryStoredTransactions(query)
+	require.NoError(t, err)
+	require.Len(t, storedResult.Transactions, numTx/2)
+
+	for _, tx := range storedResult.Transactions {
+		require.Equal(t, proto.TransactionState_CONFIRMED_ON_BTC, tx.State)
+	}
+
+	// total is counted from time index, also together with other filters
+	query = stakerdb.DefaultStoredTransactionQuery()
+	query.NumMaxTransactions = 1
+	query = query.WithStateTransitionRange(&confirmed, stakerdb.TimeRange{After: start})
+	storedResult, err = s.QueryStoredTransactions(query)
+	require.NoError(t, err)
+	require.Len(t, storedResult.Transactions, 1)
+	require.Equal(t, uint64(numTx/2), storedResult.Total)
+	require.Equal(t, uint64(numTx), storedResult.TotalStored)
+
+	firstHash := generatedStoredTxs[0].StakingTx.TxHash()
+	query = query.WithMatchFunc(func(tx *stakerdb.StoredTransaction) bool {
+		return tx.StakingTx.TxHash() != firstHash
 	})
+	storedResult, err = s.QueryStoredTransactions(query)
+	require.NoError(t, err)
+	require.Len(t, storedResult.Transactions, 1)
+	require.Equal(t, uint64(numTx/2-1), storedResult.Total)
+
+	// deleted transactions are removed from time indexes
+	txHash := generatedStoredTxs[0].StakingTx.TxHash()
+	err = s.DeleteTransaction(&txHash, proto.TransactionState_CONFIRMED_ON_BTC)
+	require.NoError(t, err)
+
+	query = stakerdb.DefaultStoredTransactionQuery()
+	query = query.WithStateTransitionRange(&confirmed, stakerdb.TimeRange{After: start})
+	storedResult, err = s.QueryStoredTransactions(query)
+	require.NoError(t, err)
+	require.Len(t, storedResult.Transactions, numTx/2-1)
+}
+
+func TestExternalKeyTransaction(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	s := MakeTestStore(t)
+	storedTx := genStoredTransaction(t, r, 200)
+
+	fundingAddr, err := btcutil.DecodeAddress(storedTx.StakerAddress, &chaincfg.MainNetParams)
+	require.NoError(t, err)
+
+	externalKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	err = s.AddExternalKeyTransaction(
+		storedTx.StakingTx,
+		storedTx.StakingOutputIndex,
+		storedTx.StakingTime,
+		storedTx.FinalityProvidersBtcPks,
+		fundingAddr,
+		externalKey.PubKey(),
+		storedTx.Metadata,
+		storedTx.StakingTxFee,
+		storedTx.RequestId,
+	)
+	require.NoError(t, err)
+
+	txHash := storedTx.StakingTx.TxHash()
+	tx, err := s.GetTransaction(&txHash)
+	require.NoError(t, err)
+	require.False(t, tx.Watched)
+	require.True(t, tx.WatchOnly())
+	require.True(t, pubKeysEqual(externalKey.PubKey(), tx.ExternalStakerBtcPk))
+	require.Equal(t, storedTx.StakerAddress, tx.StakerAddress)
+	require.Equal(t, storedTx.StakingTxFee, tx.StakingTxFee)
+
+	// watch only transactions are never returned as withdrawable
+	err = s.SetTxConfirmed(&txHash, &txHash, 1)
+	require.NoError(t, err)
+
+	query := stakerdb.DefaultStoredTransactionQuery()
+	storedResult, err := s.QueryStoredTransactions(query.WithdrawableTransactionsFilter(uint32(storedTx.StakingTime) + 10))
+	require.NoError(t, err)
+	require.Len(t, storedResult.Transactions, 0)
+}
+
+func TestBlocksToMaturity(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	s := MakeTestStore(t)
+	tx := genStoredTransaction(t, r, 200)
+	stakerAddr, err := btcutil.DecodeAddress(tx.StakerAddress, &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	txHash := tx.StakingTx.TxHash()
+	err = s.AddTransaction(
+		tx.StakingTx,
+		tx.StakingOutputIndex,
+		tx.StakingTime,
+		tx.FinalityProvidersBtcPks,
+		tx.Pop,
+		stakerAddr,
+		tx.Metadata,
+		tx.StakingTxFee,
+		tx.RequestId,
+	)
+	require.NoError(t, err)
+
+	// not confirmed yet, nothing to mature
+	storedTx, err := s.GetTransaction(&txHash)
+	require.NoError(t, err)
+	_, found := storedTx.BlocksToMaturity(1000)
+	require.False(t, found)
+
+	hash := datagen.GenRandomBtcdHash(r)
+	confirmationHeight := uint32(1000)
+	err = s.SetTxConfirmed(&txHash, &hash, confirmationHeight)
+	require.NoError(t, err)
+	storedTx, err = s.GetTransaction(&txHash)
+	require.NoError(t, err)
+
+	blocks, found := storedTx.BlocksToMaturity(confirmationHeight)
+	require.True(t, found)
+	require.Equal(t, uint32(tx.StakingTime)-1, blocks)
+
+	// maturity matches withdrawable transactions filter
+	matureHeight := confirmationHeight + uint32(tx.StakingTime) - 1
+	blocks, found = storedTx.BlocksToMaturity(matureHeight)
+	require.True(t, found)
+	require.Equal(t, uint32(0), blocks)
+
+	query := stakerdb.DefaultStoredTransactionQuery()
+	withdrawable, err := s.QueryStoredTransactions(query.WithdrawableTransactionsFilter(matureHeight))
+	require.NoError(t, err)
+	require.Len(t, withdrawable.Transactions, 1)
+
+	query = stakerdb.DefaultStoredTransactionQuery()
+	withdrawable, err = s.QueryStoredTransactions(query.WithdrawableTransactionsFilter(matureHeight - 1))
+	require.NoError(t, err)
+	require.Len(t, withdrawable.Transactions, 0)
+
+	blocks, found = storedTx.BlocksToMaturity(matureHeight + 100)
+	require.True(t, found)
+	require.Equal(t, uint32(0), blocks)
+}
+
+func TestMissingFeeData(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	s := MakeTestStore(t)
+
+	addTx := func(fee btcutil.Amount) chainhash.Hash {
+		tx := genStoredTransaction(t, r, 200)
+		stakerAddr, err := btcutil.DecodeAddress(tx.StakerAddress, &chaincfg.MainNetParams)
+		require.NoError(t, err)
+		err = s.AddTransaction(
+			tx.StakingTx,
+			tx.StakingOutputIndex,
+			tx.StakingTime,
+			tx.FinalityProvidersBtcPks,
+			tx.Pop,
+			stakerAddr,
+			tx.Metadata,
+			fee,
+			tx.RequestId,
+		)
+		require.NoError(t, err)
+		return tx.StakingTx.TxHash()
+	}
+
+	missingFeeData := func(txHash chainhash.Hash) bool {
+		storedTx, err := s.GetTransaction(&txHash)
+		require.NoError(t, err)
+		return storedTx.MissingFeeData()
+	}
+
+	// zero staking fee, as in transactions stored before fees were tracked
+	withoutFee := addTx(0)
+	require.True(t, missingFeeData(withoutFee))
+
+	withFee := addTx(1000)
+	require.False(t, missingFeeData(withFee))
+
+	// spent transaction needs spend fee as well
+	spendTxHash := datagen.GenRandomBtcdHash(r)
+	require.NoError(t, s.SetTxSpentOnBtc(&withFee, &spendTxHash, 0))
+	require.True(t, missingFeeData(withFee))
+
+	spentWithFee := addTx(1000)
+	spendTxHash = datagen.GenRandomBtcdHash(r)
+	require.NoError(t, s.SetTxSpentOnBtc(&spentWithFee, &spendTxHash, 500))
+	require.False(t, missingFeeData(spentWithFee))
+
+	// staking fee of watched transaction is never known to staker
+	watched := &stakerdb.StoredTransaction{
+		Watched: true,
+		State:   proto.TransactionState_SENT_TO_BABYLON,
+	}
+	require.False(t, watched.MissingFeeData())
+}
+
+func TestFilteredQueryTotal(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	s := MakeTestStore(t)
+	numTx := 20
+	generatedStoredTxs := genNStoredTransactions(t, r, numTx, 200)
+
+	expectedInBatch := make(map[string]uint64)
+	for _, storedTx := range generatedStoredTxs {
+		stakerAddr, err := btcutil.DecodeAddress(storedTx.StakerAddress, &chaincfg.MainNetParams)
+		require.NoError(t, err)
+		err = s.AddTransaction(
+			storedTx.StakingTx,
+			storedTx.StakingOutputIndex,
+			storedTx.StakingTime,
+			storedTx.FinalityProvidersBtcPks,
+			storedTx.Pop,
+			stakerAddr,
+			storedTx.Metadata,
+			storedTx.StakingTxFee,
+			storedTx.RequestId,
+		)
+		require.NoError(t, err)
+		expectedInBatch[storedTx.Metadata["batch"]]++
+	}
+
+	// without filters total is number of all stored transactions
+	query := stakerdb.DefaultStoredTransactionQuery()
+	query.NumMaxTransactions = 5
+	storedResult, err := s.QueryStoredTransactions(query)
+	require.NoError(t, err)
+	require.Len(t, storedResult.Transactions, 5)
+	require.Equal(t, uint64(numTx), storedResult.Total)
+	require.Equal(t, uint64(numTx), storedResult.TotalStored)
+
+	// with filters total counts all matching transactions, not only returned page
+	for batch, expected := range expectedInBatch {
+		query := stakerdb.DefaultStoredTransactionQuery()
+		query.NumMaxTransactions = 1
+		query = query.WithMetadataFilter(map[string]string{"batch": batch})
+		storedResult, err := s.QueryStoredTransactions(query)
+		require.NoError(t, err)
+		require.Len(t, storedResult.Transactions, 1)
+		require.Equal(t, expected, storedResult.Total)
+		require.Equal(t, uint64(numTx), storedResult.TotalStored)
+	}
+
+	query = stakerdb.DefaultStoredTransactionQuery()
+	query = query.WithMetadataFilter(map[string]string{"batch": "unknown"})
+	storedResult, err = s.QueryStoredTransactions(query)
+	require.NoError(t, err)
+	require.Empty(t, storedResult.Transactions)
+	require.Equal(t, uint64(0), storedResult.Total)
+	require.Equal(t, uint64(numTx), storedResult.TotalStored)
+}
+
+// bucketsReferencing returns names of buckets with keys or values containing
+// any of given byte sequences
+func bucketsReferencing(t *testing.T, backend kvdb.Backend, refs ...[]byte) []string {
+	var found []string
+
+	var walk func(name string, bucket kvdb.RBucket) error
+	walk = func(name string, bucket kvdb.RBucket) error {
+		return bucket.ForEach(func(k, v []byte) error {
+			if v == nil {
+				if nested := bucket.NestedReadBucket(k); nested != nil {
+					return walk(name+"/"+string(k), nested)
+				}
+			}
+
+			for _, ref := range refs {
+				if bytes.Contains(k, ref) || bytes.Contains(v, ref) {
+					found = append(found, name)
+					return nil
+				}
+			}
+			return nil
+		})
+	}
+
+	err := backend.View(func(tx kvdb.RTx) error {
+		return tx.ForEachBucket(func(name []byte) error {
+			return walk(string(name), tx.ReadBucket(name))
+		})
+	}, func() { found = nil })
+	require.NoError(t, err)
+
+	return found
+}
+
+func TestDeleteTransactionReferences(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	cfg := stakercfg.DefaultDBConfig()
+	cfg.DBPath = t.TempDir()
+	backend, err := stakercfg.GetDbBackend(&cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		backend.Close()
+	})
+
+	s, err := stakerdb.NewTrackedTransactionStore(backend)
+	require.NoError(t, err)
+	scheduledOps, err := stakerdb.NewScheduledOperationStore(backend)
+	require.NoError(t, err)
+	retryQueue, err := stakerdb.NewRetryQueueStore(backend)
+	require.NoError(t, err)
+	feeSpends, err := stakerdb.NewFeeSpendStore(backend)
+	require.NoError(t, err)
+	frozenOutputs, err := stakerdb.NewFrozenOutputStore(backend)
+	require.NoError(t, err)
+	nonces, err := stakerdb.NewMuSig2NonceStore(backend)
+	require.NoError(t, err)
+
+	now := time.Unix(time.Now().Unix(), 0)
+	txs := genNStoredTransactions(t, r, 2, 200)
+	unbondingTx := datagen.GenRandomTx(r)
+	spendTxHash := datagen.GenRandomBtcdHash(r)
+	cpfpChildHash := datagen.GenRandomBtcdHash(r)
+
+	for _, tx := range txs {
+		stakerAddr, err := btcutil.DecodeAddress(tx.StakerAddress, &chaincfg.MainNetParams)
+		require.NoError(t, err)
+		err = s.AddTransaction(
+			tx.StakingTx,
+			tx.StakingOutputIndex,
+			tx.StakingTime,
+			tx.FinalityProvidersBtcPks,
+			tx.Pop,
+			stakerAddr,
+			tx.Metadata,
+			tx.StakingTxFee,
+			tx.RequestId,
+		)
+		require.NoError(t, err)
+
+		txHash := tx.StakingTx.TxHash()
+
+		require.NoError(t, scheduledOps.AddOperation(&stakerdb.ScheduledOperation{
+			Operation:       proto.ScheduledOperationType_SCHEDULED_UNBOND,
+			StakingTxHash:   txHash,
+			ExecuteAtHeight: 100,
+			CreatedAt:       now,
+		}))
+		require.NoError(t, retryQueue.PutEntry(&stakerdb.RetryQueueEntry{
+			Operation:     proto.RetryOperation_SEND_DELEGATION_TO_BABYLON,
+			StakingTxHash: txHash,
+			NextAttempt:   now,
+			CreatedAt:     now,
+		}))
+		require.NoError(t, feeSpends.AddSpend(&stakerdb.FeeSpendEntry{
+			Timestamp: now,
+			Kind:      "staking",
+			TxHash:    txHash,
+			Fee:       1000,
+		}))
+		require.NoError(t, frozenOutputs.Freeze(&stakerdb.FrozenOutput{
+			Outpoint:  *wire.NewOutPoint(&txHash, 1),
+			Timestamp: now,
+		}))
+		require.NoError(t, nonces.Add(&stakerdb.MuSig2Nonce{
+			SessionId: "unbond-" + txHash.String(),
+			SignerPk:  []byte{1},
+			PubNonce:  []byte{2},
+			SecNonce:  []byte{3},
+			CreatedAt: now,
+			ExpiresAt: now.Add(time.Hour),
+		}))
+	}
+
+	txHash := txs[0].StakingTx.TxHash()
+	otherTxHash := txs[1].StakingTx.TxHash()
+	unbondingTxHash := unbondingTx.TxHash()
+
+	require.NoError(t, s.SetTxSentToBabylon(&txHash, unbondingTx, txs[0].StakingTime))
+	require.NoError(t, s.AddTxCpfpChild(&txHash, &cpfpChildHash))
+	require.NoError(t, s.SetTxSpentOnBtc(&txHash, &spendTxHash, 500))
+
+	for _, h := range []chainhash.Hash{unbondingTxHash, spendTxHash, cpfpChildHash} {
+		require.NoError(t, feeSpends.AddSpend(&stakerdb.FeeSpendEntry{
+			Timestamp: now,
+			Kind:      "spend",
+			TxHash:    h,
+			Fee:       500,
+		}))
+	}
+
+	refs := [][]byte{
+		txHash[:],
+		[]byte(txHash.String()),
+		unbondingTxHash[:],
+		spendTxHash[:],
+		cpfpChildHash[:],
+	}
+	require.NotEmpty(t, bucketsReferencing(t, backend, refs...))
+
+	err = s.DeleteTransaction(&txHash, proto.TransactionState_SPENT_ON_BTC)
+	require.NoError(t, err)
+
+	require.Empty(t, bucketsReferencing(t, backend, refs...))
+
+	// data of other transaction is kept
+	_, err = s.GetTransaction(&otherTxHash)
+	require.NoError(t, err)
+
+	operations, err := scheduledOps.Operations()
+	require.NoError(t, err)
+	require.Len(t, operations, 1)
+	require.Equal(t, otherTxHash, operations[0].StakingTxHash)
+
+	_, err = retryQueue.GetEntry(proto.RetryOperation_SEND_DELEGATION_TO_BABYLON, &otherTxHash)
+	require.NoError(t, err)
+
+	spends, err := feeSpends.SpendsSince(now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, spends, 1)
+	require.Equal(t, otherTxHash, spends[0].TxHash)
+
+	frozen, err := frozenOutputs.FrozenOutputs()
+	require.NoError(t, err)
+	require.Len(t, frozen, 1)
+	require.Equal(t, otherTxHash, frozen[0].Outpoint.Hash)
+
+	_, err = nonces.Get("unbond-" + otherTxHash.String())
+	require.NoError(t, err)
 }
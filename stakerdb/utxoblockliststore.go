@@ -0,0 +1,174 @@
+package stakerdb
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/babylonchain/btc-staker/proto"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/kvdb"
+	pm "google.golang.org/protobuf/proto"
+)
+
+var (
+	// mapping outpoint key or pk script key -> proto.UtxoBlocklistEntry
+	utxoBlocklistBucketName = []byte("utxoBlocklist")
+)
+
+const (
+	blocklistOutpointPrefix = 'o'
+	blocklistPkScriptPrefix = 's'
+)
+
+// UtxoBlocklistEntry blocks either single outpoint or all outputs paying to
+// given pk script. Exactly one of Outpoint and PkScript is set.
+type UtxoBlocklistEntry struct {
+	Outpoint  *wire.OutPoint
+	PkScript  []byte
+	Timestamp time.Time
+	Reason    string
+}
+
+// UtxoBlocklistStore keeps outputs which must never be spent by the daemon
+type UtxoBlocklistStore struct {
+	db kvdb.Backend
+}
+
+// NewUtxoBlocklistStore returns a new utxo blocklist store backed by db
+func NewUtxoBlocklistStore(db kvdb.Backend) (*UtxoBlocklistStore, error) {
+	store := &UtxoBlocklistStore{db}
+
+	if err := kvdb.Batch(db, func(tx kvdb.RwTx) error {
+		_, err := tx.CreateTopLevelBucket(utxoBlocklistBucketName)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func blocklistKey(e *UtxoBlocklistEntry) []byte {
+	if e.Outpoint != nil {
+		key := make([]byte, 1+chainhash.HashSize+4)
+		key[0] = blocklistOutpointPrefix
+		copy(key[1:], e.Outpoint.Hash[:])
+		binary.BigEndian.PutUint32(key[1+chainhash.HashSize:], e.Outpoint.Index)
+		return key
+	}
+
+	return append([]byte{blocklistPkScriptPrefix}, e.PkScript...)
+}
+
+func blocklistEntryFromKey(key []byte, entryProto *proto.UtxoBlocklistEntry) (*UtxoBlocklistEntry, error) {
+	e := &UtxoBlocklistEntry{
+		Timestamp: time.Unix(entryProto.Timestamp, 0),
+		Reason:    entryProto.Reason,
+	}
+
+	switch {
+	case len(key) == 1+chainhash.HashSize+4 && key[0] == blocklistOutpointPrefix:
+		var hash chainhash.Hash
+		copy(hash[:], key[1:1+chainhash.HashSize])
+		e.Outpoint = wire.NewOutPoint(&hash, binary.BigEndian.Uint32(key[1+chainhash.HashSize:]))
+	case len(key) > 1 && key[0] == blocklistPkScriptPrefix:
+		e.PkScript = append([]byte(nil), key[1:]...)
+	default:
+		return nil, ErrCorruptedTransactionsDb
+	}
+
+	return e, nil
+}
+
+// AddEntries adds entries to the blocklist, existing entries are overwritten
+func (s *UtxoBlocklistStore) AddEntries(entries []UtxoBlocklistEntry) error {
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(utxoBlocklistBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		for i := range entries {
+			marshalled, err := pm.Marshal(&proto.UtxoBlocklistEntry{
+				Timestamp: entries[i].Timestamp.Unix(),
+				Reason:    entries[i].Reason,
+			})
+
+			if err != nil {
+				return err
+			}
+
+			if err := bucket.Put(blocklistKey(&entries[i]), marshalled); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// RemoveEntries removes entries from the blocklist. Either all entries are
+// removed or none, if any of them is not in the blocklist.
+func (s *UtxoBlocklistStore) RemoveEntries(entries []UtxoBlocklistEntry) error {
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(utxoBlocklistBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		for i := range entries {
+			key := blocklistKey(&entries[i])
+
+			if bucket.Get(key) == nil {
+				return ErrUtxoBlocklistEntryNotFound
+			}
+
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Entries returns all entries of the blocklist
+func (s *UtxoBlocklistStore) Entries() ([]UtxoBlocklistEntry, error) {
+	var entries []UtxoBlocklistEntry
+
+	err := s.db.View(func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(utxoBlocklistBucketName)
+
+		if bucket == nil {
+			return ErrCorruptedTransactionsDb
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var entryProto proto.UtxoBlocklistEntry
+
+			if err := pm.Unmarshal(v, &entryProto); err != nil {
+				return ErrCorruptedTransactionsDb
+			}
+
+			e, err := blocklistEntryFromKey(k, &entryProto)
+
+			if err != nil {
+				return err
+			}
+
+			entries = append(entries, *e)
+			return nil
+		})
+	}, func() {
+		entries = nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
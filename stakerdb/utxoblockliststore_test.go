@@ -0,0 +1,77 @@
+package stakerdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+func MakeTestUtxoBlocklistStore(t *testing.T) *stakerdb.UtxoBlocklistStore {
+	cfg := stakercfg.DefaultDBConfig()
+
+	cfg.DBPath = t.TempDir()
+
+	backend, err := stakercfg.GetDbBackend(&cfg)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		backend.Close()
+	})
+
+	store, err := stakerdb.NewUtxoBlocklistStore(backend)
+	require.NoError(t, err)
+
+	return store
+}
+
+func TestUtxoBlocklistStore(t *testing.T) {
+	s := MakeTestUtxoBlocklistStore(t)
+
+	entries, err := s.Entries()
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	now := time.Unix(time.Now().Unix(), 0)
+	txHash := chainhash.HashH([]byte("tainted tx"))
+
+	outpointEntry := stakerdb.UtxoBlocklistEntry{
+		Outpoint:  wire.NewOutPoint(&txHash, 3),
+		Timestamp: now,
+		Reason:    "sanctioned source",
+	}
+
+	scriptEntry := stakerdb.UtxoBlocklistEntry{
+		PkScript:  []byte{0x00, 0x14, 0x01, 0x02, 0x03},
+		Timestamp: now,
+		Reason:    "flagged address",
+	}
+
+	require.NoError(t, s.AddEntries([]stakerdb.UtxoBlocklistEntry{outpointEntry, scriptEntry}))
+
+	entries, err = s.Entries()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []stakerdb.UtxoBlocklistEntry{outpointEntry, scriptEntry}, entries)
+
+	// removal is all or nothing
+	missingHash := chainhash.HashH([]byte("other tx"))
+	err = s.RemoveEntries([]stakerdb.UtxoBlocklistEntry{
+		outpointEntry,
+		{Outpoint: wire.NewOutPoint(&missingHash, 0)},
+	})
+	require.ErrorIs(t, err, stakerdb.ErrUtxoBlocklistEntryNotFound)
+
+	entries, err = s.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	require.NoError(t, s.RemoveEntries([]stakerdb.UtxoBlocklistEntry{outpointEntry}))
+
+	entries, err = s.Entries()
+	require.NoError(t, err)
+	require.Equal(t, []stakerdb.UtxoBlocklistEntry{scriptEntry}, entries)
+}
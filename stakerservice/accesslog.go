@@ -0,0 +1,222 @@
+package stakerservice
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// only beginning of the response is kept to find result of the call, larger
+	// responses are successful results
+	maxAccessLogResponsePeek = 64 * 1024
+
+	accessLogAnonymous     = "anonymous"
+	accessLogUnknownMethod = "unknown"
+	accessLogBatch         = "batch"
+	accessLogResultOk      = "ok"
+)
+
+// accessLogger logs requests served by the rpc server and flags slow ones
+type accessLogger struct {
+	enabled       bool
+	slowThreshold time.Duration
+	methods       map[string]struct{}
+	slowRequests  *prometheus.CounterVec
+	logger        *logrus.Logger
+}
+
+// newAccessLogger returns nil if neither access log nor slow request flagging
+// is enabled
+func newAccessLogger(
+	cfg *scfg.Config,
+	routes RoutesMap,
+	slowRequests *prometheus.CounterVec,
+	logger *logrus.Logger,
+) *accessLogger {
+	if !cfg.AccessLogConfig.Enabled && cfg.AccessLogConfig.SlowThreshold == 0 {
+		return nil
+	}
+
+	methods := make(map[string]struct{}, len(routes))
+	for method := range routes {
+		methods[method] = struct{}{}
+	}
+
+	return &accessLogger{
+		enabled:       cfg.AccessLogConfig.Enabled,
+		slowThreshold: cfg.AccessLogConfig.SlowThreshold,
+		methods:       methods,
+		slowRequests:  slowRequests,
+		logger:        logger,
+	}
+}
+
+// methodLabel returns method used as metric label, unknown methods are grouped
+// so that callers cannot create unbounded number of metric series
+func (a *accessLogger) methodLabel(methods []string) string {
+	switch {
+	case len(methods) == 0:
+		return accessLogUnknownMethod
+	case len(methods) > 1:
+		return accessLogBatch
+	}
+
+	if _, found := a.methods[methods[0]]; !found {
+		return accessLogUnknownMethod
+	}
+
+	return methods[0]
+}
+
+// accessLogWriter records status and size of the response, and keeps its
+// beginning to find result of the call
+type accessLogWriter struct {
+	http.ResponseWriter
+	statusCode int
+	size       int64
+	peek       bytes.Buffer
+}
+
+func (w *accessLogWriter) WriteHeader(statusCode int) {
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *accessLogWriter) Write(b []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+
+	if free := maxAccessLogResponsePeek - w.peek.Len(); free > 0 {
+		w.peek.Write(b[:min(free, len(b))])
+	}
+
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *accessLogWriter) FlushError() error {
+	return http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *accessLogWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// rpcResult returns ok, or sorted error codes of failed calls of json rpc
+// response
+func rpcResult(statusCode int, response []byte) string {
+	type rpcResponse struct {
+		Error *struct {
+			Data string `json:"data"`
+		} `json:"error"`
+	}
+
+	var responses []rpcResponse
+
+	trimmed := bytes.TrimSpace(response)
+
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		_ = json.Unmarshal(trimmed, &responses)
+	} else {
+		var single rpcResponse
+		if json.Unmarshal(trimmed, &single) == nil {
+			responses = append(responses, single)
+		}
+	}
+
+	codes := make(map[string]struct{})
+
+	for _, resp := range responses {
+		if resp.Error == nil {
+			continue
+		}
+
+		code := string(ErrCodeInternal)
+		if errData, err := ParseRpcErrorData(resp.Error.Data); err == nil && errData.ErrorCode != "" {
+			code = string(errData.ErrorCode)
+		}
+
+		codes[code] = struct{}{}
+	}
+
+	if len(codes) == 0 {
+		if statusCode >= http.StatusBadRequest {
+			return http.StatusText(statusCode)
+		}
+
+		return accessLogResultOk
+	}
+
+	sorted := make([]string, 0, len(codes))
+	for code := range codes {
+		sorted = append(sorted, code)
+	}
+	sort.Strings(sorted)
+
+	return strings.Join(sorted, ",")
+}
+
+// withAccessLog logs every request if access log is enabled, and requests
+// slower than threshold at warn level
+func withAccessLog(h http.Handler, a *accessLogger) http.Handler {
+	if a == nil {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		req := rpcRequestOf(r)
+		methods := req.methods()
+
+		lw := &accessLogWriter{ResponseWriter: w}
+
+		h.ServeHTTP(lw, r)
+
+		elapsed := time.Since(start)
+		// streams are expected to stay open for long time
+		isStream := strings.HasPrefix(r.URL.Path, streamPathPrefix)
+		slow := a.slowThreshold > 0 && elapsed >= a.slowThreshold && !isStream
+
+		if !slow && !a.enabled {
+			return
+		}
+
+		if lw.statusCode == 0 {
+			lw.statusCode = http.StatusOK
+		}
+
+		entry := a.logger.WithFields(logrus.Fields{
+			"requestId":     r.Header.Get(RequestIdHeader),
+			"method":        strings.Join(methods, ","),
+			"identity":      req.caller.String(),
+			"remoteAddr":    r.RemoteAddr,
+			"durationMs":    elapsed.Milliseconds(),
+			"requestBytes":  max(r.ContentLength, 0),
+			"responseBytes": lw.size,
+			"status":        lw.statusCode,
+			"result":        rpcResult(lw.statusCode, lw.peek.Bytes()),
+		})
+
+		if slow {
+			a.slowRequests.WithLabelValues(a.methodLabel(methods)).Inc()
+			entry.Warn("Slow rpc request")
+			return
+		}
+
+		entry.Info("Rpc request")
+	})
+}
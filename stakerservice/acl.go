@@ -0,0 +1,300 @@
+package stakerservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/sirupsen/logrus"
+)
+
+// spendMethods are methods which move funds, sign with wallet or babylon keys or
+// change state of the daemon. All other methods are considered read only, so
+// every new method of this kind must be added here.
+var spendMethods = map[string]struct{}{
+	"stake":                              {},
+	"stake_external":                     {},
+	"spend_stake":                        {},
+	"unbond_staking":                     {},
+	"set_unbonding_overrides":            {},
+	"set_auto_withdraw":                  {},
+	"unbond_all":                         {},
+	"bump_staking_fee":                   {},
+	"watch_staking_tx":                   {},
+	"prove_ownership":                    {},
+	"sign_message":                       {},
+	"proof_of_reserves":                  {},
+	"generate_musig2_nonce":              {},
+	"set_staking_preset":                 {},
+	"delete_staking_preset":              {},
+	"consolidate_outputs":                {},
+	"freeze_output":                      {},
+	"unfreeze_output":                    {},
+	"utxo_blocklist_add":                 {},
+	"utxo_blocklist_remove":              {},
+	"withdraw_babylon_rewards":           {},
+	"flush_retry_queue":                  {},
+	"retry_babylon":                      {},
+	"export_delegation":                  {},
+	"override_delegation_state":          {},
+	"purge_delegation":                   {},
+	"annotate_delegation":                {},
+	"dev_submit_covenant_unbonding_sigs": {},
+	"dev_signed_unbonding_tx":            {},
+	"schedule_operation":                 {},
+	"set_delegation_group":               {},
+	"cancel_scheduled_operation":         {},
+	"approve_action":                     {},
+	"reject_action":                      {},
+}
+
+// operatorMethods expose request bodies of calls queued by other operators, so
+// they can be called only by authenticated operators regardless of other rules
+var operatorMethods = map[string]struct{}{
+	"pending_actions": {},
+}
+
+// probes must be always reachable by the orchestrator
+var aclExemptPaths = map[string]struct{}{
+	LivenessProbePath:  {},
+	ReadinessProbePath: {},
+}
+
+// routeListingMethod is method of requests listing routes of the server, it is
+// treated as read only method
+const routeListingMethod = ""
+
+type rpcAcl struct {
+	readOnly []*net.IPNet
+	spend    []*net.IPNet
+	methods  map[string][]*net.IPNet
+	// nil identity rules do not restrict callers
+	readOnlyIdentities map[string]struct{}
+	spendIdentities    map[string]struct{}
+	methodIdentities   map[string]map[string]struct{}
+	// methods served by the daemon, calls of all other methods are denied
+	known  map[string]struct{}
+	logger *logrus.Logger
+}
+
+// newRpcAcl returns nil if access control is disabled
+func newRpcAcl(
+	cfg *scfg.RpcAclConfig,
+	routes RoutesMap,
+	streams StreamHandlers,
+	logger *logrus.Logger,
+) (*rpcAcl, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	readOnly, err := scfg.ParseCidrs(cfg.ReadOnlyCidrs)
+	if err != nil {
+		return nil, err
+	}
+
+	spend, err := scfg.ParseCidrs(cfg.SpendCidrs)
+	if err != nil {
+		return nil, err
+	}
+
+	methods, err := cfg.MethodRules()
+	if err != nil {
+		return nil, err
+	}
+
+	readOnlyIdentities, err := scfg.ParseAclIdentities(cfg.ReadOnlyIdentities)
+	if err != nil {
+		return nil, err
+	}
+
+	spendIdentities, err := scfg.ParseAclIdentities(cfg.SpendIdentities)
+	if err != nil {
+		return nil, err
+	}
+
+	methodIdentities, err := cfg.MethodIdentityRules()
+	if err != nil {
+		return nil, err
+	}
+
+	known := map[string]struct{}{routeListingMethod: {}}
+	for method := range routes {
+		known[method] = struct{}{}
+	}
+	for path := range streams {
+		if strings.HasPrefix(path, streamPathPrefix) {
+			known[strings.TrimPrefix(path, streamPathPrefix)] = struct{}{}
+		}
+	}
+
+	return &rpcAcl{
+		readOnly:           readOnly,
+		spend:              spend,
+		methods:            methods,
+		readOnlyIdentities: readOnlyIdentities,
+		spendIdentities:    spendIdentities,
+		methodIdentities:   methodIdentities,
+		known:              known,
+		logger:             logger,
+	}, nil
+}
+
+func (a *rpcAcl) allowedNets(method string) []*net.IPNet {
+	if nets, found := a.methods[method]; found {
+		return nets
+	}
+
+	if _, isSpend := spendMethods[method]; isSpend {
+		return a.spend
+	}
+
+	return a.readOnly
+}
+
+// allowedIdentities returns nil if method is not restricted by identity
+func (a *rpcAcl) allowedIdentities(method string) map[string]struct{} {
+	if identities, found := a.methodIdentities[method]; found {
+		return identities
+	}
+
+	if _, isSpend := spendMethods[method]; isSpend {
+		return a.spendIdentities
+	}
+
+	return a.readOnlyIdentities
+}
+
+// denyReason returns reason of denial of the call, or empty string if the call is
+// allowed. Unknown methods are always denied, operator methods are denied to
+// callers which are not operators. Source address is not checked for connections
+// without ip address.
+func (a *rpcAcl) denyReason(method string, ip net.IP, caller rpcCaller) string {
+	if _, known := a.known[method]; !known {
+		return "unknown method"
+	}
+
+	if _, operatorOnly := operatorMethods[method]; operatorOnly && caller.Operator == "" {
+		return "operator identity required"
+	}
+
+	if ip != nil && !containsIP(a.allowedNets(method), ip) {
+		return "source address not allowed"
+	}
+
+	if identities := a.allowedIdentities(method); identities != nil {
+		_, operatorAllowed := identities[scfg.AclOperatorIdentityPrefix+caller.Operator]
+		_, apiAllowed := identities[scfg.AclApiIdentityPrefix+caller.ApiIdentity]
+
+		if !(caller.Operator != "" && operatorAllowed) && !(caller.ApiIdentity != "" && apiAllowed) {
+			return "caller identity not allowed"
+		}
+	}
+
+	return ""
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// remoteIP returns nil for connections without ip address, i.e unix sockets
+func remoteIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return net.ParseIP(host)
+}
+
+// writeRpcError writes json rpc error response for requests rejected by http
+// middleware, before they reach rpc handler
+func writeRpcError(w http.ResponseWriter, id json.RawMessage, status int, message string, errData RpcErrorData) {
+	data, _ := json.Marshal(errData)
+
+	if len(id) == 0 {
+		id = json.RawMessage("-1")
+	}
+
+	resp, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error": map[string]interface{}{
+			"code":    -32600,
+			"message": message,
+			"data":    string(data),
+		},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(resp)
+}
+
+func writeAccessDenied(w http.ResponseWriter, id json.RawMessage, method string) {
+	writeRpcError(w, id, http.StatusForbidden, "Access denied", RpcErrorData{
+		ErrorCode: ErrCodeForbidden,
+		Message:   fmt.Sprintf("access to method %s denied", method),
+	})
+}
+
+// withAcl rejects requests calling methods which are not allowed from the source
+// address of the request or for the identity of the caller, and requests calling
+// unknown methods. Batched requests are rejected as a whole if any of their
+// methods is denied. Source address of requests over unix sockets is not
+// checked, as access to them is controlled by file system permissions.
+func withAcl(h http.Handler, acl *rpcAcl) http.Handler {
+	if acl == nil {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, exempt := aclExemptPaths[r.URL.Path]; exempt {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		req := rpcRequestOf(r)
+
+		if req.err != nil {
+			// methods of requests which can't be parsed can't be checked
+			http.Error(w, fmt.Sprintf("invalid request: %s", req.err), http.StatusBadRequest)
+			return
+		}
+
+		ip := remoteIP(r)
+
+		methods := req.methods()
+		if len(methods) == 0 {
+			methods = []string{routeListingMethod}
+		}
+
+		for _, method := range methods {
+			if reason := acl.denyReason(method, ip, req.caller); reason != "" {
+				acl.logger.WithFields(logrus.Fields{
+					"method":     method,
+					"reason":     reason,
+					"identity":   req.caller.String(),
+					"remoteAddr": r.RemoteAddr,
+					"requestId":  r.Header.Get(RequestIdHeader),
+				}).Warn("Denied rpc call")
+
+				writeAccessDenied(w, req.firstId(), method)
+				return
+			}
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
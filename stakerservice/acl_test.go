@@ -0,0 +1,284 @@
+package stakerservice
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	rpc "github.com/cometbft/cometbft/rpc/jsonrpc/server"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	aliceOperatorToken = "alice-operator-token"
+	bobOperatorToken   = "bob-operator-token"
+	exchangeApiToken   = "exchange-api-token"
+	otherApiToken      = "other-api-token"
+)
+
+func newTestIdentityResolver() *identityResolver {
+	return &identityResolver{
+		operators: map[[sha256.Size]byte]string{
+			sha256.Sum256([]byte(aliceOperatorToken)): "alice",
+			sha256.Sum256([]byte(bobOperatorToken)):   "bob",
+		},
+		apiIdentities: map[[sha256.Size]byte]string{
+			sha256.Sum256([]byte(exchangeApiToken)): "exchange",
+			sha256.Sum256([]byte(otherApiToken)):    "other",
+		},
+	}
+}
+
+func newTestRpcAcl(t *testing.T) *rpcAcl {
+	cfg := scfg.RpcAclConfig{
+		Enabled:          true,
+		ReadOnlyCidrs:    []string{"127.0.0.0/8", "10.0.0.0/8"},
+		SpendCidrs:       []string{"127.0.0.0/8"},
+		MethodCidrs:      []string{"staking_details=192.168.0.0/16"},
+		SpendIdentities:  []string{"operator:alice", "api:exchange"},
+		MethodIdentities: []string{"unbond_all=operator:bob"},
+	}
+
+	routes := RoutesMap{
+		"stake":           nil,
+		"unbond_all":      nil,
+		"list_outputs":    nil,
+		"staking_details": nil,
+		"pending_actions": nil,
+	}
+
+	streams := StreamHandlers{
+		streamPathPrefix + "events": nil,
+	}
+
+	acl, err := newRpcAcl(&cfg, routes, streams, logrus.New())
+	require.NoError(t, err)
+	require.NotNil(t, acl)
+
+	return acl
+}
+
+func TestNewRpcAclDisabled(t *testing.T) {
+	cfg := scfg.DefaultRpcAclConfig()
+
+	acl, err := newRpcAcl(&cfg, RoutesMap{"stake": nil}, nil, logrus.New())
+	require.NoError(t, err)
+	require.Nil(t, acl)
+}
+
+func TestRpcAclDenyReason(t *testing.T) {
+	acl := newTestRpcAcl(t)
+
+	var (
+		anonymous = rpcCaller{}
+		alice     = rpcCaller{Operator: "alice"}
+		bob       = rpcCaller{Operator: "bob"}
+		exchange  = rpcCaller{ApiIdentity: "exchange"}
+		other     = rpcCaller{ApiIdentity: "other"}
+		localhost = net.ParseIP("127.0.0.1")
+		private   = net.ParseIP("10.1.2.3")
+		lan       = net.ParseIP("192.168.1.1")
+	)
+
+	tests := []struct {
+		name   string
+		method string
+		ip     net.IP
+		caller rpcCaller
+		reason string
+	}{
+		{"unknown method from allowed address", "drop_database", localhost, alice, "unknown method"},
+		{"unknown method over unix socket", "drop_database", nil, alice, "unknown method"},
+		{"route listing", routeListingMethod, localhost, anonymous, ""},
+		{"streaming endpoint", "events", private, anonymous, ""},
+		{"read only from read only network", "list_outputs", private, anonymous, ""},
+		{"read only from other network", "list_outputs", lan, anonymous, "source address not allowed"},
+		{"method network override allows", "staking_details", lan, anonymous, ""},
+		{"method network override replaces default", "staking_details", localhost, anonymous, "source address not allowed"},
+		{"spend from read only network", "stake", private, alice, "source address not allowed"},
+		{"spend by allowed operator", "stake", localhost, alice, ""},
+		{"spend by allowed api identity", "stake", localhost, exchange, ""},
+		{"spend by anonymous caller", "stake", localhost, anonymous, "caller identity not allowed"},
+		{"spend by other operator", "stake", localhost, bob, "caller identity not allowed"},
+		{"spend by other api identity", "stake", localhost, other, "caller identity not allowed"},
+		{"spend over unix socket by allowed operator", "stake", nil, alice, ""},
+		{"spend over unix socket by anonymous caller", "stake", nil, anonymous, "caller identity not allowed"},
+		{"method identity override allows", "unbond_all", localhost, bob, ""},
+		{"method identity override replaces default", "unbond_all", localhost, alice, "caller identity not allowed"},
+		{"operator method by anonymous caller", "pending_actions", localhost, anonymous, "operator identity required"},
+		{"operator method by api identity", "pending_actions", localhost, exchange, "operator identity required"},
+		{"operator method by operator", "pending_actions", localhost, bob, ""},
+		{"operator method from other network", "pending_actions", lan, bob, "source address not allowed"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.reason, acl.denyReason(tc.method, tc.ip, tc.caller))
+		})
+	}
+}
+
+func TestWithAcl(t *testing.T) {
+	acl := newTestRpcAcl(t)
+
+	tests := []struct {
+		name          string
+		httpMethod    string
+		path          string
+		body          string
+		remoteAddr    string
+		operatorToken string
+		apiToken      string
+		status        int
+		deniedMethod  string
+	}{
+		{
+			name:       "allowed read only call",
+			httpMethod: http.MethodPost,
+			body:       `{"jsonrpc":"2.0","id":1,"method":"list_outputs","params":{}}`,
+			remoteAddr: "10.1.2.3:1234",
+			status:     http.StatusOK,
+		},
+		{
+			name:       "unknown method",
+			httpMethod: http.MethodPost,
+			body:       `{"jsonrpc":"2.0","id":1,"method":"drop_database","params":{}}`,
+			remoteAddr: "127.0.0.1:1234",
+			status:     http.StatusForbidden,
+		},
+		{
+			name:          "spend call of allowed operator",
+			httpMethod:    http.MethodPost,
+			body:          `{"jsonrpc":"2.0","id":1,"method":"stake","params":{}}`,
+			remoteAddr:    "127.0.0.1:1234",
+			operatorToken: aliceOperatorToken,
+			status:        http.StatusOK,
+		},
+		{
+			name:          "spend call with unknown operator token",
+			httpMethod:    http.MethodPost,
+			body:          `{"jsonrpc":"2.0","id":1,"method":"stake","params":{}}`,
+			remoteAddr:    "127.0.0.1:1234",
+			operatorToken: "stolen-token",
+			status:        http.StatusForbidden,
+		},
+		{
+			name:       "spend call of allowed api identity",
+			httpMethod: http.MethodPost,
+			body:       `{"jsonrpc":"2.0","id":1,"method":"stake","params":{}}`,
+			remoteAddr: "127.0.0.1:1234",
+			apiToken:   exchangeApiToken,
+			status:     http.StatusOK,
+		},
+		{
+			name:       "batch with one forbidden method",
+			httpMethod: http.MethodPost,
+			body: `[{"jsonrpc":"2.0","id":1,"method":"list_outputs","params":{}},` +
+				`{"jsonrpc":"2.0","id":2,"method":"stake","params":{}}]`,
+			remoteAddr:   "127.0.0.1:1234",
+			apiToken:     otherApiToken,
+			status:       http.StatusForbidden,
+			deniedMethod: "stake",
+		},
+		{
+			name:       "batch of allowed methods",
+			httpMethod: http.MethodPost,
+			body: `[{"jsonrpc":"2.0","id":1,"method":"list_outputs","params":{}},` +
+				`{"jsonrpc":"2.0","id":2,"method":"stake","params":{}}]`,
+			remoteAddr: "127.0.0.1:1234",
+			apiToken:   exchangeApiToken,
+			status:     http.StatusOK,
+		},
+		{
+			name:       "operator method over uri without operator token",
+			httpMethod: http.MethodGet,
+			path:       "/pending_actions",
+			remoteAddr: "127.0.0.1:1234",
+			apiToken:   exchangeApiToken,
+			status:     http.StatusForbidden,
+		},
+		{
+			name:          "operator method over uri with operator token",
+			httpMethod:    http.MethodGet,
+			path:          "/pending_actions",
+			remoteAddr:    "127.0.0.1:1234",
+			operatorToken: bobOperatorToken,
+			status:        http.StatusOK,
+		},
+		{
+			name:       "probe from any address",
+			httpMethod: http.MethodGet,
+			path:       LivenessProbePath,
+			remoteAddr: "203.0.113.1:1234",
+			status:     http.StatusOK,
+		},
+		{
+			name:       "unparsable request",
+			httpMethod: http.MethodPost,
+			body:       `{"jsonrpc":`,
+			remoteAddr: "127.0.0.1:1234",
+			status:     http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			served := false
+			handler := withRpcRequest(
+				withAcl(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					served = true
+				}), acl),
+				newTestIdentityResolver(),
+				rpc.DefaultConfig().MaxBodyBytes,
+			)
+
+			path := tc.path
+			if path == "" {
+				path = "/"
+			}
+
+			req := httptest.NewRequest(tc.httpMethod, path, bytes.NewReader([]byte(tc.body)))
+			req.RemoteAddr = tc.remoteAddr
+			if tc.operatorToken != "" {
+				req.Header.Set(OperatorTokenHeader, tc.operatorToken)
+			}
+			if tc.apiToken != "" {
+				req.Header.Set(ApiTokenHeader, tc.apiToken)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			require.Equal(t, tc.status, recorder.Code)
+			require.Equal(t, tc.status == http.StatusOK, served)
+
+			if tc.status != http.StatusForbidden {
+				return
+			}
+
+			var resp struct {
+				Id    json.RawMessage `json:"id"`
+				Error struct {
+					Data string `json:"data"`
+				} `json:"error"`
+			}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+
+			var data RpcErrorData
+			require.NoError(t, json.Unmarshal([]byte(resp.Error.Data), &data))
+			require.Equal(t, ErrCodeForbidden, data.ErrorCode)
+
+			if tc.deniedMethod != "" {
+				require.Contains(t, data.Message, tc.deniedMethod)
+				// error is reported with id of the first call of the batch
+				require.Equal(t, "1", string(resp.Id))
+			}
+		})
+	}
+}
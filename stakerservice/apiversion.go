@@ -0,0 +1,210 @@
+package stakerservice
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ApiVersion is the current version of rpc response shapes. It must be
+	// increased whenever a field is renamed or removed, or its meaning
+	// changes, and a downgrade to the previous shape must be registered in
+	// responseChanges. Adding new fields does not require new version.
+	ApiVersion = 1
+
+	// minApiVersion is the oldest version which can be requested by clients
+	minApiVersion = 1
+
+	// ApiVersionHeader selects version of response shapes, it is also returned
+	// in the response. Version can be selected by version query parameter too.
+	ApiVersionHeader = "X-Api-Version"
+
+	apiVersionQueryParam = "version"
+
+	// apiVersionField is added to every json rpc result which is json object
+	apiVersionField = "api_version"
+)
+
+// responseChange describes change of response of the method introduced in
+// given api version. Downgrade converts result of the method to its shape from
+// the previous version.
+type responseChange struct {
+	version   int
+	method    string
+	downgrade func(result map[string]json.RawMessage) error
+}
+
+// responseChanges are applied from the newest to the oldest, so that result
+// reaches shape of the requested version
+var responseChanges []responseChange
+
+// requestedApiVersion returns version requested by the client, ApiVersion if
+// client did not select any version
+func requestedApiVersion(r *http.Request) (int, error) {
+	requested := r.URL.Query().Get(apiVersionQueryParam)
+
+	if requested == "" {
+		requested = r.Header.Get(ApiVersionHeader)
+	}
+
+	if requested == "" {
+		return ApiVersion, nil
+	}
+
+	version, err := strconv.Atoi(requested)
+
+	if err != nil || version < minApiVersion || version > ApiVersion {
+		return 0, fmt.Errorf("unsupported api version %s, supported versions are %d to %d", requested, minApiVersion, ApiVersion)
+	}
+
+	return version, nil
+}
+
+type jsonRpcResult struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+}
+
+// versionResult adds api version to the result and converts it to the shape of
+// requested version. Results which are not json objects are left untouched.
+func versionResult(result json.RawMessage, method string, version int) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+
+	if err := json.Unmarshal(result, &fields); err != nil || fields == nil {
+		return result, nil
+	}
+
+	for i := len(responseChanges) - 1; i >= 0; i-- {
+		change := responseChanges[i]
+
+		if change.version <= version || change.method != method {
+			continue
+		}
+
+		if err := change.downgrade(fields); err != nil {
+			return nil, fmt.Errorf("failed to convert response of %s to api version %d: %w", method, version, err)
+		}
+	}
+
+	fields[apiVersionField] = json.RawMessage(strconv.Quote(strconv.Itoa(version)))
+
+	return json.Marshal(fields)
+}
+
+// versionResponse rewrites all results in (possibly batched) json rpc response
+func versionResponse(body []byte, methodsById map[string]string, version int) ([]byte, error) {
+	return rewriteResults(body, methodsById, func(method string, result json.RawMessage) (json.RawMessage, error) {
+		return versionResult(result, method, version)
+	})
+}
+
+// rewriteResults replaces every result in (possibly batched) json rpc response
+// by its rewritten version. Error responses are left untouched.
+func rewriteResults(
+	body []byte,
+	methodsById map[string]string,
+	rewrite func(method string, result json.RawMessage) (json.RawMessage, error),
+) ([]byte, error) {
+	trimmed := bytes.TrimSpace(body)
+	batch := len(trimmed) > 0 && trimmed[0] == '['
+
+	var responses []jsonRpcResult
+
+	if batch {
+		if err := json.Unmarshal(trimmed, &responses); err != nil {
+			return nil, err
+		}
+	} else {
+		var resp jsonRpcResult
+		if err := json.Unmarshal(trimmed, &resp); err != nil {
+			return nil, err
+		}
+		responses = append(responses, resp)
+	}
+
+	for i := range responses {
+		if len(responses[i].Result) == 0 {
+			continue
+		}
+
+		method := methodsById[string(responses[i].Id)]
+
+		// uri requests have single call without id
+		if method == "" && len(methodsById) == 1 {
+			for _, m := range methodsById {
+				method = m
+			}
+		}
+
+		result, err := rewrite(method, responses[i].Result)
+
+		if err != nil {
+			return nil, err
+		}
+
+		responses[i].Result = result
+	}
+
+	if batch {
+		return json.Marshal(responses)
+	}
+
+	return json.Marshal(responses[0])
+}
+
+// withApiVersion negotiates version of response shapes. Requests for
+// unsupported version are rejected, results of other requests are converted to
+// the requested version and annotated with it.
+func withApiVersion(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, streamPathPrefix) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		req := rpcRequestOf(r)
+
+		if req.err != nil {
+			// let rpc handler report malformed request
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		version, err := requestedApiVersion(r)
+
+		if err != nil {
+			writeRpcError(w, req.firstId(), http.StatusBadRequest, "Unsupported api version", RpcErrorData{
+				ErrorCode: ErrCodeUnsupportedApiVersion,
+				Message:   err.Error(),
+			})
+			return
+		}
+
+		buffered := &bufferedResponseWriter{header: w.Header()}
+
+		h.ServeHTTP(buffered, r)
+
+		if buffered.statusCode == 0 {
+			buffered.statusCode = http.StatusOK
+		}
+
+		body := buffered.body.Bytes()
+		methodsById := req.methodsById()
+
+		if versioned, err := versionResponse(body, methodsById, version); err == nil {
+			body = versioned
+		}
+
+		w.Header().Set(ApiVersionHeader, strconv.Itoa(version))
+		// body length could change
+		w.Header().Del("Content-Length")
+		w.WriteHeader(buffered.statusCode)
+		_, _ = w.Write(body)
+	})
+}
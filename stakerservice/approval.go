@@ -0,0 +1,390 @@
+package stakerservice
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// OperatorTokenHeader carries token identifying the operator in two person
+	// approval mode
+	OperatorTokenHeader = "X-Operator-Token"
+)
+
+// approvalMethods manage queued actions. They require authenticated operator,
+// but are never queued themselves.
+var approvalMethods = map[string]struct{}{
+	"approve_action": {},
+	"reject_action":  {},
+}
+
+type pendingAction struct {
+	id          string
+	method      string
+	body        []byte
+	requestId   string
+	requestedBy string
+	// caller who requested the action, approved call is executed on its behalf
+	// e.g it is charged to quota of its api identity
+	caller    rpcCaller
+	createdAt time.Time
+	expiresAt time.Time
+}
+
+// approvalQueue keeps spend capable rpc calls waiting for approval of second
+// operator. Queue is kept only in memory, so pending actions are dropped on
+// restart and must be requested again.
+type approvalQueue struct {
+	timeout time.Duration
+	logger  *logrus.Logger
+
+	// handler executing approved calls, it is not wrapped by any middleware
+	executor http.Handler
+
+	mu      sync.Mutex
+	pending map[string]*pendingAction
+}
+
+// newApprovalQueue returns nil if approval mode is disabled
+func newApprovalQueue(cfg *scfg.ApprovalConfig, logger *logrus.Logger) *approvalQueue {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	return &approvalQueue{
+		timeout: cfg.Timeout,
+		logger:  logger,
+		pending: make(map[string]*pendingAction),
+	}
+}
+
+// pruneExpired must be called with mu held
+func (q *approvalQueue) pruneExpired(now time.Time) {
+	for id, a := range q.pending {
+		if now.After(a.expiresAt) {
+			q.logger.WithFields(logrus.Fields{
+				"actionId":    id,
+				"method":      a.method,
+				"requestedBy": a.requestedBy,
+			}).Warn("Pending action expired without approval")
+
+			delete(q.pending, id)
+		}
+	}
+}
+
+func (q *approvalQueue) enqueue(method string, body []byte, requestId string, caller rpcCaller) *pendingAction {
+	now := time.Now()
+	operator := caller.Operator
+
+	a := &pendingAction{
+		id:          newRequestId(),
+		method:      method,
+		body:        body,
+		requestId:   requestId,
+		requestedBy: operator,
+		caller:      caller,
+		createdAt:   now,
+		expiresAt:   now.Add(q.timeout),
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pruneExpired(now)
+	q.pending[a.id] = a
+
+	q.logger.WithFields(logrus.Fields{
+		"actionId":    a.id,
+		"method":      method,
+		"requestedBy": operator,
+		"requestId":   requestId,
+	}).Info("Queued action waiting for approval")
+
+	return a
+}
+
+// take removes action from the queue. Action can be approved only by operator
+// other than the one who requested it, but any operator can reject it.
+func (q *approvalQueue) take(id string, operator string, approve bool) (*pendingAction, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pruneExpired(time.Now())
+
+	a, found := q.pending[id]
+
+	if !found {
+		return nil, &codedError{
+			code: ErrCodeNotFound,
+			err:  fmt.Errorf("pending action %s not found, it may have expired", id),
+		}
+	}
+
+	if approve && a.requestedBy == operator {
+		return nil, &codedError{
+			code: ErrCodeForbidden,
+			err:  fmt.Errorf("action %s must be approved by operator other than %s", id, operator),
+		}
+	}
+
+	delete(q.pending, id)
+
+	return a, nil
+}
+
+func (q *approvalQueue) list() []*pendingAction {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pruneExpired(time.Now())
+
+	actions := make([]*pendingAction, 0, len(q.pending))
+	for _, a := range q.pending {
+		actions = append(actions, a)
+	}
+
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].createdAt.Before(actions[j].createdAt)
+	})
+
+	return actions
+}
+
+// execute runs approved call on behalf of the caller who requested it and
+// returns its json rpc response
+func (q *approvalQueue) execute(a *pendingAction) (*rpctypes.RPCResponse, error) {
+	calls, err := parseRpcCalls(a.body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "/", bytes.NewReader(a.body))
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(RequestIdHeader, a.requestId)
+	// tokens are not kept with the action, identity resolved when the call was
+	// queued is used instead
+	req = withRpcRequestContext(req, &rpcRequest{
+		calls:  calls,
+		body:   a.body,
+		caller: a.caller,
+	})
+
+	recorder := &bufferedResponseWriter{header: make(http.Header)}
+	q.executor.ServeHTTP(recorder, req)
+
+	var resp rpctypes.RPCResponse
+	if err := json.Unmarshal(recorder.body.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response of approved action: %w", err)
+	}
+
+	return &resp, nil
+}
+
+func writeUnauthorized(w http.ResponseWriter, id json.RawMessage, method string) {
+	writeRpcError(w, id, http.StatusUnauthorized, "Unauthorized", RpcErrorData{
+		ErrorCode: ErrCodeUnauthorized,
+		Message:   fmt.Sprintf("method %s requires valid %s header", method, OperatorTokenHeader),
+	})
+}
+
+// withApproval queues spend capable calls until they are approved by second
+// operator. Spend capable calls and approval calls must be authenticated with
+// operator token. Queued calls must be single json rpc POST requests, so that
+// they can be executed later exactly as they were sent.
+func withApproval(h http.Handler, q *approvalQueue) http.Handler {
+	if q == nil {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := rpcRequestOf(r)
+
+		if req.err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %s", req.err), http.StatusBadRequest)
+			return
+		}
+
+		methods := req.methods()
+		id := req.firstId()
+
+		var spendMethod string
+		needsOperator := false
+
+		for _, method := range methods {
+			if _, isSpend := spendMethods[method]; isSpend {
+				needsOperator = true
+
+				if _, isApproval := approvalMethods[method]; !isApproval {
+					spendMethod = method
+				}
+			}
+		}
+
+		if !needsOperator {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		operator := req.caller.Operator
+
+		if operator == "" {
+			writeUnauthorized(w, id, methods[0])
+			return
+		}
+
+		if spendMethod == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Method != http.MethodPost || len(methods) != 1 {
+			writeRpcError(w, id, http.StatusBadRequest, "Invalid request", RpcErrorData{
+				ErrorCode: ErrCodeInvalidParams,
+				Message:   fmt.Sprintf("method %s requires approval and must be called as single json rpc POST request", spendMethod),
+			})
+			return
+		}
+
+		a := q.enqueue(spendMethod, req.body, r.Header.Get(RequestIdHeader), req.caller)
+
+		writeRpcError(w, id, http.StatusAccepted, "Approval required", RpcErrorData{
+			ErrorCode: ErrCodeApprovalRequired,
+			Message: fmt.Sprintf(
+				"action %s queued, it must be approved by another operator with approve_action before %s",
+				a.id,
+				a.expiresAt.UTC().Format(time.RFC3339),
+			),
+		})
+	})
+}
+
+func (s *StakerService) approvalOperator(ctx *rpctypes.Context) (string, error) {
+	if s.approvals == nil {
+		return "", invalidParamsf("two person approval mode is disabled")
+	}
+
+	operator := callerOf(ctx).Operator
+
+	if operator == "" {
+		return "", &codedError{
+			code: ErrCodeUnauthorized,
+			err:  fmt.Errorf("operator must be authenticated with %s header", OperatorTokenHeader),
+		}
+	}
+
+	return operator, nil
+}
+
+// pendingActions lists queued calls with their request bodies, so that they can
+// be reviewed before approval. As bodies may carry addresses and amounts of other
+// operators' calls, only operators can list them.
+func (s *StakerService) pendingActions(ctx *rpctypes.Context) (*PendingActionsResponse, error) {
+	if _, err := s.approvalOperator(ctx); err != nil {
+		return nil, err
+	}
+
+	actions := s.approvals.list()
+
+	details := make([]PendingActionDetail, len(actions))
+	for i, a := range actions {
+		details[i] = PendingActionDetail{
+			ActionId:    a.id,
+			Method:      a.method,
+			Request:     string(a.body),
+			RequestId:   a.requestId,
+			RequestedBy: a.requestedBy,
+			CreatedAt:   strconv.FormatInt(a.createdAt.Unix(), 10),
+			ExpiresAt:   strconv.FormatInt(a.expiresAt.Unix(), 10),
+		}
+	}
+
+	return &PendingActionsResponse{
+		Actions: details,
+	}, nil
+}
+
+// approveAction executes queued call on behalf of operator who requested it.
+// Errors of the executed call are returned as errors of this call.
+func (s *StakerService) approveAction(ctx *rpctypes.Context, actionId string) (*ApproveActionResponse, error) {
+	operator, err := s.approvalOperator(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := s.approvals.take(actionId, operator, true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"actionId":    a.id,
+		"method":      a.method,
+		"requestedBy": a.requestedBy,
+		"approvedBy":  operator,
+		"requestId":   a.requestId,
+	}).Info("Executing approved action")
+
+	resp, err := s.approvals.execute(a)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	return &ApproveActionResponse{
+		ActionId:    a.id,
+		Method:      a.method,
+		RequestedBy: a.requestedBy,
+		ApprovedBy:  operator,
+		Result:      resp.Result,
+	}, nil
+}
+
+func (s *StakerService) rejectAction(ctx *rpctypes.Context, actionId string) (*RejectActionResponse, error) {
+	operator, err := s.approvalOperator(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := s.approvals.take(actionId, operator, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"actionId":    a.id,
+		"method":      a.method,
+		"requestedBy": a.requestedBy,
+		"rejectedBy":  operator,
+	}).Info("Rejected pending action")
+
+	return &RejectActionResponse{
+		ActionId:   a.id,
+		Method:     a.method,
+		RejectedBy: operator,
+	}, nil
+}
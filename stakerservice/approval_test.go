@@ -0,0 +1,104 @@
+package stakerservice
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/babylonchain/btc-staker/metrics"
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/cometbft/cometbft/libs/log"
+	rpc "github.com/cometbft/cometbft/rpc/jsonrpc/server"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func tokenHashHex(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+type testStakeResponse struct{}
+
+func TestApprovedStakeIsChargedToRequesterQuota(t *testing.T) {
+	const (
+		requesterToken = "requester-operator-token"
+		approverToken  = "approver-operator-token"
+		apiToken       = "exchange-api-token"
+		stakeAmount    = btcutil.Amount(10000)
+	)
+
+	cfg := scfg.DefaultConfig()
+	cfg.ApprovalConfig.Enabled = true
+	cfg.ApprovalConfig.Operators = []string{
+		"alice:" + tokenHashHex(requesterToken),
+		"bob:" + tokenHashHex(approverToken),
+	}
+	cfg.QuotaConfig.Identities = []string{
+		"exchange:" + tokenHashHex(apiToken) + ":5:0",
+	}
+	cfg.QuotaConfig.DefaultMaxStakes = 1
+
+	identities, err := newIdentityResolver(&cfg)
+	require.NoError(t, err)
+
+	quotas, err := newStakeQuotas(cfg.QuotaConfig, metrics.NewStakerMetrics("test"))
+	require.NoError(t, err)
+
+	approvals := newApprovalQueue(cfg.ApprovalConfig, logrus.New())
+
+	routes := RoutesMap{
+		"stake": rpc.NewRPCFunc(func(ctx *rpctypes.Context, amount int64) (*testStakeResponse, error) {
+			if _, err := quotas.reserve(ctx, btcutil.Amount(amount)); err != nil {
+				return nil, err
+			}
+
+			return &testStakeResponse{}, nil
+		}, "amount"),
+	}
+
+	executor := http.NewServeMux()
+	rpc.RegisterRPCFuncs(executor, routes, log.NewNopLogger())
+	approvals.executor = executor
+
+	handler := withRpcRequest(
+		withApproval(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Fatalf("spend call must be queued instead of executed")
+		}), approvals),
+		identities,
+		rpc.DefaultConfig().MaxBodyBytes,
+	)
+
+	body := []byte(`{"jsonrpc":"2.0","id":1,"method":"stake","params":{"amount":"10000"}}`)
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set(OperatorTokenHeader, requesterToken)
+	req.Header.Set(ApiTokenHeader, apiToken)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+
+	pending := approvals.list()
+	require.Len(t, pending, 1)
+	require.Equal(t, "alice", pending[0].requestedBy)
+
+	// requester cannot approve own action
+	_, err = approvals.take(pending[0].id, "alice", true)
+	require.Error(t, err)
+
+	action, err := approvals.take(pending[0].id, "bob", true)
+	require.NoError(t, err)
+
+	resp, err := approvals.execute(action)
+	require.NoError(t, err)
+	require.Nil(t, resp.Error)
+
+	require.Len(t, quotas.usage["exchange"], 1)
+	require.Equal(t, stakeAmount, quotas.usage["exchange"][0].amount)
+	require.Empty(t, quotas.usage[scfg.DefaultQuotaIdentity])
+}
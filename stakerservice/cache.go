@@ -0,0 +1,127 @@
+package stakerservice
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	finalityProvidersCacheKeyPrefix = "finality_providers"
+	babylonParamsCacheKey           = "babylon_staking_params"
+	feeEstimateCacheKey             = "fee_estimate"
+
+	// keys of paginated responses are chosen by callers, so number of cached
+	// entries must be bounded
+	maxResponseCacheEntries = 256
+)
+
+type cacheEntry struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// responseCache is small ttl cache for responses of expensive read only rpc calls
+type responseCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+func (c *responseCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, found := c.entries[key]
+
+	if !found {
+		return nil, false
+	}
+
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	return entry.value, true
+}
+
+// put caches value for key. When cache is full, expired entries are removed
+// first, and if there are none, entry which expires soonest is evicted.
+func (c *responseCache) put(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, found := c.entries[key]; !found && len(c.entries) >= maxResponseCacheEntries {
+		c.evict(time.Now())
+	}
+
+	c.entries[key] = cacheEntry{
+		value:     value,
+		expiresAt: time.Now().Add(c.ttl),
+	}
+}
+
+// evict removes expired entries, or the entry which expires soonest if none of
+// them expired. Must be called with mu held.
+func (c *responseCache) evict(now time.Time) {
+	var oldestKey string
+	var oldestExpiresAt time.Time
+
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+			continue
+		}
+
+		if oldestKey == "" || entry.expiresAt.Before(oldestExpiresAt) {
+			oldestKey = key
+			oldestExpiresAt = entry.expiresAt
+		}
+	}
+
+	if len(c.entries) >= maxResponseCacheEntries {
+		delete(c.entries, oldestKey)
+	}
+}
+
+// invalidate removes all entries which key starts with given prefix
+func (c *responseCache) invalidate(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// cachedResponse returns cached response for given key, or loads it and caches
+// it if it is missing or expired. Errors are never cached.
+func cachedResponse[T any](c *responseCache, key string, load func() (T, error)) (T, error) {
+	if c.ttl == 0 {
+		return load()
+	}
+
+	if value, found := c.get(key); found {
+		return value.(T), nil
+	}
+
+	value, err := load()
+
+	if err != nil {
+		return value, err
+	}
+
+	c.put(key, value)
+	return value, nil
+}
@@ -0,0 +1,63 @@
+package stakerservice
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCacheIsBounded(t *testing.T) {
+	c := newResponseCache(time.Minute)
+
+	for i := 0; i < 2*maxResponseCacheEntries; i++ {
+		c.put(fmt.Sprintf("%s:%d:%d", finalityProvidersCacheKeyPrefix, i, defaultLimit), i)
+		require.LessOrEqual(t, len(c.entries), maxResponseCacheEntries)
+	}
+
+	// entry which expires soonest was evicted, latest one is kept
+	_, found := c.get(fmt.Sprintf("%s:%d:%d", finalityProvidersCacheKeyPrefix, 0, defaultLimit))
+	require.False(t, found)
+	value, found := c.get(fmt.Sprintf("%s:%d:%d", finalityProvidersCacheKeyPrefix, 2*maxResponseCacheEntries-1, defaultLimit))
+	require.True(t, found)
+	require.Equal(t, 2*maxResponseCacheEntries-1, value)
+
+	// updating cached key does not evict anything
+	c.put(feeEstimateCacheKey, 1)
+	c.put(feeEstimateCacheKey, 2)
+	require.Len(t, c.entries, maxResponseCacheEntries)
+}
+
+func TestResponseCacheEvictsExpiredEntriesFirst(t *testing.T) {
+	c := newResponseCache(time.Minute)
+
+	for i := 0; i < maxResponseCacheEntries; i++ {
+		c.put(fmt.Sprint(i), i)
+	}
+
+	// half of entries expired
+	for i := 0; i < maxResponseCacheEntries/2; i++ {
+		key := fmt.Sprint(i)
+		c.entries[key] = cacheEntry{value: i, expiresAt: time.Now().Add(-time.Second)}
+	}
+
+	c.put(babylonParamsCacheKey, "params")
+	require.Len(t, c.entries, maxResponseCacheEntries/2+1)
+
+	for i := maxResponseCacheEntries / 2; i < maxResponseCacheEntries; i++ {
+		_, found := c.get(fmt.Sprint(i))
+		require.True(t, found)
+	}
+}
+
+func TestPageParamsAreNormalised(t *testing.T) {
+	negative := -1
+	huge := maxLimit + 1
+
+	params := getPageParams(&negative, &negative)
+	require.Equal(t, PageParams{Offset: defaultOffset, Limit: defaultLimit}, params)
+
+	params = getPageParams(nil, &huge)
+	require.Equal(t, PageParams{Offset: defaultOffset, Limit: maxLimit}, params)
+}
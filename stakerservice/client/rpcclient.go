@@ -2,24 +2,124 @@ package client
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
 
+	"github.com/babylonchain/btc-staker/monitor"
+	"github.com/babylonchain/btc-staker/staker"
 	service "github.com/babylonchain/btc-staker/stakerservice"
+	"github.com/btcsuite/btcd/btcec/v2"
 	jsonrpcclient "github.com/cometbft/cometbft/rpc/jsonrpc/client"
 )
 
 type StakerServiceJsonRpcClient struct {
-	client *jsonrpcclient.Client
+	client        *jsonrpcclient.Client
+	remoteAddress string
+}
+
+// OperatorTokenEnv is environment variable with operator token sent by clients
+// created with NewStakerServiceJsonRpcClient. Token is required by daemons
+// running in two person approval mode.
+const OperatorTokenEnv = "STAKER_OPERATOR_TOKEN"
+
+// ApiTokenEnv is environment variable with api token sent by clients created
+// with NewStakerServiceJsonRpcClient. Token identifies caller for the purpose of
+// stake quotas.
+const ApiTokenEnv = "STAKER_API_TOKEN"
+
+// RequestSigningKeyEnv is environment variable with hex encoded private key
+// used by clients created with NewStakerServiceJsonRpcClient to sign requests.
+// Signed requests are required by daemons configured with client keys.
+const RequestSigningKeyEnv = "STAKER_REQUEST_SIGNING_KEY"
+
+type tokenTransport struct {
+	headers    map[string]string
+	signingKey *btcec.PrivateKey
+	base       http.RoundTripper
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for header, token := range t.headers {
+		req.Header.Set(header, token)
+	}
+
+	if t.signingKey != nil {
+		if err := service.SignRequest(req, t.signingKey); err != nil {
+			return nil, fmt.Errorf("failed to sign request: %w", err)
+		}
+	}
+
+	return t.base.RoundTrip(req)
 }
 
 // TODO Add some kind of timeout config
 func NewStakerServiceJsonRpcClient(remoteAddress string) (*StakerServiceJsonRpcClient, error) {
-	client, err := jsonrpcclient.New(remoteAddress)
+	var signingKey *btcec.PrivateKey
+
+	if keyHex := os.Getenv(RequestSigningKeyEnv); keyHex != "" {
+		key, err := service.ParseRequestSigningKey(keyHex)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", RequestSigningKeyEnv, err)
+		}
+
+		signingKey = key
+	}
+
+	return newStakerServiceJsonRpcClient(remoteAddress, os.Getenv(OperatorTokenEnv), os.Getenv(ApiTokenEnv), signingKey)
+}
+
+// NewStakerServiceJsonRpcClientWithToken creates client which authenticates
+// every request with given operator token. Empty token is not sent.
+func NewStakerServiceJsonRpcClientWithToken(remoteAddress string, operatorToken string) (*StakerServiceJsonRpcClient, error) {
+	return newStakerServiceJsonRpcClient(remoteAddress, operatorToken, "", nil)
+}
+
+func newStakerServiceJsonRpcClient(
+	remoteAddress string,
+	operatorToken string,
+	apiToken string,
+	signingKey *btcec.PrivateKey,
+) (*StakerServiceJsonRpcClient, error) {
+	httpClient, err := jsonrpcclient.DefaultHTTPClient(remoteAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := map[string]string{
+		// responses keep the shape this client was built against, even if
+		// daemon is upgraded
+		service.ApiVersionHeader: strconv.Itoa(service.ApiVersion),
+	}
+
+	if operatorToken != "" {
+		headers[service.OperatorTokenHeader] = operatorToken
+	}
+
+	if apiToken != "" {
+		headers[service.ApiTokenHeader] = apiToken
+	}
+
+	if len(headers) > 0 {
+		base := httpClient.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+
+		httpClient.Transport = &tokenTransport{headers: headers, signingKey: signingKey, base: base}
+	}
+
+	client, err := jsonrpcclient.NewWithHTTPClient(remoteAddress, httpClient)
 	if err != nil {
 		return nil, err
 	}
 
 	return &StakerServiceJsonRpcClient{
-		client: client,
+		client:        client,
+		remoteAddress: remoteAddress,
 	}, nil
 }
 
@@ -32,6 +132,15 @@ func (c *StakerServiceJsonRpcClient) Health(ctx context.Context) (*service.Resul
 	return result, nil
 }
 
+func (c *StakerServiceJsonRpcClient) DelegationStates(ctx context.Context) (*service.DelegationStatesResponse, error) {
+	result := new(service.DelegationStatesResponse)
+	_, err := c.client.Call(ctx, "delegation_states", map[string]interface{}{}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (c *StakerServiceJsonRpcClient) ListOutputs(ctx context.Context) (*service.OutputsResponse, error) {
 	result := new(service.OutputsResponse)
 	_, err := c.client.Call(ctx, "list_outputs", map[string]interface{}{}, result)
@@ -41,6 +150,32 @@ func (c *StakerServiceJsonRpcClient) ListOutputs(ctx context.Context) (*service.
 	return result, nil
 }
 
+func (c *StakerServiceJsonRpcClient) ConsolidateOutputs(
+	ctx context.Context,
+	destinationAddress string,
+	maxUtxoValue *int,
+	feeRate *int,
+) (*service.ConsolidateOutputsResponse, error) {
+	result := new(service.ConsolidateOutputsResponse)
+
+	params := make(map[string]interface{})
+	params["destinationAddress"] = destinationAddress
+
+	if maxUtxoValue != nil {
+		params["maxUtxoValue"] = maxUtxoValue
+	}
+
+	if feeRate != nil {
+		params["feeRate"] = feeRate
+	}
+
+	_, err := c.client.Call(ctx, "consolidate_outputs", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (c *StakerServiceJsonRpcClient) BabylonFinalityProviders(ctx context.Context, offset *int, limit *int) (*service.FinalityProvidersResponse, error) {
 	result := new(service.FinalityProvidersResponse)
 
@@ -61,152 +196,1307 @@ func (c *StakerServiceJsonRpcClient) BabylonFinalityProviders(ctx context.Contex
 	return result, nil
 }
 
-func (c *StakerServiceJsonRpcClient) Stake(
-	ctx context.Context,
-	stakerAddress string,
-	stakingAmount int64,
-	fpPks []string,
-	stakingTimeBlocks int64,
-) (*service.ResultStake, error) {
-	result := new(service.ResultStake)
+func (c *StakerServiceJsonRpcClient) BabylonStakingParams(ctx context.Context) (*service.BabylonStakingParamsResponse, error) {
+	result := new(service.BabylonStakingParamsResponse)
+	_, err := c.client.Call(ctx, "babylon_staking_params", map[string]interface{}{}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) FeeEstimate(ctx context.Context) (*service.FeeEstimateResponse, error) {
+	result := new(service.FeeEstimateResponse)
+	_, err := c.client.Call(ctx, "fee_estimate", map[string]interface{}{}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) FeeBudget(ctx context.Context) (*service.FeeBudgetResponse, error) {
+	result := new(service.FeeBudgetResponse)
+	_, err := c.client.Call(ctx, "fee_budget", map[string]interface{}{}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) FeeAnalytics(ctx context.Context, days *int) (*service.FeeAnalyticsResponse, error) {
+	result := new(service.FeeAnalyticsResponse)
 
 	params := make(map[string]interface{})
-	params["stakerAddress"] = stakerAddress
-	params["stakingAmount"] = stakingAmount
-	params["fpBtcPks"] = fpPks
-	params["stakingTimeBlocks"] = stakingTimeBlocks
 
-	_, err := c.client.Call(ctx, "stake", params, result)
+	if days != nil {
+		params["days"] = days
+	}
+
+	_, err := c.client.Call(ctx, "fee_analytics", params, result)
 	if err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
-func (c *StakerServiceJsonRpcClient) ListStakingTransactions(ctx context.Context, offset *int, limit *int) (*service.ListStakingTransactionsResponse, error) {
-	result := new(service.ListStakingTransactionsResponse)
+func (c *StakerServiceJsonRpcClient) ConfirmationSla(ctx context.Context, days *int) (*service.ConfirmationSlaResponse, error) {
+	result := new(service.ConfirmationSlaResponse)
 
 	params := make(map[string]interface{})
 
-	if limit != nil {
-		params["limit"] = limit
+	if days != nil {
+		params["days"] = days
 	}
 
-	if offset != nil {
-		params["offset"] = offset
+	_, err := c.client.Call(ctx, "confirmation_sla", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) UtxoBlocklist(ctx context.Context) (*service.UtxoBlocklistResponse, error) {
+	result := new(service.UtxoBlocklistResponse)
+	_, err := c.client.Call(ctx, "utxo_blocklist", map[string]interface{}{}, result)
+	if err != nil {
+		return nil, err
 	}
+	return result, nil
+}
 
-	_, err := c.client.Call(ctx, "list_staking_transactions", params, result)
+func (c *StakerServiceJsonRpcClient) UtxoBlocklistAdd(
+	ctx context.Context,
+	outpoints []string,
+	addresses []string,
+	reason string,
+) (*service.UtxoBlocklistResponse, error) {
+	result := new(service.UtxoBlocklistResponse)
+
+	params := make(map[string]interface{})
+	params["outpoints"] = outpoints
+	params["addresses"] = addresses
+	params["reason"] = reason
+
+	_, err := c.client.Call(ctx, "utxo_blocklist_add", params, result)
 	if err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
-func (c *StakerServiceJsonRpcClient) WithdrawableTransactions(ctx context.Context, offset *int, limit *int) (*service.WithdrawableTransactionsResponse, error) {
-	result := new(service.WithdrawableTransactionsResponse)
+func (c *StakerServiceJsonRpcClient) UtxoBlocklistRemove(
+	ctx context.Context,
+	outpoints []string,
+	addresses []string,
+) (*service.UtxoBlocklistResponse, error) {
+	result := new(service.UtxoBlocklistResponse)
+
+	params := make(map[string]interface{})
+	params["outpoints"] = outpoints
+	params["addresses"] = addresses
+
+	_, err := c.client.Call(ctx, "utxo_blocklist_remove", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) FrozenOutputs(ctx context.Context) (*service.FrozenOutputsResponse, error) {
+	result := new(service.FrozenOutputsResponse)
+	_, err := c.client.Call(ctx, "frozen_outputs", map[string]interface{}{}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) WalletTransactions(ctx context.Context, label *string, limit *int) (*service.WalletTransactionsResponse, error) {
+	result := new(service.WalletTransactionsResponse)
 
 	params := make(map[string]interface{})
 
+	if label != nil {
+		params["label"] = label
+	}
+
 	if limit != nil {
 		params["limit"] = limit
 	}
 
-	if offset != nil {
-		params["offset"] = offset
+	_, err := c.client.Call(ctx, "wallet_transactions", params, result)
+	if err != nil {
+		return nil, err
 	}
+	return result, nil
+}
 
-	_, err := c.client.Call(ctx, "withdrawable_transactions", params, result)
+func (c *StakerServiceJsonRpcClient) PendingActions(ctx context.Context) (*service.PendingActionsResponse, error) {
+	result := new(service.PendingActionsResponse)
+	_, err := c.client.Call(ctx, "pending_actions", map[string]interface{}{}, result)
 	if err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
-func (c *StakerServiceJsonRpcClient) StakingDetails(ctx context.Context, txHash string) (*service.StakingDetails, error) {
-	result := new(service.StakingDetails)
+func (c *StakerServiceJsonRpcClient) ApproveAction(ctx context.Context, actionId string) (*service.ApproveActionResponse, error) {
+	result := new(service.ApproveActionResponse)
 
 	params := make(map[string]interface{})
-	params["stakingTxHash"] = txHash
+	params["actionId"] = actionId
 
-	_, err := c.client.Call(ctx, "staking_details", params, result)
+	_, err := c.client.Call(ctx, "approve_action", params, result)
 	if err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
-func (c *StakerServiceJsonRpcClient) SpendStakingTransaction(ctx context.Context, txHash string) (*service.SpendTxDetails, error) {
-	result := new(service.SpendTxDetails)
+func (c *StakerServiceJsonRpcClient) RejectAction(ctx context.Context, actionId string) (*service.RejectActionResponse, error) {
+	result := new(service.RejectActionResponse)
 
 	params := make(map[string]interface{})
-	params["stakingTxHash"] = txHash
+	params["actionId"] = actionId
 
-	_, err := c.client.Call(ctx, "spend_stake", params, result)
+	_, err := c.client.Call(ctx, "reject_action", params, result)
 	if err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
-func (c *StakerServiceJsonRpcClient) WatchStaking(
+func (c *StakerServiceJsonRpcClient) FreezeOutput(
+	ctx context.Context,
+	outpoint string,
+	note string,
+) (*service.FrozenOutputsResponse, error) {
+	result := new(service.FrozenOutputsResponse)
+
+	params := make(map[string]interface{})
+	params["outpoint"] = outpoint
+	params["note"] = note
+
+	_, err := c.client.Call(ctx, "freeze_output", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) UnfreezeOutput(
+	ctx context.Context,
+	outpoint string,
+) (*service.FrozenOutputsResponse, error) {
+	result := new(service.FrozenOutputsResponse)
+
+	params := make(map[string]interface{})
+	params["outpoint"] = outpoint
+
+	_, err := c.client.Call(ctx, "unfreeze_output", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// StakeOptions are optional parameters of stake requests. Nil options, or zero
+// fields, leave the defaults of the daemon.
+type StakeOptions struct {
+	Metadata         map[string]string
+	RequestId        string
+	MinConfirmations *int
+	AutoWithdraw     bool
+}
+
+func (o *StakeOptions) addParams(params map[string]interface{}) {
+	if o == nil {
+		return
+	}
+
+	if o.MinConfirmations != nil {
+		params["minConfirmations"] = o.MinConfirmations
+	}
+
+	if len(o.Metadata) > 0 {
+		params["metadata"] = o.Metadata
+	}
+
+	if o.RequestId != "" {
+		params["requestId"] = o.RequestId
+	}
+
+	if o.AutoWithdraw {
+		params["autoWithdraw"] = o.AutoWithdraw
+	}
+}
+
+func (c *StakerServiceJsonRpcClient) Stake(
 	ctx context.Context,
-	stakingTx string,
-	stakingTime int,
-	stakingValue int,
-	stakerBtcPk string,
-	fpBtcPks []string,
-	slashingTx string,
-	slashingTxSig string,
-	stakerBabylonPk string,
 	stakerAddress string,
-	stakerBabylonSig string,
-	stakerBtcSig string,
-	unbondingTx string,
-	slashUnbondingTx string,
-	slashUnbondingTxSig string,
-	unbondingTime int,
-	popType int,
+	stakingAmount int64,
+	fpPks []string,
+	stakingTimeBlocks int64,
+	opts *StakeOptions,
 ) (*service.ResultStake, error) {
+	result := new(service.ResultStake)
+
+	params := make(map[string]interface{})
+	params["stakerAddress"] = stakerAddress
+	params["stakingAmount"] = stakingAmount
+	params["fpBtcPks"] = fpPks
+	params["stakingTimeBlocks"] = stakingTimeBlocks
+
+	opts.addParams(params)
+
+	_, err := c.client.Call(ctx, "stake", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
 
+// StakeWithPreset stakes with finality providers and staking time of given
+// preset. Zero stakingAmount selects the only amount tier of the preset.
+func (c *StakerServiceJsonRpcClient) StakeWithPreset(
+	ctx context.Context,
+	stakerAddress string,
+	stakingAmount int64,
+	preset string,
+	opts *StakeOptions,
+) (*service.ResultStake, error) {
 	result := new(service.ResultStake)
+
 	params := make(map[string]interface{})
-	params["stakingTx"] = stakingTx
-	params["stakingTime"] = stakingTime
-	params["stakingValue"] = stakingValue
-	params["stakerBtcPk"] = stakerBtcPk
-	params["fpBtcPks"] = fpBtcPks
-	params["slashingTx"] = slashingTx
-	params["slashingTxSig"] = slashingTxSig
-	params["stakerBabylonPk"] = stakerBabylonPk
 	params["stakerAddress"] = stakerAddress
-	params["stakerBabylonSig"] = stakerBabylonSig
-	params["stakerBtcSig"] = stakerBtcSig
-	params["unbondingTx"] = unbondingTx
-	params["slashUnbondingTx"] = slashUnbondingTx
-	params["slashUnbondingTxSig"] = slashUnbondingTxSig
-	params["unbondingTime"] = unbondingTime
-	params["popType"] = popType
+	params["stakingAmount"] = stakingAmount
+	params["preset"] = preset
 
-	_, err := c.client.Call(ctx, "watch_staking_tx", params, result)
+	opts.addParams(params)
+
+	_, err := c.client.Call(ctx, "stake", params, result)
 	if err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
-func (c *StakerServiceJsonRpcClient) UnbondStaking(ctx context.Context, txHash string, feeRate *int) (*service.UnbondingResponse, error) {
-	result := new(service.UnbondingResponse)
+func (c *StakerServiceJsonRpcClient) StakeExternal(
+	ctx context.Context,
+	fundingAddress string,
+	stakerPk string,
+	stakingAmount int64,
+	fpPks []string,
+	stakingTimeBlocks int64,
+	metadata map[string]string,
+	requestId string,
+	minConfirmations *int,
+) (*service.ResultStakeExternal, error) {
+	result := new(service.ResultStakeExternal)
 
 	params := make(map[string]interface{})
-	params["stakingTxHash"] = txHash
+	params["fundingAddress"] = fundingAddress
+	params["stakerPk"] = stakerPk
+	params["stakingAmount"] = stakingAmount
+	params["fpBtcPks"] = fpPks
+	params["stakingTimeBlocks"] = stakingTimeBlocks
 
-	if feeRate != nil {
-		params["feeRate"] = feeRate
+	if minConfirmations != nil {
+		params["minConfirmations"] = minConfirmations
 	}
 
-	_, err := c.client.Call(ctx, "unbond_staking", params, result)
+	if len(metadata) > 0 {
+		params["metadata"] = metadata
+	}
+
+	if requestId != "" {
+		params["requestId"] = requestId
+	}
+
+	_, err := c.client.Call(ctx, "stake_external", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TimeFilter restricts listed transactions to those created, or which changed
+// state, in given time ranges. Times are unix timestamps in seconds, after is
+// inclusive and before is exclusive. Nil fields do not restrict transactions.
+type TimeFilter struct {
+	CreatedAfter     *int64
+	CreatedBefore    *int64
+	TransitionState  *string
+	TransitionAfter  *int64
+	TransitionBefore *int64
+}
+
+func (f *TimeFilter) addParams(params map[string]interface{}) {
+	if f == nil {
+		return
+	}
+
+	if f.CreatedAfter != nil {
+		params["createdAfter"] = f.CreatedAfter
+	}
+
+	if f.CreatedBefore != nil {
+		params["createdBefore"] = f.CreatedBefore
+	}
+
+	if f.TransitionState != nil {
+		params["transitionState"] = f.TransitionState
+	}
+
+	if f.TransitionAfter != nil {
+		params["transitionAfter"] = f.TransitionAfter
+	}
+
+	if f.TransitionBefore != nil {
+		params["transitionBefore"] = f.TransitionBefore
+	}
+}
+
+func (c *StakerServiceJsonRpcClient) ListStakingTransactions(
+	ctx context.Context,
+	offset *int,
+	limit *int,
+	metadataFilter map[string]string,
+	group *string,
+	timeFilter *TimeFilter,
+) (*service.ListStakingTransactionsResponse, error) {
+	result := new(service.ListStakingTransactionsResponse)
+
+	params := make(map[string]interface{})
+
+	if limit != nil {
+		params["limit"] = limit
+	}
+
+	if offset != nil {
+		params["offset"] = offset
+	}
+
+	if len(metadataFilter) > 0 {
+		params["metadataFilter"] = metadataFilter
+	}
+
+	if group != nil {
+		params["group"] = group
+	}
+
+	timeFilter.addParams(params)
+
+	_, err := c.client.Call(ctx, "list_staking_transactions", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// StakingStatusLight returns trimmed status of delegations. If ifNoneMatch is
+// etag of the current status, response contains only the etag.
+func (c *StakerServiceJsonRpcClient) StakingStatusLight(
+	ctx context.Context,
+	offset *int,
+	limit *int,
+	ifNoneMatch *string,
+) (*service.StakingStatusLightResponse, error) {
+	result := new(service.StakingStatusLightResponse)
+
+	params := make(map[string]interface{})
+
+	if limit != nil {
+		params["limit"] = limit
+	}
+
+	if offset != nil {
+		params["offset"] = offset
+	}
+
+	if ifNoneMatch != nil {
+		params["ifNoneMatch"] = ifNoneMatch
+	}
+
+	_, err := c.client.Call(ctx, "staking_status_light", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) WithdrawableTransactions(ctx context.Context, offset *int, limit *int) (*service.WithdrawableTransactionsResponse, error) {
+	result := new(service.WithdrawableTransactionsResponse)
+
+	params := make(map[string]interface{})
+
+	if limit != nil {
+		params["limit"] = limit
+	}
+
+	if offset != nil {
+		params["offset"] = offset
+	}
+
+	_, err := c.client.Call(ctx, "withdrawable_transactions", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) SearchStakingTransactions(
+	ctx context.Context,
+	query *string,
+	minAmount, maxAmount *int64,
+	offset, limit *int,
+	timeFilter *TimeFilter,
+) (*service.SearchStakingTransactionsResponse, error) {
+	result := new(service.SearchStakingTransactionsResponse)
+
+	params := make(map[string]interface{})
+
+	if query != nil {
+		params["query"] = query
+	}
+
+	if minAmount != nil {
+		params["minAmount"] = minAmount
+	}
+
+	if maxAmount != nil {
+		params["maxAmount"] = maxAmount
+	}
+
+	if limit != nil {
+		params["limit"] = limit
+	}
+
+	if offset != nil {
+		params["offset"] = offset
+	}
+
+	timeFilter.addParams(params)
+
+	_, err := c.client.Call(ctx, "search_staking_transactions", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) StakingReport(ctx context.Context, from *int64, to *int64) (*service.StakingReportResponse, error) {
+	result := new(service.StakingReportResponse)
+
+	params := make(map[string]interface{})
+
+	if from != nil {
+		params["from"] = from
+	}
+
+	if to != nil {
+		params["to"] = to
+	}
+
+	_, err := c.client.Call(ctx, "staking_report", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) StakingSummary(ctx context.Context, group *string) (*service.StakingSummaryResponse, error) {
+	result := new(service.StakingSummaryResponse)
+
+	params := make(map[string]interface{})
+
+	if group != nil {
+		params["group"] = group
+	}
+
+	_, err := c.client.Call(ctx, "staking_summary", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) GroupSummaries(ctx context.Context) (*service.GroupSummariesResponse, error) {
+	result := new(service.GroupSummariesResponse)
+
+	params := make(map[string]interface{})
+
+	_, err := c.client.Call(ctx, "group_summaries", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) SetDelegationGroup(
+	ctx context.Context,
+	stakingTxHashes []string,
+	group string,
+) (*service.SetDelegationGroupResponse, error) {
+	result := new(service.SetDelegationGroupResponse)
+
+	params := make(map[string]interface{})
+	params["stakingTxHashes"] = stakingTxHashes
+	params["group"] = group
+
+	_, err := c.client.Call(ctx, "set_delegation_group", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) BabylonRewards(ctx context.Context) (*service.BabylonRewardsResponse, error) {
+	result := new(service.BabylonRewardsResponse)
+
+	params := make(map[string]interface{})
+
+	_, err := c.client.Call(ctx, "babylon_rewards", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) WithdrawBabylonRewards(
+	ctx context.Context,
+	stakeholderType *string,
+	recipient *string,
+) (*service.WithdrawRewardsResponse, error) {
+	result := new(service.WithdrawRewardsResponse)
+
+	params := make(map[string]interface{})
+
+	if stakeholderType != nil {
+		params["stakeholderType"] = stakeholderType
+	}
+
+	if recipient != nil {
+		params["recipient"] = recipient
+	}
+
+	_, err := c.client.Call(ctx, "withdraw_babylon_rewards", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) ProveOwnership(
+	ctx context.Context,
+	stakingTxHash string,
+	challenge string,
+) (*service.OwnershipProofResponse, error) {
+	result := new(service.OwnershipProofResponse)
+
+	params := make(map[string]interface{})
+	params["stakingTxHash"] = stakingTxHash
+	params["challenge"] = challenge
+
+	_, err := c.client.Call(ctx, "prove_ownership", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) VerifyOwnershipProof(
+	ctx context.Context,
+	stakingTxHash string,
+	stakerPk string,
+	challenge string,
+	signature string,
+) (*service.VerifyOwnershipProofResponse, error) {
+	result := new(service.VerifyOwnershipProofResponse)
+
+	params := make(map[string]interface{})
+	params["stakingTxHash"] = stakingTxHash
+	params["stakerPk"] = stakerPk
+	params["challenge"] = challenge
+	params["signature"] = signature
+
+	_, err := c.client.Call(ctx, "verify_ownership_proof", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) SignMessage(
+	ctx context.Context,
+	stakerAddress string,
+	message string,
+) (*service.SignMessageResponse, error) {
+	result := new(service.SignMessageResponse)
+
+	params := make(map[string]interface{})
+	params["stakerAddress"] = stakerAddress
+	params["message"] = message
+
+	_, err := c.client.Call(ctx, "sign_message", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) ProofOfReserves(
+	ctx context.Context,
+	message string,
+) (*service.ProofOfReservesResponse, error) {
+	result := new(service.ProofOfReservesResponse)
+
+	params := make(map[string]interface{})
+	params["message"] = message
+
+	_, err := c.client.Call(ctx, "proof_of_reserves", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) VerifyMessage(
+	ctx context.Context,
+	address string,
+	message string,
+	signature string,
+) (*service.VerifyMessageResponse, error) {
+	result := new(service.VerifyMessageResponse)
+
+	params := make(map[string]interface{})
+	params["address"] = address
+	params["message"] = message
+	params["signature"] = signature
+
+	_, err := c.client.Call(ctx, "verify_message", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) StakingDetails(ctx context.Context, txHash string) (*service.StakingDetails, error) {
+	result := new(service.StakingDetails)
+
+	params := make(map[string]interface{})
+	params["stakingTxHash"] = txHash
+
+	_, err := c.client.Call(ctx, "staking_details", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) StakingDetailsBatch(ctx context.Context, txHashes []string) (*service.StakingDetailsBatchResponse, error) {
+	result := new(service.StakingDetailsBatchResponse)
+
+	params := make(map[string]interface{})
+	params["stakingTxHashes"] = txHashes
+
+	_, err := c.client.Call(ctx, "staking_details_batch", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) StakingScriptInfo(ctx context.Context, txHash string) (*service.StakingScriptInfoResponse, error) {
+	result := new(service.StakingScriptInfoResponse)
+
+	params := make(map[string]interface{})
+	params["stakingTxHash"] = txHash
+
+	_, err := c.client.Call(ctx, "staking_script_info", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) DelegationGraph(ctx context.Context, txHash string) (*service.DelegationGraphResponse, error) {
+	result := new(service.DelegationGraphResponse)
+
+	params := make(map[string]interface{})
+	params["stakingTxHash"] = txHash
+
+	_, err := c.client.Call(ctx, "delegation_graph", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) GenerateMuSig2Nonce(
+	ctx context.Context,
+	sessionId string,
+	signerAddress string,
+	message *string,
+	ttlSeconds *int64,
+) (*service.MuSig2NonceResponse, error) {
+	result := new(service.MuSig2NonceResponse)
+
+	params := make(map[string]interface{})
+	params["sessionId"] = sessionId
+	params["signerAddress"] = signerAddress
+
+	if message != nil {
+		params["message"] = *message
+	}
+
+	if ttlSeconds != nil {
+		params["ttlSeconds"] = *ttlSeconds
+	}
+
+	_, err := c.client.Call(ctx, "generate_musig2_nonce", params, result)
+
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) MuSig2Nonce(ctx context.Context, sessionId string) (*service.MuSig2NonceResponse, error) {
+	result := new(service.MuSig2NonceResponse)
+
+	params := make(map[string]interface{})
+	params["sessionId"] = sessionId
+
+	_, err := c.client.Call(ctx, "musig2_nonce", params, result)
+
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) StakingPresets(ctx context.Context) (*service.StakingPresetsResponse, error) {
+	result := new(service.StakingPresetsResponse)
+
+	_, err := c.client.Call(ctx, "staking_presets", map[string]interface{}{}, result)
+
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) SetStakingPreset(
+	ctx context.Context,
+	name string,
+	fpPks []string,
+	stakingTimeBlocks int64,
+	amountTiers []int64,
+	maxFeeRate *int64,
+) (*service.StakingPresetsResponse, error) {
+	result := new(service.StakingPresetsResponse)
+
+	params := make(map[string]interface{})
+	params["name"] = name
+	params["fpBtcPks"] = fpPks
+	params["stakingTimeBlocks"] = stakingTimeBlocks
+
+	if len(amountTiers) > 0 {
+		params["amountTiers"] = amountTiers
+	}
+
+	if maxFeeRate != nil {
+		params["maxFeeRate"] = *maxFeeRate
+	}
+
+	_, err := c.client.Call(ctx, "set_staking_preset", params, result)
+
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) DeleteStakingPreset(ctx context.Context, name string) (*service.StakingPresetsResponse, error) {
+	result := new(service.StakingPresetsResponse)
+
+	params := make(map[string]interface{})
+	params["name"] = name
+
+	_, err := c.client.Call(ctx, "delete_staking_preset", params, result)
+
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) ComputeSigHashes(ctx context.Context, txHex string, stakingTxHash *string) (*service.ComputeSigHashesResponse, error) {
+	result := new(service.ComputeSigHashesResponse)
+
+	params := make(map[string]interface{})
+	params["tx"] = txHex
+
+	if stakingTxHash != nil {
+		params["stakingTxHash"] = *stakingTxHash
+	}
+
+	_, err := c.client.Call(ctx, "compute_sighashes", params, result)
+
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) ExitTemplates(ctx context.Context, txHash string) (*staker.ExportedExitTemplates, error) {
+	result := new(staker.ExportedExitTemplates)
+
+	params := make(map[string]interface{})
+	params["stakingTxHash"] = txHash
+
+	_, err := c.client.Call(ctx, "exit_templates", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) SpendStakingTransaction(ctx context.Context, txHash string) (*service.SpendTxDetails, error) {
+	result := new(service.SpendTxDetails)
+
+	params := make(map[string]interface{})
+	params["stakingTxHash"] = txHash
+
+	_, err := c.client.Call(ctx, "spend_stake", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) WatchStaking(
+	ctx context.Context,
+	stakingTx string,
+	stakingTime int,
+	stakingValue int,
+	stakerBtcPk string,
+	fpBtcPks []string,
+	slashingTx string,
+	slashingTxSig string,
+	stakerBabylonPk string,
+	stakerAddress string,
+	stakerBabylonSig string,
+	stakerBtcSig string,
+	unbondingTx string,
+	slashUnbondingTx string,
+	slashUnbondingTxSig string,
+	unbondingTime int,
+	popType int,
+	metadata map[string]string,
+) (*service.ResultStake, error) {
+
+	result := new(service.ResultStake)
+	params := make(map[string]interface{})
+	params["stakingTx"] = stakingTx
+	params["stakingTime"] = stakingTime
+	params["stakingValue"] = stakingValue
+	params["stakerBtcPk"] = stakerBtcPk
+	params["fpBtcPks"] = fpBtcPks
+	params["slashingTx"] = slashingTx
+	params["slashingTxSig"] = slashingTxSig
+	params["stakerBabylonPk"] = stakerBabylonPk
+	params["stakerAddress"] = stakerAddress
+	params["stakerBabylonSig"] = stakerBabylonSig
+	params["stakerBtcSig"] = stakerBtcSig
+	params["unbondingTx"] = unbondingTx
+	params["slashUnbondingTx"] = slashUnbondingTx
+	params["slashUnbondingTxSig"] = slashUnbondingTxSig
+	params["unbondingTime"] = unbondingTime
+	params["popType"] = popType
+
+	if len(metadata) > 0 {
+		params["metadata"] = metadata
+	}
+
+	_, err := c.client.Call(ctx, "watch_staking_tx", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) UnbondStaking(
+	ctx context.Context,
+	txHash string,
+	feeRate *int,
+	destinationAddress *string,
+) (*service.UnbondingResponse, error) {
+	result := new(service.UnbondingResponse)
+
+	params := make(map[string]interface{})
+	params["stakingTxHash"] = txHash
+
+	if feeRate != nil {
+		params["feeRate"] = feeRate
+	}
+
+	if destinationAddress != nil {
+		params["destinationAddress"] = *destinationAddress
+	}
+
+	_, err := c.client.Call(ctx, "unbond_staking", params, result)
+
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) SetUnbondingOverrides(
+	ctx context.Context,
+	txHash string,
+	unbondingTime *int64,
+	unbondingFeeRate *int64,
+) (*service.StakingDetails, error) {
+	result := new(service.StakingDetails)
+
+	params := make(map[string]interface{})
+	params["stakingTxHash"] = txHash
+
+	if unbondingTime != nil {
+		params["unbondingTime"] = *unbondingTime
+	}
+
+	if unbondingFeeRate != nil {
+		params["unbondingFeeRate"] = *unbondingFeeRate
+	}
+
+	_, err := c.client.Call(ctx, "set_unbonding_overrides", params, result)
+
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) SetAutoWithdraw(
+	ctx context.Context,
+	txHash string,
+	enabled bool,
+) (*service.StakingDetails, error) {
+	result := new(service.StakingDetails)
+
+	params := make(map[string]interface{})
+	params["stakingTxHash"] = txHash
+	params["enabled"] = enabled
+
+	_, err := c.client.Call(ctx, "set_auto_withdraw", params, result)
+
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) BumpStakingFee(ctx context.Context, txHash string, feeRate *int) (*service.BumpStakingFeeResponse, error) {
+	result := new(service.BumpStakingFeeResponse)
+
+	params := make(map[string]interface{})
+	params["stakingTxHash"] = txHash
+
+	if feeRate != nil {
+		params["feeRate"] = feeRate
+	}
+
+	_, err := c.client.Call(ctx, "bump_staking_fee", params, result)
+
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) UnbondAll(ctx context.Context, intervalMs *int, dryRun bool) (*service.UnbondAllResponse, error) {
+	result := new(service.UnbondAllResponse)
+
+	params := make(map[string]interface{})
+	params["dryRun"] = dryRun
+
+	if intervalMs != nil {
+		params["intervalMs"] = intervalMs
+	}
+
+	_, err := c.client.Call(ctx, "unbond_all", params, result)
+
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) DevUnbondingSigHash(ctx context.Context, txHash string) (*service.UnbondingSigHashResponse, error) {
+	result := new(service.UnbondingSigHashResponse)
+
+	params := make(map[string]interface{})
+	params["stakingTxHash"] = txHash
+
+	_, err := c.client.Call(ctx, "dev_unbonding_sighash", params, result)
+
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) DevSubmitCovenantUnbondingSigs(
+	ctx context.Context,
+	txHash string,
+	covenantPks []string,
+	covenantSigs []string,
+) (*service.SubmitCovenantSignaturesResponse, error) {
+	result := new(service.SubmitCovenantSignaturesResponse)
+
+	params := make(map[string]interface{})
+	params["stakingTxHash"] = txHash
+	params["covenantPks"] = covenantPks
+	params["covenantSigs"] = covenantSigs
+
+	_, err := c.client.Call(ctx, "dev_submit_covenant_unbonding_sigs", params, result)
+
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) DevSignedUnbondingTx(ctx context.Context, txHash string) (*service.SignedUnbondingTxResponse, error) {
+	result := new(service.SignedUnbondingTxResponse)
+
+	params := make(map[string]interface{})
+	params["stakingTxHash"] = txHash
+
+	_, err := c.client.Call(ctx, "dev_signed_unbonding_tx", params, result)
+
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) MonitoredTransactions(ctx context.Context) (*service.MonitoredTransactionsResponse, error) {
+	result := new(service.MonitoredTransactionsResponse)
+	_, err := c.client.Call(ctx, "monitored_transactions", map[string]interface{}{}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) MonitoredTransaction(ctx context.Context, stakingTxHash string) (*monitor.TransactionSummary, error) {
+	result := new(monitor.TransactionSummary)
+
+	params := make(map[string]interface{})
+	params["stakingTxHash"] = stakingTxHash
+
+	_, err := c.client.Call(ctx, "monitored_transaction", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) RetryQueue(ctx context.Context) (*service.RetryQueueResponse, error) {
+	result := new(service.RetryQueueResponse)
+	_, err := c.client.Call(ctx, "retry_queue", map[string]interface{}{}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) ScheduleOperation(
+	ctx context.Context,
+	operation string,
+	stakingTxHash string,
+	executeAtHeight *int,
+	executeAtTime *int64,
+	note *string,
+) (*service.ScheduledOperationDetail, error) {
+	result := new(service.ScheduledOperationDetail)
+
+	params := make(map[string]interface{})
+	params["operation"] = operation
+	params["stakingTxHash"] = stakingTxHash
+
+	if executeAtHeight != nil {
+		params["executeAtHeight"] = executeAtHeight
+	}
+
+	if executeAtTime != nil {
+		params["executeAtTime"] = executeAtTime
+	}
+
+	if note != nil {
+		params["note"] = note
+	}
+
+	_, err := c.client.Call(ctx, "schedule_operation", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) CancelScheduledOperation(ctx context.Context, id string) (*service.CancelScheduledOperationResponse, error) {
+	result := new(service.CancelScheduledOperationResponse)
+
+	params := make(map[string]interface{})
+	params["id"] = id
+
+	_, err := c.client.Call(ctx, "cancel_scheduled_operation", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) ScheduledOperations(ctx context.Context) (*service.ScheduledOperationsResponse, error) {
+	result := new(service.ScheduledOperationsResponse)
+	_, err := c.client.Call(ctx, "scheduled_operations", map[string]interface{}{}, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) FlushRetryQueue(ctx context.Context, operation *string) (*service.FlushRetryQueueResponse, error) {
+	result := new(service.FlushRetryQueueResponse)
+
+	params := make(map[string]interface{})
+
+	if operation != nil {
+		params["operation"] = operation
+	}
+
+	_, err := c.client.Call(ctx, "flush_retry_queue", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) RetryBabylon(ctx context.Context, stakingTxHash string, force bool) (*service.RetryBabylonResponse, error) {
+	result := new(service.RetryBabylonResponse)
+
+	params := make(map[string]interface{})
+	params["stakingTxHash"] = stakingTxHash
+	params["force"] = force
+
+	_, err := c.client.Call(ctx, "retry_babylon", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) ExportDelegation(
+	ctx context.Context,
+	stakingTxHash string,
+	gasLimit *int,
+	fees *string,
+	markSubmitted bool,
+) (*service.ExportDelegationResponse, error) {
+	result := new(service.ExportDelegationResponse)
+
+	params := make(map[string]interface{})
+	params["stakingTxHash"] = stakingTxHash
+	params["markSubmitted"] = markSubmitted
+
+	if gasLimit != nil {
+		params["gasLimit"] = gasLimit
+	}
+
+	if fees != nil {
+		params["fees"] = fees
+	}
+
+	_, err := c.client.Call(ctx, "export_delegation", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) OverrideDelegationState(
+	ctx context.Context,
+	stakingTxHash string,
+	fromState string,
+	toState string,
+	reason string,
+) (*service.OverrideDelegationStateResponse, error) {
+	result := new(service.OverrideDelegationStateResponse)
+
+	params := make(map[string]interface{})
+	params["stakingTxHash"] = stakingTxHash
+	params["fromState"] = fromState
+	params["toState"] = toState
+	params["reason"] = reason
+
+	_, err := c.client.Call(ctx, "override_delegation_state", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) PurgeDelegation(
+	ctx context.Context,
+	stakingTxHash string,
+	reason string,
+) (*service.PurgeDelegationResponse, error) {
+	result := new(service.PurgeDelegationResponse)
+
+	params := make(map[string]interface{})
+	params["stakingTxHash"] = stakingTxHash
+	params["reason"] = reason
+
+	_, err := c.client.Call(ctx, "purge_delegation", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) AnnotateDelegation(
+	ctx context.Context,
+	stakingTxHash string,
+	note string,
+) (*service.AnnotateDelegationResponse, error) {
+	result := new(service.AnnotateDelegationResponse)
+
+	params := make(map[string]interface{})
+	params["stakingTxHash"] = stakingTxHash
+	params["note"] = note
+
+	_, err := c.client.Call(ctx, "annotate_delegation", params, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *StakerServiceJsonRpcClient) AuditLog(ctx context.Context, stakingTxHash *string) (*service.AuditLogResponse, error) {
+	result := new(service.AuditLogResponse)
+
+	params := make(map[string]interface{})
+
+	if stakingTxHash != nil {
+		params["stakingTxHash"] = stakingTxHash
+	}
 
+	_, err := c.client.Call(ctx, "audit_log", params, result)
 	if err != nil {
 		return nil, err
 	}
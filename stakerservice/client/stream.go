@@ -0,0 +1,110 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	service "github.com/babylonchain/btc-staker/stakerservice"
+)
+
+// maximum size of single streamed record
+const maxStreamRecordSize = 1 << 20
+
+func (c *StakerServiceJsonRpcClient) httpURL(path string) (*url.URL, error) {
+	u, err := url.Parse(c.remoteAddress)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "tcp", "http", "":
+		u.Scheme = "http"
+	case "https":
+	default:
+		return nil, fmt.Errorf("streaming is not supported over %s", u.Scheme)
+	}
+
+	u.Path = path
+	return u, nil
+}
+
+// StreamStakingTransactions calls fn for each staking transaction streamed by
+// the daemon. Transactions are received incrementally, so fn is called before
+// whole stream is received. Returning error from fn stops the stream.
+func (c *StakerServiceJsonRpcClient) StreamStakingTransactions(
+	ctx context.Context,
+	offset *uint64,
+	metadataFilter map[string]string,
+	fn func(details *service.StakingDetails) error,
+) error {
+	u, err := c.httpURL(service.StreamStakingTransactionsPath)
+
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+
+	if offset != nil {
+		query.Set("offset", strconv.FormatUint(*offset, 10))
+	}
+
+	for k, v := range metadataFilter {
+		query.Add("metadata_filter", k+"="+v)
+	}
+
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxStreamRecordSize))
+		return fmt.Errorf("streaming failed with status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamRecordSize)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		var record struct {
+			service.StakingDetails
+			Error string `json:"error"`
+		}
+
+		if err := json.Unmarshal(line, &record); err != nil {
+			return fmt.Errorf("invalid streamed record: %w", err)
+		}
+
+		if record.Error != "" {
+			return fmt.Errorf("daemon failed to stream transactions: %s", record.Error)
+		}
+
+		if err := fn(&record.StakingDetails); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
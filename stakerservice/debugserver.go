@@ -0,0 +1,188 @@
+package stakerservice
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	runtimepprof "runtime/pprof"
+	"strings"
+	"time"
+
+	"github.com/babylonchain/btc-staker/faultinjection"
+	str "github.com/babylonchain/btc-staker/staker"
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DebugGoroutinesPath = "/debug/goroutines"
+	DebugDumpStatePath  = "/debug/dump_state"
+	DebugFaultsPath     = "/debug/faults"
+)
+
+// FaultsRequest sets faults injected into wallet and babylon clients, it is
+// also returned with currently injected faults
+type FaultsRequest struct {
+	DropBroadcasts bool `json:"drop_broadcasts"`
+	// duration in go format e.g 30s, empty means no delay
+	BabylonDelay          string `json:"babylon_delay"`
+	SigningFailurePercent uint32 `json:"signing_failure_percent"`
+}
+
+// debugAuth rejects every request without bearer token matching configured
+// sha256 hash
+func debugAuth(tokenHash []byte, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		hash := sha256.Sum256([]byte(token))
+
+		if !found || subtle.ConstantTimeCompare(hash[:], tokenHash) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// goroutines writes stack traces of all goroutines in the same format as
+// unrecovered panic
+func goroutines(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = runtimepprof.Lookup("goroutine").WriteTo(w, 2)
+}
+
+func dumpStateHandler(app *str.StakerApp) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(app.DebugState())
+	}
+}
+
+func writeFaults(w http.ResponseWriter, f *faultinjection.Faults) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(&FaultsRequest{
+		DropBroadcasts:        f.DropBroadcasts,
+		BabylonDelay:          f.BabylonDelay.String(),
+		SigningFailurePercent: f.SigningFailurePercent,
+	})
+}
+
+// faultsHandler returns injected faults on GET and replaces them with faults
+// from json body on POST
+func faultsHandler(app *str.StakerApp) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		injector := app.FaultInjector()
+
+		if injector == nil || !faultinjection.Enabled {
+			http.Error(w, faultinjection.ErrNotCompiled.Error(), http.StatusNotImplemented)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			var req FaultsRequest
+
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, fmt.Sprintf("invalid request: %s", err), http.StatusBadRequest)
+				return
+			}
+
+			faults := &faultinjection.Faults{
+				DropBroadcasts:        req.DropBroadcasts,
+				SigningFailurePercent: req.SigningFailurePercent,
+			}
+
+			if req.BabylonDelay != "" {
+				delay, err := time.ParseDuration(req.BabylonDelay)
+
+				if err != nil {
+					http.Error(w, fmt.Sprintf("invalid babylon delay: %s", err), http.StatusBadRequest)
+					return
+				}
+
+				faults.BabylonDelay = delay
+			}
+
+			if err := injector.SetFaults(faults); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		faults, err := injector.Faults()
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeFaults(w, faults)
+	}
+}
+
+func debugMux(app *str.StakerApp) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc(DebugGoroutinesPath, goroutines)
+	mux.HandleFunc(DebugDumpStatePath, dumpStateHandler(app))
+	mux.HandleFunc(DebugFaultsPath, faultsHandler(app))
+	return mux
+}
+
+// serveDebug starts debug listener if it is enabled in config. Returned function
+// closes the listener.
+func serveDebug(cfg *scfg.DebugConfig, app *str.StakerApp, logger *logrus.Logger) (func(), error) {
+	if cfg.Listen == "" {
+		return func() {}, nil
+	}
+
+	tokenHash, err := cfg.TokenHashBytes()
+
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", cfg.Listen)
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to listen on %s: %w", cfg.Listen, err)
+	}
+
+	server := &http.Server{
+		Handler:           debugAuth(tokenHash, debugMux(app)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		logger.WithField("address", cfg.Listen).Info("Starting debug HTTP server")
+
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.WithError(err).Error("Debug HTTP server stopped")
+		}
+	}()
+
+	return func() {
+		_ = server.Close()
+	}, nil
+}
@@ -0,0 +1,277 @@
+package stakerservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"syscall"
+
+	"github.com/babylonchain/btc-staker/babylonclient"
+	"github.com/babylonchain/btc-staker/monitor"
+	str "github.com/babylonchain/btc-staker/staker"
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/rpcclient"
+	rpc "github.com/cometbft/cometbft/rpc/jsonrpc/server"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// ErrorCode is stable identifier of the error returned in data of json rpc error.
+// Codes are part of the api, so existing codes must never be changed.
+type ErrorCode string
+
+const (
+	ErrCodeInvalidParams         ErrorCode = "invalid_params"
+	ErrCodeInsufficientFunds     ErrorCode = "insufficient_funds"
+	ErrCodeWalletLocked          ErrorCode = "wallet_locked"
+	ErrCodeBtcBackendUnavailable ErrorCode = "btc_backend_unavailable"
+	ErrCodeBabylonUnavailable    ErrorCode = "babylon_unavailable"
+	ErrCodeNotFound              ErrorCode = "not_found"
+	ErrCodeConflict              ErrorCode = "conflict"
+	// returned when rpc acl denies access to the method
+	ErrCodeForbidden ErrorCode = "forbidden"
+	// returned when external policy service did not approve the request
+	ErrCodePolicyRejected ErrorCode = "policy_rejected"
+	// returned when call requires operator token which was not provided
+	ErrCodeUnauthorized ErrorCode = "unauthorized"
+	// returned when call was queued and waits for approval of second operator
+	ErrCodeApprovalRequired ErrorCode = "approval_required"
+	// returned when stake request would exceed quota of the caller
+	ErrCodeQuotaExceeded ErrorCode = "quota_exceeded"
+	// returned when client requested api version which is not supported
+	ErrCodeUnsupportedApiVersion ErrorCode = "unsupported_api_version"
+	// returned for errors which do not fit any other category
+	ErrCodeInternal ErrorCode = "internal"
+)
+
+// RpcErrorData is json encoded in data field of every json rpc error returned
+// by staker service
+type RpcErrorData struct {
+	ErrorCode ErrorCode `json:"error_code"`
+	Message   string    `json:"message"`
+}
+
+// ParseRpcErrorData parses RpcErrorData from data field of json rpc error returned
+// by staker service, or from error message which contains it
+func ParseRpcErrorData(data string) (*RpcErrorData, error) {
+	var errData RpcErrorData
+
+	start := strings.Index(data, "{")
+
+	if start < 0 {
+		return nil, fmt.Errorf("no error data found in: %s", data)
+	}
+
+	if err := json.NewDecoder(strings.NewReader(data[start:])).Decode(&errData); err != nil {
+		return nil, err
+	}
+
+	return &errData, nil
+}
+
+type codedError struct {
+	code ErrorCode
+	err  error
+}
+
+func (e *codedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *codedError) Unwrap() error {
+	return e.err
+}
+
+func invalidParams(err error) error {
+	return &codedError{code: ErrCodeInvalidParams, err: err}
+}
+
+func invalidParamsf(format string, args ...interface{}) error {
+	return invalidParams(fmt.Errorf(format, args...))
+}
+
+func isConnectionError(err error) bool {
+	var netErr net.Error
+	var urlErr *url.Error
+
+	return errors.As(err, &netErr) ||
+		errors.As(err, &urlErr) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, rpcclient.ErrClientNotConnected) ||
+		errors.Is(err, rpcclient.ErrClientDisconnect) ||
+		errors.Is(err, rpcclient.ErrClientShutdown)
+}
+
+func hostOf(address string) string {
+	u, err := url.Parse(address)
+
+	if err != nil || u.Host == "" {
+		return address
+	}
+
+	return u.Host
+}
+
+// isBabylonConnectionError checks whether connection error happened when calling
+// babylon node. Both btc and babylon rpc clients use http, so errors are
+// distinguished by the address of the failed request.
+func isBabylonConnectionError(err error, cfg *scfg.Config) bool {
+	var urlErr *url.Error
+
+	if !errors.As(err, &urlErr) {
+		return false
+	}
+
+	failedHost := hostOf(urlErr.URL)
+
+	return failedHost == hostOf(cfg.BabylonConfig.RPCAddr) ||
+		failedHost == hostOf(cfg.BabylonConfig.GRPCAddr)
+}
+
+func errorCode(err error, cfg *scfg.Config) ErrorCode {
+	var coded *codedError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+
+	var btcRpcErr *btcjson.RPCError
+	if errors.As(err, &btcRpcErr) {
+		switch btcRpcErr.Code {
+		case btcjson.ErrRPCWalletUnlockNeeded, btcjson.ErrRPCWalletPassphraseIncorrect:
+			return ErrCodeWalletLocked
+		case btcjson.ErrRPCWalletInsufficientFunds:
+			return ErrCodeInsufficientFunds
+		case btcjson.ErrRPCInvalidAddressOrKey, btcjson.ErrRPCInvalidParameter, btcjson.ErrRPCDecodeHexString:
+			return ErrCodeInvalidParams
+		}
+	}
+
+	switch {
+	case errors.Is(err, stakerdb.ErrTransactionNotFound),
+		errors.Is(err, stakerdb.ErrWatchedDataNotFound),
+		errors.Is(err, stakerdb.ErrUnbondingDataNotFound),
+		errors.Is(err, stakerdb.ErrUtxoBlocklistEntryNotFound),
+		errors.Is(err, stakerdb.ErrOutputNotFrozen),
+		errors.Is(err, stakerdb.ErrScheduledOperationNotFound),
+		errors.Is(err, stakerdb.ErrMuSig2NonceNotFound),
+		errors.Is(err, stakerdb.ErrStakingPresetNotFound),
+		errors.Is(err, monitor.ErrTransactionNotMonitored),
+		errors.Is(err, babylonclient.ErrDelegationNotFound),
+		errors.Is(err, babylonclient.ErrFinalityProviderDoesNotExist):
+		return ErrCodeNotFound
+	case errors.Is(err, stakerdb.ErrDuplicateTransaction),
+		errors.Is(err, str.ErrInvalidTransactionState),
+		errors.Is(err, stakerdb.ErrUnexpectedTransactionState),
+		errors.Is(err, stakerdb.ErrDuplicateMuSig2Session),
+		errors.Is(err, stakerdb.ErrMuSig2NonceUsed),
+		errors.Is(err, stakerdb.ErrMuSig2NonceExpired),
+		errors.Is(err, stakerdb.ErrTooManyDelegationNotes),
+		errors.Is(err, babylonclient.ErrFinalityProviderIsSlashed):
+		return ErrCodeConflict
+	case errors.Is(err, str.ErrInvalidStakingRequest):
+		return ErrCodeInvalidParams
+	case errors.Is(err, str.ErrRejectedByPolicy),
+		errors.Is(err, str.ErrDestinationNotWhitelisted):
+		return ErrCodePolicyRejected
+	case errors.Is(err, babylonclient.ErrBabylonBtcLightClientNotReady),
+		errors.Is(err, babylonclient.ErrHeaderNotKnownToBabylon):
+		return ErrCodeBabylonUnavailable
+	}
+
+	// wallets report insufficient funds without dedicated error code when
+	// funding transactions
+	if strings.Contains(strings.ToLower(err.Error()), "insufficient funds") {
+		return ErrCodeInsufficientFunds
+	}
+
+	if isConnectionError(err) {
+		if isBabylonConnectionError(err, cfg) {
+			return ErrCodeBabylonUnavailable
+		}
+		return ErrCodeBtcBackendUnavailable
+	}
+
+	return ErrCodeInternal
+}
+
+func toRpcError(err error, cfg *scfg.Config) *rpctypes.RPCError {
+	code := errorCode(err, cfg)
+
+	data, marshalErr := json.Marshal(RpcErrorData{
+		ErrorCode: code,
+		Message:   err.Error(),
+	})
+
+	if marshalErr != nil {
+		// should never happen, as RpcErrorData contains only strings
+		data = []byte(err.Error())
+	}
+
+	rpcErr := &rpctypes.RPCError{
+		Code:    -32603,
+		Message: "Internal error",
+		Data:    string(data),
+	}
+
+	if code == ErrCodeInvalidParams {
+		rpcErr.Code = -32602
+		rpcErr.Message = "Invalid params"
+	}
+
+	return rpcErr
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// withErrorCodes wraps rpc handler f, so that returned error is converted to
+// json rpc error with RpcErrorData in its data field. f must be a function which
+// returns (result, error) as required by rpc.NewRPCFunc.
+func withErrorCodes(f interface{}, cfg *scfg.Config) interface{} {
+	fv := reflect.ValueOf(f)
+	ft := fv.Type()
+
+	if ft.Kind() != reflect.Func || ft.NumOut() != 2 || ft.Out(1) != errorType {
+		panic(fmt.Sprintf("rpc handler must return (result, error), got: %s", ft))
+	}
+
+	wrapped := reflect.MakeFunc(ft, func(args []reflect.Value) []reflect.Value {
+		results := fv.Call(args)
+
+		if results[1].IsNil() {
+			return results
+		}
+
+		err := results[1].Interface().(error)
+
+		var rpcErr *rpctypes.RPCError
+		if errors.As(err, &rpcErr) {
+			return results
+		}
+
+		results[1] = reflect.ValueOf(toRpcError(err, cfg)).Convert(errorType)
+		return results
+	})
+
+	return wrapped.Interface()
+}
+
+// newRPCFunc creates rpc function which returns errors with error codes
+func newRPCFunc(f interface{}, args string, cfg *scfg.Config) *rpc.RPCFunc {
+	return rpc.NewRPCFunc(withErrorCodes(f, cfg), args)
+}
+
+func (s *StakerService) newRPCFunc(f interface{}, args string) *rpc.RPCFunc {
+	return newRPCFunc(f, args, s.config)
+}
+
+func (s *MonitorService) newRPCFunc(f interface{}, args string) *rpc.RPCFunc {
+	return newRPCFunc(f, args, s.config)
+}
@@ -0,0 +1,220 @@
+package stakerservice
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+)
+
+// responseMasker removes configured fields from responses of read only methods
+// for callers which are not operators
+type responseMasker struct {
+	all      map[string]struct{}
+	byMethod map[string]map[string]struct{}
+}
+
+// newResponseMasker returns nil if no field is masked
+func newResponseMasker(cfg *scfg.ResponseMaskingConfig) (*responseMasker, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+
+	all, byMethod, err := cfg.FieldRules()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &responseMasker{
+		all:      all,
+		byMethod: byMethod,
+	}, nil
+}
+
+// appliesTo returns false for requests of operators, which see full responses
+func (m *responseMasker) appliesTo(r *http.Request) bool {
+	return rpcRequestOf(r).caller.Operator == ""
+}
+
+func (m *responseMasker) masked(method, field string) bool {
+	if _, found := m.all[field]; found {
+		return true
+	}
+
+	_, found := m.byMethod[method][field]
+	return found
+}
+
+func (m *responseMasker) maskValue(method string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for field, fieldValue := range v {
+			if m.masked(method, field) {
+				delete(v, field)
+				continue
+			}
+
+			v[field] = m.maskValue(method, fieldValue)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = m.maskValue(method, v[i])
+		}
+	}
+
+	return value
+}
+
+// maskResult removes masked fields at any depth of the result. Results of spend
+// capable methods are returned as they are.
+func (m *responseMasker) maskResult(method string, result json.RawMessage) (json.RawMessage, error) {
+	if _, isSpend := spendMethods[method]; isSpend {
+		return result, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(result))
+	// keeps numbers exactly as they were encoded
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(m.maskValue(method, value))
+}
+
+// withResponseMasking removes masked fields from json rpc results for callers
+// which are not operators
+func withResponseMasking(h http.Handler, m *responseMasker) http.Handler {
+	if m == nil {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.appliesTo(r) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		req := rpcRequestOf(r)
+
+		if req.err != nil {
+			// let rpc handler report malformed request
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		buffered := &bufferedResponseWriter{header: w.Header()}
+
+		h.ServeHTTP(buffered, r)
+
+		if buffered.statusCode == 0 {
+			buffered.statusCode = http.StatusOK
+		}
+
+		body, err := rewriteResults(buffered.body.Bytes(), req.methodsById(), m.maskResult)
+
+		if err != nil {
+			// never leak fields which should be masked
+			http.Error(w, "failed to mask response", http.StatusInternalServerError)
+			return
+		}
+
+		// body length could change
+		w.Header().Del("Content-Length")
+		w.WriteHeader(buffered.statusCode)
+		_, _ = w.Write(body)
+	})
+}
+
+// maskingStreamWriter masks every newline delimited json record written to the
+// stream
+type maskingStreamWriter struct {
+	http.ResponseWriter
+	masker  *responseMasker
+	method  string
+	pending []byte
+	err     error
+}
+
+func (w *maskingStreamWriter) writeRecord(record []byte) error {
+	trimmed := bytes.TrimSpace(record)
+
+	if len(trimmed) == 0 {
+		_, err := w.ResponseWriter.Write(record)
+		return err
+	}
+
+	masked, err := w.masker.maskResult(w.method, trimmed)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = w.ResponseWriter.Write(append(masked, '\n'))
+	return err
+}
+
+func (w *maskingStreamWriter) Write(b []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	w.pending = append(w.pending, b...)
+
+	for {
+		end := bytes.IndexByte(w.pending, '\n')
+
+		if end < 0 {
+			break
+		}
+
+		if err := w.writeRecord(w.pending[:end]); err != nil {
+			w.err = err
+			return 0, err
+		}
+
+		w.pending = w.pending[end+1:]
+	}
+
+	return len(b), nil
+}
+
+// finish writes record not terminated by newline
+func (w *maskingStreamWriter) finish() {
+	if w.err == nil && len(w.pending) > 0 {
+		_ = w.writeRecord(w.pending)
+	}
+	w.pending = nil
+}
+
+func (w *maskingStreamWriter) FlushError() error {
+	return http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *maskingStreamWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// serveMaskedStream serves stream with masked records for callers which are
+// not operators
+func serveMaskedStream(stream http.HandlerFunc, m *responseMasker, w http.ResponseWriter, r *http.Request) {
+	if m == nil || !strings.HasPrefix(r.URL.Path, streamPathPrefix) || !m.appliesTo(r) {
+		stream(w, r)
+		return
+	}
+
+	mw := &maskingStreamWriter{
+		ResponseWriter: w,
+		masker:         m,
+		method:         strings.TrimPrefix(r.URL.Path, streamPathPrefix),
+	}
+
+	stream(mw, r)
+	mw.finish()
+}
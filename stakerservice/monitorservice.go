@@ -0,0 +1,167 @@
+package stakerservice
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/babylonchain/btc-staker/monitor"
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/signal"
+	"github.com/sirupsen/logrus"
+)
+
+// MonitorService serves read only endpoints of the daemon running in watch only
+// monitoring mode
+type MonitorService struct {
+	started int32
+
+	config      *scfg.Config
+	monitor     *monitor.Monitor
+	logger      *logrus.Logger
+	db          kvdb.Backend
+	interceptor signal.Interceptor
+}
+
+func NewMonitorService(
+	c *scfg.Config,
+	m *monitor.Monitor,
+	l *logrus.Logger,
+	sig signal.Interceptor,
+	db kvdb.Backend,
+) *MonitorService {
+	return &MonitorService{
+		config:      c,
+		monitor:     m,
+		logger:      l,
+		interceptor: sig,
+		db:          db,
+	}
+}
+
+func (s *MonitorService) health(_ *rpctypes.Context) (*ResultHealth, error) {
+	return &ResultHealth{}, nil
+}
+
+func (s *MonitorService) monitoredTransactions(_ *rpctypes.Context) (*MonitoredTransactionsResponse, error) {
+	txs := s.monitor.Transactions()
+
+	return &MonitoredTransactionsResponse{
+		BtcBestBlockHeight: s.monitor.BestBlockHeight(),
+		Transactions:       txs,
+		TotalCount:         uint64(len(txs)),
+	}, nil
+}
+
+func (s *MonitorService) monitoredTransaction(_ *rpctypes.Context, stakingTxHash string) (*monitor.TransactionSummary, error) {
+	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	return s.monitor.Transaction(txHash)
+}
+
+func (s *MonitorService) GetRoutes() RoutesMap {
+	return RoutesMap{
+		// info AP
+		"health": s.newRPCFunc(s.health, ""),
+		// monitoring API
+		"monitored_transactions": s.newRPCFunc(s.monitoredTransactions, ""),
+		"monitored_transaction":  s.newRPCFunc(s.monitoredTransaction, "stakingTxHash"),
+	}
+}
+
+func (s *MonitorService) RunUntilShutdown() error {
+	if atomic.AddInt32(&s.started, 1) != 1 {
+		return nil
+	}
+
+	defer func() {
+		s.logger.Info("Shutdown complete")
+	}()
+
+	defer func() {
+		s.logger.Info("Closing database...")
+		s.db.Close()
+		s.logger.Info("Database closed")
+	}()
+
+	mkErr := func(format string, args ...interface{}) error {
+		logFormat := strings.ReplaceAll(format, "%w", "%v")
+		s.logger.Errorf("Shutting down because error in main "+
+			"method: "+logFormat, args...)
+		return fmt.Errorf(format, args...)
+	}
+
+	err := s.monitor.Start()
+	if err != nil {
+		return mkErr("error starting monitor: %w", err)
+	}
+
+	defer func() {
+		_ = s.monitor.Stop()
+		s.logger.Info("monitor stop complete")
+	}()
+
+	signer, err := loadResponseSigner(s.config.ResponseSigningConfig)
+	if err != nil {
+		return mkErr("error loading response signing key: %w", err)
+	}
+
+	if signer != nil {
+		s.logger.WithField("publicKey", signer.PublicKeyHex()).Info("Signing json rpc responses")
+	}
+
+	routes := s.GetRoutes()
+	probes := s.GetProbeHandlers()
+
+	acl, err := newRpcAcl(s.config.RpcAclConfig, routes, probes, s.logger)
+	if err != nil {
+		return mkErr("error creating rpc acl: %w", err)
+	}
+
+	verifier, err := newRequestVerifier(s.config.RequestSigningConfig)
+	if err != nil {
+		return mkErr("error creating request verifier: %w", err)
+	}
+
+	identities, err := newIdentityResolver(s.config)
+	if err != nil {
+		return mkErr("error reading rpc tokens: %w", err)
+	}
+
+	masker, err := newResponseMasker(s.config.ResponseMaskingConfig)
+	if err != nil {
+		return mkErr("error creating response masker: %w", err)
+	}
+
+	accessLog := newAccessLogger(s.config, routes, s.monitor.Metrics().RpcSlowRequests, s.logger)
+
+	closeListeners, err := serveRoutes(routes, probes, signer, acl, nil, verifier, masker, accessLog, identities, s.config.RpcListeners, s.logger)
+	if err != nil {
+		return mkErr("error starting rpc server: %w", err)
+	}
+
+	defer closeListeners()
+
+	// monitor has no event loop which could deadlock, so there is nothing to
+	// self check
+	stopWatchdog := startSystemdWatchdog(s.config.SystemdConfig, nil, s.logger)
+	defer stopWatchdog()
+
+	s.logger.Info("Monitor Service fully started")
+	notifySystemd(s.logger, sdNotifyReady)
+
+	// Wait for shutdown signal from either a graceful service stop or from
+	// the interrupt handler.
+	<-s.interceptor.ShutdownChannel()
+
+	s.logger.Info("Received shutdown signal. Stopping...")
+	notifySystemd(s.logger, sdNotifyStopping)
+
+	return nil
+}
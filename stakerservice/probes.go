@@ -0,0 +1,203 @@
+package stakerservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+)
+
+const (
+	LivenessProbePath  = "/live"
+	ReadinessProbePath = "/ready"
+)
+
+type ProbeCheckResult struct {
+	Name  string `json:"name"`
+	Ok    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+type ProbeResponse struct {
+	Status string             `json:"status"`
+	Checks []ProbeCheckResult `json:"checks,omitempty"`
+}
+
+type readinessCheck struct {
+	name  string
+	check func() error
+}
+
+func writeProbeResponse(w http.ResponseWriter, statusCode int, resp *ProbeResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// liveness reports that the process is up and serving http requests. It never
+// checks dependencies, so that orchestrator does not restart the daemon because
+// of btc or babylon node outage.
+func liveness(w http.ResponseWriter, _ *http.Request) {
+	writeProbeResponse(w, http.StatusOK, &ProbeResponse{Status: "ok"})
+}
+
+// runReadinessChecks runs all checks concurrently. Rpc clients used by checks do
+// not accept context, so checks which did not finish before timeout are
+// reported as failed and left running in the background.
+func runReadinessChecks(ctx context.Context, timeout time.Duration, checks []readinessCheck) []ProbeCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	results := make([]chan error, len(checks))
+
+	for i, c := range checks {
+		// buffered, so that abandoned check does not block forever
+		results[i] = make(chan error, 1)
+
+		go func(c readinessCheck, result chan<- error) {
+			result <- c.check()
+		}(c, results[i])
+	}
+
+	probeResults := make([]ProbeCheckResult, len(checks))
+
+	for i, c := range checks {
+		var err error
+
+		select {
+		case err = <-results[i]:
+		case <-ctx.Done():
+			err = fmt.Errorf("check did not finish in %s", timeout)
+		}
+
+		probeResults[i] = ProbeCheckResult{Name: c.name, Ok: err == nil}
+
+		if err != nil {
+			probeResults[i].Error = err.Error()
+		}
+	}
+
+	return probeResults
+}
+
+func readinessHandler(cfg *scfg.ReadinessConfig, checks []readinessCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		results := runReadinessChecks(r.Context(), cfg.CheckTimeout, checks)
+
+		resp := &ProbeResponse{Status: "ready", Checks: results}
+		statusCode := http.StatusOK
+
+		for _, res := range results {
+			if !res.Ok {
+				resp.Status = "not_ready"
+				statusCode = http.StatusServiceUnavailable
+				break
+			}
+		}
+
+		writeProbeResponse(w, statusCode, resp)
+	}
+}
+
+func (s *StakerService) readinessChecks() []readinessCheck {
+	cfg := s.config.ReadinessConfig
+	var checks []readinessCheck
+
+	if cfg.RequireBtcBackend {
+		checks = append(checks, readinessCheck{
+			name: "btc_backend",
+			check: func() error {
+				_, err := s.staker.Wallet().GetBlockHash(0)
+				return err
+			},
+		})
+	}
+
+	if cfg.RequireBtcSynced {
+		checks = append(checks, readinessCheck{
+			name: "btc_synced",
+			check: func() error {
+				status, err := s.staker.Wallet().ChainSyncStatus()
+
+				if err != nil {
+					return err
+				}
+
+				if status.InitialBlockDownload {
+					return fmt.Errorf("btc node is in initial block download")
+				}
+
+				if status.Headers > status.Blocks && uint32(status.Headers-status.Blocks) > cfg.MaxBlocksBehind {
+					return fmt.Errorf("btc node is %d blocks behind best known header", status.Headers-status.Blocks)
+				}
+
+				return nil
+			},
+		})
+	}
+
+	if cfg.RequireBabylon {
+		checks = append(checks, readinessCheck{
+			name: "babylon",
+			check: func() error {
+				_, err := s.staker.BabylonController().Params()
+				return err
+			},
+		})
+	}
+
+	if cfg.RequireWalletUnlock {
+		checks = append(checks, readinessCheck{
+			name: "wallet_unlocked",
+			check: func() error {
+				locked, err := s.staker.Wallet().WalletLocked()
+
+				if err != nil {
+					return err
+				}
+
+				if locked {
+					return fmt.Errorf("wallet is locked")
+				}
+
+				return nil
+			},
+		})
+	}
+
+	return checks
+}
+
+func (s *StakerService) GetProbeHandlers() StreamHandlers {
+	return StreamHandlers{
+		LivenessProbePath:  liveness,
+		ReadinessProbePath: readinessHandler(s.config.ReadinessConfig, s.readinessChecks()),
+	}
+}
+
+// GetProbeHandlers returns probes of monitoring mode. Monitor does not use wallet
+// nor sends anything to babylon, so only btc backend criteria apply.
+func (s *MonitorService) GetProbeHandlers() StreamHandlers {
+	var checks []readinessCheck
+
+	if s.config.ReadinessConfig.RequireBtcBackend || s.config.ReadinessConfig.RequireBtcSynced {
+		checks = append(checks, readinessCheck{
+			name: "btc_backend",
+			check: func() error {
+				if s.monitor.BestBlockHeight() == 0 {
+					return fmt.Errorf("best btc block not yet received")
+				}
+				return nil
+			},
+		})
+	}
+
+	return StreamHandlers{
+		LivenessProbePath:  liveness,
+		ReadinessProbePath: readinessHandler(s.config.ReadinessConfig, checks),
+	}
+}
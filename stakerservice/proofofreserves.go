@@ -0,0 +1,289 @@
+package stakerservice
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	str "github.com/babylonchain/btc-staker/staker"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+type ReservesOutputResponse struct {
+	StakingTxHash string `json:"staking_tx_hash"`
+	Outpoint      string `json:"outpoint"`
+	Unbonding     bool   `json:"unbonding"`
+	Amount        string `json:"amount"`
+	PkScript      string `json:"pk_script"`
+	// x-only keys, as used in staking scripts
+	StakerPk            string   `json:"staker_pk"`
+	FinalityProviderPks []string `json:"finality_provider_pks"`
+	CovenantPks         []string `json:"covenant_pks"`
+	CovenantQuorum      string   `json:"covenant_quorum"`
+	LockTime            string   `json:"lock_time"`
+}
+
+type ReservesKeyProofResponse struct {
+	StakerAddress string `json:"staker_address"`
+	// compressed key, so that it can be matched with p2wpkh address
+	StakerPk string `json:"staker_pk"`
+	// BIP-322 simple signature of the report message in base64
+	Signature string `json:"signature"`
+	Amount    string `json:"amount"`
+}
+
+type ProofOfReservesResponse struct {
+	Message            string                     `json:"message"`
+	Network            string                     `json:"network"`
+	BtcHeight          string                     `json:"btc_height"`
+	CreatedAt          string                     `json:"created_at"`
+	Outputs            []ReservesOutputResponse   `json:"outputs"`
+	Keys               []ReservesKeyProofResponse `json:"keys"`
+	TotalAmount        string                     `json:"total_amount"`
+	SkippedDelegations string                     `json:"skipped_delegations"`
+}
+
+func xOnlyKeysHex(keys []*btcec.PublicKey) []string {
+	encoded := make([]string, len(keys))
+
+	for i, k := range keys {
+		encoded[i] = hex.EncodeToString(schnorr.SerializePubKey(k))
+	}
+
+	return encoded
+}
+
+func parseXOnlyKeys(encoded []string) ([]*btcec.PublicKey, error) {
+	keys := make([]*btcec.PublicKey, len(encoded))
+
+	for i, e := range encoded {
+		keyBytes, err := hex.DecodeString(e)
+
+		if err != nil {
+			return nil, err
+		}
+
+		keys[i], err = schnorr.ParsePubKey(keyBytes)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+func proofOfReservesResponse(r *str.ReservesReport) *ProofOfReservesResponse {
+	outputs := make([]ReservesOutputResponse, len(r.Outputs))
+
+	for i, o := range r.Outputs {
+		outputs[i] = ReservesOutputResponse{
+			StakingTxHash:       o.StakingTxHash.String(),
+			Outpoint:            o.OutPoint.String(),
+			Unbonding:           o.Unbonding,
+			Amount:              strconv.FormatInt(int64(o.Amount), 10),
+			PkScript:            hex.EncodeToString(o.PkScript),
+			StakerPk:            hex.EncodeToString(schnorr.SerializePubKey(o.StakerPk)),
+			FinalityProviderPks: xOnlyKeysHex(o.FinalityProviderPks),
+			CovenantPks:         xOnlyKeysHex(o.CovenantPks),
+			CovenantQuorum:      strconv.FormatUint(uint64(o.CovenantQuorum), 10),
+			LockTime:            strconv.FormatUint(uint64(o.LockTime), 10),
+		}
+	}
+
+	keys := make([]ReservesKeyProofResponse, len(r.Keys))
+
+	for i, k := range r.Keys {
+		keys[i] = ReservesKeyProofResponse{
+			StakerAddress: k.StakerAddress.EncodeAddress(),
+			StakerPk:      hex.EncodeToString(k.StakerPk.SerializeCompressed()),
+			Signature:     base64.StdEncoding.EncodeToString(k.Signature),
+			Amount:        strconv.FormatInt(int64(k.Amount), 10),
+		}
+	}
+
+	return &ProofOfReservesResponse{
+		Message:            r.Message,
+		Network:            r.Network,
+		BtcHeight:          strconv.FormatUint(uint64(r.BtcHeight), 10),
+		CreatedAt:          r.CreatedAt.UTC().Format(time.RFC3339),
+		Outputs:            outputs,
+		Keys:               keys,
+		TotalAmount:        strconv.FormatInt(int64(r.TotalAmount), 10),
+		SkippedDelegations: strconv.Itoa(r.SkippedDelegations),
+	}
+}
+
+func parseReservesOutput(o *ReservesOutputResponse) (*str.ReservesOutput, error) {
+	stakingTxHash, err := chainhash.NewHashFromStr(o.StakingTxHash)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid staking tx hash: %w", err)
+	}
+
+	outpoint, err := wire.NewOutPointFromString(o.Outpoint)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid outpoint: %w", err)
+	}
+
+	amount, err := strconv.ParseInt(o.Amount, 10, 64)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount: %w", err)
+	}
+
+	pkScript, err := hex.DecodeString(o.PkScript)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid pk script: %w", err)
+	}
+
+	stakerPks, err := parseXOnlyKeys([]string{o.StakerPk})
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid staker key: %w", err)
+	}
+
+	fpPks, err := parseXOnlyKeys(o.FinalityProviderPks)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid finality provider key: %w", err)
+	}
+
+	covenantPks, err := parseXOnlyKeys(o.CovenantPks)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid covenant key: %w", err)
+	}
+
+	quorum, err := strconv.ParseUint(o.CovenantQuorum, 10, 32)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid covenant quorum: %w", err)
+	}
+
+	lockTime, err := strconv.ParseUint(o.LockTime, 10, 16)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid lock time: %w", err)
+	}
+
+	return &str.ReservesOutput{
+		StakingTxHash:       *stakingTxHash,
+		OutPoint:            *outpoint,
+		Unbonding:           o.Unbonding,
+		Amount:              btcutil.Amount(amount),
+		PkScript:            pkScript,
+		StakerPk:            stakerPks[0],
+		FinalityProviderPks: fpPks,
+		CovenantPks:         covenantPks,
+		CovenantQuorum:      uint32(quorum),
+		LockTime:            uint16(lockTime),
+	}, nil
+}
+
+func parseReservesKeyProof(k *ReservesKeyProofResponse, net *chaincfg.Params) (*str.ReservesKeyProof, error) {
+	address, err := btcutil.DecodeAddress(k.StakerAddress, net)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid staker address: %w", err)
+	}
+
+	pkBytes, err := hex.DecodeString(k.StakerPk)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid staker key: %w", err)
+	}
+
+	pk, err := btcec.ParsePubKey(pkBytes)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid staker key: %w", err)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(k.Signature)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+
+	amount, err := strconv.ParseInt(k.Amount, 10, 64)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount: %w", err)
+	}
+
+	return &str.ReservesKeyProof{
+		StakerAddress: address,
+		StakerPk:      pk,
+		Signature:     sig,
+		Amount:        btcutil.Amount(amount),
+	}, nil
+}
+
+// Report parses proof of reserves returned by the daemon, so that it can be
+// verified without access to the daemon
+func (r *ProofOfReservesResponse) Report(net *chaincfg.Params) (*str.ReservesReport, error) {
+	height, err := strconv.ParseUint(r.BtcHeight, 10, 32)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid btc height: %w", err)
+	}
+
+	createdAt, err := time.Parse(time.RFC3339, r.CreatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid creation time: %w", err)
+	}
+
+	total, err := strconv.ParseInt(r.TotalAmount, 10, 64)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid total amount: %w", err)
+	}
+
+	skipped, err := strconv.Atoi(r.SkippedDelegations)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid number of skipped delegations: %w", err)
+	}
+
+	report := &str.ReservesReport{
+		Message:            r.Message,
+		Network:            r.Network,
+		BtcHeight:          uint32(height),
+		CreatedAt:          createdAt,
+		TotalAmount:        btcutil.Amount(total),
+		SkippedDelegations: skipped,
+	}
+
+	for i := range r.Outputs {
+		out, err := parseReservesOutput(&r.Outputs[i])
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid output %d: %w", i, err)
+		}
+
+		report.Outputs = append(report.Outputs, *out)
+	}
+
+	for i := range r.Keys {
+		key, err := parseReservesKeyProof(&r.Keys[i], net)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid key proof %d: %w", i, err)
+		}
+
+		report.Keys = append(report.Keys, *key)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,181 @@
+package stakerservice
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/babylonchain/btc-staker/metrics"
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/btcsuite/btcd/btcutil"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+const (
+	// ApiTokenHeader carries token identifying api identity for quota purposes
+	ApiTokenHeader = "X-Api-Token"
+
+	quotaWindow = 24 * time.Hour
+)
+
+type quotaEntry struct {
+	at     time.Time
+	amount btcutil.Amount
+}
+
+// stakeQuotas enforces per identity limits of stake requests in rolling 24
+// hour window. Usage is kept in memory, so the window starts anew after
+// restart of the daemon.
+type stakeQuotas struct {
+	mu           sync.Mutex
+	byName       map[string]scfg.StakeQuota
+	defaultQuota scfg.StakeQuota
+	usage        map[string][]*quotaEntry
+	m            *metrics.StakerMetrics
+}
+
+// newStakeQuotas returns nil if no quota is configured
+func newStakeQuotas(cfg *scfg.QuotaConfig, m *metrics.StakerMetrics) (*stakeQuotas, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+
+	byTokenHash, err := cfg.QuotasByTokenHash()
+
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]scfg.StakeQuota, len(byTokenHash))
+	for _, quota := range byTokenHash {
+		byName[quota.Name] = quota
+	}
+
+	return &stakeQuotas{
+		byName:       byName,
+		defaultQuota: cfg.DefaultQuota(),
+		usage:        make(map[string][]*quotaEntry),
+		m:            m,
+	}, nil
+}
+
+// quotaOf returns quota of the caller. Callers presenting unknown token are
+// rejected instead of falling back to default quota, as it is most likely
+// misconfiguration.
+func (q *stakeQuotas) quotaOf(ctx *rpctypes.Context) (scfg.StakeQuota, error) {
+	caller := callerOf(ctx)
+
+	if caller.InvalidApiToken {
+		return scfg.StakeQuota{}, &codedError{
+			code: ErrCodeUnauthorized,
+			err:  fmt.Errorf("invalid %s header", ApiTokenHeader),
+		}
+	}
+
+	quota, found := q.byName[caller.ApiIdentity]
+
+	if !found {
+		return q.defaultQuota, nil
+	}
+
+	return quota, nil
+}
+
+// pruneExpired must be called with mu held
+func (q *stakeQuotas) pruneExpired(identity string, now time.Time) []*quotaEntry {
+	entries := q.usage[identity]
+
+	i := 0
+	for i < len(entries) && now.Sub(entries[i].at) >= quotaWindow {
+		i++
+	}
+
+	entries = entries[i:]
+
+	if len(entries) == 0 {
+		delete(q.usage, identity)
+	} else {
+		q.usage[identity] = entries
+	}
+
+	return entries
+}
+
+// reserve records stake of amount by the caller, or returns error if it would
+// exceed quota of the caller. Returned function releases the reservation and
+// must be called if stake request fails.
+func (q *stakeQuotas) reserve(ctx *rpctypes.Context, amount btcutil.Amount) (func(), error) {
+	quota, err := q.quotaOf(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	entries := q.pruneExpired(quota.Name, now)
+
+	var used btcutil.Amount
+	for _, e := range entries {
+		used += e.amount
+	}
+
+	if quota.MaxStakes > 0 && uint32(len(entries)) >= quota.MaxStakes {
+		q.m.QuotaRejections.WithLabelValues(quota.Name, "stakes").Inc()
+		return nil, &codedError{
+			code: ErrCodeQuotaExceeded,
+			err:  fmt.Errorf("identity %s reached limit of %d stakes per day", quota.Name, quota.MaxStakes),
+		}
+	}
+
+	if quota.MaxAmount > 0 && int64(used+amount) > quota.MaxAmount {
+		q.m.QuotaRejections.WithLabelValues(quota.Name, "amount").Inc()
+		return nil, &codedError{
+			code: ErrCodeQuotaExceeded,
+			err: fmt.Errorf("stake of %d satoshis would exceed limit of %d satoshis per day of identity %s, %d satoshis already staked",
+				int64(amount), quota.MaxAmount, quota.Name, int64(used)),
+		}
+	}
+
+	entry := &quotaEntry{at: now, amount: amount}
+	q.usage[quota.Name] = append(entries, entry)
+	q.setUsageMetrics(quota.Name)
+
+	return func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+
+		entries := q.usage[quota.Name]
+		for i, e := range entries {
+			if e == entry {
+				q.usage[quota.Name] = append(entries[:i:i], entries[i+1:]...)
+				break
+			}
+		}
+
+		q.setUsageMetrics(quota.Name)
+	}, nil
+}
+
+// setUsageMetrics must be called with mu held
+func (q *stakeQuotas) setUsageMetrics(identity string) {
+	var amount btcutil.Amount
+	for _, e := range q.usage[identity] {
+		amount += e.amount
+	}
+
+	q.m.QuotaUsedStakes.WithLabelValues(identity).Set(float64(len(q.usage[identity])))
+	q.m.QuotaUsedAmount.WithLabelValues(identity).Set(float64(amount))
+}
+
+// reserveQuota reserves quota of the caller for stake of amount. If quotas are
+// disabled, returned release function does nothing.
+func (s *StakerService) reserveQuota(ctx *rpctypes.Context, amount btcutil.Amount) (func(), error) {
+	if s.quotas == nil {
+		return func() {}, nil
+	}
+
+	return s.quotas.reserve(ctx, amount)
+}
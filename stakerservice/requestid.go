@@ -0,0 +1,80 @@
+package stakerservice
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+const (
+	// RequestIdHeader is http header carrying id of the request. If client does
+	// not provide it, it is generated by the daemon. It is always returned in
+	// the response.
+	RequestIdHeader = "X-Request-Id"
+
+	maxRequestIdLength = 128
+)
+
+func newRequestId() string {
+	var id [16]byte
+
+	if _, err := rand.Read(id[:]); err != nil {
+		// crypto/rand never fails on supported platforms
+		panic(err)
+	}
+
+	return hex.EncodeToString(id[:])
+}
+
+func validateRequestId(requestId string) error {
+	if len(requestId) == 0 || len(requestId) > maxRequestIdLength {
+		return invalidParamsf("request id must have between 1 and %d characters", maxRequestIdLength)
+	}
+
+	for _, c := range requestId {
+		// only printable ascii without spaces, so that ids are safe to put in
+		// logs and headers
+		if c <= ' ' || c > '~' {
+			return invalidParamsf("request id must contain only printable ascii characters without spaces")
+		}
+	}
+
+	return nil
+}
+
+// withRequestId makes sure every request has valid request id in RequestIdHeader,
+// and returns it to the client in the same header
+func withRequestId(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestId := r.Header.Get(RequestIdHeader)
+
+		if validateRequestId(requestId) != nil {
+			requestId = newRequestId()
+			r.Header.Set(RequestIdHeader, requestId)
+		}
+
+		w.Header().Set(RequestIdHeader, requestId)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// resolveRequestId returns request id passed explicitly as rpc parameter, or id
+// from the http request header
+func resolveRequestId(ctx *rpctypes.Context, requestId *string) (string, error) {
+	if requestId != nil {
+		if err := validateRequestId(*requestId); err != nil {
+			return "", err
+		}
+		return *requestId, nil
+	}
+
+	if ctx != nil && ctx.HTTPReq != nil {
+		if headerId := ctx.HTTPReq.Header.Get(RequestIdHeader); headerId != "" {
+			return headerId, nil
+		}
+	}
+
+	return newRequestId(), nil
+}
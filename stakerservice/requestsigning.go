@@ -0,0 +1,257 @@
+package stakerservice
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+const (
+	// RequestTimestampHeader carries unix time in seconds at which request was
+	// signed
+	RequestTimestampHeader = "X-Request-Timestamp"
+
+	// RequestNonceHeader carries random hex encoded nonce, unique for every
+	// signed request
+	RequestNonceHeader = "X-Request-Nonce"
+
+	// RequestSignatureHeader carries hex encoded BIP340 signature of the
+	// request
+	RequestSignatureHeader = "X-Request-Signature"
+
+	requestNonceBytes = 16
+
+	maxRequestNonceLen = 64
+)
+
+// requestSigHash commits to the whole json rpc body, which includes method and
+// all params, so that signature can't be reused for different call
+func requestSigHash(timestamp string, nonce string, body []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(timestamp))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(nonce))
+	h.Write([]byte{'\n'})
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// ParseRequestSigningKey parses hex encoded private key used by clients to sign
+// requests
+func ParseRequestSigningKey(keyHex string) (*btcec.PrivateKey, error) {
+	keyBytes, err := hex.DecodeString(strings.TrimSpace(keyHex))
+
+	if err != nil || len(keyBytes) != btcec.PrivKeyBytesLen {
+		return nil, fmt.Errorf("request signing key must be hex encoded 32 byte private key")
+	}
+
+	key, _ := btcec.PrivKeyFromBytes(keyBytes)
+
+	return key, nil
+}
+
+// SignRequest signs body of the json rpc request with given key and fresh
+// nonce, and sets signature headers. Body of the request is preserved.
+func SignRequest(r *http.Request, key *btcec.PrivateKey) error {
+	var body []byte
+
+	if r.Body != nil {
+		b, err := io.ReadAll(r.Body)
+		_ = r.Body.Close()
+
+		if err != nil {
+			return err
+		}
+
+		body = b
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	nonceBytes := make([]byte, requestNonceBytes)
+
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce := hex.EncodeToString(nonceBytes)
+
+	sig, err := schnorr.Sign(key, requestSigHash(timestamp, nonce, body))
+
+	if err != nil {
+		return err
+	}
+
+	r.Header.Set(RequestTimestampHeader, timestamp)
+	r.Header.Set(RequestNonceHeader, nonce)
+	r.Header.Set(RequestSignatureHeader, hex.EncodeToString(sig.Serialize()))
+
+	return nil
+}
+
+// requestVerifier verifies signatures of spend capable requests and rejects
+// requests which are too old or were already seen
+type requestVerifier struct {
+	keys   []*btcec.PublicKey
+	window time.Duration
+
+	mu sync.Mutex
+	// nonces seen within replay window, with time after which they can be
+	// forgotten
+	nonces map[string]time.Time
+}
+
+// newRequestVerifier returns nil if request signing is disabled
+func newRequestVerifier(cfg *scfg.RequestSigningConfig) (*requestVerifier, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+
+	keys, err := cfg.ParseClientKeys()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &requestVerifier{
+		keys:   keys,
+		window: cfg.ReplayWindow,
+		nonces: make(map[string]time.Time),
+	}, nil
+}
+
+func (v *requestVerifier) verify(r *http.Request, body []byte, now time.Time) error {
+	timestamp := r.Header.Get(RequestTimestampHeader)
+	nonce := r.Header.Get(RequestNonceHeader)
+	sigHex := r.Header.Get(RequestSignatureHeader)
+
+	if timestamp == "" || nonce == "" || sigHex == "" {
+		return fmt.Errorf("request must be signed with %s, %s and %s headers", RequestTimestampHeader, RequestNonceHeader, RequestSignatureHeader)
+	}
+
+	if len(nonce) > maxRequestNonceLen {
+		return fmt.Errorf("nonce must be at most %d characters long", maxRequestNonceLen)
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+
+	if err != nil {
+		return fmt.Errorf("invalid request timestamp %s", timestamp)
+	}
+
+	signedAt := time.Unix(unix, 0)
+
+	if signedAt.Before(now.Add(-v.window)) || signedAt.After(now.Add(v.window)) {
+		return fmt.Errorf("request timestamp is outside of the replay window of %s", v.window)
+	}
+
+	sigBytes, err := hex.DecodeString(sigHex)
+
+	if err != nil {
+		return fmt.Errorf("invalid request signature: %w", err)
+	}
+
+	sig, err := schnorr.ParseSignature(sigBytes)
+
+	if err != nil {
+		return fmt.Errorf("invalid request signature: %w", err)
+	}
+
+	sigHash := requestSigHash(timestamp, nonce, body)
+
+	verified := false
+	for _, key := range v.keys {
+		if sig.Verify(sigHash, key) {
+			verified = true
+			break
+		}
+	}
+
+	if !verified {
+		return fmt.Errorf("request signature does not match any client key")
+	}
+
+	// nonce is only recorded after signature is verified, so that nobody can
+	// exhaust nonces of legitimate clients
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for n, expiresAt := range v.nonces {
+		if now.After(expiresAt) {
+			delete(v.nonces, n)
+		}
+	}
+
+	if _, seen := v.nonces[nonce]; seen {
+		return fmt.Errorf("request with nonce %s was already processed", nonce)
+	}
+
+	// request with this nonce is rejected by timestamp check after its
+	// timestamp leaves replay window
+	v.nonces[nonce] = signedAt.Add(v.window)
+
+	return nil
+}
+
+// withRequestVerification rejects spend capable calls which are not signed by
+// one of client keys, or which are replayed. Signed calls must be json rpc POST
+// requests, as signature covers request body.
+func withRequestVerification(h http.Handler, v *requestVerifier) http.Handler {
+	if v == nil {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := rpcRequestOf(r)
+
+		if req.err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %s", req.err), http.StatusBadRequest)
+			return
+		}
+
+		id := req.firstId()
+
+		var spendMethod string
+		for _, method := range req.methods() {
+			if _, isSpend := spendMethods[method]; isSpend {
+				spendMethod = method
+				break
+			}
+		}
+
+		if spendMethod == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Method != http.MethodPost {
+			writeRpcError(w, id, http.StatusBadRequest, "Invalid request", RpcErrorData{
+				ErrorCode: ErrCodeInvalidParams,
+				Message:   fmt.Sprintf("method %s requires signed request and must be called as json rpc POST request", spendMethod),
+			})
+			return
+		}
+
+		if err := v.verify(r, req.body, time.Now()); err != nil {
+			writeRpcError(w, id, http.StatusUnauthorized, "Unauthorized", RpcErrorData{
+				ErrorCode: ErrCodeUnauthorized,
+				Message:   fmt.Sprintf("method %s requires signed request: %s", spendMethod, err),
+			})
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,244 @@
+package stakerservice
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	rpc "github.com/cometbft/cometbft/rpc/jsonrpc/server"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testStakeBody        = `{"jsonrpc":"2.0","id":1,"method":"stake","params":{}}`
+	testListOutputsBody  = `{"jsonrpc":"2.0","id":1,"method":"list_outputs","params":{}}`
+	testRequestSigWindow = time.Minute
+)
+
+type requestSigningTest struct {
+	clientKey *btcec.PrivateKey
+	handler   http.Handler
+	served    int
+}
+
+func newRequestSigningTest(t *testing.T) *requestSigningTest {
+	clientKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	otherClientKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	rt := &requestSigningTest{clientKey: clientKey}
+
+	verifier := &requestVerifier{
+		keys:   []*btcec.PublicKey{otherClientKey.PubKey(), clientKey.PubKey()},
+		window: testRequestSigWindow,
+		nonces: make(map[string]time.Time),
+	}
+
+	rt.handler = withRpcRequest(
+		withRequestVerification(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rt.served++
+		}), verifier),
+		&identityResolver{},
+		rpc.DefaultConfig().MaxBodyBytes,
+	)
+
+	return rt
+}
+
+func newTestRpcPost(body string) *http.Request {
+	return httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(body)))
+}
+
+// signTestRequest signs request the same way as SignRequest, but with given
+// timestamp and nonce
+func signTestRequest(t *testing.T, r *http.Request, body string, key *btcec.PrivateKey, signedAt time.Time, nonce string) {
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+
+	sig, err := schnorr.Sign(key, requestSigHash(timestamp, nonce, []byte(body)))
+	require.NoError(t, err)
+
+	r.Header.Set(RequestTimestampHeader, timestamp)
+	r.Header.Set(RequestNonceHeader, nonce)
+	r.Header.Set(RequestSignatureHeader, hex.EncodeToString(sig.Serialize()))
+}
+
+// serve returns http status of the response and data of json rpc error, if
+// the request was rejected with one
+func (rt *requestSigningTest) serve(t *testing.T, r *http.Request) (int, *RpcErrorData) {
+	recorder := httptest.NewRecorder()
+	rt.handler.ServeHTTP(recorder, r)
+
+	if recorder.Header().Get("Content-Type") != "application/json" {
+		return recorder.Code, nil
+	}
+
+	var resp struct {
+		Error struct {
+			Data string `json:"data"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+
+	var data RpcErrorData
+	require.NoError(t, json.Unmarshal([]byte(resp.Error.Data), &data))
+
+	return recorder.Code, &data
+}
+
+func TestRequestVerificationSignedSpendCall(t *testing.T) {
+	rt := newRequestSigningTest(t)
+
+	req := newTestRpcPost(testStakeBody)
+	require.NoError(t, SignRequest(req, rt.clientKey))
+
+	status, _ := rt.serve(t, req)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, 1, rt.served)
+}
+
+func TestRequestVerificationUnsignedCalls(t *testing.T) {
+	rt := newRequestSigningTest(t)
+
+	// read only calls do not need signatures
+	status, _ := rt.serve(t, newTestRpcPost(testListOutputsBody))
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, 1, rt.served)
+
+	status, errData := rt.serve(t, newTestRpcPost(testStakeBody))
+	require.Equal(t, http.StatusUnauthorized, status)
+	require.Equal(t, ErrCodeUnauthorized, errData.ErrorCode)
+	require.Contains(t, errData.Message, "method stake requires signed request")
+
+	// spend calls over uri can't carry signed body
+	status, errData = rt.serve(t, httptest.NewRequest(http.MethodGet, "/stake", nil))
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Equal(t, ErrCodeInvalidParams, errData.ErrorCode)
+
+	require.Equal(t, 1, rt.served)
+}
+
+func TestRequestVerificationReplayWindow(t *testing.T) {
+	rt := newRequestSigningTest(t)
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		signedAt time.Time
+		accepted bool
+	}{
+		{"signed within window", now.Add(-testRequestSigWindow / 2), true},
+		{"signed before window", now.Add(-testRequestSigWindow - 10*time.Second), false},
+		{"signed in the future", now.Add(testRequestSigWindow + 10*time.Second), false},
+	}
+
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := newTestRpcPost(testStakeBody)
+			signTestRequest(t, req, testStakeBody, rt.clientKey, tc.signedAt, "nonce-"+strconv.Itoa(i))
+
+			status, errData := rt.serve(t, req)
+
+			if tc.accepted {
+				require.Equal(t, http.StatusOK, status)
+				return
+			}
+
+			require.Equal(t, http.StatusUnauthorized, status)
+			require.Contains(t, errData.Message, "outside of the replay window")
+		})
+	}
+
+	require.Equal(t, 1, rt.served)
+}
+
+func TestRequestVerificationReusedNonce(t *testing.T) {
+	rt := newRequestSigningTest(t)
+
+	req := newTestRpcPost(testStakeBody)
+	signTestRequest(t, req, testStakeBody, rt.clientKey, time.Now(), "reused-nonce")
+	status, _ := rt.serve(t, req)
+	require.Equal(t, http.StatusOK, status)
+
+	// replay of the same request
+	replayed := newTestRpcPost(testStakeBody)
+	replayed.Header = req.Header.Clone()
+	status, errData := rt.serve(t, replayed)
+	require.Equal(t, http.StatusUnauthorized, status)
+	require.Contains(t, errData.Message, "already processed")
+
+	// freshly signed request with the same nonce
+	resigned := newTestRpcPost(testStakeBody)
+	signTestRequest(t, resigned, testStakeBody, rt.clientKey, time.Now().Add(time.Second), "reused-nonce")
+	status, errData = rt.serve(t, resigned)
+	require.Equal(t, http.StatusUnauthorized, status)
+	require.Contains(t, errData.Message, "already processed")
+
+	require.Equal(t, 1, rt.served)
+}
+
+func TestRequestVerificationWrongSignature(t *testing.T) {
+	rt := newRequestSigningTest(t)
+
+	unknownKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	req := newTestRpcPost(testStakeBody)
+	require.NoError(t, SignRequest(req, unknownKey))
+	status, errData := rt.serve(t, req)
+	require.Equal(t, http.StatusUnauthorized, status)
+	require.Contains(t, errData.Message, "does not match any client key")
+
+	// signature of client key over different body
+	tampered := `{"jsonrpc":"2.0","id":1,"method":"stake","params":{"amount":"1"}}`
+	req = newTestRpcPost(tampered)
+	signTestRequest(t, req, testStakeBody, rt.clientKey, time.Now(), "tampered-nonce")
+	status, errData = rt.serve(t, req)
+	require.Equal(t, http.StatusUnauthorized, status)
+	require.Contains(t, errData.Message, "does not match any client key")
+
+	// nonce of rejected request is not recorded, so that it can't be burned by
+	// requests with invalid signatures
+	req = newTestRpcPost(testStakeBody)
+	signTestRequest(t, req, testStakeBody, rt.clientKey, time.Now(), "tampered-nonce")
+	status, _ = rt.serve(t, req)
+	require.Equal(t, http.StatusOK, status)
+
+	require.Equal(t, 1, rt.served)
+}
+
+func TestRequestVerificationBatch(t *testing.T) {
+	rt := newRequestSigningTest(t)
+
+	readOnlyBatch := `[` + testListOutputsBody + `,{"jsonrpc":"2.0","id":2,"method":"staking_details","params":{}}]`
+	spendBatch := `[` + testListOutputsBody + `,{"jsonrpc":"2.0","id":2,"method":"stake","params":{}}]`
+
+	status, _ := rt.serve(t, newTestRpcPost(readOnlyBatch))
+	require.Equal(t, http.StatusOK, status)
+
+	// single spend call makes the whole batch require signature
+	status, errData := rt.serve(t, newTestRpcPost(spendBatch))
+	require.Equal(t, http.StatusUnauthorized, status)
+	require.Contains(t, errData.Message, "method stake requires signed request")
+
+	req := newTestRpcPost(spendBatch)
+	require.NoError(t, SignRequest(req, rt.clientKey))
+	status, _ = rt.serve(t, req)
+	require.Equal(t, http.StatusOK, status)
+
+	// signature covers all calls of the batch
+	req = newTestRpcPost(`[{"jsonrpc":"2.0","id":2,"method":"stake","params":{}}]`)
+	signTestRequest(t, req, spendBatch, rt.clientKey, time.Now(), "batch-nonce")
+	status, _ = rt.serve(t, req)
+	require.Equal(t, http.StatusUnauthorized, status)
+
+	require.Equal(t, 2, rt.served)
+}
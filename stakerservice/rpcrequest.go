@@ -0,0 +1,232 @@
+package stakerservice
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+type jsonRpcCall struct {
+	Id     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+}
+
+// rpcCaller is identity of the caller resolved from tokens of the request
+type rpcCaller struct {
+	// name of operator presenting valid operator token, empty otherwise
+	Operator string
+	// name of api identity presenting valid api token, empty otherwise
+	ApiIdentity string
+	// api token was presented, but it does not belong to any identity
+	InvalidApiToken bool
+}
+
+// String returns identity used in logs and access control rules. Tokens are
+// never logged.
+func (c rpcCaller) String() string {
+	switch {
+	case c.Operator != "":
+		return scfg.AclOperatorIdentityPrefix + c.Operator
+	case c.ApiIdentity != "":
+		return scfg.AclApiIdentityPrefix + c.ApiIdentity
+	default:
+		return accessLogAnonymous
+	}
+}
+
+// rpcRequest is json rpc request parsed once for all http middleware
+type rpcRequest struct {
+	calls []jsonRpcCall
+	// body of POST request, nil for uri requests
+	body []byte
+	// error of parsing the request. Middleware which needs calls rejects such
+	// requests, other middleware lets rpc handler report it.
+	err    error
+	caller rpcCaller
+}
+
+// methods returns methods called by the request
+func (r *rpcRequest) methods() []string {
+	methods := make([]string, len(r.calls))
+	for i, c := range r.calls {
+		methods[i] = c.Method
+	}
+
+	return methods
+}
+
+// firstId returns id of the first json rpc call
+func (r *rpcRequest) firstId() json.RawMessage {
+	if len(r.calls) == 0 {
+		return nil
+	}
+
+	return r.calls[0].Id
+}
+
+// methodsById maps ids of json rpc calls to their methods
+func (r *rpcRequest) methodsById() map[string]string {
+	methodsById := make(map[string]string, len(r.calls))
+	for _, c := range r.calls {
+		methodsById[string(c.Id)] = c.Method
+	}
+
+	return methodsById
+}
+
+type rpcRequestKey struct{}
+
+func withRpcRequestContext(r *http.Request, req *rpcRequest) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), rpcRequestKey{}, req))
+}
+
+// rpcRequestOf returns request parsed by withRpcRequest. Requests which did not
+// go through it are anonymous and without calls.
+func rpcRequestOf(r *http.Request) *rpcRequest {
+	if r != nil {
+		if req, ok := r.Context().Value(rpcRequestKey{}).(*rpcRequest); ok {
+			return req
+		}
+	}
+
+	return &rpcRequest{}
+}
+
+// callerOf returns caller of the rpc call
+func callerOf(ctx *rpctypes.Context) rpcCaller {
+	if ctx == nil {
+		return rpcCaller{}
+	}
+
+	return rpcRequestOf(ctx.HTTPReq).caller
+}
+
+// identityResolver maps operator and api tokens to names of their owners
+type identityResolver struct {
+	operators     map[[sha256.Size]byte]string
+	apiIdentities map[[sha256.Size]byte]string
+}
+
+func newIdentityResolver(cfg *scfg.Config) (*identityResolver, error) {
+	operators, err := cfg.ApprovalConfig.OperatorsByTokenHash()
+
+	if err != nil {
+		return nil, err
+	}
+
+	quotas, err := cfg.QuotaConfig.QuotasByTokenHash()
+
+	if err != nil {
+		return nil, err
+	}
+
+	apiIdentities := make(map[[sha256.Size]byte]string, len(quotas))
+	for tokenHash, quota := range quotas {
+		apiIdentities[tokenHash] = quota.Name
+	}
+
+	return &identityResolver{
+		operators:     operators,
+		apiIdentities: apiIdentities,
+	}, nil
+}
+
+func (ir *identityResolver) resolve(r *http.Request) rpcCaller {
+	var caller rpcCaller
+
+	if token := r.Header.Get(OperatorTokenHeader); token != "" {
+		caller.Operator = ir.operators[sha256.Sum256([]byte(token))]
+	}
+
+	if token := r.Header.Get(ApiTokenHeader); token != "" {
+		name, found := ir.apiIdentities[sha256.Sum256([]byte(token))]
+		caller.ApiIdentity = name
+		caller.InvalidApiToken = !found
+	}
+
+	return caller
+}
+
+// readRpcCalls returns json rpc calls made by the request. Calls are read
+// either from the uri of GET request or from the (possibly batched) json rpc
+// body. Read body is restored, so that it can be read again by rpc handler.
+func readRpcCalls(r *http.Request, maxBodyBytes int64) ([]jsonRpcCall, []byte, error) {
+	if strings.HasPrefix(r.URL.Path, streamPathPrefix) {
+		return []jsonRpcCall{{Method: strings.TrimPrefix(r.URL.Path, streamPathPrefix)}}, nil, nil
+	}
+
+	if r.Method != http.MethodPost {
+		method := strings.TrimPrefix(r.URL.Path, "/")
+
+		if method == "" {
+			// route listing
+			return nil, nil, nil
+		}
+
+		return []jsonRpcCall{{Method: method}}, nil, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if int64(len(body)) > maxBodyBytes {
+		return nil, nil, fmt.Errorf("request body larger than %d bytes", maxBodyBytes)
+	}
+
+	calls, err := parseRpcCalls(body)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return calls, body, nil
+}
+
+// parseRpcCalls parses (possibly batched) json rpc body
+func parseRpcCalls(body []byte) ([]jsonRpcCall, error) {
+	var calls []jsonRpcCall
+	trimmed := bytes.TrimSpace(body)
+
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &calls); err != nil {
+			return nil, err
+		}
+	} else {
+		var call jsonRpcCall
+		if err := json.Unmarshal(trimmed, &call); err != nil {
+			return nil, err
+		}
+		calls = append(calls, call)
+	}
+
+	return calls, nil
+}
+
+// withRpcRequest parses json rpc calls and resolves identity of the caller
+// once, so that all other middleware works with the same request
+func withRpcRequest(h http.Handler, identities *identityResolver, maxBodyBytes int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls, body, err := readRpcCalls(r, maxBodyBytes)
+
+		req := &rpcRequest{
+			calls:  calls,
+			body:   body,
+			err:    err,
+			caller: identities.resolve(r),
+		}
+
+		h.ServeHTTP(w, withRpcRequestContext(r, req))
+	})
+}
@@ -2,28 +2,39 @@ package stakerservice
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math"
 	"net"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync/atomic"
+	"time"
 
+	staking "github.com/babylonchain/babylon/btcstaking"
 	"github.com/babylonchain/btc-staker/babylonclient"
+	"github.com/babylonchain/btc-staker/proto"
 	str "github.com/babylonchain/btc-staker/staker"
 	scfg "github.com/babylonchain/btc-staker/stakercfg"
 	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/babylonchain/btc-staker/types"
+	"github.com/babylonchain/btc-staker/utils"
+	"github.com/babylonchain/btc-staker/walletcontroller"
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/cometbft/cometbft/libs/log"
 	rpc "github.com/cometbft/cometbft/rpc/jsonrpc/server"
 	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
 	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 
 	"github.com/lightningnetwork/lnd/kvdb"
 	"github.com/lightningnetwork/lnd/signal"
@@ -34,6 +45,26 @@ const (
 	defaultOffset = 0
 	defaultLimit  = 50
 	maxLimit      = 100
+
+	maxMetadataEntries     = 16
+	maxMetadataKeyLength   = 64
+	maxMetadataValueLength = 256
+
+	maxOverrideReasonLength = 1024
+
+	maxGroupNameLength = 64
+
+	maxStakingDetailsBatchSize = 100
+
+	maxMuSig2SessionIdLength = 128
+
+	defaultUnbondAllInterval = time.Second
+	maxUnbondAllInterval     = 10 * time.Minute
+
+	defaultExportGasLimit = 400000
+
+	defaultFeeAnalyticsDays    = 30
+	defaultConfirmationSlaDays = 7
 )
 
 type RoutesMap map[string]*rpc.RPCFunc
@@ -46,6 +77,11 @@ type StakerService struct {
 	logger      *logrus.Logger
 	db          kvdb.Backend
 	interceptor signal.Interceptor
+	cache       *responseCache
+	// nil if two person approval mode is disabled
+	approvals *approvalQueue
+	// nil if no stake quota is configured
+	quotas *stakeQuotas
 }
 
 func NewStakerService(
@@ -55,520 +91,2916 @@ func NewStakerService(
 	sig signal.Interceptor,
 	db kvdb.Backend,
 ) *StakerService {
+	cache := newResponseCache(c.RpcCacheConfig.TTL)
+
+	// fee estimates are recalculated by btc node on every new block
+	s.OnNewBlock(func(_ uint32) {
+		cache.invalidate(feeEstimateCacheKey)
+	})
+
 	return &StakerService{
 		config:      c,
 		staker:      s,
 		logger:      l,
 		interceptor: sig,
 		db:          db,
+		cache:       cache,
 	}
 }
 
+// stateName returns canonical name of the state, which is exposed to clients
+// instead of internal one
+func stateName(state proto.TransactionState) string {
+	return string(types.CanonicalDelegationState(state))
+}
+
 func storedTxToStakingDetails(storedTx *stakerdb.StoredTransaction) StakingDetails {
-	return StakingDetails{
-		StakingTxHash:  storedTx.StakingTx.TxHash().String(),
-		StakerAddress:  storedTx.StakerAddress,
-		StakingState:   storedTx.State.String(),
-		Watched:        storedTx.Watched,
-		TransactionIdx: strconv.FormatUint(storedTx.StoredTransactionIdx, 10),
+	details := StakingDetails{
+		StakingTxHash:     storedTx.StakingTx.TxHash().String(),
+		StakerAddress:     storedTx.StakerAddress,
+		StakingState:      stateName(storedTx.State),
+		Watched:           storedTx.Watched,
+		TransactionIdx:    strconv.FormatUint(storedTx.StoredTransactionIdx, 10),
+		Metadata:          storedTx.Metadata,
+		RequestId:         storedTx.RequestId,
+		Group:             storedTx.Group,
+		WithdrawalAddress: storedTx.WithdrawalAddress,
+		AutoWithdraw:      storedTx.AutoWithdraw,
+	}
+
+	if storedTx.ExternalStakerBtcPk != nil {
+		details.ExternalStakerPk = hex.EncodeToString(schnorr.SerializePubKey(storedTx.ExternalStakerBtcPk))
+	}
+
+	if storedTx.UnbondingTimeOverride != 0 {
+		details.UnbondingTimeOverride = strconv.FormatUint(uint64(storedTx.UnbondingTimeOverride), 10)
+	}
+
+	if storedTx.UnbondingFeeRateOverride != 0 {
+		details.UnbondingFeeRateOverride = strconv.FormatInt(int64(storedTx.UnbondingFeeRateOverride), 10)
+	}
+
+	for _, resp := range storedTx.BabylonTxResponses {
+		details.BabylonTxResponses = append(details.BabylonTxResponses, BabylonTxResponse{
+			MsgType:   resp.MsgType,
+			TxHash:    resp.TxHash,
+			Height:    strconv.FormatInt(resp.Height, 10),
+			Codespace: resp.Codespace,
+			Code:      strconv.FormatUint(uint64(resp.Code), 10),
+			Timestamp: resp.Timestamp.UTC().Format(time.RFC3339),
+		})
+	}
+
+	for _, note := range storedTx.Notes {
+		details.Notes = append(details.Notes, delegationNoteResponse(&note))
+	}
+
+	return details
+}
+
+func delegationNoteResponse(note *stakerdb.DelegationNote) DelegationNote {
+	return DelegationNote{
+		Timestamp: strconv.FormatInt(note.Timestamp.Unix(), 10),
+		Text:      note.Text,
+		RequestId: note.RequestId,
+	}
+}
+
+func validateMetadata(metadata map[string]string) error {
+	if len(metadata) > maxMetadataEntries {
+		return invalidParamsf("too many metadata entries. Max allowed: %d", maxMetadataEntries)
+	}
+
+	for k, v := range metadata {
+		if len(k) == 0 {
+			return invalidParamsf("metadata key cannot be empty")
+		}
+
+		if len(k) > maxMetadataKeyLength {
+			return invalidParamsf("metadata key %s is too long. Max allowed length: %d", k, maxMetadataKeyLength)
+		}
+
+		if len(v) > maxMetadataValueLength {
+			return invalidParamsf("metadata value for key %s is too long. Max allowed length: %d", k, maxMetadataValueLength)
+		}
+	}
+
+	return nil
+}
+
+// validateGroupName accepts empty name, which means delegation does not belong to
+// any group
+func validateGroupName(group string) error {
+	if len(group) > maxGroupNameLength {
+		return invalidParamsf("group name is too long. Max allowed length: %d", maxGroupNameLength)
+	}
+
+	for _, c := range group {
+		isAlnum := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+
+		if !isAlnum && c != '.' && c != '_' && c != '-' {
+			return invalidParamsf("group name %s contains invalid character %q. Allowed are letters, digits, '.', '_' and '-'", group, c)
+		}
+	}
+
+	return nil
+}
+
+func validateMuSig2SessionId(sessionId string) error {
+	if sessionId == "" || len(sessionId) > maxMuSig2SessionIdLength {
+		return invalidParamsf("session id must be between 1 and %d characters long", maxMuSig2SessionIdLength)
+	}
+
+	for _, c := range sessionId {
+		isAlnum := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+
+		if !isAlnum && c != '.' && c != '_' && c != '-' && c != ':' {
+			return invalidParamsf("session id %s contains invalid character %q. Allowed are letters, digits, '.', '_', '-' and ':'", sessionId, c)
+		}
+	}
+
+	return nil
+}
+
+// delegationStates returns canonical states which can appear in responses, so
+// that integrators can validate states they handle
+func (s *StakerService) delegationStates(_ *rpctypes.Context) (*DelegationStatesResponse, error) {
+	states := types.DelegationStates()
+
+	resp := &DelegationStatesResponse{
+		Version: strconv.Itoa(types.DelegationStatesVersion),
+		States:  make([]DelegationStateResponse, len(states)),
+	}
+
+	for i, state := range states {
+		resp.States[i] = DelegationStateResponse{
+			Name:        string(state.State),
+			Description: state.Description,
+		}
 	}
+
+	return resp, nil
 }
 
 func (s *StakerService) health(_ *rpctypes.Context) (*ResultHealth, error) {
-	return &ResultHealth{}, nil
+	result := &ResultHealth{}
+
+	if b := s.staker.BabylonBalance(); b != nil {
+		result.BabylonBalance = &BabylonBalanceResponse{
+			Balance:    b.Balance.Amount.String(),
+			Denom:      b.Balance.Denom,
+			MinBalance: b.MinBalance.String(),
+			Low:        b.Low,
+			CheckedAt:  strconv.FormatInt(b.CheckedAt.Unix(), 10),
+		}
+	}
+
+	if lc := s.staker.BtcLightClient(); lc != nil {
+		result.BtcLightClient = &BtcLightClientResponse{
+			BtcHeight:         strconv.FormatUint(lc.BtcHeight, 10),
+			LightClientHeight: strconv.FormatUint(lc.LightClientHeight, 10),
+			Lag:               strconv.FormatUint(lc.Lag, 10),
+			Lagging:           lc.Lagging,
+			CheckedAt:         strconv.FormatInt(lc.CheckedAt.Unix(), 10),
+		}
+	}
+
+	return result, nil
 }
 
-func (s *StakerService) stake(_ *rpctypes.Context,
+func (s *StakerService) stake(ctx *rpctypes.Context,
 	stakerAddress string,
 	stakingAmount int64,
 	fpBtcPks []string,
 	stakingTimeBlocks int64,
+	metadata map[string]string,
+	requestId *string,
+	preset *string,
+	minConfirmations *int,
+	autoWithdraw *bool,
 ) (*ResultStake, error) {
+	reqId, err := resolveRequestId(ctx, requestId)
+	if err != nil {
+		return nil, err
+	}
 
-	if stakingAmount <= 0 {
-		return nil, fmt.Errorf("staking amount must be positive")
+	minConfs, err := s.minFundingConfirmations(minConfirmations)
+	if err != nil {
+		return nil, err
 	}
 
-	amount := btcutil.Amount(stakingAmount)
+	if err := validateMetadata(metadata); err != nil {
+		return nil, invalidParams(err)
+	}
 
 	stakerAddr, err := btcutil.DecodeAddress(stakerAddress, &s.config.ActiveNetParams)
 	if err != nil {
-		return nil, err
+		return nil, invalidParams(err)
 	}
 
-	var fpPubKeys []*btcec.PublicKey = make([]*btcec.PublicKey, 0)
+	if preset != nil && *preset != "" {
+		// finality providers and staking time come from the preset, amount can be
+		// omitted for presets with single amount tier
+		if len(fpBtcPks) > 0 || stakingTimeBlocks != 0 {
+			return nil, invalidParamsf("finality providers and staking time must not be provided together with preset")
+		}
 
-	for _, fpPk := range fpBtcPks {
-		fpPkBytes, err := hex.DecodeString(fpPk)
+		if stakingAmount < 0 {
+			return nil, invalidParamsf("staking amount must not be negative")
+		}
+
+		stakingPreset, err := s.staker.GetStakingPreset(*preset)
 		if err != nil {
 			return nil, err
 		}
 
-		fpSchnorrKey, err := schnorr.ParsePubKey(fpPkBytes)
+		// amount is resolved before quota check, as it can be omitted
+		amount, err := stakingPreset.ResolveAmount(btcutil.Amount(stakingAmount))
 		if err != nil {
 			return nil, err
 		}
 
-		fpPubKeys = append(fpPubKeys, fpSchnorrKey)
+		release, err := s.reserveQuota(ctx, amount)
+		if err != nil {
+			return nil, err
+		}
+
+		stakingTxHash, err := s.staker.StakeFundsWithPreset(stakerAddr, amount, *preset, minConfs, metadata, reqId)
+		if err != nil {
+			release()
+			return nil, err
+		}
+
+		return &ResultStake{
+			TxHash:    stakingTxHash.String(),
+			RequestId: reqId,
+			Warning:   s.enableAutoWithdraw(stakingTxHash, autoWithdraw),
+		}, nil
 	}
 
-	if stakingTimeBlocks <= 0 || stakingTimeBlocks > math.MaxUint16 {
-		return nil, fmt.Errorf("staking time must be positive and lower than %d", math.MaxUint16)
+	if stakingAmount <= 0 {
+		return nil, invalidParamsf("staking amount must be positive")
 	}
 
-	stakingTimeUint16 := uint16(stakingTimeBlocks)
+	amount := btcutil.Amount(stakingAmount)
 
-	stakingTxHash, err := s.staker.StakeFunds(stakerAddr, amount, fpPubKeys, stakingTimeUint16)
+	fpPubKeys, err := parseSchnorrPubKeys(fpBtcPks)
 	if err != nil {
-		return nil, err
+		return nil, invalidParams(err)
 	}
 
-	return &ResultStake{
-		TxHash: stakingTxHash.String(),
-	}, nil
-}
+	if stakingTimeBlocks <= 0 || stakingTimeBlocks > math.MaxUint16 {
+		return nil, invalidParamsf("staking time must be positive and lower than %d", math.MaxUint16)
+	}
 
-func (s *StakerService) stakingDetails(_ *rpctypes.Context,
-	stakingTxHash string) (*StakingDetails, error) {
+	stakingTimeUint16 := uint16(stakingTimeBlocks)
 
-	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
+	release, err := s.reserveQuota(ctx, amount)
 	if err != nil {
 		return nil, err
 	}
 
-	storedTx, err := s.staker.GetStoredTransaction(txHash)
+	stakingTxHash, err := s.staker.StakeFunds(stakerAddr, amount, fpPubKeys, stakingTimeUint16, minConfs, metadata, reqId)
 	if err != nil {
+		release()
 		return nil, err
 	}
 
-	details := storedTxToStakingDetails(storedTx)
-	return &details, nil
+	return &ResultStake{
+		TxHash:    stakingTxHash.String(),
+		RequestId: reqId,
+		Warning:   s.enableAutoWithdraw(stakingTxHash, autoWithdraw),
+	}, nil
 }
 
-func (s *StakerService) spendStake(_ *rpctypes.Context,
-	stakingTxHash string) (*SpendTxDetails, error) {
-	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
+// enableAutoWithdraw enables automatic withdrawal of just staked delegation if it
+// was requested. Funds are already staked, so failure is reported as warning
+// instead of error, which could make the client stake again.
+func (s *StakerService) enableAutoWithdraw(stakingTxHash *chainhash.Hash, autoWithdraw *bool) string {
+	if autoWithdraw == nil || !*autoWithdraw {
+		return ""
+	}
 
-	if err != nil {
-		return nil, err
+	if err := s.staker.SetAutoWithdraw(stakingTxHash, true); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"stakingTxHash": stakingTxHash,
+			"err":           err,
+		}).Error("Failed to enable automatic withdrawal of staked funds")
+
+		return fmt.Sprintf("funds were staked, but automatic withdrawal could not be enabled: %v", err)
 	}
 
-	spendTxHash, value, err := s.staker.SpendStake(txHash)
+	return ""
+}
 
-	if err != nil {
-		return nil, err
+// minFundingConfirmations returns minimum confirmations of outputs funding
+// staking transaction, configured value is used if not provided
+func (s *StakerService) minFundingConfirmations(minConfirmations *int) (uint32, error) {
+	if minConfirmations == nil {
+		return s.config.StakerConfig.MinFundingConfirmations, nil
 	}
 
-	txValue := strconv.FormatInt(int64(*value), 10)
+	if *minConfirmations < 0 || *minConfirmations > math.MaxInt32 {
+		return 0, invalidParamsf("min confirmations must be between 0 and %d", math.MaxInt32)
+	}
 
-	return &SpendTxDetails{
-		TxHash:  spendTxHash.String(),
-		TxValue: txValue,
-	}, nil
+	return uint32(*minConfirmations), nil
 }
 
-func (s *StakerService) listOutputs(_ *rpctypes.Context) (*OutputsResponse, error) {
-
-	outputs, err := s.staker.ListUnspentOutputs()
+func parseSchnorrPubKeys(pks []string) ([]*btcec.PublicKey, error) {
+	var pubKeys []*btcec.PublicKey = make([]*btcec.PublicKey, 0)
 
-	if err != nil {
-		return nil, err
-	}
+	for _, pk := range pks {
+		pkBytes, err := hex.DecodeString(pk)
+		if err != nil {
+			return nil, invalidParams(err)
+		}
 
-	var outputDetails []OutputDetail
+		schnorrKey, err := schnorr.ParsePubKey(pkBytes)
+		if err != nil {
+			return nil, invalidParams(err)
+		}
 
-	for _, output := range outputs {
-		outputDetails = append(outputDetails, OutputDetail{
-			Address: output.Address,
-			Amount:  output.Amount.String(),
-		})
+		pubKeys = append(pubKeys, schnorrKey)
 	}
 
-	return &OutputsResponse{
-		Outputs: outputDetails,
-	}, nil
+	return pubKeys, nil
 }
 
-type PageParams struct {
-	Offset uint64
-	Limit  uint64
-}
+func (s *StakerService) stakeExternal(ctx *rpctypes.Context,
+	fundingAddress string,
+	stakerPk string,
+	stakingAmount int64,
+	fpBtcPks []string,
+	stakingTimeBlocks int64,
+	metadata map[string]string,
+	requestId *string,
+	minConfirmations *int,
+) (*ResultStakeExternal, error) {
+	reqId, err := resolveRequestId(ctx, requestId)
+	if err != nil {
+		return nil, err
+	}
 
-func getPageParams(offsetPtr *int, limitPtr *int) PageParams {
-	var limit uint64
+	minConfs, err := s.minFundingConfirmations(minConfirmations)
+	if err != nil {
+		return nil, err
+	}
 
-	if limitPtr == nil {
-		limit = defaultLimit
-	} else {
-		limit = uint64(*limitPtr)
+	if stakingAmount <= 0 {
+		return nil, invalidParamsf("staking amount must be positive")
 	}
 
-	if limit > maxLimit {
-		limit = maxLimit
+	if err := validateMetadata(metadata); err != nil {
+		return nil, invalidParams(err)
 	}
 
-	var offset uint64
+	amount := btcutil.Amount(stakingAmount)
 
-	if offsetPtr == nil {
-		offset = defaultOffset
-	} else {
-		offset = uint64(*offsetPtr)
+	fundingAddr, err := btcutil.DecodeAddress(fundingAddress, &s.config.ActiveNetParams)
+	if err != nil {
+		return nil, invalidParams(err)
 	}
 
-	return PageParams{
-		Offset: offset,
-		Limit:  limit,
+	stakerPkBytes, err := hex.DecodeString(stakerPk)
+	if err != nil {
+		return nil, invalidParams(err)
 	}
-}
 
-func (s *StakerService) providers(_ *rpctypes.Context, offset, limit *int) (*FinalityProvidersResponse, error) {
+	stakerBtcPk, err := schnorr.ParsePubKey(stakerPkBytes)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
 
-	pageParams := getPageParams(offset, limit)
+	fpPubKeys, err := parseSchnorrPubKeys(fpBtcPks)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
 
-	providersResp, err := s.staker.ListActiveFinalityProviders(pageParams.Limit, pageParams.Offset)
+	if stakingTimeBlocks <= 0 || stakingTimeBlocks > math.MaxUint16 {
+		return nil, invalidParamsf("staking time must be positive and lower than %d", math.MaxUint16)
+	}
 
+	release, err := s.reserveQuota(ctx, amount)
 	if err != nil {
 		return nil, err
 	}
 
-	var providerInfos []FinalityProviderInfoResponse
-
-	for _, provider := range providersResp.FinalityProviders {
-		v := FinalityProviderInfoResponse{
-			BabylonPublicKey: hex.EncodeToString(provider.BabylonPk.Key),
-			BtcPublicKey:     hex.EncodeToString(schnorr.SerializePubKey(&provider.BtcPk)),
-		}
-
-		providerInfos = append(providerInfos, v)
+	stakingTxHash, err := s.staker.StakeFundsForExternalKey(
+		fundingAddr,
+		stakerBtcPk,
+		amount,
+		fpPubKeys,
+		uint16(stakingTimeBlocks),
+		minConfs,
+		metadata,
+		reqId,
+	)
+	if err != nil {
+		release()
+		return nil, err
 	}
 
-	totalCount := strconv.FormatUint(providersResp.Total, 10)
-
-	return &FinalityProvidersResponse{
-		FinalityProviders:           providerInfos,
-		TotalFinalityProvidersCount: totalCount,
+	return &ResultStakeExternal{
+		TxHash:    stakingTxHash.String(),
+		StakerPk:  hex.EncodeToString(schnorr.SerializePubKey(stakerBtcPk)),
+		Warning:   "staked funds can only be spent by the owner of external staker key, which also needs to register delegation on babylon",
+		RequestId: reqId,
 	}, nil
 }
 
-func (s *StakerService) listStakingTransactions(_ *rpctypes.Context, offset, limit *int) (*ListStakingTransactionsResponse, error) {
-	pageParams := getPageParams(offset, limit)
+func (s *StakerService) stakingDetails(_ *rpctypes.Context,
+	stakingTxHash string) (*StakingDetails, error) {
 
-	txResult, err := s.staker.StoredTransactions(pageParams.Limit, pageParams.Offset)
+	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
 
+	storedTx, err := s.staker.GetStoredTransaction(txHash)
 	if err != nil {
 		return nil, err
 	}
 
-	var stakingDetails []StakingDetails
+	details := storedTxToStakingDetails(storedTx)
+	return &details, nil
+}
 
-	for _, tx := range txResult.Transactions {
-		tx := tx
-		stakingDetails = append(stakingDetails, storedTxToStakingDetails(&tx))
+// stakingDetailsBatch returns details of multiple staking transactions in the
+// order of requested hashes. Failure to get details of one transaction is
+// reported in its item and does not fail the whole call.
+func (s *StakerService) stakingDetailsBatch(_ *rpctypes.Context,
+	stakingTxHashes []string) (*StakingDetailsBatchResponse, error) {
+
+	if len(stakingTxHashes) == 0 {
+		return nil, invalidParamsf("at least one staking transaction hash must be provided")
 	}
 
-	totalCount := strconv.FormatUint(txResult.Total, 10)
+	if len(stakingTxHashes) > maxStakingDetailsBatchSize {
+		return nil, invalidParamsf("at most %d staking transaction hashes can be provided", maxStakingDetailsBatchSize)
+	}
 
-	return &ListStakingTransactionsResponse{
-		Transactions:          stakingDetails,
-		TotalTransactionCount: totalCount,
+	items := make([]StakingDetailsBatchItem, len(stakingTxHashes))
+	for i, hashStr := range stakingTxHashes {
+		items[i].StakingTxHash = hashStr
+
+		details, err := s.stakingDetails(nil, hashStr)
+
+		if err != nil {
+			items[i].Error = &RpcErrorData{
+				ErrorCode: errorCode(err, s.config),
+				Message:   err.Error(),
+			}
+			continue
+		}
+
+		items[i].Details = details
+	}
+
+	return &StakingDetailsBatchResponse{
+		Items: items,
 	}, nil
 }
 
-func (s *StakerService) withdrawableTransactions(_ *rpctypes.Context, offset, limit *int) (*WithdrawableTransactionsResponse, error) {
-	pageParams := getPageParams(offset, limit)
-
-	txResult, err := s.staker.WithdrawableTransactions(pageParams.Limit, pageParams.Offset)
+func spendInfoToSpendPathInfo(info *staking.SpendInfo) (*SpendPathInfo, error) {
+	controlBlockBytes, err := info.ControlBlock.ToBytes()
 
 	if err != nil {
 		return nil, err
 	}
 
-	var stakingDetails []StakingDetails
+	leafHash := info.RevealedLeaf.TapHash()
 
-	for _, tx := range txResult.Transactions {
-		stakingDetails = append(stakingDetails, storedTxToStakingDetails(&tx))
+	return &SpendPathInfo{
+		LeafScript:   hex.EncodeToString(info.RevealedLeaf.Script),
+		LeafVersion:  strconv.FormatUint(uint64(info.RevealedLeaf.LeafVersion), 10),
+		LeafHash:     hex.EncodeToString(leafHash[:]),
+		MerkleProof:  hex.EncodeToString(info.ControlBlock.InclusionProof),
+		ControlBlock: hex.EncodeToString(controlBlockBytes),
+	}, nil
+}
+
+func (s *StakerService) proveOwnership(_ *rpctypes.Context,
+	stakingTxHash string,
+	challenge string,
+) (*OwnershipProofResponse, error) {
+	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
+	if err != nil {
+		return nil, invalidParams(err)
 	}
 
-	var lastIdx string = "0"
-	if len(stakingDetails) > 0 {
-		// this should ease up pagination i.e in case when whe have 1000 transactions, and we limit query to 50
-		// due to filetring we can retrun  response with 50 transactions when last one have index 400,
-		// then caller can specify offset=400 and get next withdrawable transactions.
-		lastIdx = stakingDetails[len(stakingDetails)-1].TransactionIdx
+	proof, err := s.staker.ProveOwnership(txHash, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OwnershipProofResponse{
+		StakingTxHash: proof.StakingTxHash.String(),
+		StakerPk:      hex.EncodeToString(schnorr.SerializePubKey(proof.StakerPk)),
+		Challenge:     proof.Challenge,
+		Signature:     hex.EncodeToString(proof.Signature.Serialize()),
+	}, nil
+}
+
+func (s *StakerService) verifyOwnershipProof(_ *rpctypes.Context,
+	stakingTxHash string,
+	stakerPk string,
+	challenge string,
+	signature string,
+) (*VerifyOwnershipProofResponse, error) {
+	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	pkBytes, err := hex.DecodeString(stakerPk)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	pk, err := schnorr.ParsePubKey(pkBytes)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	sigBytes, err := hex.DecodeString(signature)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	sig, err := schnorr.ParseSignature(sigBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := str.VerifyOwnershipProof(txHash, pk, challenge, sig); err != nil {
+		return &VerifyOwnershipProofResponse{
+			Valid:  false,
+			Reason: err.Error(),
+		}, nil
+	}
+
+	return &VerifyOwnershipProofResponse{
+		Valid: true,
+	}, nil
+}
+
+func (s *StakerService) proofOfReserves(_ *rpctypes.Context,
+	message string,
+) (*ProofOfReservesResponse, error) {
+	report, err := s.staker.ProofOfReserves(message)
+	if err != nil {
+		return nil, err
+	}
+
+	return proofOfReservesResponse(report), nil
+}
+
+func (s *StakerService) signMessage(_ *rpctypes.Context,
+	stakerAddress string,
+	message string,
+) (*SignMessageResponse, error) {
+	address, err := btcutil.DecodeAddress(stakerAddress, &s.config.ActiveNetParams)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	sig, err := s.staker.SignMessage(address, message)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignMessageResponse{
+		Address:   address.EncodeAddress(),
+		Message:   message,
+		Signature: base64.StdEncoding.EncodeToString(sig),
+	}, nil
+}
+
+func musig2NonceToResponse(n *stakerdb.MuSig2Nonce) *MuSig2NonceResponse {
+	return &MuSig2NonceResponse{
+		SessionId: n.SessionId,
+		SignerPk:  hex.EncodeToString(n.SignerPk),
+		PubNonce:  hex.EncodeToString(n.PubNonce),
+		CreatedAt: n.CreatedAt.UTC().Format(time.RFC3339),
+		ExpiresAt: n.ExpiresAt.UTC().Format(time.RFC3339),
+		Used:      n.Used,
+		Expired:   n.Expired(time.Now()),
+	}
+}
+
+func (s *StakerService) generateMuSig2Nonce(_ *rpctypes.Context,
+	sessionId string,
+	signerAddress string,
+	message *string,
+	ttlSeconds *int64,
+) (*MuSig2NonceResponse, error) {
+	if err := validateMuSig2SessionId(sessionId); err != nil {
+		return nil, err
+	}
+
+	address, err := btcutil.DecodeAddress(signerAddress, &s.config.ActiveNetParams)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	var msg []byte
+	if message != nil && *message != "" {
+		msg, err = hex.DecodeString(*message)
+
+		if err != nil || len(msg) != 32 {
+			return nil, invalidParamsf("message must be 32 hex encoded bytes")
+		}
+	}
+
+	ttl := str.DefaultMuSig2NonceTtl
+	if ttlSeconds != nil {
+		ttl = time.Duration(*ttlSeconds) * time.Second
+
+		if ttl <= 0 || ttl > str.MaxMuSig2NonceTtl {
+			return nil, invalidParamsf("ttl must be between 1 and %d seconds", int64(str.MaxMuSig2NonceTtl.Seconds()))
+		}
+	}
+
+	nonce, err := s.staker.GenerateMuSig2Nonce(sessionId, address, msg, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	return musig2NonceToResponse(nonce), nil
+}
+
+func (s *StakerService) musig2Nonce(_ *rpctypes.Context, sessionId string) (*MuSig2NonceResponse, error) {
+	if err := validateMuSig2SessionId(sessionId); err != nil {
+		return nil, err
+	}
+
+	nonce, err := s.staker.MuSig2Nonce(sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	return musig2NonceToResponse(nonce), nil
+}
+
+func (s *StakerService) verifyMessage(_ *rpctypes.Context,
+	address string,
+	message string,
+	signature string,
+) (*VerifyMessageResponse, error) {
+	addr, err := btcutil.DecodeAddress(address, &s.config.ActiveNetParams)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	if err := str.VerifyMessage(addr, message, sig); err != nil {
+		return &VerifyMessageResponse{
+			Valid:  false,
+			Reason: err.Error(),
+		}, nil
+	}
+
+	return &VerifyMessageResponse{
+		Valid: true,
+	}, nil
+}
+
+func (s *StakerService) stakingScriptInfo(_ *rpctypes.Context,
+	stakingTxHash string) (*StakingScriptInfoResponse, error) {
+
+	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	storedTx, err := s.staker.GetStoredTransaction(txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := s.staker.StakingScriptsInfo(txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	timeLockPath, err := spendInfoToSpendPathInfo(info.TimeLockPath)
+	if err != nil {
+		return nil, err
+	}
+
+	unbondingPath, err := spendInfoToSpendPathInfo(info.UnbondingPath)
+	if err != nil {
+		return nil, err
+	}
+
+	slashingPath, err := spendInfoToSpendPathInfo(info.SlashingPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StakingScriptInfoResponse{
+		StakingTxHash:         txHash.String(),
+		StakingOutputIdx:      strconv.FormatUint(uint64(storedTx.StakingOutputIndex), 10),
+		StakingOutputValue:    strconv.FormatInt(info.StakingOutput.Value, 10),
+		StakingOutputPkScript: hex.EncodeToString(info.StakingOutput.PkScript),
+		InternalKey:           hex.EncodeToString(schnorr.SerializePubKey(info.TimeLockPath.ControlBlock.InternalKey)),
+		TimeLockPath:          *timeLockPath,
+		UnbondingPath:         *unbondingPath,
+		SlashingPath:          *slashingPath,
+	}, nil
+}
+
+func (s *StakerService) delegationGraph(_ *rpctypes.Context,
+	stakingTxHash string) (*DelegationGraphResponse, error) {
+
+	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	graph, err := s.staker.DelegationGraph(txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &DelegationGraphResponse{
+		StakingTxHash: txHash.String(),
+		State:         graph.State.String(),
+		Nodes:         make([]DelegationGraphNode, len(graph.Nodes)),
+		Edges:         make([]DelegationGraphEdge, len(graph.Edges)),
+	}
+
+	for i, node := range graph.Nodes {
+		resp.Nodes[i] = DelegationGraphNode{
+			TxHash: node.TxHash.String(),
+			Kind:   node.Kind,
+			Status: node.Status,
+		}
+
+		if node.BlockHeight > 0 {
+			resp.Nodes[i].BlockHeight = strconv.FormatUint(uint64(node.BlockHeight), 10)
+		}
+	}
+
+	for i, edge := range graph.Edges {
+		resp.Edges[i] = DelegationGraphEdge{
+			FromTxHash: edge.From.String(),
+			ToTxHash:   edge.To.String(),
+		}
+
+		if edge.OutputIndex != nil {
+			resp.Edges[i].OutputIdx = strconv.FormatUint(uint64(*edge.OutputIndex), 10)
+		}
+	}
+
+	return resp, nil
+}
+
+func sigHashTypeName(sigHashType txscript.SigHashType) string {
+	switch sigHashType {
+	case txscript.SigHashDefault:
+		return "SIGHASH_DEFAULT"
+	case txscript.SigHashAll:
+		return "SIGHASH_ALL"
+	default:
+		return fmt.Sprintf("0x%02x", uint32(sigHashType))
+	}
+}
+
+func (s *StakerService) computeSigHashes(_ *rpctypes.Context, tx string, stakingTxHash *string) (*ComputeSigHashesResponse, error) {
+	btcTx, err := decodeBtcTx(tx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var delegationHash *chainhash.Hash
+	if stakingTxHash != nil && *stakingTxHash != "" {
+		delegationHash, err = chainhash.NewHashFromStr(*stakingTxHash)
+
+		if err != nil {
+			return nil, invalidParams(err)
+		}
+	}
+
+	sigHashes, err := s.staker.ComputeSigHashes(btcTx, delegationHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ComputeSigHashesResponse{
+		TxHash:    btcTx.TxHash().String(),
+		SigHashes: make([]InputSigHashResponse, len(sigHashes)),
+	}
+
+	for i, sh := range sigHashes {
+		resp.SigHashes[i] = InputSigHashResponse{
+			InputIndex:    strconv.Itoa(sh.InputIndex),
+			SpendPath:     sh.SpendPath,
+			SignatureType: sh.SignatureType,
+			SigHashType:   sigHashTypeName(sh.SigHashType),
+			LeafScript:    hex.EncodeToString(sh.LeafScript),
+			SigHash:       hex.EncodeToString(sh.SigHash),
+		}
+	}
+
+	return resp, nil
+}
+
+func (s *StakerService) exitTemplates(_ *rpctypes.Context, stakingTxHash string) (*str.ExportedExitTemplates, error) {
+	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	templates, err := s.staker.ExitTemplates(txHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return templates.Export()
+}
+
+func (s *StakerService) spendStake(_ *rpctypes.Context,
+	stakingTxHash string) (*SpendTxDetails, error) {
+	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
+
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	spendTxHash, value, err := s.staker.SpendStake(txHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	txValue := strconv.FormatInt(int64(*value), 10)
+
+	return &SpendTxDetails{
+		TxHash:  spendTxHash.String(),
+		TxValue: txValue,
+	}, nil
+}
+
+func (s *StakerService) listOutputs(_ *rpctypes.Context) (*OutputsResponse, error) {
+
+	outputs, err := s.staker.ListUnspentOutputs()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var outputDetails []OutputDetail
+
+	for _, output := range outputs {
+		outputDetails = append(outputDetails, OutputDetail{
+			Address:  output.Address,
+			Amount:   output.Amount.String(),
+			Outpoint: output.OutPoint.String(),
+			Frozen:   s.staker.IsOutputFrozen(&output.OutPoint),
+		})
+	}
+
+	return &OutputsResponse{
+		Outputs: outputDetails,
+	}, nil
+}
+
+func (s *StakerService) consolidateOutputs(_ *rpctypes.Context,
+	destinationAddress string,
+	maxUtxoValue *int,
+	feeRate *int,
+) (*ConsolidateOutputsResponse, error) {
+	destAddr, err := btcutil.DecodeAddress(destinationAddress, &s.config.ActiveNetParams)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	maxValue := btcutil.Amount(s.config.ConsolidationConfig.MaxUtxoValue)
+
+	if maxUtxoValue != nil {
+		if *maxUtxoValue <= 0 {
+			return nil, invalidParamsf("max utxo value must be positive")
+		}
+		maxValue = btcutil.Amount(*maxUtxoValue)
+	}
+
+	var feeRateBtc *btcutil.Amount = nil
+
+	if feeRate != nil {
+		amt := btcutil.Amount(*feeRate)
+		feeRateBtc = &amt
+	}
+
+	result, err := s.staker.ConsolidateOutputs(destAddr, maxValue, feeRateBtc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConsolidateOutputsResponse{
+		TxHash:         result.TxHash.String(),
+		NumInputs:      strconv.Itoa(result.NumInputs),
+		InputsValue:    strconv.FormatInt(int64(result.InputsValue), 10),
+		Fee:            strconv.FormatInt(int64(result.Fee), 10),
+		ConsolidatedTo: result.ConsolidatedTo.EncodeAddress(),
+	}, nil
+}
+
+type PageParams struct {
+	Offset uint64
+	Limit  uint64
+}
+
+// getPageParams normalises pagination params of the request, negative values are
+// treated as missing ones
+func getPageParams(offsetPtr *int, limitPtr *int) PageParams {
+	var limit uint64
+
+	if limitPtr == nil || *limitPtr < 0 {
+		limit = defaultLimit
+	} else {
+		limit = uint64(*limitPtr)
+	}
+
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	var offset uint64
+
+	if offsetPtr == nil || *offsetPtr < 0 {
+		offset = defaultOffset
+	} else {
+		offset = uint64(*offsetPtr)
+	}
+
+	return PageParams{
+		Offset: offset,
+		Limit:  limit,
+	}
+}
+
+func (s *StakerService) providers(_ *rpctypes.Context, offset, limit *int) (*FinalityProvidersResponse, error) {
+
+	pageParams := getPageParams(offset, limit)
+
+	cacheKey := fmt.Sprintf("%s:%d:%d", finalityProvidersCacheKeyPrefix, pageParams.Offset, pageParams.Limit)
+
+	return cachedResponse(s.cache, cacheKey, func() (*FinalityProvidersResponse, error) {
+		return s.loadProviders(pageParams)
+	})
+}
+
+func (s *StakerService) loadProviders(pageParams PageParams) (*FinalityProvidersResponse, error) {
+	providersResp, err := s.staker.ListActiveFinalityProviders(pageParams.Limit, pageParams.Offset)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var providerInfos []FinalityProviderInfoResponse
+
+	for _, provider := range providersResp.FinalityProviders {
+		v := FinalityProviderInfoResponse{
+			BabylonPublicKey: hex.EncodeToString(provider.BabylonPk.Key),
+			BtcPublicKey:     hex.EncodeToString(schnorr.SerializePubKey(&provider.BtcPk)),
+		}
+
+		providerInfos = append(providerInfos, v)
+	}
+
+	totalCount := strconv.FormatUint(providersResp.Total, 10)
+
+	return &FinalityProvidersResponse{
+		FinalityProviders:           providerInfos,
+		TotalFinalityProvidersCount: totalCount,
+	}, nil
+}
+
+func (s *StakerService) babylonStakingParams(_ *rpctypes.Context) (*BabylonStakingParamsResponse, error) {
+	return cachedResponse(s.cache, babylonParamsCacheKey, func() (*BabylonStakingParamsResponse, error) {
+		params, err := s.staker.BabylonController().Params()
+
+		if err != nil {
+			return nil, err
+		}
+
+		covenantPks := make([]string, len(params.CovenantPks))
+		for i, pk := range params.CovenantPks {
+			covenantPks[i] = hex.EncodeToString(schnorr.SerializePubKey(pk))
+		}
+
+		return &BabylonStakingParamsResponse{
+			ConfirmationTimeBlocks:    strconv.FormatUint(uint64(params.ConfirmationTimeBlocks), 10),
+			FinalizationTimeoutBlocks: strconv.FormatUint(uint64(params.FinalizationTimeoutBlocks), 10),
+			MinSlashingTxFeeSat:       strconv.FormatInt(int64(params.MinSlashingTxFeeSat), 10),
+			CovenantPks:               covenantPks,
+			CovenantQuorum:            strconv.FormatUint(uint64(params.CovenantQuruomThreshold), 10),
+			SlashingAddress:           params.SlashingAddress.EncodeAddress(),
+			SlashingRate:              params.SlashingRate.String(),
+			MinUnbondingTime:          strconv.FormatUint(uint64(params.MinUnbondingTime), 10),
+		}, nil
+	})
+}
+
+func (s *StakerService) feeEstimate(_ *rpctypes.Context) (*FeeEstimateResponse, error) {
+	return cachedResponse(s.cache, feeEstimateCacheKey, func() (*FeeEstimateResponse, error) {
+		feeRate := s.staker.CurrentFeeRate()
+		minFeeRate := s.staker.MinFeeRate()
+
+		return &FeeEstimateResponse{
+			FeeRateSatPerKvb:    strconv.FormatInt(int64(feeRate), 10),
+			FeeRateSatPerVb:     strconv.FormatInt(int64(feeRate/1000), 10),
+			MinFeeRateSatPerKvb: strconv.FormatInt(int64(minFeeRate), 10),
+		}, nil
+	})
+}
+
+func feeBudgetWindowToResponse(w *str.FeeBudgetWindow) FeeBudgetWindowResponse {
+	spentPerKind := make(map[string]string, len(w.SpentPerKind))
+	for kind, spent := range w.SpentPerKind {
+		spentPerKind[kind] = strconv.FormatInt(int64(spent), 10)
+	}
+
+	return FeeBudgetWindowResponse{
+		WindowHours:  strconv.FormatInt(int64(w.Window.Hours()), 10),
+		Spent:        strconv.FormatInt(int64(w.Spent), 10),
+		Budget:       strconv.FormatInt(int64(w.Budget), 10),
+		Exceeded:     w.Exceeded(),
+		SpentPerKind: spentPerKind,
+	}
+}
+
+func (s *StakerService) feeBudget(_ *rpctypes.Context) (*FeeBudgetResponse, error) {
+	status, err := s.staker.FeeBudgetStatus()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &FeeBudgetResponse{
+		LastDay:               feeBudgetWindowToResponse(&status.Day),
+		LastWeek:              feeBudgetWindowToResponse(&status.Week),
+		Exceeded:              status.Exceeded(),
+		BlockAutomatedActions: s.config.FeeBudgetConfig.BlockAutomatedActions,
+	}, nil
+}
+
+func (s *StakerService) feeAnalytics(_ *rpctypes.Context, days *int) (*FeeAnalyticsResponse, error) {
+	numDays := defaultFeeAnalyticsDays
+	if days != nil {
+		numDays = *days
+	}
+
+	maxDays := int(s.config.FeeBudgetConfig.HistoryRetention / (24 * time.Hour))
+
+	if numDays <= 0 || numDays > maxDays {
+		return nil, invalidParamsf("days must be between 1 and %d, the fee history retention", maxDays)
+	}
+
+	stats, err := s.staker.FeeAnalytics(uint32(numDays))
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &FeeAnalyticsResponse{
+		Stats: make([]FeeStatsResponse, len(stats)),
+	}
+
+	for i, st := range stats {
+		resp.Stats[i] = FeeStatsResponse{
+			Day:                   st.Day.Format(time.DateOnly),
+			Kind:                  st.Kind,
+			Count:                 strconv.Itoa(st.Count),
+			TotalFee:              strconv.FormatInt(int64(st.TotalFee), 10),
+			AvgFeeRate:            strconv.FormatFloat(st.AvgFeeRate, 'f', 2, 64),
+			MedianFeeRate:         strconv.FormatFloat(st.MedianFeeRate, 'f', 2, 64),
+			Confirmed:             strconv.Itoa(st.Confirmed),
+			AvgConfirmationBlocks: strconv.FormatFloat(st.AvgConfirmationBlocks, 'f', 2, 64),
+		}
+	}
+
+	return resp, nil
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(d.Seconds()), 10)
+}
+
+func (s *StakerService) confirmationSla(_ *rpctypes.Context, days *int) (*ConfirmationSlaResponse, error) {
+	numDays := defaultConfirmationSlaDays
+	if days != nil {
+		numDays = *days
+	}
+
+	maxDays := int(s.config.FeeBudgetConfig.HistoryRetention / (24 * time.Hour))
+
+	if numDays <= 0 || numDays > maxDays {
+		return nil, invalidParamsf("days must be between 1 and %d, the fee history retention", maxDays)
+	}
+
+	summary, err := s.staker.ConfirmationSlaSummary(time.Duration(numDays) * 24 * time.Hour)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ConfirmationSlaResponse{
+		TargetSeconds: formatSeconds(summary.Target),
+		Kinds:         make([]ConfirmationSlaKindResponse, len(summary.Kinds)),
+		Flagged:       make([]FlaggedTransactionResponse, len(summary.Flagged)),
+	}
+
+	for i, k := range summary.Kinds {
+		resp.Kinds[i] = ConfirmationSlaKindResponse{
+			Kind:     k.Kind,
+			Observed: strconv.Itoa(k.Observed),
+			Avg:      formatSeconds(k.Avg),
+			Median:   formatSeconds(k.Median),
+			P90:      formatSeconds(k.P90),
+			Max:      formatSeconds(k.Max),
+			Exceeded: strconv.Itoa(k.Exceeded),
+		}
+	}
+
+	for i, f := range summary.Flagged {
+		resp.Flagged[i] = FlaggedTransactionResponse{
+			TxHash: f.TxHash.String(),
+			Kind:   f.Kind,
+			SentAt: f.SentAt.UTC().Format(time.RFC3339),
+		}
+
+		if f.TimeToFirstConfirmation != nil {
+			resp.Flagged[i].TimeToFirstConfirmation = formatSeconds(*f.TimeToFirstConfirmation)
+		}
+	}
+
+	return resp, nil
+}
+
+func (s *StakerService) parseUtxoBlocklistParams(
+	outpoints []string,
+	addresses []string,
+) ([]wire.OutPoint, []btcutil.Address, error) {
+	cfg := scfg.UtxoBlocklistConfig{
+		Outpoints: outpoints,
+		Addresses: addresses,
+	}
+
+	parsedOutpoints, parsedAddresses, err := cfg.Parse(&s.config.ActiveNetParams)
+
+	if err != nil {
+		return nil, nil, invalidParams(err)
+	}
+
+	return parsedOutpoints, parsedAddresses, nil
+}
+
+func (s *StakerService) utxoBlocklistResponse() *UtxoBlocklistResponse {
+	entries := s.staker.UtxoBlocklist()
+
+	respEntries := make([]UtxoBlocklistEntry, len(entries))
+	for i, e := range entries {
+		respEntries[i] = UtxoBlocklistEntry{
+			Reason: e.Reason,
+			Source: e.Source,
+		}
+
+		if e.Outpoint != nil {
+			respEntries[i].Outpoint = e.Outpoint.String()
+		} else {
+			respEntries[i].Address = e.Address.EncodeAddress()
+		}
+
+		if !e.Timestamp.IsZero() {
+			respEntries[i].Timestamp = strconv.FormatInt(e.Timestamp.Unix(), 10)
+		}
+	}
+
+	return &UtxoBlocklistResponse{
+		Entries: respEntries,
+	}
+}
+
+func (s *StakerService) stakingPresetsResponse() *StakingPresetsResponse {
+	presets := s.staker.StakingPresets()
+
+	respPresets := make([]StakingPresetResponse, len(presets))
+	for i, p := range presets {
+		fpPks := make([]string, len(p.FinalityProviders))
+		for j, pk := range p.FinalityProviders {
+			fpPks[j] = hex.EncodeToString(schnorr.SerializePubKey(pk))
+		}
+
+		amountTiers := make([]string, len(p.AmountTiers))
+		for j, a := range p.AmountTiers {
+			amountTiers[j] = strconv.FormatInt(int64(a), 10)
+		}
+
+		respPresets[i] = StakingPresetResponse{
+			Name:              p.Name,
+			FpBtcPks:          fpPks,
+			StakingTimeBlocks: strconv.FormatUint(uint64(p.StakingTimeBlocks), 10),
+			AmountTiers:       amountTiers,
+			MaxFeeRate:        strconv.FormatUint(p.MaxFeeRate, 10),
+			Source:            p.Source,
+		}
+
+		if !p.UpdatedAt.IsZero() {
+			respPresets[i].UpdatedAt = strconv.FormatInt(p.UpdatedAt.Unix(), 10)
+		}
+	}
+
+	return &StakingPresetsResponse{
+		Presets: respPresets,
+	}
+}
+
+func (s *StakerService) stakingPresets(_ *rpctypes.Context) (*StakingPresetsResponse, error) {
+	return s.stakingPresetsResponse(), nil
+}
+
+func (s *StakerService) setStakingPreset(
+	_ *rpctypes.Context,
+	name string,
+	fpBtcPks []string,
+	stakingTimeBlocks int64,
+	amountTiers []int64,
+	maxFeeRate *int64,
+) (*StakingPresetsResponse, error) {
+	fpPubKeys, err := parseSchnorrPubKeys(fpBtcPks)
+	if err != nil {
+		return nil, err
+	}
+
+	if stakingTimeBlocks <= 0 || stakingTimeBlocks > math.MaxUint16 {
+		return nil, invalidParamsf("staking time must be positive and lower than %d", math.MaxUint16)
+	}
+
+	preset := &scfg.StakingPreset{
+		Name:              name,
+		FinalityProviders: fpPubKeys,
+		StakingTimeBlocks: uint16(stakingTimeBlocks),
+	}
+
+	for _, a := range amountTiers {
+		preset.AmountTiers = append(preset.AmountTiers, btcutil.Amount(a))
+	}
+
+	if maxFeeRate != nil {
+		if *maxFeeRate < 0 {
+			return nil, invalidParamsf("max fee rate must not be negative")
+		}
+
+		preset.MaxFeeRate = uint64(*maxFeeRate)
+	}
+
+	if err := preset.Validate(); err != nil {
+		return nil, invalidParams(err)
+	}
+
+	if err := s.staker.SetStakingPreset(preset); err != nil {
+		return nil, err
+	}
+
+	return s.stakingPresetsResponse(), nil
+}
+
+func (s *StakerService) deleteStakingPreset(_ *rpctypes.Context, name string) (*StakingPresetsResponse, error) {
+	if err := s.staker.DeleteStakingPreset(name); err != nil {
+		return nil, err
+	}
+
+	return s.stakingPresetsResponse(), nil
+}
+
+func (s *StakerService) utxoBlocklist(_ *rpctypes.Context) (*UtxoBlocklistResponse, error) {
+	return s.utxoBlocklistResponse(), nil
+}
+
+func (s *StakerService) utxoBlocklistAdd(
+	_ *rpctypes.Context,
+	outpoints []string,
+	addresses []string,
+	reason string,
+) (*UtxoBlocklistResponse, error) {
+	parsedOutpoints, parsedAddresses, err := s.parseUtxoBlocklistParams(outpoints, addresses)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.staker.AddToUtxoBlocklist(parsedOutpoints, parsedAddresses, reason); err != nil {
+		return nil, err
+	}
+
+	return s.utxoBlocklistResponse(), nil
+}
+
+func (s *StakerService) utxoBlocklistRemove(
+	_ *rpctypes.Context,
+	outpoints []string,
+	addresses []string,
+) (*UtxoBlocklistResponse, error) {
+	parsedOutpoints, parsedAddresses, err := s.parseUtxoBlocklistParams(outpoints, addresses)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.staker.RemoveFromUtxoBlocklist(parsedOutpoints, parsedAddresses); err != nil {
+		return nil, err
+	}
+
+	return s.utxoBlocklistResponse(), nil
+}
+
+func (s *StakerService) walletTransactions(_ *rpctypes.Context, label *string, limit *int) (*WalletTransactionsResponse, error) {
+	labelPrefix := str.StakingTxLabelPrefix
+	if label != nil {
+		labelPrefix = *label
+	}
+
+	pageParams := getPageParams(nil, limit)
+
+	txs, err := s.staker.WalletTransactionsByLabel(labelPrefix, int(pageParams.Limit))
+
+	if err != nil {
+		if errors.Is(err, walletcontroller.ErrLabelsNotSupported) {
+			return nil, invalidParams(err)
+		}
+		return nil, err
+	}
+
+	details := make([]WalletTransactionDetail, len(txs))
+	for i, tx := range txs {
+		var fee string
+		if tx.Fee != nil {
+			fee = strconv.FormatInt(int64(*tx.Fee), 10)
+		}
+
+		details[i] = WalletTransactionDetail{
+			TxHash:        tx.TxHash,
+			Vout:          tx.Vout,
+			Address:       tx.Address,
+			Label:         tx.Label,
+			Category:      tx.Category,
+			Amount:        strconv.FormatInt(int64(tx.Amount), 10),
+			Fee:           fee,
+			Confirmations: strconv.FormatInt(tx.Confirmations, 10),
+			Time:          strconv.FormatInt(tx.Time, 10),
+		}
+	}
+
+	return &WalletTransactionsResponse{
+		Transactions: details,
+	}, nil
+}
+
+func (s *StakerService) frozenOutputsResponse() *FrozenOutputsResponse {
+	outputs := s.staker.FrozenOutputs()
+
+	details := make([]FrozenOutputDetail, len(outputs))
+	for i, o := range outputs {
+		details[i] = FrozenOutputDetail{
+			Outpoint:  o.Outpoint.String(),
+			Note:      o.Note,
+			Timestamp: strconv.FormatInt(o.Timestamp.Unix(), 10),
+		}
+	}
+
+	return &FrozenOutputsResponse{
+		Outputs: details,
+	}
+}
+
+func (s *StakerService) frozenOutputs(_ *rpctypes.Context) (*FrozenOutputsResponse, error) {
+	return s.frozenOutputsResponse(), nil
+}
+
+func (s *StakerService) freezeOutput(
+	_ *rpctypes.Context,
+	outpoint string,
+	note string,
+) (*FrozenOutputsResponse, error) {
+	o, err := wire.NewOutPointFromString(outpoint)
+
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	if err := s.staker.FreezeOutput(o, note); err != nil {
+		return nil, err
+	}
+
+	return s.frozenOutputsResponse(), nil
+}
+
+func (s *StakerService) unfreezeOutput(
+	_ *rpctypes.Context,
+	outpoint string,
+) (*FrozenOutputsResponse, error) {
+	o, err := wire.NewOutPointFromString(outpoint)
+
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	if err := s.staker.UnfreezeOutput(o); err != nil {
+		return nil, err
+	}
+
+	return s.frozenOutputsResponse(), nil
+}
+
+func (s *StakerService) listStakingTransactions(
+	_ *rpctypes.Context,
+	offset, limit *int,
+	metadataFilter map[string]string,
+	group *string,
+	createdAfter, createdBefore *int64,
+	transitionState *string,
+	transitionAfter, transitionBefore *int64,
+) (*ListStakingTransactionsResponse, error) {
+	pageParams := getPageParams(offset, limit)
+
+	timeFilter, err := parseTimeFilter(createdAfter, createdBefore, transitionState, transitionAfter, transitionBefore)
+
+	if err != nil {
+		return nil, err
+	}
+
+	txResult, err := s.staker.StoredTransactions(pageParams.Limit, pageParams.Offset, metadataFilter, group, timeFilter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var stakingDetails []StakingDetails
+
+	for _, tx := range txResult.Transactions {
+		tx := tx
+		stakingDetails = append(stakingDetails, storedTxToStakingDetails(&tx))
 	}
 
 	totalCount := strconv.FormatUint(txResult.Total, 10)
 
-	return &WithdrawableTransactionsResponse{
-		Transactions:                     stakingDetails,
-		LastWithdrawableTransactionIndex: lastIdx,
-		TotalTransactionCount:            totalCount,
+	return &ListStakingTransactionsResponse{
+		Transactions:          stakingDetails,
+		TotalTransactionCount: totalCount,
+	}, nil
+}
+
+func stateTimestamp(tx *stakerdb.StoredTransaction, state proto.TransactionState) string {
+	timestamp, found := tx.StateTimestamp(state)
+
+	if !found {
+		return ""
+	}
+
+	return strconv.FormatInt(timestamp.Unix(), 10)
+}
+
+func storedTxToStakingReportEntry(tx *stakerdb.StoredTransaction) StakingReportEntry {
+	var fpPks []string
+	for _, pk := range tx.FinalityProvidersBtcPks {
+		fpPks = append(fpPks, hex.EncodeToString(schnorr.SerializePubKey(pk)))
+	}
+
+	var blockHeight string
+	if tx.StakingTxConfirmationInfo != nil {
+		blockHeight = strconv.FormatUint(uint64(tx.StakingTxConfirmationInfo.Height), 10)
+	}
+
+	return StakingReportEntry{
+		StakingTxHash:        tx.StakingTx.TxHash().String(),
+		StakerAddress:        tx.StakerAddress,
+		StakingState:         stateName(tx.State),
+		Watched:              tx.Watched,
+		StakingAmount:        strconv.FormatInt(tx.StakingTx.TxOut[tx.StakingOutputIndex].Value, 10),
+		StakingTimeBlocks:    strconv.FormatUint(uint64(tx.StakingTime), 10),
+		FinalityProviderPks:  fpPks,
+		StakingTxFee:         strconv.FormatInt(int64(tx.StakingTxFee), 10),
+		UnbondingTxFee:       strconv.FormatInt(int64(tx.UnbondingTxFee()), 10),
+		SpendTxFee:           strconv.FormatInt(int64(tx.SpendTxFee), 10),
+		CreatedAt:            stateTimestamp(tx, proto.TransactionState_SENT_TO_BTC),
+		ConfirmedOnBtcAt:     stateTimestamp(tx, proto.TransactionState_CONFIRMED_ON_BTC),
+		SentToBabylonAt:      stateTimestamp(tx, proto.TransactionState_SENT_TO_BABYLON),
+		DelegationActiveAt:   stateTimestamp(tx, proto.TransactionState_DELEGATION_ACTIVE),
+		UnbondingConfirmedAt: stateTimestamp(tx, proto.TransactionState_UNBONDING_CONFIRMED_ON_BTC),
+		SpentOnBtcAt:         stateTimestamp(tx, proto.TransactionState_SPENT_ON_BTC),
+		StakingTxBlockHeight: blockHeight,
+		Metadata:             tx.Metadata,
+	}
+}
+
+func (s *StakerService) stakingReport(_ *rpctypes.Context, from, to *int64) (*StakingReportResponse, error) {
+	var fromTime, toTime time.Time
+
+	if from != nil {
+		fromTime = time.Unix(*from, 0)
+	}
+
+	if to != nil {
+		toTime = time.Unix(*to, 0)
+	}
+
+	if !fromTime.IsZero() && !toTime.IsZero() && toTime.Before(fromTime) {
+		return nil, invalidParamsf("end of report time range must not be before its start")
+	}
+
+	txs, err := s.staker.StoredTransactionsCreatedBetween(fromTime, toTime)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]StakingReportEntry, 0, len(txs))
+	for _, tx := range txs {
+		tx := tx
+		entries = append(entries, storedTxToStakingReportEntry(&tx))
+	}
+
+	return &StakingReportResponse{
+		Entries: entries,
+	}, nil
+}
+
+func (s *StakerService) stakingSummary(_ *rpctypes.Context, group *string) (*StakingSummaryResponse, error) {
+	summary, err := s.staker.StakingSummary(group)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stakingSummaryToResponse(summary), nil
+}
+
+func (s *StakerService) groupSummaries(_ *rpctypes.Context) (*GroupSummariesResponse, error) {
+	summaries, err := s.staker.GroupSummaries()
+
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]GroupSummaryResponse, 0, len(summaries))
+	for group, summary := range summaries {
+		groups = append(groups, GroupSummaryResponse{
+			Group:   group,
+			Summary: *stakingSummaryToResponse(summary),
+		})
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Group < groups[j].Group
+	})
+
+	return &GroupSummariesResponse{
+		Groups: groups,
+	}, nil
+}
+
+func (s *StakerService) setDelegationGroup(
+	_ *rpctypes.Context,
+	stakingTxHashes []string,
+	group string,
+) (*SetDelegationGroupResponse, error) {
+	if len(stakingTxHashes) == 0 {
+		return nil, invalidParamsf("at least one staking transaction hash must be provided")
+	}
+
+	if err := validateGroupName(group); err != nil {
+		return nil, err
+	}
+
+	hashes := make([]*chainhash.Hash, len(stakingTxHashes))
+	for i, hashStr := range stakingTxHashes {
+		hash, err := chainhash.NewHashFromStr(hashStr)
+
+		if err != nil {
+			return nil, invalidParams(err)
+		}
+
+		hashes[i] = hash
+	}
+
+	if err := s.staker.SetDelegationGroup(hashes, group); err != nil {
+		return nil, err
+	}
+
+	return &SetDelegationGroupResponse{
+		Group:           group,
+		StakingTxHashes: stakingTxHashes,
+	}, nil
+}
+
+func stakingSummaryToResponse(summary *str.StakingSummary) *StakingSummaryResponse {
+	perState := make(map[string]string)
+	for state, count := range summary.TransactionsPerState {
+		perState[state.String()] = strconv.FormatUint(count, 10)
+	}
+
+	totalFees := summary.StakingTxFees + summary.UnbondingTxFees + summary.SpendTxFees
+
+	return &StakingSummaryResponse{
+		TotalStaked:                strconv.FormatInt(int64(summary.TotalStaked), 10),
+		PendingStaked:              strconv.FormatInt(int64(summary.PendingStaked), 10),
+		ActiveStaked:               strconv.FormatInt(int64(summary.ActiveStaked), 10),
+		UnbondingStaked:            strconv.FormatInt(int64(summary.UnbondingStaked), 10),
+		WithdrawnAmount:            strconv.FormatInt(int64(summary.WithdrawnAmount), 10),
+		StakingTxFees:              strconv.FormatInt(int64(summary.StakingTxFees), 10),
+		UnbondingTxFees:            strconv.FormatInt(int64(summary.UnbondingTxFees), 10),
+		SpendTxFees:                strconv.FormatInt(int64(summary.SpendTxFees), 10),
+		TotalFees:                  strconv.FormatInt(int64(totalFees), 10),
+		TotalTransactionCount:      strconv.FormatUint(summary.TotalTransactions, 10),
+		TransactionCountPerState:   perState,
+		WatchedTransactionCount:    strconv.FormatUint(summary.WatchedTransactions, 10),
+		TransactionsWithoutFeeData: strconv.FormatUint(summary.TransactionsWithoutFeeData, 10),
+	}
+}
+
+func (s *StakerService) babylonRewards(_ *rpctypes.Context) (*BabylonRewardsResponse, error) {
+	rewards, err := s.staker.BabylonRewards()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var gauges []RewardGaugeResponse
+	for stakeholderType, gauge := range rewards.Gauges {
+		gauges = append(gauges, RewardGaugeResponse{
+			Type:              stakeholderType,
+			Coins:             gauge.Coins.String(),
+			WithdrawnCoins:    gauge.WithdrawnCoins.String(),
+			WithdrawableCoins: gauge.Withdrawable().String(),
+		})
+	}
+
+	// map iteration order is random, keep response stable
+	sort.Slice(gauges, func(i, j int) bool {
+		return gauges[i].Type < gauges[j].Type
+	})
+
+	return &BabylonRewardsResponse{
+		BabylonAddress: rewards.Address,
+		Rewards:        gauges,
+	}, nil
+}
+
+func (s *StakerService) withdrawBabylonRewards(
+	_ *rpctypes.Context,
+	stakeholderType *string,
+	recipient *string,
+) (*WithdrawRewardsResponse, error) {
+	var rewardType, recipientAddress string
+
+	if stakeholderType != nil {
+		rewardType = *stakeholderType
+	}
+
+	if recipient != nil {
+		recipientAddress = *recipient
+	}
+
+	result, err := s.staker.WithdrawBabylonRewards(rewardType, recipientAddress)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &WithdrawRewardsResponse{
+		TxHash:         result.TxHash,
+		WithdrawnCoins: result.WithdrawnCoins.String(),
+		Recipient:      result.Recipient,
+	}, nil
+}
+
+func (s *StakerService) withdrawableTransactions(_ *rpctypes.Context, offset, limit *int) (*WithdrawableTransactionsResponse, error) {
+	pageParams := getPageParams(offset, limit)
+
+	txResult, err := s.staker.WithdrawableTransactions(pageParams.Limit, pageParams.Offset)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var stakingDetails []StakingDetails
+
+	for _, tx := range txResult.Transactions {
+		stakingDetails = append(stakingDetails, storedTxToStakingDetails(&tx))
+	}
+
+	var lastIdx string = "0"
+	if len(stakingDetails) > 0 {
+		// this should ease up pagination i.e in case when whe have 1000 transactions, and we limit query to 50
+		// due to filetring we can retrun  response with 50 transactions when last one have index 400,
+		// then caller can specify offset=400 and get next withdrawable transactions.
+		lastIdx = stakingDetails[len(stakingDetails)-1].TransactionIdx
+	}
+
+	// withdrawable transactions are paginated by index of stored transactions,
+	// so total count of all stored transactions is returned
+	totalCount := strconv.FormatUint(txResult.TotalStored, 10)
+
+	return &WithdrawableTransactionsResponse{
+		Transactions:                     stakingDetails,
+		LastWithdrawableTransactionIndex: lastIdx,
+		TotalTransactionCount:            totalCount,
+	}, nil
+}
+
+func (s *StakerService) searchStakingTransactions(
+	_ *rpctypes.Context,
+	query *string,
+	minAmount, maxAmount *int64,
+	offset, limit *int,
+	createdAfter, createdBefore *int64,
+	transitionState *string,
+	transitionAfter, transitionBefore *int64,
+) (*SearchStakingTransactionsResponse, error) {
+	pageParams := getPageParams(offset, limit)
+
+	timeFilter, err := parseTimeFilter(createdAfter, createdBefore, transitionState, transitionAfter, transitionBefore)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var search str.TransactionSearch
+
+	if query != nil {
+		search.Text = *query
+	}
+
+	if minAmount != nil {
+		if *minAmount < 0 {
+			return nil, invalidParamsf("minAmount must be non-negative")
+		}
+		search.MinAmount = btcutil.Amount(*minAmount)
+	}
+
+	if maxAmount != nil {
+		if *maxAmount < 0 {
+			return nil, invalidParamsf("maxAmount must be non-negative")
+		}
+		search.MaxAmount = btcutil.Amount(*maxAmount)
+	}
+
+	if search.MinAmount > 0 && search.MaxAmount > 0 && search.MinAmount > search.MaxAmount {
+		return nil, invalidParamsf("minAmount must not be greater than maxAmount")
+	}
+
+	txResult, err := s.staker.SearchStoredTransactions(pageParams.Limit, pageParams.Offset, search, timeFilter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stakingDetails := make([]StakingDetails, 0, len(txResult.Transactions))
+
+	for _, tx := range txResult.Transactions {
+		tx := tx
+		stakingDetails = append(stakingDetails, storedTxToStakingDetails(&tx))
+	}
+
+	// matching transactions are sparse, so next page starts after the last
+	// returned transaction
+	lastIdx := "0"
+	if len(stakingDetails) > 0 {
+		lastIdx = stakingDetails[len(stakingDetails)-1].TransactionIdx
+	}
+
+	return &SearchStakingTransactionsResponse{
+		Transactions:         stakingDetails,
+		LastTransactionIndex: lastIdx,
+	}, nil
+}
+
+func decodeBtcTx(txHex string) (*wire.MsgTx, error) {
+	txBytes, err := hex.DecodeString(txHex)
+
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	var txMsg wire.MsgTx
+
+	err = txMsg.Deserialize(bytes.NewReader(txBytes))
+
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	return &txMsg, nil
+}
+
+func decodeBtcPk(pkHex string) (*btcec.PublicKey, error) {
+	pkBytes, err := hex.DecodeString(pkHex)
+
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	pk, err := schnorr.ParsePubKey(pkBytes)
+
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	return pk, nil
+}
+
+func parseTimeBtcLock(timelockTime int) (uint16, error) {
+	if timelockTime <= 0 {
+		return 0, invalidParamsf("staking time must be positive")
+	}
+
+	if timelockTime > math.MaxUint16 {
+		return 0, invalidParamsf("staking time %d is too big", timelockTime)
+	}
+
+	return uint16(timelockTime), nil
+}
+
+func parseStakingValue(stakingValue int) (btcutil.Amount, error) {
+	if stakingValue <= 0 {
+		return 0, invalidParamsf("staking value must be positive")
+	}
+
+	return btcutil.Amount(stakingValue), nil
+}
+
+func (s *StakerService) watchStaking(
+	_ *rpctypes.Context,
+	stakingTx string,
+	stakingTime int,
+	stakingValue int,
+	stakerBtcPk string,
+	fpBtcPks []string,
+	slashingTx string,
+	slashingTxSig string,
+	stakerBabylonPk string,
+	stakerAddress string,
+	stakerBabylonSig string,
+	stakerBtcSig string,
+	unbondingTx string,
+	slashUnbondingTx string,
+	slashUnbondingTxSig string,
+	unbondingTime int,
+	popType int,
+	metadata map[string]string,
+) (*ResultStake, error) {
+
+	if err := validateMetadata(metadata); err != nil {
+		return nil, invalidParams(err)
+	}
+
+	stkTx, err := decodeBtcTx(stakingTx)
+	if err != nil {
+		return nil, err
+	}
+
+	slshTx, err := decodeBtcTx(slashingTx)
+	if err != nil {
+		return nil, err
+	}
+
+	stakerBtcPkParsed, err := decodeBtcPk(stakerBtcPk)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var fpPubKeys []*btcec.PublicKey = make([]*btcec.PublicKey, 0)
+
+	for _, fpPk := range fpBtcPks {
+		fpPkBytes, err := hex.DecodeString(fpPk)
+		if err != nil {
+			return nil, invalidParams(err)
+		}
+
+		fpSchnorrKey, err := schnorr.ParsePubKey(fpPkBytes)
+		if err != nil {
+			return nil, invalidParams(err)
+		}
+
+		fpPubKeys = append(fpPubKeys, fpSchnorrKey)
+	}
+
+	stakingTimeUint16, err := parseTimeBtcLock(stakingTime)
+
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	stakingValueBtc, err := parseStakingValue(stakingValue)
+
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	stakerAddr, err := btcutil.DecodeAddress(stakerAddress, &s.config.ActiveNetParams)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	slashTxSigBytes, err := hex.DecodeString(slashingTxSig)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	slashingTxSchnorSig, err := schnorr.ParseSignature(slashTxSigBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	stakerBabylonPubkeyBytes, err := hex.DecodeString(stakerBabylonPk)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	if len(stakerBabylonPubkeyBytes) != secp256k1.PubKeySize {
+		return nil, invalidParamsf("babylon public key must have %d bytes", secp256k1.PubKeySize)
+	}
+
+	stakerBabylonPubKey := secp256k1.PubKey{
+		Key: stakerBabylonPubkeyBytes,
+	}
+
+	stakerBabylonSigBytes, err := hex.DecodeString(stakerBabylonSig)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	stakerBtcSigBytes, err := hex.DecodeString(stakerBtcSig)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	btcPopType, err := babylonclient.IntToPopType(popType)
+	if err != nil {
+		return nil, err
+	}
+
+	proofOfPossesion, err := babylonclient.NewBabylonPop(btcPopType, stakerBabylonSigBytes, stakerBtcSigBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unbonding related data
+	unbTx, err := decodeBtcTx(unbondingTx)
+	if err != nil {
+		return nil, err
+	}
+
+	slshUnbTx, err := decodeBtcTx(slashUnbondingTx)
+	if err != nil {
+		return nil, err
+	}
+
+	slashUnbTxSigBytes, err := hex.DecodeString(slashUnbondingTxSig)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	slashUnbTxSig, err := schnorr.ParseSignature(slashUnbTxSigBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	unbTime, err := parseTimeBtcLock(unbondingTime)
+
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	hash, err := s.staker.WatchStaking(
+		stkTx,
+		stakingTimeUint16,
+		stakingValueBtc,
+		fpPubKeys,
+		slshTx,
+		slashingTxSchnorSig,
+		&stakerBabylonPubKey,
+		stakerBtcPkParsed,
+		stakerAddr,
+		proofOfPossesion,
+		unbTx,
+		slshUnbTx,
+		slashUnbTxSig,
+		unbTime,
+		metadata,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResultStake{
+		TxHash: hash.String(),
 	}, nil
 }
 
-func decodeBtcTx(txHex string) (*wire.MsgTx, error) {
-	txBytes, err := hex.DecodeString(txHex)
+func (s *StakerService) unbondStaking(
+	_ *rpctypes.Context,
+	stakingTxHash string,
+	feeRate *int,
+	destinationAddress *string,
+) (*UnbondingResponse, error) {
+	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
+
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	var feeRateBtc *btcutil.Amount = nil
+
+	if feeRate != nil {
+		amt := btcutil.Amount(*feeRate)
+		feeRateBtc = &amt
+	}
+
+	var destAddress btcutil.Address
+
+	if destinationAddress != nil && *destinationAddress != "" {
+		destAddress, err = btcutil.DecodeAddress(*destinationAddress, &s.config.ActiveNetParams)
+
+		if err != nil {
+			return nil, invalidParams(err)
+		}
+	}
+
+	unbondingTxHash, err := s.staker.UnbondStaking(*txHash, feeRateBtc, destAddress)
 
 	if err != nil {
 		return nil, err
 	}
 
-	var txMsg wire.MsgTx
+	return &UnbondingResponse{
+		UnbondingTxHash: unbondingTxHash.String(),
+	}, nil
+}
 
-	err = txMsg.Deserialize(bytes.NewReader(txBytes))
+func (s *StakerService) setUnbondingOverrides(
+	_ *rpctypes.Context,
+	stakingTxHash string,
+	unbondingTime *int64,
+	unbondingFeeRate *int64,
+) (*StakingDetails, error) {
+	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
+
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	var (
+		unbondingTimeBlocks uint16
+		feeRate             btcutil.Amount
+	)
+
+	if unbondingTime != nil {
+		if *unbondingTime < 0 || *unbondingTime > math.MaxUint16 {
+			return nil, invalidParamsf("unbonding time must be non-negative and lower than %d", math.MaxUint16)
+		}
+
+		unbondingTimeBlocks = uint16(*unbondingTime)
+	}
+
+	if unbondingFeeRate != nil {
+		if *unbondingFeeRate < 0 {
+			return nil, invalidParamsf("unbonding fee rate must be non-negative")
+		}
+
+		feeRate = btcutil.Amount(*unbondingFeeRate)
+	}
+
+	if err := s.staker.SetUnbondingOverrides(txHash, unbondingTimeBlocks, feeRate); err != nil {
+		return nil, err
+	}
+
+	storedTx, err := s.staker.GetStoredTransaction(txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	details := storedTxToStakingDetails(storedTx)
+	return &details, nil
+}
+
+func (s *StakerService) setAutoWithdraw(
+	_ *rpctypes.Context,
+	stakingTxHash string,
+	enabled bool,
+) (*StakingDetails, error) {
+	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
+
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	if err := s.staker.SetAutoWithdraw(txHash, enabled); err != nil {
+		return nil, err
+	}
+
+	storedTx, err := s.staker.GetStoredTransaction(txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	details := storedTxToStakingDetails(storedTx)
+	return &details, nil
+}
+
+func (s *StakerService) bumpStakingFee(_ *rpctypes.Context, stakingTxHash string, feeRate *int) (*BumpStakingFeeResponse, error) {
+	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
+
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	var feeRateBtc *btcutil.Amount = nil
+
+	if feeRate != nil {
+		amt := btcutil.Amount(*feeRate)
+		feeRateBtc = &amt
+	}
+
+	result, err := s.staker.BumpStakingTxFee(txHash, feeRateBtc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var parentFee string
+	if result.ParentFee != nil {
+		parentFee = strconv.FormatInt(int64(*result.ParentFee), 10)
+	}
+
+	return &BumpStakingFeeResponse{
+		StakingTxHash:      result.StakingTxHash.String(),
+		ChildTxHash:        result.ChildTxHash.String(),
+		ChildFee:           strconv.FormatInt(int64(result.ChildFee), 10),
+		StakingTxFee:       parentFee,
+		SubmittedAsPackage: result.SubmittedAsPackage,
+	}, nil
+}
+
+func (s *StakerService) unbondAll(_ *rpctypes.Context, intervalMs *int, dryRun *bool) (*UnbondAllResponse, error) {
+	interval := defaultUnbondAllInterval
+
+	if intervalMs != nil {
+		if *intervalMs < 0 || time.Duration(*intervalMs)*time.Millisecond > maxUnbondAllInterval {
+			return nil, invalidParamsf("intervalMs must be between 0 and %d", maxUnbondAllInterval.Milliseconds())
+		}
+		interval = time.Duration(*intervalMs) * time.Millisecond
+	}
+
+	isDryRun := dryRun != nil && *dryRun
+
+	result, err := s.staker.UnbondAll(interval, isDryRun)
+
+	if err != nil {
+		return nil, err
+	}
+
+	toUnbond := make([]string, len(result.ToUnbond))
+	for i, h := range result.ToUnbond {
+		toUnbond[i] = h.String()
+	}
+
+	skipped := make([]string, len(result.Skipped))
+	for i, h := range result.Skipped {
+		skipped[i] = h.String()
+	}
+
+	return &UnbondAllResponse{
+		DryRun:     isDryRun,
+		IntervalMs: strconv.FormatInt(interval.Milliseconds(), 10),
+		ToUnbond:   toUnbond,
+		Skipped:    skipped,
+	}, nil
+}
+
+func (s *StakerService) devUnbondingSigHash(_ *rpctypes.Context, stakingTxHash string) (*UnbondingSigHashResponse, error) {
+	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
+
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	info, err := s.staker.UnbondingTxSigHash(txHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	serializedTx, err := utils.SerializeBtcTransaction(info.UnbondingTx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	covenantPks := make([]string, len(info.CovenantPks))
+	for i, pk := range info.CovenantPks {
+		covenantPks[i] = hex.EncodeToString(schnorr.SerializePubKey(pk))
+	}
+
+	return &UnbondingSigHashResponse{
+		UnbondingTxHex:      hex.EncodeToString(serializedTx),
+		UnbondingTxHash:     info.UnbondingTx.TxHash().String(),
+		UnbondingPathScript: hex.EncodeToString(info.UnbondingPathScript),
+		SigHash:             hex.EncodeToString(info.SigHash),
+		CovenantPks:         covenantPks,
+		CovenantQuorum:      strconv.FormatUint(uint64(info.CovenantQuorum), 10),
+	}, nil
+}
+
+func (s *StakerService) devSubmitCovenantUnbondingSigs(
+	_ *rpctypes.Context,
+	stakingTxHash string,
+	covenantPks []string,
+	covenantSigs []string,
+) (*SubmitCovenantSignaturesResponse, error) {
+	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
+
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	if len(covenantPks) != len(covenantSigs) {
+		return nil, invalidParamsf("number of covenant public keys must match number of signatures")
+	}
+
+	signatures := make([]babylonclient.CovenantSignatureInfo, len(covenantPks))
+
+	for i := range covenantPks {
+		pk, err := decodeBtcPk(covenantPks[i])
+
+		if err != nil {
+			return nil, err
+		}
+
+		sigBytes, err := hex.DecodeString(covenantSigs[i])
+
+		if err != nil {
+			return nil, invalidParams(err)
+		}
+
+		// slashing adaptor signatures and partial signatures are not supported,
+		// only full signatures over unbonding path sighash can be submitted
+		if len(sigBytes) != schnorr.SignatureSize {
+			return nil, invalidParamsf(
+				"covenant signature %d has %d bytes, expected %d byte BIP340 signature over unbonding sighash. Slashing adaptor signatures and partial signatures are not supported",
+				i, len(sigBytes), schnorr.SignatureSize,
+			)
+		}
+
+		sig, err := schnorr.ParseSignature(sigBytes)
+
+		if err != nil {
+			return nil, err
+		}
+
+		signatures[i] = babylonclient.CovenantSignatureInfo{
+			Signature: sig,
+			PubKey:    pk,
+		}
+	}
+
+	if err := s.staker.SubmitCovenantUnbondingSignatures(txHash, signatures); err != nil {
+		return nil, err
+	}
+
+	return &SubmitCovenantSignaturesResponse{
+		StakingTxHash: txHash.String(),
+	}, nil
+}
+
+func (s *StakerService) devSignedUnbondingTx(_ *rpctypes.Context, stakingTxHash string) (*SignedUnbondingTxResponse, error) {
+	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
+
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	signedTx, err := s.staker.SignedUnbondingTx(txHash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	serializedTx, err := utils.SerializeBtcTransaction(signedTx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignedUnbondingTxResponse{
+		UnbondingTxHex:  hex.EncodeToString(serializedTx),
+		UnbondingTxHash: signedTx.TxHash().String(),
+	}, nil
+}
+
+func (s *StakerService) retryQueue(_ *rpctypes.Context) (*RetryQueueResponse, error) {
+	entries, err := s.staker.RetryQueue()
+
+	if err != nil {
+		return nil, err
+	}
+
+	respEntries := make([]RetryQueueEntry, len(entries))
+	for i, e := range entries {
+		respEntries[i] = RetryQueueEntry{
+			Operation:     e.Operation.String(),
+			StakingTxHash: e.StakingTxHash.String(),
+			Attempts:      strconv.FormatUint(uint64(e.Attempts), 10),
+			NextAttempt:   strconv.FormatInt(e.NextAttempt.Unix(), 10),
+			LastError:     e.LastError,
+			CreatedAt:     strconv.FormatInt(e.CreatedAt.Unix(), 10),
+		}
+	}
+
+	return &RetryQueueResponse{
+		Entries:    respEntries,
+		TotalCount: strconv.Itoa(len(respEntries)),
+	}, nil
+}
+
+func (s *StakerService) flushRetryQueue(_ *rpctypes.Context, operation *string) (*FlushRetryQueueResponse, error) {
+	var op *proto.RetryOperation
+
+	if operation != nil && *operation != "" {
+		value, found := proto.RetryOperation_value[*operation]
+
+		if !found {
+			return nil, invalidParamsf("unknown operation: %s", *operation)
+		}
+
+		parsedOp := proto.RetryOperation(value)
+		op = &parsedOp
+	}
+
+	flushed, err := s.staker.FlushRetryQueue(op)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &FlushRetryQueueResponse{
+		FlushedCount: strconv.Itoa(flushed),
+	}, nil
+}
+
+func (s *StakerService) retryBabylon(_ *rpctypes.Context, stakingTxHash string, force *bool) (*RetryBabylonResponse, error) {
+	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	babylonTxHash, err := s.staker.RetryBabylonDelegation(txHash, force != nil && *force)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &RetryBabylonResponse{
+		StakingTxHash: txHash.String(),
+		BabylonTxHash: babylonTxHash,
+	}, nil
+}
+
+// feesForGas returns fees paying for gasLimit at configured babylon gas prices
+func feesForGas(gasPrices string, gasLimit uint64) (sdk.Coins, error) {
+	prices, err := sdk.ParseDecCoins(gasPrices)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid configured gas prices %s: %w", gasPrices, err)
+	}
+
+	fees := make([]sdk.Coin, len(prices))
+
+	for i, price := range prices {
+		fees[i] = sdk.NewCoin(price.Denom, price.Amount.MulInt64(int64(gasLimit)).Ceil().TruncateInt())
+	}
+
+	return sdk.NewCoins(fees...), nil
+}
+
+func (s *StakerService) exportDelegation(
+	_ *rpctypes.Context,
+	stakingTxHash string,
+	gasLimit *int,
+	fees *string,
+	markSubmitted *bool,
+) (*ExportDelegationResponse, error) {
+	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
+	if err != nil {
+		return nil, invalidParams(err)
+	}
+
+	gas := uint64(defaultExportGasLimit)
+	if gasLimit != nil {
+		if *gasLimit <= 0 {
+			return nil, invalidParamsf("gas limit must be positive")
+		}
+		gas = uint64(*gasLimit)
+	}
+
+	var feeCoins sdk.Coins
+	if fees != nil {
+		feeCoins, err = sdk.ParseCoinsNormalized(*fees)
+		if err != nil {
+			return nil, invalidParams(err)
+		}
+	} else {
+		feeCoins, err = feesForGas(s.config.BabylonConfig.GasPrices, gas)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	export, err := s.staker.ExportDelegation(txHash, gas, feeCoins, markSubmitted != nil && *markSubmitted)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &txMsg, nil
+	return &ExportDelegationResponse{
+		StakingTxHash:   txHash.String(),
+		Signer:          export.Signer,
+		Msg:             export.MsgJSON,
+		UnsignedTx:      export.UnsignedTxJSON,
+		MarkedSubmitted: export.MarkedSubmitted,
+	}, nil
 }
 
-func decodeBtcPk(pkHex string) (*btcec.PublicKey, error) {
-	pkBytes, err := hex.DecodeString(pkHex)
+func scheduledOperationToDetail(o *stakerdb.ScheduledOperation) ScheduledOperationDetail {
+	var executeAtTime string
+	if !o.ExecuteAtTime.IsZero() {
+		executeAtTime = strconv.FormatInt(o.ExecuteAtTime.Unix(), 10)
+	}
 
-	if err != nil {
-		return nil, err
+	return ScheduledOperationDetail{
+		Id:              strconv.FormatUint(o.Id, 10),
+		Operation:       o.Operation.String(),
+		StakingTxHash:   o.StakingTxHash.String(),
+		ExecuteAtHeight: strconv.FormatUint(uint64(o.ExecuteAtHeight), 10),
+		ExecuteAtTime:   executeAtTime,
+		Note:            o.Note,
+		CreatedAt:       strconv.FormatInt(o.CreatedAt.Unix(), 10),
+		Attempts:        strconv.FormatUint(uint64(o.Attempts), 10),
+		LastError:       o.LastError,
+		Automatic:       o.Automatic,
 	}
+}
 
-	pk, err := schnorr.ParsePubKey(pkBytes)
+func (s *StakerService) scheduleOperation(
+	_ *rpctypes.Context,
+	operation string,
+	stakingTxHash string,
+	executeAtHeight *int,
+	executeAtTime *int64,
+	note *string,
+) (*ScheduledOperationDetail, error) {
+	value, found := proto.ScheduledOperationType_value[operation]
+
+	if !found {
+		return nil, invalidParamsf("unknown operation: %s", operation)
+	}
 
+	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
 	if err != nil {
-		return nil, err
+		return nil, invalidParams(err)
 	}
 
-	return pk, nil
-}
+	var height uint32
+	if executeAtHeight != nil {
+		if *executeAtHeight <= 0 || int64(*executeAtHeight) > math.MaxUint32 {
+			return nil, invalidParamsf("invalid execution height: %d", *executeAtHeight)
+		}
+		height = uint32(*executeAtHeight)
+	}
 
-func parseTimeBtcLock(timelockTime int) (uint16, error) {
-	if timelockTime <= 0 {
-		return 0, fmt.Errorf("staking time must be positive")
+	var at time.Time
+	if executeAtTime != nil {
+		if *executeAtTime <= 0 {
+			return nil, invalidParamsf("invalid execution time: %d", *executeAtTime)
+		}
+		at = time.Unix(*executeAtTime, 0)
 	}
 
-	if timelockTime > math.MaxUint16 {
-		return 0, fmt.Errorf("staking time %d is too big", timelockTime)
+	var opNote string
+	if note != nil {
+		opNote = *note
 	}
 
-	return uint16(timelockTime), nil
-}
+	scheduled, err := s.staker.ScheduleOperation(
+		proto.ScheduledOperationType(value),
+		txHash,
+		height,
+		at,
+		opNote,
+	)
 
-func parseStakingValue(stakingValue int) (btcutil.Amount, error) {
-	if stakingValue <= 0 {
-		return 0, fmt.Errorf("staking value must be positive")
+	if err != nil {
+		return nil, err
 	}
 
-	return btcutil.Amount(stakingValue), nil
+	detail := scheduledOperationToDetail(scheduled)
+	return &detail, nil
 }
 
-func (s *StakerService) watchStaking(
-	_ *rpctypes.Context,
-	stakingTx string,
-	stakingTime int,
-	stakingValue int,
-	stakerBtcPk string,
-	fpBtcPks []string,
-	slashingTx string,
-	slashingTxSig string,
-	stakerBabylonPk string,
-	stakerAddress string,
-	stakerBabylonSig string,
-	stakerBtcSig string,
-	unbondingTx string,
-	slashUnbondingTx string,
-	slashUnbondingTxSig string,
-	unbondingTime int,
-	popType int,
-) (*ResultStake, error) {
+func (s *StakerService) cancelScheduledOperation(_ *rpctypes.Context, id string) (*CancelScheduledOperationResponse, error) {
+	parsedId, err := strconv.ParseUint(id, 10, 64)
 
-	stkTx, err := decodeBtcTx(stakingTx)
 	if err != nil {
-		return nil, err
+		return nil, invalidParamsf("invalid operation id: %s", id)
 	}
 
-	slshTx, err := decodeBtcTx(slashingTx)
-	if err != nil {
+	if err := s.staker.CancelScheduledOperation(parsedId); err != nil {
 		return nil, err
 	}
 
-	stakerBtcPkParsed, err := decodeBtcPk(stakerBtcPk)
+	return &CancelScheduledOperationResponse{
+		Id: id,
+	}, nil
+}
+
+func (s *StakerService) scheduledOperations(_ *rpctypes.Context) (*ScheduledOperationsResponse, error) {
+	operations, err := s.staker.ScheduledOperations()
 
 	if err != nil {
 		return nil, err
 	}
 
-	var fpPubKeys []*btcec.PublicKey = make([]*btcec.PublicKey, 0)
-
-	for _, fpPk := range fpBtcPks {
-		fpPkBytes, err := hex.DecodeString(fpPk)
-		if err != nil {
-			return nil, err
-		}
-
-		fpSchnorrKey, err := schnorr.ParsePubKey(fpPkBytes)
-		if err != nil {
-			return nil, err
-		}
-
-		fpPubKeys = append(fpPubKeys, fpSchnorrKey)
+	details := make([]ScheduledOperationDetail, len(operations))
+	for i := range operations {
+		details[i] = scheduledOperationToDetail(&operations[i])
 	}
 
-	stakingTimeUint16, err := parseTimeBtcLock(stakingTime)
-
-	if err != nil {
-		return nil, err
-	}
+	return &ScheduledOperationsResponse{
+		Operations: details,
+	}, nil
+}
 
-	stakingValueBtc, err := parseStakingValue(stakingValue)
+func parseTransactionState(state string) (proto.TransactionState, error) {
+	value, err := types.ParseDelegationState(state)
 
 	if err != nil {
-		return nil, err
+		return 0, invalidParams(err)
 	}
 
-	stakerAddr, err := btcutil.DecodeAddress(stakerAddress, &s.config.ActiveNetParams)
-	if err != nil {
-		return nil, err
-	}
+	return value, nil
+}
 
-	slashTxSigBytes, err := hex.DecodeString(slashingTxSig)
+func (s *StakerService) overrideDelegationState(
+	ctx *rpctypes.Context,
+	stakingTxHash string,
+	fromState string,
+	toState string,
+	reason string,
+) (*OverrideDelegationStateResponse, error) {
+	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
 	if err != nil {
-		return nil, err
+		return nil, invalidParams(err)
 	}
 
-	slashingTxSchnorSig, err := schnorr.ParseSignature(slashTxSigBytes)
+	from, err := parseTransactionState(fromState)
 	if err != nil {
 		return nil, err
 	}
 
-	stakerBabylonPubkeyBytes, err := hex.DecodeString(stakerBabylonPk)
+	to, err := parseTransactionState(toState)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(stakerBabylonPubkeyBytes) != secp256k1.PubKeySize {
-		return nil, fmt.Errorf("babylon public key must have %d bytes", secp256k1.PubKeySize)
-	}
+	reason = strings.TrimSpace(reason)
 
-	stakerBabylonPubKey := secp256k1.PubKey{
-		Key: stakerBabylonPubkeyBytes,
+	if len(reason) == 0 || len(reason) > maxOverrideReasonLength {
+		return nil, invalidParamsf("reason must have between 1 and %d characters", maxOverrideReasonLength)
 	}
 
-	stakerBabylonSigBytes, err := hex.DecodeString(stakerBabylonSig)
+	requestId, err := resolveRequestId(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	stakerBtcSigBytes, err := hex.DecodeString(stakerBtcSig)
-	if err != nil {
-		return nil, err
-	}
+	err = s.staker.OverrideDelegationState(&str.StateOverrideRequest{
+		StakingTxHash: *txHash,
+		From:          from,
+		To:            to,
+		Reason:        reason,
+		RequestId:     requestId,
+		RemoteAddr:    ctx.RemoteAddr(),
+	})
 
-	btcPopType, err := babylonclient.IntToPopType(popType)
 	if err != nil {
 		return nil, err
 	}
 
-	proofOfPossesion, err := babylonclient.NewBabylonPop(btcPopType, stakerBabylonSigBytes, stakerBtcSigBytes)
+	return &OverrideDelegationStateResponse{
+		StakingTxHash: txHash.String(),
+		StakingState:  stateName(to),
+	}, nil
+}
+
+func (s *StakerService) annotateDelegation(
+	ctx *rpctypes.Context,
+	stakingTxHash string,
+	note string,
+) (*AnnotateDelegationResponse, error) {
+	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
 	if err != nil {
-		return nil, err
+		return nil, invalidParams(err)
 	}
 
-	// Unbonding related data
-	unbTx, err := decodeBtcTx(unbondingTx)
-	if err != nil {
-		return nil, err
+	note = strings.TrimSpace(note)
+
+	if len(note) == 0 || len(note) > maxOverrideReasonLength {
+		return nil, invalidParamsf("note must have between 1 and %d characters", maxOverrideReasonLength)
 	}
 
-	slshUnbTx, err := decodeBtcTx(slashUnbondingTx)
+	requestId, err := resolveRequestId(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	slashUnbTxSigBytes, err := hex.DecodeString(slashUnbondingTxSig)
+	storedNote, err := s.staker.AnnotateDelegation(&str.AnnotationRequest{
+		StakingTxHash: *txHash,
+		Note:          note,
+		RequestId:     requestId,
+		RemoteAddr:    ctx.RemoteAddr(),
+	})
+
 	if err != nil {
 		return nil, err
 	}
 
-	slashUnbTxSig, err := schnorr.ParseSignature(slashUnbTxSigBytes)
+	return &AnnotateDelegationResponse{
+		StakingTxHash: txHash.String(),
+		Note:          delegationNoteResponse(storedNote),
+	}, nil
+}
+
+func (s *StakerService) purgeDelegation(
+	ctx *rpctypes.Context,
+	stakingTxHash string,
+	reason string,
+) (*PurgeDelegationResponse, error) {
+	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
 	if err != nil {
-		return nil, err
+		return nil, invalidParams(err)
 	}
 
-	unbTime, err := parseTimeBtcLock(unbondingTime)
+	reason = strings.TrimSpace(reason)
 
-	if err != nil {
-		return nil, err
+	if len(reason) == 0 || len(reason) > maxOverrideReasonLength {
+		return nil, invalidParamsf("reason must have between 1 and %d characters", maxOverrideReasonLength)
 	}
 
-	hash, err := s.staker.WatchStaking(
-		stkTx,
-		stakingTimeUint16,
-		stakingValueBtc,
-		fpPubKeys,
-		slshTx,
-		slashingTxSchnorSig,
-		&stakerBabylonPubKey,
-		stakerBtcPkParsed,
-		stakerAddr,
-		proofOfPossesion,
-		unbTx,
-		slshUnbTx,
-		slashUnbTxSig,
-		unbTime,
-	)
+	requestId, err := resolveRequestId(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	return &ResultStake{
-		TxHash: hash.String(),
-	}, nil
-}
-
-func (s *StakerService) unbondStaking(_ *rpctypes.Context, stakingTxHash string, feeRate *int) (*UnbondingResponse, error) {
-	txHash, err := chainhash.NewHashFromStr(stakingTxHash)
+	err = s.staker.PurgeDelegation(&str.PurgeRequest{
+		StakingTxHash: *txHash,
+		Reason:        reason,
+		RequestId:     requestId,
+		RemoteAddr:    ctx.RemoteAddr(),
+	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	var feeRateBtc *btcutil.Amount = nil
+	return &PurgeDelegationResponse{
+		StakingTxHash: txHash.String(),
+	}, nil
+}
 
-	if feeRate != nil {
-		amt := btcutil.Amount(*feeRate)
-		feeRateBtc = &amt
+func (s *StakerService) auditLog(_ *rpctypes.Context, stakingTxHash *string) (*AuditLogResponse, error) {
+	var txHash *chainhash.Hash
+
+	if stakingTxHash != nil && *stakingTxHash != "" {
+		hash, err := chainhash.NewHashFromStr(*stakingTxHash)
+		if err != nil {
+			return nil, invalidParams(err)
+		}
+		txHash = hash
 	}
 
-	unbondingTxHash, err := s.staker.UnbondStaking(*txHash, feeRateBtc)
+	entries, err := s.staker.AuditLog(txHash)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &UnbondingResponse{
-		UnbondingTxHash: unbondingTxHash.String(),
+	respEntries := make([]AuditLogEntry, len(entries))
+	for i, e := range entries {
+		respEntries[i] = AuditLogEntry{
+			Timestamp:     strconv.FormatInt(e.Timestamp.Unix(), 10),
+			Action:        e.Action,
+			StakingTxHash: e.StakingTxHash.String(),
+			PreviousState: stateName(e.PreviousState),
+			NewState:      stateName(e.NewState),
+			Reason:        e.Reason,
+			RequestId:     e.RequestId,
+			RemoteAddr:    e.RemoteAddr,
+		}
+	}
+
+	return &AuditLogResponse{
+		Entries: respEntries,
 	}, nil
 }
 
 func (s *StakerService) GetRoutes() RoutesMap {
-	return RoutesMap{
+	routes := RoutesMap{
 		// info AP
-		"health": rpc.NewRPCFunc(s.health, ""),
+		"health":            s.newRPCFunc(s.health, ""),
+		"delegation_states": s.newRPCFunc(s.delegationStates, ""),
 		// staking API
-		"stake":                     rpc.NewRPCFunc(s.stake, "stakerAddress,stakingAmount,fpBtcPks,stakingTimeBlocks"),
-		"staking_details":           rpc.NewRPCFunc(s.stakingDetails, "stakingTxHash"),
-		"spend_stake":               rpc.NewRPCFunc(s.spendStake, "stakingTxHash"),
-		"list_staking_transactions": rpc.NewRPCFunc(s.listStakingTransactions, "offset,limit"),
-		"unbond_staking":            rpc.NewRPCFunc(s.unbondStaking, "stakingTxHash,feeRate"),
-		"withdrawable_transactions": rpc.NewRPCFunc(s.withdrawableTransactions, "offset,limit"),
+		"stake":                     s.newRPCFunc(s.stake, "stakerAddress,stakingAmount,fpBtcPks,stakingTimeBlocks,metadata,requestId,preset,minConfirmations,autoWithdraw"),
+		"stake_external":            s.newRPCFunc(s.stakeExternal, "fundingAddress,stakerPk,stakingAmount,fpBtcPks,stakingTimeBlocks,metadata,requestId,minConfirmations"),
+		"staking_details":           s.newRPCFunc(s.stakingDetails, "stakingTxHash"),
+		"staking_details_batch":     s.newRPCFunc(s.stakingDetailsBatch, "stakingTxHashes"),
+		"staking_script_info":       s.newRPCFunc(s.stakingScriptInfo, "stakingTxHash"),
+		"delegation_graph":          s.newRPCFunc(s.delegationGraph, "stakingTxHash"),
+		"exit_templates":            s.newRPCFunc(s.exitTemplates, "stakingTxHash"),
+		"compute_sighashes":         s.newRPCFunc(s.computeSigHashes, "tx,stakingTxHash"),
+		"spend_stake":               s.newRPCFunc(s.spendStake, "stakingTxHash"),
+		"list_staking_transactions": s.newRPCFunc(s.listStakingTransactions, "offset,limit,metadataFilter,group,createdAfter,createdBefore,transitionState,transitionAfter,transitionBefore"),
+		"staking_status_light":      s.newRPCFunc(s.stakingStatusLight, "offset,limit,ifNoneMatch"),
+		"unbond_staking":            s.newRPCFunc(s.unbondStaking, "stakingTxHash,feeRate,destinationAddress"),
+		"set_unbonding_overrides":   s.newRPCFunc(s.setUnbondingOverrides, "stakingTxHash,unbondingTime,unbondingFeeRate"),
+		"set_auto_withdraw":         s.newRPCFunc(s.setAutoWithdraw, "stakingTxHash,enabled"),
+		"unbond_all":                s.newRPCFunc(s.unbondAll, "intervalMs,dryRun"),
+		"bump_staking_fee":          s.newRPCFunc(s.bumpStakingFee, "stakingTxHash,feeRate"),
+		"withdrawable_transactions": s.newRPCFunc(s.withdrawableTransactions, "offset,limit"),
+		"staking_report":            s.newRPCFunc(s.stakingReport, "from,to"),
+		"staking_summary":           s.newRPCFunc(s.stakingSummary, "group"),
+		"group_summaries":           s.newRPCFunc(s.groupSummaries, ""),
+		"set_delegation_group":      s.newRPCFunc(s.setDelegationGroup, "stakingTxHashes,group"),
+		"prove_ownership":           s.newRPCFunc(s.proveOwnership, "stakingTxHash,challenge"),
+		"verify_ownership_proof":    s.newRPCFunc(s.verifyOwnershipProof, "stakingTxHash,stakerPk,challenge,signature"),
+		"sign_message":              s.newRPCFunc(s.signMessage, "stakerAddress,message"),
+		"proof_of_reserves":         s.newRPCFunc(s.proofOfReserves, "message"),
+		"verify_message":            s.newRPCFunc(s.verifyMessage, "address,message,signature"),
+		"generate_musig2_nonce":     s.newRPCFunc(s.generateMuSig2Nonce, "sessionId,signerAddress,message,ttlSeconds"),
+		"musig2_nonce":              s.newRPCFunc(s.musig2Nonce, "sessionId"),
+		"staking_presets":           s.newRPCFunc(s.stakingPresets, ""),
+		"set_staking_preset":        s.newRPCFunc(s.setStakingPreset, "name,fpBtcPks,stakingTimeBlocks,amountTiers,maxFeeRate"),
+		"delete_staking_preset":     s.newRPCFunc(s.deleteStakingPreset, "name"),
 		// watch api
-		"watch_staking_tx": rpc.NewRPCFunc(s.watchStaking, "stakingTx,stakingTime,stakingValue,stakerBtcPk,fpBtcPks,slashingTx,slashingTxSig,stakerBabylonPk,stakerAddress,stakerBabylonSig,stakerBtcSig,unbondingTx,slashUnbondingTx,slashUnbondingTxSig,unbondingTime,popType"),
+		"watch_staking_tx": s.newRPCFunc(s.watchStaking, "stakingTx,stakingTime,stakingValue,stakerBtcPk,fpBtcPks,slashingTx,slashingTxSig,stakerBabylonPk,stakerAddress,stakerBabylonSig,stakerBtcSig,unbondingTx,slashUnbondingTx,slashUnbondingTxSig,unbondingTime,popType,metadata"),
+
+		// Search api
+		"search_staking_transactions": s.newRPCFunc(s.searchStakingTransactions, "query,minAmount,maxAmount,offset,limit,createdAfter,createdBefore,transitionState,transitionAfter,transitionBefore"),
 
 		// Wallet api
-		"list_outputs": rpc.NewRPCFunc(s.listOutputs, ""),
+		"list_outputs":          s.newRPCFunc(s.listOutputs, ""),
+		"consolidate_outputs":   s.newRPCFunc(s.consolidateOutputs, "destinationAddress,maxUtxoValue,feeRate"),
+		"fee_estimate":          s.newRPCFunc(s.feeEstimate, ""),
+		"fee_budget":            s.newRPCFunc(s.feeBudget, ""),
+		"fee_analytics":         s.newRPCFunc(s.feeAnalytics, "days"),
+		"confirmation_sla":      s.newRPCFunc(s.confirmationSla, "days"),
+		"utxo_blocklist":        s.newRPCFunc(s.utxoBlocklist, ""),
+		"utxo_blocklist_add":    s.newRPCFunc(s.utxoBlocklistAdd, "outpoints,addresses,reason"),
+		"utxo_blocklist_remove": s.newRPCFunc(s.utxoBlocklistRemove, "outpoints,addresses"),
+		"frozen_outputs":        s.newRPCFunc(s.frozenOutputs, ""),
+		"freeze_output":         s.newRPCFunc(s.freezeOutput, "outpoint,note"),
+		"unfreeze_output":       s.newRPCFunc(s.unfreezeOutput, "outpoint"),
+		"wallet_transactions":   s.newRPCFunc(s.walletTransactions, "label,limit"),
 
 		// Babylon api
-		"babylon_finality_providers": rpc.NewRPCFunc(s.providers, "offset,limit"),
+		"babylon_finality_providers": s.newRPCFunc(s.providers, "offset,limit"),
+		"babylon_staking_params":     s.newRPCFunc(s.babylonStakingParams, ""),
+		"babylon_rewards":            s.newRPCFunc(s.babylonRewards, ""),
+		"withdraw_babylon_rewards":   s.newRPCFunc(s.withdrawBabylonRewards, "stakeholderType,recipient"),
+
+		// Admin api
+		"retry_queue":               s.newRPCFunc(s.retryQueue, ""),
+		"flush_retry_queue":         s.newRPCFunc(s.flushRetryQueue, "operation"),
+		"retry_babylon":             s.newRPCFunc(s.retryBabylon, "stakingTxHash,force"),
+		"export_delegation":         s.newRPCFunc(s.exportDelegation, "stakingTxHash,gasLimit,fees,markSubmitted"),
+		"override_delegation_state": s.newRPCFunc(s.overrideDelegationState, "stakingTxHash,fromState,toState,reason"),
+		"purge_delegation":          s.newRPCFunc(s.purgeDelegation, "stakingTxHash,reason"),
+		"annotate_delegation":       s.newRPCFunc(s.annotateDelegation, "stakingTxHash,note"),
+		"audit_log":                 s.newRPCFunc(s.auditLog, "stakingTxHash"),
+
+		// Scheduled operations api
+		"schedule_operation":         s.newRPCFunc(s.scheduleOperation, "operation,stakingTxHash,executeAtHeight,executeAtTime,note"),
+		"cancel_scheduled_operation": s.newRPCFunc(s.cancelScheduledOperation, "id"),
+		"scheduled_operations":       s.newRPCFunc(s.scheduledOperations, ""),
+
+		// Two person approval api
+		"pending_actions": s.newRPCFunc(s.pendingActions, ""),
+		"approve_action":  s.newRPCFunc(s.approveAction, "actionId"),
+		"reject_action":   s.newRPCFunc(s.rejectAction, "actionId"),
+	}
+
+	if s.config.StakerConfig.EnableDevApi {
+		// developer api, enables running own covenant committee against this daemon.
+		// Only unbonding path signatures are supported, see staker/devapi.go
+		routes["dev_unbonding_sighash"] = s.newRPCFunc(s.devUnbondingSigHash, "stakingTxHash")
+		routes["dev_submit_covenant_unbonding_sigs"] = s.newRPCFunc(s.devSubmitCovenantUnbondingSigs, "stakingTxHash,covenantPks,covenantSigs")
+		routes["dev_signed_unbonding_tx"] = s.newRPCFunc(s.devSignedUnbondingTx, "stakingTxHash")
+	}
+
+	return routes
+}
+
+// serveRoutes starts json rpc http server serving given routes on every listener.
+// Returned function closes all listeners.
+func serveRoutes(
+	routes RoutesMap,
+	streams StreamHandlers,
+	signer *responseSigner,
+	acl *rpcAcl,
+	approvals *approvalQueue,
+	verifier *requestVerifier,
+	masker *responseMasker,
+	accessLog *accessLogger,
+	identities *identityResolver,
+	rpcListeners []net.Addr,
+	logger *logrus.Logger,
+) (func(), error) {
+	// TODO: Add staker service dedicated config to define those values
+	config := rpc.DefaultConfig()
+	// This way logger will log to stdout and file
+	// TODO: investigate if we can use logrus directly to pass it to rpcserver
+	rpcLogger := log.NewTMLogger(logger.Writer())
+
+	if approvals != nil {
+		executor := http.NewServeMux()
+		rpc.RegisterRPCFuncs(executor, routes, rpcLogger)
+		approvals.executor = executor
+	}
+
+	listeners := make([]net.Listener, 0, len(rpcListeners))
+	closeListeners := func() {
+		for _, listener := range listeners {
+			err := listener.Close()
+			if err != nil {
+				logger.Error("Error closing listener", "err", err)
+			}
+		}
+	}
+
+	for _, listenAddr := range rpcListeners {
+		listenAddressStr := listenAddr.Network() + "://" + listenAddr.String()
+		mux := http.NewServeMux()
+		rpc.RegisterRPCFuncs(mux, routes, rpcLogger)
+
+		listener, err := rpc.Listen(
+			listenAddressStr,
+			config.MaxOpenConnections,
+		)
+
+		if err != nil {
+			closeListeners()
+			return nil, fmt.Errorf("unable to listen on %s: %v",
+				listenAddressStr, err)
+		}
+
+		// Start standard HTTP server serving json-rpc
+		// TODO: Add additional middleware, like CORS, TLS, etc.
+		// TODO: Consider we need some websockets for some notications
+		go func() {
+			logger.Debug("Starting Json RPC HTTP server ", "address", listenAddressStr)
+
+			err := serveHTTP(
+				listener,
+				mux,
+				streams,
+				signer,
+				acl,
+				approvals,
+				verifier,
+				masker,
+				accessLog,
+				identities,
+				rpcLogger,
+				config,
+			)
+
+			logger.Error("Json RPC HTTP server stopped ", "err", err)
+		}()
+
+		listeners = append(listeners, listener)
 	}
+
+	return closeListeners, nil
 }
 
 func (s *StakerService) RunUntilShutdown() error {
@@ -603,62 +3035,76 @@ func (s *StakerService) RunUntilShutdown() error {
 		s.logger.Info("staker stop complete")
 	}()
 
+	signer, err := loadResponseSigner(s.config.ResponseSigningConfig)
+	if err != nil {
+		return mkErr("error loading response signing key: %w", err)
+	}
+
+	if signer != nil {
+		s.logger.WithField("publicKey", signer.PublicKeyHex()).Info("Signing json rpc responses")
+	}
+
 	routes := s.GetRoutes()
-	// TODO: Add staker service dedicated config to define those values
-	config := rpc.DefaultConfig()
-	// This way logger will log to stdout and file
-	// TODO: investigate if we can use logrus directly to pass it to rpcserver
-	rpcLogger := log.NewTMLogger(s.logger.Writer())
+	streams := s.GetStreamHandlers()
 
-	listeners := make([]net.Listener, len(s.config.RpcListeners))
-	for i, listenAddr := range s.config.RpcListeners {
-		listenAddressStr := listenAddr.Network() + "://" + listenAddr.String()
-		mux := http.NewServeMux()
-		rpc.RegisterRPCFuncs(mux, routes, rpcLogger)
+	acl, err := newRpcAcl(s.config.RpcAclConfig, routes, streams, s.logger)
+	if err != nil {
+		return mkErr("error creating rpc acl: %w", err)
+	}
 
-		listener, err := rpc.Listen(
-			listenAddressStr,
-			config.MaxOpenConnections,
-		)
+	identities, err := newIdentityResolver(s.config)
+	if err != nil {
+		return mkErr("error reading rpc tokens: %w", err)
+	}
 
-		if err != nil {
-			return mkErr("unable to listen on %s: %v",
-				listenAddressStr, err)
-		}
+	approvals := newApprovalQueue(s.config.ApprovalConfig, s.logger)
+	s.approvals = approvals
 
-		defer func() {
-			err := listener.Close()
-			if err != nil {
-				s.logger.Error("Error closing listener", "err", err)
-			}
-		}()
+	verifier, err := newRequestVerifier(s.config.RequestSigningConfig)
+	if err != nil {
+		return mkErr("error creating request verifier: %w", err)
+	}
 
-		// Start standard HTTP server serving json-rpc
-		// TODO: Add additional middleware, like CORS, TLS, etc.
-		// TODO: Consider we need some websockets for some notications
-		go func() {
-			s.logger.Debug("Starting Json RPC HTTP server ", "address", listenAddressStr)
+	quotas, err := newStakeQuotas(s.config.QuotaConfig, s.staker.Metrics())
+	if err != nil {
+		return mkErr("error creating stake quotas: %w", err)
+	}
 
-			err := rpc.Serve(
-				listener,
-				mux,
-				rpcLogger,
-				config,
-			)
+	s.quotas = quotas
 
-			s.logger.Error("Json RPC HTTP server stopped ", "err", err)
-		}()
+	masker, err := newResponseMasker(s.config.ResponseMaskingConfig)
+	if err != nil {
+		return mkErr("error creating response masker: %w", err)
+	}
 
-		listeners[i] = listener
+	accessLog := newAccessLogger(s.config, routes, s.staker.Metrics().RpcSlowRequests, s.logger)
+
+	closeListeners, err := serveRoutes(routes, streams, signer, acl, approvals, verifier, masker, accessLog, identities, s.config.RpcListeners, s.logger)
+	if err != nil {
+		return mkErr("error starting rpc server: %w", err)
+	}
+
+	defer closeListeners()
+
+	closeDebug, err := serveDebug(s.config.DebugConfig, s.staker, s.logger)
+	if err != nil {
+		return mkErr("error starting debug server: %w", err)
 	}
 
+	defer closeDebug()
+
+	stopWatchdog := startSystemdWatchdog(s.config.SystemdConfig, s.staker.PingEventLoop, s.logger)
+	defer stopWatchdog()
+
 	s.logger.Info("Staker Service fully started")
+	notifySystemd(s.logger, sdNotifyReady)
 
 	// Wait for shutdown signal from either a graceful service stop or from
 	// the interrupt handler.
 	<-s.interceptor.ShutdownChannel()
 
 	s.logger.Info("Received shutdown signal. Stopping...")
+	notifySystemd(s.logger, sdNotifyStopping)
 
 	return nil
 }
@@ -0,0 +1,183 @@
+package stakerservice
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+const (
+	// ResponseSignatureHeader carries hex encoded BIP340 signature of the json
+	// rpc response
+	ResponseSignatureHeader = "X-Response-Signature"
+)
+
+type responseSigner struct {
+	key *btcec.PrivateKey
+}
+
+// loadResponseSigner returns nil if response signing is disabled. If key file
+// does not exist, new key is generated and saved to it.
+func loadResponseSigner(cfg *scfg.ResponseSigningConfig) (*responseSigner, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	keyHex, err := os.ReadFile(cfg.KeyFile)
+
+	if os.IsNotExist(err) {
+		key, err := btcec.NewPrivateKey()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(cfg.KeyFile), 0700); err != nil {
+			return nil, err
+		}
+
+		if err := os.WriteFile(cfg.KeyFile, []byte(hex.EncodeToString(key.Serialize())), 0600); err != nil {
+			return nil, fmt.Errorf("failed to save response signing key: %w", err)
+		}
+
+		return &responseSigner{key: key}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response signing key: %w", err)
+	}
+
+	keyBytes, err := hex.DecodeString(strings.TrimSpace(string(keyHex)))
+
+	if err != nil || len(keyBytes) != btcec.PrivKeyBytesLen {
+		return nil, fmt.Errorf("response signing key file %s must contain hex encoded 32 byte private key", cfg.KeyFile)
+	}
+
+	key, _ := btcec.PrivKeyFromBytes(keyBytes)
+
+	return &responseSigner{key: key}, nil
+}
+
+// PublicKeyHex returns BIP340 public key which verifies response signatures
+func (s *responseSigner) PublicKeyHex() string {
+	return hex.EncodeToString(schnorr.SerializePubKey(s.key.PubKey()))
+}
+
+// responseSigHash binds the signature to the request id, so that signed response
+// can't be replayed as response to a different request
+func responseSigHash(requestId string, body []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(requestId))
+	h.Write([]byte{'\n'})
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+func (s *responseSigner) sign(requestId string, body []byte) (string, error) {
+	sig, err := schnorr.Sign(s.key, responseSigHash(requestId, body))
+
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(sig.Serialize()), nil
+}
+
+// VerifyResponseSignature verifies signature from ResponseSignatureHeader of
+// the response with given request id and body against hex encoded BIP340 public
+// key of the daemon
+func VerifyResponseSignature(pubKeyHex string, requestId string, body []byte, sigHex string) error {
+	pubKeyBytes, err := hex.DecodeString(pubKeyHex)
+
+	if err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+
+	pubKey, err := schnorr.ParsePubKey(pubKeyBytes)
+
+	if err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+
+	sigBytes, err := hex.DecodeString(sigHex)
+
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	sig, err := schnorr.ParseSignature(sigBytes)
+
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	if !sig.Verify(responseSigHash(requestId, body), pubKey) {
+		return fmt.Errorf("response signature does not match")
+	}
+
+	return nil
+}
+
+type bufferedResponseWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponseWriter) WriteHeader(statusCode int) {
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+// withResponseSignature buffers whole response of h and signs it. Json rpc
+// responses are small, so buffering them is cheap.
+func withResponseSignature(h http.Handler, signer *responseSigner) http.Handler {
+	if signer == nil {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buffered := &bufferedResponseWriter{header: w.Header()}
+
+		h.ServeHTTP(buffered, r)
+
+		if buffered.statusCode == 0 {
+			buffered.statusCode = http.StatusOK
+		}
+
+		body := buffered.body.Bytes()
+
+		// request id is always set by withRequestId middleware
+		sig, err := signer.sign(r.Header.Get(RequestIdHeader), body)
+
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to sign response: %s", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set(ResponseSignatureHeader, sig)
+		w.WriteHeader(buffered.statusCode)
+		_, _ = w.Write(body)
+	})
+}
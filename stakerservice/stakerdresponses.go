@@ -1,22 +1,108 @@
 package stakerservice
 
-type ResultHealth struct{}
+import (
+	"encoding/json"
+
+	"github.com/babylonchain/btc-staker/monitor"
+)
+
+type ResultHealth struct {
+	// nil if balance monitoring is disabled or balance was not checked yet
+	BabylonBalance *BabylonBalanceResponse `json:"babylon_balance,omitempty"`
+	BtcLightClient *BtcLightClientResponse `json:"btc_light_client,omitempty"`
+}
+
+type DelegationStateResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// DelegationStatesResponse lists canonical delegation states in order of the
+// delegation lifecycle
+type DelegationStatesResponse struct {
+	Version string                    `json:"version"`
+	States  []DelegationStateResponse `json:"states"`
+}
+
+type BtcLightClientResponse struct {
+	BtcHeight         string `json:"btc_height"`
+	LightClientHeight string `json:"light_client_height"`
+	Lag               string `json:"lag"`
+	Lagging           bool   `json:"lagging"`
+	CheckedAt         string `json:"checked_at"`
+}
+
+type BabylonBalanceResponse struct {
+	Balance    string `json:"balance"`
+	Denom      string `json:"denom"`
+	MinBalance string `json:"min_balance"`
+	Low        bool   `json:"low"`
+	CheckedAt  string `json:"checked_at"`
+}
 
 type ResultStake struct {
-	TxHash string `json:"tx_hash"`
+	TxHash    string `json:"tx_hash"`
+	RequestId string `json:"request_id"`
+	// set if funds were staked, but automatic withdrawal could not be enabled
+	Warning string `json:"warning,omitempty"`
+}
+
+type ResultStakeExternal struct {
+	TxHash    string `json:"tx_hash"`
+	StakerPk  string `json:"staker_pk"`
+	Warning   string `json:"warning"`
+	RequestId string `json:"request_id"`
 }
 
 type StakingDetails struct {
-	StakingTxHash  string `json:"staking_tx_hash"`
-	StakerAddress  string `json:"staker_address"`
-	StakingState   string `json:"staking_state"`
-	Watched        bool   `json:"watched"`
-	TransactionIdx string `json:"transaction_idx"`
+	StakingTxHash  string            `json:"staking_tx_hash"`
+	StakerAddress  string            `json:"staker_address"`
+	StakingState   string            `json:"staking_state"`
+	Watched        bool              `json:"watched"`
+	TransactionIdx string            `json:"transaction_idx"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	// Set only for delegations funded on behalf of external staker key
+	ExternalStakerPk string `json:"external_staker_pk,omitempty"`
+	// Id of the rpc request which created the delegation
+	RequestId string `json:"request_id,omitempty"`
+	// Group (portfolio) to which delegation is assigned
+	Group string `json:"group,omitempty"`
+	// Set only if operator overrode unbonding time or fee rate (sat/kvbyte) of
+	// the delegation
+	UnbondingTimeOverride    string `json:"unbonding_time_override,omitempty"`
+	UnbondingFeeRateOverride string `json:"unbonding_fee_rate_override,omitempty"`
+	// Address receiving withdrawn funds, if other than staker address
+	WithdrawalAddress string `json:"withdrawal_address,omitempty"`
+	// Responses of babylon to transactions sent for the delegation, oldest first
+	BabylonTxResponses []BabylonTxResponse `json:"babylon_tx_responses,omitempty"`
+	// Notes attached to the delegation by the operator, oldest first
+	Notes []DelegationNote `json:"notes,omitempty"`
+	// Staked funds are withdrawn automatically once timelock expires
+	AutoWithdraw bool `json:"auto_withdraw,omitempty"`
+}
+
+type BabylonTxResponse struct {
+	MsgType   string `json:"msg_type"`
+	TxHash    string `json:"tx_hash"`
+	Height    string `json:"height"`
+	Codespace string `json:"codespace,omitempty"`
+	Code      string `json:"code"`
+	Timestamp string `json:"timestamp"`
+}
+
+type DelegationNote struct {
+	// unix timestamp (seconds)
+	Timestamp string `json:"timestamp"`
+	Text      string `json:"text"`
+	RequestId string `json:"request_id,omitempty"`
 }
 
 type OutputDetail struct {
-	Amount  string `json:"amount"`
-	Address string `json:"address"`
+	Amount   string `json:"amount"`
+	Address  string `json:"address"`
+	Outpoint string `json:"outpoint"`
+	// frozen outputs are not used to fund transactions
+	Frozen bool `json:"frozen"`
 }
 
 type OutputsResponse struct {
@@ -44,12 +130,543 @@ type ListStakingTransactionsResponse struct {
 	TotalTransactionCount string           `json:"total_transaction_count"`
 }
 
+// StakingStatusLight is trimmed down status of delegation for wallets
+// polling over constrained links. BlocksToMaturity is empty if delegation has
+// no confirmed timelocked output.
+type StakingStatusLight struct {
+	StakingTxHash       string   `json:"staking_tx_hash"`
+	StakingState        string   `json:"state"`
+	StakingAmount       string   `json:"amount"`
+	FinalityProviderPks []string `json:"fp_btc_pks"`
+	BlocksToMaturity    string   `json:"blocks_to_maturity,omitempty"`
+}
+
+// StakingStatusLightResponse omits delegations if caller already knows status
+// with given etag
+type StakingStatusLightResponse struct {
+	Etag                  string               `json:"etag"`
+	NotModified           bool                 `json:"not_modified,omitempty"`
+	Delegations           []StakingStatusLight `json:"delegations,omitempty"`
+	TotalTransactionCount string               `json:"total_transaction_count,omitempty"`
+}
+
+// StakingReportEntry timestamps are unix timestamps in seconds, empty if
+// delegation never reached given state or time is unknown
+type StakingReportEntry struct {
+	StakingTxHash        string            `json:"staking_tx_hash"`
+	StakerAddress        string            `json:"staker_address"`
+	StakingState         string            `json:"staking_state"`
+	Watched              bool              `json:"watched"`
+	StakingAmount        string            `json:"staking_amount"`
+	StakingTimeBlocks    string            `json:"staking_time_blocks"`
+	FinalityProviderPks  []string          `json:"finality_provider_pks"`
+	StakingTxFee         string            `json:"staking_tx_fee"`
+	UnbondingTxFee       string            `json:"unbonding_tx_fee"`
+	SpendTxFee           string            `json:"spend_tx_fee"`
+	CreatedAt            string            `json:"created_at"`
+	ConfirmedOnBtcAt     string            `json:"confirmed_on_btc_at"`
+	SentToBabylonAt      string            `json:"sent_to_babylon_at"`
+	DelegationActiveAt   string            `json:"delegation_active_at"`
+	UnbondingConfirmedAt string            `json:"unbonding_confirmed_at"`
+	SpentOnBtcAt         string            `json:"spent_on_btc_at"`
+	StakingTxBlockHeight string            `json:"staking_tx_block_height"`
+	Metadata             map[string]string `json:"metadata,omitempty"`
+}
+
+type StakingReportResponse struct {
+	Entries []StakingReportEntry `json:"entries"`
+}
+
+type StakingSummaryResponse struct {
+	TotalStaked                string            `json:"total_staked"`
+	PendingStaked              string            `json:"pending_staked"`
+	ActiveStaked               string            `json:"active_staked"`
+	UnbondingStaked            string            `json:"unbonding_staked"`
+	WithdrawnAmount            string            `json:"withdrawn_amount"`
+	StakingTxFees              string            `json:"staking_tx_fees"`
+	UnbondingTxFees            string            `json:"unbonding_tx_fees"`
+	SpendTxFees                string            `json:"spend_tx_fees"`
+	TotalFees                  string            `json:"total_fees"`
+	TotalTransactionCount      string            `json:"total_transaction_count"`
+	TransactionCountPerState   map[string]string `json:"transaction_count_per_state"`
+	WatchedTransactionCount    string            `json:"watched_transaction_count"`
+	TransactionsWithoutFeeData string            `json:"transactions_without_fee_data"`
+}
+
+type GroupSummaryResponse struct {
+	// Empty for delegations which are not assigned to any group
+	Group   string                 `json:"group"`
+	Summary StakingSummaryResponse `json:"summary"`
+}
+
+type GroupSummariesResponse struct {
+	Groups []GroupSummaryResponse `json:"groups"`
+}
+
+type StakingDetailsBatchItem struct {
+	StakingTxHash string `json:"staking_tx_hash"`
+	// set if details were found
+	Details *StakingDetails `json:"details,omitempty"`
+	// set if details could not be returned, e.g. transaction is not tracked
+	Error *RpcErrorData `json:"error,omitempty"`
+}
+
+type StakingDetailsBatchResponse struct {
+	// items in the order of requested hashes
+	Items []StakingDetailsBatchItem `json:"items"`
+}
+
+type SetDelegationGroupResponse struct {
+	Group           string   `json:"group"`
+	StakingTxHashes []string `json:"staking_tx_hashes"`
+}
+
+type RewardGaugeResponse struct {
+	Type              string `json:"type"`
+	Coins             string `json:"coins"`
+	WithdrawnCoins    string `json:"withdrawn_coins"`
+	WithdrawableCoins string `json:"withdrawable_coins"`
+}
+
+type BabylonRewardsResponse struct {
+	BabylonAddress string                `json:"babylon_address"`
+	Rewards        []RewardGaugeResponse `json:"rewards"`
+}
+
+type WithdrawRewardsResponse struct {
+	TxHash         string `json:"tx_hash"`
+	WithdrawnCoins string `json:"withdrawn_coins"`
+	Recipient      string `json:"recipient,omitempty"`
+}
+
+type OwnershipProofResponse struct {
+	StakingTxHash string `json:"staking_tx_hash"`
+	StakerPk      string `json:"staker_pk"`
+	Challenge     string `json:"challenge"`
+	Signature     string `json:"signature"`
+}
+
+type VerifyOwnershipProofResponse struct {
+	Valid  bool   `json:"valid"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type SignMessageResponse struct {
+	Address   string `json:"address"`
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+}
+
+type VerifyMessageResponse struct {
+	Valid  bool   `json:"valid"`
+	Reason string `json:"reason,omitempty"`
+}
+
 type UnbondingResponse struct {
 	UnbondingTxHash string `json:"unbonding_tx_hash"`
 }
 
+type BumpStakingFeeResponse struct {
+	StakingTxHash string `json:"staking_tx_hash"`
+	ChildTxHash   string `json:"child_tx_hash"`
+	ChildFee      string `json:"child_fee"`
+	// empty if fee of staking transaction is no longer known
+	StakingTxFee       string `json:"staking_tx_fee,omitempty"`
+	SubmittedAsPackage bool   `json:"submitted_as_package"`
+}
+
+type UnbondAllResponse struct {
+	DryRun     bool   `json:"dry_run"`
+	IntervalMs string `json:"interval_ms"`
+	// delegations which are unbonded in this order, or would be in case of dry run
+	ToUnbond []string `json:"to_unbond"`
+	// active delegations with staker key not controlled by the daemon
+	Skipped []string `json:"skipped"`
+}
+
+type SearchStakingTransactionsResponse struct {
+	Transactions []StakingDetails `json:"transactions"`
+	// pass as offset to get the next page, 0 if no transaction matched
+	LastTransactionIndex string `json:"last_transaction_index"`
+}
+
 type WithdrawableTransactionsResponse struct {
 	Transactions                     []StakingDetails `json:"transactions"`
 	LastWithdrawableTransactionIndex string           `json:"last_transaction_index"`
 	TotalTransactionCount            string           `json:"total_transaction_count"`
 }
+
+type SpendPathInfo struct {
+	// Hex encoded script of the tapscript leaf
+	LeafScript  string `json:"leaf_script"`
+	LeafVersion string `json:"leaf_version"`
+	// Hex encoded tap leaf hash
+	LeafHash string `json:"leaf_hash"`
+	// Hex encoded inclusion proof of the leaf in the taproot script tree
+	MerkleProof string `json:"merkle_proof"`
+	// Hex encoded control block which must be part of the witness when spending
+	// through this path
+	ControlBlock string `json:"control_block"`
+}
+
+type StakingScriptInfoResponse struct {
+	StakingTxHash      string `json:"staking_tx_hash"`
+	StakingOutputIdx   string `json:"staking_output_idx"`
+	StakingOutputValue string `json:"staking_output_value"`
+	// Hex encoded pk script of the staking output
+	StakingOutputPkScript string `json:"staking_output_pk_script"`
+	// Hex encoded taproot internal key in BIP340 format
+	InternalKey   string        `json:"internal_key"`
+	TimeLockPath  SpendPathInfo `json:"timelock_path"`
+	UnbondingPath SpendPathInfo `json:"unbonding_path"`
+	SlashingPath  SpendPathInfo `json:"slashing_path"`
+}
+
+type DelegationGraphNode struct {
+	TxHash string `json:"tx_hash"`
+	// funding, staking, cpfp_child, unbonding, slashing, unbonding_slashing or
+	// withdrawal
+	Kind string `json:"kind"`
+	// confirmed, unconfirmed, presigned or unknown
+	Status string `json:"status"`
+	// empty if block including the transaction is unknown
+	BlockHeight string `json:"block_height,omitempty"`
+}
+
+type DelegationGraphEdge struct {
+	FromTxHash string `json:"from_tx_hash"`
+	ToTxHash   string `json:"to_tx_hash"`
+	// index of output of from transaction spent by to transaction, empty if
+	// unknown
+	OutputIdx string `json:"output_idx,omitempty"`
+}
+
+type DelegationGraphResponse struct {
+	StakingTxHash string                `json:"staking_tx_hash"`
+	State         string                `json:"state"`
+	Nodes         []DelegationGraphNode `json:"nodes"`
+	Edges         []DelegationGraphEdge `json:"edges"`
+}
+
+type MuSig2NonceResponse struct {
+	SessionId string `json:"session_id"`
+	// Hex encoded compressed public key of the signer
+	SignerPk string `json:"signer_pk"`
+	// Hex encoded 66 byte public nonce shared with other signers
+	PubNonce  string `json:"pub_nonce"`
+	CreatedAt string `json:"created_at"`
+	ExpiresAt string `json:"expires_at"`
+	Used      bool   `json:"used"`
+	Expired   bool   `json:"expired"`
+}
+
+type InputSigHashResponse struct {
+	InputIndex string `json:"input_index"`
+	// e.g staking_unbonding for unbonding path of staking output or p2wpkh for
+	// wallet input
+	SpendPath string `json:"spend_path"`
+	// schnorr or ecdsa
+	SignatureType string `json:"signature_type"`
+	SigHashType   string `json:"sighash_type"`
+	// Hex encoded leaf script, only for taproot script path spends
+	LeafScript string `json:"leaf_script,omitempty"`
+	// Hex encoded digest which must be signed
+	SigHash string `json:"sig_hash"`
+}
+
+type ComputeSigHashesResponse struct {
+	TxHash    string                 `json:"tx_hash"`
+	SigHashes []InputSigHashResponse `json:"sig_hashes"`
+}
+
+type UnbondingSigHashResponse struct {
+	UnbondingTxHex  string `json:"unbonding_tx_hex"`
+	UnbondingTxHash string `json:"unbonding_tx_hash"`
+	// Hex encoded unbonding path script which covenant members sign with
+	UnbondingPathScript string `json:"unbonding_path_script"`
+	// Hex encoded taproot script spend sighash of the unbonding transaction
+	SigHash string `json:"sig_hash"`
+	// Hex encoded BIP340 public keys of covenant committee members
+	CovenantPks    []string `json:"covenant_pks"`
+	CovenantQuorum string   `json:"covenant_quorum"`
+}
+
+type SubmitCovenantSignaturesResponse struct {
+	StakingTxHash string `json:"staking_tx_hash"`
+}
+
+type SignedUnbondingTxResponse struct {
+	UnbondingTxHex  string `json:"unbonding_tx_hex"`
+	UnbondingTxHash string `json:"unbonding_tx_hash"`
+}
+
+type ConsolidateOutputsResponse struct {
+	TxHash         string `json:"tx_hash"`
+	NumInputs      string `json:"num_inputs"`
+	InputsValue    string `json:"inputs_value"`
+	Fee            string `json:"fee"`
+	ConsolidatedTo string `json:"consolidated_to"`
+}
+
+type MonitoredTransactionsResponse struct {
+	BtcBestBlockHeight uint32                        `json:"btc_best_block_height"`
+	Transactions       []*monitor.TransactionSummary `json:"transactions"`
+	TotalCount         uint64                        `json:"total_count"`
+}
+
+type RetryQueueEntry struct {
+	Operation     string `json:"operation"`
+	StakingTxHash string `json:"staking_tx_hash"`
+	Attempts      string `json:"attempts"`
+	// unix timestamp (seconds) of next attempt
+	NextAttempt string `json:"next_attempt"`
+	LastError   string `json:"last_error"`
+	// unix timestamp (seconds) of first failure
+	CreatedAt string `json:"created_at"`
+}
+
+type RetryQueueResponse struct {
+	Entries    []RetryQueueEntry `json:"entries"`
+	TotalCount string            `json:"total_count"`
+}
+
+type FlushRetryQueueResponse struct {
+	FlushedCount string `json:"flushed_count"`
+}
+
+type ScheduledOperationDetail struct {
+	Id            string `json:"id"`
+	Operation     string `json:"operation"`
+	StakingTxHash string `json:"staking_tx_hash"`
+	// 0 if operation is scheduled at time
+	ExecuteAtHeight string `json:"execute_at_height"`
+	// unix timestamp (seconds), empty if operation is scheduled at height
+	ExecuteAtTime string `json:"execute_at_time"`
+	Note          string `json:"note"`
+	CreatedAt     string `json:"created_at"`
+	Attempts      string `json:"attempts"`
+	LastError     string `json:"last_error"`
+	// operation was scheduled by the daemon for delegation with automatic
+	// withdrawal
+	Automatic bool `json:"automatic"`
+}
+
+type ScheduledOperationsResponse struct {
+	Operations []ScheduledOperationDetail `json:"operations"`
+}
+
+type CancelScheduledOperationResponse struct {
+	Id string `json:"id"`
+}
+
+type RetryBabylonResponse struct {
+	StakingTxHash string `json:"staking_tx_hash"`
+	BabylonTxHash string `json:"babylon_tx_hash"`
+}
+
+type ExportDelegationResponse struct {
+	StakingTxHash string `json:"staking_tx_hash"`
+	// babylon address which must sign the transaction
+	Signer string `json:"signer"`
+	// MsgCreateBTCDelegation in proto json format
+	Msg json.RawMessage `json:"msg"`
+	// unsigned transaction accepted by babylond tx sign
+	UnsignedTx      json.RawMessage `json:"unsigned_tx"`
+	MarkedSubmitted bool            `json:"marked_submitted"`
+}
+
+type OverrideDelegationStateResponse struct {
+	StakingTxHash string `json:"staking_tx_hash"`
+	StakingState  string `json:"staking_state"`
+}
+
+type AnnotateDelegationResponse struct {
+	StakingTxHash string         `json:"staking_tx_hash"`
+	Note          DelegationNote `json:"note"`
+}
+
+type PurgeDelegationResponse struct {
+	StakingTxHash string `json:"staking_tx_hash"`
+}
+
+type AuditLogEntry struct {
+	// unix timestamp (seconds)
+	Timestamp     string `json:"timestamp"`
+	Action        string `json:"action"`
+	StakingTxHash string `json:"staking_tx_hash"`
+	PreviousState string `json:"previous_state"`
+	NewState      string `json:"new_state"`
+	Reason        string `json:"reason"`
+	RequestId     string `json:"request_id"`
+	RemoteAddr    string `json:"remote_addr"`
+}
+
+type AuditLogResponse struct {
+	Entries []AuditLogEntry `json:"entries"`
+}
+
+type BabylonStakingParamsResponse struct {
+	ConfirmationTimeBlocks    string   `json:"confirmation_time_blocks"`
+	FinalizationTimeoutBlocks string   `json:"finalization_timeout_blocks"`
+	MinSlashingTxFeeSat       string   `json:"min_slashing_tx_fee_sat"`
+	CovenantPks               []string `json:"covenant_pks"`
+	CovenantQuorum            string   `json:"covenant_quorum"`
+	SlashingAddress           string   `json:"slashing_address"`
+	SlashingRate              string   `json:"slashing_rate"`
+	MinUnbondingTime          string   `json:"min_unbonding_time"`
+}
+
+type WalletTransactionDetail struct {
+	TxHash   string `json:"tx_hash"`
+	Vout     uint32 `json:"vout"`
+	Address  string `json:"address"`
+	Label    string `json:"label"`
+	Category string `json:"category"`
+	Amount   string `json:"amount"`
+	// only set for sent outputs
+	Fee           string `json:"fee,omitempty"`
+	Confirmations string `json:"confirmations"`
+	Time          string `json:"time"`
+}
+
+type WalletTransactionsResponse struct {
+	Transactions []WalletTransactionDetail `json:"transactions"`
+}
+
+type FeeEstimateResponse struct {
+	FeeRateSatPerKvb string `json:"fee_rate_sat_per_kvb"`
+	FeeRateSatPerVb  string `json:"fee_rate_sat_per_vb"`
+	// fee rate floor derived from mempool policy of connected node
+	MinFeeRateSatPerKvb string `json:"min_fee_rate_sat_per_kvb"`
+}
+
+type UtxoBlocklistEntry struct {
+	// exactly one of outpoint and address is set
+	Outpoint string `json:"outpoint,omitempty"`
+	Address  string `json:"address,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	// config or rpc
+	Source    string `json:"source"`
+	Timestamp string `json:"timestamp,omitempty"`
+}
+
+type UtxoBlocklistResponse struct {
+	Entries []UtxoBlocklistEntry `json:"entries"`
+}
+
+type StakingPresetResponse struct {
+	Name              string   `json:"name"`
+	FpBtcPks          []string `json:"fp_btc_pks"`
+	StakingTimeBlocks string   `json:"staking_time_blocks"`
+	// empty if any amount is allowed
+	AmountTiers []string `json:"amount_tiers"`
+	// sat/vbyte, 0 if not limited
+	MaxFeeRate string `json:"max_fee_rate"`
+	// config or rpc
+	Source    string `json:"source"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+type StakingPresetsResponse struct {
+	Presets []StakingPresetResponse `json:"presets"`
+}
+
+type FrozenOutputDetail struct {
+	Outpoint  string `json:"outpoint"`
+	Note      string `json:"note,omitempty"`
+	Timestamp string `json:"timestamp"`
+}
+
+type FrozenOutputsResponse struct {
+	Outputs []FrozenOutputDetail `json:"outputs"`
+}
+
+type PendingActionDetail struct {
+	ActionId string `json:"action_id"`
+	Method   string `json:"method"`
+	// json rpc request which is executed after approval
+	Request     string `json:"request"`
+	RequestId   string `json:"request_id"`
+	RequestedBy string `json:"requested_by"`
+	CreatedAt   string `json:"created_at"`
+	ExpiresAt   string `json:"expires_at"`
+}
+
+type PendingActionsResponse struct {
+	Actions []PendingActionDetail `json:"actions"`
+}
+
+type ApproveActionResponse struct {
+	ActionId    string `json:"action_id"`
+	Method      string `json:"method"`
+	RequestedBy string `json:"requested_by"`
+	ApprovedBy  string `json:"approved_by"`
+	// result of the executed call
+	Result json.RawMessage `json:"result"`
+}
+
+type RejectActionResponse struct {
+	ActionId   string `json:"action_id"`
+	Method     string `json:"method"`
+	RejectedBy string `json:"rejected_by"`
+}
+
+type FeeBudgetWindowResponse struct {
+	WindowHours string `json:"window_hours"`
+	// fees in satoshis
+	Spent string `json:"spent"`
+	// 0 if there is no budget for the window
+	Budget       string            `json:"budget"`
+	Exceeded     bool              `json:"exceeded"`
+	SpentPerKind map[string]string `json:"spent_per_kind"`
+}
+
+type FeeBudgetResponse struct {
+	LastDay               FeeBudgetWindowResponse `json:"last_day"`
+	LastWeek              FeeBudgetWindowResponse `json:"last_week"`
+	Exceeded              bool                    `json:"exceeded"`
+	BlockAutomatedActions bool                    `json:"block_automated_actions"`
+}
+
+type FeeStatsResponse struct {
+	// UTC day in format YYYY-MM-DD
+	Day   string `json:"day"`
+	Kind  string `json:"kind"`
+	Count string `json:"count"`
+	// fees in satoshis, fee rates in sat/vbyte
+	TotalFee              string `json:"total_fee"`
+	AvgFeeRate            string `json:"avg_fee_rate"`
+	MedianFeeRate         string `json:"median_fee_rate"`
+	Confirmed             string `json:"confirmed"`
+	AvgConfirmationBlocks string `json:"avg_confirmation_blocks"`
+}
+
+type FeeAnalyticsResponse struct {
+	Stats []FeeStatsResponse `json:"stats"`
+}
+
+// times in seconds
+type ConfirmationSlaKindResponse struct {
+	Kind     string `json:"kind"`
+	Observed string `json:"observed"`
+	Avg      string `json:"avg"`
+	Median   string `json:"median"`
+	P90      string `json:"p90"`
+	Max      string `json:"max"`
+	Exceeded string `json:"exceeded"`
+}
+
+type FlaggedTransactionResponse struct {
+	TxHash string `json:"tx_hash"`
+	Kind   string `json:"kind"`
+	SentAt string `json:"sent_at"`
+	// empty if first confirmation was not observed
+	TimeToFirstConfirmation string `json:"time_to_first_confirmation"`
+}
+
+type ConfirmationSlaResponse struct {
+	// 0 if flagging is disabled
+	TargetSeconds string                        `json:"target_seconds"`
+	Kinds         []ConfirmationSlaKindResponse `json:"kinds"`
+	Flagged       []FlaggedTransactionResponse  `json:"flagged"`
+}
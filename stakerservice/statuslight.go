@@ -0,0 +1,123 @@
+package stakerservice
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	str "github.com/babylonchain/btc-staker/staker"
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// IfNoneMatchHeader carries etag of staking status already known to the caller
+const IfNoneMatchHeader = "If-None-Match"
+
+func storedTxToStakingStatusLight(tx *stakerdb.StoredTransaction, bestBlockHeight uint32) StakingStatusLight {
+	fpPks := make([]string, 0, len(tx.FinalityProvidersBtcPks))
+	for _, pk := range tx.FinalityProvidersBtcPks {
+		fpPks = append(fpPks, hex.EncodeToString(schnorr.SerializePubKey(pk)))
+	}
+
+	var blocksToMaturity string
+	if blocks, found := tx.BlocksToMaturity(bestBlockHeight); found {
+		blocksToMaturity = strconv.FormatUint(uint64(blocks), 10)
+	}
+
+	return StakingStatusLight{
+		StakingTxHash:       tx.StakingTx.TxHash().String(),
+		StakingState:        stateName(tx.State),
+		StakingAmount:       strconv.FormatInt(tx.StakingTx.TxOut[tx.StakingOutputIndex].Value, 10),
+		FinalityProviderPks: fpPks,
+		BlocksToMaturity:    blocksToMaturity,
+	}
+}
+
+// statusEtag is strong etag of the staking status page, it changes whenever any
+// field of the response changes
+func statusEtag(delegations []StakingStatusLight, total string) (string, error) {
+	encoded, err := json.Marshal(struct {
+		Delegations []StakingStatusLight `json:"delegations"`
+		Total       string               `json:"total"`
+	}{delegations, total})
+
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(encoded)
+	return `"` + hex.EncodeToString(hash[:16]) + `"`, nil
+}
+
+// etagMatches compares etags according to weak comparison of RFC 7232, so
+// callers may send etag with or without quotes and W/ prefix
+func etagMatches(ifNoneMatch string, etag string) bool {
+	normalize := func(tag string) string {
+		return strings.Trim(strings.TrimPrefix(strings.TrimSpace(tag), "W/"), `"`)
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+
+		if candidate == "*" || normalize(candidate) == normalize(etag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stakingStatusLight returns only fields needed to display delegations in a
+// wallet. If etag known to the caller is passed either as parameter or in
+// If-None-Match header and status did not change, delegations are omitted.
+func (s *StakerService) stakingStatusLight(
+	ctx *rpctypes.Context,
+	offset, limit *int,
+	ifNoneMatch *string,
+) (*StakingStatusLightResponse, error) {
+	pageParams := getPageParams(offset, limit)
+
+	txResult, err := s.staker.StoredTransactions(pageParams.Limit, pageParams.Offset, nil, nil, str.TimeFilter{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	bestBlockHeight := s.staker.BestBlockHeight()
+
+	delegations := make([]StakingStatusLight, 0, len(txResult.Transactions))
+	for i := range txResult.Transactions {
+		delegations = append(delegations, storedTxToStakingStatusLight(&txResult.Transactions[i], bestBlockHeight))
+	}
+
+	totalCount := strconv.FormatUint(txResult.Total, 10)
+
+	etag, err := statusEtag(delegations, totalCount)
+
+	if err != nil {
+		return nil, err
+	}
+
+	knownEtag := ""
+	if ifNoneMatch != nil {
+		knownEtag = *ifNoneMatch
+	} else if ctx != nil && ctx.HTTPReq != nil {
+		knownEtag = ctx.HTTPReq.Header.Get(IfNoneMatchHeader)
+	}
+
+	if knownEtag != "" && etagMatches(knownEtag, etag) {
+		return &StakingStatusLightResponse{
+			Etag:        etag,
+			NotModified: true,
+		}, nil
+	}
+
+	return &StakingStatusLightResponse{
+		Etag:                  etag,
+		Delegations:           delegations,
+		TotalTransactionCount: totalCount,
+	}, nil
+}
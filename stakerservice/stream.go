@@ -0,0 +1,225 @@
+package stakerservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/babylonchain/btc-staker/stakerdb"
+	"github.com/cometbft/cometbft/libs/log"
+	rpc "github.com/cometbft/cometbft/rpc/jsonrpc/server"
+)
+
+const (
+	streamPathPrefix = "/stream/"
+
+	StreamStakingTransactionsPath = streamPathPrefix + "list_staking_transactions"
+
+	// number of transactions read from db and written to the client at once
+	streamBatchSize = 500
+
+	// time given to the client to receive one batch of streamed transactions
+	streamBatchWriteTimeout = 30 * time.Second
+)
+
+// StreamErrorRecord is written as the last record of the stream if streaming
+// failed after some records were already sent
+type StreamErrorRecord struct {
+	Error string `json:"error"`
+}
+
+// StreamHandlers are plain http handlers served next to json rpc routes
+type StreamHandlers map[string]http.HandlerFunc
+
+func (s *StakerService) GetStreamHandlers() StreamHandlers {
+	handlers := StreamHandlers{
+		StreamStakingTransactionsPath: s.streamStakingTransactions,
+	}
+
+	for path, handler := range s.GetProbeHandlers() {
+		handlers[path] = handler
+	}
+
+	return handlers
+}
+
+func parseStreamMetadataFilter(entries []string) (map[string]string, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	filter := make(map[string]string)
+
+	for _, entry := range entries {
+		key, value, found := strings.Cut(entry, "=")
+
+		if !found || len(key) == 0 {
+			return nil, fmt.Errorf("invalid metadata filter entry %s, expected format key=value", entry)
+		}
+
+		filter[key] = value
+	}
+
+	return filter, nil
+}
+
+// streamStakingTransactions writes all staking transactions as newline delimited
+// json (one StakingDetails per line) using chunked transfer encoding.
+// Transactions are read from db in batches and next batch is read only after
+// previous one was flushed to the client, so slow clients naturally slow down
+// the stream instead of accumulating data in memory.
+//
+// Supported query parameters:
+//   - offset - stream starts after transaction with this index, which allows
+//     resuming interrupted stream
+//   - metadata_filter - key=value pair, can be repeated
+//   - group - only transactions assigned to this group are streamed
+func (s *StakerService) streamStakingTransactions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET method is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+
+	var offset uint64
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		parsed, err := strconv.ParseUint(offsetStr, 10, 64)
+
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid offset: %s", err), http.StatusBadRequest)
+			return
+		}
+		offset = parsed
+	}
+
+	metadataFilter, err := parseStreamMetadataFilter(query["metadata_filter"])
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var group *string
+	if query.Has("group") {
+		g := query.Get("group")
+		group = &g
+	}
+
+	rc := http.NewResponseController(w)
+	encoder := json.NewEncoder(w)
+	headerWritten := false
+
+	err = s.staker.StreamStoredTransactions(
+		r.Context(),
+		offset,
+		metadataFilter,
+		group,
+		streamBatchSize,
+		func(batch []stakerdb.StoredTransaction) error {
+			// write timeout of the server applies to whole response, so it is
+			// extended for each batch
+			if err := rc.SetWriteDeadline(time.Now().Add(streamBatchWriteTimeout)); err != nil {
+				return err
+			}
+
+			if !headerWritten {
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				w.WriteHeader(http.StatusOK)
+				headerWritten = true
+			}
+
+			for i := range batch {
+				if err := encoder.Encode(storedTxToStakingDetails(&batch[i])); err != nil {
+					return err
+				}
+			}
+
+			return rc.Flush()
+		},
+	)
+
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to stream staking transactions")
+
+		if !headerWritten {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// client may be already gone, nothing to do if this write fails
+		_ = encoder.Encode(StreamErrorRecord{Error: err.Error()})
+		return
+	}
+
+	if !headerWritten {
+		// there were no transactions to stream
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// serveHTTP serves json rpc routes in the same way as rpc.Serve, except for
+// streaming handlers which are served directly. Streaming handlers need access
+// to underlying http.ResponseWriter to flush and extend write deadline, which is
+// hidden by rpc recover handler. Streaming handlers are not signed, as signing
+// requires buffering whole response.
+func serveHTTP(
+	listener net.Listener,
+	mux *http.ServeMux,
+	streams StreamHandlers,
+	signer *responseSigner,
+	acl *rpcAcl,
+	approvals *approvalQueue,
+	verifier *requestVerifier,
+	masker *responseMasker,
+	accessLog *accessLogger,
+	identities *identityResolver,
+	logger log.Logger,
+	config *rpc.Config,
+) error {
+	// responses are masked and signed after conversion to requested api version
+	rpcHandler := withResponseSignature(
+		withResponseMasking(
+			withApiVersion(
+				rpc.RecoverAndLogHandler(http.MaxBytesHandler(mux, config.MaxBodyBytes), logger),
+			),
+			masker,
+		),
+		signer,
+	)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if stream, found := streams[r.URL.Path]; found {
+			serveMaskedStream(stream, masker, w, r)
+			return
+		}
+
+		rpcHandler.ServeHTTP(w, r)
+	})
+
+	// signatures are verified before calls are queued for approval, approved
+	// calls are executed without verification
+	var chain http.Handler = withApproval(handler, approvals)
+	chain = withRequestVerification(chain, verifier)
+	chain = withAcl(chain, acl)
+	// logged duration includes time spent in access control
+	chain = withAccessLog(chain, accessLog)
+	// body is parsed and caller identified once for all middleware above
+	chain = withRpcRequest(chain, identities, config.MaxBodyBytes)
+	chain = withRequestId(chain)
+
+	server := &http.Server{
+		Handler:           chain,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		MaxHeaderBytes:    config.MaxHeaderBytes,
+	}
+
+	return server.Serve(listener)
+}
@@ -0,0 +1,142 @@
+package stakerservice
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/sirupsen/logrus"
+)
+
+// states sent to systemd, see sd_notify(3)
+const (
+	sdNotifyReady    = "READY=1"
+	sdNotifyStopping = "STOPPING=1"
+	sdNotifyWatchdog = "WATCHDOG=1"
+)
+
+// sdNotify sends state to systemd. Returns false if the daemon was not started
+// by systemd with notify socket.
+func sdNotify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+
+	if socket == "" {
+		return false, nil
+	}
+
+	// abstract socket
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+
+	if err != nil {
+		return false, err
+	}
+
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func notifySystemd(logger *logrus.Logger, state string) {
+	if _, err := sdNotify(state); err != nil {
+		logger.WithError(err).WithField("state", state).Warn("Failed to notify systemd")
+	}
+}
+
+// sdWatchdogInterval returns interval in which systemd expects watchdog pings,
+// 0 if watchdog is disabled
+func sdWatchdogInterval() (time.Duration, error) {
+	usec := os.Getenv("WATCHDOG_USEC")
+
+	if usec == "" {
+		return 0, nil
+	}
+
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		// watchdog is meant for another process
+		return 0, nil
+	}
+
+	interval, err := strconv.ParseUint(usec, 10, 64)
+
+	if err != nil || interval == 0 {
+		return 0, fmt.Errorf("invalid WATCHDOG_USEC %s", usec)
+	}
+
+	return time.Duration(interval) * time.Microsecond, nil
+}
+
+// startSystemdWatchdog pings systemd watchdog twice per watchdog interval. If
+// self check is enabled, pings are sent only after selfCheck succeeds, so that
+// systemd restarts the daemon whose internals are stuck even though the process
+// is alive. Returned function stops the pings.
+func startSystemdWatchdog(cfg *scfg.SystemdConfig, selfCheck func(ctx context.Context) error, logger *logrus.Logger) func() {
+	interval, err := sdWatchdogInterval()
+
+	if err != nil {
+		logger.WithError(err).Warn("Systemd watchdog disabled")
+		return func() {}
+	}
+
+	if interval == 0 {
+		return func() {}
+	}
+
+	pingInterval := interval / 2
+
+	if cfg.SelfCheck && selfCheck != nil && cfg.SelfCheckTimeout >= pingInterval {
+		logger.WithFields(logrus.Fields{
+			"selfCheckTimeout": cfg.SelfCheckTimeout,
+			"watchdogInterval": interval,
+		}).Warn("Self check timeout is longer than half of watchdog interval, slow self check will trigger watchdog")
+	}
+
+	logger.WithField("interval", interval).Info("Pinging systemd watchdog")
+
+	quit := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if cfg.SelfCheck && selfCheck != nil {
+					ctx, cancel := context.WithTimeout(context.Background(), cfg.SelfCheckTimeout)
+					err := selfCheck(ctx)
+					cancel()
+
+					if err != nil {
+						logger.WithError(err).Error("Self check failed, systemd watchdog is not pinged")
+						continue
+					}
+				}
+
+				notifySystemd(logger, sdNotifyWatchdog)
+
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(quit)
+		<-done
+	}
+}
@@ -0,0 +1,69 @@
+package stakerservice
+
+import (
+	"time"
+
+	str "github.com/babylonchain/btc-staker/staker"
+	"github.com/babylonchain/btc-staker/stakerdb"
+)
+
+func parseTimeRange(name string, after, before *int64) (stakerdb.TimeRange, error) {
+	var r stakerdb.TimeRange
+
+	if after != nil {
+		if *after < 0 {
+			return r, invalidParamsf("%sAfter must be non-negative", name)
+		}
+		r.After = time.Unix(*after, 0)
+	}
+
+	if before != nil {
+		if *before < 0 {
+			return r, invalidParamsf("%sBefore must be non-negative", name)
+		}
+		r.Before = time.Unix(*before, 0)
+	}
+
+	if !r.After.IsZero() && !r.Before.IsZero() && !r.After.Before(r.Before) {
+		return r, invalidParamsf("%sAfter must be before %sBefore", name, name)
+	}
+
+	return r, nil
+}
+
+// parseTimeFilter parses time range parameters of listing calls. Times are unix
+// timestamps in seconds, after is inclusive and before is exclusive.
+func parseTimeFilter(
+	createdAfter, createdBefore *int64,
+	transitionState *string,
+	transitionAfter, transitionBefore *int64,
+) (str.TimeFilter, error) {
+	var filter str.TimeFilter
+
+	created, err := parseTimeRange("created", createdAfter, createdBefore)
+
+	if err != nil {
+		return filter, err
+	}
+
+	transition, err := parseTimeRange("transition", transitionAfter, transitionBefore)
+
+	if err != nil {
+		return filter, err
+	}
+
+	filter.Created = created
+	filter.StateTransition = transition
+
+	if transitionState != nil && *transitionState != "" {
+		state, err := parseTransactionState(*transitionState)
+
+		if err != nil {
+			return filter, err
+		}
+
+		filter.TransitionState = &state
+	}
+
+	return filter, nil
+}
@@ -1,4 +1,4 @@
-package e2etest
+package testutil
 
 import (
 	"encoding/json"
@@ -9,7 +9,7 @@ import (
 	"testing"
 	"time"
 
-	"github.com/babylonchain/btc-staker/itest/containers"
+	"github.com/babylonchain/btc-staker/testutil/containers"
 	"github.com/stretchr/testify/require"
 )
 
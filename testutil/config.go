@@ -0,0 +1,77 @@
+package testutil
+
+import (
+	"time"
+
+	"github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/babylonchain/btc-staker/types"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/rpcclient"
+)
+
+// credentials of bitcoind started by BitcoindTestHandler
+const (
+	BitcoindHost = "127.0.0.1:18443"
+	BitcoindUser = "user"
+	BitcoindPass = "pass"
+)
+
+// RegtestStakerConfig returns config of the staker using bitcoind started by
+// BitcoindTestHandler both as wallet and node backend. Babylon config and db
+// path must be set by the caller.
+func RegtestStakerConfig(walletPassphrase string) *stakercfg.Config {
+	defaultConfig := stakercfg.DefaultConfig()
+
+	// both wallet and node are bicoind
+	defaultConfig.BtcNodeBackendConfig.ActiveWalletBackend = types.BitcoindWalletBackend
+	defaultConfig.BtcNodeBackendConfig.ActiveNodeBackend = types.BitcoindNodeBackend
+	defaultConfig.ActiveNetParams = chaincfg.RegressionNetParams
+
+	// Fees configuration
+	defaultConfig.BtcNodeBackendConfig.FeeMode = "dynamic"
+	defaultConfig.BtcNodeBackendConfig.EstimationMode = types.DynamicFeeEstimation
+
+	// Wallet configuration
+	defaultConfig.WalletRpcConfig.Host = BitcoindHost
+	defaultConfig.WalletRpcConfig.User = BitcoindUser
+	defaultConfig.WalletRpcConfig.Pass = BitcoindPass
+	defaultConfig.WalletRpcConfig.DisableTls = true
+	defaultConfig.WalletConfig.WalletPass = walletPassphrase
+
+	// node configuration
+	defaultConfig.BtcNodeBackendConfig.Bitcoind.RPCHost = BitcoindHost
+	defaultConfig.BtcNodeBackendConfig.Bitcoind.RPCUser = BitcoindUser
+	defaultConfig.BtcNodeBackendConfig.Bitcoind.RPCPass = BitcoindPass
+
+	// Use rpc polling, as it is our default mode and it is a bit more troublesome
+	// to configure ZMQ from inside the bitcoind docker container
+	defaultConfig.BtcNodeBackendConfig.Bitcoind.RPCPolling = true
+	defaultConfig.BtcNodeBackendConfig.Bitcoind.BlockPollingInterval = 1 * time.Second
+	defaultConfig.BtcNodeBackendConfig.Bitcoind.TxPollingInterval = 1 * time.Second
+
+	defaultConfig.StakerConfig.BabylonStallingInterval = 1 * time.Second
+	defaultConfig.StakerConfig.UnbondingTxCheckInterval = 1 * time.Second
+
+	// TODO: After bumping relayer version sending transactions concurrently fails wih
+	// fatal error: concurrent map writes
+	// For now diable concurrent sends but this need to be sorted out
+	defaultConfig.StakerConfig.MaxConcurrentTransactions = 1
+
+	return &defaultConfig
+}
+
+// NewBitcoindRpcClient returns rpc client of bitcoind started by
+// BitcoindTestHandler, used by tests to inspect the chain
+func NewBitcoindRpcClient() (*rpcclient.Client, error) {
+	return rpcclient.New(&rpcclient.ConnConfig{
+		Host:                 BitcoindHost,
+		User:                 BitcoindUser,
+		Pass:                 BitcoindPass,
+		DisableTLS:           true,
+		DisableConnectOnNew:  true,
+		DisableAutoReconnect: false,
+		// we use post mode as it sure it works with either bitcoind or btcwallet
+		// we may need to re-consider it later if we need any notifications
+		HTTPPostMode: true,
+	}, nil)
+}
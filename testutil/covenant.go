@@ -0,0 +1,171 @@
+package testutil
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	staking "github.com/babylonchain/babylon/btcstaking"
+	"github.com/babylonchain/babylon/testutil/datagen"
+	bbntypes "github.com/babylonchain/babylon/types"
+	btcstypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	"github.com/babylonchain/btc-staker/babylonclient"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// CovenantSigner is mock covenant committee, which private keys are known to the
+// test
+type CovenantSigner struct {
+	PrivKeys []*btcec.PrivateKey
+	Net      *chaincfg.Params
+}
+
+func NewCovenantSigner(numCovenants int, net *chaincfg.Params) (*CovenantSigner, error) {
+	var privKeys []*btcec.PrivateKey
+
+	for i := 0; i < numCovenants; i++ {
+		privKey, err := btcec.NewPrivateKey()
+
+		if err != nil {
+			return nil, err
+		}
+
+		privKeys = append(privKeys, privKey)
+	}
+
+	return &CovenantSigner{
+		PrivKeys: privKeys,
+		Net:      net,
+	}, nil
+}
+
+func (s *CovenantSigner) PubKeys() []*btcec.PublicKey {
+	pks := make([]*btcec.PublicKey, len(s.PrivKeys))
+
+	for i, k := range s.PrivKeys {
+		pks[i] = k.PubKey()
+	}
+
+	return pks
+}
+
+// SubmitSignatures signs slashing, unbonding and unbonding slashing transactions
+// of the delegation by every covenant member and submits signatures to babylon
+func (s *CovenantSigner) SubmitSignatures(
+	bc *babylonclient.BabylonController,
+	params *babylonclient.StakingParams,
+	btcDel *btcstypes.BTCDelegationResponse,
+) error {
+	fpBTCPKs, err := bbntypes.NewBTCPKsFromBIP340PKs(btcDel.FpBtcPkList)
+	if err != nil {
+		return err
+	}
+
+	slashingTxBytes, err := hex.DecodeString(btcDel.SlashingTxHex)
+	if err != nil {
+		return err
+	}
+	slashingTx := btcstypes.BTCSlashingTx(slashingTxBytes)
+	stakingMsgTx, _, err := bbntypes.NewBTCTxFromHex(btcDel.StakingTxHex)
+	if err != nil {
+		return err
+	}
+
+	stakingInfo, err := staking.BuildStakingInfo(
+		btcDel.BtcPk.MustToBTCPK(),
+		fpBTCPKs,
+		params.CovenantPks,
+		params.CovenantQuruomThreshold,
+		uint16(btcDel.EndHeight-btcDel.StartHeight),
+		btcutil.Amount(btcDel.TotalSat),
+		s.Net,
+	)
+	if err != nil {
+		return err
+	}
+	slashingPathInfo, err := stakingInfo.SlashingPathSpendInfo()
+	if err != nil {
+		return err
+	}
+
+	covenantSlashingTxSigs, err := datagen.GenCovenantAdaptorSigs(
+		s.PrivKeys,
+		fpBTCPKs,
+		stakingMsgTx,
+		slashingPathInfo.GetPkScriptPath(),
+		&slashingTx,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to sign slashing tx: %w", err)
+	}
+
+	// slash unbonding tx spends unbonding tx
+	unbondingMsgTx, _, err := bbntypes.NewBTCTxFromHex(btcDel.UndelegationResponse.UnbondingTxHex)
+	if err != nil {
+		return err
+	}
+	unbondingInfo, err := staking.BuildUnbondingInfo(
+		btcDel.BtcPk.MustToBTCPK(),
+		fpBTCPKs,
+		params.CovenantPks,
+		params.CovenantQuruomThreshold,
+		uint16(btcDel.UnbondingTime),
+		btcutil.Amount(unbondingMsgTx.TxOut[0].Value),
+		s.Net,
+	)
+	if err != nil {
+		return err
+	}
+	unbondingSlashingPathInfo, err := unbondingInfo.SlashingPathSpendInfo()
+	if err != nil {
+		return err
+	}
+
+	// generate all covenant signatures from all covenant members
+	unbondingSlashingTx, err := btcstypes.NewBTCSlashingTxFromHex(btcDel.UndelegationResponse.SlashingTxHex)
+	if err != nil {
+		return err
+	}
+	covenantUnbondingSlashingTxSigs, err := datagen.GenCovenantAdaptorSigs(
+		s.PrivKeys,
+		fpBTCPKs,
+		unbondingMsgTx,
+		unbondingSlashingPathInfo.GetPkScriptPath(),
+		unbondingSlashingTx,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to sign unbonding slashing tx: %w", err)
+	}
+
+	unbondingPathInfo, err := stakingInfo.UnbondingPathSpendInfo()
+	if err != nil {
+		return err
+	}
+	covUnbondingSigs, err := datagen.GenCovenantUnbondingSigs(
+		s.PrivKeys,
+		stakingMsgTx,
+		btcDel.StakingOutputIdx,
+		unbondingPathInfo.GetPkScriptPath(),
+		unbondingMsgTx,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to sign unbonding tx: %w", err)
+	}
+
+	// each covenant member submits signatures
+	for i := 0; i < len(s.PrivKeys); i++ {
+		_, err = bc.SubmitCovenantSig(
+			bbntypes.NewBIP340PubKeyFromBTCPK(s.PrivKeys[i].PubKey()),
+			stakingMsgTx.TxHash().String(),
+			covenantSlashingTxSigs[i].AdaptorSigs,
+			bbntypes.NewBIP340SignatureFromBTCSig(covUnbondingSigs[i]),
+			covenantUnbondingSlashingTxSigs[i].AdaptorSigs,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to submit signatures of covenant %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,5 @@
+// Package testutil contains utilities used by staker e2e tests, exported so that
+// projects integrating with the staker can run integration tests against it:
+// regtest bitcoind started in docker, local Babylon node started from babylond
+// binary and mock covenant committee signing delegations.
+package testutil
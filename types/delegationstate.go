@@ -0,0 +1,99 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/babylonchain/btc-staker/proto"
+)
+
+// DelegationStatesVersion is version of canonical delegation state names. It is
+// increased only when state is removed or its meaning changes, adding new state
+// does not change the version.
+const DelegationStatesVersion = 1
+
+// DelegationState is canonical name of delegation state used in rpc responses,
+// audit log events, metric labels and cli. Canonical names are mapped from
+// internal states, so refactoring internal states does not break integrators.
+type DelegationState string
+
+const (
+	DelegationStateSentToBtc               DelegationState = "SENT_TO_BTC"
+	DelegationStateConfirmedOnBtc          DelegationState = "CONFIRMED_ON_BTC"
+	DelegationStateSentToBabylon           DelegationState = "SENT_TO_BABYLON"
+	DelegationStateActive                  DelegationState = "DELEGATION_ACTIVE"
+	DelegationStateUnbondingConfirmedOnBtc DelegationState = "UNBONDING_CONFIRMED_ON_BTC"
+	DelegationStateSpentOnBtc              DelegationState = "SPENT_ON_BTC"
+	DelegationStateUnknown                 DelegationState = "UNKNOWN"
+)
+
+type DelegationStateInfo struct {
+	State       DelegationState
+	Description string
+	internal    proto.TransactionState
+}
+
+// delegationStates maps internal states to canonical ones, in order of the
+// delegation lifecycle
+var delegationStates = []DelegationStateInfo{
+	{
+		State:       DelegationStateSentToBtc,
+		Description: "staking transaction was sent to btc and is not confirmed yet",
+		internal:    proto.TransactionState_SENT_TO_BTC,
+	},
+	{
+		State:       DelegationStateConfirmedOnBtc,
+		Description: "staking transaction is confirmed on btc, delegation was not sent to babylon yet",
+		internal:    proto.TransactionState_CONFIRMED_ON_BTC,
+	},
+	{
+		State:       DelegationStateSentToBabylon,
+		Description: "delegation was sent to babylon and waits for covenant signatures",
+		internal:    proto.TransactionState_SENT_TO_BABYLON,
+	},
+	{
+		State:       DelegationStateActive,
+		Description: "delegation is active on babylon",
+		internal:    proto.TransactionState_DELEGATION_ACTIVE,
+	},
+	{
+		State:       DelegationStateUnbondingConfirmedOnBtc,
+		Description: "unbonding transaction is confirmed on btc, funds can be withdrawn once unbonding time passes",
+		internal:    proto.TransactionState_UNBONDING_CONFIRMED_ON_BTC,
+	},
+	{
+		State:       DelegationStateSpentOnBtc,
+		Description: "staked funds were withdrawn or spent on btc",
+		internal:    proto.TransactionState_SPENT_ON_BTC,
+	},
+}
+
+// DelegationStates returns all canonical delegation states in order of the
+// delegation lifecycle
+func DelegationStates() []DelegationStateInfo {
+	states := make([]DelegationStateInfo, len(delegationStates))
+	copy(states, delegationStates)
+	return states
+}
+
+// CanonicalDelegationState returns canonical name of internal state,
+// DelegationStateUnknown if state has no canonical name
+func CanonicalDelegationState(state proto.TransactionState) DelegationState {
+	for _, s := range delegationStates {
+		if s.internal == state {
+			return s.State
+		}
+	}
+
+	return DelegationStateUnknown
+}
+
+// ParseDelegationState returns internal state with given canonical name
+func ParseDelegationState(name string) (proto.TransactionState, error) {
+	for _, s := range delegationStates {
+		if string(s.State) == name {
+			return s.internal, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown delegation state: %s", name)
+}
@@ -0,0 +1,218 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// Implementation of BIP-322 "simple" signatures for single key segwit v0 (P2WPKH)
+// and taproot key spend (P2TR) addresses.
+// https://github.com/bitcoin/bips/blob/master/bip-0322.mediawiki
+
+var bip322Tag = []byte("BIP0322-signed-message")
+
+// Bip322MessageHash returns BIP-322 tagged hash of the message
+func Bip322MessageHash(message []byte) *chainhash.Hash {
+	return chainhash.TaggedHash(bip322Tag, message)
+}
+
+func bip322ToSpendTx(message []byte, pkScript []byte) (*wire.MsgTx, error) {
+	msgHash := Bip322MessageHash(message)
+
+	sigScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_0).
+		AddData(msgHash[:]).
+		Script()
+
+	if err != nil {
+		return nil, err
+	}
+
+	tx := wire.NewMsgTx(0)
+	tx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{
+			Hash:  chainhash.Hash{},
+			Index: 0xffffffff,
+		},
+		SignatureScript: sigScript,
+		Sequence:        0,
+	})
+	tx.AddTxOut(wire.NewTxOut(0, pkScript))
+
+	return tx, nil
+}
+
+func bip322ToSignTx(toSpend *wire.MsgTx) *wire.MsgTx {
+	tx := wire.NewMsgTx(0)
+	tx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{
+			Hash:  toSpend.TxHash(),
+			Index: 0,
+		},
+		Sequence: 0,
+	})
+	tx.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_RETURN}))
+
+	return tx
+}
+
+func serializeWitness(witness wire.TxWitness) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := wire.WriteVarInt(&buf, 0, uint64(len(witness))); err != nil {
+		return nil, err
+	}
+
+	for _, item := range witness {
+		if err := wire.WriteVarBytes(&buf, 0, item); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func deserializeWitness(serialized []byte) (wire.TxWitness, error) {
+	r := bytes.NewReader(serialized)
+
+	count, err := wire.ReadVarInt(r, 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// each witness item takes at least one byte
+	if count > uint64(len(serialized)) {
+		return nil, fmt.Errorf("invalid number of witness items: %d", count)
+	}
+
+	witness := make(wire.TxWitness, count)
+	for i := range witness {
+		item, err := wire.ReadVarBytes(r, 0, txscript.MaxScriptSize, "witness item")
+
+		if err != nil {
+			return nil, err
+		}
+
+		witness[i] = item
+	}
+
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("unexpected trailing bytes after witness")
+	}
+
+	return witness, nil
+}
+
+// SignBip322Simple creates BIP-322 simple signature of the message, which is
+// serialized witness of the to_sign transaction
+func SignBip322Simple(privKey *btcec.PrivateKey, address btcutil.Address, message []byte) ([]byte, error) {
+	pkScript, err := txscript.PayToAddrScript(address)
+
+	if err != nil {
+		return nil, err
+	}
+
+	toSpend, err := bip322ToSpendTx(message, pkScript)
+
+	if err != nil {
+		return nil, err
+	}
+
+	toSign := bip322ToSignTx(toSpend)
+
+	fetcher := txscript.NewCannedPrevOutputFetcher(pkScript, 0)
+	sigHashes := txscript.NewTxSigHashes(toSign, fetcher)
+
+	var witness wire.TxWitness
+
+	switch address.(type) {
+	case *btcutil.AddressWitnessPubKeyHash:
+		witness, err = txscript.WitnessSignature(
+			toSign, sigHashes, 0, 0, pkScript, txscript.SigHashAll, privKey, true,
+		)
+	case *btcutil.AddressTaproot:
+		witness, err = txscript.TaprootWitnessSignature(
+			toSign, sigHashes, 0, 0, pkScript, txscript.SigHashDefault, privKey,
+		)
+	default:
+		return nil, fmt.Errorf("unsupported address type %T, only p2wpkh and p2tr addresses are supported", address)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	toSign.TxIn[0].Witness = witness
+
+	// make sure we produced valid signature i.e private key matches address
+	if err := verifyBip322ToSign(toSign, pkScript, fetcher); err != nil {
+		return nil, fmt.Errorf("private key does not match address %s: %w", address, err)
+	}
+
+	return serializeWitness(witness)
+}
+
+func verifyBip322ToSign(toSign *wire.MsgTx, pkScript []byte, fetcher txscript.PrevOutputFetcher) error {
+	vm, err := txscript.NewEngine(
+		pkScript,
+		toSign,
+		0,
+		txscript.StandardVerifyFlags,
+		nil,
+		txscript.NewTxSigHashes(toSign, fetcher),
+		0,
+		fetcher,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return vm.Execute()
+}
+
+// VerifyBip322Simple verifies BIP-322 simple signature of the message made by the
+// owner of the given address
+func VerifyBip322Simple(address btcutil.Address, message []byte, signature []byte) error {
+	switch address.(type) {
+	case *btcutil.AddressWitnessPubKeyHash, *btcutil.AddressTaproot:
+	default:
+		return fmt.Errorf("unsupported address type %T, only p2wpkh and p2tr addresses are supported", address)
+	}
+
+	pkScript, err := txscript.PayToAddrScript(address)
+
+	if err != nil {
+		return err
+	}
+
+	witness, err := deserializeWitness(signature)
+
+	if err != nil {
+		return fmt.Errorf("malformed signature: %w", err)
+	}
+
+	toSpend, err := bip322ToSpendTx(message, pkScript)
+
+	if err != nil {
+		return err
+	}
+
+	toSign := bip322ToSignTx(toSpend)
+	toSign.TxIn[0].Witness = witness
+
+	fetcher := txscript.NewCannedPrevOutputFetcher(pkScript, 0)
+
+	if err := verifyBip322ToSign(toSign, pkScript, fetcher); err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	return nil
+}
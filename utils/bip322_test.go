@@ -0,0 +1,118 @@
+package utils_test
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/babylonchain/btc-staker/utils"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/require"
+)
+
+// test vectors from https://github.com/bitcoin/bips/blob/master/bip-0322.mediawiki#test-vectors
+const (
+	bip322TestWif           = "L3VFeEujGtevx9w18HD1fhRbCH67Az2dpCymeRE1SoPK6XQtaN2k"
+	bip322TestP2wpkhAddress = "bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l"
+	bip322TestP2trAddress   = "bc1ppv609nr0vr25u07u95waq5lucwfm6tde4nydujnu8npg4q75mr5sxq8lt3"
+)
+
+func decodeBip322TestAddress(t *testing.T, address string) btcutil.Address {
+	addr, err := btcutil.DecodeAddress(address, &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	return addr
+}
+
+func TestBip322MessageHash(t *testing.T) {
+	require.Equal(t,
+		"c90c269c4f8fcbe6880f72a721ddfbf1914268a794cbb21cfafee13770ae19f1",
+		hex.EncodeToString(utils.Bip322MessageHash([]byte(""))[:]),
+	)
+	require.Equal(t,
+		"f0eb03b1a75ac6d9847f55c624a99169b5dccba2a31f5b23bea77ba270de0a7a",
+		hex.EncodeToString(utils.Bip322MessageHash([]byte("Hello World"))[:]),
+	)
+}
+
+func TestBip322P2wpkhVectors(t *testing.T) {
+	wif, err := btcutil.DecodeWIF(bip322TestWif)
+	require.NoError(t, err)
+
+	addr := decodeBip322TestAddress(t, bip322TestP2wpkhAddress)
+
+	tests := []struct {
+		message   string
+		signature string
+	}{
+		{
+			message:   "",
+			signature: "AkcwRAIgM2gBAQqvZX15ZiysmKmQpDrG83avLIT492QBzLnQIxYCIBaTpOaD20qRlEylyxFSeEA2ba9YOixpX8z46TSDtS40ASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI=",
+		},
+		{
+			message:   "Hello World",
+			signature: "AkcwRAIgZRfIY3p7/DoVTty6YZbWS71bc5Vct9p9Fia83eRmw2QCICK/ENGfwLtptFluMGs2KsqoNSk89pO7F29zJLUx9a/sASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI=",
+		},
+		{
+			// signatures are not unique, any valid signature is accepted. This
+			// one is made with plain RFC6979 nonce, without grinding for low R.
+			message:   "Hello World",
+			signature: "AkgwRQIhAOzyynlqt93lOKJr+wmmxIens//zPzl9tqIOua93wO6MAiBi5n5EyAcPScOjf1lAqIUIQtr3zKNeavYabHyR8eGhowEhAsfxIAMZZEKUPYWI4BruhAQjzFT8FSFSajuFwrDL1Yhy",
+		},
+	}
+
+	for _, tt := range tests {
+		signature, err := base64.StdEncoding.DecodeString(tt.signature)
+		require.NoError(t, err)
+
+		require.NoError(t, utils.VerifyBip322Simple(addr, []byte(tt.message), signature))
+
+		// signature of one message must not verify other message
+		require.Error(t, utils.VerifyBip322Simple(addr, []byte(tt.message+"!"), signature))
+	}
+
+	// btcec uses RFC6979 nonces without grinding for low R, so signature of
+	// the message matches the vector made the same way
+	signature, err := utils.SignBip322Simple(wif.PrivKey, addr, []byte("Hello World"))
+	require.NoError(t, err)
+	require.Equal(t, tests[2].signature, base64.StdEncoding.EncodeToString(signature))
+
+	signature, err = utils.SignBip322Simple(wif.PrivKey, addr, []byte(""))
+	require.NoError(t, err)
+	require.NoError(t, utils.VerifyBip322Simple(addr, []byte(""), signature))
+}
+
+func TestBip322P2trVectors(t *testing.T) {
+	wif, err := btcutil.DecodeWIF(bip322TestWif)
+	require.NoError(t, err)
+
+	addr := decodeBip322TestAddress(t, bip322TestP2trAddress)
+
+	// vector is signed with SIGHASH_ALL
+	signature, err := base64.StdEncoding.DecodeString(
+		"AUHd69PrJQEv+oKTfZ8l+WROBHuy9HKrbFCJu7U1iK2iiEy1vMU5EfMtjc+VSHM7aU0SDbak5IUZRVno2P5mjSafAQ==",
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, utils.VerifyBip322Simple(addr, []byte("Hello World"), signature))
+	require.Error(t, utils.VerifyBip322Simple(addr, []byte(""), signature))
+
+	// schnorr signatures of the vectors were made with random aux data, so
+	// created signatures are checked only by verification
+	for _, message := range []string{"", "Hello World"} {
+		signature, err := utils.SignBip322Simple(wif.PrivKey, addr, []byte(message))
+		require.NoError(t, err)
+		require.NoError(t, utils.VerifyBip322Simple(addr, []byte(message), signature))
+	}
+}
+
+func TestBip322WrongKey(t *testing.T) {
+	wif, err := btcutil.DecodeWIF(bip322TestWif)
+	require.NoError(t, err)
+
+	// address of other key
+	addr := decodeBip322TestAddress(t, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4")
+
+	_, err = utils.SignBip322Simple(wif.PrivKey, addr, []byte("Hello World"))
+	require.ErrorContains(t, err, "private key does not match address")
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"container/list"
+	"sync"
+)
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// LruCache is a concurrency safe map holding at most capacity entries. When
+// full, least recently used entry is evicted to make room for a new one, so
+// it must only hold values which can be recomputed or loaded again on miss.
+type LruCache[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[K]*list.Element
+}
+
+// NewLruCache creates cache holding at most capacity entries, capacity lower
+// than 1 is treated as 1
+func NewLruCache[K comparable, V any](capacity int) *LruCache[K, V] {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &LruCache[K, V]{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[K]*list.Element),
+	}
+}
+
+// Load returns value stored for key and marks it as most recently used
+func (c *LruCache[K, V]) Load(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, found := c.entries[key]
+
+	if !found {
+		var zero V
+		return zero, false
+	}
+
+	c.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry[K, V]).value, true
+}
+
+// Store sets value for key, evicting least recently used entry if cache is full
+func (c *LruCache[K, V]) Store(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, found := c.entries[key]; found {
+		elem.Value.(*lruEntry[K, V]).value = value
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	if c.order.Len() >= c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry[K, V]).key)
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value})
+}
+
+// Delete removes key from cache
+func (c *LruCache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, found := c.entries[key]; found {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+	}
+}
+
+// Len returns number of entries in cache
+func (c *LruCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
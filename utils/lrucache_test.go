@@ -0,0 +1,151 @@
+package utils_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/babylonchain/btc-staker/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func requireCached(t *testing.T, c *utils.LruCache[string, int], key string, expected int) {
+	value, found := c.Load(key)
+	require.True(t, found, "key %s not cached", key)
+	require.Equal(t, expected, value)
+}
+
+func requireNotCached(t *testing.T, c *utils.LruCache[string, int], key string) {
+	_, found := c.Load(key)
+	require.False(t, found, "key %s should be evicted", key)
+}
+
+func TestLruCacheEvictionOrder(t *testing.T) {
+	c := utils.NewLruCache[string, int](3)
+
+	c.Store("a", 1)
+	c.Store("b", 2)
+	c.Store("c", 3)
+	require.Equal(t, 3, c.Len())
+
+	// least recently stored entry is evicted first
+	c.Store("d", 4)
+	require.Equal(t, 3, c.Len())
+	requireNotCached(t, c, "a")
+
+	// loading entry makes it most recently used, so b is evicted instead of it
+	requireCached(t, c, "b", 2)
+	c.Store("e", 5)
+	requireNotCached(t, c, "c")
+	requireCached(t, c, "b", 2)
+	requireCached(t, c, "d", 4)
+	requireCached(t, c, "e", 5)
+
+	// loads above made e the most recently used entry, followed by d and b
+	c.Store("f", 6)
+	requireNotCached(t, c, "b")
+	c.Store("g", 7)
+	requireNotCached(t, c, "d")
+	requireCached(t, c, "e", 5)
+	requireCached(t, c, "f", 6)
+	requireCached(t, c, "g", 7)
+
+	// missed loads do not change the order
+	requireNotCached(t, c, "a")
+	c.Store("h", 8)
+	requireNotCached(t, c, "e")
+}
+
+func TestLruCacheUpdateExistingKey(t *testing.T) {
+	c := utils.NewLruCache[string, int](2)
+
+	c.Store("a", 1)
+	c.Store("b", 2)
+
+	// update replaces value without evicting anything and makes the key most
+	// recently used
+	c.Store("a", 10)
+	require.Equal(t, 2, c.Len())
+	requireCached(t, c, "b", 2)
+	c.Store("a", 11)
+
+	c.Store("c", 3)
+	require.Equal(t, 2, c.Len())
+	requireNotCached(t, c, "b")
+	requireCached(t, c, "a", 11)
+	requireCached(t, c, "c", 3)
+}
+
+func TestLruCacheDelete(t *testing.T) {
+	c := utils.NewLruCache[string, int](2)
+
+	c.Store("a", 1)
+	c.Store("b", 2)
+	c.Delete("a")
+	c.Delete("missing")
+	require.Equal(t, 1, c.Len())
+	requireNotCached(t, c, "a")
+
+	// deleted entry frees room, so no entry is evicted
+	c.Store("c", 3)
+	requireCached(t, c, "b", 2)
+	requireCached(t, c, "c", 3)
+}
+
+func TestLruCacheMinimalCapacity(t *testing.T) {
+	for _, capacity := range []int{-1, 0, 1} {
+		c := utils.NewLruCache[string, int](capacity)
+
+		c.Store("a", 1)
+		requireCached(t, c, "a", 1)
+
+		c.Store("b", 2)
+		require.Equal(t, 1, c.Len())
+		requireNotCached(t, c, "a")
+		requireCached(t, c, "b", 2)
+	}
+}
+
+func TestLruCacheConcurrentAccess(t *testing.T) {
+	const (
+		capacity   = 64
+		goroutines = 16
+		operations = 2000
+	)
+
+	c := utils.NewLruCache[string, int](capacity)
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+
+		go func(g int) {
+			defer wg.Done()
+
+			for i := 0; i < operations; i++ {
+				// keys are shared between goroutines, value is always derived
+				// from the key, so every hit must return it
+				n := (g*operations + i) % (capacity * 2)
+				key := fmt.Sprintf("key-%d", n)
+
+				switch i % 4 {
+				case 0, 1:
+					c.Store(key, n)
+				case 2:
+					if value, found := c.Load(key); found && value != n {
+						t.Errorf("key %s has value %d", key, value)
+					}
+				case 3:
+					c.Delete(key)
+				}
+
+				if l := c.Len(); l > capacity {
+					t.Errorf("cache holds %d entries, capacity is %d", l, capacity)
+				}
+			}
+		}(g)
+	}
+
+	wg.Wait()
+	require.LessOrEqual(t, c.Len(), capacity)
+}
@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// CustomNetworkParamsFileExt is extension of files which are accepted as network
+// name by GetBtcNetworkParams
+const CustomNetworkParamsFileExt = ".json"
+
+// CustomNetworkParams describes private bitcoin network i.e custom signet or
+// regtest with its own genesis block. Parameters are copied from Base network and
+// every provided field overrides the copied value.
+type CustomNetworkParams struct {
+	Name string `json:"name"`
+	// name of network known to GetBtcNetworkParams
+	Base string `json:"base"`
+	// hex encoded signet challenge script, network magic is derived from it
+	// unless NetMagic is provided
+	SignetChallenge string   `json:"signet_challenge,omitempty"`
+	NetMagic        *uint32  `json:"net_magic,omitempty"`
+	DefaultPort     *string  `json:"default_port,omitempty"`
+	DnsSeeds        []string `json:"dns_seeds,omitempty"`
+	// hex encoded serialized genesis block
+	GenesisBlock     string  `json:"genesis_block,omitempty"`
+	Bech32HRPSegwit  *string `json:"bech32_hrp_segwit,omitempty"`
+	PubKeyHashAddrID *byte   `json:"pubkey_hash_addr_id,omitempty"`
+	ScriptHashAddrID *byte   `json:"script_hash_addr_id,omitempty"`
+	PrivateKeyID     *byte   `json:"private_key_id,omitempty"`
+	CoinbaseMaturity *uint16 `json:"coinbase_maturity,omitempty"`
+}
+
+var (
+	customNetworksMu sync.RWMutex
+	customNetworks   = make(map[string]*chaincfg.Params)
+)
+
+func toDNSSeeds(hosts []string) []chaincfg.DNSSeed {
+	seeds := make([]chaincfg.DNSSeed, len(hosts))
+	for i, h := range hosts {
+		seeds[i] = chaincfg.DNSSeed{Host: h}
+	}
+	return seeds
+}
+
+// Params builds chain params of the custom network
+func (c *CustomNetworkParams) Params() (*chaincfg.Params, error) {
+	if c.Name == "" {
+		return nil, fmt.Errorf("custom network name must be provided")
+	}
+
+	base, err := GetBtcNetworkParams(c.Base)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid base network: %w", err)
+	}
+
+	params := *base
+
+	if c.SignetChallenge != "" {
+		challenge, err := hex.DecodeString(c.SignetChallenge)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid signet challenge: %w", err)
+		}
+
+		params = chaincfg.CustomSignetParams(challenge, toDNSSeeds(c.DnsSeeds))
+	}
+
+	params.Name = c.Name
+
+	if c.NetMagic != nil {
+		params.Net = wire.BitcoinNet(*c.NetMagic)
+	}
+
+	if c.DefaultPort != nil {
+		params.DefaultPort = *c.DefaultPort
+	}
+
+	if c.DnsSeeds != nil {
+		params.DNSSeeds = toDNSSeeds(c.DnsSeeds)
+	}
+
+	if c.GenesisBlock != "" {
+		blockBytes, err := hex.DecodeString(c.GenesisBlock)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid genesis block: %w", err)
+		}
+
+		var genesis wire.MsgBlock
+		if err := genesis.Deserialize(bytes.NewReader(blockBytes)); err != nil {
+			return nil, fmt.Errorf("invalid genesis block: %w", err)
+		}
+
+		genesisHash := genesis.BlockHash()
+		params.GenesisBlock = &genesis
+		params.GenesisHash = &genesisHash
+		// checkpoints of the base network are not part of the custom chain
+		params.Checkpoints = nil
+	}
+
+	if c.Bech32HRPSegwit != nil {
+		params.Bech32HRPSegwit = *c.Bech32HRPSegwit
+	}
+
+	if c.PubKeyHashAddrID != nil {
+		params.PubKeyHashAddrID = *c.PubKeyHashAddrID
+	}
+
+	if c.ScriptHashAddrID != nil {
+		params.ScriptHashAddrID = *c.ScriptHashAddrID
+	}
+
+	if c.PrivateKeyID != nil {
+		params.PrivateKeyID = *c.PrivateKeyID
+	}
+
+	if c.CoinbaseMaturity != nil {
+		params.CoinbaseMaturity = *c.CoinbaseMaturity
+	}
+
+	return &params, nil
+}
+
+// LoadCustomNetworkParams reads custom network from json file and registers
+// it, so that it can be later referred to by its name and its addresses can
+// be decoded
+func LoadCustomNetworkParams(path string) (*chaincfg.Params, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read network params file: %w", err)
+	}
+
+	var custom CustomNetworkParams
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&custom); err != nil {
+		return nil, fmt.Errorf("invalid network params file %s: %w", path, err)
+	}
+
+	params, err := custom.Params()
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid network params file %s: %w", path, err)
+	}
+
+	if err := RegisterCustomNetworkParams(params); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// RegisterCustomNetworkParams makes network known to GetBtcNetworkParams and to
+// address decoding. Network registered before under the same name is replaced.
+func RegisterCustomNetworkParams(params *chaincfg.Params) error {
+	if builtin, err := GetBtcNetworkParams(params.Name); err == nil && !isCustomNetwork(builtin) {
+		return fmt.Errorf("custom network can't use name of known network %s", params.Name)
+	}
+
+	// networks sharing magic with already registered network i.e signet with
+	// default challenge, use its address encoding
+	if err := chaincfg.Register(params); err != nil && !errors.Is(err, chaincfg.ErrDuplicateNet) {
+		return fmt.Errorf("failed to register network %s: %w", params.Name, err)
+	}
+
+	customNetworksMu.Lock()
+	defer customNetworksMu.Unlock()
+	customNetworks[params.Name] = params
+
+	return nil
+}
+
+func isCustomNetwork(params *chaincfg.Params) bool {
+	customNetworksMu.RLock()
+	defer customNetworksMu.RUnlock()
+	return customNetworks[params.Name] == params
+}
+
+func customBtcNetworkParams(network string) (*chaincfg.Params, error) {
+	customNetworksMu.RLock()
+	params, found := customNetworks[network]
+	customNetworksMu.RUnlock()
+
+	if found {
+		return params, nil
+	}
+
+	if strings.HasSuffix(network, CustomNetworkParamsFileExt) {
+		return LoadCustomNetworkParams(network)
+	}
+
+	return nil, fmt.Errorf("unknown network %s", network)
+}
@@ -0,0 +1,41 @@
+package utils
+
+import "sync"
+
+// ParallelForEach calls fn for every item using at most workers goroutines and
+// returns after all started calls finish. Items which were not started yet are
+// skipped once quit is closed.
+func ParallelForEach[T any](items []T, workers int, quit <-chan struct{}, fn func(T)) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	if workers > len(items) {
+		workers = len(items)
+	}
+
+	work := make(chan T)
+	var wg sync.WaitGroup
+
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for item := range work {
+				fn(item)
+			}
+		}()
+	}
+
+feed:
+	for _, item := range items {
+		select {
+		case work <- item:
+		case <-quit:
+			break feed
+		}
+	}
+
+	close(work)
+	wg.Wait()
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrCallTimedOut is returned by CallWithContext if call does not finish before
+// context is done
+var ErrCallTimedOut = errors.New("call timed out")
+
+// CallWithContext runs call, which itself can't be cancelled, and returns as soon
+// as it finishes or ctx is done. In the latter case call keeps running in the
+// background and its result is discarded.
+func CallWithContext[T any](ctx context.Context, call func() (T, error)) (T, error) {
+	type result struct {
+		value T
+		err   error
+	}
+
+	// buffered, so that abandoned call does not block forever
+	done := make(chan result, 1)
+
+	go func() {
+		value, err := call()
+		done <- result{value: value, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero T
+		return zero, fmt.Errorf("%w: %w", ErrCallTimedOut, ctx.Err())
+	}
+}
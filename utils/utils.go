@@ -21,7 +21,8 @@ func GetBtcNetworkParams(network string) (*chaincfg.Params, error) {
 	case "signet":
 		return &chaincfg.SigNetParams, nil
 	default:
-		return nil, fmt.Errorf("unknown network %s", network)
+		// custom network registered before or loaded from file
+		return customBtcNetworkParams(network)
 	}
 }
 
@@ -0,0 +1,167 @@
+package walletcontroller
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/babylonchain/btc-staker/types"
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+const (
+	// label of addresses imported by the staker
+	trackedAddressLabel = "btc-staker-change"
+)
+
+type importResult struct {
+	Success bool `json:"success"`
+	Error   *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func rawParams(params ...interface{}) ([]json.RawMessage, error) {
+	raw := make([]json.RawMessage, len(params))
+
+	for i, p := range params {
+		marshalled, err := json.Marshal(p)
+
+		if err != nil {
+			return nil, err
+		}
+
+		raw[i] = marshalled
+	}
+
+	return raw, nil
+}
+
+// importTimestamp returns timestamp from which wallet rescans the chain after
+// import, zero time means that there are no past transactions to rediscover
+func importTimestamp(since time.Time) interface{} {
+	if since.IsZero() {
+		return "now"
+	}
+	return since.Unix()
+}
+
+func (w *RpcWalletController) descriptorWallet() (bool, error) {
+	res, err := w.RawRequest("getwalletinfo", nil)
+
+	if err != nil {
+		return false, err
+	}
+
+	var info struct {
+		// not returned by bitcoind versions without descriptor wallets
+		Descriptors bool `json:"descriptors"`
+	}
+
+	if err := json.Unmarshal(res, &info); err != nil {
+		return false, err
+	}
+
+	return info.Descriptors, nil
+}
+
+func (w *RpcWalletController) importAddress(address btcutil.Address, since time.Time) error {
+	descriptors, err := w.descriptorWallet()
+
+	if err != nil {
+		return err
+	}
+
+	var method string
+	var request map[string]interface{}
+
+	if descriptors {
+		descInfo, err := w.GetDescriptorInfo(fmt.Sprintf("addr(%s)", address.EncodeAddress()))
+
+		if err != nil {
+			return err
+		}
+
+		method = "importdescriptors"
+		request = map[string]interface{}{
+			"desc":      fmt.Sprintf("%s#%s", descInfo.Descriptor, descInfo.Checksum),
+			"timestamp": importTimestamp(since),
+			"label":     trackedAddressLabel,
+		}
+	} else {
+		method = "importmulti"
+		request = map[string]interface{}{
+			"scriptPubKey": map[string]string{"address": address.EncodeAddress()},
+			"timestamp":    importTimestamp(since),
+			"watchonly":    true,
+			"label":        trackedAddressLabel,
+		}
+	}
+
+	params, err := rawParams([]interface{}{request})
+
+	if err != nil {
+		return err
+	}
+
+	res, err := w.RawRequest(method, params)
+
+	if err != nil {
+		return err
+	}
+
+	var results []importResult
+	if err := json.Unmarshal(res, &results); err != nil {
+		return err
+	}
+
+	if len(results) != 1 {
+		return fmt.Errorf("unexpected number of %s results: %d", method, len(results))
+	}
+
+	if !results[0].Success {
+		msg := "unknown error"
+		if results[0].Error != nil {
+			msg = results[0].Error.Message
+		}
+		return fmt.Errorf("%s of address %s failed: %s", method, address.EncodeAddress(), msg)
+	}
+
+	return nil
+}
+
+// TrackAddress makes sure that bitcoind wallet tracks outputs paying to given
+// address, so that they are rediscovered by rescans e.g after wallet is restored
+// from backup. Addresses which are not known to the wallet are imported as watch
+// only, with rescan starting at since. Returns true if address was imported.
+// btcwallet tracks all addresses of its keys and imported keys, so this is no-op
+// for btcwallet.
+func (w *RpcWalletController) TrackAddress(address btcutil.Address, since time.Time) (bool, error) {
+	if w.backend != types.BitcoindWalletBackend {
+		return false, nil
+	}
+
+	encoded := address.EncodeAddress()
+
+	if _, tracked := w.trackedAddresses.Load(encoded); tracked {
+		return false, nil
+	}
+
+	info, err := w.GetAddressInfo(encoded)
+
+	if err != nil {
+		return false, err
+	}
+
+	if info.IsMine || info.IsWatchOnly {
+		w.trackedAddresses.Store(encoded, struct{}{})
+		return false, nil
+	}
+
+	if err := w.importAddress(address, since); err != nil {
+		return false, err
+	}
+
+	w.trackedAddresses.Store(encoded, struct{}{})
+	return true, nil
+}
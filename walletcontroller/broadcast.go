@@ -0,0 +1,257 @@
+package walletcontroller
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/peer"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	broadcastUserAgentName    = "btc-staker"
+	broadcastUserAgentVersion = "0.1.0"
+
+	// maximum size of error response body read from http endpoints
+	maxBroadcastResponseBytes = 4096
+)
+
+// BroadcastEndpoint is additional point to which transactions sent by the
+// daemon are announced
+type BroadcastEndpoint interface {
+	// Name identifies endpoint in logs and metrics, it must not contain
+	// credentials
+	Name() string
+	Broadcast(ctx context.Context, tx *wire.MsgTx) error
+}
+
+func serializeTxHex(tx *wire.MsgTx) (string, error) {
+	var buf bytes.Buffer
+
+	if err := tx.Serialize(&buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf.Bytes()), nil
+}
+
+func postBroadcast(ctx context.Context, req *http.Request) ([]byte, error) {
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBroadcastResponseBytes))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return body, fmt.Errorf("endpoint responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return body, nil
+}
+
+// bitcoindEndpoint sends transactions through sendrawtransaction rpc of
+// additional bitcoind node
+type bitcoindEndpoint struct {
+	url  string
+	name string
+}
+
+func (e *bitcoindEndpoint) Name() string {
+	return e.name
+}
+
+func (e *bitcoindEndpoint) Broadcast(ctx context.Context, tx *wire.MsgTx) error {
+	txHex, err := serializeTxHex(tx)
+
+	if err != nil {
+		return err
+	}
+
+	reqBody, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "1.0",
+		"id":      broadcastUserAgentName,
+		"method":  "sendrawtransaction",
+		"params":  []interface{}{txHex},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(reqBody))
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// bitcoind responds with status 500 to failed rpc calls, rpc error is more
+	// useful than the status
+	respBody, postErr := postBroadcast(ctx, req)
+
+	var resp struct {
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		if postErr != nil {
+			return postErr
+		}
+		return fmt.Errorf("invalid response from bitcoind: %w", err)
+	}
+
+	if resp.Error != nil {
+		return fmt.Errorf("bitcoind rpc error %d: %s", resp.Error.Code, resp.Error.Message)
+	}
+
+	return postErr
+}
+
+// esploraEndpoint posts transactions to Esplora api
+type esploraEndpoint struct {
+	url string
+}
+
+func (e *esploraEndpoint) Name() string {
+	return e.url
+}
+
+func (e *esploraEndpoint) Broadcast(ctx context.Context, tx *wire.MsgTx) error {
+	txHex, err := serializeTxHex(tx)
+
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.url+"/tx", strings.NewReader(txHex))
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "text/plain")
+
+	_, err = postBroadcast(ctx, req)
+	return err
+}
+
+// peerEndpoint announces transactions directly to bitcoin p2p node. Connection
+// is established only for the time of the broadcast.
+type peerEndpoint struct {
+	addr string
+	net  *chaincfg.Params
+}
+
+func (e *peerEndpoint) Name() string {
+	return "peer:" + e.addr
+}
+
+func (e *peerEndpoint) Broadcast(ctx context.Context, tx *wire.MsgTx) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", e.addr)
+
+	if err != nil {
+		return err
+	}
+
+	verAck := make(chan struct{})
+
+	// remote address is used instead of configured one, as p2p address of the
+	// peer must be an ip address
+	p, err := peer.NewOutboundPeer(&peer.Config{
+		UserAgentName:    broadcastUserAgentName,
+		UserAgentVersion: broadcastUserAgentVersion,
+		ChainParams:      e.net,
+		Listeners: peer.MessageListeners{
+			OnVerAck: func(_ *peer.Peer, _ *wire.MsgVerAck) {
+				close(verAck)
+			},
+		},
+	}, conn.RemoteAddr().String())
+
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	p.AssociateConnection(conn)
+
+	defer func() {
+		p.Disconnect()
+		p.WaitForDisconnect()
+	}()
+
+	select {
+	case <-verAck:
+	case <-ctx.Done():
+		return fmt.Errorf("handshake with peer not finished: %w", ctx.Err())
+	}
+
+	sent := make(chan struct{}, 1)
+	p.QueueMessage(tx, sent)
+
+	select {
+	case <-sent:
+	case <-ctx.Done():
+		return fmt.Errorf("transaction not sent to peer: %w", ctx.Err())
+	}
+
+	// p2p protocol does not confirm acceptance of the transaction, so success
+	// only means that transaction was delivered to the peer
+	return nil
+}
+
+// NewBroadcastEndpoints returns additional broadcast endpoints defined in config
+func NewBroadcastEndpoints(cfg *scfg.BroadcastConfig, net *chaincfg.Params) ([]BroadcastEndpoint, error) {
+	var endpoints []BroadcastEndpoint
+
+	for _, rawUrl := range cfg.BitcoindUrls {
+		u, err := url.Parse(rawUrl)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid bitcoind url: %w", err)
+		}
+
+		endpoints = append(endpoints, &bitcoindEndpoint{
+			url:  rawUrl,
+			name: "bitcoind:" + u.Host,
+		})
+	}
+
+	for _, rawUrl := range cfg.EsploraUrls {
+		endpoints = append(endpoints, &esploraEndpoint{
+			url: strings.TrimSuffix(rawUrl, "/"),
+		})
+	}
+
+	for _, addr := range cfg.Peers {
+		endpoints = append(endpoints, &peerEndpoint{
+			addr: addr,
+			net:  net,
+		})
+	}
+
+	return endpoints, nil
+}
@@ -0,0 +1,273 @@
+package walletcontroller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/babylonchain/btc-staker/utils"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/btcd/wire"
+	notifier "github.com/lightningnetwork/lnd/chainntnfs"
+)
+
+const (
+	// raw blocks are limited by consensus to 4MB
+	maxEsploraResponseBytes = 10 * 1024 * 1024
+)
+
+// ChainBackend is secondary source of chain data, used when node connected to
+// the wallet is unavailable. Wallet specific operations are not supported.
+type ChainBackend interface {
+	// Name identifies backend in logs and metrics, it must not contain
+	// credentials
+	Name() string
+	BestHeight(ctx context.Context) (int64, error)
+	SendRawTransaction(ctx context.Context, tx *wire.MsgTx) (*chainhash.Hash, error)
+	TxDetails(ctx context.Context, txHash *chainhash.Hash, pkScript []byte) (*notifier.TxConfirmation, TxStatus, error)
+	GetBlockHash(ctx context.Context, blockHeight int64) (*chainhash.Hash, error)
+	GetBlock(ctx context.Context, blockHash *chainhash.Hash) (*wire.MsgBlock, error)
+}
+
+// NewChainBackend creates secondary backend defined in failover config
+func NewChainBackend(cfg *scfg.FailoverConfig) (ChainBackend, error) {
+	switch cfg.Backend {
+	case scfg.FailoverBackendBitcoind:
+		return newBitcoindBackend(cfg.Url)
+	case scfg.FailoverBackendEsplora:
+		return &esploraBackend{url: strings.TrimSuffix(cfg.Url, "/")}, nil
+	default:
+		return nil, fmt.Errorf("unknown failover backend %s", cfg.Backend)
+	}
+}
+
+// bitcoindBackend queries bitcoind node with enabled transaction index
+type bitcoindBackend struct {
+	client *rpcclient.Client
+	name   string
+}
+
+func newBitcoindBackend(rawUrl string) (*bitcoindBackend, error) {
+	u, err := url.Parse(rawUrl)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid bitcoind url: %w", err)
+	}
+
+	pass, _ := u.User.Password()
+
+	client, err := rpcclient.New(&rpcclient.ConnConfig{
+		Host:                u.Host,
+		User:                u.User.Username(),
+		Pass:                pass,
+		DisableTLS:          u.Scheme != "https",
+		DisableConnectOnNew: true,
+		HTTPPostMode:        true,
+	}, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &bitcoindBackend{
+		client: client,
+		name:   "bitcoind:" + u.Host,
+	}, nil
+}
+
+func (b *bitcoindBackend) Name() string {
+	return b.name
+}
+
+func (b *bitcoindBackend) BestHeight(ctx context.Context) (int64, error) {
+	return utils.CallWithContext(ctx, b.client.GetBlockCount)
+}
+
+func (b *bitcoindBackend) SendRawTransaction(ctx context.Context, tx *wire.MsgTx) (*chainhash.Hash, error) {
+	return utils.CallWithContext(ctx, func() (*chainhash.Hash, error) {
+		return b.client.SendRawTransaction(tx, false)
+	})
+}
+
+func (b *bitcoindBackend) TxDetails(ctx context.Context, txHash *chainhash.Hash, pkScript []byte) (*notifier.TxConfirmation, TxStatus, error) {
+	req, err := notifier.NewConfRequest(txHash, pkScript)
+
+	if err != nil {
+		return nil, TxNotFound, err
+	}
+
+	res, err := utils.CallWithContext(ctx, func() (txDetailsResult, error) {
+		details, state, err := notifier.ConfDetailsFromTxIndex(b.client, req, txNotFoundErrMsgBitcoind)
+		return txDetailsResult{details: details, state: state}, err
+	})
+
+	if err != nil {
+		return nil, TxNotFound, err
+	}
+
+	return res.details, nofitierStateToWalletState(res.state), nil
+}
+
+func (b *bitcoindBackend) GetBlockHash(ctx context.Context, blockHeight int64) (*chainhash.Hash, error) {
+	return utils.CallWithContext(ctx, func() (*chainhash.Hash, error) {
+		return b.client.GetBlockHash(blockHeight)
+	})
+}
+
+func (b *bitcoindBackend) GetBlock(ctx context.Context, blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+	return utils.CallWithContext(ctx, func() (*wire.MsgBlock, error) {
+		return b.client.GetBlock(blockHash)
+	})
+}
+
+// esploraBackend queries Esplora api
+type esploraBackend struct {
+	url string
+}
+
+var errEsploraNotFound = errors.New("not found")
+
+func (e *esploraBackend) Name() string {
+	return e.url
+}
+
+func (e *esploraBackend) get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.url+path, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxEsploraResponseBytes))
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return body, nil
+	case http.StatusNotFound:
+		return nil, errEsploraNotFound
+	default:
+		return nil, fmt.Errorf("endpoint responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+}
+
+func (e *esploraBackend) BestHeight(ctx context.Context) (int64, error) {
+	body, err := e.get(ctx, "/blocks/tip/height")
+
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(strings.TrimSpace(string(body)), 10, 64)
+}
+
+func (e *esploraBackend) SendRawTransaction(ctx context.Context, tx *wire.MsgTx) (*chainhash.Hash, error) {
+	endpoint := &esploraEndpoint{url: e.url}
+
+	if err := endpoint.Broadcast(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	txHash := tx.TxHash()
+	return &txHash, nil
+}
+
+func (e *esploraBackend) TxDetails(ctx context.Context, txHash *chainhash.Hash, _ []byte) (*notifier.TxConfirmation, TxStatus, error) {
+	body, err := e.get(ctx, "/tx/"+txHash.String()+"/status")
+
+	if errors.Is(err, errEsploraNotFound) {
+		return nil, TxNotFound, nil
+	}
+
+	if err != nil {
+		return nil, TxNotFound, err
+	}
+
+	var status struct {
+		Confirmed   bool   `json:"confirmed"`
+		BlockHeight uint32 `json:"block_height"`
+		BlockHash   string `json:"block_hash"`
+	}
+
+	if err := json.Unmarshal(body, &status); err != nil {
+		return nil, TxNotFound, fmt.Errorf("invalid response from esplora: %w", err)
+	}
+
+	if !status.Confirmed {
+		return nil, TxInMemPool, nil
+	}
+
+	blockHash, err := chainhash.NewHashFromStr(status.BlockHash)
+
+	if err != nil {
+		return nil, TxNotFound, fmt.Errorf("invalid block hash from esplora: %w", err)
+	}
+
+	block, err := e.GetBlock(ctx, blockHash)
+
+	if err != nil {
+		return nil, TxNotFound, err
+	}
+
+	for i, tx := range block.Transactions {
+		if tx.TxHash() != *txHash {
+			continue
+		}
+
+		return &notifier.TxConfirmation{
+			BlockHash:   blockHash,
+			BlockHeight: status.BlockHeight,
+			TxIndex:     uint32(i),
+			Tx:          tx,
+			Block:       block,
+		}, TxInChain, nil
+	}
+
+	return nil, TxNotFound, fmt.Errorf("transaction %s not found in block %s", txHash, blockHash)
+}
+
+func (e *esploraBackend) GetBlockHash(ctx context.Context, blockHeight int64) (*chainhash.Hash, error) {
+	body, err := e.get(ctx, "/block-height/"+strconv.FormatInt(blockHeight, 10))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return chainhash.NewHashFromStr(strings.TrimSpace(string(body)))
+}
+
+func (e *esploraBackend) GetBlock(ctx context.Context, blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+	body, err := e.get(ctx, "/block/"+blockHash.String()+"/raw")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var block wire.MsgBlock
+
+	if err := block.Deserialize(bytes.NewReader(body)); err != nil {
+		return nil, fmt.Errorf("invalid block from esplora: %w", err)
+	}
+
+	return &block, nil
+}
@@ -0,0 +1,34 @@
+package walletcontroller
+
+import (
+	"encoding/json"
+
+	"github.com/babylonchain/btc-staker/types"
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+// NewChangeAddress returns fresh wallet address intended for receiving change
+func (w *RpcWalletController) NewChangeAddress() (btcutil.Address, error) {
+	if w.backend == types.BtcwalletWalletBackend {
+		return rpcCall(w, func() (btcutil.Address, error) {
+			return w.Client.GetRawChangeAddress("default")
+		})
+	}
+
+	// bitcoind expects address type instead of account as first parameter, so
+	// wallet default change type is used by not providing any parameters
+	res, err := rpcCall(w, func() (json.RawMessage, error) {
+		return w.RawRequest("getrawchangeaddress", nil)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var encoded string
+	if err := json.Unmarshal(res, &encoded); err != nil {
+		return nil, err
+	}
+
+	return btcutil.DecodeAddress(encoded, w.netParams)
+}
@@ -1,14 +1,19 @@
 package walletcontroller
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
+	"sync"
+	"time"
 
 	"github.com/babylonchain/btc-staker/stakercfg"
 	scfg "github.com/babylonchain/btc-staker/stakercfg"
 	"github.com/babylonchain/btc-staker/types"
+	"github.com/babylonchain/btc-staker/utils"
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/txsort"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/rpcclient"
@@ -21,7 +26,29 @@ type RpcWalletController struct {
 	*rpcclient.Client
 	walletPassphrase string
 	network          string
+	netParams        *chaincfg.Params
 	backend          types.SupportedWalletBackend
+	deterministicTxs bool
+	// 0 means wallet calls are never abandoned
+	rpcTimeout time.Duration
+
+	filterMu   sync.RWMutex
+	utxoFilter UtxoFilter
+
+	inputWeights *InputWeightRegistry
+
+	// addresses already known to be tracked by the wallet
+	trackedAddresses *utils.LruCache[string, struct{}]
+
+	// which unconfirmed outputs can fund transactions, one of
+	// UnconfirmedFunding* values
+	unconfirmedFunding string
+	// whether wallet transactions spend wallet outputs, by transaction hash
+	ownTxs *utils.LruCache[chainhash.Hash, bool]
+
+	// nil until package relay support of the node is checked
+	packageRelayMu sync.Mutex
+	packageRelay   *bool
 }
 
 var _ WalletController = (*RpcWalletController)(nil)
@@ -32,18 +59,34 @@ const (
 )
 
 func NewRpcWalletController(scfg *stakercfg.Config) (*RpcWalletController, error) {
-	return NewRpcWalletControllerFromArgs(
+	wc, err := NewRpcWalletControllerFromArgs(
 		scfg.WalletRpcConfig.Host,
 		scfg.WalletRpcConfig.User,
 		scfg.WalletRpcConfig.Pass,
 		scfg.ActiveNetParams.Name,
 		scfg.WalletConfig.WalletPass,
+		scfg.WalletConfig.DeterministicTxs,
 		scfg.BtcNodeBackendConfig.ActiveWalletBackend,
 		&scfg.ActiveNetParams,
 		scfg.WalletRpcConfig.DisableTls,
 		scfg.WalletRpcConfig.RawRPCWalletCert,
 		scfg.WalletRpcConfig.RPCWalletCert,
+		scfg.WalletRpcConfig.RpcTimeout,
 	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := registerConfiguredInputWeights(wc.inputWeights, scfg.WalletConfig); err != nil {
+		return nil, err
+	}
+
+	wc.unconfirmedFunding = scfg.WalletConfig.UnconfirmedFunding
+	wc.trackedAddresses = utils.NewLruCache[string, struct{}](scfg.CacheConfig.Addresses)
+	wc.ownTxs = utils.NewLruCache[chainhash.Hash, bool](scfg.CacheConfig.WalletTxs)
+
+	return wc, nil
 }
 
 func NewRpcWalletControllerFromArgs(
@@ -52,10 +95,12 @@ func NewRpcWalletControllerFromArgs(
 	pass string,
 	network string,
 	walletPassphrase string,
+	deterministicTxs bool,
 	nodeBackend types.SupportedWalletBackend,
 	params *chaincfg.Params,
 	disableTls bool,
 	rawWalletCert string, walletCertFilePath string,
+	rpcTimeout time.Duration,
 ) (*RpcWalletController, error) {
 
 	connCfg := &rpcclient.ConnConfig{
@@ -83,11 +128,20 @@ func NewRpcWalletControllerFromArgs(
 		return nil, err
 	}
 
+	cacheCfg := scfg.DefaultCacheConfig()
+
 	return &RpcWalletController{
-		Client:           rpcclient,
-		walletPassphrase: walletPassphrase,
-		network:          params.Name,
-		backend:          nodeBackend,
+		Client:             rpcclient,
+		walletPassphrase:   walletPassphrase,
+		network:            params.Name,
+		netParams:          params,
+		backend:            nodeBackend,
+		deterministicTxs:   deterministicTxs,
+		rpcTimeout:         rpcTimeout,
+		inputWeights:       NewInputWeightRegistry(),
+		unconfirmedFunding: UnconfirmedFundingOwn,
+		trackedAddresses:   utils.NewLruCache[string, struct{}](cacheCfg.Addresses),
+		ownTxs:             utils.NewLruCache[chainhash.Hash, bool](cacheCfg.WalletTxs),
 	}, nil
 }
 
@@ -95,6 +149,65 @@ func (w *RpcWalletController) UnlockWallet(timoutSec int64) error {
 	return w.WalletPassphrase(w.walletPassphrase, timoutSec)
 }
 
+// WalletLocked checks whether wallet is locked without unlocking it. Unencrypted
+// wallets are never locked.
+func (w *RpcWalletController) WalletLocked() (bool, error) {
+	if w.backend == types.BtcwalletWalletBackend {
+		res, err := w.RawRequest("walletislocked", nil)
+
+		if err != nil {
+			return false, err
+		}
+
+		var locked bool
+		if err := json.Unmarshal(res, &locked); err != nil {
+			return false, err
+		}
+
+		return locked, nil
+	}
+
+	info, err := w.GetWalletInfo()
+
+	if err != nil {
+		return false, err
+	}
+
+	// bitcoind does not return unlocked_until for unencrypted wallets
+	if info.UnlockedUntil == nil {
+		return false, nil
+	}
+
+	return int64(*info.UnlockedUntil) <= time.Now().Unix(), nil
+}
+
+// ChainSyncStatus returns sync status of the node connected to the wallet.
+// getblockchaininfo is called directly, as rpcclient.GetBlockChainInfo requires
+// querying backend version, which is not supported by btcwallet.
+func (w *RpcWalletController) ChainSyncStatus() (*ChainSyncStatus, error) {
+	res, err := w.RawRequest("getblockchaininfo", nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var info struct {
+		Blocks               int32 `json:"blocks"`
+		Headers              int32 `json:"headers"`
+		InitialBlockDownload bool  `json:"initialblockdownload"`
+	}
+
+	if err := json.Unmarshal(res, &info); err != nil {
+		return nil, err
+	}
+
+	return &ChainSyncStatus{
+		Blocks:               info.Blocks,
+		Headers:              info.Headers,
+		InitialBlockDownload: info.InitialBlockDownload,
+	}, nil
+}
+
 func (w *RpcWalletController) AddressPublicKey(address btcutil.Address) (*btcec.PublicKey, error) {
 	privKey, err := w.DumpPrivKey(address)
 
@@ -119,12 +232,47 @@ func (w *RpcWalletController) NetworkName() string {
 	return w.network
 }
 
+func (w *RpcWalletController) SetUtxoFilter(filter UtxoFilter) {
+	w.filterMu.Lock()
+	defer w.filterMu.Unlock()
+	w.utxoFilter = filter
+}
+
+func (w *RpcWalletController) InputWeights() *InputWeightRegistry {
+	return w.inputWeights
+}
+
+func (w *RpcWalletController) filterUtxos(utxos []Utxo) []Utxo {
+	w.filterMu.RLock()
+	filter := w.utxoFilter
+	w.filterMu.RUnlock()
+
+	if filter == nil {
+		return utxos
+	}
+
+	allowed := utxos[:0]
+	for i := range utxos {
+		if filter(&utxos[i]) {
+			allowed = append(allowed, utxos[i])
+		}
+	}
+
+	return allowed
+}
+
+// maxUtxoConfirmations is upper bound of confirmations of listed outputs, the
+// same as default of listunspent
+const maxUtxoConfirmations = 9999999
+
 func (w *RpcWalletController) CreateTransaction(
 	outputs []*wire.TxOut,
 	feeRatePerKb btcutil.Amount,
-	changeAddres btcutil.Address) (*wire.MsgTx, error) {
+	changeAddres btcutil.Address,
+	minConfirmations uint32,
+) (*wire.MsgTx, error) {
 
-	utxoResults, err := w.ListUnspent()
+	utxoResults, err := w.ListUnspentMinMax(int(minConfirmations), maxUtxoConfirmations)
 
 	if err != nil {
 		return nil, err
@@ -136,9 +284,26 @@ func (w *RpcWalletController) CreateTransaction(
 		return nil, err
 	}
 
-	// sort utxos by amount from highest to lowest, this is effectively strategy of using
-	// largest inputs first
-	sort.Sort(sort.Reverse(byAmount(utxos)))
+	utxos = w.filterUtxos(utxos)
+
+	if minConfirmations == 0 && w.unconfirmedFunding != UnconfirmedFundingAny {
+		if err := w.tagUtxoSources(utxos); err != nil {
+			return nil, err
+		}
+
+		utxos = withoutForeignUnconfirmed(utxos)
+	}
+
+	if w.deterministicTxs {
+		// ListUnspent does not guarantee any particular ordering, so ties between
+		// utxos with the same amount are broken by outpoint to make input
+		// selection independent of the wallet internals
+		sort.Sort(sort.Reverse(byAmountAndOutpoint(utxos)))
+	} else {
+		// sort utxos by amount from highest to lowest, this is effectively strategy of using
+		// largest inputs first
+		sort.Sort(sort.Reverse(byAmount(utxos)))
+	}
 
 	changeScript, err := txscript.PayToAddrScript(changeAddres)
 
@@ -146,12 +311,18 @@ func (w *RpcWalletController) CreateTransaction(
 		return nil, err
 	}
 
-	tx, err := buildTxFromOutputs(utxos, outputs, feeRatePerKb, changeScript)
+	tx, err := buildTxFromOutputs(w.inputWeights, utxos, outputs, feeRatePerKb, changeScript)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if w.deterministicTxs {
+		// transaction is not signed yet, so it is safe to re-order inputs and
+		// outputs in place
+		txsort.InPlaceSort(tx)
+	}
+
 	return tx, err
 }
 
@@ -159,8 +330,9 @@ func (w *RpcWalletController) CreateAndSignTx(
 	outputs []*wire.TxOut,
 	feeRatePerKb btcutil.Amount,
 	changeAddress btcutil.Address,
+	minConfirmations uint32,
 ) (*wire.MsgTx, error) {
-	tx, err := w.CreateTransaction(outputs, feeRatePerKb, changeAddress)
+	tx, err := w.CreateTransaction(outputs, feeRatePerKb, changeAddress, minConfirmations)
 
 	if err != nil {
 		return nil, err
@@ -181,19 +353,51 @@ func (w *RpcWalletController) CreateAndSignTx(
 	return fundedTx, nil
 }
 
+type signResult struct {
+	tx     *wire.MsgTx
+	signed bool
+}
+
 func (w *RpcWalletController) SignRawTransaction(tx *wire.MsgTx) (*wire.MsgTx, bool, error) {
+	var sign func(tx *wire.MsgTx) (*wire.MsgTx, bool, error)
+
 	switch w.backend {
 	case types.BitcoindWalletBackend:
-		return w.Client.SignRawTransactionWithWallet(tx)
+		sign = w.Client.SignRawTransactionWithWallet
 	case types.BtcwalletWalletBackend:
-		return w.Client.SignRawTransaction(tx)
+		sign = w.Client.SignRawTransaction
 	default:
 		return nil, false, fmt.Errorf("invalid bitcoin backend")
 	}
+
+	res, err := rpcCall(w, func() (signResult, error) {
+		signedTx, signed, err := sign(tx)
+		return signResult{tx: signedTx, signed: signed}, err
+	})
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	if res.signed {
+		return res.tx, true, nil
+	}
+
+	// wallet rpc may leave some inputs unsigned e.g taproot inputs in legacy
+	// wallets, sign them with wallet keys
+	signed, err := w.signUnsignedInputs(res.tx)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	return res.tx, signed, nil
 }
 
 func (w *RpcWalletController) SendRawTransaction(tx *wire.MsgTx, allowHighFees bool) (*chainhash.Hash, error) {
-	return w.Client.SendRawTransaction(tx, allowHighFees)
+	return rpcCall(w, func() (*chainhash.Hash, error) {
+		return w.Client.SendRawTransaction(tx, allowHighFees)
+	})
 }
 
 func (w *RpcWalletController) ListOutputs(onlySpendable bool) ([]Utxo, error) {
@@ -229,14 +433,23 @@ func nofitierStateToWalletState(state notifier.TxConfStatus) TxStatus {
 	}
 }
 
+type txDetailsResult struct {
+	details *notifier.TxConfirmation
+	state   notifier.TxConfStatus
+}
+
 func (w *RpcWalletController) getTxDetails(req notifier.ConfRequest, msg string) (*notifier.TxConfirmation, TxStatus, error) {
-	res, state, err := notifier.ConfDetailsFromTxIndex(w.Client, req, msg)
+	// lookup can issue several rpc calls, so timeout applies to all of them
+	res, err := rpcCall(w, func() (txDetailsResult, error) {
+		details, state, err := notifier.ConfDetailsFromTxIndex(w.Client, req, msg)
+		return txDetailsResult{details: details, state: state}, err
+	})
 
 	if err != nil {
 		return nil, TxNotFound, err
 	}
 
-	return res, nofitierStateToWalletState(state), nil
+	return res.details, nofitierStateToWalletState(res.state), nil
 }
 
 // Fetch info about transaction from mempool or blockchain, requires node to have enabled  transaction index
@@ -0,0 +1,236 @@
+package walletcontroller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	scfg "github.com/babylonchain/btc-staker/stakercfg"
+	"github.com/babylonchain/btc-staker/utils"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/btcd/wire"
+	notifier "github.com/lightningnetwork/lnd/chainntnfs"
+)
+
+// BackendSwitch describes change of backend used for broadcasts and
+// confirmation queries
+type BackendSwitch struct {
+	// true if secondary backend became active
+	ToSecondary bool
+	// name of secondary backend
+	Secondary string
+	Reason    string
+}
+
+// BackendHeights is result of comparison of primary and secondary backends
+type BackendHeights struct {
+	// -1 if primary node is unreachable
+	Primary         int64
+	Secondary       int64
+	SecondaryActive bool
+}
+
+// FailoverWalletController routes broadcasts and chain queries to secondary
+// backend when node connected to the wallet is unreachable or stalls. All other
+// calls are served by the primary wallet.
+type FailoverWalletController struct {
+	WalletController
+
+	secondary       ChainBackend
+	timeout         time.Duration
+	maxBlocksBehind int64
+	onSwitch        func(ev BackendSwitch)
+
+	mu     sync.Mutex
+	active bool
+}
+
+var _ WalletController = (*FailoverWalletController)(nil)
+
+// NewFailoverWalletController wraps primary wallet with failover to secondary
+// backend. onSwitch is called on every switch between backends.
+func NewFailoverWalletController(
+	primary WalletController,
+	cfg *scfg.FailoverConfig,
+	onSwitch func(ev BackendSwitch),
+) (*FailoverWalletController, error) {
+	secondary, err := NewChainBackend(cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &FailoverWalletController{
+		WalletController: primary,
+		secondary:        secondary,
+		timeout:          cfg.Timeout,
+		maxBlocksBehind:  int64(cfg.MaxBlocksBehind),
+		onSwitch:         onSwitch,
+	}, nil
+}
+
+// SecondaryActive returns true if broadcasts and chain queries are currently
+// served by secondary backend
+func (f *FailoverWalletController) SecondaryActive() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.active
+}
+
+func (f *FailoverWalletController) setActive(active bool, reason string) {
+	f.mu.Lock()
+	changed := f.active != active
+	f.active = active
+	f.mu.Unlock()
+
+	if changed && f.onSwitch != nil {
+		f.onSwitch(BackendSwitch{
+			ToSecondary: active,
+			Secondary:   f.secondary.Name(),
+			Reason:      reason,
+		})
+	}
+}
+
+// isUnreachable returns true if error means that node did not respond, as
+// opposed to node rejecting the request
+func isUnreachable(err error) bool {
+	var netErr net.Error
+
+	return errors.Is(err, utils.ErrCallTimedOut) ||
+		errors.Is(err, rpcclient.ErrClientShutdown) ||
+		errors.As(err, &netErr)
+}
+
+func (f *FailoverWalletController) secondaryContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), f.timeout)
+}
+
+// failoverCall runs call against primary backend unless secondary is active.
+// If primary is unreachable secondary becomes active and serves the call.
+func failoverCall[T any](
+	f *FailoverWalletController,
+	primary func() (T, error),
+	secondary func(ctx context.Context) (T, error),
+) (T, error) {
+	if !f.SecondaryActive() {
+		res, err := primary()
+
+		if err == nil || !isUnreachable(err) {
+			return res, err
+		}
+
+		f.setActive(true, fmt.Sprintf("primary node unreachable: %v", err))
+	}
+
+	ctx, cancel := f.secondaryContext()
+	defer cancel()
+
+	return secondary(ctx)
+}
+
+func (f *FailoverWalletController) SendRawTransaction(tx *wire.MsgTx, allowHighFees bool) (*chainhash.Hash, error) {
+	return failoverCall(f,
+		func() (*chainhash.Hash, error) {
+			return f.WalletController.SendRawTransaction(tx, allowHighFees)
+		},
+		func(ctx context.Context) (*chainhash.Hash, error) {
+			return f.secondary.SendRawTransaction(ctx, tx)
+		},
+	)
+}
+
+type failoverTxDetails struct {
+	details *notifier.TxConfirmation
+	status  TxStatus
+}
+
+func (f *FailoverWalletController) TxDetails(txHash *chainhash.Hash, pkScript []byte) (*notifier.TxConfirmation, TxStatus, error) {
+	res, err := failoverCall(f,
+		func() (failoverTxDetails, error) {
+			details, status, err := f.WalletController.TxDetails(txHash, pkScript)
+			return failoverTxDetails{details: details, status: status}, err
+		},
+		func(ctx context.Context) (failoverTxDetails, error) {
+			details, status, err := f.secondary.TxDetails(ctx, txHash, pkScript)
+			return failoverTxDetails{details: details, status: status}, err
+		},
+	)
+
+	if err != nil {
+		return nil, TxNotFound, err
+	}
+
+	return res.details, res.status, nil
+}
+
+func (f *FailoverWalletController) GetBlockHash(blockHeight int64) (*chainhash.Hash, error) {
+	return failoverCall(f,
+		func() (*chainhash.Hash, error) {
+			return f.WalletController.GetBlockHash(blockHeight)
+		},
+		func(ctx context.Context) (*chainhash.Hash, error) {
+			return f.secondary.GetBlockHash(ctx, blockHeight)
+		},
+	)
+}
+
+func (f *FailoverWalletController) GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+	return failoverCall(f,
+		func() (*wire.MsgBlock, error) {
+			return f.WalletController.GetBlock(blockHash)
+		},
+		func(ctx context.Context) (*wire.MsgBlock, error) {
+			return f.secondary.GetBlock(ctx, blockHash)
+		},
+	)
+}
+
+// CheckBackends compares heights of both backends. Secondary becomes active if
+// primary is unreachable or lags behind by more than configured number of
+// blocks, primary becomes active again once it catches up.
+func (f *FailoverWalletController) CheckBackends() (*BackendHeights, error) {
+	ctx, cancel := f.secondaryContext()
+	defer cancel()
+
+	secondaryHeight, secondaryErr := f.secondary.BestHeight(ctx)
+
+	status, primaryErr := f.WalletController.ChainSyncStatus()
+
+	switch {
+	case primaryErr != nil && isUnreachable(primaryErr):
+		f.setActive(true, fmt.Sprintf("primary node unreachable: %v", primaryErr))
+	case primaryErr != nil:
+		return nil, fmt.Errorf("failed to get status of primary node: %w", primaryErr)
+	case secondaryErr != nil:
+		// nothing to compare with, primary is the only working backend
+		f.setActive(false, fmt.Sprintf("secondary backend failed: %v", secondaryErr))
+	case int64(status.Blocks)+f.maxBlocksBehind < secondaryHeight:
+		f.setActive(true, fmt.Sprintf(
+			"primary node stalled at height %d, secondary backend is at height %d",
+			status.Blocks, secondaryHeight,
+		))
+	default:
+		f.setActive(false, fmt.Sprintf("primary node caught up at height %d", status.Blocks))
+	}
+
+	if secondaryErr != nil {
+		return nil, fmt.Errorf("failed to get best height of %s: %w", f.secondary.Name(), secondaryErr)
+	}
+
+	heights := &BackendHeights{
+		Primary:         -1,
+		Secondary:       secondaryHeight,
+		SecondaryActive: f.SecondaryActive(),
+	}
+
+	if primaryErr == nil {
+		heights.Primary = int64(status.Blocks)
+	}
+
+	return heights, nil
+}
@@ -0,0 +1,271 @@
+package walletcontroller
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+func inputSigned(txIn *wire.TxIn) bool {
+	return len(txIn.Witness) > 0 || len(txIn.SignatureScript) > 0
+}
+
+// signUnsignedInputs signs single key inputs left unsigned by wallet rpc.
+// Btcwallet signrawtransaction and bitcoind legacy wallets do not sign taproot
+// inputs, and wallets restored from old backups may not sign their legacy or
+// nested segwit outputs, although keys of all of them can be dumped from the
+// wallet. Supported inputs are P2TR key spend (BIP86), P2WPKH, P2SH-P2WPKH and
+// P2PKH. Taproot sighash commits to all spent outputs, so all inputs must be
+// outputs of the wallet. Returns whether all inputs of the transaction are
+// signed.
+//
+// NOTE: requires wallet to be unlocked, as keys are dumped from the wallet
+func (w *RpcWalletController) signUnsignedInputs(tx *wire.MsgTx) (bool, error) {
+	var unsigned []int
+	for i, txIn := range tx.TxIn {
+		if !inputSigned(txIn) {
+			unsigned = append(unsigned, i)
+		}
+	}
+
+	if len(unsigned) == 0 {
+		return true, nil
+	}
+
+	utxos, err := w.ListOutputs(false)
+
+	if err != nil {
+		return false, err
+	}
+
+	walletOutputs := make(map[wire.OutPoint]Utxo, len(utxos))
+	for _, utxo := range utxos {
+		walletOutputs[utxo.OutPoint] = utxo
+	}
+
+	prevOutFetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for _, txIn := range tx.TxIn {
+		utxo, found := walletOutputs[txIn.PreviousOutPoint]
+
+		if !found {
+			// without all spent outputs sighashes cannot be computed
+			return false, nil
+		}
+
+		prevOutFetcher.AddPrevOut(txIn.PreviousOutPoint, wire.NewTxOut(int64(utxo.Amount), utxo.PkScript))
+	}
+
+	sigHashes := txscript.NewTxSigHashes(tx, prevOutFetcher)
+
+	for _, idx := range unsigned {
+		utxo := walletOutputs[tx.TxIn[idx].PreviousOutPoint]
+
+		var signErr error
+		switch txscript.GetScriptClass(utxo.PkScript) {
+		case txscript.WitnessV1TaprootTy:
+			signErr = w.signTaprootKeySpendInput(tx, sigHashes, idx, &utxo)
+		case txscript.WitnessV0PubKeyHashTy:
+			signErr = w.signP2WPKHInput(tx, sigHashes, idx, &utxo)
+		case txscript.ScriptHashTy:
+			signErr = w.signNestedP2WPKHInput(tx, sigHashes, idx, &utxo)
+		case txscript.PubKeyHashTy:
+			signErr = w.signP2PKHInput(tx, idx, &utxo)
+		default:
+			return false, nil
+		}
+
+		if signErr != nil {
+			return false, fmt.Errorf("failed to sign input %d: %w", idx, signErr)
+		}
+	}
+
+	return true, nil
+}
+
+func (w *RpcWalletController) signTaprootKeySpendInput(
+	tx *wire.MsgTx,
+	sigHashes *txscript.TxSigHashes,
+	idx int,
+	utxo *Utxo,
+) error {
+	address, err := btcutil.NewAddressTaproot(utxo.PkScript[2:], w.netParams)
+
+	if err != nil {
+		return err
+	}
+
+	privKey, err := w.DumpPrivateKey(address)
+
+	if err != nil {
+		return err
+	}
+
+	// wallet taproot addresses commit to the key without script tree, as
+	// defined in BIP86
+	outputKey := txscript.ComputeTaprootKeyNoScript(privKey.PubKey())
+	pkScript, err := txscript.PayToTaprootScript(outputKey)
+
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(pkScript, utxo.PkScript) {
+		return fmt.Errorf("output %s is not BIP86 key spend output of wallet key", utxo.OutPoint)
+	}
+
+	witness, err := txscript.TaprootWitnessSignature(
+		tx,
+		sigHashes,
+		idx,
+		int64(utxo.Amount),
+		utxo.PkScript,
+		txscript.SigHashDefault,
+		privKey,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	tx.TxIn[idx].Witness = witness
+	return nil
+}
+
+// p2wpkhScriptOfKey returns P2WPKH script paying to compressed key
+func p2wpkhScriptOfKey(key *btcec.PublicKey, params *chaincfg.Params) ([]byte, error) {
+	address, err := btcutil.NewAddressWitnessPubKeyHash(
+		btcutil.Hash160(key.SerializeCompressed()), params,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return txscript.PayToAddrScript(address)
+}
+
+func (w *RpcWalletController) signP2WPKHInput(
+	tx *wire.MsgTx,
+	sigHashes *txscript.TxSigHashes,
+	idx int,
+	utxo *Utxo,
+) error {
+	address, err := btcutil.NewAddressWitnessPubKeyHash(utxo.PkScript[2:], w.netParams)
+
+	if err != nil {
+		return err
+	}
+
+	privKey, err := w.DumpPrivateKey(address)
+
+	if err != nil {
+		return err
+	}
+
+	witness, err := txscript.WitnessSignature(
+		tx,
+		sigHashes,
+		idx,
+		int64(utxo.Amount),
+		utxo.PkScript,
+		txscript.SigHashAll,
+		privKey,
+		true,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	tx.TxIn[idx].Witness = witness
+	return nil
+}
+
+// signNestedP2WPKHInput signs P2SH output, which wallet creates only for P2WPKH
+// nested in P2SH. Redeem script is derived from the wallet key and checked
+// against the script hash.
+func (w *RpcWalletController) signNestedP2WPKHInput(
+	tx *wire.MsgTx,
+	sigHashes *txscript.TxSigHashes,
+	idx int,
+	utxo *Utxo,
+) error {
+	address, err := btcutil.NewAddressScriptHashFromHash(utxo.PkScript[2:22], w.netParams)
+
+	if err != nil {
+		return err
+	}
+
+	privKey, err := w.DumpPrivateKey(address)
+
+	if err != nil {
+		return err
+	}
+
+	witnessProgram, err := p2wpkhScriptOfKey(privKey.PubKey(), w.netParams)
+
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(btcutil.Hash160(witnessProgram), address.ScriptAddress()) {
+		return fmt.Errorf("output %s is not P2SH-P2WPKH output of wallet key", utxo.OutPoint)
+	}
+
+	witness, err := txscript.WitnessSignature(
+		tx,
+		sigHashes,
+		idx,
+		int64(utxo.Amount),
+		witnessProgram,
+		txscript.SigHashAll,
+		privKey,
+		true,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	sigScript, err := txscript.NewScriptBuilder().AddData(witnessProgram).Script()
+
+	if err != nil {
+		return err
+	}
+
+	tx.TxIn[idx].SignatureScript = sigScript
+	tx.TxIn[idx].Witness = witness
+	return nil
+}
+
+func (w *RpcWalletController) signP2PKHInput(tx *wire.MsgTx, idx int, utxo *Utxo) error {
+	address, err := btcutil.NewAddressPubKeyHash(utxo.PkScript[3:23], w.netParams)
+
+	if err != nil {
+		return err
+	}
+
+	privKey, err := w.DumpPrivateKey(address)
+
+	if err != nil {
+		return err
+	}
+
+	// legacy wallets may hold uncompressed keys, key must hash to address of
+	// the output
+	compress := bytes.Equal(btcutil.Hash160(privKey.PubKey().SerializeCompressed()), address.ScriptAddress())
+
+	sigScript, err := txscript.SignatureScript(tx, idx, utxo.PkScript, txscript.SigHashAll, privKey, compress)
+
+	if err != nil {
+		return err
+	}
+
+	tx.TxIn[idx].SignatureScript = sigScript
+	return nil
+}
@@ -0,0 +1,311 @@
+package walletcontroller
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/babylonchain/btc-staker/types"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+var testNetParams = &chaincfg.RegressionNetParams
+
+// fakeWalletRpc serves listunspent and dumpprivkey calls of wallet holding
+// given outputs and keys
+type fakeWalletRpc struct {
+	unspent []btcjson.ListUnspentResult
+	// wif encoded keys by address
+	keys map[string]string
+}
+
+func (f *fakeWalletRpc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Id     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var result interface{}
+	var rpcErr *btcjson.RPCError
+
+	switch req.Method {
+	case "listunspent":
+		result = f.unspent
+	case "dumpprivkey":
+		var address string
+		_ = json.Unmarshal(req.Params[0], &address)
+
+		if wif, found := f.keys[address]; found {
+			result = wif
+		} else {
+			rpcErr = btcjson.NewRPCError(btcjson.ErrRPCInvalidAddressOrKey, "address not found in wallet")
+		}
+	default:
+		rpcErr = btcjson.NewRPCError(btcjson.ErrRPCMethodNotFound.Code, "method not found")
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":     req.Id,
+		"result": result,
+		"error":  rpcErr,
+	})
+}
+
+// addOutput adds output of the wallet paying to pkScript, spendable by key of
+// given address
+func (f *fakeWalletRpc) addOutput(
+	t *testing.T,
+	pkScript []byte,
+	redeemScript []byte,
+	address btcutil.Address,
+	key *btcec.PrivateKey,
+	compressed bool,
+) wire.OutPoint {
+	outpoint := wire.OutPoint{Hash: chainhash.Hash{byte(len(f.unspent) + 1)}, Index: uint32(len(f.unspent))}
+
+	f.unspent = append(f.unspent, btcjson.ListUnspentResult{
+		TxID:          outpoint.Hash.String(),
+		Vout:          outpoint.Index,
+		Address:       address.EncodeAddress(),
+		ScriptPubKey:  hex.EncodeToString(pkScript),
+		RedeemScript:  hex.EncodeToString(redeemScript),
+		Amount:        0.001 * float64(len(f.unspent)+1),
+		Confirmations: 6,
+		Spendable:     true,
+	})
+
+	if key != nil {
+		wif, err := btcutil.NewWIF(key, testNetParams, compressed)
+		require.NoError(t, err)
+		f.keys[address.EncodeAddress()] = wif.String()
+	}
+
+	return outpoint
+}
+
+func newTestRpcWalletController(t *testing.T, f *fakeWalletRpc) *RpcWalletController {
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+
+	wc, err := NewRpcWalletControllerFromArgs(
+		strings.TrimPrefix(server.URL, "http://"),
+		"user",
+		"pass",
+		testNetParams.Name,
+		"",
+		false,
+		types.BitcoindWalletBackend,
+		testNetParams,
+		true,
+		"",
+		"",
+		0,
+	)
+	require.NoError(t, err)
+	t.Cleanup(wc.Shutdown)
+
+	return wc
+}
+
+func newTestKey(t *testing.T) *btcec.PrivateKey {
+	key, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	return key
+}
+
+// walletOutputs creates wallet output of every input type signed by
+// signUnsignedInputs
+func walletOutputs(t *testing.T, f *fakeWalletRpc) []wire.OutPoint {
+	var outpoints []wire.OutPoint
+
+	// P2TR key spend, BIP86
+	key := newTestKey(t)
+	outputKey := txscript.ComputeTaprootKeyNoScript(key.PubKey())
+	taprootAddress, err := btcutil.NewAddressTaproot(schnorr.SerializePubKey(outputKey), testNetParams)
+	require.NoError(t, err)
+	pkScript, err := txscript.PayToAddrScript(taprootAddress)
+	require.NoError(t, err)
+	outpoints = append(outpoints, f.addOutput(t, pkScript, nil, taprootAddress, key, true))
+
+	// P2WPKH
+	key = newTestKey(t)
+	pkScript, err = p2wpkhScriptOfKey(key.PubKey(), testNetParams)
+	require.NoError(t, err)
+	witnessAddress, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(key.PubKey().SerializeCompressed()), testNetParams)
+	require.NoError(t, err)
+	outpoints = append(outpoints, f.addOutput(t, pkScript, nil, witnessAddress, key, true))
+
+	// P2SH-P2WPKH
+	key = newTestKey(t)
+	witnessProgram, err := p2wpkhScriptOfKey(key.PubKey(), testNetParams)
+	require.NoError(t, err)
+	nestedAddress, err := btcutil.NewAddressScriptHash(witnessProgram, testNetParams)
+	require.NoError(t, err)
+	pkScript, err = txscript.PayToAddrScript(nestedAddress)
+	require.NoError(t, err)
+	outpoints = append(outpoints, f.addOutput(t, pkScript, witnessProgram, nestedAddress, key, true))
+
+	// P2PKH with compressed and uncompressed key
+	for _, compressed := range []bool{true, false} {
+		key = newTestKey(t)
+		serializedKey := key.PubKey().SerializeUncompressed()
+		if compressed {
+			serializedKey = key.PubKey().SerializeCompressed()
+		}
+
+		pkhAddress, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(serializedKey), testNetParams)
+		require.NoError(t, err)
+		pkScript, err = txscript.PayToAddrScript(pkhAddress)
+		require.NoError(t, err)
+		outpoints = append(outpoints, f.addOutput(t, pkScript, nil, pkhAddress, key, compressed))
+	}
+
+	return outpoints
+}
+
+func newTestSpendTx(outpoints []wire.OutPoint) *wire.MsgTx {
+	tx := wire.NewMsgTx(2)
+	for i := range outpoints {
+		tx.AddTxIn(wire.NewTxIn(&outpoints[i], nil, nil))
+	}
+	tx.AddTxOut(wire.NewTxOut(50000, []byte{txscript.OP_TRUE}))
+	return tx
+}
+
+// requireValidInputs executes scripts of all inputs of tx
+func requireValidInputs(t *testing.T, tx *wire.MsgTx, f *fakeWalletRpc) {
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for _, result := range f.unspent {
+		hash, err := chainhash.NewHashFromStr(result.TxID)
+		require.NoError(t, err)
+		pkScript, err := hex.DecodeString(result.ScriptPubKey)
+		require.NoError(t, err)
+		amount, err := btcutil.NewAmount(result.Amount)
+		require.NoError(t, err)
+
+		fetcher.AddPrevOut(wire.OutPoint{Hash: *hash, Index: result.Vout}, wire.NewTxOut(int64(amount), pkScript))
+	}
+
+	sigHashes := txscript.NewTxSigHashes(tx, fetcher)
+
+	for i, txIn := range tx.TxIn {
+		prevOut := fetcher.FetchPrevOutput(txIn.PreviousOutPoint)
+		require.NotNil(t, prevOut)
+
+		engine, err := txscript.NewEngine(
+			prevOut.PkScript,
+			tx,
+			i,
+			txscript.StandardVerifyFlags,
+			nil,
+			sigHashes,
+			prevOut.Value,
+			fetcher,
+		)
+		require.NoError(t, err)
+		require.NoError(t, engine.Execute(), "input %d (%s)", i, txscript.GetScriptClass(prevOut.PkScript))
+	}
+}
+
+func TestSignUnsignedInputs(t *testing.T) {
+	f := &fakeWalletRpc{keys: make(map[string]string)}
+	outpoints := walletOutputs(t, f)
+	wc := newTestRpcWalletController(t, f)
+
+	tx := newTestSpendTx(outpoints)
+
+	signed, err := wc.signUnsignedInputs(tx)
+	require.NoError(t, err)
+	require.True(t, signed)
+	requireValidInputs(t, tx, f)
+
+	// already signed inputs are left as they are
+	signedTx := tx.Copy()
+	signed, err = wc.signUnsignedInputs(tx)
+	require.NoError(t, err)
+	require.True(t, signed)
+	require.Equal(t, signedTx, tx)
+}
+
+func TestSignUnsignedInputsSignsOnlyUnsigned(t *testing.T) {
+	f := &fakeWalletRpc{keys: make(map[string]string)}
+	outpoints := walletOutputs(t, f)
+	wc := newTestRpcWalletController(t, f)
+
+	tx := newTestSpendTx(outpoints)
+	signed, err := wc.signUnsignedInputs(tx)
+	require.NoError(t, err)
+	require.True(t, signed)
+
+	// inputs signed by wallet rpc are kept, the rest is signed
+	partiallySigned := tx.Copy()
+	for _, idx := range []int{0, 2, 3} {
+		partiallySigned.TxIn[idx].Witness = nil
+		partiallySigned.TxIn[idx].SignatureScript = nil
+	}
+
+	signed, err = wc.signUnsignedInputs(partiallySigned)
+	require.NoError(t, err)
+	require.True(t, signed)
+	require.Equal(t, tx.TxIn[1].Witness, partiallySigned.TxIn[1].Witness)
+	require.Equal(t, tx.TxIn[4].SignatureScript, partiallySigned.TxIn[4].SignatureScript)
+	requireValidInputs(t, partiallySigned, f)
+}
+
+func TestSignUnsignedInputsNotSignable(t *testing.T) {
+	f := &fakeWalletRpc{keys: make(map[string]string)}
+	outpoints := walletOutputs(t, f)
+
+	// P2WSH output can't be signed with single key
+	witnessScriptHash := make([]byte, 32)
+	scriptAddress, err := btcutil.NewAddressWitnessScriptHash(witnessScriptHash, testNetParams)
+	require.NoError(t, err)
+	pkScript, err := txscript.PayToAddrScript(scriptAddress)
+	require.NoError(t, err)
+	p2wshOutpoint := f.addOutput(t, pkScript, nil, scriptAddress, nil, true)
+
+	// taproot output committing to script tree is not BIP86 output
+	key := newTestKey(t)
+	outputKey := txscript.ComputeTaprootOutputKey(key.PubKey(), []byte("script root"))
+	taprootAddress, err := btcutil.NewAddressTaproot(schnorr.SerializePubKey(outputKey), testNetParams)
+	require.NoError(t, err)
+	pkScript, err = txscript.PayToAddrScript(taprootAddress)
+	require.NoError(t, err)
+	tweakedOutpoint := f.addOutput(t, pkScript, nil, taprootAddress, key, true)
+
+	wc := newTestRpcWalletController(t, f)
+
+	// sighashes can't be computed without all spent outputs
+	foreignOutpoint := wire.OutPoint{Hash: chainhash.Hash{0xff}, Index: 7}
+	tx := newTestSpendTx(append([]wire.OutPoint{foreignOutpoint}, outpoints...))
+	signed, err := wc.signUnsignedInputs(tx)
+	require.NoError(t, err)
+	require.False(t, signed)
+
+	tx = newTestSpendTx(append([]wire.OutPoint{p2wshOutpoint}, outpoints...))
+	signed, err = wc.signUnsignedInputs(tx)
+	require.NoError(t, err)
+	require.False(t, signed)
+	require.False(t, inputSigned(tx.TxIn[0]))
+
+	tx = newTestSpendTx(append([]wire.OutPoint{tweakedOutpoint}, outpoints...))
+	_, err = wc.signUnsignedInputs(tx)
+	require.ErrorContains(t, err, "is not BIP86 key spend output of wallet key")
+}
@@ -0,0 +1,334 @@
+package walletcontroller
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/babylonchain/btc-staker/stakercfg"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcwallet/wallet/txsizes"
+)
+
+const (
+	// outpoint, signature script length and sequence
+	inputBaseSize = 32 + 4 + 1 + 4
+
+	// worst case der encoded ecdsa signature with sighash type
+	maxEcdsaSigSize = 73
+
+	// schnorr signature with non default sighash type
+	maxSchnorrSigSize = 65
+)
+
+// InputSize is size of signed input, split into the part serialized in the
+// transaction without witness and its witness
+type InputSize struct {
+	// outpoint, signature script and sequence in bytes
+	BaseSize int
+	// witness including number of its items in weight units, 0 for inputs
+	// without witness
+	WitnessWeight int
+}
+
+// InputWeightEstimator returns worst case size of the input spending utxo once
+// it is signed, or false if the estimator does not know how utxo is spent
+type InputWeightEstimator func(utxo *Utxo) (InputSize, bool)
+
+// InputWeightRegistry estimates sizes of inputs used to fund transactions.
+// Standard single key outputs are known by default, estimators of other outputs
+// e.g multisig or taproot script path spends are registered by the user.
+type InputWeightRegistry struct {
+	mu sync.RWMutex
+	// estimators are tried from the last registered one
+	estimators []InputWeightEstimator
+}
+
+func NewInputWeightRegistry() *InputWeightRegistry {
+	r := &InputWeightRegistry{}
+	r.Register(standardInputSize)
+	return r
+}
+
+// Register adds estimator which takes precedence over estimators registered
+// before it
+func (r *InputWeightRegistry) Register(estimator InputWeightEstimator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.estimators = append(r.estimators, estimator)
+}
+
+func (r *InputWeightRegistry) InputSize(utxo *Utxo) (InputSize, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := len(r.estimators) - 1; i >= 0; i-- {
+		if size, ok := r.estimators[i](utxo); ok {
+			return size, nil
+		}
+	}
+
+	return InputSize{}, fmt.Errorf("unsupported utxo script type: %s, no input weight estimator is registered for utxo %s",
+		txscript.GetScriptClass(utxo.PkScript), utxo.OutPoint)
+}
+
+// EstimateVirtualSize returns virtual size of the transaction spending utxos to
+// outputs and, if changeScriptSize is not 0, to change output
+func (r *InputWeightRegistry) EstimateVirtualSize(utxos []Utxo, outputs []*wire.TxOut, changeScriptSize int) (int, error) {
+	outputCount := len(outputs)
+	changeOutputSize := 0
+
+	if changeScriptSize > 0 {
+		changeOutputSize = 8 + wire.VarIntSerializeSize(uint64(changeScriptSize)) + changeScriptSize
+		outputCount++
+	}
+
+	// version, locktime, input and output counts
+	baseSize := 8 +
+		wire.VarIntSerializeSize(uint64(len(utxos))) +
+		wire.VarIntSerializeSize(uint64(outputCount)) +
+		txsizes.SumOutputSerializeSizes(outputs) +
+		changeOutputSize
+
+	witnessWeight := 0
+	hasWitness := false
+
+	for i := range utxos {
+		size, err := r.InputSize(&utxos[i])
+
+		if err != nil {
+			return 0, err
+		}
+
+		baseSize += size.BaseSize
+
+		if size.WitnessWeight > 0 {
+			hasWitness = true
+			witnessWeight += size.WitnessWeight
+		} else {
+			// empty witness of input without witness still needs its item count
+			witnessWeight++
+		}
+	}
+
+	if !hasWitness {
+		return baseSize, nil
+	}
+
+	// segwit marker and flag
+	witnessWeight += 2
+
+	// rounded up
+	return baseSize + (witnessWeight+blockchain.WitnessScaleFactor-1)/blockchain.WitnessScaleFactor, nil
+}
+
+func pushDataSize(dataLen int) int {
+	switch {
+	case dataLen < txscript.OP_PUSHDATA1:
+		return 1 + dataLen
+	case dataLen <= 0xff:
+		return 2 + dataLen
+	default:
+		return 3 + dataLen
+	}
+}
+
+func inputSizeWithSigScript(sigScriptSize int, witnessWeight int) InputSize {
+	return InputSize{
+		BaseSize:      inputBaseSize - 1 + wire.VarIntSerializeSize(uint64(sigScriptSize)) + sigScriptSize,
+		WitnessWeight: witnessWeight,
+	}
+}
+
+// multisigWitnessWeight returns weight of witness spending multisig witness
+// script, including empty item consumed by OP_CHECKMULTISIG
+func multisigWitnessWeight(script []byte, numSigs int) int {
+	return wire.VarIntSerializeSize(uint64(numSigs+2)) +
+		1 +
+		numSigs*(1+maxEcdsaSigSize) +
+		wire.VarIntSerializeSize(uint64(len(script))) + len(script)
+}
+
+func standardInputSize(utxo *Utxo) (InputSize, bool) {
+	switch txscript.GetScriptClass(utxo.PkScript) {
+	case txscript.PubKeyHashTy:
+		return InputSize{BaseSize: txsizes.RedeemP2PKHInputSize}, true
+	case txscript.WitnessV0PubKeyHashTy:
+		return InputSize{
+			BaseSize:      txsizes.RedeemP2WPKHInputSize,
+			WitnessWeight: txsizes.RedeemP2WPKHInputWitnessWeight,
+		}, true
+	case txscript.WitnessV1TaprootTy:
+		// key spend, script path spends need registered estimator
+		return InputSize{
+			BaseSize:      txsizes.RedeemP2TRInputSize,
+			WitnessWeight: txsizes.RedeemP2TRInputWitnessWeight,
+		}, true
+	case txscript.ScriptHashTy:
+		// wallet p2sh outputs without redeem script are assumed to be nested p2wpkh
+		if len(utxo.RedeemScript) == 0 || txscript.IsPayToWitnessPubKeyHash(utxo.RedeemScript) {
+			return InputSize{
+				BaseSize:      txsizes.RedeemNestedP2WPKHInputSize,
+				WitnessWeight: txsizes.RedeemP2WPKHInputWitnessWeight,
+			}, true
+		}
+
+		if isMultisig, _ := txscript.IsMultisigScript(utxo.RedeemScript); isMultisig {
+			_, numSigs, err := txscript.CalcMultiSigStats(utxo.RedeemScript)
+
+			if err != nil {
+				return InputSize{}, false
+			}
+
+			// OP_0, signatures and redeem script
+			sigScriptSize := 1 + numSigs*(1+maxEcdsaSigSize) + pushDataSize(len(utxo.RedeemScript))
+			return inputSizeWithSigScript(sigScriptSize, 0), true
+		}
+
+		return InputSize{}, false
+	default:
+		return InputSize{}, false
+	}
+}
+
+// NewMultisigWitnessScriptEstimator returns estimator of inputs spending P2WSH
+// outputs with given multisig witness script, either native or nested in P2SH
+func NewMultisigWitnessScriptEstimator(witnessScript []byte) (InputWeightEstimator, error) {
+	_, numSigs, err := txscript.CalcMultiSigStats(witnessScript)
+
+	if err != nil {
+		return nil, fmt.Errorf("witness script is not multisig script: %w", err)
+	}
+
+	scriptHash := sha256.Sum256(witnessScript)
+	witnessProgram, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(scriptHash[:]).Script()
+
+	if err != nil {
+		return nil, err
+	}
+
+	nestedPkScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_HASH160).
+		AddData(btcutil.Hash160(witnessProgram)).
+		AddOp(txscript.OP_EQUAL).
+		Script()
+
+	if err != nil {
+		return nil, err
+	}
+
+	witnessWeight := multisigWitnessWeight(witnessScript, numSigs)
+
+	return func(utxo *Utxo) (InputSize, bool) {
+		switch {
+		case bytes.Equal(utxo.PkScript, witnessProgram):
+			return inputSizeWithSigScript(0, witnessWeight), true
+		case bytes.Equal(utxo.PkScript, nestedPkScript):
+			return inputSizeWithSigScript(pushDataSize(len(witnessProgram)), witnessWeight), true
+		default:
+			return InputSize{}, false
+		}
+	}, nil
+}
+
+// NewTaprootScriptPathEstimator returns estimator of inputs spending taproot
+// output through leaf script revealed with control block. Worst case is
+// assumed, where every signature checking opcode of the script consumes
+// signature.
+func NewTaprootScriptPathEstimator(leafScript []byte, controlBlock []byte) (InputWeightEstimator, error) {
+	ctrlBlock, err := txscript.ParseControlBlock(controlBlock)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid control block: %w", err)
+	}
+
+	outputKey := txscript.ComputeTaprootOutputKey(ctrlBlock.InternalKey, ctrlBlock.RootHash(leafScript))
+	pkScript, err := txscript.PayToTaprootScript(outputKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	numSigs := 0
+	tokenizer := txscript.MakeScriptTokenizer(0, leafScript)
+	for tokenizer.Next() {
+		switch tokenizer.Opcode() {
+		case txscript.OP_CHECKSIG, txscript.OP_CHECKSIGVERIFY, txscript.OP_CHECKSIGADD:
+			numSigs++
+		}
+	}
+
+	if err := tokenizer.Err(); err != nil {
+		return nil, fmt.Errorf("invalid leaf script: %w", err)
+	}
+
+	witnessWeight := wire.VarIntSerializeSize(uint64(numSigs+2)) +
+		numSigs*(1+maxSchnorrSigSize) +
+		wire.VarIntSerializeSize(uint64(len(leafScript))) + len(leafScript) +
+		wire.VarIntSerializeSize(uint64(len(controlBlock))) + len(controlBlock)
+
+	return func(utxo *Utxo) (InputSize, bool) {
+		if !bytes.Equal(utxo.PkScript, pkScript) {
+			return InputSize{}, false
+		}
+
+		return inputSizeWithSigScript(0, witnessWeight), true
+	}, nil
+}
+
+// registerConfiguredInputWeights registers estimators of non standard outputs
+// listed in wallet config
+func registerConfiguredInputWeights(r *InputWeightRegistry, cfg *stakercfg.WalletConfig) error {
+	for _, scriptHex := range cfg.MultisigWitnessScripts {
+		script, err := hex.DecodeString(scriptHex)
+
+		if err != nil {
+			return fmt.Errorf("invalid multisig witness script %s: %w", scriptHex, err)
+		}
+
+		estimator, err := NewMultisigWitnessScriptEstimator(script)
+
+		if err != nil {
+			return fmt.Errorf("invalid multisig witness script %s: %w", scriptHex, err)
+		}
+
+		r.Register(estimator)
+	}
+
+	for _, spend := range cfg.TaprootScriptPathSpends {
+		leafHex, controlBlockHex, found := strings.Cut(spend, ":")
+
+		if !found {
+			return fmt.Errorf("taproot script path spend %s must have format <leaf_script_hex>:<control_block_hex>", spend)
+		}
+
+		leafScript, err := hex.DecodeString(leafHex)
+
+		if err != nil {
+			return fmt.Errorf("invalid leaf script of taproot script path spend %s: %w", spend, err)
+		}
+
+		controlBlock, err := hex.DecodeString(controlBlockHex)
+
+		if err != nil {
+			return fmt.Errorf("invalid control block of taproot script path spend %s: %w", spend, err)
+		}
+
+		estimator, err := NewTaprootScriptPathEstimator(leafScript, controlBlock)
+
+		if err != nil {
+			return fmt.Errorf("invalid taproot script path spend %s: %w", spend, err)
+		}
+
+		r.Register(estimator)
+	}
+
+	return nil
+}
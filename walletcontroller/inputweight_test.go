@@ -0,0 +1,281 @@
+package walletcontroller
+
+import (
+	"testing"
+
+	staking "github.com/babylonchain/babylon/btcstaking"
+	"github.com/babylonchain/btc-staker/scriptbuilder"
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+const testInputAmount = btcutil.Amount(100000)
+
+// signedInput is input of single input transaction signed with real keys,
+// together with utxo it spends
+type signedInput struct {
+	utxo Utxo
+	tx   *wire.MsgTx
+}
+
+func newUnsignedSpend(pkScript []byte, sequence uint32) (*wire.MsgTx, Utxo, *txscript.TxSigHashes, txscript.PrevOutputFetcher) {
+	utxo := Utxo{
+		Amount:   testInputAmount,
+		OutPoint: wire.OutPoint{Hash: chainhash.Hash{1}, Index: 1},
+		PkScript: pkScript,
+	}
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&utxo.OutPoint, nil, nil))
+	tx.TxIn[0].Sequence = sequence
+	tx.AddTxOut(wire.NewTxOut(int64(testInputAmount)-1000, []byte{txscript.OP_TRUE}))
+
+	fetcher := txscript.NewCannedPrevOutputFetcher(pkScript, int64(testInputAmount))
+	return tx, utxo, txscript.NewTxSigHashes(tx, fetcher), fetcher
+}
+
+// requireSpends executes script of the only input of the transaction
+func requireSpends(t *testing.T, in *signedInput) {
+	fetcher := txscript.NewCannedPrevOutputFetcher(in.utxo.PkScript, int64(in.utxo.Amount))
+
+	engine, err := txscript.NewEngine(
+		in.utxo.PkScript,
+		in.tx,
+		0,
+		txscript.StandardVerifyFlags,
+		nil,
+		txscript.NewTxSigHashes(in.tx, fetcher),
+		int64(in.utxo.Amount),
+		fetcher,
+	)
+	require.NoError(t, err)
+	require.NoError(t, engine.Execute())
+}
+
+// requireEstimateCovers checks that estimated size of the input is not lower
+// than size of the signed input and exceeds it by at most slack weight units.
+// Estimated virtual size of the whole transaction must cover its real size.
+func requireEstimateCovers(t *testing.T, r *InputWeightRegistry, in *signedInput, slack int) {
+	requireSpends(t, in)
+
+	estimate, err := r.InputSize(&in.utxo)
+	require.NoError(t, err)
+
+	txIn := in.tx.TxIn[0]
+	require.Equal(t, txIn.SerializeSize(), estimate.BaseSize)
+
+	witnessWeight := txIn.Witness.SerializeSize()
+	if len(txIn.Witness) == 0 {
+		witnessWeight = 0
+	}
+	require.GreaterOrEqual(t, estimate.WitnessWeight, witnessWeight)
+	require.LessOrEqual(t, estimate.WitnessWeight-witnessWeight, slack)
+
+	vsize, err := r.EstimateVirtualSize([]Utxo{in.utxo}, in.tx.TxOut, 0)
+	require.NoError(t, err)
+
+	weight := blockchain.GetTransactionWeight(btcutil.NewTx(in.tx))
+	actualVSize := int((weight + blockchain.WitnessScaleFactor - 1) / blockchain.WitnessScaleFactor)
+	require.GreaterOrEqual(t, vsize, actualVSize)
+	require.LessOrEqual(t, vsize-actualVSize, (slack+blockchain.WitnessScaleFactor-1)/blockchain.WitnessScaleFactor+1)
+}
+
+func signedP2WPKHInput(t *testing.T) *signedInput {
+	key := newTestKey(t)
+	pkScript, err := p2wpkhScriptOfKey(key.PubKey(), testNetParams)
+	require.NoError(t, err)
+
+	tx, utxo, sigHashes, _ := newUnsignedSpend(pkScript, wire.MaxTxInSequenceNum)
+
+	tx.TxIn[0].Witness, err = txscript.WitnessSignature(
+		tx, sigHashes, 0, int64(utxo.Amount), pkScript, txscript.SigHashAll, key, true,
+	)
+	require.NoError(t, err)
+
+	return &signedInput{utxo: utxo, tx: tx}
+}
+
+func signedTaprootKeySpendInput(t *testing.T) *signedInput {
+	key := newTestKey(t)
+	pkScript, err := txscript.PayToTaprootScript(txscript.ComputeTaprootKeyNoScript(key.PubKey()))
+	require.NoError(t, err)
+
+	tx, utxo, sigHashes, _ := newUnsignedSpend(pkScript, wire.MaxTxInSequenceNum)
+
+	tx.TxIn[0].Witness, err = txscript.TaprootWitnessSignature(
+		tx, sigHashes, 0, int64(utxo.Amount), pkScript, txscript.SigHashDefault, key,
+	)
+	require.NoError(t, err)
+
+	return &signedInput{utxo: utxo, tx: tx}
+}
+
+func TestStandardInputSizeMatchesSignedInputs(t *testing.T) {
+	r := NewInputWeightRegistry()
+
+	for i := 0; i < 10; i++ {
+		// ecdsa signatures are 71 or 72 bytes long, estimate assumes 73
+		requireEstimateCovers(t, r, signedP2WPKHInput(t), 2)
+		// estimate assumes schnorr signature with sighash type
+		requireEstimateCovers(t, r, signedTaprootKeySpendInput(t), 1)
+	}
+}
+
+type testStakingKeys struct {
+	staker    *btcec.PrivateKey
+	fp        *btcec.PrivateKey
+	covenants []*btcec.PrivateKey
+	quorum    uint32
+}
+
+func (k *testStakingKeys) covenantPks() []*btcec.PublicKey {
+	pks := make([]*btcec.PublicKey, len(k.covenants))
+	for i, c := range k.covenants {
+		pks[i] = c.PubKey()
+	}
+	return pks
+}
+
+// keyOf returns key of the x-only public key
+func (k *testStakingKeys) keyOf(xOnly []byte) *btcec.PrivateKey {
+	for _, key := range append([]*btcec.PrivateKey{k.staker, k.fp}, k.covenants...) {
+		if string(schnorr.SerializePubKey(key.PubKey())) == string(xOnly) {
+			return key
+		}
+	}
+	return nil
+}
+
+// signScriptPath signs spend of staking output through leaf of spendInfo. Keys
+// are taken from the leaf in the order in which script checks them. The leaf
+// is satisfied with the smallest number of covenant signatures, missing
+// signatures are empty witness items.
+func signScriptPath(
+	t *testing.T,
+	keys *testStakingKeys,
+	output *wire.TxOut,
+	leaf txscript.TapLeaf,
+	controlBlock []byte,
+	sequence uint32,
+) *signedInput {
+	tx, utxo, sigHashes, fetcher := newUnsignedSpend(output.PkScript, sequence)
+
+	sigHash, err := txscript.CalcTapscriptSignaturehash(sigHashes, txscript.SigHashDefault, tx, 0, fetcher, leaf)
+	require.NoError(t, err)
+
+	var leafKeys [][]byte
+	tokenizer := txscript.MakeScriptTokenizer(0, leaf.Script)
+	for tokenizer.Next() {
+		if len(tokenizer.Data()) == schnorr.PubKeyBytesLen {
+			leafKeys = append(leafKeys, tokenizer.Data())
+		}
+	}
+	require.NoError(t, tokenizer.Err())
+
+	// signature checked first is on top of the stack i.e last in witness
+	var witness wire.TxWitness
+	for i := len(leafKeys) - 1; i >= 0; i-- {
+		key := keys.keyOf(leafKeys[i])
+		require.NotNil(t, key)
+
+		isCovenant := key != keys.staker && key != keys.fp
+		if isCovenant {
+			// covenant keys are checked after staker and finality provider
+			// keys, first quorum of them signs
+			checkedBefore := uint32(0)
+			for _, k := range leafKeys[:i] {
+				if ck := keys.keyOf(k); ck != keys.staker && ck != keys.fp {
+					checkedBefore++
+				}
+			}
+
+			if checkedBefore >= keys.quorum {
+				witness = append(witness, []byte{})
+				continue
+			}
+		}
+
+		sig, err := schnorr.Sign(key, sigHash)
+		require.NoError(t, err)
+		witness = append(witness, sig.Serialize())
+	}
+
+	tx.TxIn[0].Witness = append(witness, leaf.Script, controlBlock)
+
+	return &signedInput{utxo: utxo, tx: tx}
+}
+
+func TestTaprootScriptPathEstimatorMatchesStakingPaths(t *testing.T) {
+	keys := &testStakingKeys{
+		staker:    newTestKey(t),
+		fp:        newTestKey(t),
+		covenants: []*btcec.PrivateKey{newTestKey(t), newTestKey(t), newTestKey(t)},
+		quorum:    2,
+	}
+
+	const stakingTime = uint16(100)
+
+	scripts, err := scriptbuilder.Default().BuildStakingScripts(
+		keys.staker.PubKey(),
+		[]*btcec.PublicKey{keys.fp.PubKey()},
+		keys.covenantPks(),
+		keys.quorum,
+		stakingTime,
+		testInputAmount,
+		testNetParams,
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		spendInfo func() (*staking.SpendInfo, error)
+		sequence  uint32
+		// estimate assumes signature with sighash type for every signature
+		// checking opcode, while covenant signatures above quorum are empty
+		slack int
+	}{
+		{
+			name:      "timelock path",
+			spendInfo: scripts.TimeLockPathSpendInfo,
+			sequence:  uint32(stakingTime),
+			slack:     1,
+		},
+		{
+			name:      "unbonding path",
+			spendInfo: scripts.UnbondingPathSpendInfo,
+			sequence:  wire.MaxTxInSequenceNum,
+			slack:     1 + len(keys.covenants) + (len(keys.covenants)-int(keys.quorum))*schnorr.SignatureSize,
+		},
+		{
+			name:      "slashing path",
+			spendInfo: scripts.SlashingPathSpendInfo,
+			sequence:  wire.MaxTxInSequenceNum,
+			slack:     2 + len(keys.covenants) + (len(keys.covenants)-int(keys.quorum))*schnorr.SignatureSize,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			info, err := tc.spendInfo()
+			require.NoError(t, err)
+
+			controlBlock, err := info.ControlBlock.ToBytes()
+			require.NoError(t, err)
+
+			estimator, err := NewTaprootScriptPathEstimator(info.RevealedLeaf.Script, controlBlock)
+			require.NoError(t, err)
+
+			r := NewInputWeightRegistry()
+			r.Register(estimator)
+
+			in := signScriptPath(t, keys, scripts.Output(), info.RevealedLeaf, controlBlock, tc.sequence)
+			requireEstimateCovers(t, r, in, tc.slack)
+		})
+	}
+}
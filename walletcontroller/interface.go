@@ -1,6 +1,8 @@
 package walletcontroller
 
 import (
+	"time"
+
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
@@ -16,24 +18,66 @@ const (
 	TxInChain
 )
 
+// ChainSyncStatus describes how far is the node connected to the wallet synced
+type ChainSyncStatus struct {
+	Blocks  int32
+	Headers int32
+	// always false for btcd, which does not report initial block download
+	InitialBlockDownload bool
+}
+
+// UtxoFilter returns false for outputs which must not be used to fund
+// transactions
+type UtxoFilter func(utxo *Utxo) bool
+
 type WalletController interface {
 	UnlockWallet(timeoutSecs int64) error
+	// does not change lock state of the wallet
+	WalletLocked() (bool, error)
+	ChainSyncStatus() (*ChainSyncStatus, error)
 	AddressPublicKey(address btcutil.Address) (*btcec.PublicKey, error)
 	DumpPrivateKey(address btcutil.Address) (*btcec.PrivateKey, error)
 	ImportPrivKey(privKeyWIF *btcutil.WIF) error
 	NetworkName() string
+	// filter is applied to outputs selected to fund transactions created by
+	// CreateTransaction and CreateAndSignTx
+	SetUtxoFilter(filter UtxoFilter)
+	// estimates sizes of inputs when funding transactions and computing fees
+	InputWeights() *InputWeightRegistry
+	// makes sure outputs paying to address are tracked by the wallet, returns
+	// true if address had to be imported
+	TrackAddress(address btcutil.Address, since time.Time) (bool, error)
+	// returns fresh wallet address intended for receiving change
+	NewChangeAddress() (btcutil.Address, error)
+	// only outputs with at least minConfirmations confirmations are used to
+	// fund the transaction, 0 allows outputs of unconfirmed transactions
 	CreateTransaction(
 		outputs []*wire.TxOut,
 		feeRatePerKb btcutil.Amount,
-		changeScript btcutil.Address) (*wire.MsgTx, error)
+		changeScript btcutil.Address,
+		minConfirmations uint32,
+	) (*wire.MsgTx, error)
 	SignRawTransaction(tx *wire.MsgTx) (*wire.MsgTx, bool, error)
 	// requires wallet to be unlocked
 	CreateAndSignTx(
 		output []*wire.TxOut,
 		feeRatePerKb btcutil.Amount,
 		changeAddress btcutil.Address,
+		minConfirmations uint32,
 	) (*wire.MsgTx, error)
 	SendRawTransaction(tx *wire.MsgTx, allowHighFees bool) (*chainhash.Hash, error)
+	// sets label of the address, no-op for wallets without labels
+	LabelAddress(address btcutil.Address, label string) error
+	ListTransactionsByLabel(labelPrefix string, limit int) ([]WalletTransaction, error)
+	// returns fee rates below which connected node rejects transactions
+	MempoolPolicy() (*MempoolPolicy, error)
+	// returns true if connected node accepts transaction packages
+	SupportsPackageRelay() (bool, error)
+	// submits child paying for its parent as one package
+	SubmitPackage(parent, child *wire.MsgTx) error
 	ListOutputs(onlySpendable bool) ([]Utxo, error)
 	TxDetails(txHash *chainhash.Hash, pkScript []byte) (*notifier.TxConfirmation, TxStatus, error)
+	// block queries are served by node connected to the wallet
+	GetBlockHash(blockHeight int64) (*chainhash.Hash, error)
+	GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error)
 }
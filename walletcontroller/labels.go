@@ -0,0 +1,137 @@
+package walletcontroller
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/babylonchain/btc-staker/types"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+const (
+	// number of wallet transactions read at once when searching by label
+	listTransactionsPageSize = 1000
+)
+
+// ErrLabelsNotSupported is returned by label operations on wallets without
+// address book labels
+var ErrLabelsNotSupported = errors.New("labels are only supported by bitcoind wallet")
+
+// WalletTransaction is one entry of wallet transaction history i.e one output
+// sent or received by the transaction
+type WalletTransaction struct {
+	TxHash   string
+	Vout     uint32
+	Address  string
+	Label    string
+	Category string
+	Amount   btcutil.Amount
+	// only set for sent outputs
+	Fee           *btcutil.Amount
+	Confirmations int64
+	Time          int64
+}
+
+// LabelAddress sets label of the address in bitcoind wallet. Addresses which do
+// not belong to the wallet are labeled as well, and the label is displayed for
+// transactions sending to them. No-op for btcwallet.
+func (w *RpcWalletController) LabelAddress(address btcutil.Address, label string) error {
+	if w.backend != types.BitcoindWalletBackend {
+		return nil
+	}
+
+	params, err := rawParams(address.EncodeAddress(), label)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = w.RawRequest("setlabel", params)
+	return err
+}
+
+// ListTransactionsByLabel returns up to limit most recent wallet transaction
+// entries with label starting with labelPrefix, newest first
+func (w *RpcWalletController) ListTransactionsByLabel(labelPrefix string, limit int) ([]WalletTransaction, error) {
+	if w.backend != types.BitcoindWalletBackend {
+		return nil, ErrLabelsNotSupported
+	}
+
+	var found []WalletTransaction
+
+	// listtransactions returns pages ordered from oldest to newest, with skip
+	// counted from the newest entry
+	for skip := 0; len(found) < limit; skip += listTransactionsPageSize {
+		params, err := rawParams("*", listTransactionsPageSize, skip, true)
+
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := w.RawRequest("listtransactions", params)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var page []btcjson.ListTransactionsResult
+		if err := json.Unmarshal(res, &page); err != nil {
+			return nil, err
+		}
+
+		for i := len(page) - 1; i >= 0 && len(found) < limit; i-- {
+			entry := page[i]
+
+			if entry.Label == nil || !strings.HasPrefix(*entry.Label, labelPrefix) {
+				continue
+			}
+
+			tx, err := walletTransactionFromResult(&entry)
+
+			if err != nil {
+				return nil, err
+			}
+
+			found = append(found, *tx)
+		}
+
+		if len(page) < listTransactionsPageSize {
+			break
+		}
+	}
+
+	return found, nil
+}
+
+func walletTransactionFromResult(r *btcjson.ListTransactionsResult) (*WalletTransaction, error) {
+	amount, err := btcutil.NewAmount(r.Amount)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var fee *btcutil.Amount
+	if r.Fee != nil {
+		f, err := btcutil.NewAmount(*r.Fee)
+
+		if err != nil {
+			return nil, err
+		}
+
+		fee = &f
+	}
+
+	return &WalletTransaction{
+		TxHash:        r.TxID,
+		Vout:          r.Vout,
+		Address:       r.Address,
+		Label:         *r.Label,
+		Category:      r.Category,
+		Amount:        amount,
+		Fee:           fee,
+		Confirmations: r.Confirmations,
+		Time:          r.Time,
+	}, nil
+}
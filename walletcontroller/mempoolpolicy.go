@@ -0,0 +1,81 @@
+package walletcontroller
+
+import (
+	"encoding/json"
+
+	"github.com/babylonchain/btc-staker/types"
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+// MempoolPolicy describes fee rates (in sat/kvbyte) below which node connected
+// to the wallet does not accept transactions
+type MempoolPolicy struct {
+	MinRelayFee btcutil.Amount
+	// raises above MinRelayFee when mempool of the node is full, always equal
+	// to MinRelayFee for btcd
+	MempoolMinFee btcutil.Amount
+}
+
+func (w *RpcWalletController) MempoolPolicy() (*MempoolPolicy, error) {
+	switch w.backend {
+	case types.BitcoindWalletBackend:
+		res, err := w.RawRequest("getmempoolinfo", nil)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// fee rates are in btc/kvbyte
+		var info struct {
+			MempoolMinFee float64 `json:"mempoolminfee"`
+			MinRelayTxFee float64 `json:"minrelaytxfee"`
+		}
+
+		if err := json.Unmarshal(res, &info); err != nil {
+			return nil, err
+		}
+
+		minRelayFee, err := btcutil.NewAmount(info.MinRelayTxFee)
+
+		if err != nil {
+			return nil, err
+		}
+
+		mempoolMinFee, err := btcutil.NewAmount(info.MempoolMinFee)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &MempoolPolicy{
+			MinRelayFee:   minRelayFee,
+			MempoolMinFee: mempoolMinFee,
+		}, nil
+	default:
+		// btcwallet forwards getinfo to connected btcd
+		res, err := w.RawRequest("getinfo", nil)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var info struct {
+			RelayFee float64 `json:"relayfee"`
+		}
+
+		if err := json.Unmarshal(res, &info); err != nil {
+			return nil, err
+		}
+
+		relayFee, err := btcutil.NewAmount(info.RelayFee)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &MempoolPolicy{
+			MinRelayFee:   relayFee,
+			MempoolMinFee: relayFee,
+		}, nil
+	}
+}
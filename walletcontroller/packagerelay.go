@@ -0,0 +1,110 @@
+package walletcontroller
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/babylonchain/btc-staker/types"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	// first bitcoind version which accepts packages through submitpackage on
+	// all networks, previous versions allowed it only on regtest
+	minPackageRelayBitcoindVersion = 280000
+
+	packageSubmitSuccessMsg = "success"
+)
+
+type packageTxResult struct {
+	Txid  string `json:"txid"`
+	Error string `json:"error,omitempty"`
+}
+
+type submitPackageResult struct {
+	PackageMsg string                     `json:"package_msg"`
+	TxResults  map[string]packageTxResult `json:"tx-results"`
+}
+
+// SupportsPackageRelay checks whether node connected to the wallet accepts
+// packages. Result is cached after first successful check, so node upgrade
+// requires daemon restart to be detected.
+func (w *RpcWalletController) SupportsPackageRelay() (bool, error) {
+	if w.backend != types.BitcoindWalletBackend {
+		return false, nil
+	}
+
+	w.packageRelayMu.Lock()
+	defer w.packageRelayMu.Unlock()
+
+	if w.packageRelay != nil {
+		return *w.packageRelay, nil
+	}
+
+	res, err := w.RawRequest("getnetworkinfo", nil)
+
+	if err != nil {
+		return false, err
+	}
+
+	var info struct {
+		Version int64 `json:"version"`
+	}
+
+	if err := json.Unmarshal(res, &info); err != nil {
+		return false, err
+	}
+
+	supported := info.Version >= minPackageRelayBitcoindVersion
+	w.packageRelay = &supported
+
+	return supported, nil
+}
+
+// SubmitPackage submits child paying for its parent as one package, so that
+// parent paying less than mempool minimum fee is accepted together with child
+func (w *RpcWalletController) SubmitPackage(parent, child *wire.MsgTx) error {
+	parentHex, err := serializeTxHex(parent)
+
+	if err != nil {
+		return err
+	}
+
+	childHex, err := serializeTxHex(child)
+
+	if err != nil {
+		return err
+	}
+
+	params, err := rawParams([]string{parentHex, childHex})
+
+	if err != nil {
+		return err
+	}
+
+	res, err := w.RawRequest("submitpackage", params)
+
+	if err != nil {
+		return err
+	}
+
+	var result submitPackageResult
+
+	if err := json.Unmarshal(res, &result); err != nil {
+		return fmt.Errorf("invalid submitpackage response: %w", err)
+	}
+
+	if result.PackageMsg != packageSubmitSuccessMsg {
+		var txErrs []string
+		for _, r := range result.TxResults {
+			if r.Error != "" {
+				txErrs = append(txErrs, fmt.Sprintf("%s: %s", r.Txid, r.Error))
+			}
+		}
+
+		return fmt.Errorf("package rejected: %s %s", result.PackageMsg, strings.Join(txErrs, ", "))
+	}
+
+	return nil
+}
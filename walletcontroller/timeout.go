@@ -0,0 +1,100 @@
+package walletcontroller
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/babylonchain/btc-staker/utils"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// Calls of rpcclient.Client can't be cancelled, so a hung wallet would block
+// callers forever. All wallet calls go through wrappers below, which give up
+// waiting after configured timeout.
+
+func (w *RpcWalletController) rpcContext() (context.Context, context.CancelFunc) {
+	if w.rpcTimeout == 0 {
+		return context.WithCancel(context.Background())
+	}
+
+	return context.WithTimeout(context.Background(), w.rpcTimeout)
+}
+
+func rpcCall[T any](w *RpcWalletController, call func() (T, error)) (T, error) {
+	ctx, cancel := w.rpcContext()
+	defer cancel()
+
+	return utils.CallWithContext(ctx, call)
+}
+
+func rpcCallNoResult(w *RpcWalletController, call func() error) error {
+	_, err := rpcCall(w, func() (struct{}, error) {
+		return struct{}{}, call()
+	})
+	return err
+}
+
+func (w *RpcWalletController) RawRequest(method string, params []json.RawMessage) (json.RawMessage, error) {
+	return rpcCall(w, func() (json.RawMessage, error) {
+		return w.Client.RawRequest(method, params)
+	})
+}
+
+func (w *RpcWalletController) WalletPassphrase(passphrase string, timeoutSecs int64) error {
+	return rpcCallNoResult(w, func() error {
+		return w.Client.WalletPassphrase(passphrase, timeoutSecs)
+	})
+}
+
+func (w *RpcWalletController) GetWalletInfo() (*btcjson.GetWalletInfoResult, error) {
+	return rpcCall(w, w.Client.GetWalletInfo)
+}
+
+func (w *RpcWalletController) DumpPrivKey(address btcutil.Address) (*btcutil.WIF, error) {
+	return rpcCall(w, func() (*btcutil.WIF, error) {
+		return w.Client.DumpPrivKey(address)
+	})
+}
+
+func (w *RpcWalletController) ImportPrivKey(privKeyWIF *btcutil.WIF) error {
+	return rpcCallNoResult(w, func() error {
+		return w.Client.ImportPrivKey(privKeyWIF)
+	})
+}
+
+func (w *RpcWalletController) ListUnspent() ([]btcjson.ListUnspentResult, error) {
+	return rpcCall(w, w.Client.ListUnspent)
+}
+
+func (w *RpcWalletController) ListUnspentMinMax(minConf, maxConf int) ([]btcjson.ListUnspentResult, error) {
+	return rpcCall(w, func() ([]btcjson.ListUnspentResult, error) {
+		return w.Client.ListUnspentMinMax(minConf, maxConf)
+	})
+}
+
+func (w *RpcWalletController) GetBlockHash(blockHeight int64) (*chainhash.Hash, error) {
+	return rpcCall(w, func() (*chainhash.Hash, error) {
+		return w.Client.GetBlockHash(blockHeight)
+	})
+}
+
+func (w *RpcWalletController) GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+	return rpcCall(w, func() (*wire.MsgBlock, error) {
+		return w.Client.GetBlock(blockHash)
+	})
+}
+
+func (w *RpcWalletController) GetAddressInfo(address string) (*btcjson.GetAddressInfoResult, error) {
+	return rpcCall(w, func() (*btcjson.GetAddressInfoResult, error) {
+		return w.Client.GetAddressInfo(address)
+	})
+}
+
+func (w *RpcWalletController) GetDescriptorInfo(descriptor string) (*btcjson.GetDescriptorInfoResult, error) {
+	return rpcCall(w, func() (*btcjson.GetDescriptorInfoResult, error) {
+		return w.Client.GetDescriptorInfo(descriptor)
+	})
+}
@@ -1,6 +1,7 @@
 package walletcontroller
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcwallet/wallet/txauthor"
+	"github.com/btcsuite/btcwallet/wallet/txrules"
 )
 
 type Utxo struct {
@@ -17,6 +19,11 @@ type Utxo struct {
 	PkScript     []byte
 	RedeemScript []byte
 	Address      string
+	// 0 for outputs of unconfirmed transactions
+	Confirmations int64
+	// set only for outputs considered for funding of transactions, listed
+	// unconfirmed outputs are not tagged
+	Source UtxoSource
 }
 
 type byAmount []Utxo
@@ -25,6 +32,26 @@ func (s byAmount) Len() int           { return len(s) }
 func (s byAmount) Less(i, j int) bool { return s[i].Amount < s[j].Amount }
 func (s byAmount) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
 
+// byAmountAndOutpoint orders utxos by amount and, for equal amounts, by outpoint.
+// This makes ordering of utxos fully deterministic.
+type byAmountAndOutpoint []Utxo
+
+func (s byAmountAndOutpoint) Len() int { return len(s) }
+func (s byAmountAndOutpoint) Less(i, j int) bool {
+	if s[i].Amount != s[j].Amount {
+		return s[i].Amount < s[j].Amount
+	}
+
+	hashCmp := bytes.Compare(s[i].OutPoint.Hash[:], s[j].OutPoint.Hash[:])
+
+	if hashCmp != 0 {
+		return hashCmp < 0
+	}
+
+	return s[i].OutPoint.Index < s[j].OutPoint.Index
+}
+func (s byAmountAndOutpoint) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
 func resultsToUtxos(results []btcjson.ListUnspentResult, onlySpendable bool) ([]Utxo, error) {
 	var utxos []Utxo
 	for _, result := range results {
@@ -60,40 +87,20 @@ func resultsToUtxos(results []btcjson.ListUnspentResult, onlySpendable bool) ([]
 		}
 
 		utxo := Utxo{
-			Amount:       amount,
-			OutPoint:     *outpoint,
-			PkScript:     script,
-			RedeemScript: redeemScript,
-			Address:      result.Address,
+			Amount:        amount,
+			OutPoint:      *outpoint,
+			PkScript:      script,
+			RedeemScript:  redeemScript,
+			Address:       result.Address,
+			Confirmations: result.Confirmations,
 		}
 		utxos = append(utxos, utxo)
 	}
 	return utxos, nil
 }
 
-func makeInputSource(utxos []Utxo) txauthor.InputSource {
-	currentTotal := btcutil.Amount(0)
-	currentInputs := make([]*wire.TxIn, 0, len(utxos))
-	currentScripts := make([][]byte, 0, len(utxos))
-	currentInputValues := make([]btcutil.Amount, 0, len(utxos))
-
-	return func(target btcutil.Amount) (btcutil.Amount, []*wire.TxIn,
-		[]btcutil.Amount, [][]byte, error) {
-
-		for currentTotal < target && len(utxos) != 0 {
-			nextCredit := &utxos[0]
-			utxos = utxos[1:]
-			nextInput := wire.NewTxIn(&nextCredit.OutPoint, nil, nil)
-			currentTotal += nextCredit.Amount
-			currentInputs = append(currentInputs, nextInput)
-			currentScripts = append(currentScripts, nextCredit.PkScript)
-			currentInputValues = append(currentInputValues, nextCredit.Amount)
-		}
-		return currentTotal, currentInputs, currentInputValues, currentScripts, nil
-	}
-}
-
 func buildTxFromOutputs(
+	weights *InputWeightRegistry,
 	utxos []Utxo,
 	outputs []*wire.TxOut,
 	feeRatePerKb btcutil.Amount,
@@ -107,25 +114,132 @@ func buildTxFromOutputs(
 		return nil, fmt.Errorf("there must be at least 1 output in transaction")
 	}
 
-	ch := txauthor.ChangeSource{
-		NewScript: func() ([]byte, error) {
-			return changeScript, nil
-		},
-		ScriptSize: len(changeScript),
+	targetAmount := txauthor.SumOutputValues(outputs)
+
+	var inputAmount btcutil.Amount
+	// utxos are used in provided order until they cover outputs and fee of
+	// transaction with change output
+	for i := range utxos {
+		inputAmount += utxos[i].Amount
+		selected := utxos[:i+1]
+
+		txSize, err := weights.EstimateVirtualSize(selected, outputs, len(changeScript))
+
+		if err != nil {
+			return nil, err
+		}
+
+		fee := txrules.FeeForSerializeSize(feeRatePerKb, txSize)
+
+		if inputAmount < targetAmount+fee {
+			continue
+		}
+
+		tx := wire.NewMsgTx(wire.TxVersion)
+		for _, utxo := range selected {
+			tx.AddTxIn(wire.NewTxIn(&utxo.OutPoint, nil, nil))
+		}
+
+		for _, output := range outputs {
+			tx.AddTxOut(output)
+		}
+
+		change := wire.NewTxOut(int64(inputAmount-targetAmount-fee), changeScript)
+		if change.Value != 0 && !txrules.IsDustOutput(change, txrules.DefaultRelayFeePerKb) {
+			tx.AddTxOut(change)
+		}
+
+		return tx, nil
+	}
+
+	return nil, fmt.Errorf("insufficient funds available to construct transaction")
+}
+
+// BuildConsolidationTx builds unsigned transaction which spends all provided utxos
+// to one output paying to destinationScript. Fee is deducted from the output value.
+// Output is checked against dust threshold at dustRelayFeePerKb.
+func BuildConsolidationTx(
+	weights *InputWeightRegistry,
+	utxos []Utxo,
+	destinationScript []byte,
+	feeRatePerKb btcutil.Amount,
+	dustRelayFeePerKb btcutil.Amount,
+) (*wire.MsgTx, btcutil.Amount, error) {
+	if len(utxos) == 0 {
+		return nil, 0, fmt.Errorf("there must be at least 1 usable UTXO to build transaction")
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+
+	var totalValue btcutil.Amount
+	for _, utxo := range utxos {
+		tx.AddTxIn(wire.NewTxIn(&utxo.OutPoint, nil, nil))
+		totalValue += utxo.Amount
 	}
 
-	inputSource := makeInputSource(utxos)
+	output := wire.NewTxOut(int64(totalValue), destinationScript)
 
-	authoredTx, err := txauthor.NewUnsignedTransaction(
-		outputs,
-		feeRatePerKb,
-		inputSource,
-		&ch,
-	)
+	txSize, err := weights.EstimateVirtualSize(utxos, []*wire.TxOut{output}, 0)
 
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	return authoredTx.Tx, nil
+	fee := txrules.FeeForSerializeSize(feeRatePerKb, txSize)
+
+	output.Value -= int64(fee)
+
+	if txrules.IsDustOutput(output, dustRelayFeePerKb) {
+		return nil, 0, fmt.Errorf("consolidated output value %d is too low after paying fee %d", output.Value, fee)
+	}
+
+	tx.AddTxOut(output)
+
+	return tx, fee, nil
+}
+
+// BuildCpfpTx builds unsigned child transaction which spends parentOutput back
+// to destinationScript. Child fee is chosen so that parent and child together
+// pay feeRatePerKb, taking into account fee already paid by the parent.
+func BuildCpfpTx(
+	weights *InputWeightRegistry,
+	parentOutput Utxo,
+	parentVSize int64,
+	parentFee btcutil.Amount,
+	destinationScript []byte,
+	feeRatePerKb btcutil.Amount,
+	dustRelayFeePerKb btcutil.Amount,
+) (*wire.MsgTx, btcutil.Amount, error) {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	tx.AddTxIn(wire.NewTxIn(&parentOutput.OutPoint, nil, nil))
+
+	output := wire.NewTxOut(int64(parentOutput.Amount), destinationScript)
+
+	childSize, err := weights.EstimateVirtualSize([]Utxo{parentOutput}, []*wire.TxOut{output}, 0)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	packageFee := txrules.FeeForSerializeSize(feeRatePerKb, int(parentVSize)+childSize)
+
+	if packageFee <= parentFee {
+		return nil, 0, fmt.Errorf("parent fee %d already pays requested fee rate %d", parentFee, feeRatePerKb)
+	}
+
+	// child must pay at least for itself to be relayed
+	fee := packageFee - parentFee
+	if childFee := txrules.FeeForSerializeSize(feeRatePerKb, childSize); fee < childFee {
+		fee = childFee
+	}
+
+	output.Value -= int64(fee)
+
+	if txrules.IsDustOutput(output, dustRelayFeePerKb) {
+		return nil, 0, fmt.Errorf("child output value %d is too low after paying fee %d", output.Value, fee)
+	}
+
+	tx.AddTxOut(output)
+
+	return tx, fee, nil
 }
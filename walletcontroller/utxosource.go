@@ -0,0 +1,106 @@
+package walletcontroller
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// UtxoSource tells whether output can be built upon safely
+type UtxoSource int
+
+const (
+	UtxoSourceConfirmed UtxoSource = iota
+	// output of unconfirmed transaction spending wallet outputs, i.e change or
+	// self transfer. Such transaction can't be replaced by third party.
+	UtxoSourceOwnUnconfirmed
+	// output of unconfirmed transaction received from third party, which can
+	// replace or double spend its transaction at any time
+	UtxoSourceForeignUnconfirmed
+)
+
+func (s UtxoSource) String() string {
+	switch s {
+	case UtxoSourceConfirmed:
+		return "confirmed"
+	case UtxoSourceOwnUnconfirmed:
+		return "own_unconfirmed"
+	case UtxoSourceForeignUnconfirmed:
+		return "foreign_unconfirmed"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+const (
+	// UnconfirmedFundingOwn allows funding only from unconfirmed outputs of
+	// transactions spending wallet outputs
+	UnconfirmedFundingOwn = "own"
+	// UnconfirmedFundingAny allows funding from any unconfirmed outputs
+	UnconfirmedFundingAny = "any"
+)
+
+func (w *RpcWalletController) GetTransaction(txHash *chainhash.Hash) (*btcjson.GetTransactionResult, error) {
+	return rpcCall(w, func() (*btcjson.GetTransactionResult, error) {
+		return w.Client.GetTransaction(txHash)
+	})
+}
+
+// isOwnTx returns true if transaction spends wallet outputs. Wallet reports
+// fee only for transactions debiting it, so transactions received from third
+// parties have no fee.
+func (w *RpcWalletController) isOwnTx(txHash *chainhash.Hash) (bool, error) {
+	if own, found := w.ownTxs.Load(*txHash); found {
+		return own, nil
+	}
+
+	tx, err := w.GetTransaction(txHash)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to get wallet transaction %s: %w", txHash, err)
+	}
+
+	own := tx.Fee != 0
+	// whether transaction spends wallet outputs never changes
+	w.ownTxs.Store(*txHash, own)
+
+	return own, nil
+}
+
+// tagUtxoSources sets source of unconfirmed outputs, confirmed outputs are
+// tagged already when listed
+func (w *RpcWalletController) tagUtxoSources(utxos []Utxo) error {
+	for i := range utxos {
+		if utxos[i].Confirmations > 0 {
+			continue
+		}
+
+		own, err := w.isOwnTx(&utxos[i].OutPoint.Hash)
+
+		if err != nil {
+			return err
+		}
+
+		if own {
+			utxos[i].Source = UtxoSourceOwnUnconfirmed
+		} else {
+			utxos[i].Source = UtxoSourceForeignUnconfirmed
+		}
+	}
+
+	return nil
+}
+
+// withoutForeignUnconfirmed removes tagged outputs of unconfirmed transactions
+// received from third parties
+func withoutForeignUnconfirmed(utxos []Utxo) []Utxo {
+	allowed := utxos[:0]
+	for i := range utxos {
+		if utxos[i].Source != UtxoSourceForeignUnconfirmed {
+			allowed = append(allowed, utxos[i])
+		}
+	}
+
+	return allowed
+}